@@ -0,0 +1,171 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"reflect"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+var apiServicesGVR = schema.GroupVersionResource{
+	Group:    "apiregistration.k8s.io",
+	Version:  "v1",
+	Resource: "apiservices",
+}
+
+// resourceLister lists the resources served for a group version, like the
+// discovery client.
+type resourceLister interface {
+	ServerResourcesForGroupVersion(groupVersion string) (*metav1.APIResourceList, error)
+}
+
+// startAPIServiceDiscovery watches the APIServices and adds the resources
+// served by aggregated API servers to the cache, like the resources of CRDs.
+func (r *CRDiscoverer) startAPIServiceDiscovery(client dynamic.Interface, lister resourceLister, stopper <-chan struct{}) error {
+	informer := dynamicinformer.NewFilteredDynamicInformer(client, apiServicesGVR, "", 0, nil, nil).Informer()
+	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			r.updateAPIService(obj.(*unstructured.Unstructured), lister)
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			r.updateAPIService(obj.(*unstructured.Unstructured), lister)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if u, ok := obj.(*unstructured.Unstructured); ok {
+				r.removeAPIService(u.GetName())
+			}
+		},
+	})
+	if err != nil {
+		return err
+	}
+	go informer.Run(stopper)
+	return nil
+}
+
+// updateAPIService replaces the resources of the APIService in the cache with
+// the resources it currently serves.
+func (r *CRDiscoverer) updateAPIService(apiService *unstructured.Unstructured, lister resourceLister) {
+	gvkps, err := aggregatedResources(apiService, lister)
+	if err != nil {
+		// Keep the previously discovered resources, the APIService is
+		// resolved again on its next update.
+		klog.ErrorS(err, "failed to discover the resources of the aggregated API", "apiservice", apiService.GetName())
+		return
+	}
+	r.SafeWrite(func() {
+		previous := r.aggregated[apiService.GetName()]
+		if reflect.DeepEqual(previous, gvkps) {
+			return
+		}
+		r.RemoveFromMap(previous...)
+		r.AppendToMap(gvkps...)
+		if r.aggregated == nil {
+			r.aggregated = map[string][]groupVersionKindPlural{}
+		}
+		r.aggregated[apiService.GetName()] = gvkps
+		r.markUpdated()
+	})
+}
+
+// removeAPIService removes the resources of the APIService from the cache.
+func (r *CRDiscoverer) removeAPIService(name string) {
+	r.SafeWrite(func() {
+		previous, ok := r.aggregated[name]
+		if !ok {
+			return
+		}
+		r.RemoveFromMap(previous...)
+		delete(r.aggregated, name)
+		r.markUpdated()
+	})
+}
+
+// aggregatedResources returns the resources served by the aggregated API
+// server of the APIService which can be listed and watched. APIServices which
+// are served by the apiserver itself, i.e. built-in and CRD resources, and
+// unavailable APIServices have none.
+func aggregatedResources(apiService *unstructured.Unstructured, lister resourceLister) ([]groupVersionKindPlural, error) {
+	service, _, _ := unstructured.NestedMap(apiService.Object, "spec", "service")
+	if service == nil || !apiServiceAvailable(apiService) {
+		return nil, nil
+	}
+	group, _, _ := unstructured.NestedString(apiService.Object, "spec", "group")
+	version, _, _ := unstructured.NestedString(apiService.Object, "spec", "version")
+	resources, err := lister.ServerResourcesForGroupVersion(schema.GroupVersion{Group: group, Version: version}.String())
+	if err != nil {
+		return nil, err
+	}
+	var gvkps []groupVersionKindPlural
+	for _, resource := range resources.APIResources {
+		// Stores need to list and watch the resource, which subresources
+		// don't support on their own.
+		if strings.Contains(resource.Name, "/") || !hasVerbs(resource.Verbs, "list", "watch") {
+			continue
+		}
+		gvkps = append(gvkps, groupVersionKindPlural{
+			GroupVersionKind: schema.GroupVersionKind{
+				Group:   group,
+				Version: version,
+				Kind:    resource.Kind,
+			},
+			Plural: resource.Name,
+		})
+	}
+	return gvkps, nil
+}
+
+// apiServiceAvailable returns whether the Available condition of the
+// APIService is true.
+func apiServiceAvailable(apiService *unstructured.Unstructured) bool {
+	conditions, _, _ := unstructured.NestedSlice(apiService.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if ok && condition["type"] == "Available" {
+			return condition["status"] == "True"
+		}
+	}
+	return false
+}
+
+// hasVerbs returns whether all the given verbs are supported.
+func hasVerbs(supported metav1.Verbs, verbs ...string) bool {
+	for _, verb := range verbs {
+		found := false
+		for _, s := range supported {
+			if s == verb {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+type fakeResourceLister map[string]*metav1.APIResourceList
+
+func (f fakeResourceLister) ServerResourcesForGroupVersion(groupVersion string) (*metav1.APIResourceList, error) {
+	return f[groupVersion], nil
+}
+
+func apiService(name string, service bool, available string) *unstructured.Unstructured {
+	spec := map[string]interface{}{
+		"group":   "catalog.example.com",
+		"version": "v1",
+	}
+	if service {
+		spec["service"] = map[string]interface{}{"name": "catalog", "namespace": "catalog"}
+	}
+	u := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": spec,
+		"status": map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Available", "status": available},
+			},
+		},
+	}}
+	u.SetName(name)
+	return u
+}
+
+func TestAggregatedResources(t *testing.T) {
+	lister := fakeResourceLister{
+		"catalog.example.com/v1": {
+			APIResources: []metav1.APIResource{
+				{Name: "instances", Kind: "Instance", Verbs: metav1.Verbs{"get", "list", "watch"}},
+				{Name: "instances/status", Kind: "Instance", Verbs: metav1.Verbs{"get", "list", "watch"}},
+				{Name: "bindings", Kind: "Binding", Verbs: metav1.Verbs{"get", "list"}},
+			},
+		},
+	}
+	want := []groupVersionKindPlural{
+		{
+			GroupVersionKind: schema.GroupVersionKind{Group: "catalog.example.com", Version: "v1", Kind: "Instance"},
+			Plural:           "instances",
+		},
+	}
+
+	tests := []struct {
+		name       string
+		apiService *unstructured.Unstructured
+		want       []groupVersionKindPlural
+	}{
+		{"aggregated", apiService("v1.catalog.example.com", true, "True"), want},
+		{"unavailable", apiService("v1.catalog.example.com", true, "False"), nil},
+		{"local", apiService("v1.catalog.example.com", false, "True"), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := aggregatedResources(tt.apiService, lister)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestUpdateAPIService(t *testing.T) {
+	lister := fakeResourceLister{
+		"catalog.example.com/v1": {
+			APIResources: []metav1.APIResource{
+				{Name: "instances", Kind: "Instance", Verbs: metav1.Verbs{"list", "watch"}},
+			},
+		},
+	}
+	r := &CRDiscoverer{}
+
+	r.updateAPIService(apiService("v1.catalog.example.com", true, "True"), lister)
+	gvkps, err := r.ResolveGVKToGVKPs(schema.GroupVersionKind{Group: "catalog.example.com", Version: "*", Kind: "*"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(gvkps) != 1 || gvkps[0].Plural != "instances" || !r.WasUpdated {
+		t.Fatalf("expected the resource of the aggregated API to be discovered, got %v", gvkps)
+	}
+
+	r.WasUpdated = false
+	r.updateAPIService(apiService("v1.catalog.example.com", true, "True"), lister)
+	if r.WasUpdated {
+		t.Errorf("expected no update for unchanged resources")
+	}
+
+	r.updateAPIService(apiService("v1.catalog.example.com", true, "False"), lister)
+	if len(r.Map) != 0 || !r.WasUpdated {
+		t.Errorf("expected the resources of the unavailable aggregated API to be removed, got %v", r.Map)
+	}
+
+	r.updateAPIService(apiService("v1.catalog.example.com", true, "True"), lister)
+	r.removeAPIService("v1.catalog.example.com")
+	if len(r.Map) != 0 {
+		t.Errorf("expected the resources of the deleted APIService to be removed, got %v", r.Map)
+	}
+}
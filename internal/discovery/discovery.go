@@ -17,10 +17,13 @@ package discovery
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"time"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	clientdiscovery "k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/rest"
@@ -34,8 +37,14 @@ import (
 	"k8s.io/kube-state-metrics/v2/pkg/util"
 )
 
-// Interval is the time interval between two cache sync checks.
-const Interval = 3 * time.Second
+const (
+	// Interval is the minimum time interval between two updates of the
+	// custom resource stores.
+	Interval = 3 * time.Second
+	// MaxBackoff is the maximum time interval between two retries of failed
+	// updates of the custom resource stores.
+	MaxBackoff = 5 * time.Minute
+)
 
 // StartDiscovery starts the discovery process, fetching all the objects that can be listed from the apiserver, every `Interval` seconds.
 // resolveGVK needs to be called after StartDiscovery to generate factories.
@@ -50,51 +59,51 @@ func (r *CRDiscoverer) StartDiscovery(ctx context.Context, config *rest.Config)
 	stopper := make(chan struct{})
 	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj interface{}) {
-			objSpec := obj.(*unstructured.Unstructured).Object["spec"].(map[string]interface{})
-			for _, version := range objSpec["versions"].([]interface{}) {
-				g := objSpec["group"].(string)
-				v := version.(map[string]interface{})["name"].(string)
-				k := objSpec["names"].(map[string]interface{})["kind"].(string)
-				p := objSpec["names"].(map[string]interface{})["plural"].(string)
-				gotGVKP := groupVersionKindPlural{
-					GroupVersionKind: schema.GroupVersionKind{
-						Group:   g,
-						Version: v,
-						Kind:    k,
-					},
-					Plural: p,
-				}
-				r.AppendToMap(gotGVKP)
-				r.SafeWrite(func() {
-					r.WasUpdated = true
-				})
+			gvkps, err := crdGVKPs(obj)
+			if err != nil {
+				// A malformed CRD must not stall the discovery of the others.
+				r.recordError(err, "failed to discover the resources of the CRD")
+				return
 			}
 			r.SafeWrite(func() {
+				r.AppendToMap(gvkps...)
+				r.markUpdated()
 				r.CRDsAddEventsCounter.Inc()
 				r.CRDsCacheCountGauge.Inc()
 			})
 		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			// CRDs are updated e.g. when versions or their schemas change.
+			oldGVKPs, err := crdGVKPs(oldObj)
+			if err != nil {
+				oldGVKPs = nil
+			}
+			gvkps, err := crdGVKPs(newObj)
+			if err != nil {
+				r.recordError(err, "failed to discover the resources of the CRD")
+				return
+			}
+			if reflect.DeepEqual(oldGVKPs, gvkps) {
+				return
+			}
+			r.SafeWrite(func() {
+				r.RemoveFromMap(oldGVKPs...)
+				r.AppendToMap(gvkps...)
+				r.markUpdated()
+			})
+		},
 		DeleteFunc: func(obj interface{}) {
-			objSpec := obj.(*unstructured.Unstructured).Object["spec"].(map[string]interface{})
-			for _, version := range objSpec["versions"].([]interface{}) {
-				g := objSpec["group"].(string)
-				v := version.(map[string]interface{})["name"].(string)
-				k := objSpec["names"].(map[string]interface{})["kind"].(string)
-				p := objSpec["names"].(map[string]interface{})["plural"].(string)
-				gotGVKP := groupVersionKindPlural{
-					GroupVersionKind: schema.GroupVersionKind{
-						Group:   g,
-						Version: v,
-						Kind:    k,
-					},
-					Plural: p,
-				}
-				r.RemoveFromMap(gotGVKP)
-				r.SafeWrite(func() {
-					r.WasUpdated = true
-				})
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			gvkps, err := crdGVKPs(obj)
+			if err != nil {
+				r.recordError(err, "failed to remove the resources of the CRD")
+				return
 			}
 			r.SafeWrite(func() {
+				r.RemoveFromMap(gvkps...)
+				r.markUpdated()
 				r.CRDsDeleteEventsCounter.Inc()
 				r.CRDsCacheCountGauge.Dec()
 			})
@@ -112,11 +121,98 @@ func (r *CRDiscoverer) StartDiscovery(ctx context.Context, config *rest.Config)
 		}
 	}()
 	go informer.Run(stopper)
-	return nil
+	// Resources of aggregated API servers aren't defined by CRDs, they are
+	// discovered from their APIServices instead.
+	discoveryClient, err := clientdiscovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return err
+	}
+	return r.startAPIServiceDiscovery(client, discoveryClient, stopper)
+}
+
+// crdGVKPs returns the GVKPs of all versions of the CRD.
+func crdGVKPs(obj interface{}) ([]groupVersionKindPlural, error) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("unexpected CRD object of type %T", obj)
+	}
+	g, _, err := unstructured.NestedString(u.Object, "spec", "group")
+	if err != nil {
+		return nil, fmt.Errorf("invalid group of CRD %s: %w", u.GetName(), err)
+	}
+	k, _, err := unstructured.NestedString(u.Object, "spec", "names", "kind")
+	if err != nil {
+		return nil, fmt.Errorf("invalid kind of CRD %s: %w", u.GetName(), err)
+	}
+	p, _, err := unstructured.NestedString(u.Object, "spec", "names", "plural")
+	if err != nil {
+		return nil, fmt.Errorf("invalid plural of CRD %s: %w", u.GetName(), err)
+	}
+	if g == "" || k == "" || p == "" {
+		return nil, fmt.Errorf("CRD %s is missing its group, kind or plural", u.GetName())
+	}
+	versions, _, err := unstructured.NestedSlice(u.Object, "spec", "versions")
+	if err != nil {
+		return nil, fmt.Errorf("invalid versions of CRD %s: %w", u.GetName(), err)
+	}
+	gvkps := make([]groupVersionKindPlural, 0, len(versions))
+	for _, version := range versions {
+		version, ok := version.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid version of CRD %s", u.GetName())
+		}
+		v, ok := version["name"].(string)
+		if !ok || v == "" {
+			return nil, fmt.Errorf("version of CRD %s is missing its name", u.GetName())
+		}
+		gvkps = append(gvkps, groupVersionKindPlural{
+			GroupVersionKind: schema.GroupVersionKind{
+				Group:   g,
+				Version: v,
+				Kind:    k,
+			},
+			Plural: p,
+			Scale:  scaleSubresourceOf(version),
+			Schema: schemaOf(version),
+		})
+	}
+	return gvkps, nil
+}
+
+// nextBackoff returns the backoff of the next retry of a failed update of the
+// custom resource stores, doubling the previous backoff up to MaxBackoff.
+func nextBackoff(previous time.Duration) time.Duration {
+	if previous < Interval {
+		return Interval
+	}
+	if previous*2 > MaxBackoff {
+		return MaxBackoff
+	}
+	return previous * 2
+}
+
+// schemaOf returns the OpenAPI v3 schema of the given version of a
+// CustomResourceDefinition, or nil if it has none.
+func schemaOf(version map[string]interface{}) map[string]interface{} {
+	openAPISchema, _, err := unstructured.NestedMap(version, "schema", "openAPIV3Schema")
+	if err != nil {
+		return nil
+	}
+	return openAPISchema
+}
+
+// recordError logs a discovery error and counts it.
+func (r *CRDiscoverer) recordError(err error, msg string, keysAndValues ...interface{}) {
+	klog.ErrorS(err, msg, keysAndValues...)
+	if r.DiscoveryErrorsCounter != nil {
+		r.DiscoveryErrorsCounter.Inc()
+	}
 }
 
 // ResolveGVKToGVKPs resolves the variable VKs to a GVK list, based on the current cache.
 func (r *CRDiscoverer) ResolveGVKToGVKPs(gvk schema.GroupVersionKind) (resolvedGVKPs []groupVersionKindPlural, err error) { // nolint:revive
+	r.m.RLock()
+	defer r.m.RUnlock()
 	g := gvk.Group
 	v := gvk.Version
 	k := gvk.Kind
@@ -128,12 +224,19 @@ func (r *CRDiscoverer) ResolveGVKToGVKPs(gvk schema.GroupVersionKind) (resolvedG
 	// No need to resolve, return.
 	if hasVersion && hasKind {
 		var p string
+		var openAPISchema map[string]interface{}
 		for _, el := range r.Map[g][v] {
 			if el.Kind == k {
 				p = el.Plural
+				openAPISchema = el.Schema
 				break
 			}
 		}
+		// The CRD might not have been discovered yet or have been deleted
+		// since, without its plural no client can be created.
+		if p == "" {
+			return nil, fmt.Errorf("GVK %v was not discovered", gvk)
+		}
 		return []groupVersionKindPlural{
 			{
 				GroupVersionKind: schema.GroupVersionKind{
@@ -142,6 +245,7 @@ func (r *CRDiscoverer) ResolveGVKToGVKPs(gvk schema.GroupVersionKind) (resolvedG
 					Kind:    k,
 				},
 				Plural: p,
+				Schema: openAPISchema,
 			},
 		}, nil
 	}
@@ -155,6 +259,7 @@ func (r *CRDiscoverer) ResolveGVKToGVKPs(gvk schema.GroupVersionKind) (resolvedG
 					Kind:    el.Kind,
 				},
 				Plural: el.Plural,
+				Schema: el.Schema,
 			})
 		}
 	}
@@ -170,6 +275,7 @@ func (r *CRDiscoverer) ResolveGVKToGVKPs(gvk schema.GroupVersionKind) (resolvedG
 							Kind:    k,
 						},
 						Plural: el.Plural,
+						Schema: el.Schema,
 					})
 				}
 			}
@@ -186,6 +292,7 @@ func (r *CRDiscoverer) ResolveGVKToGVKPs(gvk schema.GroupVersionKind) (resolvedG
 						Kind:    el.Kind,
 					},
 					Plural: el.Plural,
+					Schema: el.Schema,
 				})
 			}
 		}
@@ -193,7 +300,7 @@ func (r *CRDiscoverer) ResolveGVKToGVKPs(gvk schema.GroupVersionKind) (resolvedG
 	return
 }
 
-// PollForCacheUpdates polls the cache for updates and updates the stores accordingly.
+// PollForCacheUpdates watches the cache for updates and updates the stores accordingly.
 func (r *CRDiscoverer) PollForCacheUpdates(
 	ctx context.Context,
 	opts *options.Options,
@@ -201,17 +308,22 @@ func (r *CRDiscoverer) PollForCacheUpdates(
 	m *metricshandler.MetricsHandler,
 	factoryGenerator func() ([]customresource.RegistryFactory, error),
 ) {
-	// The interval at which we will check the cache for updates.
-	t := time.NewTicker(Interval)
-	// Track previous context to allow refreshing cache.
-	olderContext, olderCancel := context.WithCancel(ctx)
-	// Prevent context leak (kill the last metric handler instance).
-	defer olderCancel()
-	generateMetrics := func() {
+	// Whether the metrics handler was started, after which its stores are
+	// rebuilt on updates.
+	started := false
+	generateMetrics := func() (err error) {
+		// A panic, e.g. due to a CRD deleted while its stores are updated,
+		// must not stop the updates of the stores.
+		defer func() {
+			if p := recover(); p != nil {
+				err = fmt.Errorf("panic while updating custom resource stores: %v", p)
+			}
+		}()
+		var errs []error
 		// Get families for discovered factories.
 		customFactories, err := factoryGenerator()
 		if err != nil {
-			klog.ErrorS(err, "failed to update custom resource stores")
+			errs = append(errs, err)
 		}
 		// Update the list of enabled custom resources.
 		var enabledCustomResources []string
@@ -222,7 +334,7 @@ func (r *CRDiscoverer) PollForCacheUpdates(
 		// Create clients for discovered factories.
 		discoveredCustomResourceClients, err := util.CreateCustomResourceClients(opts.Apiserver, opts.Kubeconfig, customFactories...)
 		if err != nil {
-			klog.ErrorS(err, "failed to update custom resource stores")
+			errs = append(errs, err)
 		}
 		// Update the store builder with the new clients.
 		storeBuilder.WithCustomResourceClients(discoveredCustomResourceClients)
@@ -230,50 +342,109 @@ func (r *CRDiscoverer) PollForCacheUpdates(
 		storeBuilder.WithCustomResourceStoreFactories(customFactories...)
 		// Update the store builder with the new custom resources.
 		if err := storeBuilder.WithEnabledResources(enabledCustomResources); err != nil {
-			klog.ErrorS(err, "failed to update custom resource stores")
+			errs = append(errs, err)
 		}
 		// Configure the generation function for the custom resource stores.
 		storeBuilder.WithGenerateCustomResourceStoresFunc(storeBuilder.DefaultGenerateCustomResourceStoresFunc())
-		// Reset the flag, if there were no errors. Else, we'll try again on the next tick.
-		// Keep retrying if there were errors.
-		r.SafeWrite(func() {
-			r.WasUpdated = false
-		})
-		// Run the metrics handler with updated configs.
-		olderContext, olderCancel = context.WithCancel(ctx)
+		if started {
+			// Swap the stores once the stores of the updated resources have
+			// synced, serving the previous stores in the meantime.
+			m.Rebuild()
+			return utilerrors.NewAggregate(errs)
+		}
+		started = true
 		go func() {
-			// Blocks indefinitely until the unbuffered context is cancelled to serve metrics for that duration.
-			err = m.Run(olderContext)
+			// Blocks indefinitely until the context is cancelled to serve metrics for that duration.
+			err := m.Run(ctx)
 			if err != nil {
 				// Check if context was cancelled.
 				select {
-				case <-olderContext.Done():
+				case <-ctx.Done():
 					// Context cancelled, don't really need to log this though.
 				default:
 					klog.ErrorS(err, "failed to run metrics handler")
 				}
 			}
 		}()
+		return utilerrors.NewAggregate(errs)
 	}
 	go func() {
-		for range t.C {
+		// The cache is checked when it was updated, and in addition on
+		// every tick of the discovery interval, if any.
+		var tick <-chan time.Time
+		if opts.CustomResourceDiscoveryInterval > 0 {
+			t := time.NewTicker(opts.CustomResourceDiscoveryInterval)
+			defer t.Stop()
+			tick = t.C
+		}
+		updated := r.updates()
+		// timer delays updates to bursts of events and retries of failed
+		// updates, it fires right away for the initial check.
+		timer := time.NewTimer(0)
+		defer timer.Stop()
+		// The time of the last update, the backoff of the retries of failed
+		// updates and the time of the next retry.
+		var lastUpdate, retryAt time.Time
+		backoff := time.Duration(0)
+		for {
 			select {
 			case <-ctx.Done():
 				klog.InfoS("context cancelled")
-				t.Stop()
 				return
-			default:
-				// Check if cache has been updated.
-				shouldGenerateMetrics := false
-				r.SafeRead(func() {
-					shouldGenerateMetrics = r.WasUpdated
+			case <-updated:
+			case <-tick:
+			case <-timer.C:
+			}
+			// Check if cache has been updated.
+			shouldGenerateMetrics := false
+			r.SafeRead(func() {
+				shouldGenerateMetrics = r.WasUpdated
+			})
+			if !shouldGenerateMetrics {
+				continue
+			}
+			// Updates are at least Interval apart, so that e.g. the CRDs
+			// listed at startup are discovered at once.
+			notBefore := lastUpdate.Add(Interval)
+			if retryAt.After(notBefore) {
+				notBefore = retryAt
+			}
+			if wait := time.Until(notBefore); wait > 0 {
+				resetTimer(timer, wait)
+				continue
+			}
+			// Updates of the cache during the generation are picked up
+			// afterwards.
+			r.SafeWrite(func() {
+				r.WasUpdated = false
+			})
+			lastUpdate = time.Now()
+			if err := generateMetrics(); err != nil {
+				// Retry with backoff, keeping the stores which could be
+				// updated.
+				backoff = nextBackoff(backoff)
+				retryAt = time.Now().Add(backoff)
+				r.recordError(err, "failed to update custom resource stores, retrying", "backoff", backoff)
+				r.SafeWrite(func() {
+					r.WasUpdated = true
 				})
-				if shouldGenerateMetrics {
-					olderCancel()
-					generateMetrics()
-					klog.InfoS("discovery finished, cache updated")
-				}
+				resetTimer(timer, backoff)
+				continue
 			}
+			backoff, retryAt = 0, time.Time{}
+			klog.InfoS("discovery finished, cache updated")
 		}
 	}()
 }
+
+// resetTimer changes the timer to fire after the duration, whether it fired
+// already or not.
+func resetTimer(t *time.Timer, d time.Duration) {
+	if !t.Stop() {
+		select {
+		case <-t.C:
+		default:
+		}
+	}
+	t.Reset(d)
+}
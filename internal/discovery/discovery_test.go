@@ -17,7 +17,9 @@ import (
 	"reflect"
 	"sort"
 	"testing"
+	"time"
 
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
@@ -182,3 +184,97 @@ func TestGVKMapsResolveGVK(t *testing.T) {
 		}
 	}
 }
+
+func TestGVKMapsResolveUndiscoveredGVK(t *testing.T) {
+	r := &CRDiscoverer{}
+	if _, err := r.ResolveGVKToGVKPs(schema.GroupVersionKind{Group: "testgroup", Version: "v1", Kind: "TestObject1"}); err == nil {
+		t.Errorf("expected an error for a GVK which was not discovered")
+	}
+}
+
+func TestCRDGVKPs(t *testing.T) {
+	crd := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"group": "testgroup",
+			"names": map[string]interface{}{"kind": "TestObject1", "plural": "testobjects1"},
+			"versions": []interface{}{
+				map[string]interface{}{"name": "v1"},
+			},
+		},
+	}}
+	want := []groupVersionKindPlural{
+		{
+			GroupVersionKind: schema.GroupVersionKind{Group: "testgroup", Version: "v1", Kind: "TestObject1"},
+			Plural:           "testobjects1",
+		},
+	}
+	got, err := crdGVKPs(crd)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	malformed := crd.DeepCopy()
+	unstructured.RemoveNestedField(malformed.Object, "spec", "names", "plural")
+	if _, err := crdGVKPs(malformed); err == nil {
+		t.Errorf("expected an error for a CRD without plural")
+	}
+	malformed = crd.DeepCopy()
+	malformed.Object["spec"].(map[string]interface{})["versions"] = []interface{}{"v1"}
+	if _, err := crdGVKPs(malformed); err == nil {
+		t.Errorf("expected an error for a CRD with malformed versions")
+	}
+}
+
+func TestNextBackoff(t *testing.T) {
+	backoff := time.Duration(0)
+	for _, want := range []time.Duration{Interval, 2 * Interval, 4 * Interval} {
+		backoff = nextBackoff(backoff)
+		if backoff != want {
+			t.Errorf("got backoff %s, want %s", backoff, want)
+		}
+	}
+	if got := nextBackoff(MaxBackoff); got != MaxBackoff {
+		t.Errorf("got backoff %s, want %s", got, MaxBackoff)
+	}
+}
+
+func TestMarkUpdated(t *testing.T) {
+	r := &CRDiscoverer{}
+	updated := r.updates()
+	r.MarkUpdated()
+	// Updates are coalesced while one is pending.
+	r.MarkUpdated()
+	select {
+	case <-updated:
+	default:
+		t.Fatalf("expected the update to be signaled")
+	}
+	select {
+	case <-updated:
+		t.Errorf("expected a single pending update")
+	default:
+	}
+	if !r.WasUpdated {
+		t.Errorf("expected the cache to be marked as updated")
+	}
+}
+
+func TestGroupVersionKinds(t *testing.T) {
+	r := &CRDiscoverer{}
+	r.AppendToMap(
+		groupVersionKindPlural{GroupVersionKind: schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "ServiceMonitor"}, Plural: "servicemonitors"},
+		groupVersionKindPlural{GroupVersionKind: schema.GroupVersionKind{Group: "apps.example.com", Version: "v1beta1", Kind: "Widget"}, Plural: "widgets"},
+		groupVersionKindPlural{GroupVersionKind: schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "PodMonitor"}, Plural: "podmonitors"},
+	)
+	want := []schema.GroupVersionKind{
+		{Group: "apps.example.com", Version: "v1beta1", Kind: "Widget"},
+		{Group: "monitoring.coreos.com", Version: "v1", Kind: "PodMonitor"},
+		{Group: "monitoring.coreos.com", Version: "v1", Kind: "ServiceMonitor"},
+	}
+	if got := r.GroupVersionKinds(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	basemetrics "k8s.io/component-base/metrics"
+
+	"k8s.io/kube-state-metrics/v2/pkg/customresource"
+	"k8s.io/kube-state-metrics/v2/pkg/metric"
+	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
+)
+
+// scaleResourceSuffix is appended to the names of the resources of the scale
+// metrics, so that their stores do not replace the stores of the Custom
+// Resource State metrics of the same resources.
+const scaleResourceSuffix = "-scale"
+
+var scaleLabelKeys = []string{"customresource_group", "customresource_kind", "customresource_version", "namespace", "name"}
+
+// scaleSubresourceOf returns the scale subresource of the given version of a
+// CustomResourceDefinition, or nil if it has none.
+func scaleSubresourceOf(version map[string]interface{}) *scaleSubresource {
+	scale, found, err := unstructured.NestedMap(version, "subresources", "scale")
+	if err != nil || !found {
+		return nil
+	}
+	specReplicasPath, _, _ := unstructured.NestedString(scale, "specReplicasPath")
+	statusReplicasPath, _, _ := unstructured.NestedString(scale, "statusReplicasPath")
+	return &scaleSubresource{
+		SpecReplicasPath:   specReplicasPath,
+		StatusReplicasPath: statusReplicasPath,
+	}
+}
+
+// ScaleFactories returns the factories of the replica metrics of the
+// discovered custom resources which implement the scale subresource, read
+// via the JSON paths of their scale subresource.
+func (r *CRDiscoverer) ScaleFactories() []customresource.RegistryFactory {
+	var factories []customresource.RegistryFactory
+	r.SafeRead(func() {
+		for group, versions := range r.Map {
+			for version, kinds := range versions {
+				for _, el := range kinds {
+					if el.Scale == nil {
+						continue
+					}
+					factories = append(factories, scaleMetrics{
+						groupVersionKindPlural: groupVersionKindPlural{
+							GroupVersionKind: schema.GroupVersionKind{Group: group, Version: version, Kind: el.Kind},
+							Plural:           el.Plural,
+							Scale:            el.Scale,
+						},
+					})
+				}
+			}
+		}
+	})
+	sort.Slice(factories, func(i, j int) bool {
+		return factories[i].(scaleMetrics).String() < factories[j].(scaleMetrics).String()
+	})
+	return factories
+}
+
+// scaleMetrics is an implementation of the customresource.RegistryFactory
+// interface which provides the replica metrics of a custom resource which
+// implements the scale subresource.
+type scaleMetrics struct {
+	groupVersionKindPlural
+}
+
+var _ customresource.RegistryFactory = scaleMetrics{}
+
+func (s scaleMetrics) Name() string {
+	return s.Plural + scaleResourceSuffix
+}
+
+func (s scaleMetrics) CreateClient(cfg *rest.Config) (interface{}, error) {
+	c, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return c.Resource(schema.GroupVersionResource{
+		Group:    s.Group,
+		Version:  s.Version,
+		Resource: s.Plural,
+	}), nil
+}
+
+func (s scaleMetrics) MetricFamilyGenerators() []generator.FamilyGenerator {
+	return []generator.FamilyGenerator{
+		*generator.NewFamilyGeneratorWithStability(
+			"kube_scale_spec_replicas",
+			"Number of desired replicas of a custom resource, as read via its scale subresource.",
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			s.replicasFamily(s.Scale.SpecReplicasPath),
+		),
+		*generator.NewFamilyGeneratorWithStability(
+			"kube_scale_status_replicas",
+			"Number of actual replicas of a custom resource, as read via its scale subresource.",
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			s.replicasFamily(s.Scale.StatusReplicasPath),
+		),
+	}
+}
+
+// replicasFamily returns the function which generates the metric of the
+// number of replicas at the given JSON path of the object.
+func (s scaleMetrics) replicasFamily(path string) func(obj interface{}) *metric.Family {
+	return func(obj interface{}) *metric.Family {
+		u := obj.(*unstructured.Unstructured)
+		value, ok := replicasAt(u, path)
+		if !ok {
+			return &metric.Family{}
+		}
+		return &metric.Family{
+			Metrics: []*metric.Metric{
+				{
+					LabelKeys:   scaleLabelKeys,
+					LabelValues: []string{s.Group, s.Kind, s.Version, u.GetNamespace(), u.GetName()},
+					Value:       value,
+				},
+			},
+		}
+	}
+}
+
+// replicasAt returns the number at the JSON path of the scale subresource,
+// e.g. .spec.replicas, in the object. Paths of the scale subresource must not
+// use the array notation.
+func replicasAt(u *unstructured.Unstructured, path string) (float64, bool) {
+	if path == "" {
+		return 0, false
+	}
+	value, found, err := unstructured.NestedFieldNoCopy(u.Object, strings.Split(strings.TrimPrefix(path, "."), ".")...)
+	if err != nil || !found {
+		return 0, false
+	}
+	switch v := value.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case int32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+func (s scaleMetrics) ExpectedType() interface{} {
+	u := unstructured.Unstructured{}
+	u.SetGroupVersionKind(s.GroupVersionKind)
+	return &u
+}
+
+func (s scaleMetrics) ListWatch(customResourceClient interface{}, ns string, fieldSelector string) cache.ListerWatcher {
+	api := customResourceClient.(dynamic.NamespaceableResourceInterface).Namespace(ns)
+	ctx := context.Background()
+	return &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.FieldSelector = fieldSelector
+			return api.List(ctx, options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.FieldSelector = fieldSelector
+			return api.Watch(ctx, options)
+		},
+	}
+}
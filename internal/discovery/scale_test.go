@@ -0,0 +1,97 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestScaleSubresourceOf(t *testing.T) {
+	version := map[string]interface{}{
+		"name": "v1",
+		"subresources": map[string]interface{}{
+			"scale": map[string]interface{}{
+				"specReplicasPath":   ".spec.replicas",
+				"statusReplicasPath": ".status.replicas",
+			},
+		},
+	}
+	want := &scaleSubresource{SpecReplicasPath: ".spec.replicas", StatusReplicasPath: ".status.replicas"}
+	if got := scaleSubresourceOf(version); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+	if got := scaleSubresourceOf(map[string]interface{}{"name": "v1"}); got != nil {
+		t.Errorf("expected no scale subresource, got %+v", got)
+	}
+}
+
+func TestScaleFactories(t *testing.T) {
+	r := &CRDiscoverer{
+		Map: map[string]map[string][]kindPlural{
+			"myteam.io": {
+				"v1": {
+					{
+						Kind:   "Foo",
+						Plural: "foos",
+						Scale:  &scaleSubresource{SpecReplicasPath: ".spec.replicas", StatusReplicasPath: ".status.ready.replicas"},
+					},
+					{
+						Kind:   "Bar",
+						Plural: "bars",
+					},
+				},
+			},
+		},
+	}
+
+	factories := r.ScaleFactories()
+	if len(factories) != 1 {
+		t.Fatalf("expected only the factory of the scalable resource, got %d", len(factories))
+	}
+	f := factories[0]
+	if f.Name() != "foos-scale" {
+		t.Errorf("expected name foos-scale, got %s", f.Name())
+	}
+
+	u := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec":   map[string]interface{}{"replicas": int64(3)},
+		"status": map[string]interface{}{"ready": map[string]interface{}{"replicas": int64(2)}},
+	}}
+	u.SetNamespace("default")
+	u.SetName("foo")
+	families := f.MetricFamilyGenerators()
+	want := []string{
+		`kube_scale_spec_replicas{customresource_group="myteam.io",customresource_kind="Foo",customresource_version="v1",namespace="default",name="foo"} 3
+`,
+		`kube_scale_status_replicas{customresource_group="myteam.io",customresource_kind="Foo",customresource_version="v1",namespace="default",name="foo"} 2
+`,
+	}
+	for i, family := range families {
+		if got := string(family.Generate(u).ByteSlice()); got != want[i] {
+			t.Errorf("unexpected metrics, got:\n%s\nwant:\n%s", got, want[i])
+		}
+	}
+
+	// Objects without the number of replicas have no metrics.
+	delete(u.Object, "status")
+	if got := string(families[1].Generate(u).ByteSlice()); got != "" {
+		t.Errorf("expected no metrics without status replicas, got:\n%s", got)
+	}
+}
@@ -15,6 +15,7 @@ package discovery
 
 import (
 	"fmt"
+	"sort"
 	"sync"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -24,6 +25,17 @@ import (
 type groupVersionKindPlural struct {
 	schema.GroupVersionKind
 	Plural string
+	// Scale is the scale subresource of the version of the resource, if any.
+	Scale *scaleSubresource
+	// Schema is the OpenAPI v3 schema of the version of the resource, if any.
+	Schema map[string]interface{}
+}
+
+// scaleSubresource holds the JSON paths of the scale subresource of a custom
+// resource.
+type scaleSubresource struct {
+	SpecReplicasPath   string
+	StatusReplicasPath string
 }
 
 func (g groupVersionKindPlural) String() string {
@@ -33,6 +45,8 @@ func (g groupVersionKindPlural) String() string {
 type kindPlural struct {
 	Kind   string
 	Plural string
+	Scale  *scaleSubresource
+	Schema map[string]interface{}
 }
 
 // CRDiscoverer provides a cache of the collected GVKs, along with helper utilities.
@@ -49,6 +63,13 @@ type CRDiscoverer struct {
 	CRDsDeleteEventsCounter prometheus.Counter
 	// CRDsCacheCountGauge tracks the net amount of CRDs affecting the cache at this point.
 	CRDsCacheCountGauge prometheus.Gauge
+	// DiscoveryErrorsCounter tracks the number of malformed CRDs and failed updates of the custom resource stores.
+	DiscoveryErrorsCounter prometheus.Counter
+	// updated is signaled when the cache was updated, see MarkUpdated.
+	updated chan struct{}
+	// aggregated holds the resources added to the cache for each APIService
+	// of an aggregated API server.
+	aggregated map[string][]groupVersionKindPlural
 }
 
 // SafeRead executes the given function while holding a read lock.
@@ -65,6 +86,37 @@ func (r *CRDiscoverer) SafeWrite(f func()) {
 	f()
 }
 
+// MarkUpdated marks the cache as updated, so that the custom resource stores
+// are updated by PollForCacheUpdates.
+func (r *CRDiscoverer) MarkUpdated() {
+	r.SafeWrite(r.markUpdated)
+}
+
+// markUpdated marks the cache as updated, while holding the write lock.
+func (r *CRDiscoverer) markUpdated() {
+	r.WasUpdated = true
+	if r.updated == nil {
+		r.updated = make(chan struct{}, 1)
+	}
+	select {
+	case r.updated <- struct{}{}:
+	default:
+		// An update is pending already.
+	}
+}
+
+// updates returns the channel which is signaled when the cache was updated.
+func (r *CRDiscoverer) updates() <-chan struct{} {
+	var updated chan struct{}
+	r.SafeWrite(func() {
+		if r.updated == nil {
+			r.updated = make(chan struct{}, 1)
+		}
+		updated = r.updated
+	})
+	return updated
+}
+
 // AppendToMap appends the given GVKs to the cache.
 func (r *CRDiscoverer) AppendToMap(gvkps ...groupVersionKindPlural) {
 	if r.Map == nil {
@@ -77,8 +129,27 @@ func (r *CRDiscoverer) AppendToMap(gvkps ...groupVersionKindPlural) {
 		if _, ok := r.Map[gvkp.Group][gvkp.Version]; !ok {
 			r.Map[gvkp.Group][gvkp.Version] = []kindPlural{}
 		}
-		r.Map[gvkp.Group][gvkp.Version] = append(r.Map[gvkp.Group][gvkp.Version], kindPlural{Kind: gvkp.Kind, Plural: gvkp.Plural})
+		r.Map[gvkp.Group][gvkp.Version] = append(r.Map[gvkp.Group][gvkp.Version], kindPlural{Kind: gvkp.Kind, Plural: gvkp.Plural, Scale: gvkp.Scale, Schema: gvkp.Schema})
+	}
+}
+
+// GroupVersionKinds returns the discovered GVKs, sorted by group, version
+// and kind.
+func (r *CRDiscoverer) GroupVersionKinds() []schema.GroupVersionKind {
+	r.m.RLock()
+	defer r.m.RUnlock()
+	gvks := []schema.GroupVersionKind{}
+	for g, versions := range r.Map {
+		for v, kinds := range versions {
+			for _, el := range kinds {
+				gvks = append(gvks, schema.GroupVersionKind{Group: g, Version: v, Kind: el.Kind})
+			}
+		}
 	}
+	sort.Slice(gvks, func(i, j int) bool {
+		return gvks[i].String() < gvks[j].String()
+	})
+	return gvks
 }
 
 // RemoveFromMap removes the given GVKs from the cache.
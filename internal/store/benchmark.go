@@ -0,0 +1,112 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// benchmarkNamespaces is the number of namespaces the generated objects
+	// are spread over.
+	benchmarkNamespaces = 100
+	// benchmarkNodes is the number of nodes generated pods are spread over.
+	benchmarkNodes = 1000
+)
+
+// populateBenchmark populates the store with the generated objects of the
+// given namespace of the given resource.
+func (b *Builder) populateBenchmark(resourceName string, expectedType interface{}, store cache.Store, namespace string) {
+	objects := benchmarkObjects(expectedType, resourceName, b.benchmarkObjects[resourceName])
+	if err := store.Replace(namespaceObjects(objects, namespace), ""); err != nil {
+		klog.ErrorS(err, "Failed to populate store with benchmark objects", "resource", resourceName)
+	}
+}
+
+// benchmarkObjects returns count generated objects of the type of
+// expectedType. The objects are spread over benchmarkNamespaces namespaces
+// and carry labels and annotations, so that the labels and annotations
+// metric families can be benchmarked with allow lists as well.
+func benchmarkObjects(expectedType interface{}, resource string, count int) []interface{} {
+	t := reflect.TypeOf(expectedType)
+	if t == nil || t.Kind() != reflect.Ptr {
+		return nil
+	}
+	objects := make([]interface{}, 0, count)
+	for i := 0; i < count; i++ {
+		obj := reflect.New(t.Elem()).Interface()
+		m, err := meta.Accessor(obj)
+		if err != nil {
+			return nil
+		}
+		name := fmt.Sprintf("%s-%d", resource, i)
+		m.SetName(name)
+		m.SetNamespace("benchmark-" + strconv.Itoa(i%benchmarkNamespaces))
+		m.SetUID(types.UID("benchmark-" + name))
+		m.SetResourceVersion("1")
+		m.SetCreationTimestamp(metav1.Unix(1500000000, 0))
+		m.SetLabels(map[string]string{"app": name, "benchmark": "true"})
+		m.SetAnnotations(map[string]string{"benchmark/index": strconv.Itoa(i)})
+		if p, ok := obj.(*v1.Pod); ok {
+			populateBenchmarkPod(p, i)
+		}
+		objects = append(objects, obj)
+	}
+	return objects
+}
+
+// populateBenchmarkPod makes the pod a scheduled, running pod with a single
+// ready container, the most common kind of pod of a cluster.
+func populateBenchmarkPod(p *v1.Pod, i int) {
+	p.Spec.NodeName = "benchmark-node-" + strconv.Itoa(i%benchmarkNodes)
+	p.Spec.Containers = []v1.Container{{
+		Name:  "app",
+		Image: "registry.k8s.io/pause:3.9",
+		Resources: v1.ResourceRequirements{
+			Requests: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("100m"),
+				v1.ResourceMemory: resource.MustParse("128Mi"),
+			},
+			Limits: v1.ResourceList{
+				v1.ResourceMemory: resource.MustParse("128Mi"),
+			},
+		},
+	}}
+	p.Status.Phase = v1.PodRunning
+	p.Status.PodIP = fmt.Sprintf("10.%d.%d.%d", i>>16&0xff, i>>8&0xff, i&0xff)
+	p.Status.StartTime = &metav1.Time{Time: p.CreationTimestamp.Time}
+	p.Status.Conditions = []v1.PodCondition{
+		{Type: v1.PodScheduled, Status: v1.ConditionTrue},
+		{Type: v1.PodReady, Status: v1.ConditionTrue},
+	}
+	p.Status.ContainerStatuses = []v1.ContainerStatus{{
+		Name:  "app",
+		Image: "registry.k8s.io/pause:3.9",
+		Ready: true,
+		State: v1.ContainerState{Running: &v1.ContainerStateRunning{StartedAt: p.CreationTimestamp}},
+	}}
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+
+	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
+	metricsstore "k8s.io/kube-state-metrics/v2/pkg/metrics_store"
+)
+
+func TestPopulateBenchmark(t *testing.T) {
+	b := NewBuilder()
+	if err := b.WithBenchmarkObjects(map[string]int{"unicorns": 1}); err == nil {
+		t.Error("expected an error for an unknown resource")
+	}
+	if err := b.WithBenchmarkObjects(map[string]int{"pods": 250}); err != nil {
+		t.Fatal(err)
+	}
+
+	families := podMetricFamilies(nil, nil, podFamilyOptions{})
+	s := metricsstore.NewMetricsStore(
+		generator.ExtractMetricFamilyHeaders(families),
+		generator.ComposeMetricGenFuncs(families),
+	)
+	b.populateBenchmark("pods", &v1.Pod{}, s, "benchmark-1")
+	if !s.HasSynced() {
+		t.Error("expected the store to have synced")
+	}
+	var out strings.Builder
+	if err := metricsstore.NewMetricsWriter(s).WriteAll(&out); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`kube_pod_status_phase{namespace="benchmark-1",pod="pods-101",uid="benchmark-pods-101",phase="Running"} 1`,
+		`kube_pod_container_status_ready{namespace="benchmark-1",pod="pods-1",uid="benchmark-pods-1",container="app"} 1`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %s, got:\n%s", want, out.String())
+		}
+	}
+	if n := strings.Count(out.String(), "\nkube_pod_info{"); n != 3 {
+		t.Errorf("expected the 3 pods of namespace benchmark-1, got %d", n)
+	}
+}
@@ -19,7 +19,11 @@ package store
 import (
 	"context"
 	"fmt"
+	"maps"
+	"os"
 	"reflect"
+	"regexp"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -34,16 +38,23 @@ import (
 	coordinationv1 "k8s.io/api/coordination/v1"
 	v1 "k8s.io/api/core/v1"
 	discoveryv1 "k8s.io/api/discovery/v1"
+	eventsv1 "k8s.io/api/events/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	nodev1 "k8s.io/api/node/v1"
 	policyv1 "k8s.io/api/policy/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
 	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 
 	ksmtypes "k8s.io/kube-state-metrics/v2/pkg/builder/types"
 	"k8s.io/kube-state-metrics/v2/pkg/customresource"
+	"k8s.io/kube-state-metrics/v2/pkg/httpsd"
+	"k8s.io/kube-state-metrics/v2/pkg/metric"
 	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
 	metricsstore "k8s.io/kube-state-metrics/v2/pkg/metrics_store"
 	"k8s.io/kube-state-metrics/v2/pkg/options"
@@ -66,14 +77,18 @@ var _ ksmtypes.BuilderInterface = &Builder{}
 // (https://en.wikipedia.org/wiki/Builder_pattern).
 type Builder struct {
 	kubeClient            clientset.Interface
+	metadataClient        metadata.Interface
 	customResourceClients map[string]interface{}
 	namespaces            options.NamespaceList
 	// namespaceFilter is inside fieldSelectorFilter
 	fieldSelectorFilter           string
+	resourceFieldSelectors        map[string]string
+	resourceListOptions           options.ResourceListOptions
 	ctx                           context.Context
 	enabledResources              []string
 	familyGeneratorFilter         generator.FamilyGeneratorFilter
 	listWatchMetrics              *watch.ListWatchMetrics
+	objectChurnMetrics            *watch.ObjectChurnMetrics
 	shardingMetrics               *sharding.Metrics
 	shard                         int32
 	totalShards                   int
@@ -81,13 +96,53 @@ type Builder struct {
 	buildCustomResourceStoresFunc ksmtypes.BuildCustomResourceStoresFunc
 	allowAnnotationsList          map[string][]string
 	allowLabelsList               map[string][]string
+	allowAnnotationsWildcard      []string
+	allowLabelsWildcard           []string
 	useAPIServerCache             bool
-	utilOptions                   *options.Options
+	ignoreAnnotation              string
+	namespaceDenylistPatterns     []*regexp.Regexp
+	podGCThreshold                time.Duration
+	storeShards                   int
+	metricPrefix                  string
+	familyRenames                 map[string]string
+	exemplarFamilies              map[string]struct{}
+	exemplarTraceIDAnnotation     string
+	labelValueLimit               metric.LabelValueLimit
+	timestampPrecision            metric.TimestampPrecision
+	conditionLimit                int
+	conditionTypeLimit            int
+	metadataOnlyResources         map[string]struct{}
+	podFamilyOptions              podFamilyOptions
+	nodeFamilyOptions             nodeFamilyOptions
+	replicaSetFamilyOptions       replicaSetFamilyOptions
+	snapshotDir                   string
+	snapshotInterval              time.Duration
+	snapshotReplayDir             string
+	snapshots                     *snapshotWriter
+	listPacingMetrics             *watch.ListPacingMetrics
+	listPacer                     *watch.ListPacer
+	stalenessTracker              *watch.StalenessTracker
+	writeTracker                  *watch.WriteTracker
+	notifier                      *watch.Notifier
+	podTargets                    *httpsd.PodTargets
+	cacheDir                      string
+	cacheInterval                 time.Duration
+	cache                         *snapshotWriter
+	benchmarkObjects              map[string]int
+	filterMetrics                 *watch.FilterMetrics
+	ownerKindsAllowList           map[string][]string
+	ownerKindsDenyList            map[string][]string
+	// updateTimes tracks the update times of the objects of the resources
+	// whose last update time family is enabled.
+	updateTimes map[string]*updateTimeTracker
+	utilOptions *options.Options
 }
 
 // NewBuilder returns a new builder.
 func NewBuilder() *Builder {
-	b := &Builder{}
+	b := &Builder{
+		metricPrefix: generator.DefaultNamePrefix,
+	}
 	return b
 }
 
@@ -102,19 +157,33 @@ func (b *Builder) WithUtilOptions(opts *options.Options) {
 // WithMetrics sets the metrics property of a Builder.
 func (b *Builder) WithMetrics(r prometheus.Registerer) {
 	b.listWatchMetrics = watch.NewListWatchMetrics(r)
+	b.objectChurnMetrics = watch.NewObjectChurnMetrics(r)
 	b.shardingMetrics = sharding.NewShardingMetrics(r)
+	b.listPacingMetrics = watch.NewListPacingMetrics(r)
+	b.filterMetrics = watch.NewFilterMetrics(r)
 }
 
-// WithEnabledResources sets the enabledResources property of a Builder.
+// WithEnabledResources adds the given resources to the enabledResources
+// property of a Builder. Resources which are enabled already are skipped, so
+// that the custom resources can be added again whenever they are discovered.
 func (b *Builder) WithEnabledResources(r []string) error {
 	for _, resource := range r {
 		if !resourceExists(resource) {
-			return fmt.Errorf("resource %s does not exist. Available resources: %s", resource, strings.Join(availableResources(), ","))
+			return fmt.Errorf("resource %s does not exist. Available resources: %s", resource, strings.Join(AvailableResources(), ","))
 		}
 	}
 
+	enabled := make(map[string]struct{}, len(b.enabledResources))
+	for _, resource := range b.enabledResources {
+		enabled[resource] = struct{}{}
+	}
 	var sortedResources []string
-	sortedResources = append(sortedResources, r...)
+	for _, resource := range r {
+		if _, ok := enabled[resource]; !ok {
+			enabled[resource] = struct{}{}
+			sortedResources = append(sortedResources, resource)
+		}
+	}
 
 	sort.Strings(sortedResources)
 
@@ -127,6 +196,19 @@ func (b *Builder) WithFieldSelectorFilter(fieldSelectorFilter string) {
 	b.fieldSelectorFilter = fieldSelectorFilter
 }
 
+// WithResourceFieldSelectors sets the field selectors which are used instead
+// of the fieldSelectorFilter for specific resources. They are expected to
+// include the fieldSelectorFilter already.
+func (b *Builder) WithResourceFieldSelectors(fieldSelectors map[string]string) {
+	b.resourceFieldSelectors = fieldSelectors
+}
+
+// WithResourceListOptions sets the list options of specific resources,
+// which take precedence over WithUsingAPIServerCache.
+func (b *Builder) WithResourceListOptions(listOptions options.ResourceListOptions) {
+	b.resourceListOptions = listOptions
+}
+
 // WithNamespaces sets the namespaces property of a Builder.
 func (b *Builder) WithNamespaces(n options.NamespaceList) {
 	b.namespaces = n
@@ -157,6 +239,12 @@ func (b *Builder) WithKubeClient(c clientset.Interface) {
 	b.kubeClient = c
 }
 
+// WithMetadataClient sets the client the objects of the resources configured
+// by WithMetadataOnlyResources are watched by.
+func (b *Builder) WithMetadataClient(c metadata.Interface) {
+	b.metadataClient = c
+}
+
 // WithCustomResourceClients sets the customResourceClients property of a Builder.
 func (b *Builder) WithCustomResourceClients(cs map[string]interface{}) {
 	b.customResourceClients = cs
@@ -167,6 +255,223 @@ func (b *Builder) WithUsingAPIServerCache(u bool) {
 	b.useAPIServerCache = u
 }
 
+// WithIgnoreAnnotation configures the annotation key which excludes objects
+// from metric generation when set to "true". An empty key disables it.
+func (b *Builder) WithIgnoreAnnotation(key string) {
+	b.ignoreAnnotation = key
+}
+
+// WithNamespaceDenylistPatterns configures regular expressions of namespaces
+// whose objects are excluded from metric generation. Unlike exact names, which
+// are excluded via the field selector, they are evaluated in the store.
+func (b *Builder) WithNamespaceDenylistPatterns(patterns []*regexp.Regexp) {
+	b.namespaceDenylistPatterns = patterns
+}
+
+// WithStoreShards configures the number of shards the metrics of each store
+// are split into by namespace, to reduce the lock contention between informer
+// events and scrapes.
+func (b *Builder) WithStoreShards(shards int) {
+	b.storeShards = shards
+}
+
+// WithPodGCThreshold configures the duration after which Succeeded and Failed
+// pods are excluded from metric generation. A threshold of 0 disables it.
+func (b *Builder) WithPodGCThreshold(threshold time.Duration) {
+	b.podGCThreshold = threshold
+}
+
+// WithSplitTerminalPods configures whether Succeeded and Failed pods are
+// served by kube_pod_terminal_info instead of kube_pod_info.
+func (b *Builder) WithSplitTerminalPods(split bool) {
+	b.podFamilyOptions.splitTerminalPods = split
+}
+
+// WithLastTerminatedReasonLimit configures the maximum number of distinct
+// reasons of kube_pod_container_status_last_terminated_info. Further reasons
+// are served as "Other". A limit of 0 disables it.
+func (b *Builder) WithLastTerminatedReasonLimit(limit int) {
+	b.podFamilyOptions.lastTerminatedReasonLimit = limit
+}
+
+// WithPodStatusReasons configures the reasons served by kube_pod_status_reason.
+// The default reasons are served if reasons is empty.
+func (b *Builder) WithPodStatusReasons(reasons []string) {
+	b.podFamilyOptions.statusReasons = reasons
+}
+
+// WithNodeImageLimit configures the maximum number of images per node served
+// by kube_node_image_size_bytes. A limit of 0 disables it.
+func (b *Builder) WithNodeImageLimit(limit int) {
+	b.nodeFamilyOptions.imageLimit = limit
+}
+
+// WithReplicaSetDeploymentLabel configures whether the name of the deployment
+// which controls a replicaset is added as deployment label to all replicaset
+// metric families.
+func (b *Builder) WithReplicaSetDeploymentLabel(enabled bool) {
+	b.replicaSetFamilyOptions.deploymentLabel = enabled
+}
+
+// WithMetricPrefix configures the prefix which replaces the default "kube_"
+// prefix of the metric family names.
+func (b *Builder) WithMetricPrefix(prefix string) error {
+	if !validMetricPrefixRE.MatchString(prefix) {
+		return fmt.Errorf("invalid metric prefix %q", prefix)
+	}
+	b.metricPrefix = prefix
+	return nil
+}
+
+// WithNamingConvention configures the naming convention of the metric
+// families, one of NamingConventionV2 or NamingConventionV3.
+func (b *Builder) WithNamingConvention(convention string) error {
+	renames, err := FamilyRenames(convention)
+	if err != nil {
+		return err
+	}
+	b.familyRenames = renames
+	return nil
+}
+
+// WithLabelValueLimit configures the maximum length of label values of the
+// metric families and how longer values are handled.
+func (b *Builder) WithLabelValueLimit(limit metric.LabelValueLimit) error {
+	if err := limit.Validate(); err != nil {
+		return err
+	}
+	b.labelValueLimit = limit
+	return nil
+}
+
+// WithConditionLimit configures the maximum number of condition types per
+// object served by the kube_<resource>_status_condition families, and the
+// maximum number of distinct condition types per resource which are not
+// defined by Kubernetes. A limit of 0 disables it.
+func (b *Builder) WithConditionLimit(perObject, types int) error {
+	if perObject < 0 {
+		return fmt.Errorf("condition limit must not be negative, got %d", perObject)
+	}
+	if types < 0 {
+		return fmt.Errorf("condition type limit must not be negative, got %d", types)
+	}
+	b.conditionLimit = perObject
+	b.conditionTypeLimit = types
+	return nil
+}
+
+// WithTimestampPrecision configures the precision of the values of metrics
+// which are timestamps.
+func (b *Builder) WithTimestampPrecision(precision metric.TimestampPrecision) error {
+	if err := precision.Validate(); err != nil {
+		return err
+	}
+	b.timestampPrecision = precision
+	return nil
+}
+
+// WithExemplars configures the metric families whose metrics carry exemplars
+// with the UID of their object, and the annotation of the trace ID added to
+// the exemplars, if any.
+func (b *Builder) WithExemplars(families map[string]struct{}, traceIDAnnotation string) {
+	b.exemplarFamilies = families
+	b.exemplarTraceIDAnnotation = traceIDAnnotation
+}
+
+// WithSnapshots configures the directory the objects of all stores are written
+// to every interval, to be replayed with WithSnapshotReplay.
+func (b *Builder) WithSnapshots(dir string, interval time.Duration) {
+	b.snapshotDir = dir
+	b.snapshotInterval = interval
+}
+
+// WithListConcurrency limits the concurrent list requests of all stores to
+// the given maximum, adapted to the responses of the apiserver. 0 disables
+// the limit.
+func (b *Builder) WithListConcurrency(maxConcurrency int) {
+	b.listPacer = nil
+	if maxConcurrency > 0 {
+		b.listPacer = watch.NewListPacer(maxConcurrency, b.listPacingMetrics)
+	}
+}
+
+// WithStalenessTracker configures the tracker which records the results of
+// the list and watch requests of all stores, to detect stale metrics.
+func (b *Builder) WithStalenessTracker(t *watch.StalenessTracker) {
+	b.stalenessTracker = t
+}
+
+// WithWriteTracker configures the tracker which records successful writes to
+// the stores, to check that the events of the reflectors are processed.
+func (b *Builder) WithWriteTracker(t *watch.WriteTracker) {
+	b.writeTracker = t
+}
+
+// WithPodTargets configures the pod targets of the HTTP service discovery,
+// which track the pods of the stores.
+func (b *Builder) WithPodTargets(t *httpsd.PodTargets) {
+	b.podTargets = t
+}
+
+// WithNotifier configures the notifier which is notified about the changes
+// of the objects of the stores of the resources it watches.
+func (b *Builder) WithNotifier(n *watch.Notifier) {
+	b.notifier = n
+}
+
+// WithCache configures the directory the objects of all stores are cached in
+// every interval, together with the last observed resourceVersions. Stores are
+// populated from the cache when they are built and watch from the cached
+// resourceVersion instead of listing the objects, if possible.
+func (b *Builder) WithCache(dir string, interval time.Duration) {
+	b.cacheDir = dir
+	b.cacheInterval = interval
+}
+
+// WithSnapshotReplay configures the directory of a snapshot the stores are
+// populated from, instead of listing and watching the objects.
+func (b *Builder) WithSnapshotReplay(dir string) {
+	b.snapshotReplayDir = dir
+}
+
+// WithBenchmarkObjects configures the number of generated objects the stores
+// of each resource are populated with, instead of listing and watching the
+// objects.
+func (b *Builder) WithBenchmarkObjects(counts map[string]int) error {
+	for resource := range counts {
+		if !resourceExists(resource) {
+			return fmt.Errorf("resource %s does not exist. Available resources: %s", resource, strings.Join(AvailableResources(), ","))
+		}
+	}
+	b.benchmarkObjects = counts
+	return nil
+}
+
+// WithMetadataOnlyResources configures the resources whose objects are watched
+// by their metadata only, so that e.g. the values of secrets are never held in
+// memory. Only the families generated from the metadata of their objects are
+// served for these resources.
+func (b *Builder) WithMetadataOnlyResources(resources []string) error {
+	set := make(map[string]struct{}, len(resources))
+	for _, resource := range resources {
+		if _, ok := metadataOnlyFamilies[resource]; !ok {
+			return fmt.Errorf("resource %s can't be watched by metadata only. Supported resources: %s", resource, strings.Join(slices.Sorted(maps.Keys(metadataOnlyFamilies)), ","))
+		}
+		set[resource] = struct{}{}
+	}
+	b.metadataOnlyResources = set
+	return nil
+}
+
+// WithOwnerKinds configures the owner reference kinds objects are filtered
+// by, per resource. An object is only kept if one of its owners has a kind of
+// the allowlist, if one is set, and none of its owners has a kind of the
+// denylist. The '*' key applies to all resources which are not set explicitly.
+func (b *Builder) WithOwnerKinds(allowList, denyList map[string][]string) {
+	b.ownerKindsAllowList = allowList
+	b.ownerKindsDenyList = denyList
+}
+
 // WithFamilyGeneratorFilter configures the family generator filter which decides which
 // metrics are to be exposed by the store build by the Builder.
 func (b *Builder) WithFamilyGeneratorFilter(l generator.FamilyGeneratorFilter) {
@@ -208,9 +513,13 @@ func (b *Builder) WithCustomResourceStoreFactories(fs ...customresource.Registry
 			klog.InfoS("Updating store", "GVR", gvrString)
 		}
 		availableStores[gvrString] = func(b *Builder) []cache.Store {
+			metricFamilies := f.MetricFamilyGenerators()
+			if af, ok := f.(customresource.AllowListRegistryFactory); ok {
+				metricFamilies = append(metricFamilies, af.AllowListMetricFamilyGenerators(b.allowAnnotationsWildcard, b.allowLabelsWildcard)...)
+			}
 			return b.buildCustomResourceStoresFunc(
 				f.Name(),
-				f.MetricFamilyGenerators(),
+				metricFamilies,
 				f.ExpectedType(),
 				f.ListWatch,
 				b.useAPIServerCache,
@@ -220,41 +529,50 @@ func (b *Builder) WithCustomResourceStoreFactories(fs ...customresource.Registry
 }
 
 // allowList validates the given map and checks if the resources exists.
-// If there is a '*' as key, return new map with all enabled resources.
-func (b *Builder) allowList(list map[string][]string) (map[string][]string, error) {
+// If there is a '*' as key, return new map with all enabled resources, where
+// resources which are specified explicitly override the '*' entry. The '*'
+// entry is returned separately, to be applied to custom resources.
+func (b *Builder) allowList(list map[string][]string) (map[string][]string, []string, error) {
 	if len(list) == 0 {
-		return nil, nil
+		return nil, nil, nil
 	}
 
-	for l := range list {
+	for l, entries := range list {
 		if !resourceExists(l) && l != "*" {
-			return nil, fmt.Errorf("resource %s does not exist. Available resources: %s", l, strings.Join(availableResources(), ","))
+			return nil, nil, fmt.Errorf("resource %s does not exist. Available resources: %s", l, strings.Join(AvailableResources(), ","))
+		}
+		if err := ValidateAllowList(entries); err != nil {
+			return nil, nil, fmt.Errorf("resource %s: %w", l, err)
 		}
 	}
 
-	// "*" takes precedence over other specifications
 	allowedList, ok := list["*"]
 	if !ok {
-		return list, nil
+		return list, nil, nil
 	}
 	m := make(map[string][]string)
 	for _, resource := range b.enabledResources {
 		m[resource] = allowedList
 	}
-	return m, nil
+	for resource, l := range list {
+		if resource != "*" {
+			m[resource] = l
+		}
+	}
+	return m, allowedList, nil
 }
 
 // WithAllowAnnotations configures which annotations can be returned for metrics
 func (b *Builder) WithAllowAnnotations(annotations map[string][]string) error {
 	var err error
-	b.allowAnnotationsList, err = b.allowList(annotations)
+	b.allowAnnotationsList, b.allowAnnotationsWildcard, err = b.allowList(annotations)
 	return err
 }
 
 // WithAllowLabels configures which labels can be returned for metrics
 func (b *Builder) WithAllowLabels(labels map[string][]string) error {
 	var err error
-	b.allowLabelsList, err = b.allowList(labels)
+	b.allowLabelsList, b.allowLabelsWildcard, err = b.allowList(labels)
 	return err
 }
 
@@ -268,6 +586,7 @@ func (b *Builder) Build() metricsstore.MetricsWriterList {
 
 	var metricsWriters metricsstore.MetricsWriterList
 	var activeStoreNames []string
+	b.startSnapshots()
 
 	for _, c := range b.enabledResources {
 		// availableStores[c]是前面默认的
@@ -276,7 +595,7 @@ func (b *Builder) Build() metricsstore.MetricsWriterList {
 			// 封装metricsstore.MetricsWriterList
 			stores := cacheStoresToMetricStores(constructor(b))
 			activeStoreNames = append(activeStoreNames, c)
-			metricsWriters = append(metricsWriters, metricsstore.NewMetricsWriter(stores...))
+			metricsWriters = append(metricsWriters, metricsstore.NewResourceMetricsWriter(c, stores...))
 		}
 	}
 
@@ -297,6 +616,7 @@ func (b *Builder) BuildStores() [][]cache.Store {
 
 	var allStores [][]cache.Store
 	var activeStoreNames []string
+	b.startSnapshots()
 
 	for _, c := range b.enabledResources {
 		constructor, ok := availableStores[c]
@@ -318,10 +638,14 @@ var availableStores = map[string]func(f *Builder) []cache.Store{
 	"configmaps":                      func(b *Builder) []cache.Store { return b.buildConfigMapStores() },
 	"clusterrolebindings":             func(b *Builder) []cache.Store { return b.buildClusterRoleBindingStores() },
 	"cronjobs":                        func(b *Builder) []cache.Store { return b.buildCronJobStores() },
+	"csidrivers":                      func(b *Builder) []cache.Store { return b.buildCSIDriverStores() },
+	"csinodes":                        func(b *Builder) []cache.Store { return b.buildCSINodeStores() },
+	"csistoragecapacities":            func(b *Builder) []cache.Store { return b.buildCSIStorageCapacityStores() },
 	"daemonsets":                      func(b *Builder) []cache.Store { return b.buildDaemonSetStores() },
 	"deployments":                     func(b *Builder) []cache.Store { return b.buildDeploymentStores() },
 	"endpoints":                       func(b *Builder) []cache.Store { return b.buildEndpointsStores() },
 	"endpointslices":                  func(b *Builder) []cache.Store { return b.buildEndpointSlicesStores() },
+	"events":                          func(b *Builder) []cache.Store { return b.buildEventStores() },
 	"horizontalpodautoscalers":        func(b *Builder) []cache.Store { return b.buildHPAStores() },
 	"ingresses":                       func(b *Builder) []cache.Store { return b.buildIngressStores() },
 	"ingressclasses":                  func(b *Builder) []cache.Store { return b.buildIngressClassStores() },
@@ -336,10 +660,12 @@ var availableStores = map[string]func(f *Builder) []cache.Store{
 	"persistentvolumes":               func(b *Builder) []cache.Store { return b.buildPersistentVolumeStores() },
 	"poddisruptionbudgets":            func(b *Builder) []cache.Store { return b.buildPodDisruptionBudgetStores() },
 	"pods":                            func(b *Builder) []cache.Store { return b.buildPodStores() },
+	"priorityclasses":                 func(b *Builder) []cache.Store { return b.buildPriorityClassStores() },
 	"replicasets":                     func(b *Builder) []cache.Store { return b.buildReplicaSetStores() },
 	"replicationcontrollers":          func(b *Builder) []cache.Store { return b.buildReplicationControllerStores() },
 	"resourcequotas":                  func(b *Builder) []cache.Store { return b.buildResourceQuotaStores() },
 	"roles":                           func(b *Builder) []cache.Store { return b.buildRoleStores() },
+	"runtimeclasses":                  func(b *Builder) []cache.Store { return b.buildRuntimeClassStores() },
 	"rolebindings":                    func(b *Builder) []cache.Store { return b.buildRoleBindingStores() },
 	"secrets":                         func(b *Builder) []cache.Store { return b.buildSecretStores() },
 	"serviceaccounts":                 func(b *Builder) []cache.Store { return b.buildServiceAccountStores() },
@@ -355,194 +681,298 @@ func resourceExists(name string) bool {
 	return ok
 }
 
-func availableResources() []string {
+// AvailableResources returns the sorted names of all resources which can be
+// enabled.
+func AvailableResources() []string {
 	c := []string{}
 	for name := range availableStores {
 		c = append(c, name)
 	}
+	sort.Strings(c)
 	return c
 }
 
 func (b *Builder) buildConfigMapStores() []cache.Store {
-	return b.buildStoresFunc(configMapMetricFamilies(b.allowAnnotationsList["configmaps"], b.allowLabelsList["configmaps"]), &v1.ConfigMap{}, createConfigMapListWatch, b.useAPIServerCache)
+	families := configMapMetricFamilies(b.allowAnnotationsList["configmaps"], b.allowLabelsList["configmaps"])
+	if _, ok := b.metadataOnlyResources["configmaps"]; ok {
+		families = metadataFamilyGenerators("configmaps", families, func(m metav1.ObjectMeta) interface{} { return &v1.ConfigMap{ObjectMeta: m} })
+		return b.buildResourceStores("configmaps", families, &metav1.PartialObjectMetadata{}, b.metadataListWatchFunc(v1.SchemeGroupVersion.WithResource("configmaps")), b.useAPIServerCache)
+	}
+	return b.buildResourceStores("configmaps", families, &v1.ConfigMap{}, createConfigMapListWatch, b.useAPIServerCache)
 }
 
 func (b *Builder) buildCronJobStores() []cache.Store {
-	return b.buildStoresFunc(cronJobMetricFamilies(b.allowAnnotationsList["cronjobs"], b.allowLabelsList["cronjobs"]), &batchv1.CronJob{}, createCronJobListWatch, b.useAPIServerCache)
+	return b.buildResourceStores("cronjobs", cronJobMetricFamilies(b.allowAnnotationsList["cronjobs"], b.allowLabelsList["cronjobs"]), &batchv1.CronJob{}, createCronJobListWatch, b.useAPIServerCache)
+}
+
+func (b *Builder) buildCSIDriverStores() []cache.Store {
+	return b.buildResourceStores("csidrivers", csiDriverMetricFamilies(b.allowAnnotationsList["csidrivers"], b.allowLabelsList["csidrivers"]), &storagev1.CSIDriver{}, createCSIDriverListWatch, b.useAPIServerCache)
+}
+
+func (b *Builder) buildCSINodeStores() []cache.Store {
+	return b.buildResourceStores("csinodes", csiNodeMetricFamilies(b.allowAnnotationsList["csinodes"], b.allowLabelsList["csinodes"]), &storagev1.CSINode{}, createCSINodeListWatch, b.useAPIServerCache)
+}
+
+func (b *Builder) buildCSIStorageCapacityStores() []cache.Store {
+	return b.buildResourceStores("csistoragecapacities", csiStorageCapacityMetricFamilies(b.allowAnnotationsList["csistoragecapacities"], b.allowLabelsList["csistoragecapacities"]), &storagev1.CSIStorageCapacity{}, createCSIStorageCapacityListWatch, b.useAPIServerCache)
 }
 
 func (b *Builder) buildDaemonSetStores() []cache.Store {
-	return b.buildStoresFunc(daemonSetMetricFamilies(b.allowAnnotationsList["daemonsets"], b.allowLabelsList["daemonsets"]), &appsv1.DaemonSet{}, createDaemonSetListWatch, b.useAPIServerCache)
+	return b.buildResourceStores("daemonsets", daemonSetMetricFamilies(b.allowAnnotationsList["daemonsets"], b.allowLabelsList["daemonsets"]), &appsv1.DaemonSet{}, createDaemonSetListWatch, b.useAPIServerCache)
 }
 
 func (b *Builder) buildDeploymentStores() []cache.Store {
-	return b.buildStoresFunc(deploymentMetricFamilies(b.allowAnnotationsList["deployments"], b.allowLabelsList["deployments"]), &appsv1.Deployment{}, createDeploymentListWatch, b.useAPIServerCache)
+	return b.buildResourceStores("deployments", deploymentMetricFamilies(b.allowAnnotationsList["deployments"], b.allowLabelsList["deployments"]), &appsv1.Deployment{}, createDeploymentListWatch, b.useAPIServerCache)
 }
 
 func (b *Builder) buildEndpointsStores() []cache.Store {
-	return b.buildStoresFunc(endpointMetricFamilies(b.allowAnnotationsList["endpoints"], b.allowLabelsList["endpoints"]), &v1.Endpoints{}, createEndpointsListWatch, b.useAPIServerCache)
+	return b.buildResourceStores("endpoints", endpointMetricFamilies(b.allowAnnotationsList["endpoints"], b.allowLabelsList["endpoints"]), &v1.Endpoints{}, createEndpointsListWatch, b.useAPIServerCache)
 }
 
 func (b *Builder) buildEndpointSlicesStores() []cache.Store {
-	return b.buildStoresFunc(endpointSliceMetricFamilies(b.allowAnnotationsList["endpointslices"], b.allowLabelsList["endpointslices"]), &discoveryv1.EndpointSlice{}, createEndpointSliceListWatch, b.useAPIServerCache)
+	return b.buildResourceStores("endpointslices", endpointSliceMetricFamilies(b.allowAnnotationsList["endpointslices"], b.allowLabelsList["endpointslices"]), &discoveryv1.EndpointSlice{}, createEndpointSliceListWatch, b.useAPIServerCache)
+}
+
+func (b *Builder) buildEventStores() []cache.Store {
+	return b.buildEventAggregatorStores("events", eventMetricFamilies, createEventListWatch, b.useAPIServerCache)
 }
 
 func (b *Builder) buildHPAStores() []cache.Store {
-	return b.buildStoresFunc(hpaMetricFamilies(b.allowAnnotationsList["horizontalpodautoscalers"], b.allowLabelsList["horizontalpodautoscalers"]), &autoscaling.HorizontalPodAutoscaler{}, createHPAListWatch, b.useAPIServerCache)
+	return b.buildResourceStores("horizontalpodautoscalers", hpaMetricFamilies(b.allowAnnotationsList["horizontalpodautoscalers"], b.allowLabelsList["horizontalpodautoscalers"]), &autoscaling.HorizontalPodAutoscaler{}, createHPAListWatch, b.useAPIServerCache)
 }
 
 func (b *Builder) buildIngressStores() []cache.Store {
-	return b.buildStoresFunc(ingressMetricFamilies(b.allowAnnotationsList["ingresses"], b.allowLabelsList["ingresses"]), &networkingv1.Ingress{}, createIngressListWatch, b.useAPIServerCache)
+	return b.buildResourceStores("ingresses", ingressMetricFamilies(b.allowAnnotationsList["ingresses"], b.allowLabelsList["ingresses"]), &networkingv1.Ingress{}, createIngressListWatch, b.useAPIServerCache)
 }
 
 func (b *Builder) buildJobStores() []cache.Store {
-	return b.buildStoresFunc(jobMetricFamilies(b.allowAnnotationsList["jobs"], b.allowLabelsList["jobs"]), &batchv1.Job{}, createJobListWatch, b.useAPIServerCache)
+	return b.buildResourceStores("jobs", jobMetricFamilies(b.allowAnnotationsList["jobs"], b.allowLabelsList["jobs"]), &batchv1.Job{}, createJobListWatch, b.useAPIServerCache)
 }
 
 func (b *Builder) buildLimitRangeStores() []cache.Store {
-	return b.buildStoresFunc(limitRangeMetricFamilies, &v1.LimitRange{}, createLimitRangeListWatch, b.useAPIServerCache)
+	return b.buildResourceStores("limitranges", limitRangeMetricFamilies, &v1.LimitRange{}, createLimitRangeListWatch, b.useAPIServerCache)
 }
 
 func (b *Builder) buildMutatingWebhookConfigurationStores() []cache.Store {
-	return b.buildStoresFunc(mutatingWebhookConfigurationMetricFamilies, &admissionregistrationv1.MutatingWebhookConfiguration{}, createMutatingWebhookConfigurationListWatch, b.useAPIServerCache)
+	return b.buildResourceStores("mutatingwebhookconfigurations", mutatingWebhookConfigurationMetricFamilies, &admissionregistrationv1.MutatingWebhookConfiguration{}, createMutatingWebhookConfigurationListWatch, b.useAPIServerCache)
 }
 
 func (b *Builder) buildNamespaceStores() []cache.Store {
-	return b.buildStoresFunc(namespaceMetricFamilies(b.allowAnnotationsList["namespaces"], b.allowLabelsList["namespaces"]), &v1.Namespace{}, createNamespaceListWatch, b.useAPIServerCache)
+	return b.buildResourceStores("namespaces", namespaceMetricFamilies(b.allowAnnotationsList["namespaces"], b.allowLabelsList["namespaces"]), &v1.Namespace{}, createNamespaceListWatch, b.useAPIServerCache)
 }
 
 func (b *Builder) buildNetworkPolicyStores() []cache.Store {
-	return b.buildStoresFunc(networkPolicyMetricFamilies(b.allowAnnotationsList["networkpolicies"], b.allowLabelsList["networkpolicies"]), &networkingv1.NetworkPolicy{}, createNetworkPolicyListWatch, b.useAPIServerCache)
+	return b.buildResourceStores("networkpolicies", networkPolicyMetricFamilies(b.allowAnnotationsList["networkpolicies"], b.allowLabelsList["networkpolicies"]), &networkingv1.NetworkPolicy{}, createNetworkPolicyListWatch, b.useAPIServerCache)
 }
 
 func (b *Builder) buildNodeStores() []cache.Store {
-	return b.buildStoresFunc(nodeMetricFamilies(b.allowAnnotationsList["nodes"], b.allowLabelsList["nodes"]), &v1.Node{}, createNodeListWatch, b.useAPIServerCache)
+	return b.buildResourceStores("nodes", nodeMetricFamilies(b.allowAnnotationsList["nodes"], b.allowLabelsList["nodes"], b.nodeFamilyOptions), &v1.Node{}, createNodeListWatch, b.useAPIServerCache)
 }
 
 func (b *Builder) buildPersistentVolumeClaimStores() []cache.Store {
-	return b.buildStoresFunc(persistentVolumeClaimMetricFamilies(b.allowAnnotationsList["persistentvolumeclaims"], b.allowLabelsList["persistentvolumeclaims"]), &v1.PersistentVolumeClaim{}, createPersistentVolumeClaimListWatch, b.useAPIServerCache)
+	return b.buildResourceStores("persistentvolumeclaims", persistentVolumeClaimMetricFamilies(b.allowAnnotationsList["persistentvolumeclaims"], b.allowLabelsList["persistentvolumeclaims"]), &v1.PersistentVolumeClaim{}, createPersistentVolumeClaimListWatch, b.useAPIServerCache)
 }
 
 func (b *Builder) buildPersistentVolumeStores() []cache.Store {
-	return b.buildStoresFunc(persistentVolumeMetricFamilies(b.allowAnnotationsList["persistentvolumes"], b.allowLabelsList["persistentvolumes"]), &v1.PersistentVolume{}, createPersistentVolumeListWatch, b.useAPIServerCache)
+	return b.buildResourceStores("persistentvolumes", persistentVolumeMetricFamilies(b.allowAnnotationsList["persistentvolumes"], b.allowLabelsList["persistentvolumes"]), &v1.PersistentVolume{}, createPersistentVolumeListWatch, b.useAPIServerCache)
 }
 
 func (b *Builder) buildPodDisruptionBudgetStores() []cache.Store {
-	return b.buildStoresFunc(podDisruptionBudgetMetricFamilies(b.allowAnnotationsList["poddisruptionbudgets"], b.allowLabelsList["poddisruptionbudgets"]), &policyv1.PodDisruptionBudget{}, createPodDisruptionBudgetListWatch, b.useAPIServerCache)
+	return b.buildResourceStores("poddisruptionbudgets", podDisruptionBudgetMetricFamilies(b.allowAnnotationsList["poddisruptionbudgets"], b.allowLabelsList["poddisruptionbudgets"]), &policyv1.PodDisruptionBudget{}, createPodDisruptionBudgetListWatch, b.useAPIServerCache)
 }
 
 func (b *Builder) buildReplicaSetStores() []cache.Store {
-	return b.buildStoresFunc(replicaSetMetricFamilies(b.allowAnnotationsList["replicasets"], b.allowLabelsList["replicasets"]), &appsv1.ReplicaSet{}, createReplicaSetListWatch, b.useAPIServerCache)
+	return b.buildResourceStores("replicasets", replicaSetMetricFamilies(b.allowAnnotationsList["replicasets"], b.allowLabelsList["replicasets"], b.replicaSetFamilyOptions), &appsv1.ReplicaSet{}, createReplicaSetListWatch, b.useAPIServerCache)
 }
 
 func (b *Builder) buildReplicationControllerStores() []cache.Store {
-	return b.buildStoresFunc(replicationControllerMetricFamilies, &v1.ReplicationController{}, createReplicationControllerListWatch, b.useAPIServerCache)
+	return b.buildResourceStores("replicationcontrollers", replicationControllerMetricFamilies, &v1.ReplicationController{}, createReplicationControllerListWatch, b.useAPIServerCache)
 }
 
 func (b *Builder) buildResourceQuotaStores() []cache.Store {
-	return b.buildStoresFunc(resourceQuotaMetricFamilies(b.allowAnnotationsList["resourcequotas"], b.allowLabelsList["resourcequotas"]), &v1.ResourceQuota{}, createResourceQuotaListWatch, b.useAPIServerCache)
+	return b.buildResourceStores("resourcequotas", resourceQuotaMetricFamilies(b.allowAnnotationsList["resourcequotas"], b.allowLabelsList["resourcequotas"]), &v1.ResourceQuota{}, createResourceQuotaListWatch, b.useAPIServerCache)
+}
+
+func (b *Builder) buildRuntimeClassStores() []cache.Store {
+	return b.buildResourceStores("runtimeclasses", runtimeClassMetricFamilies(b.allowAnnotationsList["runtimeclasses"], b.allowLabelsList["runtimeclasses"]), &nodev1.RuntimeClass{}, createRuntimeClassListWatch, b.useAPIServerCache)
 }
 
 func (b *Builder) buildSecretStores() []cache.Store {
-	return b.buildStoresFunc(secretMetricFamilies(b.allowAnnotationsList["secrets"], b.allowLabelsList["secrets"]), &v1.Secret{}, createSecretListWatch, b.useAPIServerCache)
+	families := secretMetricFamilies(b.allowAnnotationsList["secrets"], b.allowLabelsList["secrets"])
+	if _, ok := b.metadataOnlyResources["secrets"]; ok {
+		families = metadataFamilyGenerators("secrets", families, func(m metav1.ObjectMeta) interface{} { return &v1.Secret{ObjectMeta: m} })
+		return b.buildResourceStores("secrets", families, &metav1.PartialObjectMetadata{}, b.metadataListWatchFunc(v1.SchemeGroupVersion.WithResource("secrets")), b.useAPIServerCache)
+	}
+	return b.buildResourceStores("secrets", families, &v1.Secret{}, createSecretListWatch, b.useAPIServerCache)
 }
 
 func (b *Builder) buildServiceAccountStores() []cache.Store {
-	return b.buildStoresFunc(serviceAccountMetricFamilies(b.allowAnnotationsList["serviceaccounts"], b.allowLabelsList["serviceaccounts"]), &v1.ServiceAccount{}, createServiceAccountListWatch, b.useAPIServerCache)
+	return b.buildResourceStores("serviceaccounts", serviceAccountMetricFamilies(b.allowAnnotationsList["serviceaccounts"], b.allowLabelsList["serviceaccounts"]), &v1.ServiceAccount{}, createServiceAccountListWatch, b.useAPIServerCache)
 }
 
 func (b *Builder) buildServiceStores() []cache.Store {
-	return b.buildStoresFunc(serviceMetricFamilies(b.allowAnnotationsList["services"], b.allowLabelsList["services"]), &v1.Service{}, createServiceListWatch, b.useAPIServerCache)
+	return b.buildResourceStores("services", serviceMetricFamilies(b.allowAnnotationsList["services"], b.allowLabelsList["services"]), &v1.Service{}, createServiceListWatch, b.useAPIServerCache)
 }
 
 func (b *Builder) buildStatefulSetStores() []cache.Store {
-	return b.buildStoresFunc(statefulSetMetricFamilies(b.allowAnnotationsList["statefulsets"], b.allowLabelsList["statefulsets"]), &appsv1.StatefulSet{}, createStatefulSetListWatch, b.useAPIServerCache)
+	return b.buildResourceStores("statefulsets", statefulSetMetricFamilies(b.allowAnnotationsList["statefulsets"], b.allowLabelsList["statefulsets"]), &appsv1.StatefulSet{}, createStatefulSetListWatch, b.useAPIServerCache)
 }
 
 func (b *Builder) buildStorageClassStores() []cache.Store {
-	return b.buildStoresFunc(storageClassMetricFamilies(b.allowAnnotationsList["storageclasses"], b.allowLabelsList["storageclasses"]), &storagev1.StorageClass{}, createStorageClassListWatch, b.useAPIServerCache)
+	return b.buildResourceStores("storageclasses", storageClassMetricFamilies(b.allowAnnotationsList["storageclasses"], b.allowLabelsList["storageclasses"]), &storagev1.StorageClass{}, createStorageClassListWatch, b.useAPIServerCache)
 }
 
 func (b *Builder) buildPodStores() []cache.Store {
-	return b.buildStoresFunc(podMetricFamilies(b.allowAnnotationsList["pods"], b.allowLabelsList["pods"]), &v1.Pod{}, createPodListWatch, b.useAPIServerCache)
+	return b.buildResourceStores("pods", podMetricFamilies(b.allowAnnotationsList["pods"], b.allowLabelsList["pods"], b.podFamilyOptions), &v1.Pod{}, createPodListWatch, b.useAPIServerCache)
+}
+
+func (b *Builder) buildPriorityClassStores() []cache.Store {
+	return b.buildResourceStores("priorityclasses", priorityClassMetricFamilies(b.allowAnnotationsList["priorityclasses"], b.allowLabelsList["priorityclasses"]), &schedulingv1.PriorityClass{}, createPriorityClassListWatch, b.useAPIServerCache)
 }
 
 func (b *Builder) buildCsrStores() []cache.Store {
 	// buildStoresFunc
-	return b.buildStoresFunc(csrMetricFamilies(b.allowAnnotationsList["certificatesigningrequests"],
+	return b.buildResourceStores("certificatesigningrequests", csrMetricFamilies(b.allowAnnotationsList["certificatesigningrequests"],
 		b.allowLabelsList["certificatesigningrequests"]),
 		&certv1.CertificateSigningRequest{},
 		createCSRListWatch, b.useAPIServerCache)
 }
 
 func (b *Builder) buildValidatingWebhookConfigurationStores() []cache.Store {
-	return b.buildStoresFunc(validatingWebhookConfigurationMetricFamilies, &admissionregistrationv1.ValidatingWebhookConfiguration{}, createValidatingWebhookConfigurationListWatch, b.useAPIServerCache)
+	return b.buildResourceStores("validatingwebhookconfigurations", validatingWebhookConfigurationMetricFamilies, &admissionregistrationv1.ValidatingWebhookConfiguration{}, createValidatingWebhookConfigurationListWatch, b.useAPIServerCache)
 }
 
 func (b *Builder) buildVolumeAttachmentStores() []cache.Store {
-	return b.buildStoresFunc(volumeAttachmentMetricFamilies, &storagev1.VolumeAttachment{}, createVolumeAttachmentListWatch, b.useAPIServerCache)
+	return b.buildResourceStores("volumeattachments", volumeAttachmentMetricFamilies(b.allowLabelsList["volumeattachments"]), &storagev1.VolumeAttachment{}, createVolumeAttachmentListWatch, b.useAPIServerCache)
 }
 
 func (b *Builder) buildLeasesStores() []cache.Store {
-	return b.buildStoresFunc(leaseMetricFamilies, &coordinationv1.Lease{}, createLeaseListWatch, b.useAPIServerCache)
+	return b.buildResourceStores("leases", leaseMetricFamilies, &coordinationv1.Lease{}, createLeaseListWatch, b.useAPIServerCache)
 }
 
 func (b *Builder) buildClusterRoleStores() []cache.Store {
-	return b.buildStoresFunc(clusterRoleMetricFamilies(b.allowAnnotationsList["clusterroles"], b.allowLabelsList["clusterroles"]), &rbacv1.ClusterRole{}, createClusterRoleListWatch, b.useAPIServerCache)
+	return b.buildResourceStores("clusterroles", clusterRoleMetricFamilies(b.allowAnnotationsList["clusterroles"], b.allowLabelsList["clusterroles"]), &rbacv1.ClusterRole{}, createClusterRoleListWatch, b.useAPIServerCache)
 }
 
 func (b *Builder) buildRoleStores() []cache.Store {
-	return b.buildStoresFunc(roleMetricFamilies(b.allowAnnotationsList["roles"], b.allowLabelsList["roles"]), &rbacv1.Role{}, createRoleListWatch, b.useAPIServerCache)
+	return b.buildResourceStores("roles", roleMetricFamilies(b.allowAnnotationsList["roles"], b.allowLabelsList["roles"]), &rbacv1.Role{}, createRoleListWatch, b.useAPIServerCache)
 }
 
 func (b *Builder) buildClusterRoleBindingStores() []cache.Store {
-	return b.buildStoresFunc(clusterRoleBindingMetricFamilies(b.allowAnnotationsList["clusterrolebindings"], b.allowLabelsList["clusterrolebindings"]), &rbacv1.ClusterRoleBinding{}, createClusterRoleBindingListWatch, b.useAPIServerCache)
+	return b.buildResourceStores("clusterrolebindings", clusterRoleBindingMetricFamilies(b.allowAnnotationsList["clusterrolebindings"], b.allowLabelsList["clusterrolebindings"]), &rbacv1.ClusterRoleBinding{}, createClusterRoleBindingListWatch, b.useAPIServerCache)
 }
 
 func (b *Builder) buildRoleBindingStores() []cache.Store {
-	return b.buildStoresFunc(roleBindingMetricFamilies(b.allowAnnotationsList["rolebindings"], b.allowLabelsList["rolebindings"]), &rbacv1.RoleBinding{}, createRoleBindingListWatch, b.useAPIServerCache)
+	return b.buildResourceStores("rolebindings", roleBindingMetricFamilies(b.allowAnnotationsList["rolebindings"], b.allowLabelsList["rolebindings"]), &rbacv1.RoleBinding{}, createRoleBindingListWatch, b.useAPIServerCache)
 }
 
 func (b *Builder) buildIngressClassStores() []cache.Store {
-	return b.buildStoresFunc(ingressClassMetricFamilies(b.allowAnnotationsList["ingressclasses"], b.allowLabelsList["ingressclasses"]), &networkingv1.IngressClass{}, createIngressClassListWatch, b.useAPIServerCache)
+	return b.buildResourceStores("ingressclasses", ingressClassMetricFamilies(b.allowAnnotationsList["ingressclasses"], b.allowLabelsList["ingressclasses"]), &networkingv1.IngressClass{}, createIngressClassListWatch, b.useAPIServerCache)
+}
+
+// buildResourceStores adds the last update time family of the given resource,
+// if it serves one, and builds the stores by the configured BuildStoresFunc.
+func (b *Builder) buildResourceStores(
+	resourceName string,
+	metricFamilies []generator.FamilyGenerator,
+	expectedType interface{},
+	listWatchFunc func(kubeClient clientset.Interface, ns string, fieldSelector string) cache.ListerWatcher,
+	useAPIServerCache bool,
+) []cache.Store {
+	if r, ok := updateTimeResources[resourceName]; ok {
+		tracker := newUpdateTimeTracker()
+		family := createLastUpdateTimeFamilyGenerator(resourceName, r, tracker)
+		if b.familyGeneratorFilter.Test(family) {
+			if b.updateTimes == nil {
+				b.updateTimes = map[string]*updateTimeTracker{}
+			}
+			b.updateTimes[resourceName] = tracker
+		}
+		// Some resources share their families, which must not be appended to.
+		metricFamilies = append(metricFamilies[:len(metricFamilies):len(metricFamilies)], family)
+	}
+	return b.buildStoresFunc(resourceName, metricFamilies, expectedType, listWatchFunc, useAPIServerCache)
 }
 
 func (b *Builder) buildStores(
+	resourceName string,
 	metricFamilies []generator.FamilyGenerator,
 	expectedType interface{},
 	listWatchFunc func(kubeClient clientset.Interface, ns string, fieldSelector string) cache.ListerWatcher,
 	useAPIServerCache bool,
 ) []cache.Store {
-	metricFamilies = generator.FilterFamilyGenerators(b.familyGeneratorFilter, metricFamilies)
-	composedMetricGenFuncs := generator.ComposeMetricGenFuncs(metricFamilies)
+	metricFamilies = b.filterFamilyGenerators(resourceName, metricFamilies)
+	eagerFamilies, lazyFamilies, lazyIndices := lazyFamilyGenerators(metricFamilies)
+	eagerFamilies, scrapeTimeFamilies, scrapeTimeIndices := scrapeTimeFamilyGenerators(eagerFamilies)
+	composedMetricGenFuncs := generator.ComposeMetricGenFuncs(eagerFamilies)
+	composedLazyMetricGenFuncs := generator.ComposeMetricGenFuncs(lazyFamilies)
+	composedScrapeTimeMetricGenFuncs := generator.ComposeMetricGenFuncs(scrapeTimeFamilies)
 	familyHeaders := generator.ExtractMetricFamilyHeaders(metricFamilies)
-
-	if b.namespaces.IsAllNamespaces() {
-		store := metricsstore.NewMetricsStore(
+	fieldSelector := b.fieldSelector(resourceName)
+	newStore := func() *metricsstore.MetricsStore {
+		store := metricsstore.NewShardedMetricsStore(
 			familyHeaders,
 			composedMetricGenFuncs,
+			b.storeShards,
 		)
-		if b.fieldSelectorFilter != "" {
-			klog.InfoS("FieldSelector is used", "fieldSelector", b.fieldSelectorFilter)
+		if len(lazyIndices) > 0 {
+			store.WithLazyFamilies(lazyIndices, keepMetadata, composedLazyMetricGenFuncs)
+		}
+		if len(scrapeTimeIndices) > 0 {
+			store.WithScrapeTimeFamilies(scrapeTimeIndices, composedScrapeTimeMetricGenFuncs)
+		}
+		return store
+	}
+
+	if b.namespaces.IsAllNamespaces() {
+		store := newStore()
+		if fieldSelector != "" {
+			klog.InfoS("FieldSelector is used", "fieldSelector", fieldSelector)
 		}
 		// 创建完store再创建listwatch对象，再startReflector
 		// 通过这里监听资源，kms重写了store的add，会导致kms执行相关资源的指标筛选（familyGenerator）和生成，
-		listWatcher := listWatchFunc(b.kubeClient, v1.NamespaceAll, b.fieldSelectorFilter)
-		b.startReflector(expectedType, store, listWatcher, useAPIServerCache)
+		listWatcher := listWatchFunc(b.kubeClient, v1.NamespaceAll, fieldSelector)
+		b.startReflector(resourceName, expectedType, store, listWatcher, v1.NamespaceAll, useAPIServerCache)
 		return []cache.Store{store}
 	}
 
 	stores := make([]cache.Store, 0, len(b.namespaces))
 	for _, ns := range b.namespaces {
-		store := metricsstore.NewMetricsStore(
+		store := newStore()
+		if fieldSelector != "" {
+			klog.InfoS("FieldSelector is used", "fieldSelector", fieldSelector)
+		}
+		listWatcher := listWatchFunc(b.kubeClient, ns, fieldSelector)
+		b.startReflector(resourceName, expectedType, store, listWatcher, ns, useAPIServerCache)
+		stores = append(stores, store)
+	}
+
+	return stores
+}
+
+// buildEventAggregatorStores is like buildStores, but feeds the reflectors into
+// an eventAggregator instead of the MetricsStore, as events are exposed as
+// aggregated counters rather than per object.
+func (b *Builder) buildEventAggregatorStores(
+	resourceName string,
+	metricFamilies []generator.FamilyGenerator,
+	listWatchFunc func(kubeClient clientset.Interface, ns string, fieldSelector string) cache.ListerWatcher,
+	useAPIServerCache bool,
+) []cache.Store {
+	metricFamilies = b.filterFamilyGenerators(resourceName, metricFamilies)
+	composedMetricGenFuncs := generator.ComposeMetricGenFuncs(metricFamilies)
+	familyHeaders := generator.ExtractMetricFamilyHeaders(metricFamilies)
+	fieldSelector := b.fieldSelector(resourceName)
+
+	stores := make([]cache.Store, 0, len(b.namespaces))
+	for _, ns := range b.namespaces {
+		store := metricsstore.NewShardedMetricsStore(
 			familyHeaders,
 			composedMetricGenFuncs,
+			b.storeShards,
 		)
-		if b.fieldSelectorFilter != "" {
-			klog.InfoS("FieldSelector is used", "fieldSelector", b.fieldSelectorFilter)
-		}
-		listWatcher := listWatchFunc(b.kubeClient, ns, b.fieldSelectorFilter)
-		b.startReflector(expectedType, store, listWatcher, useAPIServerCache)
+		listWatcher := listWatchFunc(b.kubeClient, ns, fieldSelector)
+		b.startReflector(resourceName, &eventsv1.Event{}, newEventAggregator(store), listWatcher, ns, useAPIServerCache)
 		stores = append(stores, store)
 	}
 
@@ -556,11 +986,6 @@ func (b *Builder) buildCustomResourceStores(resourceName string,
 	listWatchFunc func(customResourceClient interface{}, ns string, fieldSelector string) cache.ListerWatcher,
 	useAPIServerCache bool,
 ) []cache.Store {
-	metricFamilies = generator.FilterFamilyGenerators(b.familyGeneratorFilter, metricFamilies)
-	composedMetricGenFuncs := generator.ComposeMetricGenFuncs(metricFamilies)
-
-	familyHeaders := generator.ExtractMetricFamilyHeaders(metricFamilies)
-
 	gvr := util.GVRFromType(resourceName, expectedType)
 	var gvrString string
 	if gvr != nil {
@@ -568,34 +993,43 @@ func (b *Builder) buildCustomResourceStores(resourceName string,
 	} else {
 		gvrString = resourceName
 	}
+
+	metricFamilies = b.filterFamilyGenerators(gvrString, metricFamilies)
+	composedMetricGenFuncs := generator.ComposeMetricGenFuncs(metricFamilies)
+
+	familyHeaders := generator.ExtractMetricFamilyHeaders(metricFamilies)
+
 	customResourceClient, ok := b.customResourceClients[gvrString]
 	if !ok {
 		klog.InfoS("Custom resource client does not exist", "resourceName", resourceName)
 		return []cache.Store{}
 	}
+	fieldSelector := b.fieldSelector(gvrString)
 
 	if b.namespaces.IsAllNamespaces() {
-		store := metricsstore.NewMetricsStore(
+		store := metricsstore.NewShardedMetricsStore(
 			familyHeaders,
 			composedMetricGenFuncs,
+			b.storeShards,
 		)
-		if b.fieldSelectorFilter != "" {
-			klog.InfoS("FieldSelector is used", "fieldSelector", b.fieldSelectorFilter)
+		if fieldSelector != "" {
+			klog.InfoS("FieldSelector is used", "fieldSelector", fieldSelector)
 		}
-		listWatcher := listWatchFunc(customResourceClient, v1.NamespaceAll, b.fieldSelectorFilter)
-		b.startReflector(expectedType, store, listWatcher, useAPIServerCache)
+		listWatcher := listWatchFunc(customResourceClient, v1.NamespaceAll, fieldSelector)
+		b.startReflector(gvrString, expectedType, store, listWatcher, v1.NamespaceAll, useAPIServerCache)
 		return []cache.Store{store}
 	}
 
 	stores := make([]cache.Store, 0, len(b.namespaces))
 	for _, ns := range b.namespaces {
-		store := metricsstore.NewMetricsStore(
+		store := metricsstore.NewShardedMetricsStore(
 			familyHeaders,
 			composedMetricGenFuncs,
+			b.storeShards,
 		)
-		klog.InfoS("FieldSelector is used", "fieldSelector", b.fieldSelectorFilter)
-		listWatcher := listWatchFunc(customResourceClient, ns, b.fieldSelectorFilter)
-		b.startReflector(expectedType, store, listWatcher, useAPIServerCache)
+		klog.InfoS("FieldSelector is used", "fieldSelector", fieldSelector)
+		listWatcher := listWatchFunc(customResourceClient, ns, fieldSelector)
+		b.startReflector(gvrString, expectedType, store, listWatcher, ns, useAPIServerCache)
 		stores = append(stores, store)
 	}
 
@@ -603,19 +1037,178 @@ func (b *Builder) buildCustomResourceStores(resourceName string,
 }
 
 // startReflector starts a Kubernetes client-go reflector with the given
-// listWatcher and registers it with the given store.
+// listWatcher and registers it with the given store of the given resource.
 func (b *Builder) startReflector(
+	resourceName string,
 	expectedType interface{},
 	store cache.Store,
 	listWatcher cache.ListerWatcher,
+	namespace string,
 	useAPIServerCache bool,
 ) {
 	// httpserver 中的 availableStore
-	instrumentedListWatch := watch.NewInstrumentedListerWatcher(listWatcher, b.listWatchMetrics, reflect.TypeOf(expectedType).String(), useAPIServerCache)
-	reflector := cache.NewReflector(sharding.NewShardedListWatch(b.shard, b.totalShards, instrumentedListWatch), expectedType, store, 0)
+	resource := reflect.TypeOf(expectedType).String()
+	listOptions := b.listOptions(resourceName, useAPIServerCache)
+	instrumentedListWatch := watch.NewInstrumentedListerWatcher(listWatcher, b.listWatchMetrics, resource, listOptions.ResourceVersion == options.ListResourceVersionCache)
+	if listOptions.ResourceVersion == options.ListResourceVersionConsistent {
+		instrumentedListWatch = watch.NewConsistentListerWatcher(instrumentedListWatch)
+	}
+	if tracker, ok := b.updateTimes[resourceName]; ok {
+		store = tracker.track(store)
+	}
+	if _, ok := expectedType.(*v1.Pod); ok && b.familyGeneratorFilter.Test(createPodPriorityClassCountFamilyGenerator()) {
+		store = newPodPriorityClassStore(store)
+	}
+	if b.ignoreAnnotation != "" {
+		store = b.trackFiltered(resourceName, newIgnoreAnnotationStore(store, b.ignoreAnnotation))
+	}
+	if len(b.namespaceDenylistPatterns) > 0 {
+		store = b.trackFiltered(resourceName, newNamespaceDenylistStore(store, b.namespaceDenylistPatterns))
+	}
+	if _, ok := expectedType.(*v1.Pod); ok && b.podGCThreshold > 0 {
+		store = newPodGCStore(b.ctx, store, b.podGCThreshold)
+	}
+	allowedOwnerKinds, deniedOwnerKinds := resourceOwnerKinds(b.ownerKindsAllowList, resourceName), resourceOwnerKinds(b.ownerKindsDenyList, resourceName)
+	if len(allowedOwnerKinds) > 0 || len(deniedOwnerKinds) > 0 {
+		store = b.trackFiltered(resourceName, newOwnerKindStore(store, allowedOwnerKinds, deniedOwnerKinds))
+	}
+	if _, ok := expectedType.(*v1.Pod); ok && b.podTargets != nil {
+		store = b.podTargets.Track(b.ctx, store)
+	}
+	if b.snapshotReplayDir != "" {
+		b.replaySnapshot(resourceName, expectedType, store, namespace)
+		return
+	}
+	if b.benchmarkObjects != nil {
+		b.populateBenchmark(resourceName, expectedType, store, namespace)
+		return
+	}
+	if b.snapshots != nil {
+		store = b.snapshots.track(resourceName, namespace, store)
+	}
+	if b.notifier.Watches(resourceName) {
+		store = watch.NewNotifyingStore(store, b.notifier, resourceName)
+	}
+	lw := watch.NewStalenessListerWatcher(b.ctx, watch.NewPacedListerWatcher(instrumentedListWatch, b.listPacer), b.stalenessTracker, resource)
+	if b.cache != nil {
+		lw = b.cachedListWatch(resourceName, expectedType, lw, namespace)
+		store = b.cache.track(resourceName, namespace, store)
+	}
+	instrumentedStore := watch.NewInstrumentedStore(b.writeTracker.Track(store), b.objectChurnMetrics, resource)
+	reflector := cache.NewReflector(sharding.NewShardedListWatch(b.shard, b.totalShards, lw), expectedType, instrumentedStore, 0)
+	reflector.WatchListPageSize = listOptions.PageSize
 	go reflector.Run(b.ctx.Done())
 }
 
+// startSnapshots starts writing the objects of the stores which are built
+// afterwards to snapshots and to the cache, if configured.
+func (b *Builder) startSnapshots() {
+	b.snapshots = nil
+	b.cache = nil
+	if b.snapshotReplayDir != "" || b.benchmarkObjects != nil {
+		return
+	}
+	if b.snapshotDir != "" {
+		b.snapshots = newSnapshotWriter(b.snapshotDir)
+		go b.snapshots.run(b.ctx, b.snapshotInterval)
+	}
+	if b.cacheDir != "" {
+		b.cache = newSnapshotWriter(b.cacheDir)
+		b.cache.resourceVersions = true
+		go b.cache.run(b.ctx, b.cacheInterval)
+	}
+}
+
+// replaySnapshot populates the store with the objects of the given namespace
+// from the snapshot of the given resource.
+func (b *Builder) replaySnapshot(resourceName string, expectedType interface{}, store cache.Store, namespace string) {
+	objects, err := readSnapshot(b.snapshotReplayDir, resourceName, expectedType)
+	if os.IsNotExist(err) {
+		klog.InfoS("No snapshot of resource to replay", "resource", resourceName)
+		return
+	}
+	if err != nil {
+		klog.ErrorS(err, "Failed to read snapshot", "resource", resourceName)
+		return
+	}
+	if err := store.Replace(namespaceObjects(objects, namespace), ""); err != nil {
+		klog.ErrorS(err, "Failed to replay snapshot", "resource", resourceName)
+	}
+}
+
+// trackFiltered tracks the objects of the given resource skipped by the
+// filtered store in the filter metrics, if configured.
+func (b *Builder) trackFiltered(resourceName string, store cache.Store) cache.Store {
+	if s, ok := store.(*filteredStore); ok && b.filterMetrics != nil {
+		b.filterMetrics.TrackObjects(b.ctx, resourceName, s.filter, s.skippedCount)
+	}
+	return store
+}
+
+// filterFamilyGenerators returns the families of the given resource which pass
+// the family generator filter, with the configured condition limits and
+// exemplars and named with the configured metric prefix. The families renamed
+// by the naming convention are filtered by both names.
+func (b *Builder) filterFamilyGenerators(resourceName string, metricFamilies []generator.FamilyGenerator) []generator.FamilyGenerator {
+	if len(b.familyRenames) > 0 {
+		metricFamilies = generator.RenameFamilyGenerators(b.familyRenames, metricFamilies)
+	}
+	if b.filterMetrics != nil {
+		rejected := map[string]int{}
+		for _, f := range metricFamilies {
+			if filter, ok := generator.RejectingFilter(b.familyGeneratorFilter, f); ok {
+				rejected[filter]++
+			}
+		}
+		b.filterMetrics.ObserveFamilies(resourceName, rejected)
+	}
+	metricFamilies = generator.FilterFamilyGenerators(b.familyGeneratorFilter, metricFamilies)
+	if known, ok := knownConditionTypes[resourceName]; ok && (b.conditionLimit > 0 || b.conditionTypeLimit > 0) {
+		metricFamilies = limitConditionFamilyGenerators(newConditionLimiter(b.conditionLimit, b.conditionTypeLimit, known), metricFamilies)
+	}
+	if b.labelValueLimit.MaxLength > 0 {
+		metricFamilies = generator.LimitLabelValueFamilyGenerators(b.labelValueLimit, metricFamilies)
+	}
+	if b.timestampPrecision == metric.TimestampPrecisionMilliseconds {
+		metricFamilies = generator.TimestampPrecisionFamilyGenerators(b.timestampPrecision, metricFamilies)
+	}
+	if len(b.exemplarFamilies) > 0 {
+		metricFamilies = generator.ExemplarFamilyGenerators(b.exemplarFamilies, b.exemplarTraceIDAnnotation, metricFamilies)
+	}
+	if b.metricPrefix != generator.DefaultNamePrefix {
+		metricFamilies = generator.PrefixFamilyGenerators(b.metricPrefix, metricFamilies)
+	}
+	return metricFamilies
+}
+
+// fieldSelector returns the field selector of the given resource.
+func (b *Builder) fieldSelector(resourceName string) string {
+	if fieldSelector, ok := b.resourceFieldSelectors[resourceName]; ok {
+		return fieldSelector
+	}
+	return b.fieldSelectorFilter
+}
+
+// listOptions returns the list options of the given resource. Without a
+// resourceVersion configured for the resource, lists are served from the
+// apiserver cache if useAPIServerCache is set.
+func (b *Builder) listOptions(resourceName string, useAPIServerCache bool) options.ListOptions {
+	o := b.resourceListOptions.Resource(resourceName)
+	if o.ResourceVersion == "" && useAPIServerCache {
+		o.ResourceVersion = options.ListResourceVersionCache
+	}
+	return o
+}
+
+// resourceOwnerKinds returns the owner kinds of the given list which apply to
+// the resource, falling back to the '*' entry.
+func resourceOwnerKinds(list map[string][]string, resource string) []string {
+	if kinds, ok := list[resource]; ok {
+		return kinds
+	}
+	return list["*"]
+}
+
 // cacheStoresToMetricStores converts []cache.Store into []*metricsstore.MetricsStore
 func cacheStoresToMetricStores(cStores []cache.Store) []*metricsstore.MetricsStore {
 	mStores := make([]*metricsstore.MetricsStore, 0, len(cStores))
@@ -19,7 +19,14 @@ package store
 import (
 	"reflect"
 	"testing"
+	"time"
 
+	coordinationv1 "k8s.io/api/coordination/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"k8s.io/kube-state-metrics/v2/pkg/metric"
+	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
 	"k8s.io/kube-state-metrics/v2/pkg/options"
 )
 
@@ -81,11 +88,93 @@ func TestWithAllowLabels(t *testing.T) {
 			Desc:             "wildcard key-value as not the only element, with other resources that do not exist",
 			LabelsAllowlist:  map[string][]string{"*": {"*"}, "cronjobs": {"*"}},
 			EnabledResources: []string{"cronjobs", "pods", "deployments", "foo", "bar"},
-			Wanted:           LabelsAllowList{},
+			Wanted: LabelsAllowList(map[string][]string{
+				"cronjobs": {"*"},
+			}),
 			err: expectedError{
 				expectedResourceError: true,
 			},
 		},
+		{
+			Desc:             "wildcard key-value with per-resource overrides",
+			LabelsAllowlist:  map[string][]string{"*": {"team", "owner"}, "pods": {"app"}},
+			EnabledResources: []string{"cronjobs", "pods", "deployments"},
+			Wanted: LabelsAllowList(map[string][]string{
+				"deployments": {"team", "owner"},
+				"pods":        {"app"},
+				"cronjobs":    {"team", "owner"},
+			}),
+		},
+		{
+			Desc:             "regular expressions and renamed keys",
+			LabelsAllowlist:  map[string][]string{"pods": {"app.kubernetes.io/.*", "team:owner"}},
+			EnabledResources: []string{"pods"},
+			Wanted: LabelsAllowList(map[string][]string{
+				"pods": {"app.kubernetes.io/.*", "team:owner"},
+			}),
+		},
+		{
+			Desc:             "invalid regular expression",
+			LabelsAllowlist:  map[string][]string{"pods": {"app.kubernetes.io/(.*"}},
+			EnabledResources: []string{"pods"},
+			Wanted:           LabelsAllowList(nil),
+			err: expectedError{
+				expectedLabelError: true,
+			},
+		},
+		{
+			Desc:             "renamed regular expression",
+			LabelsAllowlist:  map[string][]string{"pods": {"app.kubernetes.io/.*:app"}},
+			EnabledResources: []string{"pods"},
+			Wanted:           LabelsAllowList(nil),
+			err: expectedError{
+				expectedLabelError: true,
+			},
+		},
+		{
+			Desc:             "renamed key colliding with another key",
+			LabelsAllowlist:  map[string][]string{"pods": {"team:owner", "owner"}},
+			EnabledResources: []string{"pods"},
+			Wanted:           LabelsAllowList(nil),
+			err: expectedError{
+				expectedLabelError: true,
+			},
+		},
+		{
+			Desc:             "renamed keys colliding with each other",
+			LabelsAllowlist:  map[string][]string{"pods": {"team:owner", "squad:owner"}},
+			EnabledResources: []string{"pods"},
+			Wanted:           LabelsAllowList(nil),
+			err: expectedError{
+				expectedLabelError: true,
+			},
+		},
+		{
+			Desc:             "renamed key matched by a regular expression",
+			LabelsAllowlist:  map[string][]string{"pods": {"team:owner", "own.*"}},
+			EnabledResources: []string{"pods"},
+			Wanted:           LabelsAllowList(nil),
+			err: expectedError{
+				expectedLabelError: true,
+			},
+		},
+		{
+			Desc:             "renamed key swapping names with another renamed key",
+			LabelsAllowlist:  map[string][]string{"pods": {"team:owner", "owner:team"}},
+			EnabledResources: []string{"pods"},
+			Wanted: LabelsAllowList(map[string][]string{
+				"pods": {"team:owner", "owner:team"},
+			}),
+		},
+		{
+			Desc:             "invalid label name for renamed key",
+			LabelsAllowlist:  map[string][]string{"pods": {"team:team-name"}},
+			EnabledResources: []string{"pods"},
+			Wanted:           LabelsAllowList(nil),
+			err: expectedError{
+				expectedLabelError: true,
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -100,6 +189,9 @@ func TestWithAllowLabels(t *testing.T) {
 
 		// Resolve the allow list.
 		err = b.WithAllowLabels(test.LabelsAllowlist)
+		if err == nil && test.err.expectedLabelError {
+			t.Errorf("Test error for Desc: %s. Expected error while parsing allow list labels.", test.Desc)
+		}
 		if err != nil && !test.err.expectedLabelError {
 			t.Log("Did not expect error while parsing allow list labels (--metric-labels-allowlist).")
 			t.Errorf("Test error for Desc: %s. Got Error: %v", test.Desc, err)
@@ -164,7 +256,9 @@ func TestWithAllowAnnotations(t *testing.T) {
 			Desc:                 "wildcard key-value as not the only element, with other resources that do not exist",
 			AnnotationsAllowlist: map[string][]string{"*": {"*"}, "cronjobs": {"*"}},
 			EnabledResources:     []string{"cronjobs", "pods", "deployments", "foo", "bar"},
-			Wanted:               LabelsAllowList{},
+			Wanted: LabelsAllowList(map[string][]string{
+				"cronjobs": {"*"},
+			}),
 			err: expectedError{
 				expectedResourceError: true,
 			},
@@ -196,3 +290,225 @@ func TestWithAllowAnnotations(t *testing.T) {
 		}
 	}
 }
+
+func TestWithEnabledResources(t *testing.T) {
+	b := NewBuilder()
+	if err := b.WithEnabledResources([]string{"secrets", "pods"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.WithEnabledResources([]string{"services", "pods", "services"}); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"pods", "secrets", "services"}
+	if !reflect.DeepEqual(b.enabledResources, want) {
+		t.Errorf("expected enabled resources %v, got %v", want, b.enabledResources)
+	}
+	if err := b.WithEnabledResources([]string{"unknown"}); err == nil {
+		t.Error("expected an error for an unknown resource")
+	}
+}
+
+func TestWithMetricPrefix(t *testing.T) {
+	tests := []struct {
+		Desc        string
+		Prefix      string
+		Wanted      []string
+		WantedError bool
+	}{
+		{
+			Desc:   "default prefix",
+			Prefix: "kube_",
+			Wanted: []string{"kube_pod_info", "kube_customresource_foo_info", "custom_foo_info"},
+		},
+		{
+			Desc:   "custom prefix",
+			Prefix: "acme_k8s_",
+			Wanted: []string{"acme_k8s_pod_info", "acme_k8s_customresource_foo_info", "custom_foo_info"},
+		},
+		{
+			Desc:        "invalid prefix",
+			Prefix:      "acme-k8s_",
+			WantedError: true,
+		},
+		{
+			Desc:        "empty prefix",
+			Prefix:      "",
+			WantedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		b := NewBuilder()
+		b.WithFamilyGeneratorFilter(generator.NewCompositeFamilyGeneratorFilter())
+
+		err := b.WithMetricPrefix(test.Prefix)
+		if test.WantedError {
+			if err == nil {
+				t.Errorf("Test error for Desc: %s. Want error.", test.Desc)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test error for Desc: %s. Got Error: %v", test.Desc, err)
+		}
+
+		families := b.filterFamilyGenerators("pods", []generator.FamilyGenerator{
+			{Name: "kube_pod_info"},
+			{Name: "kube_customresource_foo_info"},
+			{Name: "custom_foo_info"},
+		})
+		names := make([]string, 0, len(families))
+		for _, f := range families {
+			names = append(names, f.Name)
+		}
+		if !reflect.DeepEqual(names, test.Wanted) {
+			t.Errorf("Test error for Desc: %s\n Want: %v\n Got: %v", test.Desc, test.Wanted, names)
+		}
+	}
+}
+
+func TestWithNamingConvention(t *testing.T) {
+	tests := []struct {
+		Desc        string
+		Convention  string
+		Filter      generator.FamilyGeneratorFilter
+		Wanted      []string
+		WantedError bool
+	}{
+		{
+			Desc:       "v2",
+			Convention: "v2",
+			Wanted:     []string{"kube_pod_created", "kube_pod_start_time", "kube_pod_start_timestamp_seconds"},
+		},
+		{
+			Desc:       "v3 serves both names and skips duplicates",
+			Convention: "v3",
+			Wanted:     []string{"kube_pod_created", "kube_pod_start_time", "kube_pod_start_timestamp_seconds", "kube_pod_created_timestamp_seconds"},
+		},
+		{
+			Desc:       "v3 names are filtered",
+			Convention: "v3",
+			Filter: generator.FamilyGeneratorFilterFunc(func(f generator.FamilyGenerator) bool {
+				return f.Name != "kube_pod_created"
+			}),
+			Wanted: []string{"kube_pod_start_time", "kube_pod_start_timestamp_seconds", "kube_pod_created_timestamp_seconds"},
+		},
+		{
+			Desc:        "invalid convention",
+			Convention:  "v4",
+			WantedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		b := NewBuilder()
+		filter := test.Filter
+		if filter == nil {
+			filter = generator.NewCompositeFamilyGeneratorFilter()
+		}
+		b.WithFamilyGeneratorFilter(filter)
+
+		err := b.WithNamingConvention(test.Convention)
+		if test.WantedError {
+			if err == nil {
+				t.Errorf("Test error for Desc: %s. Want error.", test.Desc)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test error for Desc: %s. Got Error: %v", test.Desc, err)
+		}
+
+		// kube_pod_start_timestamp_seconds stands in for a family which
+		// already has the v3 name of kube_pod_start_time.
+		families := b.filterFamilyGenerators("pods", []generator.FamilyGenerator{
+			{Name: "kube_pod_created"},
+			{Name: "kube_pod_start_time"},
+			{Name: "kube_pod_start_timestamp_seconds"},
+		})
+		names := make([]string, 0, len(families))
+		for _, f := range families {
+			names = append(names, f.Name)
+		}
+		if !reflect.DeepEqual(names, test.Wanted) {
+			t.Errorf("Test error for Desc: %s\n Want: %v\n Got: %v", test.Desc, test.Wanted, names)
+		}
+	}
+}
+
+func TestWithExemplars(t *testing.T) {
+	b := NewBuilder()
+	b.WithFamilyGeneratorFilter(generator.NewCompositeFamilyGeneratorFilter())
+	b.WithExemplars(map[string]struct{}{"kube_pod_restarts_total": {}}, "example.com/trace-id")
+
+	generate := func(obj interface{}) *metric.Family {
+		return &metric.Family{Metrics: []*metric.Metric{{Value: 3}}}
+	}
+	families := b.filterFamilyGenerators("pods", []generator.FamilyGenerator{
+		{Name: "kube_pod_info", GenerateFunc: generate},
+		{Name: "kube_pod_restarts_total", GenerateFunc: generate},
+	})
+
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", UID: "a", Annotations: map[string]string{"example.com/trace-id": "0af7651916cd43dd8448eb211c80319c"}}}
+	if e := families[0].Generate(pod).Metrics[0].Exemplar; e != nil {
+		t.Errorf("expected no exemplar for kube_pod_info, got %+v", e)
+	}
+	want := &metric.Exemplar{LabelKeys: []string{"uid", "trace_id"}, LabelValues: []string{"a", "0af7651916cd43dd8448eb211c80319c"}, Value: 3}
+	if e := families[1].Generate(pod).Metrics[0].Exemplar; !reflect.DeepEqual(e, want) {
+		t.Errorf("expected exemplar %+v, got %+v", want, e)
+	}
+
+	pod.Annotations = nil
+	want = &metric.Exemplar{LabelKeys: []string{"uid"}, LabelValues: []string{"a"}, Value: 3}
+	if e := families[1].Generate(pod).Metrics[0].Exemplar; !reflect.DeepEqual(e, want) {
+		t.Errorf("expected exemplar %+v without annotation, got %+v", want, e)
+	}
+}
+
+func TestWithLabelValueLimit(t *testing.T) {
+	b := NewBuilder()
+	b.WithFamilyGeneratorFilter(generator.NewCompositeFamilyGeneratorFilter())
+	if err := b.WithLabelValueLimit(metric.LabelValueLimit{MaxLength: 8, Policy: metric.LabelValueLimitTruncate}); err == nil {
+		t.Fatal("expected a max length below the length of the hash to be invalid")
+	}
+	if err := b.WithLabelValueLimit(metric.LabelValueLimit{MaxLength: 16, Policy: metric.LabelValueLimitHash}); err != nil {
+		t.Fatal(err)
+	}
+
+	families := b.filterFamilyGenerators("pods", []generator.FamilyGenerator{
+		{Name: "kube_pod_annotations", GenerateFunc: func(obj interface{}) *metric.Family {
+			return &metric.Family{Metrics: []*metric.Metric{
+				{LabelKeys: []string{"annotation_description"}, LabelValues: []string{obj.(*v1.Pod).Annotations["description"]}, Value: 1},
+			}}
+		}},
+	})
+
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Annotations: map[string]string{"description": "a description longer than the limit"}}}
+	if got := families[0].Generate(pod).Metrics[0].LabelValues; len(got) != 1 || len(got[0]) != 16 {
+		t.Errorf("expected the hash of the label value, got %v", got)
+	}
+}
+
+func TestWithTimestampPrecision(t *testing.T) {
+	b := NewBuilder()
+	b.WithFamilyGeneratorFilter(generator.NewCompositeFamilyGeneratorFilter())
+	if err := b.WithTimestampPrecision("nanoseconds"); err == nil {
+		t.Fatal("expected an unknown precision to be invalid")
+	}
+	if err := b.WithTimestampPrecision(metric.TimestampPrecisionMilliseconds); err != nil {
+		t.Fatal(err)
+	}
+
+	families := b.filterFamilyGenerators("leases", []generator.FamilyGenerator{
+		{Name: "kube_lease_renew_time", GenerateFunc: func(obj interface{}) *metric.Family {
+			return &metric.Family{Metrics: []*metric.Metric{
+				metric.TimestampMetric(nil, nil, obj.(*coordinationv1.Lease).Spec.RenewTime.Time),
+			}}
+		}},
+	})
+
+	lease := &coordinationv1.Lease{Spec: coordinationv1.LeaseSpec{RenewTime: &metav1.MicroTime{Time: time.Unix(1700000000, 250*int64(time.Millisecond))}}}
+	if got := families[0].Generate(lease).Metrics[0].Value; got != 1700000000.25 {
+		t.Errorf("expected the renew time with milliseconds, got %v", got)
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"os"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+// cachedListerWatcher returns the cached objects on the first List, so that
+// the reflector populates its store from the cache and watches from the
+// cached resourceVersion. Later calls, e.g. after the cached resourceVersion
+// expired, list the objects from the apiserver.
+type cachedListerWatcher struct {
+	cache.ListerWatcher
+
+	mutex           sync.Mutex
+	objects         []interface{}
+	resourceVersion string
+}
+
+// List implements the List method of the ListerWatcher interface.
+func (lw *cachedListerWatcher) List(options metav1.ListOptions) (runtime.Object, error) {
+	lw.mutex.Lock()
+	objects, resourceVersion := lw.objects, lw.resourceVersion
+	lw.objects, lw.resourceVersion = nil, ""
+	lw.mutex.Unlock()
+
+	if resourceVersion == "" {
+		return lw.ListerWatcher.List(options)
+	}
+	list := &metav1.List{Items: make([]runtime.RawExtension, 0, len(objects))}
+	for _, o := range objects {
+		if obj, ok := o.(runtime.Object); ok {
+			list.Items = append(list.Items, runtime.RawExtension{Object: obj})
+		}
+	}
+	list.ResourceVersion = resourceVersion
+	return list, nil
+}
+
+// cachedListWatch returns a ListerWatcher which lists the objects of the given
+// namespace from the cache of the given resource first, if they are cached.
+func (b *Builder) cachedListWatch(resourceName string, expectedType interface{}, lw cache.ListerWatcher, namespace string) cache.ListerWatcher {
+	resourceVersions, err := readResourceVersions(b.cacheDir, resourceName)
+	if os.IsNotExist(err) {
+		return lw
+	}
+	if err != nil {
+		klog.ErrorS(err, "Failed to read cached resourceVersions", "resource", resourceName)
+		return lw
+	}
+	resourceVersion := resourceVersions[namespace]
+	if resourceVersion == "" {
+		return lw
+	}
+	objects, err := readSnapshot(b.cacheDir, resourceName, expectedType)
+	if err != nil {
+		klog.ErrorS(err, "Failed to read cached objects", "resource", resourceName)
+		return lw
+	}
+	objects = namespaceObjects(objects, namespace)
+
+	klog.InfoS("Populating stores from cache", "resource", resourceName, "namespace", namespace, "objects", len(objects), "resourceVersion", resourceVersion)
+	return &cachedListerWatcher{ListerWatcher: lw, objects: objects, resourceVersion: resourceVersion}
+}
@@ -65,6 +65,7 @@ func csrMetricFamilies(allowAnnotationsList, allowLabelsList []string) []generat
 				}
 			}),
 		),
+		// Not lazy, as the metrics are labeled with the signer of the request.
 		*generator.NewFamilyGeneratorWithStability(
 			descCSRLabelsName,
 			descCSRLabelsHelp,
@@ -96,11 +97,7 @@ func csrMetricFamilies(allowAnnotationsList, allowLabelsList []string) []generat
 			wrapCSRFunc(func(csr *certv1.CertificateSigningRequest) *metric.Family {
 				ms := []*metric.Metric{}
 				if !csr.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						LabelKeys:   []string{},
-						LabelValues: []string{},
-						Value:       float64(csr.CreationTimestamp.Unix()),
-					})
+					ms = append(ms, metric.TimestampMetric([]string{}, []string{}, csr.CreationTimestamp.Time))
 				}
 
 				return &metric.Family{
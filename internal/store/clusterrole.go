@@ -18,6 +18,8 @@ package store
 
 import (
 	"context"
+	"sort"
+	"strconv"
 
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -41,7 +43,7 @@ var (
 
 func clusterRoleMetricFamilies(allowAnnotationsList, allowLabelsList []string) []generator.FamilyGenerator {
 	return []generator.FamilyGenerator{
-		*generator.NewFamilyGeneratorWithStability(
+		*generator.NewLazyFamilyGenerator(
 			descClusterRoleAnnotationsName,
 			descClusterRoleAnnotationsHelp,
 			metric.Gauge,
@@ -63,7 +65,7 @@ func clusterRoleMetricFamilies(allowAnnotationsList, allowLabelsList []string) [
 				}
 			}),
 		),
-		*generator.NewFamilyGeneratorWithStability(
+		*generator.NewLazyFamilyGenerator(
 			descClusterRoleLabelsName,
 			descClusterRoleLabelsHelp,
 			metric.Gauge,
@@ -111,11 +113,7 @@ func clusterRoleMetricFamilies(allowAnnotationsList, allowLabelsList []string) [
 				ms := []*metric.Metric{}
 
 				if !r.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						LabelKeys:   []string{},
-						LabelValues: []string{},
-						Value:       float64(r.CreationTimestamp.Unix()),
-					})
+					ms = append(ms, metric.TimestampMetric([]string{}, []string{}, r.CreationTimestamp.Time))
 				}
 
 				return &metric.Family{
@@ -135,6 +133,82 @@ func clusterRoleMetricFamilies(allowAnnotationsList, allowLabelsList []string) [
 				}
 			}),
 		),
+		*generator.NewFamilyGeneratorWithStability(
+			"kube_clusterrole_rules",
+			"Number of policy rules of the cluster role.",
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			wrapClusterRoleFunc(func(r *rbacv1.ClusterRole) *metric.Family {
+				return &metric.Family{
+					Metrics: []*metric.Metric{{
+						Value: float64(len(r.Rules)),
+					}},
+				}
+			}),
+		),
+		*generator.NewFamilyGeneratorWithStability(
+			"kube_clusterrole_wildcard_rules",
+			"Number of policy rules of the cluster role which contain the '*' wildcard in the field.",
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			wrapClusterRoleFunc(func(r *rbacv1.ClusterRole) *metric.Family {
+				return &metric.Family{
+					Metrics: wildcardRuleMetrics(r.Rules, clusterRolePolicyRuleFields),
+				}
+			}),
+		),
+		*generator.NewFamilyGeneratorWithStability(
+			"kube_clusterrole_aggregation_rule",
+			"Label requirements of the cluster role selectors of the aggregation rule of the cluster role, one series per value.",
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			wrapClusterRoleFunc(func(r *rbacv1.ClusterRole) *metric.Family {
+				ms := []*metric.Metric{}
+
+				if r.AggregationRule == nil {
+					return &metric.Family{
+						Metrics: ms,
+					}
+				}
+
+				for i, selector := range r.AggregationRule.ClusterRoleSelectors {
+					index := strconv.Itoa(i)
+					keys := make([]string, 0, len(selector.MatchLabels))
+					for key := range selector.MatchLabels {
+						keys = append(keys, key)
+					}
+					sort.Strings(keys)
+					for _, key := range keys {
+						ms = append(ms, &metric.Metric{
+							LabelKeys:   []string{"selector", "key", "operator", "value"},
+							LabelValues: []string{index, key, string(metav1.LabelSelectorOpIn), selector.MatchLabels[key]},
+							Value:       1,
+						})
+					}
+					for _, req := range selector.MatchExpressions {
+						values := req.Values
+						if len(values) == 0 {
+							// Exists and DoesNotExist requirements have no values.
+							values = []string{""}
+						}
+						for _, value := range values {
+							ms = append(ms, &metric.Metric{
+								LabelKeys:   []string{"selector", "key", "operator", "value"},
+								LabelValues: []string{index, req.Key, string(req.Operator), value},
+								Value:       1,
+							})
+						}
+					}
+				}
+
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		),
 	}
 }
 
@@ -92,6 +92,69 @@ func TestClusterRoleStore(t *testing.T) {
 				`,
 			MetricNames: []string{"kube_clusterrole_info", "kube_clusterrole_created", "kube_clusterrole_metadata_resource_version"},
 		},
+		{
+			Obj: &rbacv1.ClusterRole{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "role3",
+				},
+				Rules: []rbacv1.PolicyRule{
+					{
+						APIGroups: []string{""},
+						Resources: []string{"pods", "secrets"},
+						Verbs:     []string{"*"},
+					},
+					{
+						APIGroups: []string{"*"},
+						Resources: []string{"*"},
+						Verbs:     []string{"get", "*"},
+					},
+					{
+						NonResourceURLs: []string{"/metrics"},
+						Verbs:           []string{"get"},
+					},
+				},
+				AggregationRule: &rbacv1.AggregationRule{
+					ClusterRoleSelectors: []metav1.LabelSelector{
+						{
+							MatchLabels: map[string]string{
+								"rbac.example.com/aggregate-to-monitoring": "true",
+							},
+						},
+						{
+							MatchExpressions: []metav1.LabelSelectorRequirement{
+								{
+									Key:      "tier",
+									Operator: metav1.LabelSelectorOpIn,
+									Values:   []string{"a", "b"},
+								},
+								{
+									Key:      "legacy",
+									Operator: metav1.LabelSelectorOpDoesNotExist,
+								},
+							},
+						},
+					},
+				},
+			},
+			Want: `
+				# HELP kube_clusterrole_aggregation_rule Label requirements of the cluster role selectors of the aggregation rule of the cluster role, one series per value.
+				# HELP kube_clusterrole_rules Number of policy rules of the cluster role.
+				# HELP kube_clusterrole_wildcard_rules Number of policy rules of the cluster role which contain the '*' wildcard in the field.
+				# TYPE kube_clusterrole_aggregation_rule gauge
+				# TYPE kube_clusterrole_rules gauge
+				# TYPE kube_clusterrole_wildcard_rules gauge
+				kube_clusterrole_aggregation_rule{clusterrole="role3",key="legacy",operator="DoesNotExist",selector="1",value=""} 1
+				kube_clusterrole_aggregation_rule{clusterrole="role3",key="rbac.example.com/aggregate-to-monitoring",operator="In",selector="0",value="true"} 1
+				kube_clusterrole_aggregation_rule{clusterrole="role3",key="tier",operator="In",selector="1",value="a"} 1
+				kube_clusterrole_aggregation_rule{clusterrole="role3",key="tier",operator="In",selector="1",value="b"} 1
+				kube_clusterrole_rules{clusterrole="role3"} 3
+				kube_clusterrole_wildcard_rules{clusterrole="role3",field="api_groups"} 1
+				kube_clusterrole_wildcard_rules{clusterrole="role3",field="non_resource_urls"} 0
+				kube_clusterrole_wildcard_rules{clusterrole="role3",field="resources"} 1
+				kube_clusterrole_wildcard_rules{clusterrole="role3",field="verbs"} 2
+				`,
+			MetricNames: []string{"kube_clusterrole_aggregation_rule", "kube_clusterrole_rules", "kube_clusterrole_wildcard_rules"},
+		},
 	}
 	for i, c := range cases {
 		c.Func = generator.ComposeMetricGenFuncs(clusterRoleMetricFamilies(c.AllowAnnotationsList, c.AllowLabelsList))
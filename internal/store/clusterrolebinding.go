@@ -41,7 +41,7 @@ var (
 
 func clusterRoleBindingMetricFamilies(allowAnnotationsList, allowLabelsList []string) []generator.FamilyGenerator {
 	return []generator.FamilyGenerator{
-		*generator.NewFamilyGeneratorWithStability(
+		*generator.NewLazyFamilyGenerator(
 			descClusterRoleBindingAnnotationsName,
 			descClusterRoleBindingAnnotationsHelp,
 			metric.Gauge,
@@ -63,7 +63,7 @@ func clusterRoleBindingMetricFamilies(allowAnnotationsList, allowLabelsList []st
 				}
 			}),
 		),
-		*generator.NewFamilyGeneratorWithStability(
+		*generator.NewLazyFamilyGenerator(
 			descClusterRoleBindingLabelsName,
 			descClusterRoleBindingLabelsHelp,
 			metric.Gauge,
@@ -113,11 +113,7 @@ func clusterRoleBindingMetricFamilies(allowAnnotationsList, allowLabelsList []st
 				ms := []*metric.Metric{}
 
 				if !r.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						LabelKeys:   []string{},
-						LabelValues: []string{},
-						Value:       float64(r.CreationTimestamp.Unix()),
-					})
+					ms = append(ms, metric.TimestampMetric([]string{}, []string{}, r.CreationTimestamp.Time))
 				}
 
 				return &metric.Family{
@@ -137,6 +133,18 @@ func clusterRoleBindingMetricFamilies(allowAnnotationsList, allowLabelsList []st
 				}
 			}),
 		),
+		*generator.NewFamilyGeneratorWithStability(
+			"kube_clusterrolebinding_subjects",
+			"Number of subjects of the clusterrolebinding by kind.",
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			wrapClusterRoleBindingFunc(func(r *rbacv1.ClusterRoleBinding) *metric.Family {
+				return &metric.Family{
+					Metrics: subjectKindMetrics(r.Subjects),
+				}
+			}),
+		),
 	}
 }
 
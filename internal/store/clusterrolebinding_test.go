@@ -102,6 +102,25 @@ func TestClusterRoleBindingStore(t *testing.T) {
 				`,
 			MetricNames: []string{"kube_clusterrolebinding_info", "kube_clusterrolebinding_created", "kube_clusterrolebinding_metadata_resource_version"},
 		},
+		{
+			Obj: &rbacv1.ClusterRoleBinding{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "clusterrolebinding3",
+				},
+				Subjects: []rbacv1.Subject{
+					{Kind: rbacv1.UserKind, Name: "alice"},
+					{Kind: rbacv1.GroupKind, Name: "system:masters"},
+				},
+			},
+			Want: `
+				# HELP kube_clusterrolebinding_subjects Number of subjects of the clusterrolebinding by kind.
+				# TYPE kube_clusterrolebinding_subjects gauge
+				kube_clusterrolebinding_subjects{clusterrolebinding="clusterrolebinding3",kind="Group"} 1
+				kube_clusterrolebinding_subjects{clusterrolebinding="clusterrolebinding3",kind="ServiceAccount"} 0
+				kube_clusterrolebinding_subjects{clusterrolebinding="clusterrolebinding3",kind="User"} 1
+				`,
+			MetricNames: []string{"kube_clusterrolebinding_subjects"},
+		},
 	}
 	for i, c := range cases {
 		c.Func = generator.ComposeMetricGenFuncs(clusterRoleBindingMetricFamilies(c.AllowAnnotationsList, c.AllowLabelsList))
@@ -0,0 +1,182 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscaling "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+
+	"k8s.io/kube-state-metrics/v2/pkg/metric"
+	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
+)
+
+// knownConditionTypes are the condition types defined by Kubernetes for each
+// resource with kube_<resource>_status_condition families. They are served
+// regardless of the limit of distinct condition types.
+var knownConditionTypes = map[string]map[string]struct{}{
+	"daemonsets": {},
+	"deployments": {
+		string(appsv1.DeploymentAvailable):      {},
+		string(appsv1.DeploymentProgressing):    {},
+		string(appsv1.DeploymentReplicaFailure): {},
+	},
+	"horizontalpodautoscalers": {
+		string(autoscaling.AbleToScale):    {},
+		string(autoscaling.ScalingActive):  {},
+		string(autoscaling.ScalingLimited): {},
+	},
+	"jobs": {
+		string(batchv1.JobComplete):           {},
+		string(batchv1.JobFailed):             {},
+		string(batchv1.JobFailureTarget):      {},
+		string(batchv1.JobSuccessCriteriaMet): {},
+		string(batchv1.JobSuspended):          {},
+	},
+	"namespaces": {
+		string(v1.NamespaceContentRemaining):         {},
+		string(v1.NamespaceDeletionContentFailure):   {},
+		string(v1.NamespaceDeletionDiscoveryFailure): {},
+		string(v1.NamespaceDeletionGVParsingFailure): {},
+		string(v1.NamespaceFinalizersRemaining):      {},
+	},
+	"nodes": {
+		string(v1.NodeDiskPressure):       {},
+		string(v1.NodeMemoryPressure):     {},
+		string(v1.NodeNetworkUnavailable): {},
+		string(v1.NodePIDPressure):        {},
+		string(v1.NodeReady):              {},
+	},
+	"persistentvolumeclaims": {
+		string(v1.PersistentVolumeClaimControllerResizeError):   {},
+		string(v1.PersistentVolumeClaimFileSystemResizePending): {},
+		string(v1.PersistentVolumeClaimNodeResizeError):         {},
+		string(v1.PersistentVolumeClaimResizing):                {},
+		string(v1.PersistentVolumeClaimVolumeModifyVolumeError): {},
+		string(v1.PersistentVolumeClaimVolumeModifyingVolume):   {},
+	},
+	"pods": {
+		string(v1.ContainersReady):           {},
+		string(v1.DisruptionTarget):          {},
+		string(v1.PodInitialized):            {},
+		string(v1.PodReady):                  {},
+		string(v1.PodReadyToStartContainers): {},
+		string(v1.PodResizeInProgress):       {},
+		string(v1.PodResizePending):          {},
+		string(v1.PodScheduled):              {},
+	},
+	"poddisruptionbudgets": {
+		policyv1.DisruptionAllowedCondition: {},
+	},
+	"replicasets": {
+		string(appsv1.ReplicaSetReplicaFailure): {},
+	},
+	"replicationcontrollers": {
+		string(v1.ReplicationControllerReplicaFailure): {},
+	},
+	"services":     {},
+	"statefulsets": {},
+}
+
+// conditionLimiter limits the condition types served by the
+// kube_<resource>_status_condition families of a resource.
+type conditionLimiter struct {
+	// perObject is the maximum number of condition types per object, 0 if
+	// it is unlimited.
+	perObject int
+	// known are the condition types which are not limited by types.
+	known map[string]struct{}
+	// types bounds the number of distinct condition types which are not
+	// known across all objects of the resource.
+	types *valueLimiter
+}
+
+// newConditionLimiter returns a conditionLimiter for the given maximum
+// number of condition types per object and of distinct unknown condition
+// types of the resource. A maximum of 0 disables the respective limit.
+func newConditionLimiter(perObject, types int, known map[string]struct{}) *conditionLimiter {
+	return &conditionLimiter{perObject: perObject, known: known, types: newValueLimiter(types)}
+}
+
+// apply drops the metrics of the condition types of the family beyond the
+// limits. Condition types are counted per object in the order of the metrics
+// of the family.
+func (l *conditionLimiter) apply(f *metric.Family) {
+	served := map[string]bool{}
+	count := 0
+	metrics := f.Metrics[:0]
+	for _, m := range f.Metrics {
+		conditionType, ok := conditionLabelValue(m)
+		if !ok {
+			metrics = append(metrics, m)
+			continue
+		}
+		serve, seen := served[conditionType]
+		if !seen {
+			serve = (l.perObject == 0 || count < l.perObject) && l.admit(conditionType)
+			served[conditionType] = serve
+			if serve {
+				count++
+			}
+		}
+		if serve {
+			metrics = append(metrics, m)
+		}
+	}
+	f.Metrics = metrics
+}
+
+// admit reports whether the condition type is known or within the limit of
+// distinct unknown condition types.
+func (l *conditionLimiter) admit(conditionType string) bool {
+	if _, ok := l.known[conditionType]; ok {
+		return true
+	}
+	return l.types.admit(conditionType)
+}
+
+// conditionLabelValue returns the value of the condition label of the metric.
+func conditionLabelValue(m *metric.Metric) (string, bool) {
+	for i, k := range m.LabelKeys {
+		if k == "condition" && i < len(m.LabelValues) {
+			return m.LabelValues[i], true
+		}
+	}
+	return "", false
+}
+
+// limitConditionFamilyGenerators returns copies of the given families where
+// the status condition families apply the given limiter to their metrics.
+func limitConditionFamilyGenerators(limiter *conditionLimiter, families []generator.FamilyGenerator) []generator.FamilyGenerator {
+	result := make([]generator.FamilyGenerator, len(families))
+	for i, f := range families {
+		if strings.HasSuffix(f.Name, "_status_condition") || strings.HasSuffix(f.Name, "_status_condition_last_transition_time") || strings.HasSuffix(f.Name, "_status_condition_last_transition_age_seconds") {
+			generate := f.GenerateFunc
+			f.GenerateFunc = func(obj interface{}) *metric.Family {
+				family := generate(obj)
+				limiter.apply(family)
+				return family
+			}
+		}
+		result[i] = f
+	}
+	return result
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
+)
+
+func TestWithConditionLimit(t *testing.T) {
+	b := NewBuilder()
+	b.WithFamilyGeneratorFilter(generator.NewCompositeFamilyGeneratorFilter())
+	if err := b.WithConditionLimit(-1, 0); err == nil {
+		t.Error("expected an error for a negative condition limit")
+	}
+	if err := b.WithConditionLimit(0, -1); err == nil {
+		t.Error("expected an error for a negative condition type limit")
+	}
+	if err := b.WithConditionLimit(2, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	var family generator.FamilyGenerator
+	for _, f := range b.filterFamilyGenerators("nodes", nodeMetricFamilies(nil, nil, nodeFamilyOptions{})) {
+		if f.Name == "kube_node_status_condition" {
+			family = f
+		}
+	}
+	node := func(name string, types ...v1.NodeConditionType) *v1.Node {
+		n := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		for _, t := range types {
+			n.Status.Conditions = append(n.Status.Conditions, v1.NodeCondition{Type: t, Status: v1.ConditionTrue})
+		}
+		return n
+	}
+
+	for _, test := range []struct {
+		node *v1.Node
+		want []string
+	}{
+		{
+			// At most 2 condition types per node.
+			node: node("node1", v1.NodeReady, "example.com/A", "example.com/B"),
+			want: []string{"Ready", "example.com/A"},
+		},
+		{
+			// At most 1 unknown condition type across all nodes, while the
+			// condition types defined by Kubernetes are always served.
+			node: node("node2", "example.com/B", v1.NodeMemoryPressure, "example.com/A"),
+			want: []string{"MemoryPressure", "example.com/A"},
+		},
+	} {
+		var got []string
+		for _, m := range family.Generate(test.node).Metrics {
+			conditionType, _ := conditionLabelValue(m)
+			if len(got) == 0 || got[len(got)-1] != conditionType {
+				got = append(got, conditionType)
+			}
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: expected condition types %v, got %v", test.node.Name, test.want, got)
+		}
+	}
+}
+
+func TestConditionLimiterWithoutLimits(t *testing.T) {
+	b := NewBuilder()
+	b.WithFamilyGeneratorFilter(generator.NewCompositeFamilyGeneratorFilter())
+	families := b.filterFamilyGenerators("nodes", nodeMetricFamilies(nil, nil, nodeFamilyOptions{}))
+	n := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}
+	for i := 0; i < 30; i++ {
+		n.Status.Conditions = append(n.Status.Conditions, v1.NodeCondition{Type: v1.NodeConditionType(fmt.Sprintf("example.com/C%d", i)), Status: v1.ConditionTrue})
+	}
+	for _, f := range families {
+		if f.Name == "kube_node_status_condition" {
+			if got := len(f.Generate(n).Metrics); got != 30*len(conditionStatuses) {
+				t.Errorf("expected all conditions to be served by default, got %d metrics", got)
+			}
+		}
+	}
+}
@@ -37,7 +37,7 @@ var (
 
 func configMapMetricFamilies(allowAnnotationsList, allowLabelsList []string) []generator.FamilyGenerator {
 	return []generator.FamilyGenerator{
-		*generator.NewFamilyGeneratorWithStability(
+		*generator.NewLazyFamilyGenerator(
 			"kube_configmap_annotations",
 			"Kubernetes annotations converted to Prometheus labels.",
 			metric.Gauge,
@@ -59,7 +59,7 @@ func configMapMetricFamilies(allowAnnotationsList, allowLabelsList []string) []g
 				}
 			}),
 		),
-		*generator.NewFamilyGeneratorWithStability(
+		*generator.NewLazyFamilyGenerator(
 			"kube_configmap_labels",
 			"Kubernetes labels converted to Prometheus labels.",
 			metric.Gauge,
@@ -97,6 +97,45 @@ func configMapMetricFamilies(allowAnnotationsList, allowLabelsList []string) []g
 				}
 			}),
 		),
+		*generator.NewOptInFamilyGenerator(
+			"kube_configmap_data_keys",
+			"Number of data and binary data keys of the configmap.",
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			wrapConfigMapFunc(func(c *v1.ConfigMap) *metric.Family {
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							Value: float64(len(c.Data) + len(c.BinaryData)),
+						},
+					},
+				}
+			}),
+		),
+		*generator.NewOptInFamilyGenerator(
+			"kube_configmap_data_bytes",
+			"Total size of the data and binary data values of the configmap in bytes.",
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			wrapConfigMapFunc(func(c *v1.ConfigMap) *metric.Family {
+				size := 0
+				for _, v := range c.Data {
+					size += len(v)
+				}
+				for _, v := range c.BinaryData {
+					size += len(v)
+				}
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							Value: float64(size),
+						},
+					},
+				}
+			}),
+		),
 		*generator.NewFamilyGeneratorWithStability(
 			"kube_configmap_created",
 			"Unix creation timestamp",
@@ -107,11 +146,7 @@ func configMapMetricFamilies(allowAnnotationsList, allowLabelsList []string) []g
 				ms := []*metric.Metric{}
 
 				if !c.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						LabelKeys:   []string{},
-						LabelValues: []string{},
-						Value:       float64(c.CreationTimestamp.Unix()),
-					})
+					ms = append(ms, metric.TimestampMetric([]string{}, []string{}, c.CreationTimestamp.Time))
 				}
 
 				return &metric.Family{
@@ -94,6 +94,29 @@ func TestConfigMapStore(t *testing.T) {
 				`,
 			MetricNames: []string{"kube_configmap_info", "kube_configmap_created", "kube_configmap_metadata_resource_version"},
 		},
+		{
+			Obj: &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "configmap3",
+					Namespace: "ns3",
+				},
+				Data: map[string]string{
+					"config.yaml": "key: value",
+				},
+				BinaryData: map[string][]byte{
+					"logo.png": {0x89, 0x50, 0x4e, 0x47},
+				},
+			},
+			Want: `
+				# HELP kube_configmap_data_bytes Total size of the data and binary data values of the configmap in bytes.
+				# HELP kube_configmap_data_keys Number of data and binary data keys of the configmap.
+				# TYPE kube_configmap_data_bytes gauge
+				# TYPE kube_configmap_data_keys gauge
+				kube_configmap_data_bytes{configmap="configmap3",namespace="ns3"} 14
+				kube_configmap_data_keys{configmap="configmap3",namespace="ns3"} 2
+				`,
+			MetricNames: []string{"kube_configmap_data_bytes", "kube_configmap_data_keys"},
+		},
 	}
 	for i, c := range cases {
 		c.Func = generator.ComposeMetricGenFuncs(configMapMetricFamilies(c.AllowAnnotationsList, c.AllowLabelsList))
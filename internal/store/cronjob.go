@@ -45,7 +45,7 @@ var (
 
 func cronJobMetricFamilies(allowAnnotationsList, allowLabelsList []string) []generator.FamilyGenerator {
 	return []generator.FamilyGenerator{
-		*generator.NewFamilyGeneratorWithStability(
+		*generator.NewLazyFamilyGenerator(
 			descCronJobAnnotationsName,
 			descCronJobAnnotationsHelp,
 			metric.Gauge,
@@ -67,7 +67,7 @@ func cronJobMetricFamilies(allowAnnotationsList, allowLabelsList []string) []gen
 				}
 			}),
 		),
-		*generator.NewFamilyGeneratorWithStability(
+		*generator.NewLazyFamilyGenerator(
 			descCronJobLabelsName,
 			descCronJobLabelsHelp,
 			metric.Gauge,
@@ -116,11 +116,7 @@ func cronJobMetricFamilies(allowAnnotationsList, allowLabelsList []string) []gen
 			wrapCronJobFunc(func(j *batchv1.CronJob) *metric.Family {
 				ms := []*metric.Metric{}
 				if !j.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						LabelKeys:   []string{},
-						LabelValues: []string{},
-						Value:       float64(j.CreationTimestamp.Unix()),
-					})
+					ms = append(ms, metric.TimestampMetric([]string{}, []string{}, j.CreationTimestamp.Time))
 				}
 
 				return &metric.Family{
@@ -156,11 +152,7 @@ func cronJobMetricFamilies(allowAnnotationsList, allowLabelsList []string) []gen
 				ms := []*metric.Metric{}
 
 				if j.Status.LastScheduleTime != nil {
-					ms = append(ms, &metric.Metric{
-						LabelKeys:   []string{},
-						LabelValues: []string{},
-						Value:       float64(j.Status.LastScheduleTime.Unix()),
-					})
+					ms = append(ms, metric.TimestampMetric([]string{}, []string{}, j.Status.LastScheduleTime.Time))
 				}
 
 				return &metric.Family{
@@ -178,11 +170,7 @@ func cronJobMetricFamilies(allowAnnotationsList, allowLabelsList []string) []gen
 				ms := []*metric.Metric{}
 
 				if j.Status.LastSuccessfulTime != nil {
-					ms = append(ms, &metric.Metric{
-						LabelKeys:   []string{},
-						LabelValues: []string{},
-						Value:       float64(j.Status.LastSuccessfulTime.Unix()),
-					})
+					ms = append(ms, metric.TimestampMetric([]string{}, []string{}, j.Status.LastSuccessfulTime.Time))
 				}
 
 				return &metric.Family{
@@ -249,11 +237,7 @@ func cronJobMetricFamilies(allowAnnotationsList, allowLabelsList []string) []gen
 				if err != nil {
 					panic(err)
 				} else if !*j.Spec.Suspend {
-					ms = append(ms, &metric.Metric{
-						LabelKeys:   []string{},
-						LabelValues: []string{},
-						Value:       float64(nextScheduledTime.Unix()),
-					})
+					ms = append(ms, metric.TimestampMetric([]string{}, []string{}, nextScheduledTime))
 				}
 
 				return &metric.Family{
@@ -0,0 +1,99 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"testing"
+
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
+)
+
+func TestCSIDriverStore(t *testing.T) {
+	startTime := 1501569018
+	metav1StartTime := metav1.Unix(int64(startTime), 0)
+	attachRequired := true
+	podInfoOnMount := false
+	fsGroupPolicy := storagev1.FileFSGroupPolicy
+
+	cases := []generateMetricsTestCase{
+		{
+			Obj: &storagev1.CSIDriver{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "ebs.csi.aws.com",
+					CreationTimestamp: metav1StartTime,
+				},
+				Spec: storagev1.CSIDriverSpec{
+					AttachRequired: &attachRequired,
+					PodInfoOnMount: &podInfoOnMount,
+					FSGroupPolicy:  &fsGroupPolicy,
+					VolumeLifecycleModes: []storagev1.VolumeLifecycleMode{
+						storagev1.VolumeLifecyclePersistent,
+						storagev1.VolumeLifecycleEphemeral,
+					},
+				},
+			},
+			Want: `
+					# HELP kube_csidriver_created Unix creation timestamp
+					# HELP kube_csidriver_info Information about csidriver.
+					# HELP kube_csidriver_volume_lifecycle_mode Volume lifecycle modes supported by the csidriver.
+					# TYPE kube_csidriver_created gauge
+					# TYPE kube_csidriver_info gauge
+					# TYPE kube_csidriver_volume_lifecycle_mode gauge
+					kube_csidriver_created{csidriver="ebs.csi.aws.com"} 1.501569018e+09
+					kube_csidriver_info{csidriver="ebs.csi.aws.com",attach_required="true",pod_info_on_mount="false",storage_capacity="",requires_republish="",fs_group_policy="File"} 1
+					kube_csidriver_volume_lifecycle_mode{csidriver="ebs.csi.aws.com",mode="Ephemeral"} 1
+					kube_csidriver_volume_lifecycle_mode{csidriver="ebs.csi.aws.com",mode="Persistent"} 1
+				`,
+			MetricNames: []string{
+				"kube_csidriver_created", "kube_csidriver_info", "kube_csidriver_volume_lifecycle_mode",
+			},
+		},
+		{
+			AllowAnnotationsList: []string{
+				"app.k8s.io/owner",
+			},
+			Obj: &storagev1.CSIDriver{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "csi.example.com",
+					Annotations: map[string]string{
+						"app.k8s.io/owner": "storage",
+					},
+				},
+			},
+			Want: `
+					# HELP kube_csidriver_annotations Kubernetes annotations converted to Prometheus labels.
+					# HELP kube_csidriver_labels Kubernetes labels converted to Prometheus labels.
+					# TYPE kube_csidriver_annotations gauge
+					# TYPE kube_csidriver_labels gauge
+					kube_csidriver_annotations{csidriver="csi.example.com",annotation_app_k8s_io_owner="storage"} 1
+				`,
+			MetricNames: []string{
+				"kube_csidriver_annotations", "kube_csidriver_labels",
+			},
+		},
+	}
+	for i, c := range cases {
+		c.Func = generator.ComposeMetricGenFuncs(csiDriverMetricFamilies(c.AllowAnnotationsList, nil))
+		c.Headers = generator.ExtractMetricFamilyHeaders(csiDriverMetricFamilies(c.AllowAnnotationsList, nil))
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
+		}
+	}
+}
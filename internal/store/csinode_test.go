@@ -0,0 +1,106 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"testing"
+
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
+)
+
+func TestCSINodeStore(t *testing.T) {
+	startTime := 1501569018
+	metav1StartTime := metav1.Unix(int64(startTime), 0)
+	var allocatable int32 = 25
+
+	cases := []generateMetricsTestCase{
+		{
+			Obj: &storagev1.CSINode{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "node-1",
+					CreationTimestamp: metav1StartTime,
+				},
+				Spec: storagev1.CSINodeSpec{
+					Drivers: []storagev1.CSINodeDriver{
+						{
+							Name:         "ebs.csi.aws.com",
+							NodeID:       "i-0123456789",
+							TopologyKeys: []string{"topology.ebs.csi.aws.com/zone", "topology.kubernetes.io/zone"},
+							Allocatable:  &storagev1.VolumeNodeResources{Count: &allocatable},
+						},
+						{
+							Name:   "nfs.csi.k8s.io",
+							NodeID: "node-1",
+						},
+					},
+				},
+			},
+			Want: `
+					# HELP kube_csinode_created Unix creation timestamp
+					# HELP kube_csinode_driver_allocatable_volumes Maximum number of volumes of the CSI driver which can be used on the node.
+					# HELP kube_csinode_driver_info Information about the CSI drivers installed on the node.
+					# HELP kube_csinode_driver_topology_key Topology keys of the CSI drivers installed on the node.
+					# TYPE kube_csinode_created gauge
+					# TYPE kube_csinode_driver_allocatable_volumes gauge
+					# TYPE kube_csinode_driver_info gauge
+					# TYPE kube_csinode_driver_topology_key gauge
+					kube_csinode_created{node="node-1"} 1.501569018e+09
+					kube_csinode_driver_allocatable_volumes{node="node-1",driver="ebs.csi.aws.com"} 25
+					kube_csinode_driver_info{node="node-1",driver="ebs.csi.aws.com",node_id="i-0123456789"} 1
+					kube_csinode_driver_info{node="node-1",driver="nfs.csi.k8s.io",node_id="node-1"} 1
+					kube_csinode_driver_topology_key{node="node-1",driver="ebs.csi.aws.com",key="topology.ebs.csi.aws.com/zone"} 1
+					kube_csinode_driver_topology_key{node="node-1",driver="ebs.csi.aws.com",key="topology.kubernetes.io/zone"} 1
+				`,
+			MetricNames: []string{
+				"kube_csinode_created", "kube_csinode_driver_allocatable_volumes", "kube_csinode_driver_info", "kube_csinode_driver_topology_key",
+			},
+		},
+		{
+			AllowLabelsList: []string{
+				"pool",
+			},
+			Obj: &storagev1.CSINode{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "node-2",
+					Labels: map[string]string{
+						"pool": "storage",
+					},
+				},
+			},
+			Want: `
+					# HELP kube_csinode_annotations Kubernetes annotations converted to Prometheus labels.
+					# HELP kube_csinode_labels Kubernetes labels converted to Prometheus labels.
+					# TYPE kube_csinode_annotations gauge
+					# TYPE kube_csinode_labels gauge
+					kube_csinode_labels{node="node-2",label_pool="storage"} 1
+				`,
+			MetricNames: []string{
+				"kube_csinode_annotations", "kube_csinode_labels",
+			},
+		},
+	}
+	for i, c := range cases {
+		c.Func = generator.ComposeMetricGenFuncs(csiNodeMetricFamilies(nil, c.AllowLabelsList))
+		c.Headers = generator.ExtractMetricFamilyHeaders(csiNodeMetricFamilies(nil, c.AllowLabelsList))
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
+		}
+	}
+}
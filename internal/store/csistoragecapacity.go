@@ -0,0 +1,244 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"context"
+	"sort"
+
+	basemetrics "k8s.io/component-base/metrics"
+
+	"k8s.io/kube-state-metrics/v2/pkg/metric"
+	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
+
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+var (
+	descCSIStorageCapacityAnnotationsName     = "kube_csistoragecapacity_annotations"
+	descCSIStorageCapacityAnnotationsHelp     = "Kubernetes annotations converted to Prometheus labels."
+	descCSIStorageCapacityLabelsName          = "kube_csistoragecapacity_labels"
+	descCSIStorageCapacityLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
+	descCSIStorageCapacityLabelsDefaultLabels = []string{"namespace", "csistoragecapacity"}
+)
+
+func csiStorageCapacityMetricFamilies(allowAnnotationsList, allowLabelsList []string) []generator.FamilyGenerator {
+	return []generator.FamilyGenerator{
+		*generator.NewFamilyGeneratorWithStability(
+			"kube_csistoragecapacity_info",
+			"Information about csistoragecapacity.",
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			wrapCSIStorageCapacityFunc(func(c *storagev1.CSIStorageCapacity) *metric.Family {
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							LabelKeys:   []string{"storageclass"},
+							LabelValues: []string{c.StorageClassName},
+							Value:       1,
+						},
+					},
+				}
+			}),
+		),
+		*generator.NewFamilyGeneratorWithStability(
+			"kube_csistoragecapacity_capacity_bytes",
+			"Capacity in bytes available for new volumes of the storage class in the node topology segment.",
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			wrapCSIStorageCapacityFunc(func(c *storagev1.CSIStorageCapacity) *metric.Family {
+				ms := []*metric.Metric{}
+
+				// An unset capacity is unknown, not zero.
+				if c.Capacity != nil {
+					ms = append(ms, &metric.Metric{
+						LabelKeys:   []string{"storageclass"},
+						LabelValues: []string{c.StorageClassName},
+						Value:       float64(c.Capacity.Value()),
+					})
+				}
+
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		),
+		*generator.NewFamilyGeneratorWithStability(
+			"kube_csistoragecapacity_maximum_volume_size_bytes",
+			"Maximum size in bytes of new volumes of the storage class in the node topology segment.",
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			wrapCSIStorageCapacityFunc(func(c *storagev1.CSIStorageCapacity) *metric.Family {
+				ms := []*metric.Metric{}
+
+				if c.MaximumVolumeSize != nil {
+					ms = append(ms, &metric.Metric{
+						LabelKeys:   []string{"storageclass"},
+						LabelValues: []string{c.StorageClassName},
+						Value:       float64(c.MaximumVolumeSize.Value()),
+					})
+				}
+
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		),
+		*generator.NewFamilyGeneratorWithStability(
+			"kube_csistoragecapacity_node_topology",
+			"Node label requirements of the node topology segment of the csistoragecapacity, one series per value.",
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			wrapCSIStorageCapacityFunc(func(c *storagev1.CSIStorageCapacity) *metric.Family {
+				ms := []*metric.Metric{}
+
+				if c.NodeTopology == nil {
+					return &metric.Family{
+						Metrics: ms,
+					}
+				}
+
+				keys := make([]string, 0, len(c.NodeTopology.MatchLabels))
+				for key := range c.NodeTopology.MatchLabels {
+					keys = append(keys, key)
+				}
+				sort.Strings(keys)
+				for _, key := range keys {
+					ms = append(ms, &metric.Metric{
+						LabelKeys:   []string{"key", "operator", "value"},
+						LabelValues: []string{key, string(metav1.LabelSelectorOpIn), c.NodeTopology.MatchLabels[key]},
+						Value:       1,
+					})
+				}
+				for _, req := range c.NodeTopology.MatchExpressions {
+					values := req.Values
+					if len(values) == 0 {
+						// Exists and DoesNotExist requirements have no values.
+						values = []string{""}
+					}
+					for _, value := range values {
+						ms = append(ms, &metric.Metric{
+							LabelKeys:   []string{"key", "operator", "value"},
+							LabelValues: []string{req.Key, string(req.Operator), value},
+							Value:       1,
+						})
+					}
+				}
+
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		),
+		*generator.NewFamilyGeneratorWithStability(
+			"kube_csistoragecapacity_created",
+			"Unix creation timestamp",
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			wrapCSIStorageCapacityFunc(func(c *storagev1.CSIStorageCapacity) *metric.Family {
+				ms := []*metric.Metric{}
+				if !c.CreationTimestamp.IsZero() {
+					ms = append(ms, metric.TimestampMetric(nil, nil, c.CreationTimestamp.Time))
+				}
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		),
+		*generator.NewLazyFamilyGenerator(
+			descCSIStorageCapacityAnnotationsName,
+			descCSIStorageCapacityAnnotationsHelp,
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			wrapCSIStorageCapacityFunc(func(c *storagev1.CSIStorageCapacity) *metric.Family {
+				if len(allowAnnotationsList) == 0 {
+					return &metric.Family{}
+				}
+				annotationKeys, annotationValues := createPrometheusLabelKeysValues("annotation", c.Annotations, allowAnnotationsList)
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							LabelKeys:   annotationKeys,
+							LabelValues: annotationValues,
+							Value:       1,
+						},
+					},
+				}
+			}),
+		),
+		*generator.NewLazyFamilyGenerator(
+			descCSIStorageCapacityLabelsName,
+			descCSIStorageCapacityLabelsHelp,
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			wrapCSIStorageCapacityFunc(func(c *storagev1.CSIStorageCapacity) *metric.Family {
+				if len(allowLabelsList) == 0 {
+					return &metric.Family{}
+				}
+				labelKeys, labelValues := createPrometheusLabelKeysValues("label", c.Labels, allowLabelsList)
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							LabelKeys:   labelKeys,
+							LabelValues: labelValues,
+							Value:       1,
+						},
+					},
+				}
+			}),
+		),
+	}
+}
+
+func wrapCSIStorageCapacityFunc(f func(*storagev1.CSIStorageCapacity) *metric.Family) func(interface{}) *metric.Family {
+	return func(obj interface{}) *metric.Family {
+		csiStorageCapacity := obj.(*storagev1.CSIStorageCapacity)
+
+		metricFamily := f(csiStorageCapacity)
+
+		for _, m := range metricFamily.Metrics {
+			m.LabelKeys, m.LabelValues = mergeKeyValues(descCSIStorageCapacityLabelsDefaultLabels, []string{csiStorageCapacity.Namespace, csiStorageCapacity.Name}, m.LabelKeys, m.LabelValues)
+		}
+
+		return metricFamily
+	}
+}
+
+func createCSIStorageCapacityListWatch(kubeClient clientset.Interface, ns string, fieldSelector string) cache.ListerWatcher {
+	return &cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			opts.FieldSelector = fieldSelector
+			return kubeClient.StorageV1().CSIStorageCapacities(ns).List(context.TODO(), opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			opts.FieldSelector = fieldSelector
+			return kubeClient.StorageV1().CSIStorageCapacities(ns).Watch(context.TODO(), opts)
+		},
+	}
+}
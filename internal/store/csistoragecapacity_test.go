@@ -0,0 +1,108 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"testing"
+
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
+)
+
+func TestCSIStorageCapacityStore(t *testing.T) {
+	startTime := 1501569018
+	metav1StartTime := metav1.Unix(int64(startTime), 0)
+	capacity := resource.MustParse("100Gi")
+	maximumVolumeSize := resource.MustParse("16Ti")
+
+	cases := []generateMetricsTestCase{
+		{
+			Obj: &storagev1.CSIStorageCapacity{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "csisc-abcde",
+					Namespace:         "kube-system",
+					CreationTimestamp: metav1StartTime,
+				},
+				StorageClassName: "local-ssd",
+				Capacity:         &capacity,
+				NodeTopology: &metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						"topology.kubernetes.io/zone":   "zone-a",
+						"topology.kubernetes.io/region": "region-1",
+					},
+					MatchExpressions: []metav1.LabelSelectorRequirement{
+						{Key: "kubernetes.io/hostname", Operator: metav1.LabelSelectorOpIn, Values: []string{"node-1", "node-2"}},
+					},
+				},
+			},
+			Want: `
+					# HELP kube_csistoragecapacity_capacity_bytes Capacity in bytes available for new volumes of the storage class in the node topology segment.
+					# HELP kube_csistoragecapacity_created Unix creation timestamp
+					# HELP kube_csistoragecapacity_info Information about csistoragecapacity.
+					# HELP kube_csistoragecapacity_maximum_volume_size_bytes Maximum size in bytes of new volumes of the storage class in the node topology segment.
+					# HELP kube_csistoragecapacity_node_topology Node label requirements of the node topology segment of the csistoragecapacity, one series per value.
+					# TYPE kube_csistoragecapacity_capacity_bytes gauge
+					# TYPE kube_csistoragecapacity_created gauge
+					# TYPE kube_csistoragecapacity_info gauge
+					# TYPE kube_csistoragecapacity_maximum_volume_size_bytes gauge
+					# TYPE kube_csistoragecapacity_node_topology gauge
+					kube_csistoragecapacity_capacity_bytes{namespace="kube-system",csistoragecapacity="csisc-abcde",storageclass="local-ssd"} 1.073741824e+11
+					kube_csistoragecapacity_created{namespace="kube-system",csistoragecapacity="csisc-abcde"} 1.501569018e+09
+					kube_csistoragecapacity_info{namespace="kube-system",csistoragecapacity="csisc-abcde",storageclass="local-ssd"} 1
+					kube_csistoragecapacity_node_topology{namespace="kube-system",csistoragecapacity="csisc-abcde",key="kubernetes.io/hostname",operator="In",value="node-1"} 1
+					kube_csistoragecapacity_node_topology{namespace="kube-system",csistoragecapacity="csisc-abcde",key="kubernetes.io/hostname",operator="In",value="node-2"} 1
+					kube_csistoragecapacity_node_topology{namespace="kube-system",csistoragecapacity="csisc-abcde",key="topology.kubernetes.io/region",operator="In",value="region-1"} 1
+					kube_csistoragecapacity_node_topology{namespace="kube-system",csistoragecapacity="csisc-abcde",key="topology.kubernetes.io/zone",operator="In",value="zone-a"} 1
+				`,
+			MetricNames: []string{
+				"kube_csistoragecapacity_capacity_bytes", "kube_csistoragecapacity_created", "kube_csistoragecapacity_info", "kube_csistoragecapacity_maximum_volume_size_bytes", "kube_csistoragecapacity_node_topology",
+			},
+		},
+		{
+			Obj: &storagev1.CSIStorageCapacity{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "csisc-fghij",
+					Namespace: "kube-system",
+				},
+				StorageClassName:  "local-ssd",
+				MaximumVolumeSize: &maximumVolumeSize,
+			},
+			Want: `
+					# HELP kube_csistoragecapacity_capacity_bytes Capacity in bytes available for new volumes of the storage class in the node topology segment.
+					# HELP kube_csistoragecapacity_maximum_volume_size_bytes Maximum size in bytes of new volumes of the storage class in the node topology segment.
+					# HELP kube_csistoragecapacity_node_topology Node label requirements of the node topology segment of the csistoragecapacity, one series per value.
+					# TYPE kube_csistoragecapacity_capacity_bytes gauge
+					# TYPE kube_csistoragecapacity_maximum_volume_size_bytes gauge
+					# TYPE kube_csistoragecapacity_node_topology gauge
+					kube_csistoragecapacity_maximum_volume_size_bytes{namespace="kube-system",csistoragecapacity="csisc-fghij",storageclass="local-ssd"} 1.7592186044416e+13
+				`,
+			MetricNames: []string{
+				"kube_csistoragecapacity_capacity_bytes", "kube_csistoragecapacity_maximum_volume_size_bytes", "kube_csistoragecapacity_node_topology",
+			},
+		},
+	}
+	for i, c := range cases {
+		c.Func = generator.ComposeMetricGenFuncs(csiStorageCapacityMetricFamilies(nil, nil))
+		c.Headers = generator.ExtractMetricFamilyHeaders(csiStorageCapacityMetricFamilies(nil, nil))
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
+		}
+	}
+}
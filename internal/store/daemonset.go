@@ -20,8 +20,10 @@ import (
 	"context"
 
 	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/watch"
 	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
@@ -51,11 +53,7 @@ func daemonSetMetricFamilies(allowAnnotationsList, allowLabelsList []string) []g
 				ms := []*metric.Metric{}
 
 				if !d.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						LabelKeys:   []string{},
-						LabelValues: []string{},
-						Value:       float64(d.CreationTimestamp.Unix()),
-					})
+					ms = append(ms, metric.TimestampMetric([]string{}, []string{}, d.CreationTimestamp.Time))
 				}
 
 				return &metric.Family{
@@ -189,6 +187,36 @@ func daemonSetMetricFamilies(allowAnnotationsList, allowLabelsList []string) []g
 				}
 			}),
 		),
+		*generator.NewFamilyGeneratorWithStability(
+			"kube_daemonset_generation_mismatch",
+			"The difference between the generation of the desired state and the generation observed by the daemon set controller, which is positive until the controller processed the latest change.",
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			wrapDaemonSetFunc(func(d *v1.DaemonSet) *metric.Family {
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							Value: float64(d.ObjectMeta.Generation - d.Status.ObservedGeneration),
+						},
+					},
+				}
+			}),
+		),
+		*generator.NewFamilyGeneratorWithStability(
+			"kube_daemonset_status_condition",
+			"The current status conditions of a daemonset.",
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			wrapDaemonSetFunc(func(d *v1.DaemonSet) *metric.Family {
+				return &metric.Family{
+					Metrics: statusConditionMetrics(d.Status.Conditions, func(c v1.DaemonSetCondition) (string, corev1.ConditionStatus) {
+						return string(c.Type), c.Status
+					}),
+				}
+			}),
+		),
 		*generator.NewFamilyGeneratorWithStability(
 			"kube_daemonset_status_updated_number_scheduled",
 			"The total number of nodes that are running updated daemon pod",
@@ -205,6 +233,97 @@ func daemonSetMetricFamilies(allowAnnotationsList, allowLabelsList []string) []g
 				}
 			}),
 		),
+		*generator.NewFamilyGeneratorWithStability(
+			"kube_daemonset_status_number_outdated",
+			"The number of nodes that should run the daemon pod and are not running the updated daemon pod.",
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			wrapDaemonSetFunc(func(d *v1.DaemonSet) *metric.Family {
+				outdated := d.Status.DesiredNumberScheduled - d.Status.UpdatedNumberScheduled
+				if outdated < 0 {
+					outdated = 0
+				}
+
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							Value: float64(outdated),
+						},
+					},
+				}
+			}),
+		),
+		*generator.NewFamilyGeneratorWithStability(
+			"kube_daemonset_spec_update_strategy",
+			"The update strategy of a daemonset.",
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			wrapDaemonSetFunc(func(d *v1.DaemonSet) *metric.Family {
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							LabelKeys:   []string{"type"},
+							LabelValues: []string{string(d.Spec.UpdateStrategy.Type)},
+							Value:       1,
+						},
+					},
+				}
+			}),
+		),
+		*generator.NewFamilyGeneratorWithStability(
+			"kube_daemonset_spec_strategy_rollingupdate_max_unavailable",
+			"Maximum number of nodes whose daemon pod can be unavailable during a rolling update of a daemonset.",
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			wrapDaemonSetFunc(func(d *v1.DaemonSet) *metric.Family {
+				if d.Spec.UpdateStrategy.RollingUpdate == nil {
+					return &metric.Family{}
+				}
+
+				// The daemonset controller rounds percentages of the desired
+				// number of nodes up.
+				maxUnavailable, err := intstr.GetScaledValueFromIntOrPercent(d.Spec.UpdateStrategy.RollingUpdate.MaxUnavailable, int(d.Status.DesiredNumberScheduled), true)
+				if err != nil {
+					return &metric.Family{}
+				}
+
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							Value: float64(maxUnavailable),
+						},
+					},
+				}
+			}),
+		),
+		*generator.NewFamilyGeneratorWithStability(
+			"kube_daemonset_spec_strategy_rollingupdate_max_surge",
+			"Maximum number of nodes which can run an updated daemon pod in addition to the old one during a rolling update of a daemonset.",
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			wrapDaemonSetFunc(func(d *v1.DaemonSet) *metric.Family {
+				if d.Spec.UpdateStrategy.RollingUpdate == nil {
+					return &metric.Family{}
+				}
+
+				maxSurge, err := intstr.GetScaledValueFromIntOrPercent(d.Spec.UpdateStrategy.RollingUpdate.MaxSurge, int(d.Status.DesiredNumberScheduled), true)
+				if err != nil {
+					return &metric.Family{}
+				}
+
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							Value: float64(maxSurge),
+						},
+					},
+				}
+			}),
+		),
 		*generator.NewFamilyGeneratorWithStability(
 			"kube_daemonset_metadata_generation",
 			"Sequence number representing a specific generation of the desired state.",
@@ -223,7 +342,7 @@ func daemonSetMetricFamilies(allowAnnotationsList, allowLabelsList []string) []g
 				}
 			}),
 		),
-		*generator.NewFamilyGeneratorWithStability(
+		*generator.NewLazyFamilyGenerator(
 			descDaemonSetAnnotationsName,
 			descDaemonSetAnnotationsHelp,
 			metric.Gauge,
@@ -245,7 +364,7 @@ func daemonSetMetricFamilies(allowAnnotationsList, allowLabelsList []string) []g
 				}
 			}),
 		),
-		*generator.NewFamilyGeneratorWithStability(
+		*generator.NewLazyFamilyGenerator(
 			descDaemonSetLabelsName,
 			descDaemonSetLabelsHelp,
 			metric.Gauge,
@@ -22,11 +22,15 @@ import (
 
 	v1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 
 	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
 )
 
 func TestDaemonSetStore(t *testing.T) {
+	maxUnavailable := intstr.FromString("25%")
+	maxSurge := intstr.FromInt(1)
+
 	cases := []generateMetricsTestCase{
 		{
 			AllowAnnotationsList: []string{
@@ -228,6 +232,79 @@ func TestDaemonSetStore(t *testing.T) {
 				"kube_daemonset_status_updated_number_scheduled",
 			},
 		},
+		{
+			Obj: &v1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "ds-rolling",
+					Namespace: "ns1",
+				},
+				Spec: v1.DaemonSetSpec{
+					UpdateStrategy: v1.DaemonSetUpdateStrategy{
+						Type: v1.RollingUpdateDaemonSetStrategyType,
+						RollingUpdate: &v1.RollingUpdateDaemonSet{
+							MaxUnavailable: &maxUnavailable,
+							MaxSurge:       &maxSurge,
+						},
+					},
+				},
+				Status: v1.DaemonSetStatus{
+					DesiredNumberScheduled: 9,
+					UpdatedNumberScheduled: 4,
+				},
+			},
+			Want: `
+				# HELP kube_daemonset_spec_strategy_rollingupdate_max_surge Maximum number of nodes which can run an updated daemon pod in addition to the old one during a rolling update of a daemonset.
+				# HELP kube_daemonset_spec_strategy_rollingupdate_max_unavailable Maximum number of nodes whose daemon pod can be unavailable during a rolling update of a daemonset.
+				# HELP kube_daemonset_spec_update_strategy The update strategy of a daemonset.
+				# HELP kube_daemonset_status_number_outdated The number of nodes that should run the daemon pod and are not running the updated daemon pod.
+				# TYPE kube_daemonset_spec_strategy_rollingupdate_max_surge gauge
+				# TYPE kube_daemonset_spec_strategy_rollingupdate_max_unavailable gauge
+				# TYPE kube_daemonset_spec_update_strategy gauge
+				# TYPE kube_daemonset_status_number_outdated gauge
+				kube_daemonset_spec_strategy_rollingupdate_max_surge{daemonset="ds-rolling",namespace="ns1"} 1
+				kube_daemonset_spec_strategy_rollingupdate_max_unavailable{daemonset="ds-rolling",namespace="ns1"} 3
+				kube_daemonset_spec_update_strategy{daemonset="ds-rolling",namespace="ns1",type="RollingUpdate"} 1
+				kube_daemonset_status_number_outdated{daemonset="ds-rolling",namespace="ns1"} 5
+`,
+			MetricNames: []string{
+				"kube_daemonset_spec_strategy_rollingupdate_max_surge",
+				"kube_daemonset_spec_strategy_rollingupdate_max_unavailable",
+				"kube_daemonset_spec_update_strategy",
+				"kube_daemonset_status_number_outdated",
+			},
+		},
+		{
+			Obj: &v1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "ds-ondelete",
+					Namespace: "ns1",
+				},
+				Spec: v1.DaemonSetSpec{
+					UpdateStrategy: v1.DaemonSetUpdateStrategy{
+						Type: v1.OnDeleteDaemonSetStrategyType,
+					},
+				},
+				Status: v1.DaemonSetStatus{
+					DesiredNumberScheduled: 2,
+					UpdatedNumberScheduled: 2,
+				},
+			},
+			Want: `
+				# HELP kube_daemonset_spec_strategy_rollingupdate_max_unavailable Maximum number of nodes whose daemon pod can be unavailable during a rolling update of a daemonset.
+				# HELP kube_daemonset_spec_update_strategy The update strategy of a daemonset.
+				# HELP kube_daemonset_status_number_outdated The number of nodes that should run the daemon pod and are not running the updated daemon pod.
+				# TYPE kube_daemonset_spec_strategy_rollingupdate_max_unavailable gauge
+				# TYPE kube_daemonset_spec_update_strategy gauge
+				# TYPE kube_daemonset_status_number_outdated gauge
+				kube_daemonset_spec_update_strategy{daemonset="ds-ondelete",namespace="ns1",type="OnDelete"} 1
+				kube_daemonset_status_number_outdated{daemonset="ds-ondelete",namespace="ns1"} 0
+`,
+			MetricNames: []string{
+				"kube_daemonset_spec_strategy_rollingupdate_max_unavailable",
+				"kube_daemonset_spec_update_strategy",
+				"kube_daemonset_status_number_outdated",
+			},
+		},
 	}
 	for i, c := range cases {
 		c.Func = generator.ComposeMetricGenFuncs(daemonSetMetricFamilies(c.AllowAnnotationsList, c.AllowLabelsList))
@@ -25,6 +25,7 @@ import (
 	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
 
 	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -53,9 +54,7 @@ func deploymentMetricFamilies(allowAnnotationsList, allowLabelsList []string) []
 				ms := []*metric.Metric{}
 
 				if !d.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						Value: float64(d.CreationTimestamp.Unix()),
-					})
+					ms = append(ms, metric.TimestampMetric(nil, nil, d.CreationTimestamp.Time))
 				}
 
 				return &metric.Family{
@@ -159,6 +158,22 @@ func deploymentMetricFamilies(allowAnnotationsList, allowLabelsList []string) []
 				}
 			}),
 		),
+		*generator.NewFamilyGeneratorWithStability(
+			"kube_deployment_generation_mismatch",
+			"The difference between the generation of the desired state and the generation observed by the deployment controller, which is positive until the controller processed the latest change.",
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			wrapDeploymentFunc(func(d *v1.Deployment) *metric.Family {
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							Value: float64(d.ObjectMeta.Generation - d.Status.ObservedGeneration),
+						},
+					},
+				}
+			}),
+		),
 		*generator.NewFamilyGeneratorWithStability(
 			"kube_deployment_status_condition",
 			"The current status conditions of a deployment.",
@@ -166,19 +181,9 @@ func deploymentMetricFamilies(allowAnnotationsList, allowLabelsList []string) []
 			basemetrics.STABLE,
 			"",
 			wrapDeploymentFunc(func(d *v1.Deployment) *metric.Family {
-				ms := make([]*metric.Metric, len(d.Status.Conditions)*len(conditionStatuses))
-
-				for i, c := range d.Status.Conditions {
-					conditionMetrics := addConditionMetrics(c.Status)
-
-					for j, m := range conditionMetrics {
-						metric := m
-
-						metric.LabelKeys = []string{"condition", "status"}
-						metric.LabelValues = append([]string{string(c.Type)}, metric.LabelValues...)
-						ms[i*len(conditionStatuses)+j] = metric
-					}
-				}
+				ms := statusConditionMetrics(d.Status.Conditions, func(c v1.DeploymentCondition) (string, corev1.ConditionStatus) {
+					return string(c.Type), c.Status
+				})
 
 				return &metric.Family{
 					Metrics: ms,
@@ -283,7 +288,7 @@ func deploymentMetricFamilies(allowAnnotationsList, allowLabelsList []string) []
 				}
 			}),
 		),
-		*generator.NewFamilyGeneratorWithStability(
+		*generator.NewLazyFamilyGenerator(
 			descDeploymentAnnotationsName,
 			descDeploymentAnnotationsHelp,
 			metric.Gauge,
@@ -305,7 +310,7 @@ func deploymentMetricFamilies(allowAnnotationsList, allowLabelsList []string) []
 				}
 			}),
 		),
-		*generator.NewFamilyGeneratorWithStability(
+		*generator.NewLazyFamilyGenerator(
 			descDeploymentLabelsName,
 			descDeploymentLabelsHelp,
 			metric.Gauge,
@@ -47,6 +47,8 @@ func TestDeploymentStore(t *testing.T) {
 		# TYPE kube_deployment_annotations gauge
 		# HELP kube_deployment_created [STABLE] Unix creation timestamp
 		# TYPE kube_deployment_created gauge
+		# HELP kube_deployment_generation_mismatch The difference between the generation of the desired state and the generation observed by the deployment controller, which is positive until the controller processed the latest change.
+		# TYPE kube_deployment_generation_mismatch gauge
 		# HELP kube_deployment_metadata_generation [STABLE] Sequence number representing a specific generation of the desired state.
 		# TYPE kube_deployment_metadata_generation gauge
 		# HELP kube_deployment_spec_paused [STABLE] Whether the deployment is paused and will not be processed by the deployment controller.
@@ -115,6 +117,7 @@ func TestDeploymentStore(t *testing.T) {
 			Want: metadata + `
         kube_deployment_annotations{annotation_company_io_team="my-brilliant-team",deployment="depl1",namespace="ns1"} 1
         kube_deployment_created{deployment="depl1",namespace="ns1"} 1.5e+09
+        kube_deployment_generation_mismatch{deployment="depl1",namespace="ns1"} -90
         kube_deployment_metadata_generation{deployment="depl1",namespace="ns1"} 21
         kube_deployment_spec_paused{deployment="depl1",namespace="ns1"} 0
         kube_deployment_spec_replicas{deployment="depl1",namespace="ns1"} 200
@@ -169,6 +172,7 @@ func TestDeploymentStore(t *testing.T) {
 				},
 			},
 			Want: metadata + `
+        kube_deployment_generation_mismatch{deployment="depl2",namespace="ns2"} -1097
         kube_deployment_metadata_generation{deployment="depl2",namespace="ns2"} 14
         kube_deployment_spec_paused{deployment="depl2",namespace="ns2"} 1
         kube_deployment_spec_replicas{deployment="depl2",namespace="ns2"} 5
@@ -68,10 +68,7 @@ func endpointMetricFamilies(allowAnnotationsList, allowLabelsList []string) []ge
 				ms := []*metric.Metric{}
 
 				if !e.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-
-						Value: float64(e.CreationTimestamp.Unix()),
-					})
+					ms = append(ms, metric.TimestampMetric(nil, nil, e.CreationTimestamp.Time))
 				}
 
 				return &metric.Family{
@@ -79,7 +76,7 @@ func endpointMetricFamilies(allowAnnotationsList, allowLabelsList []string) []ge
 				}
 			}),
 		),
-		*generator.NewFamilyGeneratorWithStability(
+		*generator.NewLazyFamilyGenerator(
 			descEndpointAnnotationsName,
 			descEndpointAnnotationsHelp,
 			metric.Gauge,
@@ -101,7 +98,7 @@ func endpointMetricFamilies(allowAnnotationsList, allowLabelsList []string) []ge
 				}
 			}),
 		),
-		*generator.NewFamilyGeneratorWithStability(
+		*generator.NewLazyFamilyGenerator(
 			descEndpointLabelsName,
 			descEndpointLabelsHelp,
 			metric.Gauge,
@@ -15,6 +15,7 @@ package store
 
 import (
 	"context"
+	"sort"
 	"strconv"
 
 	basemetrics "k8s.io/component-base/metrics"
@@ -65,9 +66,7 @@ func endpointSliceMetricFamilies(allowAnnotationsList, allowLabelsList []string)
 			wrapEndpointSliceFunc(func(s *discoveryv1.EndpointSlice) *metric.Family {
 				ms := []*metric.Metric{}
 				if !s.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						Value: float64(s.CreationTimestamp.Unix()),
-					})
+					ms = append(ms, metric.TimestampMetric(nil, nil, s.CreationTimestamp.Time))
 				}
 				return &metric.Family{
 					Metrics: ms,
@@ -186,6 +185,84 @@ func endpointSliceMetricFamilies(allowAnnotationsList, allowLabelsList []string)
 				}
 			}),
 		),
+		*generator.NewFamilyGeneratorWithStability(
+			"kube_endpointslice_endpoints_condition",
+			"Number of endpoints of the endpointslice by condition and status of the condition.",
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			wrapEndpointSliceFunc(func(e *discoveryv1.EndpointSlice) *metric.Family {
+				conditions := []struct {
+					name   string
+					status func(c discoveryv1.EndpointConditions) *bool
+				}{
+					{"ready", func(c discoveryv1.EndpointConditions) *bool { return c.Ready }},
+					{"serving", func(c discoveryv1.EndpointConditions) *bool { return c.Serving }},
+					{"terminating", func(c discoveryv1.EndpointConditions) *bool { return c.Terminating }},
+				}
+
+				m := make([]*metric.Metric, 0, len(conditions)*len(endpointConditionStatuses))
+				for _, condition := range conditions {
+					counts := map[string]float64{}
+					for _, ep := range e.Endpoints {
+						counts[endpointConditionStatus(condition.status(ep.Conditions))]++
+					}
+					for _, status := range endpointConditionStatuses {
+						m = append(m, &metric.Metric{
+							LabelKeys:   []string{"condition", "status"},
+							LabelValues: []string{condition.name, status},
+							Value:       counts[status],
+						})
+					}
+				}
+				return &metric.Family{
+					Metrics: m,
+				}
+			}),
+		),
+		*generator.NewFamilyGeneratorWithStability(
+			"kube_endpointslice_endpoints_zone",
+			"Number of endpoints of the endpointslice by zone and readiness.",
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			wrapEndpointSliceFunc(func(e *discoveryv1.EndpointSlice) *metric.Family {
+				type zoneReadiness struct {
+					zone, ready string
+				}
+				counts := map[zoneReadiness]float64{}
+				for _, ep := range e.Endpoints {
+					zone := ""
+					if ep.Zone != nil {
+						zone = *ep.Zone
+					}
+					counts[zoneReadiness{zone, endpointConditionStatus(ep.Conditions.Ready)}]++
+				}
+
+				keys := make([]zoneReadiness, 0, len(counts))
+				for k := range counts {
+					keys = append(keys, k)
+				}
+				sort.Slice(keys, func(i, j int) bool {
+					if keys[i].zone != keys[j].zone {
+						return keys[i].zone < keys[j].zone
+					}
+					return keys[i].ready < keys[j].ready
+				})
+
+				m := make([]*metric.Metric, 0, len(keys))
+				for _, k := range keys {
+					m = append(m, &metric.Metric{
+						LabelKeys:   []string{"zone", "ready"},
+						LabelValues: []string{k.zone, k.ready},
+						Value:       counts[k],
+					})
+				}
+				return &metric.Family{
+					Metrics: m,
+				}
+			}),
+		),
 
 		*generator.NewFamilyGeneratorWithStability(
 			"kube_endpointslice_ports",
@@ -207,7 +284,7 @@ func endpointSliceMetricFamilies(allowAnnotationsList, allowLabelsList []string)
 				}
 			}),
 		),
-		*generator.NewFamilyGeneratorWithStability(
+		*generator.NewLazyFamilyGenerator(
 			descEndpointSliceAnnotationsName,
 			descEndpointSliceAnnotationsHelp,
 			metric.Gauge,
@@ -229,7 +306,7 @@ func endpointSliceMetricFamilies(allowAnnotationsList, allowLabelsList []string)
 				}
 			}),
 		),
-		*generator.NewFamilyGeneratorWithStability(
+		*generator.NewLazyFamilyGenerator(
 			descEndpointSliceLabelsName,
 			descEndpointSliceLabelsHelp,
 			metric.Gauge,
@@ -254,6 +331,18 @@ func endpointSliceMetricFamilies(allowAnnotationsList, allowLabelsList []string)
 	}
 }
 
+// endpointConditionStatuses are the statuses of the conditions of endpoints.
+// Conditions which are not set are unknown.
+var endpointConditionStatuses = []string{"true", "false", "unknown"}
+
+// endpointConditionStatus returns the status of an endpoint condition.
+func endpointConditionStatus(status *bool) string {
+	if status == nil {
+		return "unknown"
+	}
+	return strconv.FormatBool(*status)
+}
+
 func wrapEndpointSliceFunc(f func(*discoveryv1.EndpointSlice) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
 		endpointSlice := obj.(*discoveryv1.EndpointSlice)
@@ -34,6 +34,7 @@ func TestEndpointSliceStore(t *testing.T) {
 	zone := "west"
 	ready := true
 	terminating := false
+	notReady := false
 	addresses := []string{"10.0.0.1", "192.168.1.10"}
 
 	cases := []generateMetricsTestCase{
@@ -113,9 +114,23 @@ func TestEndpointSliceStore(t *testing.T) {
 			},
 			Want: `
 					# HELP kube_endpointslice_endpoints Endpoints attached to the endpointslice.
+					# HELP kube_endpointslice_endpoints_condition Number of endpoints of the endpointslice by condition and status of the condition.
 					# HELP kube_endpointslice_endpoints_hints Topology routing hints attached to endpoints
+					# HELP kube_endpointslice_endpoints_zone Number of endpoints of the endpointslice by zone and readiness.
 					# TYPE kube_endpointslice_endpoints gauge
+					# TYPE kube_endpointslice_endpoints_condition gauge
 					# TYPE kube_endpointslice_endpoints_hints gauge
+					# TYPE kube_endpointslice_endpoints_zone gauge
+					kube_endpointslice_endpoints_condition{condition="ready",endpointslice="test_endpointslice-endpoints",status="false"} 0
+					kube_endpointslice_endpoints_condition{condition="ready",endpointslice="test_endpointslice-endpoints",status="true"} 1
+					kube_endpointslice_endpoints_condition{condition="ready",endpointslice="test_endpointslice-endpoints",status="unknown"} 0
+					kube_endpointslice_endpoints_condition{condition="serving",endpointslice="test_endpointslice-endpoints",status="false"} 0
+					kube_endpointslice_endpoints_condition{condition="serving",endpointslice="test_endpointslice-endpoints",status="true"} 0
+					kube_endpointslice_endpoints_condition{condition="serving",endpointslice="test_endpointslice-endpoints",status="unknown"} 1
+					kube_endpointslice_endpoints_condition{condition="terminating",endpointslice="test_endpointslice-endpoints",status="false"} 1
+					kube_endpointslice_endpoints_condition{condition="terminating",endpointslice="test_endpointslice-endpoints",status="true"} 0
+					kube_endpointslice_endpoints_condition{condition="terminating",endpointslice="test_endpointslice-endpoints",status="unknown"} 0
+					kube_endpointslice_endpoints_zone{endpointslice="test_endpointslice-endpoints",ready="true",zone="west"} 1
 					kube_endpointslice_endpoints{address="10.0.0.1",endpoint_nodename="node",endpoint_zone="west",endpointslice="test_endpointslice-endpoints",hostname="host",ready="true",terminating="false"} 1
 					kube_endpointslice_endpoints{address="192.168.1.10",endpoint_nodename="node",endpoint_zone="west",endpointslice="test_endpointslice-endpoints",hostname="host",ready="true",terminating="false"} 1
 				  `,
@@ -150,9 +165,23 @@ func TestEndpointSliceStore(t *testing.T) {
 			},
 			Want: `
 					# HELP kube_endpointslice_endpoints Endpoints attached to the endpointslice.
+					# HELP kube_endpointslice_endpoints_condition Number of endpoints of the endpointslice by condition and status of the condition.
 					# HELP kube_endpointslice_endpoints_hints Topology routing hints attached to endpoints
+					# HELP kube_endpointslice_endpoints_zone Number of endpoints of the endpointslice by zone and readiness.
 					# TYPE kube_endpointslice_endpoints gauge
+					# TYPE kube_endpointslice_endpoints_condition gauge
         			# TYPE kube_endpointslice_endpoints_hints gauge
+					# TYPE kube_endpointslice_endpoints_zone gauge
+					kube_endpointslice_endpoints_condition{condition="ready",endpointslice="test_endpointslice-endpoints",status="false"} 0
+					kube_endpointslice_endpoints_condition{condition="ready",endpointslice="test_endpointslice-endpoints",status="true"} 1
+					kube_endpointslice_endpoints_condition{condition="ready",endpointslice="test_endpointslice-endpoints",status="unknown"} 0
+					kube_endpointslice_endpoints_condition{condition="serving",endpointslice="test_endpointslice-endpoints",status="false"} 0
+					kube_endpointslice_endpoints_condition{condition="serving",endpointslice="test_endpointslice-endpoints",status="true"} 0
+					kube_endpointslice_endpoints_condition{condition="serving",endpointslice="test_endpointslice-endpoints",status="unknown"} 1
+					kube_endpointslice_endpoints_condition{condition="terminating",endpointslice="test_endpointslice-endpoints",status="false"} 1
+					kube_endpointslice_endpoints_condition{condition="terminating",endpointslice="test_endpointslice-endpoints",status="true"} 0
+					kube_endpointslice_endpoints_condition{condition="terminating",endpointslice="test_endpointslice-endpoints",status="unknown"} 0
+					kube_endpointslice_endpoints_zone{endpointslice="test_endpointslice-endpoints",ready="true",zone="west"} 1
          			kube_endpointslice_endpoints_hints{address="10.0.0.1",endpointslice="test_endpointslice-endpoints",for_zone="zone1"} 1
         			kube_endpointslice_endpoints{address="10.0.0.1",endpoint_nodename="node",endpoint_zone="west",endpointslice="test_endpointslice-endpoints",hostname="host",ready="true",terminating="false"} 1
         			kube_endpointslice_endpoints{address="192.168.1.10",endpoint_nodename="node",endpoint_zone="west",endpointslice="test_endpointslice-endpoints",hostname="host",ready="true",terminating="false"} 1
@@ -162,6 +191,43 @@ func TestEndpointSliceStore(t *testing.T) {
 				"kube_endpointslice_endpoints",
 			},
 		},
+		{
+			Obj: &discoveryv1.EndpointSlice{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test_endpointslice-zones",
+				},
+				AddressType: "IPv4",
+				Endpoints: []discoveryv1.Endpoint{
+					{
+						Conditions: discoveryv1.EndpointConditions{
+							Ready: &ready,
+						},
+						Zone:      &zone,
+						Addresses: []string{"10.0.0.1"},
+					},
+					{
+						Conditions: discoveryv1.EndpointConditions{
+							Ready: &notReady,
+						},
+						Zone:      &zone,
+						Addresses: []string{"10.0.0.2"},
+					},
+					{
+						Addresses: []string{"10.0.0.3"},
+					},
+				},
+			},
+			Want: `
+					# HELP kube_endpointslice_endpoints_zone Number of endpoints of the endpointslice by zone and readiness.
+					# TYPE kube_endpointslice_endpoints_zone gauge
+					kube_endpointslice_endpoints_zone{endpointslice="test_endpointslice-zones",ready="false",zone="west"} 1
+					kube_endpointslice_endpoints_zone{endpointslice="test_endpointslice-zones",ready="true",zone="west"} 1
+					kube_endpointslice_endpoints_zone{endpointslice="test_endpointslice-zones",ready="unknown",zone=""} 1
+				`,
+			MetricNames: []string{
+				"kube_endpointslice_endpoints_zone",
+			},
+		},
 		{
 			AllowAnnotationsList: []string{
 				"foo",
@@ -0,0 +1,261 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	eventsv1 "k8s.io/api/events/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	basemetrics "k8s.io/component-base/metrics"
+
+	"k8s.io/kube-state-metrics/v2/pkg/metric"
+	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
+	metricsstore "k8s.io/kube-state-metrics/v2/pkg/metrics_store"
+)
+
+const (
+	// maxEventAggregates bounds the number of distinct series an event store
+	// exposes. Occurrences that would create further series are accounted to a
+	// single overflow series.
+	maxEventAggregates = 1000
+
+	eventAggregateOverflow = "other"
+)
+
+var (
+	descEventLabelsDefaultLabels = []string{"namespace", "involved_object_namespace", "involved_object_kind", "reason", "type"}
+
+	eventMetricFamilies = []generator.FamilyGenerator{
+		*generator.NewFamilyGeneratorWithStability(
+			"kube_event_occurrences_total",
+			"Number of occurrences of Kubernetes events, aggregated by reason, type and involved object.",
+			metric.Counter,
+			basemetrics.ALPHA,
+			"",
+			wrapEventAggregateFunc(func(a *eventAggregate) *metric.Family {
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							Value: a.count,
+						},
+					},
+				}
+			}),
+		),
+	}
+)
+
+// eventAggregateKey identifies a series of the aggregated event metrics.
+type eventAggregateKey struct {
+	namespace               string
+	involvedObjectNamespace string
+	involvedObjectKind      string
+	reason                  string
+	eventType               string
+}
+
+func (k eventAggregateKey) labelValues() []string {
+	return []string{k.namespace, k.involvedObjectNamespace, k.involvedObjectKind, k.reason, k.eventType}
+}
+
+// eventAggregate is the object stored in the underlying MetricsStore for each
+// series. Its UID is derived from the key so that updates replace the previous
+// value of the series.
+type eventAggregate struct {
+	metav1.ObjectMeta
+	key   eventAggregateKey
+	count float64
+}
+
+func wrapEventAggregateFunc(f func(*eventAggregate) *metric.Family) func(interface{}) *metric.Family {
+	return func(obj interface{}) *metric.Family {
+		aggregate := obj.(*eventAggregate)
+
+		metricFamily := f(aggregate)
+
+		for _, m := range metricFamily.Metrics {
+			m.LabelKeys, m.LabelValues = mergeKeyValues(descEventLabelsDefaultLabels, aggregate.key.labelValues(), m.LabelKeys, m.LabelValues)
+		}
+
+		return metricFamily
+	}
+}
+
+// eventAggregator implements the k8s.io/client-go/tools/cache.Store interface.
+// Instead of generating metrics per event, it folds events into counters and
+// writes the counters into the wrapped MetricsStore.
+type eventAggregator struct {
+	mutex sync.Mutex
+	store *metricsstore.MetricsStore
+	// occurrences is the last seen occurrence count of each event.
+	occurrences map[types.UID]float64
+	aggregates  map[eventAggregateKey]*eventAggregate
+}
+
+func newEventAggregator(store *metricsstore.MetricsStore) *eventAggregator {
+	return &eventAggregator{
+		store:       store,
+		occurrences: map[types.UID]float64{},
+		aggregates:  map[eventAggregateKey]*eventAggregate{},
+	}
+}
+
+// eventOccurrences returns how often the given event occurred.
+func eventOccurrences(e *eventsv1.Event) float64 {
+	if e.Series != nil {
+		return float64(e.Series.Count)
+	}
+	if e.DeprecatedCount > 0 {
+		return float64(e.DeprecatedCount)
+	}
+	return 1
+}
+
+func (a *eventAggregator) observe(e *eventsv1.Event, previous map[types.UID]float64) error {
+	n := eventOccurrences(e)
+	delta := n - previous[e.UID]
+	if delta < 0 {
+		// The event series was restarted.
+		delta = n
+	}
+	a.occurrences[e.UID] = n
+	if delta == 0 {
+		return nil
+	}
+
+	key := eventAggregateKey{
+		namespace:               e.Namespace,
+		involvedObjectNamespace: e.Regarding.Namespace,
+		involvedObjectKind:      e.Regarding.Kind,
+		reason:                  e.Reason,
+		eventType:               e.Type,
+	}
+	aggregate, ok := a.aggregates[key]
+	if !ok && len(a.aggregates) >= maxEventAggregates {
+		key = eventAggregateKey{
+			namespace:               eventAggregateOverflow,
+			involvedObjectNamespace: eventAggregateOverflow,
+			involvedObjectKind:      eventAggregateOverflow,
+			reason:                  eventAggregateOverflow,
+			eventType:               eventAggregateOverflow,
+		}
+		aggregate, ok = a.aggregates[key]
+	}
+	if !ok {
+		aggregate = &eventAggregate{key: key}
+		aggregate.UID = types.UID(strings.Join(key.labelValues(), "/"))
+		a.aggregates[key] = aggregate
+	}
+	aggregate.count += delta
+
+	return a.store.Add(aggregate)
+}
+
+// Add accounts the occurrences of the given event.
+func (a *eventAggregator) Add(obj interface{}) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	return a.observe(obj.(*eventsv1.Event), a.occurrences)
+}
+
+// Update accounts the occurrences of the given event since it was last seen.
+func (a *eventAggregator) Update(obj interface{}) error {
+	return a.Add(obj)
+}
+
+// Delete forgets the given event. Counters are not decreased.
+func (a *eventAggregator) Delete(obj interface{}) error {
+	e, ok := obj.(*eventsv1.Event)
+	if !ok {
+		return nil
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	delete(a.occurrences, e.UID)
+	return nil
+}
+
+// List implements the List method of the store interface.
+func (a *eventAggregator) List() []interface{} {
+	return nil
+}
+
+// ListKeys implements the ListKeys method of the store interface.
+func (a *eventAggregator) ListKeys() []string {
+	return nil
+}
+
+// Get implements the Get method of the store interface.
+func (a *eventAggregator) Get(_ interface{}) (item interface{}, exists bool, err error) {
+	return nil, false, nil
+}
+
+// GetByKey implements the GetByKey method of the store interface.
+func (a *eventAggregator) GetByKey(_ string) (item interface{}, exists bool, err error) {
+	return nil, false, nil
+}
+
+// Replace accounts the given list of events against the previously seen ones,
+// keeping the counters accumulated so far. The counters replace the metrics
+// of the wrapped MetricsStore, which marks it as synced.
+func (a *eventAggregator) Replace(list []interface{}, _ string) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	previous := a.occurrences
+	a.occurrences = make(map[types.UID]float64, len(list))
+	for _, o := range list {
+		if err := a.observe(o.(*eventsv1.Event), previous); err != nil {
+			return err
+		}
+	}
+
+	aggregates := make([]interface{}, 0, len(a.aggregates))
+	for _, aggregate := range a.aggregates {
+		aggregates = append(aggregates, aggregate)
+	}
+	return a.store.Replace(aggregates, "")
+}
+
+// Resync implements the Resync method of the store interface.
+func (a *eventAggregator) Resync() error {
+	return nil
+}
+
+func createEventListWatch(kubeClient clientset.Interface, ns string, fieldSelector string) cache.ListerWatcher {
+	return &cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			opts.FieldSelector = fieldSelector
+			return kubeClient.EventsV1().Events(ns).List(context.TODO(), opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			opts.FieldSelector = fieldSelector
+			return kubeClient.EventsV1().Events(ns).Watch(context.TODO(), opts)
+		},
+	}
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
+	metricsstore "k8s.io/kube-state-metrics/v2/pkg/metrics_store"
+)
+
+func newTestEvent(uid, reason, kind string, count int32) *eventsv1.Event {
+	e := &eventsv1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      uid,
+			Namespace: "ns1",
+			UID:       types.UID(uid),
+		},
+		Reason: reason,
+		Type:   v1.EventTypeWarning,
+		Regarding: v1.ObjectReference{
+			Kind:      kind,
+			Namespace: "ns1",
+		},
+	}
+	if count > 1 {
+		e.Series = &eventsv1.EventSeries{Count: count}
+	}
+	return e
+}
+
+func TestEventAggregator(t *testing.T) {
+	store := metricsstore.NewMetricsStore(
+		generator.ExtractMetricFamilyHeaders(eventMetricFamilies),
+		generator.ComposeMetricGenFuncs(eventMetricFamilies),
+	)
+	aggregator := newEventAggregator(store)
+
+	render := func() string {
+		var b strings.Builder
+		if err := metricsstore.NewMetricsWriter(store).WriteAll(&b); err != nil {
+			t.Fatalf("failed to write metrics: %v", err)
+		}
+		return b.String()
+	}
+
+	if store.HasSynced() {
+		t.Fatal("expected the store not to be synced before the initial list")
+	}
+	if err := aggregator.Replace([]interface{}{
+		newTestEvent("a", "BackOff", "Pod", 1),
+		newTestEvent("b", "BackOff", "Pod", 3),
+		newTestEvent("c", "FailedMount", "Pod", 1),
+	}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if !store.HasSynced() {
+		t.Fatal("expected the store to be synced after the initial list")
+	}
+	// An update of a series only accounts the new occurrences.
+	if err := aggregator.Update(newTestEvent("b", "BackOff", "Pod", 5)); err != nil {
+		t.Fatal(err)
+	}
+	// Deleting an event does not decrease the counter.
+	if err := aggregator.Delete(newTestEvent("a", "BackOff", "Pod", 1)); err != nil {
+		t.Fatal(err)
+	}
+	// Relisting accounts events that were missed while the watch was down.
+	if err := aggregator.Replace([]interface{}{
+		newTestEvent("b", "BackOff", "Pod", 5),
+		newTestEvent("c", "FailedMount", "Pod", 1),
+		newTestEvent("d", "FailedMount", "Pod", 1),
+	}, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `
+		# HELP kube_event_occurrences_total Number of occurrences of Kubernetes events, aggregated by reason, type and involved object.
+		# TYPE kube_event_occurrences_total counter
+		kube_event_occurrences_total{namespace="ns1",involved_object_namespace="ns1",involved_object_kind="Pod",reason="BackOff",type="Warning"} 6
+		kube_event_occurrences_total{namespace="ns1",involved_object_namespace="ns1",involved_object_kind="Pod",reason="FailedMount",type="Warning"} 2
+`
+	if err := compareOutput(want, render()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEventAggregatorCardinalityLimit(t *testing.T) {
+	store := metricsstore.NewMetricsStore(
+		generator.ExtractMetricFamilyHeaders(eventMetricFamilies),
+		generator.ComposeMetricGenFuncs(eventMetricFamilies),
+	)
+	aggregator := newEventAggregator(store)
+
+	for i := 0; i < maxEventAggregates+5; i++ {
+		if err := aggregator.Add(newTestEvent(fmt.Sprintf("e%d", i), fmt.Sprintf("Reason%d", i), "Pod", 1)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got, want := len(aggregator.aggregates), maxEventAggregates+1; got != want {
+		t.Fatalf("expected %d aggregates, got %d", want, got)
+	}
+	overflow := aggregator.aggregates[eventAggregateKey{
+		namespace:               eventAggregateOverflow,
+		involvedObjectNamespace: eventAggregateOverflow,
+		involvedObjectKind:      eventAggregateOverflow,
+		reason:                  eventAggregateOverflow,
+		eventType:               eventAggregateOverflow,
+	}]
+	if overflow == nil || overflow.count != 5 {
+		t.Fatalf("expected overflow series to account 5 occurrences, got %v", overflow)
+	}
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"regexp"
+	"strconv"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// filteredStore wraps a cache.Store and hides objects for which skip returns
+// true from it. Objects that become skipped while being watched are deleted
+// from the wrapped store.
+type filteredStore struct {
+	cache.Store
+	skip func(metav1.Object) bool
+	// filter names the filter in the filter metrics.
+	filter string
+
+	mutex sync.Mutex
+	// skippedKeys holds the keys of the objects which are currently skipped.
+	skippedKeys map[string]struct{}
+}
+
+// newIgnoreAnnotationStore returns a store which skips objects that have the
+// given annotation set to "true".
+func newIgnoreAnnotationStore(store cache.Store, annotation string) cache.Store {
+	return &filteredStore{
+		Store:  store,
+		filter: "ignore_annotation",
+		skip: func(o metav1.Object) bool {
+			ignore, _ := strconv.ParseBool(o.GetAnnotations()[annotation])
+			return ignore
+		},
+	}
+}
+
+// newNamespaceDenylistStore returns a store which skips namespaced objects
+// whose namespace matches any of the given patterns.
+func newNamespaceDenylistStore(store cache.Store, patterns []*regexp.Regexp) cache.Store {
+	return &filteredStore{
+		Store:  store,
+		filter: "namespace_denylist",
+		skip: func(o metav1.Object) bool {
+			ns := o.GetNamespace()
+			if ns == "" {
+				return false
+			}
+			for _, p := range patterns {
+				if p.MatchString(ns) {
+					return true
+				}
+			}
+			return false
+		},
+	}
+}
+
+// newOwnerKindStore returns a store which skips objects that have no owner of
+// an allowed kind, if allowed kinds are given, or an owner of a denied kind.
+func newOwnerKindStore(store cache.Store, allowed, denied []string) cache.Store {
+	return &filteredStore{
+		Store:  store,
+		filter: "owner_kinds",
+		skip: func(o metav1.Object) bool {
+			owned := len(allowed) == 0
+			for _, ref := range o.GetOwnerReferences() {
+				for _, kind := range denied {
+					if ref.Kind == kind {
+						return true
+					}
+				}
+				for _, kind := range allowed {
+					if ref.Kind == kind {
+						owned = true
+					}
+				}
+			}
+			return !owned
+		},
+	}
+}
+
+func (s *filteredStore) skipped(obj interface{}) bool {
+	o, err := meta.Accessor(obj)
+	if err != nil {
+		return false
+	}
+	return s.skip(o)
+}
+
+// record records whether the object is skipped.
+func (s *filteredStore) record(obj interface{}, skipped bool) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.skippedKeys == nil {
+		s.skippedKeys = map[string]struct{}{}
+	}
+	if skipped {
+		s.skippedKeys[key] = struct{}{}
+	} else {
+		delete(s.skippedKeys, key)
+	}
+}
+
+// skippedCount returns the number of objects which are currently skipped.
+func (s *filteredStore) skippedCount() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.skippedKeys)
+}
+
+// Add adds the object to the wrapped store unless it is skipped.
+func (s *filteredStore) Add(obj interface{}) error {
+	skipped := s.skipped(obj)
+	s.record(obj, skipped)
+	if skipped {
+		return s.Store.Delete(obj)
+	}
+	return s.Store.Add(obj)
+}
+
+// Update updates the object in the wrapped store, or deletes it from there if
+// it is skipped.
+func (s *filteredStore) Update(obj interface{}) error {
+	skipped := s.skipped(obj)
+	s.record(obj, skipped)
+	if skipped {
+		return s.Store.Delete(obj)
+	}
+	return s.Store.Update(obj)
+}
+
+// Delete deletes the object from the wrapped store.
+func (s *filteredStore) Delete(obj interface{}) error {
+	s.record(obj, false)
+	return s.Store.Delete(obj)
+}
+
+// Replace replaces the contents of the wrapped store with the objects of the
+// given list which are not skipped.
+func (s *filteredStore) Replace(list []interface{}, resourceVersion string) error {
+	filtered := make([]interface{}, 0, len(list))
+	skippedKeys := map[string]struct{}{}
+	for _, o := range list {
+		if !s.skipped(o) {
+			filtered = append(filtered, o)
+		} else if key, err := cache.MetaNamespaceKeyFunc(o); err == nil {
+			skippedKeys[key] = struct{}{}
+		}
+	}
+	s.mutex.Lock()
+	s.skippedKeys = skippedKeys
+	s.mutex.Unlock()
+	return s.Store.Replace(filtered, resourceVersion)
+}
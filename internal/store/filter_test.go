@@ -0,0 +1,234 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
+	metricsstore "k8s.io/kube-state-metrics/v2/pkg/metrics_store"
+	"k8s.io/kube-state-metrics/v2/pkg/options"
+)
+
+func TestIgnoreAnnotationStore(t *testing.T) {
+	families := configMapMetricFamilies(nil, nil)
+	store := metricsstore.NewMetricsStore(
+		generator.ExtractMetricFamilyHeaders(families),
+		generator.ComposeMetricGenFuncs(families),
+	)
+	ignoreStore := newIgnoreAnnotationStore(store, options.DefaultIgnoreAnnotation)
+
+	newConfigMap := func(name string, annotations map[string]string) *v1.ConfigMap {
+		return &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   "ns1",
+				UID:         types.UID(name),
+				Annotations: annotations,
+			},
+		}
+	}
+	ignored := map[string]string{options.DefaultIgnoreAnnotation: "true"}
+
+	if err := ignoreStore.Replace([]interface{}{
+		newConfigMap("kept", nil),
+		newConfigMap("ignored", ignored),
+		newConfigMap("not-ignored", map[string]string{options.DefaultIgnoreAnnotation: "false"}),
+	}, ""); err != nil {
+		t.Fatal(err)
+	}
+	// Annotating a watched object removes its metrics.
+	if err := ignoreStore.Update(newConfigMap("not-ignored", ignored)); err != nil {
+		t.Fatal(err)
+	}
+
+	var b strings.Builder
+	if err := metricsstore.NewMetricsWriter(store).WriteAll(&b); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+
+	if !strings.Contains(out, `configmap="kept"`) {
+		t.Errorf("expected metrics of configmap kept, got:\n%s", out)
+	}
+	for _, name := range []string{"ignored", "not-ignored"} {
+		if strings.Contains(out, `configmap="`+name+`"`) {
+			t.Errorf("expected no metrics of configmap %s, got:\n%s", name, out)
+		}
+	}
+}
+
+func TestNamespaceDenylistStore(t *testing.T) {
+	families := configMapMetricFamilies(nil, nil)
+	store := metricsstore.NewMetricsStore(
+		generator.ExtractMetricFamilyHeaders(families),
+		generator.ComposeMetricGenFuncs(families),
+	)
+	denylistStore := newNamespaceDenylistStore(store, []*regexp.Regexp{regexp.MustCompile("^(?:ci-.+)$")})
+
+	newConfigMap := func(name, namespace string) *v1.ConfigMap {
+		return &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				UID:       types.UID(name),
+			},
+		}
+	}
+
+	if err := denylistStore.Replace([]interface{}{
+		newConfigMap("kept", "default"),
+		newConfigMap("denied", "ci-1234"),
+	}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := denylistStore.Add(newConfigMap("kept-too", "ci")); err != nil {
+		t.Fatal(err)
+	}
+
+	var b strings.Builder
+	if err := metricsstore.NewMetricsWriter(store).WriteAll(&b); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+
+	for _, name := range []string{"kept", "kept-too"} {
+		if !strings.Contains(out, `configmap="`+name+`"`) {
+			t.Errorf("expected metrics of configmap %s, got:\n%s", name, out)
+		}
+	}
+	if strings.Contains(out, `configmap="denied"`) {
+		t.Errorf("expected no metrics of configmap denied, got:\n%s", out)
+	}
+}
+
+func TestOwnerKindStore(t *testing.T) {
+	families := podMetricFamilies(nil, nil, podFamilyOptions{})
+	newPod := func(name string, ownerKinds ...string) *v1.Pod {
+		p := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "ns1",
+				UID:       types.UID(name),
+			},
+		}
+		for _, kind := range ownerKinds {
+			p.OwnerReferences = append(p.OwnerReferences, metav1.OwnerReference{Kind: kind, Name: name})
+		}
+		return p
+	}
+
+	tests := []struct {
+		allowed, denied []string
+		want            []string
+	}{
+		{
+			denied: []string{"Job"},
+			want:   []string{"orphan", "replicaset", "statefulset"},
+		},
+		{
+			allowed: []string{"ReplicaSet", "StatefulSet"},
+			want:    []string{"replicaset", "statefulset"},
+		},
+		{
+			allowed: []string{"ReplicaSet", "Job"},
+			denied:  []string{"Job"},
+			want:    []string{"replicaset"},
+		},
+	}
+
+	for i, test := range tests {
+		store := metricsstore.NewMetricsStore(
+			generator.ExtractMetricFamilyHeaders(families),
+			generator.ComposeMetricGenFuncs(families),
+		)
+		ownerKindStore := newOwnerKindStore(store, test.allowed, test.denied)
+		if err := ownerKindStore.Replace([]interface{}{
+			newPod("orphan"),
+			newPod("replicaset", "ReplicaSet"),
+			newPod("statefulset", "StatefulSet"),
+			newPod("job", "Job"),
+		}, ""); err != nil {
+			t.Fatal(err)
+		}
+
+		var b strings.Builder
+		if err := metricsstore.NewMetricsWriter(store).WriteAll(&b); err != nil {
+			t.Fatal(err)
+		}
+		out := b.String()
+
+		for _, name := range []string{"orphan", "replicaset", "statefulset", "job"} {
+			want := false
+			for _, w := range test.want {
+				want = want || w == name
+			}
+			if got := strings.Contains(out, `pod="`+name+`"`); got != want {
+				t.Errorf("Test %d: expected metrics of pod %s to be exposed: %t, got:\n%s", i, name, want, out)
+			}
+		}
+	}
+}
+
+func TestFilteredStoreSkippedCount(t *testing.T) {
+	families := configMapMetricFamilies(nil, nil)
+	store := metricsstore.NewMetricsStore(
+		generator.ExtractMetricFamilyHeaders(families),
+		generator.ComposeMetricGenFuncs(families),
+	)
+	ignoreStore := newIgnoreAnnotationStore(store, options.DefaultIgnoreAnnotation).(*filteredStore)
+
+	newConfigMap := func(name string, ignore string) *v1.ConfigMap {
+		return &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   "ns1",
+				UID:         types.UID(name),
+				Annotations: map[string]string{options.DefaultIgnoreAnnotation: ignore},
+			},
+		}
+	}
+	if err := ignoreStore.Replace([]interface{}{
+		newConfigMap("a", "true"),
+		newConfigMap("b", "true"),
+		newConfigMap("c", "false"),
+	}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if n := ignoreStore.skippedCount(); n != 2 {
+		t.Errorf("expected 2 skipped objects after replace, got %d", n)
+	}
+	// Objects which are no longer skipped or deleted are not counted anymore.
+	if err := ignoreStore.Update(newConfigMap("a", "false")); err != nil {
+		t.Fatal(err)
+	}
+	if err := ignoreStore.Add(newConfigMap("d", "true")); err != nil {
+		t.Fatal(err)
+	}
+	if err := ignoreStore.Delete(newConfigMap("b", "true")); err != nil {
+		t.Fatal(err)
+	}
+	if n := ignoreStore.skippedCount(); n != 1 {
+		t.Errorf("expected 1 skipped object, got %d", n)
+	}
+}
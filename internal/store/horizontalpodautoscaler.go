@@ -20,6 +20,7 @@ import (
 	"context"
 
 	autoscaling "k8s.io/api/autoscaling/v2"
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/watch"
@@ -337,7 +338,7 @@ func createHPAStatusDesiredReplicas() generator.FamilyGenerator {
 }
 
 func createHPAAnnotations(allowAnnotationsList []string) generator.FamilyGenerator {
-	return *generator.NewFamilyGeneratorWithStability(
+	return *generator.NewLazyFamilyGenerator(
 		descHorizontalPodAutoscalerAnnotationsName,
 		descHorizontalPodAutoscalerAnnotationsHelp,
 		metric.Gauge,
@@ -362,7 +363,7 @@ func createHPAAnnotations(allowAnnotationsList []string) generator.FamilyGenerat
 }
 
 func createHPALabels(allowLabelsList []string) generator.FamilyGenerator {
-	return *generator.NewFamilyGeneratorWithStability(
+	return *generator.NewLazyFamilyGenerator(
 		descHorizontalPodAutoscalerLabelsName,
 		descHorizontalPodAutoscalerLabelsHelp,
 		metric.Gauge,
@@ -394,18 +395,9 @@ func createHPAStatusCondition() generator.FamilyGenerator {
 		basemetrics.STABLE,
 		"",
 		wrapHPAFunc(func(a *autoscaling.HorizontalPodAutoscaler) *metric.Family {
-			ms := make([]*metric.Metric, 0, len(a.Status.Conditions)*len(conditionStatuses))
-
-			for _, c := range a.Status.Conditions {
-				metrics := addConditionMetrics(c.Status)
-
-				for _, m := range metrics {
-					metric := m
-					metric.LabelKeys = []string{"condition", "status"}
-					metric.LabelValues = append([]string{string(c.Type)}, metric.LabelValues...)
-					ms = append(ms, metric)
-				}
-			}
+			ms := statusConditionMetrics(a.Status.Conditions, func(c autoscaling.HorizontalPodAutoscalerCondition) (string, v1.ConditionStatus) {
+				return string(c.Type), c.Status
+			})
 
 			return &metric.Family{
 				Metrics: ms,
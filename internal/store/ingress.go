@@ -68,7 +68,7 @@ func ingressMetricFamilies(allowAnnotationsList, allowLabelsList []string) []gen
 					}}
 			}),
 		),
-		*generator.NewFamilyGeneratorWithStability(
+		*generator.NewLazyFamilyGenerator(
 			descIngressAnnotationsName,
 			descIngressAnnotationsHelp,
 			metric.Gauge,
@@ -90,7 +90,7 @@ func ingressMetricFamilies(allowAnnotationsList, allowLabelsList []string) []gen
 
 			}),
 		),
-		*generator.NewFamilyGeneratorWithStability(
+		*generator.NewLazyFamilyGenerator(
 			descIngressLabelsName,
 			descIngressLabelsHelp,
 			metric.Gauge,
@@ -122,9 +122,7 @@ func ingressMetricFamilies(allowAnnotationsList, allowLabelsList []string) []gen
 				ms := []*metric.Metric{}
 
 				if !i.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						Value: float64(i.CreationTimestamp.Unix()),
-					})
+					ms = append(ms, metric.TimestampMetric(nil, nil, i.CreationTimestamp.Time))
 				}
 
 				return &metric.Family{
@@ -202,6 +200,36 @@ func ingressMetricFamilies(allowAnnotationsList, allowLabelsList []string) []gen
 				}
 			}),
 		),
+		*generator.NewFamilyGeneratorWithStability(
+			"kube_ingress_tls_secret",
+			"Secret referenced by an ingress TLS entry, with the namespace of the secret for joins with metrics of secrets and certificates.",
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			wrapIngressFunc(func(i *networkingv1.Ingress) *metric.Family {
+				ms := make([]*metric.Metric, 0, len(i.Spec.TLS))
+				secrets := map[string]struct{}{}
+				for _, tls := range i.Spec.TLS {
+					// TLS entries without secret use the default certificate
+					// of the ingress controller.
+					if tls.SecretName == "" {
+						continue
+					}
+					if _, ok := secrets[tls.SecretName]; ok {
+						continue
+					}
+					secrets[tls.SecretName] = struct{}{}
+					ms = append(ms, &metric.Metric{
+						LabelKeys:   []string{"secret", "secret_namespace"},
+						LabelValues: []string{tls.SecretName, i.Namespace},
+						Value:       1,
+					})
+				}
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		),
 	}
 }
 
@@ -40,12 +40,14 @@ func TestIngressStore(t *testing.T) {
 		# HELP kube_ingress_metadata_resource_version Resource version representing a specific version of ingress.
 		# HELP kube_ingress_path [STABLE] Ingress host, paths and backend service information.
 		# HELP kube_ingress_tls [STABLE] Ingress TLS host and secret information.
+		# HELP kube_ingress_tls_secret Secret referenced by an ingress TLS entry, with the namespace of the secret for joins with metrics of secrets and certificates.
 		# TYPE kube_ingress_created gauge
 		# TYPE kube_ingress_info gauge
 		# TYPE kube_ingress_labels gauge
 		# TYPE kube_ingress_metadata_resource_version gauge
 		# TYPE kube_ingress_path gauge
 		# TYPE kube_ingress_tls gauge
+		# TYPE kube_ingress_tls_secret gauge
 	`
 	cases := []generateMetricsTestCase{
 		{
@@ -192,9 +194,39 @@ func TestIngressStore(t *testing.T) {
 				kube_ingress_created{namespace="ns5",ingress="ingress5"} 1.501569018e+09
 				kube_ingress_tls{namespace="ns5",ingress="ingress5",tls_host="somehost1",secret="somesecret"} 1
 				kube_ingress_tls{namespace="ns5",ingress="ingress5",tls_host="somehost2",secret="somesecret"} 1
+				kube_ingress_tls_secret{namespace="ns5",ingress="ingress5",secret="somesecret",secret_namespace="ns5"} 1
 `,
 			MetricNames: []string{"kube_ingress_info", "kube_ingress_metadata_resource_version", "kube_ingress_created", "kube_ingress_labels", "kube_ingress_path", "kube_ingress_tls"},
 		},
+		{
+			Obj: &networkingv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "ingress7",
+					Namespace: "ns7",
+				},
+				Spec: networkingv1.IngressSpec{
+					TLS: []networkingv1.IngressTLS{
+						{
+							Hosts:      []string{"somehost1", "somehost2"},
+							SecretName: "somesecret",
+						},
+						{
+							Hosts:      []string{"somehost3"},
+							SecretName: "somesecret",
+						},
+						{
+							Hosts: []string{"somehost4"},
+						},
+					},
+				},
+			},
+			Want: `
+				# HELP kube_ingress_tls_secret Secret referenced by an ingress TLS entry, with the namespace of the secret for joins with metrics of secrets and certificates.
+				# TYPE kube_ingress_tls_secret gauge
+				kube_ingress_tls_secret{namespace="ns7",ingress="ingress7",secret="somesecret",secret_namespace="ns7"} 1
+`,
+			MetricNames: []string{"kube_ingress_tls_secret"},
+		},
 		{
 			Obj: &networkingv1.Ingress{
 				ObjectMeta: metav1.ObjectMeta{
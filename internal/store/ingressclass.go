@@ -64,16 +64,14 @@ func ingressClassMetricFamilies(allowAnnotationsList, allowLabelsList []string)
 			wrapIngressClassFunc(func(s *networkingv1.IngressClass) *metric.Family {
 				ms := []*metric.Metric{}
 				if !s.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						Value: float64(s.CreationTimestamp.Unix()),
-					})
+					ms = append(ms, metric.TimestampMetric(nil, nil, s.CreationTimestamp.Time))
 				}
 				return &metric.Family{
 					Metrics: ms,
 				}
 			}),
 		),
-		*generator.NewFamilyGeneratorWithStability(
+		*generator.NewLazyFamilyGenerator(
 			descIngressClassAnnotationsName,
 			descIngressClassAnnotationsHelp,
 			metric.Gauge,
@@ -95,7 +93,7 @@ func ingressClassMetricFamilies(allowAnnotationsList, allowLabelsList []string)
 				}
 			}),
 		),
-		*generator.NewFamilyGeneratorWithStability(
+		*generator.NewLazyFamilyGenerator(
 			descIngressClassLabelsName,
 			descIngressClassLabelsHelp,
 			metric.Gauge,
@@ -26,6 +26,7 @@ import (
 	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
 
 	v1batch "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/watch"
@@ -44,7 +45,7 @@ var (
 
 func jobMetricFamilies(allowAnnotationsList, allowLabelsList []string) []generator.FamilyGenerator {
 	return []generator.FamilyGenerator{
-		*generator.NewFamilyGeneratorWithStability(
+		*generator.NewLazyFamilyGenerator(
 			descJobAnnotationsName,
 			descJobAnnotationsHelp,
 			metric.Gauge,
@@ -66,7 +67,7 @@ func jobMetricFamilies(allowAnnotationsList, allowLabelsList []string) []generat
 				}
 			}),
 		),
-		*generator.NewFamilyGeneratorWithStability(
+		*generator.NewLazyFamilyGenerator(
 			descJobLabelsName,
 			descJobLabelsHelp,
 			metric.Gauge,
@@ -114,9 +115,7 @@ func jobMetricFamilies(allowAnnotationsList, allowLabelsList []string) []generat
 				ms := []*metric.Metric{}
 
 				if !j.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						Value: float64(j.CreationTimestamp.Unix()),
-					})
+					ms = append(ms, metric.TimestampMetric(nil, nil, j.CreationTimestamp.Time))
 				}
 
 				return &metric.Family{
@@ -314,6 +313,20 @@ func jobMetricFamilies(allowAnnotationsList, allowLabelsList []string) []generat
 				}
 			}),
 		),
+		*generator.NewFamilyGeneratorWithStability(
+			"kube_job_status_condition",
+			"The current status conditions of a job.",
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			wrapJobFunc(func(j *v1batch.Job) *metric.Family {
+				return &metric.Family{
+					Metrics: statusConditionMetrics(j.Status.Conditions, func(c v1batch.JobCondition) (string, corev1.ConditionStatus) {
+						return string(c.Type), c.Status
+					}),
+				}
+			}),
+		),
 		*generator.NewFamilyGeneratorWithStability(
 			"kube_job_status_start_time",
 			"StartTime represents time when the job was acknowledged by the Job Manager.",
@@ -324,10 +337,7 @@ func jobMetricFamilies(allowAnnotationsList, allowLabelsList []string) []generat
 				ms := []*metric.Metric{}
 
 				if j.Status.StartTime != nil {
-					ms = append(ms, &metric.Metric{
-
-						Value: float64(j.Status.StartTime.Unix()),
-					})
+					ms = append(ms, metric.TimestampMetric(nil, nil, j.Status.StartTime.Time))
 				}
 
 				return &metric.Family{
@@ -344,10 +354,7 @@ func jobMetricFamilies(allowAnnotationsList, allowLabelsList []string) []generat
 			wrapJobFunc(func(j *v1batch.Job) *metric.Family {
 				ms := []*metric.Metric{}
 				if j.Status.CompletionTime != nil {
-					ms = append(ms, &metric.Metric{
-
-						Value: float64(j.Status.CompletionTime.Unix()),
-					})
+					ms = append(ms, metric.TimestampMetric(nil, nil, j.Status.CompletionTime.Time))
 				}
 
 				return &metric.Family{
@@ -58,6 +58,8 @@ func TestJobStore(t *testing.T) {
 		# TYPE kube_job_complete gauge
 		# HELP kube_job_failed [STABLE] The job has failed its execution.
 		# TYPE kube_job_failed gauge
+		# HELP kube_job_status_condition The current status conditions of a job.
+		# TYPE kube_job_status_condition gauge
 		# HELP kube_job_info [STABLE] Information about job.
 		# TYPE kube_job_info gauge
 		# HELP kube_job_labels [STABLE] Kubernetes labels converted to Prometheus labels.
@@ -155,6 +157,9 @@ func TestJobStore(t *testing.T) {
 				kube_job_complete{condition="false",job_name="SuccessfulJob1",namespace="ns1"} 0
 				kube_job_complete{condition="true",job_name="SuccessfulJob1",namespace="ns1"} 1
 				kube_job_complete{condition="unknown",job_name="SuccessfulJob1",namespace="ns1"} 0
+				kube_job_status_condition{condition="Complete",job_name="SuccessfulJob1",namespace="ns1",status="false"} 0
+				kube_job_status_condition{condition="Complete",job_name="SuccessfulJob1",namespace="ns1",status="true"} 1
+				kube_job_status_condition{condition="Complete",job_name="SuccessfulJob1",namespace="ns1",status="unknown"} 0
 				kube_job_info{job_name="SuccessfulJob1",namespace="ns1"} 1
 				kube_job_spec_active_deadline_seconds{job_name="SuccessfulJob1",namespace="ns1"} 900
 				kube_job_spec_completions{job_name="SuccessfulJob1",namespace="ns1"} 1
@@ -197,6 +202,9 @@ func TestJobStore(t *testing.T) {
 				kube_job_failed{condition="false",job_name="FailedJob1",namespace="ns1"} 0
 				kube_job_failed{condition="true",job_name="FailedJob1",namespace="ns1"} 1
 				kube_job_failed{condition="unknown",job_name="FailedJob1",namespace="ns1"} 0
+				kube_job_status_condition{condition="Failed",job_name="FailedJob1",namespace="ns1",status="false"} 0
+				kube_job_status_condition{condition="Failed",job_name="FailedJob1",namespace="ns1",status="true"} 1
+				kube_job_status_condition{condition="Failed",job_name="FailedJob1",namespace="ns1",status="unknown"} 0
 				kube_job_info{job_name="FailedJob1",namespace="ns1"} 1
 				kube_job_spec_active_deadline_seconds{job_name="FailedJob1",namespace="ns1"} 900
 				kube_job_spec_completions{job_name="FailedJob1",namespace="ns1"} 1
@@ -242,6 +250,9 @@ func TestJobStore(t *testing.T) {
 				kube_job_complete{condition="true",job_name="SuccessfulJob2NoActiveDeadlineSeconds",namespace="ns1"} 1
 
 				kube_job_complete{condition="unknown",job_name="SuccessfulJob2NoActiveDeadlineSeconds",namespace="ns1"} 0
+				kube_job_status_condition{condition="Complete",job_name="SuccessfulJob2NoActiveDeadlineSeconds",namespace="ns1",status="false"} 0
+				kube_job_status_condition{condition="Complete",job_name="SuccessfulJob2NoActiveDeadlineSeconds",namespace="ns1",status="true"} 1
+				kube_job_status_condition{condition="Complete",job_name="SuccessfulJob2NoActiveDeadlineSeconds",namespace="ns1",status="unknown"} 0
 				kube_job_info{job_name="SuccessfulJob2NoActiveDeadlineSeconds",namespace="ns1"} 1
 				kube_job_spec_completions{job_name="SuccessfulJob2NoActiveDeadlineSeconds",namespace="ns1"} 1
 				kube_job_spec_parallelism{job_name="SuccessfulJob2NoActiveDeadlineSeconds",namespace="ns1"} 1
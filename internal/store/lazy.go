@@ -0,0 +1,86 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+
+	"k8s.io/kube-state-metrics/v2/pkg/metric"
+	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
+)
+
+// lazyFamilyGenerators splits the lazy families off the given families, so
+// that they are only generated once they are first scraped. It returns the
+// given families with the lazy families generating no metrics, the lazy
+// families and their indices.
+func lazyFamilyGenerators(families []generator.FamilyGenerator) ([]generator.FamilyGenerator, []generator.FamilyGenerator, []int) {
+	return splitFamilyGenerators(families, func(f generator.FamilyGenerator) bool {
+		return f.Lazy
+	})
+}
+
+// scrapeTimeFamilyGenerators splits the scrape-time families off the given
+// families, so that they are generated whenever they are scraped. It returns
+// the given families with the scrape-time families generating no metrics, the
+// scrape-time families and their indices.
+func scrapeTimeFamilyGenerators(families []generator.FamilyGenerator) ([]generator.FamilyGenerator, []generator.FamilyGenerator, []int) {
+	return splitFamilyGenerators(families, func(f generator.FamilyGenerator) bool {
+		return f.ScrapeTime
+	})
+}
+
+// splitFamilyGenerators splits the families for which split returns true off
+// the given families. It returns the given families with the split families
+// generating no metrics, the split families and their indices.
+func splitFamilyGenerators(families []generator.FamilyGenerator, split func(generator.FamilyGenerator) bool) ([]generator.FamilyGenerator, []generator.FamilyGenerator, []int) {
+	var splitFamilies []generator.FamilyGenerator
+	var indices []int
+	rest := make([]generator.FamilyGenerator, len(families))
+	for i, f := range families {
+		if split(f) {
+			splitFamilies = append(splitFamilies, f)
+			indices = append(indices, i)
+			f.GenerateFunc = func(interface{}) *metric.Family {
+				return &metric.Family{}
+			}
+		}
+		rest[i] = f
+	}
+	return rest, splitFamilies, indices
+}
+
+// keepMetadata returns an object of the type of the given object with only
+// the metadata the lazy families are generated from.
+func keepMetadata(obj interface{}) interface{} {
+	o, err := meta.Accessor(obj)
+	if err != nil || reflect.TypeOf(obj).Kind() != reflect.Ptr {
+		return obj
+	}
+	kept, err := meta.Accessor(reflect.New(reflect.TypeOf(obj).Elem()).Interface())
+	if err != nil {
+		return obj
+	}
+	kept.SetName(o.GetName())
+	kept.SetNamespace(o.GetNamespace())
+	kept.SetUID(o.GetUID())
+	kept.SetLabels(o.GetLabels())
+	kept.SetAnnotations(o.GetAnnotations())
+	kept.SetOwnerReferences(o.GetOwnerReferences())
+	return kept
+}
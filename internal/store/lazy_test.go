@@ -0,0 +1,129 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
+	metricsstore "k8s.io/kube-state-metrics/v2/pkg/metrics_store"
+)
+
+func TestLazyFamilyGenerators(t *testing.T) {
+	families := secretMetricFamilies([]string{"owner"}, []string{"team"})
+	eager, lazy, indices := lazyFamilyGenerators(families)
+	if len(lazy) != 2 || len(indices) != 2 {
+		t.Fatalf("expected the labels and annotations families to be lazy, got %d families at %v", len(lazy), indices)
+	}
+
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "secret1",
+			Namespace:   "ns1",
+			UID:         "uid1",
+			Labels:      map[string]string{"team": "a"},
+			Annotations: map[string]string{"owner": "b"},
+		},
+		Type: v1.SecretTypeOpaque,
+		Data: map[string][]byte{"key": []byte("value")},
+	}
+	kept := keepMetadata(secret).(*v1.Secret)
+	if kept.Type != "" || kept.Data != nil {
+		t.Errorf("expected only the metadata to be kept, got %+v", kept)
+	}
+	generated := generator.ComposeMetricGenFuncs(lazy)(kept)
+	for i, index := range indices {
+		if eager[index].Name != families[index].Name || lazy[i].Name != families[index].Name {
+			t.Errorf("expected family %d to be %s, got %s and %s", index, families[index].Name, eager[index].Name, lazy[i].Name)
+		}
+		if got := eager[index].Generate(secret).ByteSlice(); len(got) != 0 {
+			t.Errorf("expected the eager %s family to generate nothing, got %s", families[index].Name, got)
+		}
+		if want, got := families[index].Generate(secret).ByteSlice(), generated[i].ByteSlice(); !bytes.Equal(want, got) {
+			t.Errorf("expected the %s family generated from the kept metadata to be\n%s\ngot\n%s", families[index].Name, want, got)
+		}
+	}
+
+	if _, lazy, _ := lazyFamilyGenerators(csrMetricFamilies(nil, []string{"team"})); len(lazy) != 0 {
+		t.Errorf("expected the families of certificatesigningrequests not to be lazy, got %d", len(lazy))
+	}
+}
+
+func TestLazyFamilyGeneratorsReplicaSetDeploymentLabel(t *testing.T) {
+	families := replicaSetMetricFamilies(nil, []string{"app"}, replicaSetFamilyOptions{deploymentLabel: true})
+	eager, lazy, indices := lazyFamilyGenerators(families)
+	store := metricsstore.NewMetricsStore(
+		generator.ExtractMetricFamilyHeaders(families),
+		generator.ComposeMetricGenFuncs(eager),
+	)
+	store.WithLazyFamilies(indices, keepMetadata, generator.ComposeMetricGenFuncs(lazy))
+
+	// The replicaset is added before the first write, so that its labels
+	// family is generated from the kept metadata.
+	controller := true
+	if err := store.Add(&appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rs1-7d9f8b",
+			Namespace: "ns1",
+			UID:       "uid1",
+			Labels:    map[string]string{"app": "web"},
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: "Deployment", Name: "rs1", Controller: &controller},
+			},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	var b strings.Builder
+	if err := metricsstore.NewMetricsWriter(store).WriteAll(&b); err != nil {
+		t.Fatal(err)
+	}
+	if want := `kube_replicaset_labels{namespace="ns1",replicaset="rs1-7d9f8b",label_app="web",deployment="rs1"} 1`; !strings.Contains(b.String(), want) {
+		t.Errorf("expected %s to be written, got\n%s", want, b.String())
+	}
+}
+
+func TestScrapeTimeFamilyGenerators(t *testing.T) {
+	families := nodeMetricFamilies(nil, nil, nodeFamilyOptions{})
+	eager, scrapeTime, indices := scrapeTimeFamilyGenerators(families)
+	if len(scrapeTime) != 1 || len(indices) != 1 || scrapeTime[0].Name != "kube_node_status_condition_last_transition_age_seconds" {
+		t.Fatalf("expected the condition transition age family to be generated at scrape time, got %d families at %v", len(scrapeTime), indices)
+	}
+
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node1"},
+		Status: v1.NodeStatus{
+			Conditions: []v1.NodeCondition{
+				{Type: v1.NodeReady, Status: v1.ConditionTrue, LastTransitionTime: metav1.Time{Time: time.Unix(1500000000, 0)}},
+			},
+		},
+	}
+	if got := eager[indices[0]].Generate(node).ByteSlice(); len(got) != 0 {
+		t.Errorf("expected the eager %s family to generate nothing, got %s", scrapeTime[0].Name, got)
+	}
+	if len(eager) != len(families) {
+		t.Errorf("expected %d eager families, got %d", len(families), len(eager))
+	}
+}
@@ -88,11 +88,7 @@ var (
 				ms := []*metric.Metric{}
 
 				if !l.Spec.RenewTime.IsZero() {
-					ms = append(ms, &metric.Metric{
-						LabelKeys:   labelKeys,
-						LabelValues: []string{l.Namespace},
-						Value:       float64(l.Spec.RenewTime.Unix()),
-					})
+					ms = append(ms, metric.TimestampMetric(labelKeys, []string{l.Namespace}, l.Spec.RenewTime.Time))
 				}
 				return &metric.Family{
 					Metrics: ms,
@@ -101,10 +101,7 @@ var (
 				ms := []*metric.Metric{}
 
 				if !r.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-
-						Value: float64(r.CreationTimestamp.Unix()),
-					})
+					ms = append(ms, metric.TimestampMetric(nil, nil, r.CreationTimestamp.Time))
 				}
 
 				return &metric.Family{
@@ -0,0 +1,92 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/tools/cache"
+
+	"k8s.io/kube-state-metrics/v2/pkg/metric"
+	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
+)
+
+// metadataOnlyFamilies are the families of the resources which can be watched
+// by the metadata of their objects only, which are generated from the
+// metadata. The other families of these resources are not served then.
+var metadataOnlyFamilies = map[string]map[string]struct{}{
+	"configmaps": {
+		"kube_configmap_annotations":               {},
+		"kube_configmap_created":                   {},
+		"kube_configmap_info":                      {},
+		"kube_configmap_labels":                    {},
+		"kube_configmap_metadata_resource_version": {},
+	},
+	"secrets": {
+		"kube_secret_annotations":               {},
+		"kube_secret_created":                   {},
+		"kube_secret_info":                      {},
+		"kube_secret_labels":                    {},
+		"kube_secret_metadata_resource_version": {},
+	},
+}
+
+// metadataFamilyGenerators returns the families of the resource which are
+// generated from the metadata of its objects, generating their metrics from
+// the object newObject returns for the metadata of the objects.
+func metadataFamilyGenerators(resource string, families []generator.FamilyGenerator, newObject func(metav1.ObjectMeta) interface{}) []generator.FamilyGenerator {
+	result := make([]generator.FamilyGenerator, 0, len(metadataOnlyFamilies[resource]))
+	for _, f := range families {
+		if _, ok := metadataOnlyFamilies[resource][f.Name]; !ok {
+			continue
+		}
+		generate := f.GenerateFunc
+		f.GenerateFunc = func(obj interface{}) *metric.Family {
+			return generate(newObject(obj.(*metav1.PartialObjectMetadata).ObjectMeta))
+		}
+		result = append(result, f)
+	}
+	return result
+}
+
+// metadataListWatchFunc returns a function which returns a ListerWatcher of
+// the metadata of the objects of the resource, using the metadata client of
+// the Builder.
+func (b *Builder) metadataListWatchFunc(gvr schema.GroupVersionResource) func(clientset.Interface, string, string) cache.ListerWatcher {
+	return func(_ clientset.Interface, ns string, fieldSelector string) cache.ListerWatcher {
+		return createMetadataListWatch(b.metadataClient, gvr, ns, fieldSelector)
+	}
+}
+
+func createMetadataListWatch(client metadata.Interface, gvr schema.GroupVersionResource, ns string, fieldSelector string) cache.ListerWatcher {
+	return &cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			opts.FieldSelector = fieldSelector
+			return client.Resource(gvr).Namespace(ns).List(context.TODO(), opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			opts.FieldSelector = fieldSelector
+			return client.Resource(gvr).Namespace(ns).Watch(context.TODO(), opts)
+		},
+	}
+}
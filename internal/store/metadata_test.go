@@ -0,0 +1,107 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metadatafake "k8s.io/client-go/metadata/fake"
+
+	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
+)
+
+func TestMetadataFamilyGenerators(t *testing.T) {
+	families := metadataFamilyGenerators("secrets", secretMetricFamilies(nil, []string{"team"}), func(m metav1.ObjectMeta) interface{} {
+		return &v1.Secret{ObjectMeta: m}
+	})
+	c := generateMetricsTestCase{
+		Obj: &metav1.PartialObjectMetadata{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "secret1",
+				Namespace:         "ns1",
+				CreationTimestamp: metav1.Unix(1501569018, 0),
+				Labels:            map[string]string{"team": "a"},
+				ResourceVersion:   "10",
+			},
+		},
+		Want: `
+			# HELP kube_secret_created [STABLE] Unix creation timestamp
+			# HELP kube_secret_info [STABLE] Information about secret.
+			# HELP kube_secret_labels [STABLE] Kubernetes labels converted to Prometheus labels.
+			# HELP kube_secret_metadata_resource_version Resource version representing a specific version of secret.
+			# TYPE kube_secret_created gauge
+			# TYPE kube_secret_info gauge
+			# TYPE kube_secret_labels gauge
+			# TYPE kube_secret_metadata_resource_version gauge
+			kube_secret_created{namespace="ns1",secret="secret1"} 1.501569018e+09
+			kube_secret_info{namespace="ns1",secret="secret1"} 1
+			kube_secret_labels{label_team="a",namespace="ns1",secret="secret1"} 1
+			kube_secret_metadata_resource_version{namespace="ns1",secret="secret1"} 10
+`,
+		MetricNames: []string{"kube_secret_info", "kube_secret_metadata_resource_version", "kube_secret_created", "kube_secret_labels", "kube_secret_type", "kube_secret_data_keys"},
+		Func:        generator.ComposeMetricGenFuncs(families),
+		Headers:     generator.ExtractMetricFamilyHeaders(families),
+	}
+	if err := c.run(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWithMetadataOnlyResources(t *testing.T) {
+	b := NewBuilder()
+	if err := b.WithMetadataOnlyResources([]string{"pods"}); err == nil {
+		t.Error("expected an error for a resource which can't be watched by metadata only")
+	}
+	if err := b.WithMetadataOnlyResources([]string{"configmaps", "secrets"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(b.metadataOnlyResources) != 2 {
+		t.Errorf("expected 2 metadata-only resources, got %v", b.metadataOnlyResources)
+	}
+}
+
+func TestMetadataListWatch(t *testing.T) {
+	scheme := metadatafake.NewTestScheme()
+	if err := metav1.AddMetaToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	client := metadatafake.NewSimpleMetadataClient(scheme, &metav1.PartialObjectMetadata{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{Name: "secret1", Namespace: "ns1"},
+	})
+	b := NewBuilder()
+	b.WithMetadataClient(client)
+
+	lw := b.metadataListWatchFunc(v1.SchemeGroupVersion.WithResource("secrets"))(nil, "ns1", "")
+	list, err := lw.List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("expected 1 object, got %d", len(items))
+	}
+	if _, ok := items[0].(*metav1.PartialObjectMetadata); !ok {
+		t.Errorf("expected the metadata of the secret, got %T", items[0])
+	}
+}
@@ -61,9 +61,7 @@ var (
 				ms := []*metric.Metric{}
 
 				if !mwc.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						Value: float64(mwc.CreationTimestamp.Unix()),
-					})
+					ms = append(ms, metric.TimestampMetric(nil, nil, mwc.CreationTimestamp.Time))
 				}
 				return &metric.Family{
 					Metrics: ms,
@@ -38,6 +38,11 @@ var (
 	descNamespaceLabelsName          = "kube_namespace_labels"
 	descNamespaceLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
 	descNamespaceLabelsDefaultLabels = []string{"namespace"}
+
+	// podSecurityLabelPrefix is the prefix of the namespace labels which
+	// configure pod security admission.
+	podSecurityLabelPrefix = "pod-security.kubernetes.io/"
+	podSecurityModes       = []string{"enforce", "audit", "warn"}
 )
 
 func namespaceMetricFamilies(allowAnnotationsList, allowLabelsList []string) []generator.FamilyGenerator {
@@ -51,9 +56,7 @@ func namespaceMetricFamilies(allowAnnotationsList, allowLabelsList []string) []g
 			wrapNamespaceFunc(func(n *v1.Namespace) *metric.Family {
 				ms := []*metric.Metric{}
 				if !n.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						Value: float64(n.CreationTimestamp.Unix()),
-					})
+					ms = append(ms, metric.TimestampMetric(nil, nil, n.CreationTimestamp.Time))
 				}
 
 				return &metric.Family{
@@ -61,7 +64,7 @@ func namespaceMetricFamilies(allowAnnotationsList, allowLabelsList []string) []g
 				}
 			}),
 		),
-		*generator.NewFamilyGeneratorWithStability(
+		*generator.NewLazyFamilyGenerator(
 			descNamespaceAnnotationsName,
 			descNamespaceAnnotationsHelp,
 			metric.Gauge,
@@ -84,7 +87,7 @@ func namespaceMetricFamilies(allowAnnotationsList, allowLabelsList []string) []g
 				}
 			}),
 		),
-		*generator.NewFamilyGeneratorWithStability(
+		*generator.NewLazyFamilyGenerator(
 			descNamespaceLabelsName,
 			descNamespaceLabelsHelp,
 			metric.Gauge,
@@ -140,18 +143,38 @@ func namespaceMetricFamilies(allowAnnotationsList, allowLabelsList []string) []g
 			basemetrics.ALPHA,
 			"",
 			wrapNamespaceFunc(func(n *v1.Namespace) *metric.Family {
-				ms := make([]*metric.Metric, len(n.Status.Conditions)*len(conditionStatuses))
-				for i, c := range n.Status.Conditions {
-					conditionMetrics := addConditionMetrics(c.Status)
+				ms := statusConditionMetrics(n.Status.Conditions, func(c v1.NamespaceCondition) (string, v1.ConditionStatus) {
+					return string(c.Type), c.Status
+				})
 
-					for j, m := range conditionMetrics {
-						metric := m
-
-						metric.LabelKeys = []string{"condition", "status"}
-						metric.LabelValues = append([]string{string(c.Type)}, metric.LabelValues...)
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		),
+		*generator.NewFamilyGeneratorWithStability(
+			"kube_namespace_pod_security",
+			"The pod security admission level and version of a namespace per mode, as set by its labels.",
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			wrapNamespaceFunc(func(n *v1.Namespace) *metric.Family {
+				ms := []*metric.Metric{}
 
-						ms[i*len(conditionStatuses)+j] = metric
+				for _, mode := range podSecurityModes {
+					level, ok := n.Labels[podSecurityLabelPrefix+mode]
+					if !ok {
+						continue
+					}
+					version, ok := n.Labels[podSecurityLabelPrefix+mode+"-version"]
+					if !ok {
+						version = "latest"
 					}
+					ms = append(ms, &metric.Metric{
+						LabelKeys:   []string{"mode", "level", "version"},
+						LabelValues: []string{mode, level, version},
+						Value:       1,
+					})
 				}
 
 				return &metric.Family{
@@ -40,6 +40,8 @@ func TestNamespaceStore(t *testing.T) {
 		# TYPE kube_namespace_status_phase gauge
 		# HELP kube_namespace_status_condition The condition of a namespace.
 		# TYPE kube_namespace_status_condition gauge
+		# HELP kube_namespace_pod_security The pod security admission level and version of a namespace per mode, as set by its labels.
+		# TYPE kube_namespace_pod_security gauge
 	`
 
 	cases := []generateMetricsTestCase{
@@ -150,6 +152,24 @@ func TestNamespaceStore(t *testing.T) {
 			Want: metadata + `
 				kube_namespace_status_phase{namespace="ns2",phase="Active"} 1
 				kube_namespace_status_phase{namespace="ns2",phase="Terminating"} 0
+`,
+		},
+		{
+			Obj: &v1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "ns3",
+					Labels: map[string]string{
+						"pod-security.kubernetes.io/enforce":         "baseline",
+						"pod-security.kubernetes.io/enforce-version": "v1.28",
+						"pod-security.kubernetes.io/warn":            "restricted",
+					},
+				},
+			},
+			Want: metadata + `
+				kube_namespace_pod_security{level="baseline",mode="enforce",namespace="ns3",version="v1.28"} 1
+				kube_namespace_pod_security{level="restricted",mode="warn",namespace="ns3",version="latest"} 1
+				kube_namespace_status_phase{namespace="ns3",phase="Active"} 0
+				kube_namespace_status_phase{namespace="ns3",phase="Terminating"} 0
 `,
 		},
 	}
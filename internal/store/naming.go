@@ -0,0 +1,101 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import "fmt"
+
+const (
+	// NamingConventionV2 serves the metric families with their current names.
+	NamingConventionV2 = "v2"
+	// NamingConventionV3 additionally serves the families of v3FamilyNames
+	// with names following the Prometheus naming conventions.
+	NamingConventionV3 = "v3"
+)
+
+// v3FamilyNames maps the names of metric families which do not follow the
+// Prometheus naming conventions to their names in the v3 naming convention:
+// timestamps end in _timestamp_seconds and resources are singular.
+var v3FamilyNames = map[string]string{
+	"kube_certificatesigningrequest_created":                "kube_certificatesigningrequest_created_timestamp_seconds",
+	"kube_clusterrole_created":                              "kube_clusterrole_created_timestamp_seconds",
+	"kube_clusterrolebinding_created":                       "kube_clusterrolebinding_created_timestamp_seconds",
+	"kube_configmap_created":                                "kube_configmap_created_timestamp_seconds",
+	"kube_cronjob_created":                                  "kube_cronjob_created_timestamp_seconds",
+	"kube_cronjob_next_schedule_time":                       "kube_cronjob_next_schedule_timestamp_seconds",
+	"kube_cronjob_status_last_schedule_time":                "kube_cronjob_status_last_schedule_timestamp_seconds",
+	"kube_cronjob_status_last_successful_time":              "kube_cronjob_status_last_successful_timestamp_seconds",
+	"kube_csidriver_created":                                "kube_csidriver_created_timestamp_seconds",
+	"kube_csinode_created":                                  "kube_csinode_created_timestamp_seconds",
+	"kube_csistoragecapacity_created":                       "kube_csistoragecapacity_created_timestamp_seconds",
+	"kube_daemonset_created":                                "kube_daemonset_created_timestamp_seconds",
+	"kube_deployment_created":                               "kube_deployment_created_timestamp_seconds",
+	"kube_endpoint_created":                                 "kube_endpoint_created_timestamp_seconds",
+	"kube_endpointslice_created":                            "kube_endpointslice_created_timestamp_seconds",
+	"kube_ingress_created":                                  "kube_ingress_created_timestamp_seconds",
+	"kube_ingressclass_created":                             "kube_ingressclass_created_timestamp_seconds",
+	"kube_job_created":                                      "kube_job_created_timestamp_seconds",
+	"kube_job_status_completion_time":                       "kube_job_status_completion_timestamp_seconds",
+	"kube_job_status_start_time":                            "kube_job_status_start_timestamp_seconds",
+	"kube_lease_renew_time":                                 "kube_lease_renew_timestamp_seconds",
+	"kube_limitrange_created":                               "kube_limitrange_created_timestamp_seconds",
+	"kube_mutatingwebhookconfiguration_created":             "kube_mutatingwebhookconfiguration_created_timestamp_seconds",
+	"kube_namespace_created":                                "kube_namespace_created_timestamp_seconds",
+	"kube_networkpolicy_created":                            "kube_networkpolicy_created_timestamp_seconds",
+	"kube_node_created":                                     "kube_node_created_timestamp_seconds",
+	"kube_node_deletion_timestamp":                          "kube_node_deletion_timestamp_seconds",
+	"kube_persistentvolume_created":                         "kube_persistentvolume_created_timestamp_seconds",
+	"kube_persistentvolume_deletion_timestamp":              "kube_persistentvolume_deletion_timestamp_seconds",
+	"kube_persistentvolumeclaim_created":                    "kube_persistentvolumeclaim_created_timestamp_seconds",
+	"kube_persistentvolumeclaim_deletion_timestamp":         "kube_persistentvolumeclaim_deletion_timestamp_seconds",
+	"kube_pod_completion_time":                              "kube_pod_completion_timestamp_seconds",
+	"kube_pod_created":                                      "kube_pod_created_timestamp_seconds",
+	"kube_pod_deletion_timestamp":                           "kube_pod_deletion_timestamp_seconds",
+	"kube_pod_spec_volumes_persistentvolumeclaims_info":     "kube_pod_spec_volume_persistentvolumeclaim_info",
+	"kube_pod_spec_volumes_persistentvolumeclaims_readonly": "kube_pod_spec_volume_persistentvolumeclaim_readonly",
+	"kube_pod_start_time":                                   "kube_pod_start_timestamp_seconds",
+	"kube_pod_status_container_ready_time":                  "kube_pod_status_container_ready_timestamp_seconds",
+	"kube_pod_status_initialized_time":                      "kube_pod_status_initialized_timestamp_seconds",
+	"kube_pod_status_ready_time":                            "kube_pod_status_ready_timestamp_seconds",
+	"kube_pod_status_scheduled_time":                        "kube_pod_status_scheduled_timestamp_seconds",
+	"kube_poddisruptionbudget_created":                      "kube_poddisruptionbudget_created_timestamp_seconds",
+	"kube_priorityclass_created":                            "kube_priorityclass_created_timestamp_seconds",
+	"kube_replicaset_created":                               "kube_replicaset_created_timestamp_seconds",
+	"kube_replicationcontroller_created":                    "kube_replicationcontroller_created_timestamp_seconds",
+	"kube_resourcequota_created":                            "kube_resourcequota_created_timestamp_seconds",
+	"kube_role_created":                                     "kube_role_created_timestamp_seconds",
+	"kube_rolebinding_created":                              "kube_rolebinding_created_timestamp_seconds",
+	"kube_runtimeclass_created":                             "kube_runtimeclass_created_timestamp_seconds",
+	"kube_secret_created":                                   "kube_secret_created_timestamp_seconds",
+	"kube_service_created":                                  "kube_service_created_timestamp_seconds",
+	"kube_serviceaccount_created":                           "kube_serviceaccount_created_timestamp_seconds",
+	"kube_statefulset_created":                              "kube_statefulset_created_timestamp_seconds",
+	"kube_storageclass_created":                             "kube_storageclass_created_timestamp_seconds",
+	"kube_validatingwebhookconfiguration_created":           "kube_validatingwebhookconfiguration_created_timestamp_seconds",
+	"kube_volumeattachment_created":                         "kube_volumeattachment_created_timestamp_seconds",
+}
+
+// FamilyRenames returns the renames of metric families of the naming
+// convention.
+func FamilyRenames(convention string) (map[string]string, error) {
+	switch convention {
+	case "", NamingConventionV2:
+		return nil, nil
+	case NamingConventionV3:
+		return v3FamilyNames, nil
+	}
+	return nil, fmt.Errorf("invalid naming convention %q, must be one of %q or %q", convention, NamingConventionV2, NamingConventionV3)
+}
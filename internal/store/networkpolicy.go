@@ -50,16 +50,12 @@ func networkPolicyMetricFamilies(allowAnnotationsList, allowLabelsList []string)
 			wrapNetworkPolicyFunc(func(n *networkingv1.NetworkPolicy) *metric.Family {
 				return &metric.Family{
 					Metrics: []*metric.Metric{
-						{
-							LabelKeys:   []string{},
-							LabelValues: []string{},
-							Value:       float64(n.CreationTimestamp.Unix()),
-						},
+						metric.TimestampMetric([]string{}, []string{}, n.CreationTimestamp.Time),
 					},
 				}
 			}),
 		),
-		*generator.NewFamilyGeneratorWithStability(
+		*generator.NewLazyFamilyGenerator(
 			descNetworkPolicyAnnotationsName,
 			descNetworkPolicyAnnotationsHelp,
 			metric.Gauge,
@@ -81,7 +77,7 @@ func networkPolicyMetricFamilies(allowAnnotationsList, allowLabelsList []string)
 				}
 			}),
 		),
-		*generator.NewFamilyGeneratorWithStability(
+		*generator.NewLazyFamilyGenerator(
 			descNetworkPolicyLabelsName,
 			descNetworkPolicyLabelsHelp,
 			metric.Gauge,
@@ -19,6 +19,7 @@ package store
 import (
 	"context"
 	"strings"
+	"time"
 
 	basemetrics "k8s.io/component-base/metrics"
 
@@ -42,22 +43,91 @@ var (
 	descNodeLabelsDefaultLabels = []string{"node"}
 )
 
-func nodeMetricFamilies(allowAnnotationsList, allowLabelsList []string) []generator.FamilyGenerator {
+// nodeFamilyOptions configures the generation of node metric families.
+type nodeFamilyOptions struct {
+	// imageLimit is the maximum number of images per node of
+	// kube_node_image_size_bytes. 0 disables the limit.
+	imageLimit int
+}
+
+func nodeMetricFamilies(allowAnnotationsList, allowLabelsList []string, opts nodeFamilyOptions) []generator.FamilyGenerator {
 	return []generator.FamilyGenerator{
 		createNodeAnnotationsGenerator(allowAnnotationsList),
 		createNodeCreatedFamilyGenerator(),
 		createNodeDeletionTimestampFamilyGenerator(),
+		createNodeImageSizeFamilyGenerator(opts.imageLimit),
 		createNodeInfoFamilyGenerator(),
 		createNodeLabelsGenerator(allowLabelsList),
 		createNodeRoleFamilyGenerator(),
 		createNodeSpecTaintFamilyGenerator(),
 		createNodeSpecUnschedulableFamilyGenerator(),
 		createNodeStatusAllocatableFamilyGenerator(),
+		createNodeStatusAllocatableHugePagesFamilyGenerator(),
+		createNodeStatusAllocatableRatioFamilyGenerator(),
 		createNodeStatusCapacityFamilyGenerator(),
+		createNodeStatusCapacityHugePagesFamilyGenerator(),
+		createNodeStatusCapacitySwapFamilyGenerator(),
 		createNodeStatusConditionFamilyGenerator(),
+		createNodeStatusConditionLastTransitionAgeFamilyGenerator(),
+		createNodeStatusConditionLastTransitionTimeFamilyGenerator(),
 	}
 }
 
+func createNodeImageSizeFamilyGenerator(limit int) generator.FamilyGenerator {
+	return *generator.NewOptInFamilyGenerator(
+		"kube_node_image_size_bytes",
+		"Size of the images cached on the node in bytes, as reported by the kubelet.",
+		metric.Gauge,
+		basemetrics.ALPHA,
+		"",
+		wrapNodeFunc(func(n *v1.Node) *metric.Family {
+			ms := []*metric.Metric{}
+
+			for _, image := range n.Status.Images {
+				if limit > 0 && len(ms) >= limit {
+					break
+				}
+				name, digest := nodeImageNameDigest(image.Names)
+				if name == "" {
+					continue
+				}
+				ms = append(ms, &metric.Metric{
+					LabelKeys:   []string{"image", "digest"},
+					LabelValues: []string{name, digest},
+					Value:       float64(image.SizeBytes),
+				})
+			}
+
+			return &metric.Family{
+				Metrics: ms,
+			}
+		}),
+	)
+}
+
+// nodeImageNameDigest returns the name of an image, preferring a tagged
+// reference over a digest reference, and its digest, if any of the names
+// refers to the image by digest.
+func nodeImageNameDigest(names []string) (name, digest string) {
+	var repository string
+	for _, n := range names {
+		r, d, isDigest := strings.Cut(n, "@")
+		if !isDigest {
+			if name == "" {
+				name = n
+			}
+			continue
+		}
+		if digest == "" {
+			repository, digest = r, d
+		}
+	}
+	if name == "" {
+		name = repository
+	}
+	return name, digest
+}
+
 func createNodeDeletionTimestampFamilyGenerator() generator.FamilyGenerator {
 	return *generator.NewFamilyGeneratorWithStability(
 		"kube_node_deletion_timestamp",
@@ -69,9 +139,7 @@ func createNodeDeletionTimestampFamilyGenerator() generator.FamilyGenerator {
 			var ms []*metric.Metric
 
 			if n.DeletionTimestamp != nil && !n.DeletionTimestamp.IsZero() {
-				ms = append(ms, &metric.Metric{
-					Value: float64(n.DeletionTimestamp.Unix()),
-				})
+				ms = append(ms, metric.TimestampMetric(nil, nil, n.DeletionTimestamp.Time))
 			}
 
 			return &metric.Family{
@@ -92,10 +160,7 @@ func createNodeCreatedFamilyGenerator() generator.FamilyGenerator {
 			ms := []*metric.Metric{}
 
 			if !n.CreationTimestamp.IsZero() {
-				ms = append(ms, &metric.Metric{
-
-					Value: float64(n.CreationTimestamp.Unix()),
-				})
+				ms = append(ms, metric.TimestampMetric(nil, nil, n.CreationTimestamp.Time))
 			}
 
 			return &metric.Family{
@@ -157,7 +222,7 @@ func createNodeInfoFamilyGenerator() generator.FamilyGenerator {
 }
 
 func createNodeAnnotationsGenerator(allowAnnotationsList []string) generator.FamilyGenerator {
-	return *generator.NewFamilyGeneratorWithStability(
+	return *generator.NewLazyFamilyGenerator(
 		descNodeAnnotationsName,
 		descNodeAnnotationsHelp,
 		metric.Gauge,
@@ -182,7 +247,7 @@ func createNodeAnnotationsGenerator(allowAnnotationsList []string) generator.Fam
 }
 
 func createNodeLabelsGenerator(allowLabelsList []string) generator.FamilyGenerator {
-	return *generator.NewFamilyGeneratorWithStability(
+	return *generator.NewLazyFamilyGenerator(
 		descNodeLabelsName,
 		descNodeLabelsHelp,
 		metric.Gauge,
@@ -363,6 +428,35 @@ func createNodeStatusAllocatableFamilyGenerator() generator.FamilyGenerator {
 	)
 }
 
+func createNodeStatusAllocatableRatioFamilyGenerator() generator.FamilyGenerator {
+	return *generator.NewOptInFamilyGenerator(
+		"kube_node_status_allocatable_ratio",
+		"The ratio of allocatable to capacity for different resources of a node.",
+		metric.Gauge,
+		basemetrics.ALPHA,
+		"",
+		wrapNodeFunc(func(n *v1.Node) *metric.Family {
+			ms := []*metric.Metric{}
+
+			for resourceName, capacity := range n.Status.Capacity {
+				allocatable, ok := n.Status.Allocatable[resourceName]
+				if !ok || capacity.MilliValue() <= 0 {
+					continue
+				}
+				ms = append(ms, &metric.Metric{
+					LabelKeys:   []string{"resource"},
+					LabelValues: []string{SanitizeLabelName(string(resourceName))},
+					Value:       float64(allocatable.MilliValue()) / float64(capacity.MilliValue()),
+				})
+			}
+
+			return &metric.Family{
+				Metrics: ms,
+			}
+		}),
+	)
+}
+
 func createNodeStatusCapacityFamilyGenerator() generator.FamilyGenerator {
 	return *generator.NewFamilyGeneratorWithStability(
 		"kube_node_status_capacity",
@@ -446,6 +540,81 @@ func createNodeStatusCapacityFamilyGenerator() generator.FamilyGenerator {
 	)
 }
 
+func createNodeStatusAllocatableHugePagesFamilyGenerator() generator.FamilyGenerator {
+	return createNodeHugePagesFamilyGenerator(
+		"kube_node_status_allocatable_hugepages_bytes",
+		"The allocatable huge pages of a node per page size in bytes.",
+		func(n *v1.Node) v1.ResourceList { return n.Status.Allocatable },
+	)
+}
+
+func createNodeStatusCapacityHugePagesFamilyGenerator() generator.FamilyGenerator {
+	return createNodeHugePagesFamilyGenerator(
+		"kube_node_status_capacity_hugepages_bytes",
+		"The capacity of huge pages of a node per page size in bytes.",
+		func(n *v1.Node) v1.ResourceList { return n.Status.Capacity },
+	)
+}
+
+// createNodeStatusCapacitySwapFamilyGenerator serves the swap capacity
+// reported by the kubelet. There is no allocatable swap, as swap is not a
+// schedulable resource.
+func createNodeStatusCapacitySwapFamilyGenerator() generator.FamilyGenerator {
+	return *generator.NewFamilyGeneratorWithStability(
+		"kube_node_status_capacity_swap_bytes",
+		"The swap capacity of a node in bytes, as reported by the kubelet.",
+		metric.Gauge,
+		basemetrics.ALPHA,
+		"",
+		wrapNodeFunc(func(n *v1.Node) *metric.Family {
+			ms := []*metric.Metric{}
+
+			if swap := n.Status.NodeInfo.Swap; swap != nil && swap.Capacity != nil {
+				ms = append(ms, &metric.Metric{
+					Value: float64(*swap.Capacity),
+				})
+			}
+
+			return &metric.Family{
+				Metrics: ms,
+			}
+		}),
+	)
+}
+
+// createNodeHugePagesFamilyGenerator serves the huge pages resources of the
+// given resource list with the page size as label, e.g. 2Mi for the
+// hugepages-2Mi resource, so that they can be aggregated without parsing the
+// resource label of kube_node_status_capacity and
+// kube_node_status_allocatable.
+func createNodeHugePagesFamilyGenerator(name, help string, resources func(*v1.Node) v1.ResourceList) generator.FamilyGenerator {
+	return *generator.NewFamilyGeneratorWithStability(
+		name,
+		help,
+		metric.Gauge,
+		basemetrics.ALPHA,
+		"",
+		wrapNodeFunc(func(n *v1.Node) *metric.Family {
+			ms := []*metric.Metric{}
+
+			for resourceName, val := range resources(n) {
+				if !isHugePageResourceName(resourceName) {
+					continue
+				}
+				ms = append(ms, &metric.Metric{
+					LabelKeys:   []string{"size"},
+					LabelValues: []string{strings.TrimPrefix(string(resourceName), v1.ResourceHugePagesPrefix)},
+					Value:       float64(val.MilliValue()) / 1000,
+				})
+			}
+
+			return &metric.Family{
+				Metrics: ms,
+			}
+		}),
+	)
+}
+
 // createNodeStatusConditionFamilyGenerator returns an all-in-one metric family
 // containing all conditions for extensibility. Third party plugin may report
 // customized condition for cluster node (e.g. node-problem-detector), and
@@ -458,20 +627,42 @@ func createNodeStatusConditionFamilyGenerator() generator.FamilyGenerator {
 		basemetrics.STABLE,
 		"",
 		wrapNodeFunc(func(n *v1.Node) *metric.Family {
-			ms := make([]*metric.Metric, len(n.Status.Conditions)*len(conditionStatuses))
-
-			// Collect node conditions and while default to false.
-			for i, c := range n.Status.Conditions {
-				conditionMetrics := addConditionMetrics(c.Status)
+			ms := statusConditionMetrics(n.Status.Conditions, func(c v1.NodeCondition) (string, v1.ConditionStatus) {
+				return string(c.Type), c.Status
+			})
 
-				for j, m := range conditionMetrics {
-					metric := m
+			return &metric.Family{
+				Metrics: ms,
+			}
+		}),
+	)
+}
 
-					metric.LabelKeys = []string{"condition", "status"}
-					metric.LabelValues = append([]string{string(c.Type)}, metric.LabelValues...)
+// createNodeStatusConditionLastTransitionAgeFamilyGenerator reports how long
+// each condition has been in its current status. It is generated whenever it
+// is scraped, so that the age advances between node status updates.
+func createNodeStatusConditionLastTransitionAgeFamilyGenerator() generator.FamilyGenerator {
+	f := generator.NewOptInFamilyGenerator(
+		"kube_node_status_condition_last_transition_age_seconds",
+		"Seconds since the condition of a cluster node last transitioned.",
+		metric.Gauge,
+		basemetrics.ALPHA,
+		"",
+		wrapNodeFunc(func(n *v1.Node) *metric.Family {
+			ms := []*metric.Metric{}
+			now := timeNow()
 
-					ms[i*len(conditionStatuses)+j] = metric
+			for _, c := range uniqueConditions(n.Status.Conditions, func(c v1.NodeCondition) (string, v1.ConditionStatus) {
+				return string(c.Type), c.Status
+			}) {
+				if c.LastTransitionTime.IsZero() {
+					continue
 				}
+				ms = append(ms, &metric.Metric{
+					LabelKeys:   []string{"condition", "status"},
+					LabelValues: []string{string(c.Type), strings.ToLower(string(c.Status))},
+					Value:       now.Sub(c.LastTransitionTime.Time).Seconds(),
+				})
 			}
 
 			return &metric.Family{
@@ -479,6 +670,30 @@ func createNodeStatusConditionFamilyGenerator() generator.FamilyGenerator {
 			}
 		}),
 	)
+	f.ScrapeTime = true
+	return *f
+}
+
+// createNodeStatusConditionLastTransitionTimeFamilyGenerator serves when each
+// condition last transitioned, so that flapping conditions can be detected by
+// the changes of the value, e.g. with changes() in PromQL.
+func createNodeStatusConditionLastTransitionTimeFamilyGenerator() generator.FamilyGenerator {
+	return *generator.NewFamilyGeneratorWithStability(
+		"kube_node_status_condition_last_transition_time",
+		"Unix timestamp of the last transition of the condition of a cluster node.",
+		metric.Gauge,
+		basemetrics.ALPHA,
+		"",
+		wrapNodeFunc(func(n *v1.Node) *metric.Family {
+			return &metric.Family{
+				Metrics: statusConditionLastTransitionMetrics(n.Status.Conditions, func(c v1.NodeCondition) (string, v1.ConditionStatus) {
+					return string(c.Type), c.Status
+				}, func(c v1.NodeCondition) time.Time {
+					return c.LastTransitionTime.Time
+				}),
+			}
+		}),
+	)
 }
 
 func wrapNodeFunc(f func(*v1.Node) *metric.Family) func(interface{}) *metric.Family {
@@ -23,6 +23,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 
 	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
 )
@@ -102,6 +103,9 @@ func TestNodeStore(t *testing.T) {
 						OSImage:                 "osimage",
 						ContainerRuntimeVersion: "rkt",
 						SystemUUID:              "6a934e21-5207-4a84-baea-3a952d926c80",
+						Swap: &v1.NodeSwapStatus{
+							Capacity: ptr.To(int64(4294967296)),
+						},
 					},
 					Addresses: []v1.NodeAddress{
 						{Type: "InternalIP", Address: "1.2.3.4"},
@@ -113,6 +117,7 @@ func TestNodeStore(t *testing.T) {
 						v1.ResourceStorage:                resource.MustParse("3G"),
 						v1.ResourceEphemeralStorage:       resource.MustParse("4G"),
 						v1.ResourceName("nvidia.com/gpu"): resource.MustParse("4"),
+						v1.ResourceName("hugepages-2Mi"):  resource.MustParse("1Gi"),
 					},
 					Allocatable: v1.ResourceList{
 						v1.ResourceCPU:                    resource.MustParse("3"),
@@ -121,6 +126,7 @@ func TestNodeStore(t *testing.T) {
 						v1.ResourceStorage:                resource.MustParse("2G"),
 						v1.ResourceEphemeralStorage:       resource.MustParse("3G"),
 						v1.ResourceName("nvidia.com/gpu"): resource.MustParse("1"),
+						v1.ResourceName("hugepages-2Mi"):  resource.MustParse("512Mi"),
 					},
 				},
 			},
@@ -131,26 +137,46 @@ func TestNodeStore(t *testing.T) {
 		# HELP kube_node_role The role of a cluster node.
 		# HELP kube_node_spec_unschedulable [STABLE] Whether a node can schedule new pods.
 		# HELP kube_node_status_allocatable [STABLE] The allocatable for different resources of a node that are available for scheduling.
+		# HELP kube_node_status_allocatable_hugepages_bytes The allocatable huge pages of a node per page size in bytes.
+		# HELP kube_node_status_allocatable_ratio The ratio of allocatable to capacity for different resources of a node.
 		# HELP kube_node_status_capacity [STABLE] The capacity for different resources of a node.
+		# HELP kube_node_status_capacity_hugepages_bytes The capacity of huge pages of a node per page size in bytes.
+		# HELP kube_node_status_capacity_swap_bytes The swap capacity of a node in bytes, as reported by the kubelet.
 		# TYPE kube_node_created gauge
 		# TYPE kube_node_info gauge
 		# TYPE kube_node_labels gauge
 		# TYPE kube_node_role gauge
 		# TYPE kube_node_spec_unschedulable gauge
 		# TYPE kube_node_status_allocatable gauge
+		# TYPE kube_node_status_allocatable_hugepages_bytes gauge
+		# TYPE kube_node_status_allocatable_ratio gauge
 		# TYPE kube_node_status_capacity gauge
+		# TYPE kube_node_status_capacity_hugepages_bytes gauge
+		# TYPE kube_node_status_capacity_swap_bytes gauge
 		kube_node_created{node="127.0.0.1"} 1.5e+09
         kube_node_info{container_runtime_version="rkt",kernel_version="kernel",kubelet_version="kubelet",kubeproxy_version="kubeproxy",node="127.0.0.1",os_image="osimage",pod_cidr="172.24.10.0/24",provider_id="provider://i-randomidentifier",internal_ip="1.2.3.4",system_uuid="6a934e21-5207-4a84-baea-3a952d926c80"} 1
 		kube_node_role{node="127.0.0.1",role="master"} 1
         kube_node_spec_unschedulable{node="127.0.0.1"} 1
         kube_node_status_allocatable{node="127.0.0.1",resource="cpu",unit="core"} 3
         kube_node_status_allocatable{node="127.0.0.1",resource="ephemeral_storage",unit="byte"} 3e+09
+        kube_node_status_allocatable{node="127.0.0.1",resource="hugepages_2Mi",unit="byte"} 5.36870912e+08
+        kube_node_status_allocatable_hugepages_bytes{node="127.0.0.1",size="2Mi"} 5.36870912e+08
         kube_node_status_allocatable{node="127.0.0.1",resource="memory",unit="byte"} 1e+09
         kube_node_status_allocatable{node="127.0.0.1",resource="nvidia_com_gpu",unit="integer"} 1
         kube_node_status_allocatable{node="127.0.0.1",resource="pods",unit="integer"} 555
         kube_node_status_allocatable{node="127.0.0.1",resource="storage",unit="byte"} 2e+09
+        kube_node_status_allocatable_ratio{node="127.0.0.1",resource="cpu"} 0.6976744186046512
+        kube_node_status_allocatable_ratio{node="127.0.0.1",resource="ephemeral_storage"} 0.75
+        kube_node_status_allocatable_ratio{node="127.0.0.1",resource="hugepages_2Mi"} 0.5
+        kube_node_status_allocatable_ratio{node="127.0.0.1",resource="memory"} 0.5
+        kube_node_status_allocatable_ratio{node="127.0.0.1",resource="nvidia_com_gpu"} 0.25
+        kube_node_status_allocatable_ratio{node="127.0.0.1",resource="pods"} 0.555
+        kube_node_status_allocatable_ratio{node="127.0.0.1",resource="storage"} 0.6666666666666666
         kube_node_status_capacity{node="127.0.0.1",resource="cpu",unit="core"} 4.3
         kube_node_status_capacity{node="127.0.0.1",resource="ephemeral_storage",unit="byte"} 4e+09
+        kube_node_status_capacity{node="127.0.0.1",resource="hugepages_2Mi",unit="byte"} 1.073741824e+09
+        kube_node_status_capacity_hugepages_bytes{node="127.0.0.1",size="2Mi"} 1.073741824e+09
+        kube_node_status_capacity_swap_bytes{node="127.0.0.1"} 4.294967296e+09
         kube_node_status_capacity{node="127.0.0.1",resource="memory",unit="byte"} 2e+09
         kube_node_status_capacity{node="127.0.0.1",resource="nvidia_com_gpu",unit="integer"} 4
         kube_node_status_capacity{node="127.0.0.1",resource="pods",unit="integer"} 1000
@@ -158,7 +184,10 @@ func TestNodeStore(t *testing.T) {
 			`,
 			MetricNames: []string{
 				"kube_node_status_capacity",
+				"kube_node_status_capacity_hugepages_bytes",
+				"kube_node_status_capacity_swap_bytes",
 				"kube_node_status_allocatable",
+				"kube_node_status_allocatable_hugepages_bytes",
 				"kube_node_spec_unschedulable",
 				"kube_node_labels",
 				"kube_node_role",
@@ -182,7 +211,11 @@ func TestNodeStore(t *testing.T) {
 			},
 			Want: `
 		# HELP kube_node_status_condition [STABLE] The condition of a cluster node.
+		# HELP kube_node_status_condition_last_transition_age_seconds Seconds since the condition of a cluster node last transitioned.
+		# HELP kube_node_status_condition_last_transition_time Unix timestamp of the last transition of the condition of a cluster node.
 		# TYPE kube_node_status_condition gauge
+		# TYPE kube_node_status_condition_last_transition_age_seconds gauge
+		# TYPE kube_node_status_condition_last_transition_time gauge
         kube_node_status_condition{condition="CustomizedType",node="127.0.0.1",status="false"} 0
         kube_node_status_condition{condition="CustomizedType",node="127.0.0.1",status="true"} 1
         kube_node_status_condition{condition="CustomizedType",node="127.0.0.1",status="unknown"} 0
@@ -210,7 +243,11 @@ func TestNodeStore(t *testing.T) {
 			},
 			Want: `
 		# HELP kube_node_status_condition [STABLE] The condition of a cluster node.
+		# HELP kube_node_status_condition_last_transition_age_seconds Seconds since the condition of a cluster node last transitioned.
+		# HELP kube_node_status_condition_last_transition_time Unix timestamp of the last transition of the condition of a cluster node.
 		# TYPE kube_node_status_condition gauge
+		# TYPE kube_node_status_condition_last_transition_age_seconds gauge
+		# TYPE kube_node_status_condition_last_transition_time gauge
         kube_node_status_condition{condition="CustomizedType",node="127.0.0.2",status="false"} 0
         kube_node_status_condition{condition="CustomizedType",node="127.0.0.2",status="true"} 0
         kube_node_status_condition{condition="CustomizedType",node="127.0.0.2",status="unknown"} 1
@@ -238,7 +275,11 @@ func TestNodeStore(t *testing.T) {
 			},
 			Want: `
 		# HELP kube_node_status_condition [STABLE] The condition of a cluster node.
+		# HELP kube_node_status_condition_last_transition_age_seconds Seconds since the condition of a cluster node last transitioned.
+		# HELP kube_node_status_condition_last_transition_time Unix timestamp of the last transition of the condition of a cluster node.
 		# TYPE kube_node_status_condition gauge
+		# TYPE kube_node_status_condition_last_transition_age_seconds gauge
+		# TYPE kube_node_status_condition_last_transition_time gauge
         kube_node_status_condition{condition="CustomizedType",node="127.0.0.3",status="false"} 1
         kube_node_status_condition{condition="CustomizedType",node="127.0.0.3",status="true"} 0
         kube_node_status_condition{condition="CustomizedType",node="127.0.0.3",status="unknown"} 0
@@ -274,12 +315,100 @@ func TestNodeStore(t *testing.T) {
 			`,
 			MetricNames: []string{"kube_node_spec_taint"},
 		},
+		// Verify condition last transition age
+		{
+			Obj: &v1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "127.0.0.1",
+				},
+				Status: v1.NodeStatus{
+					Conditions: []v1.NodeCondition{
+						{Type: v1.NodeMemoryPressure, Status: v1.ConditionTrue, LastTransitionTime: metav1.Time{Time: time.Unix(1500000000, 0)}},
+						{Type: v1.NodeReady, Status: v1.ConditionFalse, LastTransitionTime: metav1.Time{Time: time.Unix(1500000300, 0)}},
+						{Type: v1.NodeDiskPressure, Status: v1.ConditionFalse},
+					},
+				},
+			},
+			Want: `
+		# HELP kube_node_status_condition_last_transition_age_seconds Seconds since the condition of a cluster node last transitioned.
+		# TYPE kube_node_status_condition_last_transition_age_seconds gauge
+        kube_node_status_condition_last_transition_age_seconds{condition="MemoryPressure",node="127.0.0.1",status="true"} 600
+        kube_node_status_condition_last_transition_age_seconds{condition="Ready",node="127.0.0.1",status="false"} 300
+`,
+			MetricNames: []string{"kube_node_status_condition_last_transition_age_seconds"},
+		},
+		// Verify condition last transition time
+		{
+			Obj: &v1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "127.0.0.1",
+				},
+				Status: v1.NodeStatus{
+					Conditions: []v1.NodeCondition{
+						{Type: v1.NodeMemoryPressure, Status: v1.ConditionTrue, LastTransitionTime: metav1.Time{Time: time.Unix(1500000000, 0)}},
+						{Type: v1.NodeReady, Status: v1.ConditionFalse, LastTransitionTime: metav1.Time{Time: time.Unix(1500000300, 0)}},
+						{Type: v1.NodeDiskPressure, Status: v1.ConditionFalse},
+					},
+				},
+			},
+			Want: `
+		# HELP kube_node_status_condition_last_transition_time Unix timestamp of the last transition of the condition of a cluster node.
+		# TYPE kube_node_status_condition_last_transition_time gauge
+        kube_node_status_condition_last_transition_time{condition="MemoryPressure",node="127.0.0.1",status="true"} 1.5e+09
+        kube_node_status_condition_last_transition_time{condition="Ready",node="127.0.0.1",status="false"} 1.5000003e+09
+`,
+			MetricNames: []string{"kube_node_status_condition_last_transition_time"},
+		},
 	}
+	timeNow = func() time.Time { return time.Unix(1500000600, 0) }
+	defer func() { timeNow = time.Now }()
 	for i, c := range cases {
-		c.Func = generator.ComposeMetricGenFuncs(nodeMetricFamilies(nil, nil))
-		c.Headers = generator.ExtractMetricFamilyHeaders(nodeMetricFamilies(nil, nil))
+		c.Func = generator.ComposeMetricGenFuncs(nodeMetricFamilies(nil, nil, nodeFamilyOptions{}))
+		c.Headers = generator.ExtractMetricFamilyHeaders(nodeMetricFamilies(nil, nil, nodeFamilyOptions{}))
 		if err := c.run(); err != nil {
 			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
 		}
 	}
 }
+
+func TestNodeStoreImageLimit(t *testing.T) {
+	c := generateMetricsTestCase{
+		Obj: &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "127.0.0.1",
+			},
+			Status: v1.NodeStatus{
+				Images: []v1.ContainerImage{
+					{
+						Names: []string{
+							"registry.k8s.io/kube-state-metrics/kube-state-metrics@sha256:aaaa",
+							"registry.k8s.io/kube-state-metrics/kube-state-metrics:v2.10.0",
+						},
+						SizeBytes: 30000000,
+					},
+					{
+						Names:     []string{"docker.io/library/busybox@sha256:bbbb"},
+						SizeBytes: 2000000,
+					},
+					{
+						Names:     []string{"registry.k8s.io/pause:3.9"},
+						SizeBytes: 300000,
+					},
+				},
+			},
+		},
+		Want: `
+			# HELP kube_node_image_size_bytes Size of the images cached on the node in bytes, as reported by the kubelet.
+			# TYPE kube_node_image_size_bytes gauge
+			kube_node_image_size_bytes{digest="sha256:aaaa",image="registry.k8s.io/kube-state-metrics/kube-state-metrics:v2.10.0",node="127.0.0.1"} 3e+07
+			kube_node_image_size_bytes{digest="sha256:bbbb",image="docker.io/library/busybox",node="127.0.0.1"} 2e+06
+		`,
+		MetricNames: []string{"kube_node_image_size_bytes"},
+	}
+	families := nodeMetricFamilies(nil, nil, nodeFamilyOptions{imageLimit: 2})
+	c.Func = generator.ComposeMetricGenFuncs(families)
+	c.Headers = generator.ExtractMetricFamilyHeaders(families)
+	if err := c.run(); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
@@ -81,7 +81,7 @@ func persistentVolumeMetricFamilies(allowAnnotationsList, allowLabelsList []stri
 				}
 			}),
 		),
-		*generator.NewFamilyGeneratorWithStability(
+		*generator.NewLazyFamilyGenerator(
 			descPersistentVolumeAnnotationsName,
 			descPersistentVolumeAnnotationsHelp,
 			metric.Gauge,
@@ -103,7 +103,7 @@ func persistentVolumeMetricFamilies(allowAnnotationsList, allowLabelsList []stri
 				}
 			}),
 		),
-		*generator.NewFamilyGeneratorWithStability(
+		*generator.NewLazyFamilyGenerator(
 			descPersistentVolumeLabelsName,
 			descPersistentVolumeLabelsHelp,
 			metric.Gauge,
@@ -318,11 +318,7 @@ func persistentVolumeMetricFamilies(allowAnnotationsList, allowLabelsList []stri
 				ms := []*metric.Metric{}
 
 				if !p.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						LabelKeys:   []string{},
-						LabelValues: []string{},
-						Value:       float64(p.CreationTimestamp.Unix()),
-					})
+					ms = append(ms, metric.TimestampMetric([]string{}, []string{}, p.CreationTimestamp.Time))
 				}
 
 				return &metric.Family{
@@ -340,10 +336,52 @@ func persistentVolumeMetricFamilies(allowAnnotationsList, allowLabelsList []stri
 				ms := []*metric.Metric{}
 
 				if p.DeletionTimestamp != nil && !p.DeletionTimestamp.IsZero() {
+					ms = append(ms, metric.TimestampMetric([]string{}, []string{}, p.DeletionTimestamp.Time))
+				}
+
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		),
+		*generator.NewFamilyGeneratorWithStability(
+			"kube_persistentvolume_volume_mode",
+			"Volume mode of the persistentvolume.",
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			wrapPersistentVolumeFunc(func(p *v1.PersistentVolume) *metric.Family {
+				// Persistent volumes without a volume mode are filesystem volumes.
+				volumeMode := v1.PersistentVolumeFilesystem
+				if p.Spec.VolumeMode != nil {
+					volumeMode = *p.Spec.VolumeMode
+				}
+
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							LabelKeys:   []string{"volumemode"},
+							LabelValues: []string{string(volumeMode)},
+							Value:       1,
+						},
+					},
+				}
+			}),
+		),
+		*generator.NewFamilyGeneratorWithStability(
+			"kube_persistentvolume_reclaim_policy",
+			"Reclaim policy of the persistentvolume.",
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			wrapPersistentVolumeFunc(func(p *v1.PersistentVolume) *metric.Family {
+				ms := []*metric.Metric{}
+
+				if p.Spec.PersistentVolumeReclaimPolicy != "" {
 					ms = append(ms, &metric.Metric{
-						LabelKeys:   []string{},
-						LabelValues: []string{},
-						Value:       float64(p.DeletionTimestamp.Unix()),
+						LabelKeys:   []string{"reclaim_policy"},
+						LabelValues: []string{string(p.Spec.PersistentVolumeReclaimPolicy)},
+						Value:       1,
 					})
 				}
 
@@ -352,6 +390,43 @@ func persistentVolumeMetricFamilies(allowAnnotationsList, allowLabelsList []stri
 				}
 			}),
 		),
+		*generator.NewFamilyGeneratorWithStability(
+			"kube_persistentvolume_node_affinity",
+			"Node selector requirements of the node affinity of the persistentvolume, one series per value. Requirements of different terms are ORed.",
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			wrapPersistentVolumeFunc(func(p *v1.PersistentVolume) *metric.Family {
+				ms := []*metric.Metric{}
+
+				if p.Spec.NodeAffinity == nil || p.Spec.NodeAffinity.Required == nil {
+					return &metric.Family{
+						Metrics: ms,
+					}
+				}
+
+				for i, term := range p.Spec.NodeAffinity.Required.NodeSelectorTerms {
+					for _, req := range term.MatchExpressions {
+						values := req.Values
+						if len(values) == 0 {
+							// Exists and DoesNotExist requirements have no values.
+							values = []string{""}
+						}
+						for _, value := range values {
+							ms = append(ms, &metric.Metric{
+								LabelKeys:   []string{"term", "key", "operator", "value"},
+								LabelValues: []string{strconv.Itoa(i), req.Key, string(req.Operator), value},
+								Value:       1,
+							})
+						}
+					}
+				}
+
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		),
 		*generator.NewOptInFamilyGenerator(
 			descPersistentVolumeCSIAttributesName,
 			descPersistentVolumeCSIAttributesHelp,
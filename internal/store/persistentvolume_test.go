@@ -30,6 +30,7 @@ import (
 
 func TestPersistentVolumeStore(t *testing.T) {
 	iscsiInitiatorName := "iqn.my.test.initiator:112233"
+	blockVolumeMode := v1.PersistentVolumeBlock
 	cases := []generateMetricsTestCase{
 		// Verify phase enumerations.
 		{
@@ -743,6 +744,73 @@ func TestPersistentVolumeStore(t *testing.T) {
 				`,
 			MetricNames: []string{"kube_persistentvolume_csi_attributes"},
 		},
+		{
+			Obj: &v1.PersistentVolume{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-pv-local",
+				},
+				Spec: v1.PersistentVolumeSpec{
+					PersistentVolumeReclaimPolicy: v1.PersistentVolumeReclaimRetain,
+					PersistentVolumeSource: v1.PersistentVolumeSource{
+						Local: &v1.LocalVolumeSource{
+							Path: "/mnt/disks/ssd1",
+						},
+					},
+					NodeAffinity: &v1.VolumeNodeAffinity{
+						Required: &v1.NodeSelector{
+							NodeSelectorTerms: []v1.NodeSelectorTerm{
+								{
+									MatchExpressions: []v1.NodeSelectorRequirement{
+										{Key: "topology.kubernetes.io/zone", Operator: v1.NodeSelectorOpIn, Values: []string{"zone-a", "zone-b"}},
+										{Key: "node.kubernetes.io/exclude-from-storage", Operator: v1.NodeSelectorOpDoesNotExist},
+									},
+								},
+								{
+									MatchExpressions: []v1.NodeSelectorRequirement{
+										{Key: "kubernetes.io/hostname", Operator: v1.NodeSelectorOpIn, Values: []string{"node-1"}},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			Want: `
+				# HELP kube_persistentvolume_node_affinity Node selector requirements of the node affinity of the persistentvolume, one series per value. Requirements of different terms are ORed.
+				# HELP kube_persistentvolume_reclaim_policy Reclaim policy of the persistentvolume.
+				# HELP kube_persistentvolume_volume_mode Volume mode of the persistentvolume.
+				# TYPE kube_persistentvolume_node_affinity gauge
+				# TYPE kube_persistentvolume_reclaim_policy gauge
+				# TYPE kube_persistentvolume_volume_mode gauge
+				kube_persistentvolume_node_affinity{persistentvolume="test-pv-local",term="0",key="topology.kubernetes.io/zone",operator="In",value="zone-a"} 1
+				kube_persistentvolume_node_affinity{persistentvolume="test-pv-local",term="0",key="topology.kubernetes.io/zone",operator="In",value="zone-b"} 1
+				kube_persistentvolume_node_affinity{persistentvolume="test-pv-local",term="0",key="node.kubernetes.io/exclude-from-storage",operator="DoesNotExist",value=""} 1
+				kube_persistentvolume_node_affinity{persistentvolume="test-pv-local",term="1",key="kubernetes.io/hostname",operator="In",value="node-1"} 1
+				kube_persistentvolume_reclaim_policy{persistentvolume="test-pv-local",reclaim_policy="Retain"} 1
+				kube_persistentvolume_volume_mode{persistentvolume="test-pv-local",volumemode="Filesystem"} 1
+`,
+			MetricNames: []string{"kube_persistentvolume_node_affinity", "kube_persistentvolume_reclaim_policy", "kube_persistentvolume_volume_mode"},
+		},
+		{
+			Obj: &v1.PersistentVolume{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-pv-block",
+				},
+				Spec: v1.PersistentVolumeSpec{
+					VolumeMode: &blockVolumeMode,
+				},
+			},
+			Want: `
+				# HELP kube_persistentvolume_node_affinity Node selector requirements of the node affinity of the persistentvolume, one series per value. Requirements of different terms are ORed.
+				# HELP kube_persistentvolume_reclaim_policy Reclaim policy of the persistentvolume.
+				# HELP kube_persistentvolume_volume_mode Volume mode of the persistentvolume.
+				# TYPE kube_persistentvolume_node_affinity gauge
+				# TYPE kube_persistentvolume_reclaim_policy gauge
+				# TYPE kube_persistentvolume_volume_mode gauge
+				kube_persistentvolume_volume_mode{persistentvolume="test-pv-block",volumemode="Block"} 1
+`,
+			MetricNames: []string{"kube_persistentvolume_node_affinity", "kube_persistentvolume_reclaim_policy", "kube_persistentvolume_volume_mode"},
+		},
 	}
 	for i, c := range cases {
 		c.Func = generator.ComposeMetricGenFuncs(persistentVolumeMetricFamilies(c.AllowAnnotationsList, c.AllowLabelsList))
@@ -42,7 +42,7 @@ var (
 
 func persistentVolumeClaimMetricFamilies(allowAnnotationsList, allowLabelsList []string) []generator.FamilyGenerator {
 	return []generator.FamilyGenerator{
-		*generator.NewFamilyGeneratorWithStability(
+		*generator.NewLazyFamilyGenerator(
 			descPersistentVolumeClaimLabelsName,
 			descPersistentVolumeClaimLabelsHelp,
 			metric.Gauge,
@@ -64,7 +64,7 @@ func persistentVolumeClaimMetricFamilies(allowAnnotationsList, allowLabelsList [
 				}
 			}),
 		),
-		*generator.NewFamilyGeneratorWithStability(
+		*generator.NewLazyFamilyGenerator(
 			descPersistentVolumeClaimAnnotationsName,
 			descPersistentVolumeClaimAnnotationsHelp,
 			metric.Gauge,
@@ -201,20 +201,9 @@ func persistentVolumeClaimMetricFamilies(allowAnnotationsList, allowLabelsList [
 			basemetrics.ALPHA,
 			"",
 			wrapPersistentVolumeClaimFunc(func(p *v1.PersistentVolumeClaim) *metric.Family {
-				ms := make([]*metric.Metric, len(p.Status.Conditions)*len(conditionStatuses))
-
-				for i, c := range p.Status.Conditions {
-					conditionMetrics := addConditionMetrics(c.Status)
-
-					for j, m := range conditionMetrics {
-						metric := m
-
-						metric.LabelKeys = []string{"condition", "status"}
-						metric.LabelValues = append([]string{string(c.Type)}, metric.LabelValues...)
-
-						ms[i*len(conditionStatuses)+j] = metric
-					}
-				}
+				ms := statusConditionMetrics(p.Status.Conditions, func(c v1.PersistentVolumeClaimCondition) (string, v1.ConditionStatus) {
+					return string(c.Type), c.Status
+				})
 
 				return &metric.Family{
 					Metrics: ms,
@@ -231,11 +220,29 @@ func persistentVolumeClaimMetricFamilies(allowAnnotationsList, allowLabelsList [
 				ms := []*metric.Metric{}
 
 				if !p.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						LabelKeys:   []string{},
-						LabelValues: []string{},
-						Value:       float64(p.CreationTimestamp.Unix()),
-					})
+					ms = append(ms, metric.TimestampMetric([]string{}, []string{}, p.CreationTimestamp.Time))
+				}
+
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		),
+		*generator.NewFamilyGeneratorWithStability(
+			"kube_persistentvolumeclaim_unbound_since_timestamp_seconds",
+			"Unix timestamp since which the persistent volume claim is waiting to be bound.",
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			wrapPersistentVolumeClaimFunc(func(p *v1.PersistentVolumeClaim) *metric.Family {
+				ms := []*metric.Metric{}
+
+				// Claims are pending from their creation until they are bound,
+				// bound claims never become pending again. The duration is
+				// left to the query, as metrics are only generated when the
+				// claim changes.
+				if p.Status.Phase == v1.ClaimPending && !p.CreationTimestamp.IsZero() {
+					ms = append(ms, metric.TimestampMetric([]string{}, []string{}, p.CreationTimestamp.Time))
 				}
 
 				return &metric.Family{
@@ -253,11 +260,7 @@ func persistentVolumeClaimMetricFamilies(allowAnnotationsList, allowLabelsList [
 				ms := []*metric.Metric{}
 
 				if p.DeletionTimestamp != nil && !p.DeletionTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						LabelKeys:   []string{},
-						LabelValues: []string{},
-						Value:       float64(p.DeletionTimestamp.Unix()),
-					})
+					ms = append(ms, metric.TimestampMetric([]string{}, []string{}, p.DeletionTimestamp.Time))
 				}
 
 				return &metric.Family{
@@ -51,7 +51,7 @@ func TestPersistentVolumeClaimStore(t *testing.T) {
 						v1.ReadWriteOnce,
 					},
 					StorageClassName: &storageClassName,
-					Resources: v1.ResourceRequirements{
+					Resources: v1.VolumeResourceRequirements{
 						Requests: v1.ResourceList{
 							v1.ResourceStorage: resource.MustParse("1Gi"),
 						},
@@ -123,7 +123,7 @@ func TestPersistentVolumeClaimStore(t *testing.T) {
 						v1.ReadWriteOnce,
 					},
 					StorageClassName: &storageClassName,
-					Resources: v1.ResourceRequirements{
+					Resources: v1.VolumeResourceRequirements{
 						Requests: v1.ResourceList{
 							v1.ResourceStorage: resource.MustParse("1Gi"),
 						},
@@ -287,7 +287,7 @@ func TestPersistentVolumeClaimStore(t *testing.T) {
 					AccessModes: []v1.PersistentVolumeAccessMode{
 						v1.ReadWriteOnce,
 					},
-					Resources: v1.ResourceRequirements{
+					Resources: v1.VolumeResourceRequirements{
 						Requests: v1.ResourceList{
 							v1.ResourceStorage: resource.MustParse("1Gi"),
 						},
@@ -311,6 +311,44 @@ func TestPersistentVolumeClaimStore(t *testing.T) {
 `,
 			MetricNames: []string{"kube_persistentvolumeclaim_deletion_timestamp", "kube_persistentvolumeclaim_status_phase"},
 		},
+		{
+			Obj: &v1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "pending-data",
+					CreationTimestamp: metav1.Time{Time: time.Unix(1500000000, 0)},
+					Namespace:         "default",
+				},
+				Spec: v1.PersistentVolumeClaimSpec{
+					StorageClassName: &storageClassName,
+				},
+				Status: v1.PersistentVolumeClaimStatus{
+					Phase: v1.ClaimPending,
+				},
+			},
+			Want: `
+				# HELP kube_persistentvolumeclaim_unbound_since_timestamp_seconds Unix timestamp since which the persistent volume claim is waiting to be bound.
+				# TYPE kube_persistentvolumeclaim_unbound_since_timestamp_seconds gauge
+				kube_persistentvolumeclaim_unbound_since_timestamp_seconds{namespace="default",persistentvolumeclaim="pending-data"} 1.5e+09
+`,
+			MetricNames: []string{"kube_persistentvolumeclaim_unbound_since_timestamp_seconds"},
+		},
+		{
+			Obj: &v1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "bound-data",
+					CreationTimestamp: metav1.Time{Time: time.Unix(1500000000, 0)},
+					Namespace:         "default",
+				},
+				Status: v1.PersistentVolumeClaimStatus{
+					Phase: v1.ClaimBound,
+				},
+			},
+			Want: `
+				# HELP kube_persistentvolumeclaim_unbound_since_timestamp_seconds Unix timestamp since which the persistent volume claim is waiting to be bound.
+				# TYPE kube_persistentvolumeclaim_unbound_since_timestamp_seconds gauge
+`,
+			MetricNames: []string{"kube_persistentvolumeclaim_unbound_since_timestamp_seconds"},
+		},
 	}
 	for i, c := range cases {
 		c.Func = generator.ComposeMetricGenFuncs(persistentVolumeClaimMetricFamilies(c.AllowAnnotationsList, c.AllowLabelsList))
@@ -19,11 +19,12 @@ package store
 import (
 	"context"
 	"strconv"
+	"strings"
+	"time"
 
 	basemetrics "k8s.io/component-base/metrics"
 	"k8s.io/utils/net"
 
-	"k8s.io/kube-state-metrics/v2/pkg/constant"
 	"k8s.io/kube-state-metrics/v2/pkg/metric"
 	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
 
@@ -38,812 +39,108 @@ import (
 var (
 	descPodLabelsDefaultLabels = []string{"namespace", "pod", "uid"}
 	podStatusReasons           = []string{"Evicted", "NodeAffinity", "NodeLost", "Shutdown", "UnexpectedAdmissionError"}
+
+	// The prefixes of the eviction messages of the kubelet.
+	evictionNodeLowMessagePrefix       = "The node was low on resource: "
+	evictionNodeConditionMessagePrefix = "The node had condition: "
+
+	// UnitSuffixedFamilies maps the deprecated families with the unit of a
+	// resource in their name to the opt-in family which serves the resource in
+	// its base unit with a unit label instead.
+	UnitSuffixedFamilies = map[string]string{
+		"kube_pod_overhead_cpu_cores":    "kube_pod_overhead",
+		"kube_pod_overhead_memory_bytes": "kube_pod_overhead",
+	}
 )
 
-func podMetricFamilies(allowAnnotationsList, allowLabelsList []string) []generator.FamilyGenerator {
-	return []generator.FamilyGenerator{
+// podFamilyOptions configures the generation of pod metric families.
+type podFamilyOptions struct {
+	// splitTerminalPods serves Succeeded and Failed pods by
+	// kube_pod_terminal_info instead of kube_pod_info.
+	splitTerminalPods bool
+	// lastTerminatedReasonLimit is the maximum number of distinct reasons of
+	// kube_pod_container_status_last_terminated_info and its init and
+	// ephemeral container equivalents. 0 disables the limit.
+	lastTerminatedReasonLimit int
+	// statusReasons are the reasons of kube_pod_status_reason. The default
+	// podStatusReasons are used if it is empty.
+	statusReasons []string
+}
+
+func podMetricFamilies(allowAnnotationsList, allowLabelsList []string, opts podFamilyOptions) []generator.FamilyGenerator {
+	terminalInfo := createPodTerminalInfoFamilyGenerator()
+	terminalInfo.OptIn = !opts.splitTerminalPods
+	families := []generator.FamilyGenerator{
 		createPodCompletionTimeFamilyGenerator(),
-		createPodContainerInfoFamilyGenerator(),
-		createPodContainerResourceLimitsFamilyGenerator(),
-		createPodContainerResourceRequestsFamilyGenerator(),
-		createPodContainerStateStartedFamilyGenerator(),
-		createPodContainerStatusLastTerminatedReasonFamilyGenerator(),
-		createPodContainerStatusLastTerminatedExitCodeFamilyGenerator(),
-		createPodContainerStatusReadyFamilyGenerator(),
-		createPodContainerStatusRestartsTotalFamilyGenerator(),
-		createPodContainerStatusRunningFamilyGenerator(),
-		createPodContainerStatusTerminatedFamilyGenerator(),
-		createPodContainerStatusTerminatedReasonFamilyGenerator(),
-		createPodContainerStatusWaitingFamilyGenerator(),
-		createPodContainerStatusWaitingReasonFamilyGenerator(),
 		createPodCreatedFamilyGenerator(),
 		createPodDeletionTimestampFamilyGenerator(),
-		createPodInfoFamilyGenerator(),
+		createPodInfoFamilyGenerator(opts.splitTerminalPods),
 		createPodIPFamilyGenerator(),
-		createPodInitContainerInfoFamilyGenerator(),
-		createPodInitContainerResourceLimitsFamilyGenerator(),
-		createPodInitContainerResourceRequestsFamilyGenerator(),
-		createPodInitContainerStatusLastTerminatedReasonFamilyGenerator(),
-		createPodInitContainerStatusReadyFamilyGenerator(),
-		createPodInitContainerStatusRestartsTotalFamilyGenerator(),
-		createPodInitContainerStatusRunningFamilyGenerator(),
-		createPodInitContainerStatusTerminatedFamilyGenerator(),
-		createPodInitContainerStatusTerminatedReasonFamilyGenerator(),
-		createPodInitContainerStatusWaitingFamilyGenerator(),
-		createPodInitContainerStatusWaitingReasonFamilyGenerator(),
 		createPodAnnotationsGenerator(allowAnnotationsList),
 		createPodLabelsGenerator(allowLabelsList),
+		createPodOverheadFamilyGenerator(),
 		createPodOverheadCPUCoresFamilyGenerator(),
 		createPodOverheadMemoryBytesFamilyGenerator(),
 		createPodOwnerFamilyGenerator(),
+		createPodPriorityClassCountFamilyGenerator(),
 		createPodRestartPolicyFamilyGenerator(),
+		createPodResourceFamilyGenerator("kube_pod_spec_resource_limits", "limit", func(r *v1.ResourceRequirements) v1.ResourceList { return r.Limits }),
+		createPodResourceFamilyGenerator("kube_pod_spec_resource_requests", "request", func(r *v1.ResourceRequirements) v1.ResourceList { return r.Requests }),
 		createPodRuntimeClassNameInfoFamilyGenerator(),
 		createPodSpecVolumesPersistentVolumeClaimsInfoFamilyGenerator(),
 		createPodSpecVolumesPersistentVolumeClaimsReadonlyFamilyGenerator(),
 		createPodStartTimeFamilyGenerator(),
 		createPodStatusPhaseFamilyGenerator(),
-		createPodStatusQosClassFamilyGenerator(),
-		createPodStatusReadyFamilyGenerator(),
-		createPodStatusReadyTimeFamilyGenerator(),
-		createPodStatusInitializedTimeFamilyGenerator(),
-		createPodStatusContainerReadyTimeFamilyGenerator(),
-		createPodStatusReasonFamilyGenerator(),
-		createPodStatusScheduledFamilyGenerator(),
-		createPodStatusScheduledTimeFamilyGenerator(),
-		createPodStatusUnschedulableFamilyGenerator(),
-		createPodTolerationsFamilyGenerator(),
-		createPodNodeSelectorsFamilyGenerator(),
-		createPodServiceAccountFamilyGenerator(),
-		createPodSchedulerNameFamilyGenerator(),
-	}
-}
-
-func createPodCompletionTimeFamilyGenerator() generator.FamilyGenerator {
-	return *generator.NewFamilyGeneratorWithStability(
-		"kube_pod_completion_time",
-		"Completion time in unix timestamp for a pod.",
-		metric.Gauge,
-		basemetrics.STABLE,
-		"",
-		wrapPodFunc(func(p *v1.Pod) *metric.Family {
-			ms := []*metric.Metric{}
-
-			var lastFinishTime float64
-			for _, cs := range p.Status.ContainerStatuses {
-				if cs.State.Terminated != nil {
-					if lastFinishTime == 0 || lastFinishTime < float64(cs.State.Terminated.FinishedAt.Unix()) {
-						lastFinishTime = float64(cs.State.Terminated.FinishedAt.Unix())
-					}
-				}
-			}
-
-			if lastFinishTime > 0 {
-				ms = append(ms, &metric.Metric{
-
-					LabelKeys:   []string{},
-					LabelValues: []string{},
-					Value:       lastFinishTime,
-				})
-			}
-
-			return &metric.Family{
-				Metrics: ms,
-			}
-		}),
-	)
-}
-
-func createPodContainerInfoFamilyGenerator() generator.FamilyGenerator {
-	return *generator.NewFamilyGeneratorWithStability(
-		"kube_pod_container_info",
-		"Information about a container in a pod.",
-		metric.Gauge,
-		basemetrics.STABLE,
-		"",
-		wrapPodFunc(func(p *v1.Pod) *metric.Family {
-			ms := []*metric.Metric{}
-			labelKeys := []string{"container", "image_spec", "image", "image_id", "container_id"}
-
-			for _, c := range p.Spec.Containers {
-				for _, cs := range p.Status.ContainerStatuses {
-					if cs.Name != c.Name {
-						continue
-					}
-					ms = append(ms, &metric.Metric{
-						LabelKeys:   labelKeys,
-						LabelValues: []string{cs.Name, c.Image, cs.Image, cs.ImageID, cs.ContainerID},
-						Value:       1,
-					})
-				}
-			}
-			return &metric.Family{
-				Metrics: ms,
-			}
-		}),
-	)
-}
-
-func createPodContainerResourceLimitsFamilyGenerator() generator.FamilyGenerator {
-	return *generator.NewFamilyGeneratorWithStability(
-		"kube_pod_container_resource_limits",
-		"The number of requested limit resource by a container. It is recommended to use the kube_pod_resource_limits metric exposed by kube-scheduler instead, as it is more precise.",
-		metric.Gauge,
-		basemetrics.ALPHA,
-		"",
-		wrapPodFunc(func(p *v1.Pod) *metric.Family {
-			ms := []*metric.Metric{}
-
-			for _, c := range p.Spec.Containers {
-				lim := c.Resources.Limits
-
-				for resourceName, val := range lim {
-					switch resourceName {
-					case v1.ResourceCPU:
-						ms = append(ms, &metric.Metric{
-							LabelValues: []string{c.Name, p.Spec.NodeName, SanitizeLabelName(string(resourceName)), string(constant.UnitCore)},
-							Value:       float64(val.MilliValue()) / 1000,
-						})
-					case v1.ResourceStorage:
-						fallthrough
-					case v1.ResourceEphemeralStorage:
-						fallthrough
-					case v1.ResourceMemory:
-						ms = append(ms, &metric.Metric{
-							LabelValues: []string{c.Name, p.Spec.NodeName, SanitizeLabelName(string(resourceName)), string(constant.UnitByte)},
-							Value:       float64(val.Value()),
-						})
-					default:
-						if isHugePageResourceName(resourceName) {
-							ms = append(ms, &metric.Metric{
-								LabelValues: []string{c.Name, p.Spec.NodeName, SanitizeLabelName(string(resourceName)), string(constant.UnitByte)},
-								Value:       float64(val.Value()),
-							})
-						}
-						if isAttachableVolumeResourceName(resourceName) {
-							ms = append(ms, &metric.Metric{
-								Value:       float64(val.Value()),
-								LabelValues: []string{c.Name, p.Spec.NodeName, SanitizeLabelName(string(resourceName)), string(constant.UnitByte)},
-							})
-						}
-						if isExtendedResourceName(resourceName) {
-							ms = append(ms, &metric.Metric{
-								Value:       float64(val.Value()),
-								LabelValues: []string{c.Name, p.Spec.NodeName, SanitizeLabelName(string(resourceName)), string(constant.UnitInteger)},
-							})
-
-						}
-					}
-				}
-			}
-
-			for _, metric := range ms {
-				metric.LabelKeys = []string{"container", "node", "resource", "unit"}
-			}
-
-			return &metric.Family{
-				Metrics: ms,
-			}
-		}),
-	)
-}
-
-func createPodContainerResourceRequestsFamilyGenerator() generator.FamilyGenerator {
-	return *generator.NewFamilyGeneratorWithStability(
-		"kube_pod_container_resource_requests",
-		"The number of requested request resource by a container. It is recommended to use the kube_pod_resource_requests metric exposed by kube-scheduler instead, as it is more precise.",
-		metric.Gauge,
-		basemetrics.ALPHA,
-		"",
-		wrapPodFunc(func(p *v1.Pod) *metric.Family {
-			ms := []*metric.Metric{}
-
-			for _, c := range p.Spec.Containers {
-				req := c.Resources.Requests
-
-				for resourceName, val := range req {
-					switch resourceName {
-					case v1.ResourceCPU:
-						ms = append(ms, &metric.Metric{
-							LabelValues: []string{c.Name, p.Spec.NodeName, SanitizeLabelName(string(resourceName)), string(constant.UnitCore)},
-							Value:       float64(val.MilliValue()) / 1000,
-						})
-					case v1.ResourceStorage:
-						fallthrough
-					case v1.ResourceEphemeralStorage:
-						fallthrough
-					case v1.ResourceMemory:
-						ms = append(ms, &metric.Metric{
-							LabelValues: []string{c.Name, p.Spec.NodeName, SanitizeLabelName(string(resourceName)), string(constant.UnitByte)},
-							Value:       float64(val.Value()),
-						})
-					default:
-						if isHugePageResourceName(resourceName) {
-							ms = append(ms, &metric.Metric{
-								LabelValues: []string{c.Name, p.Spec.NodeName, SanitizeLabelName(string(resourceName)), string(constant.UnitByte)},
-								Value:       float64(val.Value()),
-							})
-						}
-						if isAttachableVolumeResourceName(resourceName) {
-							ms = append(ms, &metric.Metric{
-								LabelValues: []string{c.Name, p.Spec.NodeName, SanitizeLabelName(string(resourceName)), string(constant.UnitByte)},
-								Value:       float64(val.Value()),
-							})
-						}
-						if isExtendedResourceName(resourceName) {
-							ms = append(ms, &metric.Metric{
-								LabelValues: []string{c.Name, p.Spec.NodeName, SanitizeLabelName(string(resourceName)), string(constant.UnitInteger)},
-								Value:       float64(val.Value()),
-							})
-						}
-					}
-				}
-			}
-
-			for _, metric := range ms {
-				metric.LabelKeys = []string{"container", "node", "resource", "unit"}
-			}
-
-			return &metric.Family{
-				Metrics: ms,
-			}
-		}),
-	)
-}
-
-func createPodContainerStateStartedFamilyGenerator() generator.FamilyGenerator {
-	return *generator.NewFamilyGeneratorWithStability(
-		"kube_pod_container_state_started",
-		"Start time in unix timestamp for a pod container.",
-		metric.Gauge,
-		basemetrics.STABLE,
-		"",
-		wrapPodFunc(func(p *v1.Pod) *metric.Family {
-			ms := []*metric.Metric{}
-
-			for _, cs := range p.Status.ContainerStatuses {
-				if cs.State.Running != nil {
-					ms = append(ms, &metric.Metric{
-						LabelKeys:   []string{"container"},
-						LabelValues: []string{cs.Name},
-						Value:       float64((cs.State.Running.StartedAt).Unix()),
-					})
-				} else if cs.State.Terminated != nil {
-					ms = append(ms, &metric.Metric{
-						LabelKeys:   []string{"container"},
-						LabelValues: []string{cs.Name},
-						Value:       float64((cs.State.Terminated.StartedAt).Unix()),
-					})
-				}
-			}
-
-			return &metric.Family{
-				Metrics: ms,
-			}
-		}),
-	)
-}
-
-func createPodContainerStatusLastTerminatedReasonFamilyGenerator() generator.FamilyGenerator {
-	return *generator.NewFamilyGeneratorWithStability(
-		"kube_pod_container_status_last_terminated_reason",
-		"Describes the last reason the container was in terminated state.",
-		metric.Gauge,
-		basemetrics.ALPHA,
-		"",
-		wrapPodFunc(func(p *v1.Pod) *metric.Family {
-			ms := make([]*metric.Metric, 0, len(p.Status.ContainerStatuses))
-			for _, cs := range p.Status.ContainerStatuses {
-				if cs.LastTerminationState.Terminated != nil {
-					ms = append(ms, &metric.Metric{
-						LabelKeys:   []string{"container", "reason"},
-						LabelValues: []string{cs.Name, cs.LastTerminationState.Terminated.Reason},
-						Value:       1,
-					})
-				}
-			}
-
-			return &metric.Family{
-				Metrics: ms,
-			}
-		}),
-	)
-}
-
-func createPodContainerStatusLastTerminatedExitCodeFamilyGenerator() generator.FamilyGenerator {
-	return *generator.NewFamilyGeneratorWithStability(
-		"kube_pod_container_status_last_terminated_exitcode",
-		"Describes the exit code for the last container in terminated state.",
-		metric.Gauge,
-		basemetrics.ALPHA,
-		"",
-		wrapPodFunc(func(p *v1.Pod) *metric.Family {
-			ms := make([]*metric.Metric, 0, len(p.Status.ContainerStatuses))
-			for _, cs := range p.Status.ContainerStatuses {
-				if cs.LastTerminationState.Terminated != nil {
-					ms = append(ms, &metric.Metric{
-						LabelKeys:   []string{"container"},
-						LabelValues: []string{cs.Name},
-						Value:       float64(cs.LastTerminationState.Terminated.ExitCode),
-					})
-				}
-			}
-
-			return &metric.Family{
-				Metrics: ms,
-			}
-		}),
-	)
-}
-
-func createPodContainerStatusReadyFamilyGenerator() generator.FamilyGenerator {
-	return *generator.NewFamilyGeneratorWithStability(
-		"kube_pod_container_status_ready",
-		"Describes whether the containers readiness check succeeded.",
-		metric.Gauge,
-		basemetrics.STABLE,
-		"",
-		wrapPodFunc(func(p *v1.Pod) *metric.Family {
-			ms := make([]*metric.Metric, len(p.Status.ContainerStatuses))
-			for i, cs := range p.Status.ContainerStatuses {
-				ms[i] = &metric.Metric{
-					LabelKeys:   []string{"container"},
-					LabelValues: []string{cs.Name},
-					Value:       boolFloat64(cs.Ready),
-				}
-			}
-
-			return &metric.Family{
-				Metrics: ms,
-			}
-		}),
-	)
-}
-
-func createPodContainerStatusRestartsTotalFamilyGenerator() generator.FamilyGenerator {
-	return *generator.NewFamilyGeneratorWithStability(
-		"kube_pod_container_status_restarts_total",
-		"The number of container restarts per container.",
-		metric.Counter, basemetrics.STABLE,
-		"",
-		wrapPodFunc(func(p *v1.Pod) *metric.Family {
-			ms := make([]*metric.Metric, len(p.Status.ContainerStatuses))
-
-			for i, cs := range p.Status.ContainerStatuses {
-				ms[i] = &metric.Metric{
-					LabelKeys:   []string{"container"},
-					LabelValues: []string{cs.Name},
-					Value:       float64(cs.RestartCount),
-				}
-			}
-
-			return &metric.Family{
-				Metrics: ms,
-			}
-		}),
-	)
-}
-
-func createPodContainerStatusRunningFamilyGenerator() generator.FamilyGenerator {
-	return *generator.NewFamilyGeneratorWithStability(
-		"kube_pod_container_status_running",
-		"Describes whether the container is currently in running state.",
-		metric.Gauge,
-		basemetrics.STABLE,
-		"",
-		wrapPodFunc(func(p *v1.Pod) *metric.Family {
-			ms := make([]*metric.Metric, len(p.Status.ContainerStatuses))
-
-			for i, cs := range p.Status.ContainerStatuses {
-				ms[i] = &metric.Metric{
-					LabelKeys:   []string{"container"},
-					LabelValues: []string{cs.Name},
-					Value:       boolFloat64(cs.State.Running != nil),
-				}
-			}
-
-			return &metric.Family{
-				Metrics: ms,
-			}
-		}),
-	)
-}
-
-func createPodContainerStatusTerminatedFamilyGenerator() generator.FamilyGenerator {
-	return *generator.NewFamilyGeneratorWithStability(
-		"kube_pod_container_status_terminated",
-		"Describes whether the container is currently in terminated state.",
-		metric.Gauge,
-		basemetrics.STABLE,
-		"",
-		wrapPodFunc(func(p *v1.Pod) *metric.Family {
-			ms := make([]*metric.Metric, len(p.Status.ContainerStatuses))
-
-			for i, cs := range p.Status.ContainerStatuses {
-				ms[i] = &metric.Metric{
-					LabelKeys:   []string{"container"},
-					LabelValues: []string{cs.Name},
-					Value:       boolFloat64(cs.State.Terminated != nil),
-				}
-			}
-
-			return &metric.Family{
-				Metrics: ms,
-			}
-		}),
-	)
-}
-
-func createPodContainerStatusTerminatedReasonFamilyGenerator() generator.FamilyGenerator {
-	return *generator.NewFamilyGeneratorWithStability(
-		"kube_pod_container_status_terminated_reason",
-		"Describes the reason the container is currently in terminated state.",
-		metric.Gauge,
-		basemetrics.ALPHA,
-		"",
-		wrapPodFunc(func(p *v1.Pod) *metric.Family {
-			ms := make([]*metric.Metric, 0, len(p.Status.ContainerStatuses))
-			for _, cs := range p.Status.ContainerStatuses {
-				if cs.State.Terminated != nil {
-					ms = append(ms, &metric.Metric{
-						LabelKeys:   []string{"container", "reason"},
-						LabelValues: []string{cs.Name, cs.State.Terminated.Reason},
-						Value:       1,
-					})
-				}
-			}
-
-			return &metric.Family{
-				Metrics: ms,
-			}
-		}),
-	)
-}
-
-func createPodContainerStatusWaitingFamilyGenerator() generator.FamilyGenerator {
-	return *generator.NewFamilyGeneratorWithStability(
-		"kube_pod_container_status_waiting",
-		"Describes whether the container is currently in waiting state.",
-		metric.Gauge,
-		basemetrics.STABLE,
-		"",
-		wrapPodFunc(func(p *v1.Pod) *metric.Family {
-			ms := make([]*metric.Metric, len(p.Status.ContainerStatuses))
-
-			for i, cs := range p.Status.ContainerStatuses {
-				ms[i] = &metric.Metric{
-					LabelKeys:   []string{"container"},
-					LabelValues: []string{cs.Name},
-					Value:       boolFloat64(cs.State.Waiting != nil),
-				}
-			}
-
-			return &metric.Family{
-				Metrics: ms,
-			}
-		}),
-	)
-}
-
-func createPodContainerStatusWaitingReasonFamilyGenerator() generator.FamilyGenerator {
-	return *generator.NewFamilyGeneratorWithStability(
-		"kube_pod_container_status_waiting_reason",
-		"Describes the reason the container is currently in waiting state.",
-		metric.Gauge,
-		basemetrics.STABLE,
-		"",
-		wrapPodFunc(func(p *v1.Pod) *metric.Family {
-			ms := make([]*metric.Metric, 0, len(p.Status.ContainerStatuses))
-			for _, cs := range p.Status.ContainerStatuses {
-				// Skip creating series for running containers.
-				if cs.State.Waiting != nil {
-					ms = append(ms, &metric.Metric{
-						LabelKeys:   []string{"container", "reason"},
-						LabelValues: []string{cs.Name, cs.State.Waiting.Reason},
-						Value:       1,
-					})
-				}
-			}
-			return &metric.Family{
-				Metrics: ms,
-			}
-		}),
-	)
-}
-
-func createPodCreatedFamilyGenerator() generator.FamilyGenerator {
-	return *generator.NewFamilyGeneratorWithStability(
-		"kube_pod_created",
-		"Unix creation timestamp",
-		metric.Gauge,
-		basemetrics.STABLE,
-		"",
-		wrapPodFunc(func(p *v1.Pod) *metric.Family {
-			ms := []*metric.Metric{}
-
-			if !p.CreationTimestamp.IsZero() {
-				ms = append(ms, &metric.Metric{
-					LabelKeys:   []string{},
-					LabelValues: []string{},
-					Value:       float64(p.CreationTimestamp.Unix()),
-				})
-			}
-
-			return &metric.Family{
-				Metrics: ms,
-			}
-		}),
-	)
-}
-
-func createPodDeletionTimestampFamilyGenerator() generator.FamilyGenerator {
-	return *generator.NewFamilyGeneratorWithStability(
-		"kube_pod_deletion_timestamp",
-		"Unix deletion timestamp",
-		metric.Gauge,
-		basemetrics.ALPHA,
-		"",
-		wrapPodFunc(func(p *v1.Pod) *metric.Family {
-			ms := []*metric.Metric{}
-
-			if p.DeletionTimestamp != nil && !p.DeletionTimestamp.IsZero() {
-				ms = append(ms, &metric.Metric{
-					LabelKeys:   []string{},
-					LabelValues: []string{},
-					Value:       float64(p.DeletionTimestamp.Unix()),
-				})
-			}
-
-			return &metric.Family{
-				Metrics: ms,
-			}
-		}),
-	)
-}
-
-func createPodInfoFamilyGenerator() generator.FamilyGenerator {
-	return *generator.NewFamilyGeneratorWithStability(
-		"kube_pod_info",
-		"Information about pod.",
-		metric.Gauge,
-		basemetrics.STABLE,
-		"",
-		wrapPodFunc(func(p *v1.Pod) *metric.Family {
-			createdBy := metav1.GetControllerOf(p)
-			createdByKind := ""
-			createdByName := ""
-			if createdBy != nil {
-				if createdBy.Kind != "" {
-					createdByKind = createdBy.Kind
-				}
-				if createdBy.Name != "" {
-					createdByName = createdBy.Name
-				}
-			}
-
-			m := metric.Metric{
-				LabelKeys:   []string{"host_ip", "pod_ip", "node", "created_by_kind", "created_by_name", "priority_class", "host_network"},
-				LabelValues: []string{p.Status.HostIP, p.Status.PodIP, p.Spec.NodeName, createdByKind, createdByName, p.Spec.PriorityClassName, strconv.FormatBool(p.Spec.HostNetwork)},
-				Value:       1,
-			}
-
-			return &metric.Family{
-				Metrics: []*metric.Metric{&m},
-			}
-		}),
-	)
-}
-
-func createPodIPFamilyGenerator() generator.FamilyGenerator {
-	return *generator.NewFamilyGeneratorWithStability(
-		"kube_pod_ips",
-		"Pod IP addresses",
-		metric.Gauge,
-		basemetrics.ALPHA,
-		"",
-		wrapPodFunc(func(p *v1.Pod) *metric.Family {
-			ms := make([]*metric.Metric, len(p.Status.PodIPs))
-			labelKeys := []string{"ip", "ip_family"}
-
-			for i, ip := range p.Status.PodIPs {
-				netIP := net.ParseIPSloppy(ip.IP)
-				var ipFamily net.IPFamily
-				switch {
-				case net.IsIPv4(netIP):
-					ipFamily = net.IPv4
-				case net.IsIPv6(netIP):
-					ipFamily = net.IPv6
-				default:
-					continue // nil from ParseIPSloppy indicates failure to parse, so we don't include that in our metrics series
-				}
-				ms[i] = &metric.Metric{
-					LabelKeys:   labelKeys,
-					LabelValues: []string{ip.IP, string(ipFamily)},
-					Value:       1,
-				}
-			}
-
-			return &metric.Family{
-				Metrics: ms,
-			}
-		}))
-}
-
-func createPodInitContainerInfoFamilyGenerator() generator.FamilyGenerator {
-	return *generator.NewFamilyGeneratorWithStability(
-		"kube_pod_init_container_info",
-		"Information about an init container in a pod.",
-		metric.Gauge,
-		basemetrics.STABLE,
-		"",
-		wrapPodFunc(func(p *v1.Pod) *metric.Family {
-			ms := []*metric.Metric{}
-			labelKeys := []string{"container", "image_spec", "image", "image_id", "container_id"}
-
-			for _, c := range p.Spec.InitContainers {
-				for _, cs := range p.Status.InitContainerStatuses {
-					if cs.Name != c.Name {
-						continue
-					}
-					ms = append(ms, &metric.Metric{
-						LabelKeys:   labelKeys,
-						LabelValues: []string{cs.Name, c.Image, cs.Image, cs.ImageID, cs.ContainerID},
-						Value:       1,
-					})
-				}
-			}
-
-			return &metric.Family{
-				Metrics: ms,
-			}
-		}),
-	)
-}
-
-func createPodInitContainerResourceLimitsFamilyGenerator() generator.FamilyGenerator {
-	return *generator.NewFamilyGeneratorWithStability(
-		"kube_pod_init_container_resource_limits",
-		"The number of requested limit resource by an init container.",
-		metric.Gauge,
-		basemetrics.ALPHA,
-		"",
-		wrapPodFunc(func(p *v1.Pod) *metric.Family {
-			ms := []*metric.Metric{}
-
-			for _, c := range p.Spec.InitContainers {
-				lim := c.Resources.Limits
-
-				for resourceName, val := range lim {
-					switch resourceName {
-					case v1.ResourceCPU:
-						ms = append(ms, &metric.Metric{
-							LabelValues: []string{c.Name, p.Spec.NodeName, SanitizeLabelName(string(resourceName)), string(constant.UnitCore)},
-							Value:       float64(val.MilliValue()) / 1000,
-						})
-					case v1.ResourceStorage:
-						fallthrough
-					case v1.ResourceEphemeralStorage:
-						fallthrough
-					case v1.ResourceMemory:
-						ms = append(ms, &metric.Metric{
-							LabelValues: []string{c.Name, p.Spec.NodeName, SanitizeLabelName(string(resourceName)), string(constant.UnitByte)},
-							Value:       float64(val.Value()),
-						})
-					default:
-						if isHugePageResourceName(resourceName) {
-							ms = append(ms, &metric.Metric{
-								LabelValues: []string{c.Name, p.Spec.NodeName, SanitizeLabelName(string(resourceName)), string(constant.UnitByte)},
-								Value:       float64(val.Value()),
-							})
-						}
-						if isAttachableVolumeResourceName(resourceName) {
-							ms = append(ms, &metric.Metric{
-								Value:       float64(val.Value()),
-								LabelValues: []string{c.Name, p.Spec.NodeName, SanitizeLabelName(string(resourceName)), string(constant.UnitByte)},
-							})
-						}
-						if isExtendedResourceName(resourceName) {
-							ms = append(ms, &metric.Metric{
-								Value:       float64(val.Value()),
-								LabelValues: []string{c.Name, p.Spec.NodeName, SanitizeLabelName(string(resourceName)), string(constant.UnitInteger)},
-							})
-
-						}
-					}
-				}
-			}
-
-			for _, metric := range ms {
-				metric.LabelKeys = []string{"container", "node", "resource", "unit"}
-			}
-
-			return &metric.Family{
-				Metrics: ms,
-			}
-		}),
-	)
+		createPodStatusQosClassFamilyGenerator(),
+		createPodStatusConditionFamilyGenerator(),
+		createPodStatusConditionLastTransitionTimeFamilyGenerator(),
+		createPodStatusReadyFamilyGenerator(),
+		createPodStatusReadyTimeFamilyGenerator(),
+		createPodStatusInitializedTimeFamilyGenerator(),
+		createPodStatusContainerReadyTimeFamilyGenerator(),
+		createPodStatusReasonFamilyGenerator(opts.statusReasons),
+		createPodStatusEvictionCategoryFamilyGenerator(),
+		createPodStatusScheduledFamilyGenerator(),
+		createPodStatusScheduledTimeFamilyGenerator(),
+		createPodStatusUnschedulableFamilyGenerator(),
+		terminalInfo,
+		createPodTolerationsFamilyGenerator(),
+		createPodNodeSelectorsFamilyGenerator(),
+		createPodServiceAccountFamilyGenerator(),
+		createPodSchedulerNameFamilyGenerator(),
+	}
+	// The distinct reasons of the last terminated containers are limited
+	// across all kinds of containers.
+	reasons := newValueLimiter(opts.lastTerminatedReasonLimit)
+	for _, k := range podContainerKinds {
+		families = append(families, podContainerFamilies(k, reasons)...)
+	}
+	return families
 }
 
-func createPodInitContainerResourceRequestsFamilyGenerator() generator.FamilyGenerator {
+func createPodCompletionTimeFamilyGenerator() generator.FamilyGenerator {
 	return *generator.NewFamilyGeneratorWithStability(
-		"kube_pod_init_container_resource_requests",
-		"The number of requested request resource by an init container.",
+		"kube_pod_completion_time",
+		"Completion time in unix timestamp for a pod.",
 		metric.Gauge,
-		basemetrics.ALPHA,
+		basemetrics.STABLE,
 		"",
 		wrapPodFunc(func(p *v1.Pod) *metric.Family {
 			ms := []*metric.Metric{}
 
-			for _, c := range p.Spec.InitContainers {
-				req := c.Resources.Requests
-
-				for resourceName, val := range req {
-					switch resourceName {
-					case v1.ResourceCPU:
-						ms = append(ms, &metric.Metric{
-							LabelValues: []string{c.Name, p.Spec.NodeName, SanitizeLabelName(string(resourceName)), string(constant.UnitCore)},
-							Value:       float64(val.MilliValue()) / 1000,
-						})
-					case v1.ResourceStorage:
-						fallthrough
-					case v1.ResourceEphemeralStorage:
-						fallthrough
-					case v1.ResourceMemory:
-						ms = append(ms, &metric.Metric{
-							LabelValues: []string{c.Name, p.Spec.NodeName, SanitizeLabelName(string(resourceName)), string(constant.UnitByte)},
-							Value:       float64(val.Value()),
-						})
-					default:
-						if isHugePageResourceName(resourceName) {
-							ms = append(ms, &metric.Metric{
-								LabelValues: []string{c.Name, p.Spec.NodeName, SanitizeLabelName(string(resourceName)), string(constant.UnitByte)},
-								Value:       float64(val.Value()),
-							})
-						}
-						if isAttachableVolumeResourceName(resourceName) {
-							ms = append(ms, &metric.Metric{
-								LabelValues: []string{c.Name, p.Spec.NodeName, SanitizeLabelName(string(resourceName)), string(constant.UnitByte)},
-								Value:       float64(val.Value()),
-							})
-						}
-						if isExtendedResourceName(resourceName) {
-							ms = append(ms, &metric.Metric{
-								LabelValues: []string{c.Name, p.Spec.NodeName, SanitizeLabelName(string(resourceName)), string(constant.UnitInteger)},
-								Value:       float64(val.Value()),
-							})
-						}
+			var lastFinishTime time.Time
+			for _, cs := range p.Status.ContainerStatuses {
+				if cs.State.Terminated != nil {
+					if lastFinishTime.IsZero() || lastFinishTime.Before(cs.State.Terminated.FinishedAt.Time) {
+						lastFinishTime = cs.State.Terminated.FinishedAt.Time
 					}
 				}
 			}
 
-			for _, metric := range ms {
-				metric.LabelKeys = []string{"container", "node", "resource", "unit"}
-			}
-
-			return &metric.Family{
-				Metrics: ms,
+			if lastFinishTime.Unix() > 0 {
+				ms = append(ms, metric.TimestampMetric([]string{}, []string{}, lastFinishTime))
 			}
-		}),
-	)
-}
 
-func createPodInitContainerStatusLastTerminatedReasonFamilyGenerator() generator.FamilyGenerator {
-	return *generator.NewFamilyGeneratorWithStability(
-		"kube_pod_init_container_status_last_terminated_reason",
-		"Describes the last reason the init container was in terminated state.",
-		metric.Gauge,
-		basemetrics.ALPHA,
-		"",
-		wrapPodFunc(func(p *v1.Pod) *metric.Family {
-			ms := make([]*metric.Metric, 0, len(p.Status.InitContainerStatuses))
-			for _, cs := range p.Status.InitContainerStatuses {
-				if cs.LastTerminationState.Terminated != nil {
-					ms = append(ms, &metric.Metric{
-						LabelKeys:   []string{"container", "reason"},
-						LabelValues: []string{cs.Name, cs.LastTerminationState.Terminated.Reason},
-						Value:       1,
-					})
-				}
-			}
 			return &metric.Family{
 				Metrics: ms,
 			}
@@ -851,46 +148,18 @@ func createPodInitContainerStatusLastTerminatedReasonFamilyGenerator() generator
 	)
 }
 
-func createPodInitContainerStatusReadyFamilyGenerator() generator.FamilyGenerator {
+func createPodCreatedFamilyGenerator() generator.FamilyGenerator {
 	return *generator.NewFamilyGeneratorWithStability(
-		"kube_pod_init_container_status_ready",
-		"Describes whether the init containers readiness check succeeded.",
+		"kube_pod_created",
+		"Unix creation timestamp",
 		metric.Gauge,
 		basemetrics.STABLE,
 		"",
 		wrapPodFunc(func(p *v1.Pod) *metric.Family {
-			ms := make([]*metric.Metric, len(p.Status.InitContainerStatuses))
-
-			for i, cs := range p.Status.InitContainerStatuses {
-				ms[i] = &metric.Metric{
-					LabelKeys:   []string{"container"},
-					LabelValues: []string{cs.Name},
-					Value:       boolFloat64(cs.Ready),
-				}
-			}
-
-			return &metric.Family{
-				Metrics: ms,
-			}
-		}),
-	)
-}
-
-func createPodInitContainerStatusRestartsTotalFamilyGenerator() generator.FamilyGenerator {
-	return *generator.NewFamilyGeneratorWithStability(
-		"kube_pod_init_container_status_restarts_total",
-		"The number of restarts for the init container.",
-		metric.Counter, basemetrics.STABLE,
-		"",
-		wrapPodFunc(func(p *v1.Pod) *metric.Family {
-			ms := make([]*metric.Metric, len(p.Status.InitContainerStatuses))
+			ms := []*metric.Metric{}
 
-			for i, cs := range p.Status.InitContainerStatuses {
-				ms[i] = &metric.Metric{
-					LabelKeys:   []string{"container"},
-					LabelValues: []string{cs.Name},
-					Value:       float64(cs.RestartCount),
-				}
+			if !p.CreationTimestamp.IsZero() {
+				ms = append(ms, metric.TimestampMetric([]string{}, []string{}, p.CreationTimestamp.Time))
 			}
 
 			return &metric.Family{
@@ -900,22 +169,18 @@ func createPodInitContainerStatusRestartsTotalFamilyGenerator() generator.Family
 	)
 }
 
-func createPodInitContainerStatusRunningFamilyGenerator() generator.FamilyGenerator {
+func createPodDeletionTimestampFamilyGenerator() generator.FamilyGenerator {
 	return *generator.NewFamilyGeneratorWithStability(
-		"kube_pod_init_container_status_running",
-		"Describes whether the init container is currently in running state.",
+		"kube_pod_deletion_timestamp",
+		"Unix deletion timestamp",
 		metric.Gauge,
-		basemetrics.STABLE,
+		basemetrics.ALPHA,
 		"",
 		wrapPodFunc(func(p *v1.Pod) *metric.Family {
-			ms := make([]*metric.Metric, len(p.Status.InitContainerStatuses))
+			ms := []*metric.Metric{}
 
-			for i, cs := range p.Status.InitContainerStatuses {
-				ms[i] = &metric.Metric{
-					LabelKeys:   []string{"container"},
-					LabelValues: []string{cs.Name},
-					Value:       boolFloat64(cs.State.Running != nil),
-				}
+			if p.DeletionTimestamp != nil && !p.DeletionTimestamp.IsZero() {
+				ms = append(ms, metric.TimestampMetric([]string{}, []string{}, p.DeletionTimestamp.Time))
 			}
 
 			return &metric.Family{
@@ -925,111 +190,110 @@ func createPodInitContainerStatusRunningFamilyGenerator() generator.FamilyGenera
 	)
 }
 
-func createPodInitContainerStatusTerminatedFamilyGenerator() generator.FamilyGenerator {
+func createPodInfoFamilyGenerator(skipTerminalPods bool) generator.FamilyGenerator {
 	return *generator.NewFamilyGeneratorWithStability(
-		"kube_pod_init_container_status_terminated",
-		"Describes whether the init container is currently in terminated state.",
+		"kube_pod_info",
+		"Information about pod.",
 		metric.Gauge,
 		basemetrics.STABLE,
 		"",
 		wrapPodFunc(func(p *v1.Pod) *metric.Family {
-			ms := make([]*metric.Metric, len(p.Status.InitContainerStatuses))
+			if skipTerminalPods && isPodTerminal(p) {
+				return &metric.Family{}
+			}
+			createdByKind, createdByName := podCreatedBy(p)
 
-			for i, cs := range p.Status.InitContainerStatuses {
-				ms[i] = &metric.Metric{
-					LabelKeys:   []string{"container"},
-					LabelValues: []string{cs.Name},
-					Value:       boolFloat64(cs.State.Terminated != nil),
-				}
+			m := metric.Metric{
+				LabelKeys:   []string{"host_ip", "pod_ip", "node", "created_by_kind", "created_by_name", "priority_class", "host_network"},
+				LabelValues: []string{p.Status.HostIP, p.Status.PodIP, p.Spec.NodeName, createdByKind, createdByName, p.Spec.PriorityClassName, strconv.FormatBool(p.Spec.HostNetwork)},
+				Value:       1,
 			}
 
 			return &metric.Family{
-				Metrics: ms,
+				Metrics: []*metric.Metric{&m},
 			}
 		}),
 	)
 }
 
-func createPodInitContainerStatusTerminatedReasonFamilyGenerator() generator.FamilyGenerator {
-	return *generator.NewFamilyGeneratorWithStability(
-		"kube_pod_init_container_status_terminated_reason",
-		"Describes the reason the init container is currently in terminated state.",
+func createPodTerminalInfoFamilyGenerator() generator.FamilyGenerator {
+	return *generator.NewOptInFamilyGenerator(
+		"kube_pod_terminal_info",
+		"Information about Succeeded or Failed pod, with fewer labels than kube_pod_info.",
 		metric.Gauge,
 		basemetrics.ALPHA,
 		"",
 		wrapPodFunc(func(p *v1.Pod) *metric.Family {
-			ms := make([]*metric.Metric, 0, len(p.Status.InitContainerStatuses))
-			for _, cs := range p.Status.InitContainerStatuses {
-				if cs.State.Terminated != nil {
-					ms = append(ms, &metric.Metric{
-						LabelKeys:   []string{"container", "reason"},
-						LabelValues: []string{cs.Name, cs.State.Terminated.Reason},
-						Value:       1,
-					})
-				}
+			if !isPodTerminal(p) {
+				return &metric.Family{}
 			}
+			createdByKind, createdByName := podCreatedBy(p)
 
 			return &metric.Family{
-				Metrics: ms,
+				Metrics: []*metric.Metric{
+					{
+						LabelKeys:   []string{"phase", "node", "created_by_kind", "created_by_name"},
+						LabelValues: []string{string(p.Status.Phase), p.Spec.NodeName, createdByKind, createdByName},
+						Value:       1,
+					},
+				},
 			}
 		}),
 	)
 }
 
-func createPodInitContainerStatusWaitingFamilyGenerator() generator.FamilyGenerator {
-	return *generator.NewFamilyGeneratorWithStability(
-		"kube_pod_init_container_status_waiting",
-		"Describes whether the init container is currently in waiting state.",
-		metric.Gauge,
-		basemetrics.STABLE,
-		"",
-		wrapPodFunc(func(p *v1.Pod) *metric.Family {
-			ms := make([]*metric.Metric, len(p.Status.InitContainerStatuses))
-
-			for i, cs := range p.Status.InitContainerStatuses {
-				ms[i] = &metric.Metric{
-					LabelKeys:   []string{"container"},
-					LabelValues: []string{cs.Name},
-					Value:       boolFloat64(cs.State.Waiting != nil),
-				}
-			}
+// podCreatedBy returns the kind and name of the controller of the pod, if
+// any.
+func podCreatedBy(p *v1.Pod) (string, string) {
+	createdBy := metav1.GetControllerOf(p)
+	if createdBy == nil {
+		return "", ""
+	}
+	return createdBy.Kind, createdBy.Name
+}
 
-			return &metric.Family{
-				Metrics: ms,
-			}
-		}),
-	)
+// isPodTerminal returns whether the pod is Succeeded or Failed.
+func isPodTerminal(p *v1.Pod) bool {
+	return p.Status.Phase == v1.PodSucceeded || p.Status.Phase == v1.PodFailed
 }
 
-func createPodInitContainerStatusWaitingReasonFamilyGenerator() generator.FamilyGenerator {
+func createPodIPFamilyGenerator() generator.FamilyGenerator {
 	return *generator.NewFamilyGeneratorWithStability(
-		"kube_pod_init_container_status_waiting_reason",
-		"Describes the reason the init container is currently in waiting state.",
+		"kube_pod_ips",
+		"Pod IP addresses",
 		metric.Gauge,
 		basemetrics.ALPHA,
 		"",
 		wrapPodFunc(func(p *v1.Pod) *metric.Family {
-			ms := make([]*metric.Metric, 0, len(p.Status.InitContainerStatuses))
-			for _, cs := range p.Status.InitContainerStatuses {
-				// Skip creating series for running containers.
-				if cs.State.Waiting != nil {
-					ms = append(ms, &metric.Metric{
-						LabelKeys:   []string{"container", "reason"},
-						LabelValues: []string{cs.Name, cs.State.Waiting.Reason},
-						Value:       1,
-					})
+			ms := make([]*metric.Metric, len(p.Status.PodIPs))
+			labelKeys := []string{"ip", "ip_family"}
+
+			for i, ip := range p.Status.PodIPs {
+				netIP := net.ParseIPSloppy(ip.IP)
+				var ipFamily net.IPFamily
+				switch {
+				case net.IsIPv4(netIP):
+					ipFamily = net.IPv4
+				case net.IsIPv6(netIP):
+					ipFamily = net.IPv6
+				default:
+					continue // nil from ParseIPSloppy indicates failure to parse, so we don't include that in our metrics series
+				}
+				ms[i] = &metric.Metric{
+					LabelKeys:   labelKeys,
+					LabelValues: []string{ip.IP, string(ipFamily)},
+					Value:       1,
 				}
 			}
 
 			return &metric.Family{
 				Metrics: ms,
 			}
-		}),
-	)
+		}))
 }
 
 func createPodAnnotationsGenerator(allowAnnotations []string) generator.FamilyGenerator {
-	return *generator.NewFamilyGeneratorWithStability(
+	return *generator.NewLazyFamilyGenerator(
 		"kube_pod_annotations",
 		"Kubernetes annotations converted to Prometheus labels.",
 		metric.Gauge,
@@ -1053,7 +317,7 @@ func createPodAnnotationsGenerator(allowAnnotations []string) generator.FamilyGe
 }
 
 func createPodLabelsGenerator(allowLabelsList []string) generator.FamilyGenerator {
-	return *generator.NewFamilyGeneratorWithStability(
+	return *generator.NewLazyFamilyGenerator(
 		"kube_pod_labels",
 		"Kubernetes labels converted to Prometheus labels.",
 		metric.Gauge,
@@ -1076,6 +340,72 @@ func createPodLabelsGenerator(allowLabelsList []string) generator.FamilyGenerato
 	)
 }
 
+func createPodOverheadFamilyGenerator() generator.FamilyGenerator {
+	return *generator.NewOptInFamilyGenerator(
+		"kube_pod_overhead",
+		"The pod overhead associated with running a pod, in the base unit of the resource.",
+		metric.Gauge,
+		basemetrics.ALPHA,
+		"",
+		wrapPodFunc(func(p *v1.Pod) *metric.Family {
+			ms := []*metric.Metric{}
+
+			for resourceName, val := range p.Spec.Overhead {
+				unit, value, ok := canonicalResourceQuantity(resourceName, val)
+				if !ok {
+					continue
+				}
+				ms = append(ms, &metric.Metric{
+					LabelKeys:   []string{"resource", "unit"},
+					LabelValues: []string{SanitizeLabelName(string(resourceName)), string(unit)},
+					Value:       value,
+				})
+			}
+
+			return &metric.Family{
+				Metrics: ms,
+			}
+		}),
+	)
+}
+
+// createPodResourceFamilyGenerator returns the family of the given pod-level
+// resource list of the pod spec, in the base unit of the resource. Pods
+// without pod-level resources have no metrics.
+func createPodResourceFamilyGenerator(name, list string, resources func(*v1.ResourceRequirements) v1.ResourceList) generator.FamilyGenerator {
+	return *generator.NewFamilyGeneratorWithStability(
+		name,
+		"The pod-level "+list+" of a resource set in the pod spec, in the base unit of the resource.",
+		metric.Gauge,
+		basemetrics.ALPHA,
+		"",
+		wrapPodFunc(func(p *v1.Pod) *metric.Family {
+			ms := []*metric.Metric{}
+			if p.Spec.Resources == nil {
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}
+
+			for resourceName, val := range resources(p.Spec.Resources) {
+				unit, value, ok := canonicalResourceQuantity(resourceName, val)
+				if !ok {
+					continue
+				}
+				ms = append(ms, &metric.Metric{
+					LabelKeys:   []string{"node", "resource", "unit"},
+					LabelValues: []string{p.Spec.NodeName, SanitizeLabelName(string(resourceName)), string(unit)},
+					Value:       value,
+				})
+			}
+
+			return &metric.Family{
+				Metrics: ms,
+			}
+		}),
+	)
+}
+
 func createPodOverheadCPUCoresFamilyGenerator() generator.FamilyGenerator {
 	return *generator.NewFamilyGeneratorWithStability(
 		"kube_pod_overhead_cpu_cores",
@@ -1288,11 +618,7 @@ func createPodStartTimeFamilyGenerator() generator.FamilyGenerator {
 			ms := []*metric.Metric{}
 
 			if p.Status.StartTime != nil {
-				ms = append(ms, &metric.Metric{
-					LabelKeys:   []string{},
-					LabelValues: []string{},
-					Value:       float64((p.Status.StartTime).Unix()),
-				})
+				ms = append(ms, metric.TimestampMetric([]string{}, []string{}, p.Status.StartTime.Time))
 			}
 			return &metric.Family{
 				Metrics: ms,
@@ -1357,11 +683,7 @@ func createPodStatusInitializedTimeFamilyGenerator() generator.FamilyGenerator {
 
 			for _, c := range p.Status.Conditions {
 				if c.Type == v1.PodInitialized && c.Status == v1.ConditionTrue {
-					ms = append(ms, &metric.Metric{
-						LabelKeys:   []string{},
-						LabelValues: []string{},
-						Value:       float64((c.LastTransitionTime).Unix()),
-					})
+					ms = append(ms, metric.TimestampMetric([]string{}, []string{}, c.LastTransitionTime.Time))
 				}
 			}
 
@@ -1384,11 +706,7 @@ func createPodStatusContainerReadyTimeFamilyGenerator() generator.FamilyGenerato
 
 			for _, c := range p.Status.Conditions {
 				if c.Type == v1.ContainersReady && c.Status == v1.ConditionTrue {
-					ms = append(ms, &metric.Metric{
-						LabelKeys:   []string{},
-						LabelValues: []string{},
-						Value:       float64((c.LastTransitionTime).Unix()),
-					})
+					ms = append(ms, metric.TimestampMetric([]string{}, []string{}, c.LastTransitionTime.Time))
 				}
 			}
 
@@ -1412,11 +730,7 @@ func createPodStatusReadyTimeFamilyGenerator() generator.FamilyGenerator {
 
 			for _, c := range p.Status.Conditions {
 				if c.Type == v1.PodReady && c.Status == v1.ConditionTrue {
-					ms = append(ms, &metric.Metric{
-						LabelKeys:   []string{},
-						LabelValues: []string{},
-						Value:       float64((c.LastTransitionTime).Unix()),
-					})
+					ms = append(ms, metric.TimestampMetric([]string{}, []string{}, c.LastTransitionTime.Time))
 				}
 			}
 
@@ -1469,6 +783,46 @@ func createPodStatusQosClassFamilyGenerator() generator.FamilyGenerator {
 	)
 }
 
+// createPodStatusConditionFamilyGenerator serves all conditions of a pod,
+// including readiness gates of third party controllers. It is opt-in as pods
+// are numerous, and kube_pod_status_ready and kube_pod_status_scheduled serve
+// the most relevant conditions.
+func createPodStatusConditionFamilyGenerator() generator.FamilyGenerator {
+	return *generator.NewOptInFamilyGenerator(
+		"kube_pod_status_condition",
+		"The current status conditions of a pod.",
+		metric.Gauge,
+		basemetrics.ALPHA,
+		"",
+		wrapPodFunc(func(p *v1.Pod) *metric.Family {
+			return &metric.Family{
+				Metrics: statusConditionMetrics(p.Status.Conditions, func(c v1.PodCondition) (string, v1.ConditionStatus) {
+					return string(c.Type), c.Status
+				}),
+			}
+		}),
+	)
+}
+
+func createPodStatusConditionLastTransitionTimeFamilyGenerator() generator.FamilyGenerator {
+	return *generator.NewOptInFamilyGenerator(
+		"kube_pod_status_condition_last_transition_time",
+		"Unix timestamp of the last transition of the condition of a pod.",
+		metric.Gauge,
+		basemetrics.ALPHA,
+		"",
+		wrapPodFunc(func(p *v1.Pod) *metric.Family {
+			return &metric.Family{
+				Metrics: statusConditionLastTransitionMetrics(p.Status.Conditions, func(c v1.PodCondition) (string, v1.ConditionStatus) {
+					return string(c.Type), c.Status
+				}, func(c v1.PodCondition) time.Time {
+					return c.LastTransitionTime.Time
+				}),
+			}
+		}),
+	)
+}
+
 func createPodStatusReadyFamilyGenerator() generator.FamilyGenerator {
 	return *generator.NewFamilyGeneratorWithStability(
 		"kube_pod_status_ready",
@@ -1498,7 +852,10 @@ func createPodStatusReadyFamilyGenerator() generator.FamilyGenerator {
 	)
 }
 
-func createPodStatusReasonFamilyGenerator() generator.FamilyGenerator {
+func createPodStatusReasonFamilyGenerator(reasons []string) generator.FamilyGenerator {
+	if len(reasons) == 0 {
+		reasons = podStatusReasons
+	}
 	return *generator.NewFamilyGeneratorWithStability(
 		"kube_pod_status_reason",
 		"The pod status reasons",
@@ -1508,11 +865,12 @@ func createPodStatusReasonFamilyGenerator() generator.FamilyGenerator {
 		wrapPodFunc(func(p *v1.Pod) *metric.Family {
 			ms := []*metric.Metric{}
 
-			for _, reason := range podStatusReasons {
+			disruptionReason := podDisruptionReason(p)
+			for _, reason := range reasons {
 				metric := &metric.Metric{}
 				metric.LabelKeys = []string{"reason"}
 				metric.LabelValues = []string{reason}
-				if p.Status.Reason == reason {
+				if p.Status.Reason == reason || disruptionReason == reason {
 					metric.Value = boolFloat64(true)
 				} else {
 					metric.Value = boolFloat64(false)
@@ -1527,6 +885,60 @@ func createPodStatusReasonFamilyGenerator() generator.FamilyGenerator {
 	)
 }
 
+// podDisruptionReason returns the reason of the DisruptionTarget condition of
+// the pod, e.g. PreemptionByScheduler, if the pod is about to be terminated.
+func podDisruptionReason(p *v1.Pod) string {
+	for _, c := range p.Status.Conditions {
+		if c.Type == v1.DisruptionTarget && c.Status == v1.ConditionTrue {
+			return c.Reason
+		}
+	}
+	return ""
+}
+
+func createPodStatusEvictionCategoryFamilyGenerator() generator.FamilyGenerator {
+	return *generator.NewFamilyGeneratorWithStability(
+		"kube_pod_status_eviction_category",
+		"The category of the eviction of an evicted pod, i.e. the resource or node condition the kubelet evicted the pod for.",
+		metric.Gauge,
+		basemetrics.ALPHA,
+		"",
+		wrapPodFunc(func(p *v1.Pod) *metric.Family {
+			ms := []*metric.Metric{}
+
+			if p.Status.Reason == "Evicted" {
+				ms = append(ms, &metric.Metric{
+					LabelKeys:   []string{"category"},
+					LabelValues: []string{podEvictionCategory(p.Status.Message)},
+					Value:       1,
+				})
+			}
+
+			return &metric.Family{
+				Metrics: ms,
+			}
+		}),
+	)
+}
+
+// podEvictionCategory returns the resource or node condition of the eviction
+// message set by the kubelet, or "Other" if it is not known.
+func podEvictionCategory(message string) string {
+	switch {
+	case strings.HasPrefix(message, evictionNodeLowMessagePrefix):
+		resource, _, _ := strings.Cut(strings.TrimPrefix(message, evictionNodeLowMessagePrefix), ".")
+		return resource
+	case strings.HasPrefix(message, evictionNodeConditionMessagePrefix):
+		condition, _, _ := strings.Cut(strings.TrimPrefix(message, evictionNodeConditionMessagePrefix), ".")
+		return strings.Trim(condition, "[]")
+	case strings.Contains(message, "local ephemeral storage limit"),
+		strings.HasPrefix(message, "Pod ephemeral local storage usage exceeds"),
+		strings.HasPrefix(message, "Usage of EmptyDir volume"):
+		return string(v1.ResourceEphemeralStorage)
+	}
+	return "Other"
+}
+
 func createPodStatusScheduledFamilyGenerator() generator.FamilyGenerator {
 	return *generator.NewFamilyGeneratorWithStability(
 		"kube_pod_status_scheduled",
@@ -1568,11 +980,7 @@ func createPodStatusScheduledTimeFamilyGenerator() generator.FamilyGenerator {
 
 			for _, c := range p.Status.Conditions {
 				if c.Type == v1.PodScheduled && c.Status == v1.ConditionTrue {
-					ms = append(ms, &metric.Metric{
-						LabelKeys:   []string{},
-						LabelValues: []string{},
-						Value:       float64(c.LastTransitionTime.Unix()),
-					})
+					ms = append(ms, metric.TimestampMetric([]string{}, []string{}, c.LastTransitionTime.Time))
 				}
 			}
 
@@ -1732,7 +1140,11 @@ func createPodSchedulerNameFamilyGenerator() generator.FamilyGenerator {
 
 func wrapPodFunc(f func(*v1.Pod) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
-		pod := obj.(*v1.Pod)
+		pod, ok := obj.(*v1.Pod)
+		if !ok {
+			// The store of pods also holds their podPriorityClassCount.
+			return &metric.Family{}
+		}
 
 		metricFamily := f(pod)
 
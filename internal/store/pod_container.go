@@ -0,0 +1,621 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"fmt"
+	"strconv"
+
+	v1 "k8s.io/api/core/v1"
+	basemetrics "k8s.io/component-base/metrics"
+
+	"k8s.io/kube-state-metrics/v2/pkg/constant"
+	"k8s.io/kube-state-metrics/v2/pkg/metric"
+	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
+)
+
+// podContainerFamilyNames are the names of the container metric families of a
+// kind of containers. Families without a name are not generated for the kind.
+type podContainerFamilyNames struct {
+	info                   string
+	resourceLimits         string
+	resourceRequests       string
+	stateStarted           string
+	lastTerminatedReason   string
+	lastTerminatedExitCode string
+	lastTerminatedInfo     string
+	ready                  string
+	restartsTotal          string
+	running                string
+	terminated             string
+	terminatedReason       string
+	waiting                string
+	waitingReason          string
+	restartPolicy          string
+}
+
+// podContainerKind describes a kind of containers of a pod, for which the
+// same container metric families are generated.
+type podContainerKind struct {
+	// noun names a single container of the kind in help texts.
+	noun string
+	// article is the indefinite article of noun.
+	article string
+	// regular is set for the regular containers of the pod, whose families
+	// keep their historical help texts.
+	regular    bool
+	names      podContainerFamilyNames
+	containers func(p *v1.Pod) []v1.Container
+	statuses   func(p *v1.Pod) []v1.ContainerStatus
+}
+
+var (
+	podContainerKinds = []podContainerKind{
+		{
+			noun:    "container",
+			article: "a",
+			regular: true,
+			names: podContainerFamilyNames{
+				info:                   "kube_pod_container_info",
+				resourceLimits:         "kube_pod_container_resource_limits",
+				resourceRequests:       "kube_pod_container_resource_requests",
+				stateStarted:           "kube_pod_container_state_started",
+				lastTerminatedReason:   "kube_pod_container_status_last_terminated_reason",
+				lastTerminatedExitCode: "kube_pod_container_status_last_terminated_exitcode",
+				lastTerminatedInfo:     "kube_pod_container_status_last_terminated_info",
+				ready:                  "kube_pod_container_status_ready",
+				restartsTotal:          "kube_pod_container_status_restarts_total",
+				running:                "kube_pod_container_status_running",
+				terminated:             "kube_pod_container_status_terminated",
+				terminatedReason:       "kube_pod_container_status_terminated_reason",
+				waiting:                "kube_pod_container_status_waiting",
+				waitingReason:          "kube_pod_container_status_waiting_reason",
+			},
+			containers: func(p *v1.Pod) []v1.Container { return p.Spec.Containers },
+			statuses:   func(p *v1.Pod) []v1.ContainerStatus { return p.Status.ContainerStatuses },
+		},
+		{
+			noun:    "init container",
+			article: "an",
+			names: podContainerFamilyNames{
+				info:                   "kube_pod_init_container_info",
+				resourceLimits:         "kube_pod_init_container_resource_limits",
+				resourceRequests:       "kube_pod_init_container_resource_requests",
+				stateStarted:           "kube_pod_init_container_state_started",
+				lastTerminatedReason:   "kube_pod_init_container_status_last_terminated_reason",
+				lastTerminatedExitCode: "kube_pod_init_container_status_last_terminated_exitcode",
+				lastTerminatedInfo:     "kube_pod_init_container_status_last_terminated_info",
+				ready:                  "kube_pod_init_container_status_ready",
+				restartsTotal:          "kube_pod_init_container_status_restarts_total",
+				running:                "kube_pod_init_container_status_running",
+				terminated:             "kube_pod_init_container_status_terminated",
+				terminatedReason:       "kube_pod_init_container_status_terminated_reason",
+				waiting:                "kube_pod_init_container_status_waiting",
+				waitingReason:          "kube_pod_init_container_status_waiting_reason",
+				restartPolicy:          "kube_pod_init_container_restart_policy",
+			},
+			containers: func(p *v1.Pod) []v1.Container { return p.Spec.InitContainers },
+			statuses:   func(p *v1.Pod) []v1.ContainerStatus { return p.Status.InitContainerStatuses },
+		},
+		{
+			noun:    "ephemeral container",
+			article: "an",
+			// Ephemeral containers have neither resources nor readiness
+			// probes.
+			names: podContainerFamilyNames{
+				info:                   "kube_pod_ephemeral_container_info",
+				stateStarted:           "kube_pod_ephemeral_container_state_started",
+				lastTerminatedReason:   "kube_pod_ephemeral_container_status_last_terminated_reason",
+				lastTerminatedExitCode: "kube_pod_ephemeral_container_status_last_terminated_exitcode",
+				lastTerminatedInfo:     "kube_pod_ephemeral_container_status_last_terminated_info",
+				restartsTotal:          "kube_pod_ephemeral_container_status_restarts_total",
+				running:                "kube_pod_ephemeral_container_status_running",
+				terminated:             "kube_pod_ephemeral_container_status_terminated",
+				terminatedReason:       "kube_pod_ephemeral_container_status_terminated_reason",
+				waiting:                "kube_pod_ephemeral_container_status_waiting",
+				waitingReason:          "kube_pod_ephemeral_container_status_waiting_reason",
+			},
+			containers: podEphemeralContainers,
+			statuses:   func(p *v1.Pod) []v1.ContainerStatus { return p.Status.EphemeralContainerStatuses },
+		},
+	}
+
+	// stablePodContainerFamilies are the container metric families which
+	// are STABLE, all others are ALPHA.
+	stablePodContainerFamilies = map[string]struct{}{
+		"kube_pod_container_info":                       {},
+		"kube_pod_container_state_started":              {},
+		"kube_pod_container_status_ready":               {},
+		"kube_pod_container_status_restarts_total":      {},
+		"kube_pod_container_status_running":             {},
+		"kube_pod_container_status_terminated":          {},
+		"kube_pod_container_status_waiting":             {},
+		"kube_pod_container_status_waiting_reason":      {},
+		"kube_pod_init_container_info":                  {},
+		"kube_pod_init_container_status_ready":          {},
+		"kube_pod_init_container_status_restarts_total": {},
+		"kube_pod_init_container_status_running":        {},
+		"kube_pod_init_container_status_terminated":     {},
+		"kube_pod_init_container_status_waiting":        {},
+	}
+)
+
+// podEphemeralContainers returns the ephemeral containers of the pod as
+// containers.
+func podEphemeralContainers(p *v1.Pod) []v1.Container {
+	containers := make([]v1.Container, len(p.Spec.EphemeralContainers))
+	for i, c := range p.Spec.EphemeralContainers {
+		containers[i] = v1.Container(c.EphemeralContainerCommon)
+	}
+	return containers
+}
+
+func podContainerFamilyStability(name string) basemetrics.StabilityLevel {
+	if _, ok := stablePodContainerFamilies[name]; ok {
+		return basemetrics.STABLE
+	}
+	return basemetrics.ALPHA
+}
+
+// podContainerFamilies returns the container metric families of the kind of
+// containers.
+func podContainerFamilies(k podContainerKind, reasons *valueLimiter) []generator.FamilyGenerator {
+	families := []generator.FamilyGenerator{
+		createPodContainerInfoFamilyGenerator(k),
+		createPodContainerResourceLimitsFamilyGenerator(k),
+		createPodContainerResourceRequestsFamilyGenerator(k),
+		createPodContainerStateStartedFamilyGenerator(k),
+		createPodContainerStatusLastTerminatedReasonFamilyGenerator(k),
+		createPodContainerStatusLastTerminatedExitCodeFamilyGenerator(k),
+		createPodContainerStatusLastTerminatedInfoFamilyGenerator(k, reasons),
+		createPodContainerStatusReadyFamilyGenerator(k),
+		createPodContainerStatusRestartsTotalFamilyGenerator(k),
+		createPodContainerStatusRunningFamilyGenerator(k),
+		createPodContainerStatusTerminatedFamilyGenerator(k),
+		createPodContainerStatusTerminatedReasonFamilyGenerator(k),
+		createPodContainerStatusWaitingFamilyGenerator(k),
+		createPodContainerStatusWaitingReasonFamilyGenerator(k),
+		createPodContainerRestartPolicyFamilyGenerator(k),
+	}
+	result := families[:0]
+	for _, f := range families {
+		if f.Name != "" {
+			result = append(result, f)
+		}
+	}
+	return result
+}
+
+func createPodContainerInfoFamilyGenerator(k podContainerKind) generator.FamilyGenerator {
+	return *generator.NewFamilyGeneratorWithStability(
+		k.names.info,
+		fmt.Sprintf("Information about %s %s in a pod.", k.article, k.noun),
+		metric.Gauge,
+		podContainerFamilyStability(k.names.info),
+		"",
+		wrapPodFunc(func(p *v1.Pod) *metric.Family {
+			ms := []*metric.Metric{}
+			labelKeys := []string{"container", "image_spec", "image", "image_id", "container_id"}
+
+			for _, c := range k.containers(p) {
+				for _, cs := range k.statuses(p) {
+					if cs.Name != c.Name {
+						continue
+					}
+					ms = append(ms, &metric.Metric{
+						LabelKeys:   labelKeys,
+						LabelValues: []string{cs.Name, c.Image, cs.Image, cs.ImageID, cs.ContainerID},
+						Value:       1,
+					})
+				}
+			}
+			return &metric.Family{
+				Metrics: ms,
+			}
+		}),
+	)
+}
+
+// createPodContainerRestartPolicyFamilyGenerator returns the family which
+// describes the restart policy of the containers of the kind. It is only set
+// for restartable init containers, also known as native sidecars.
+func createPodContainerRestartPolicyFamilyGenerator(k podContainerKind) generator.FamilyGenerator {
+	return *generator.NewFamilyGeneratorWithStability(
+		k.names.restartPolicy,
+		fmt.Sprintf("Describes the restart policy of %s %s, which is only set for restartable %ss.", k.article, k.noun, k.noun),
+		metric.Gauge,
+		podContainerFamilyStability(k.names.restartPolicy),
+		"",
+		wrapPodFunc(func(p *v1.Pod) *metric.Family {
+			ms := []*metric.Metric{}
+			for _, c := range k.containers(p) {
+				if c.RestartPolicy == nil {
+					continue
+				}
+				ms = append(ms, &metric.Metric{
+					LabelKeys:   []string{"container", "type"},
+					LabelValues: []string{c.Name, string(*c.RestartPolicy)},
+					Value:       1,
+				})
+			}
+			return &metric.Family{
+				Metrics: ms,
+			}
+		}),
+	)
+}
+
+// resourceHelp returns the help text of the resource family of the kind of
+// containers for the resource list, either "limit" or "request".
+func (k podContainerKind) resourceHelp(list string) string {
+	help := fmt.Sprintf("The number of requested %s resource by %s %s.", list, k.article, k.noun)
+	if k.regular {
+		help += fmt.Sprintf(" It is recommended to use the kube_pod_resource_%ss metric exposed by kube-scheduler instead, as it is more precise.", list)
+	}
+	return help
+}
+
+func createPodContainerResourceLimitsFamilyGenerator(k podContainerKind) generator.FamilyGenerator {
+	return *generator.NewFamilyGeneratorWithStability(
+		k.names.resourceLimits,
+		k.resourceHelp("limit"),
+		metric.Gauge,
+		podContainerFamilyStability(k.names.resourceLimits),
+		"",
+		wrapPodFunc(func(p *v1.Pod) *metric.Family {
+			ms := []*metric.Metric{}
+			for _, c := range k.containers(p) {
+				ms = append(ms, containerResourceMetrics(p, c.Name, c.Resources.Limits)...)
+			}
+			return &metric.Family{
+				Metrics: ms,
+			}
+		}),
+	)
+}
+
+func createPodContainerResourceRequestsFamilyGenerator(k podContainerKind) generator.FamilyGenerator {
+	return *generator.NewFamilyGeneratorWithStability(
+		k.names.resourceRequests,
+		k.resourceHelp("request"),
+		metric.Gauge,
+		podContainerFamilyStability(k.names.resourceRequests),
+		"",
+		wrapPodFunc(func(p *v1.Pod) *metric.Family {
+			ms := []*metric.Metric{}
+			for _, c := range k.containers(p) {
+				ms = append(ms, containerResourceMetrics(p, c.Name, c.Resources.Requests)...)
+			}
+			return &metric.Family{
+				Metrics: ms,
+			}
+		}),
+	)
+}
+
+// containerResourceMetrics returns the metrics of the resources of the
+// container with the given name.
+func containerResourceMetrics(p *v1.Pod, container string, resources v1.ResourceList) []*metric.Metric {
+	ms := []*metric.Metric{}
+
+	for resourceName, val := range resources {
+		switch resourceName {
+		case v1.ResourceCPU:
+			ms = append(ms, &metric.Metric{
+				LabelValues: []string{container, p.Spec.NodeName, SanitizeLabelName(string(resourceName)), string(constant.UnitCore)},
+				Value:       float64(val.MilliValue()) / 1000,
+			})
+		case v1.ResourceStorage:
+			fallthrough
+		case v1.ResourceEphemeralStorage:
+			fallthrough
+		case v1.ResourceMemory:
+			ms = append(ms, &metric.Metric{
+				LabelValues: []string{container, p.Spec.NodeName, SanitizeLabelName(string(resourceName)), string(constant.UnitByte)},
+				Value:       float64(val.Value()),
+			})
+		default:
+			if isHugePageResourceName(resourceName) {
+				ms = append(ms, &metric.Metric{
+					LabelValues: []string{container, p.Spec.NodeName, SanitizeLabelName(string(resourceName)), string(constant.UnitByte)},
+					Value:       float64(val.Value()),
+				})
+			}
+			if isAttachableVolumeResourceName(resourceName) {
+				ms = append(ms, &metric.Metric{
+					LabelValues: []string{container, p.Spec.NodeName, SanitizeLabelName(string(resourceName)), string(constant.UnitByte)},
+					Value:       float64(val.Value()),
+				})
+			}
+			if isExtendedResourceName(resourceName) {
+				ms = append(ms, &metric.Metric{
+					LabelValues: []string{container, p.Spec.NodeName, SanitizeLabelName(string(resourceName)), string(constant.UnitInteger)},
+					Value:       float64(val.Value()),
+				})
+			}
+		}
+	}
+
+	for _, metric := range ms {
+		metric.LabelKeys = []string{"container", "node", "resource", "unit"}
+	}
+	return ms
+}
+
+func createPodContainerStateStartedFamilyGenerator(k podContainerKind) generator.FamilyGenerator {
+	return *generator.NewFamilyGeneratorWithStability(
+		k.names.stateStarted,
+		fmt.Sprintf("Start time in unix timestamp for a pod %s.", k.noun),
+		metric.Gauge,
+		podContainerFamilyStability(k.names.stateStarted),
+		"",
+		wrapPodFunc(func(p *v1.Pod) *metric.Family {
+			ms := []*metric.Metric{}
+
+			for _, cs := range k.statuses(p) {
+				if cs.State.Running != nil {
+					ms = append(ms, metric.TimestampMetric([]string{"container"}, []string{cs.Name}, cs.State.Running.StartedAt.Time))
+				} else if cs.State.Terminated != nil {
+					ms = append(ms, metric.TimestampMetric([]string{"container"}, []string{cs.Name}, cs.State.Terminated.StartedAt.Time))
+				}
+			}
+
+			return &metric.Family{
+				Metrics: ms,
+			}
+		}),
+	)
+}
+
+func createPodContainerStatusLastTerminatedReasonFamilyGenerator(k podContainerKind) generator.FamilyGenerator {
+	return *generator.NewFamilyGeneratorWithStability(
+		k.names.lastTerminatedReason,
+		fmt.Sprintf("Describes the last reason the %s was in terminated state.", k.noun),
+		metric.Gauge,
+		podContainerFamilyStability(k.names.lastTerminatedReason),
+		"",
+		wrapPodFunc(func(p *v1.Pod) *metric.Family {
+			statuses := k.statuses(p)
+			ms := make([]*metric.Metric, 0, len(statuses))
+			for _, cs := range statuses {
+				if cs.LastTerminationState.Terminated != nil {
+					ms = append(ms, &metric.Metric{
+						LabelKeys:   []string{"container", "reason"},
+						LabelValues: []string{cs.Name, cs.LastTerminationState.Terminated.Reason},
+						Value:       1,
+					})
+				}
+			}
+
+			return &metric.Family{
+				Metrics: ms,
+			}
+		}),
+	)
+}
+
+func createPodContainerStatusLastTerminatedExitCodeFamilyGenerator(k podContainerKind) generator.FamilyGenerator {
+	return *generator.NewFamilyGeneratorWithStability(
+		k.names.lastTerminatedExitCode,
+		fmt.Sprintf("Describes the exit code for the last %s in terminated state.", k.noun),
+		metric.Gauge,
+		podContainerFamilyStability(k.names.lastTerminatedExitCode),
+		"",
+		wrapPodFunc(func(p *v1.Pod) *metric.Family {
+			statuses := k.statuses(p)
+			ms := make([]*metric.Metric, 0, len(statuses))
+			for _, cs := range statuses {
+				if cs.LastTerminationState.Terminated != nil {
+					ms = append(ms, &metric.Metric{
+						LabelKeys:   []string{"container"},
+						LabelValues: []string{cs.Name},
+						Value:       float64(cs.LastTerminationState.Terminated.ExitCode),
+					})
+				}
+			}
+
+			return &metric.Family{
+				Metrics: ms,
+			}
+		}),
+	)
+}
+
+func createPodContainerStatusLastTerminatedInfoFamilyGenerator(k podContainerKind, reasons *valueLimiter) generator.FamilyGenerator {
+	return *generator.NewOptInFamilyGenerator(
+		k.names.lastTerminatedInfo,
+		fmt.Sprintf("Unix timestamp the %s last terminated at, with the reason and exit code of the termination.", k.noun),
+		metric.Gauge,
+		podContainerFamilyStability(k.names.lastTerminatedInfo),
+		"",
+		wrapPodFunc(func(p *v1.Pod) *metric.Family {
+			statuses := k.statuses(p)
+			ms := make([]*metric.Metric, 0, len(statuses))
+			for _, cs := range statuses {
+				terminated := cs.LastTerminationState.Terminated
+				if terminated == nil {
+					continue
+				}
+				labelKeys := []string{"container", "reason", "exit_code"}
+				labelValues := []string{cs.Name, reasons.limit(terminated.Reason), strconv.Itoa(int(terminated.ExitCode))}
+				if terminated.FinishedAt.IsZero() {
+					ms = append(ms, &metric.Metric{LabelKeys: labelKeys, LabelValues: labelValues})
+					continue
+				}
+				ms = append(ms, metric.TimestampMetric(labelKeys, labelValues, terminated.FinishedAt.Time))
+			}
+
+			return &metric.Family{
+				Metrics: ms,
+			}
+		}),
+	)
+}
+
+func createPodContainerStatusReadyFamilyGenerator(k podContainerKind) generator.FamilyGenerator {
+	return *generator.NewFamilyGeneratorWithStability(
+		k.names.ready,
+		fmt.Sprintf("Describes whether the %ss readiness check succeeded.", k.noun),
+		metric.Gauge,
+		podContainerFamilyStability(k.names.ready),
+		"",
+		wrapPodFunc(func(p *v1.Pod) *metric.Family {
+			statuses := k.statuses(p)
+			ms := make([]*metric.Metric, len(statuses))
+			for i, cs := range statuses {
+				ms[i] = &metric.Metric{
+					LabelKeys:   []string{"container"},
+					LabelValues: []string{cs.Name},
+					Value:       boolFloat64(cs.Ready),
+				}
+			}
+
+			return &metric.Family{
+				Metrics: ms,
+			}
+		}),
+	)
+}
+
+func createPodContainerStatusRestartsTotalFamilyGenerator(k podContainerKind) generator.FamilyGenerator {
+	help := fmt.Sprintf("The number of restarts for the %s.", k.noun)
+	if k.regular {
+		help = "The number of container restarts per container."
+	}
+	return *generator.NewFamilyGeneratorWithStability(
+		k.names.restartsTotal,
+		help,
+		metric.Counter, podContainerFamilyStability(k.names.restartsTotal),
+		"",
+		wrapPodFunc(func(p *v1.Pod) *metric.Family {
+			statuses := k.statuses(p)
+			ms := make([]*metric.Metric, len(statuses))
+
+			for i, cs := range statuses {
+				ms[i] = &metric.Metric{
+					LabelKeys:   []string{"container"},
+					LabelValues: []string{cs.Name},
+					Value:       float64(cs.RestartCount),
+				}
+			}
+
+			return &metric.Family{
+				Metrics: ms,
+			}
+		}),
+	)
+}
+
+func createPodContainerStatusRunningFamilyGenerator(k podContainerKind) generator.FamilyGenerator {
+	return createPodContainerStateFamilyGenerator(k, k.names.running, "running", func(s v1.ContainerState) bool {
+		return s.Running != nil
+	})
+}
+
+func createPodContainerStatusTerminatedFamilyGenerator(k podContainerKind) generator.FamilyGenerator {
+	return createPodContainerStateFamilyGenerator(k, k.names.terminated, "terminated", func(s v1.ContainerState) bool {
+		return s.Terminated != nil
+	})
+}
+
+func createPodContainerStatusWaitingFamilyGenerator(k podContainerKind) generator.FamilyGenerator {
+	return createPodContainerStateFamilyGenerator(k, k.names.waiting, "waiting", func(s v1.ContainerState) bool {
+		return s.Waiting != nil
+	})
+}
+
+// createPodContainerStateFamilyGenerator returns the family which describes
+// whether the containers of the kind are currently in the given state.
+func createPodContainerStateFamilyGenerator(k podContainerKind, name, state string, inState func(v1.ContainerState) bool) generator.FamilyGenerator {
+	return *generator.NewFamilyGeneratorWithStability(
+		name,
+		fmt.Sprintf("Describes whether the %s is currently in %s state.", k.noun, state),
+		metric.Gauge,
+		podContainerFamilyStability(name),
+		"",
+		wrapPodFunc(func(p *v1.Pod) *metric.Family {
+			statuses := k.statuses(p)
+			ms := make([]*metric.Metric, len(statuses))
+
+			for i, cs := range statuses {
+				ms[i] = &metric.Metric{
+					LabelKeys:   []string{"container"},
+					LabelValues: []string{cs.Name},
+					Value:       boolFloat64(inState(cs.State)),
+				}
+			}
+
+			return &metric.Family{
+				Metrics: ms,
+			}
+		}),
+	)
+}
+
+func createPodContainerStatusTerminatedReasonFamilyGenerator(k podContainerKind) generator.FamilyGenerator {
+	return *generator.NewFamilyGeneratorWithStability(
+		k.names.terminatedReason,
+		fmt.Sprintf("Describes the reason the %s is currently in terminated state.", k.noun),
+		metric.Gauge,
+		podContainerFamilyStability(k.names.terminatedReason),
+		"",
+		wrapPodFunc(func(p *v1.Pod) *metric.Family {
+			statuses := k.statuses(p)
+			ms := make([]*metric.Metric, 0, len(statuses))
+			for _, cs := range statuses {
+				if cs.State.Terminated != nil {
+					ms = append(ms, &metric.Metric{
+						LabelKeys:   []string{"container", "reason"},
+						LabelValues: []string{cs.Name, cs.State.Terminated.Reason},
+						Value:       1,
+					})
+				}
+			}
+
+			return &metric.Family{
+				Metrics: ms,
+			}
+		}),
+	)
+}
+
+func createPodContainerStatusWaitingReasonFamilyGenerator(k podContainerKind) generator.FamilyGenerator {
+	return *generator.NewFamilyGeneratorWithStability(
+		k.names.waitingReason,
+		fmt.Sprintf("Describes the reason the %s is currently in waiting state.", k.noun),
+		metric.Gauge,
+		podContainerFamilyStability(k.names.waitingReason),
+		"",
+		wrapPodFunc(func(p *v1.Pod) *metric.Family {
+			statuses := k.statuses(p)
+			ms := make([]*metric.Metric, 0, len(statuses))
+			for _, cs := range statuses {
+				// Skip creating series for running containers.
+				if cs.State.Waiting != nil {
+					ms = append(ms, &metric.Metric{
+						LabelKeys:   []string{"container", "reason"},
+						LabelValues: []string{cs.Name, cs.State.Waiting.Reason},
+						Value:       1,
+					})
+				}
+			}
+			return &metric.Family{
+				Metrics: ms,
+			}
+		}),
+	)
+}
@@ -0,0 +1,205 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"strings"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	basemetrics "k8s.io/component-base/metrics"
+
+	"k8s.io/kube-state-metrics/v2/pkg/metric"
+	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
+)
+
+// podPriorityClassKey identifies a series of kube_pod_priority_class_count.
+type podPriorityClassKey struct {
+	namespace     string
+	priorityClass string
+}
+
+// podPriorityClassCount is stored in the MetricsStore of the pods alongside
+// them for each series of kube_pod_priority_class_count. Its UID is derived
+// from the key so that updates replace the previous value of the series. The
+// other pod families ignore it.
+type podPriorityClassCount struct {
+	metav1.ObjectMeta
+	key   podPriorityClassKey
+	count float64
+}
+
+func createPodPriorityClassCountFamilyGenerator() generator.FamilyGenerator {
+	return *generator.NewOptInFamilyGenerator(
+		"kube_pod_priority_class_count",
+		"The number of pods which did not finish per namespace and priority class.",
+		metric.Gauge,
+		basemetrics.ALPHA,
+		"",
+		func(obj interface{}) *metric.Family {
+			c, ok := obj.(*podPriorityClassCount)
+			if !ok {
+				return &metric.Family{}
+			}
+			return &metric.Family{
+				Metrics: []*metric.Metric{
+					{
+						LabelKeys:   []string{"namespace", "priority_class"},
+						LabelValues: []string{c.key.namespace, c.key.priorityClass},
+						Value:       c.count,
+					},
+				},
+			}
+		},
+	)
+}
+
+// podPriorityClassStore wraps the MetricsStore of pods and maintains the
+// podPriorityClassCount of the pods passing through it in the same store, so
+// that pods are counted without a join over the series of all pods. Each
+// shard counts its own pods.
+type podPriorityClassStore struct {
+	cache.Store
+
+	mutex sync.Mutex
+	// pods holds the key of each counted pod.
+	pods   map[types.UID]podPriorityClassKey
+	counts map[podPriorityClassKey]*podPriorityClassCount
+}
+
+func newPodPriorityClassStore(store cache.Store) *podPriorityClassStore {
+	return &podPriorityClassStore{
+		Store:  store,
+		pods:   map[types.UID]podPriorityClassKey{},
+		counts: map[podPriorityClassKey]*podPriorityClassCount{},
+	}
+}
+
+// track updates the counts for the given pod, which is forgotten if deleted
+// is true. It returns the counts which changed. The mutex must be held by the
+// caller.
+func (s *podPriorityClassStore) track(p *v1.Pod, deleted bool) []*podPriorityClassCount {
+	var changed []*podPriorityClassCount
+
+	if key, ok := s.pods[p.UID]; ok {
+		delete(s.pods, p.UID)
+		c := s.counts[key]
+		c.count--
+		changed = append(changed, c)
+	}
+	if _, finished := podFinishedAt(p); !deleted && !finished {
+		key := podPriorityClassKey{namespace: p.Namespace, priorityClass: p.Spec.PriorityClassName}
+		s.pods[p.UID] = key
+		c, ok := s.counts[key]
+		if !ok {
+			c = &podPriorityClassCount{key: key}
+			c.UID = types.UID("priority-class/" + strings.Join([]string{key.namespace, key.priorityClass}, "/"))
+			s.counts[key] = c
+		}
+		c.count++
+		changed = append(changed, c)
+	}
+
+	return changed
+}
+
+// write writes the given counts into the wrapped store, deleting the ones
+// which dropped to zero. The mutex must be held by the caller.
+func (s *podPriorityClassStore) write(counts []*podPriorityClassCount) error {
+	for _, c := range counts {
+		if c.count > 0 {
+			if err := s.Store.Add(c); err != nil {
+				return err
+			}
+			continue
+		}
+		delete(s.counts, c.key)
+		if err := s.Store.Delete(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Add adds the pod to the wrapped store and counts it.
+func (s *podPriorityClassStore) Add(obj interface{}) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.Store.Add(obj); err != nil {
+		return err
+	}
+	if p, ok := obj.(*v1.Pod); ok {
+		return s.write(s.track(p, false))
+	}
+	return nil
+}
+
+// Update updates the pod in the wrapped store and its count.
+func (s *podPriorityClassStore) Update(obj interface{}) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.Store.Update(obj); err != nil {
+		return err
+	}
+	if p, ok := obj.(*v1.Pod); ok {
+		return s.write(s.track(p, false))
+	}
+	return nil
+}
+
+// Delete deletes the pod from the wrapped store and no longer counts it.
+func (s *podPriorityClassStore) Delete(obj interface{}) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.Store.Delete(obj); err != nil {
+		return err
+	}
+	if p, ok := obj.(*v1.Pod); ok {
+		return s.write(s.track(p, true))
+	}
+	return nil
+}
+
+// Replace replaces the contents of the wrapped store with the given list of
+// pods and recounts them.
+func (s *podPriorityClassStore) Replace(list []interface{}, resourceVersion string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.Store.Replace(list, resourceVersion); err != nil {
+		return err
+	}
+	s.pods = map[types.UID]podPriorityClassKey{}
+	s.counts = map[podPriorityClassKey]*podPriorityClassCount{}
+	for _, o := range list {
+		if p, ok := o.(*v1.Pod); ok {
+			s.track(p, false)
+		}
+	}
+	for _, c := range s.counts {
+		if err := s.Store.Add(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
+	metricsstore "k8s.io/kube-state-metrics/v2/pkg/metrics_store"
+)
+
+func TestPodPriorityClassStore(t *testing.T) {
+	families := podMetricFamilies(nil, nil, podFamilyOptions{})
+	store := metricsstore.NewMetricsStore(
+		generator.ExtractMetricFamilyHeaders(families),
+		generator.ComposeMetricGenFuncs(families),
+	)
+	countStore := newPodPriorityClassStore(store)
+
+	newPod := func(name, namespace, priorityClass string, phase v1.PodPhase) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				UID:       types.UID(name),
+			},
+			Spec: v1.PodSpec{
+				PriorityClassName: priorityClass,
+			},
+			Status: v1.PodStatus{
+				Phase: phase,
+			},
+		}
+	}
+
+	counts := func() []string {
+		var b strings.Builder
+		if err := metricsstore.NewMetricsWriter(store).WriteAll(&b); err != nil {
+			t.Fatal(err)
+		}
+		var lines []string
+		for _, l := range strings.Split(b.String(), "\n") {
+			if strings.HasPrefix(l, "kube_pod_priority_class_count{") {
+				lines = append(lines, l)
+			}
+		}
+		return lines
+	}
+	expect := func(want ...string) {
+		t.Helper()
+		got := counts()
+		if !sameElements(got, want) {
+			t.Errorf("expected counts:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+		}
+	}
+
+	if err := countStore.Replace([]interface{}{
+		newPod("web-1", "ns1", "high", v1.PodRunning),
+		newPod("web-2", "ns1", "high", v1.PodPending),
+		newPod("batch-1", "ns1", "", v1.PodRunning),
+		newPod("batch-2", "ns1", "", v1.PodSucceeded),
+		newPod("db-1", "ns2", "high", v1.PodRunning),
+	}, ""); err != nil {
+		t.Fatal(err)
+	}
+	expect(
+		`kube_pod_priority_class_count{namespace="ns1",priority_class="high"} 2`,
+		`kube_pod_priority_class_count{namespace="ns1",priority_class=""} 1`,
+		`kube_pod_priority_class_count{namespace="ns2",priority_class="high"} 1`,
+	)
+
+	// Finished pods are no longer counted, and counts dropping to zero are
+	// removed.
+	if err := countStore.Update(newPod("batch-1", "ns1", "", v1.PodFailed)); err != nil {
+		t.Fatal(err)
+	}
+	if err := countStore.Delete(newPod("web-2", "ns1", "high", v1.PodPending)); err != nil {
+		t.Fatal(err)
+	}
+	if err := countStore.Add(newPod("db-2", "ns2", "low", v1.PodPending)); err != nil {
+		t.Fatal(err)
+	}
+	expect(
+		`kube_pod_priority_class_count{namespace="ns1",priority_class="high"} 1`,
+		`kube_pod_priority_class_count{namespace="ns2",priority_class="high"} 1`,
+		`kube_pod_priority_class_count{namespace="ns2",priority_class="low"} 1`,
+	)
+
+	// The pods keep their own metrics.
+	var b strings.Builder
+	if err := metricsstore.NewMetricsWriter(store).WriteAll(&b); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), `kube_pod_info{namespace="ns2",pod="db-2",uid="db-2"`) {
+		t.Errorf("expected metrics of pod db-2, got:\n%s", b.String())
+	}
+}
+
+func sameElements(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	seen := map[string]int{}
+	for _, s := range a {
+		seen[s]++
+	}
+	for _, s := range b {
+		if seen[s] == 0 {
+			return false
+		}
+		seen[s]--
+	}
+	return true
+}
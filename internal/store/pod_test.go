@@ -17,6 +17,7 @@ limitations under the License.
 package store
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -30,6 +31,7 @@ import (
 
 func TestPodStore(t *testing.T) {
 	var test = true
+	restartPolicyAlways := v1.ContainerRestartPolicyAlways
 	runtimeclass := "foo"
 	startTime := 1501569018
 	metav1StartTime := metav1.Unix(int64(startTime), 0)
@@ -127,6 +129,33 @@ func TestPodStore(t *testing.T) {
 				kube_pod_init_container_info{container="initContainer",container_id="docker://ef123",image_spec="k8s.gcr.io/initfoo_spec",image="k8s.gcr.io/initfoo",image_id="docker://sha256:wxyz",namespace="ns2",pod="pod2",uid="uid2"} 1`,
 			MetricNames: []string{"kube_pod_container_info", "kube_pod_init_container_info"},
 		},
+		{
+			Obj: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pod2",
+					Namespace: "ns2",
+					UID:       "uid2",
+				},
+				Spec: v1.PodSpec{
+					InitContainers: []v1.Container{
+						{
+							Name:  "initContainer",
+							Image: "k8s.gcr.io/initfoo_spec",
+						},
+						{
+							Name:          "sidecar",
+							Image:         "k8s.gcr.io/sidecar_spec",
+							RestartPolicy: &restartPolicyAlways,
+						},
+					},
+				},
+			},
+			Want: `
+				# HELP kube_pod_init_container_restart_policy Describes the restart policy of an init container, which is only set for restartable init containers.
+				# TYPE kube_pod_init_container_restart_policy gauge
+				kube_pod_init_container_restart_policy{container="sidecar",namespace="ns2",pod="pod2",type="Always",uid="uid2"} 1`,
+			MetricNames: []string{"kube_pod_init_container_restart_policy"},
+		},
 		{
 			Obj: &v1.Pod{
 				ObjectMeta: metav1.ObjectMeta{
@@ -1962,6 +1991,79 @@ func TestPodStore(t *testing.T) {
 				"kube_pod_runtimeclass_name_info",
 			},
 		},
+		{
+			Obj: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pod1",
+					Namespace: "ns1",
+					UID:       "uid1",
+				},
+				Spec: v1.PodSpec{
+					Overhead: v1.ResourceList{
+						v1.ResourceCPU:                    resource.MustParse("250m"),
+						v1.ResourceMemory:                 resource.MustParse("120Mi"),
+						"hugepages-2Mi":                   resource.MustParse("2Mi"),
+						"attachable-volumes-aws-ebs":      resource.MustParse("1"),
+						"nvidia.com/gpu":                  resource.MustParse("1"),
+						v1.ResourceName("not-a-resource"): resource.MustParse("1"),
+					},
+				},
+			},
+			Want: `
+				# HELP kube_pod_overhead The pod overhead associated with running a pod, in the base unit of the resource.
+				# HELP kube_pod_overhead_cpu_cores The pod overhead in regards to cpu cores associated with running a pod.
+				# HELP kube_pod_overhead_memory_bytes The pod overhead in regards to memory associated with running a pod.
+				# TYPE kube_pod_overhead gauge
+				# TYPE kube_pod_overhead_cpu_cores gauge
+				# TYPE kube_pod_overhead_memory_bytes gauge
+				kube_pod_overhead{namespace="ns1",pod="pod1",resource="attachable_volumes_aws_ebs",uid="uid1",unit="integer"} 1
+				kube_pod_overhead{namespace="ns1",pod="pod1",resource="cpu",uid="uid1",unit="core"} 0.25
+				kube_pod_overhead{namespace="ns1",pod="pod1",resource="hugepages_2Mi",uid="uid1",unit="byte"} 2.097152e+06
+				kube_pod_overhead{namespace="ns1",pod="pod1",resource="memory",uid="uid1",unit="byte"} 1.2582912e+08
+				kube_pod_overhead{namespace="ns1",pod="pod1",resource="nvidia_com_gpu",uid="uid1",unit="integer"} 1
+				kube_pod_overhead_cpu_cores{namespace="ns1",pod="pod1",uid="uid1"} 0.25
+				kube_pod_overhead_memory_bytes{namespace="ns1",pod="pod1",uid="uid1"} 1.2582912e+08
+			`,
+			MetricNames: []string{
+				"kube_pod_overhead",
+				"kube_pod_overhead_cpu_cores",
+				"kube_pod_overhead_memory_bytes",
+			},
+		},
+		{
+			Obj: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pod1",
+					Namespace: "ns1",
+					UID:       "uid1",
+				},
+				Spec: v1.PodSpec{
+					NodeName: "node1",
+					Resources: &v1.ResourceRequirements{
+						Limits: v1.ResourceList{
+							v1.ResourceMemory: resource.MustParse("1Gi"),
+						},
+						Requests: v1.ResourceList{
+							v1.ResourceCPU:    resource.MustParse("500m"),
+							v1.ResourceMemory: resource.MustParse("512Mi"),
+						},
+					},
+				},
+			},
+			Want: `
+				# HELP kube_pod_spec_resource_limits The pod-level limit of a resource set in the pod spec, in the base unit of the resource.
+				# HELP kube_pod_spec_resource_requests The pod-level request of a resource set in the pod spec, in the base unit of the resource.
+				# TYPE kube_pod_spec_resource_limits gauge
+				# TYPE kube_pod_spec_resource_requests gauge
+				kube_pod_spec_resource_limits{namespace="ns1",node="node1",pod="pod1",resource="memory",uid="uid1",unit="byte"} 1.073741824e+09
+				kube_pod_spec_resource_requests{namespace="ns1",node="node1",pod="pod1",resource="cpu",uid="uid1",unit="core"} 0.5
+				kube_pod_spec_resource_requests{namespace="ns1",node="node1",pod="pod1",resource="memory",uid="uid1",unit="byte"} 5.36870912e+08
+			`,
+			MetricNames: []string{
+				"kube_pod_spec_resource_limits",
+				"kube_pod_spec_resource_requests",
+			},
+		},
 		{
 			Obj: &v1.Pod{
 				ObjectMeta: metav1.ObjectMeta{
@@ -2156,21 +2258,365 @@ func TestPodStore(t *testing.T) {
 				"kube_pod_scheduler",
 			},
 		},
+		{
+			Obj: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pod-conditions",
+					Namespace: "ns1",
+					UID:       "uid-conditions",
+				},
+				Status: v1.PodStatus{
+					Conditions: []v1.PodCondition{
+						{Type: v1.PodReady, Status: v1.ConditionTrue, LastTransitionTime: metav1.Time{Time: time.Unix(1501666018, 0)}},
+						{Type: "example.com/gate", Status: v1.ConditionFalse},
+					},
+				},
+			},
+			Want: `
+				# HELP kube_pod_status_condition The current status conditions of a pod.
+				# HELP kube_pod_status_condition_last_transition_time Unix timestamp of the last transition of the condition of a pod.
+				# TYPE kube_pod_status_condition gauge
+				# TYPE kube_pod_status_condition_last_transition_time gauge
+				kube_pod_status_condition{condition="Ready",namespace="ns1",pod="pod-conditions",status="false",uid="uid-conditions"} 0
+				kube_pod_status_condition{condition="Ready",namespace="ns1",pod="pod-conditions",status="true",uid="uid-conditions"} 1
+				kube_pod_status_condition{condition="Ready",namespace="ns1",pod="pod-conditions",status="unknown",uid="uid-conditions"} 0
+				kube_pod_status_condition{condition="example.com/gate",namespace="ns1",pod="pod-conditions",status="false",uid="uid-conditions"} 1
+				kube_pod_status_condition{condition="example.com/gate",namespace="ns1",pod="pod-conditions",status="true",uid="uid-conditions"} 0
+				kube_pod_status_condition{condition="example.com/gate",namespace="ns1",pod="pod-conditions",status="unknown",uid="uid-conditions"} 0
+				kube_pod_status_condition_last_transition_time{condition="Ready",namespace="ns1",pod="pod-conditions",status="true",uid="uid-conditions"} 1.501666018e+09
+			`,
+			MetricNames: []string{"kube_pod_status_condition", "kube_pod_status_condition_last_transition_time"},
+		},
 	}
 
 	for i, c := range cases {
-		c.Func = generator.ComposeMetricGenFuncs(podMetricFamilies(c.AllowAnnotationsList, c.AllowLabelsList))
-		c.Headers = generator.ExtractMetricFamilyHeaders(podMetricFamilies(c.AllowAnnotationsList, c.AllowLabelsList))
+		c.Func = generator.ComposeMetricGenFuncs(podMetricFamilies(c.AllowAnnotationsList, c.AllowLabelsList, podFamilyOptions{}))
+		c.Headers = generator.ExtractMetricFamilyHeaders(podMetricFamilies(c.AllowAnnotationsList, c.AllowLabelsList, podFamilyOptions{}))
 		if err := c.run(); err != nil {
 			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
 		}
 	}
 }
 
+func TestPodStoreSplitTerminalPods(t *testing.T) {
+	pod := func(phase v1.PodPhase) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "pod1",
+				Namespace: "ns1",
+				UID:       "uid1",
+				OwnerReferences: []metav1.OwnerReference{
+					{Kind: "Job", Name: "job1", Controller: &[]bool{true}[0]},
+				},
+			},
+			Spec: v1.PodSpec{
+				NodeName: "node1",
+			},
+			Status: v1.PodStatus{
+				Phase:  phase,
+				HostIP: "1.1.1.1",
+				PodIP:  "1.2.3.4",
+			},
+		}
+	}
+	cases := []generateMetricsTestCase{
+		{
+			Obj: pod(v1.PodRunning),
+			Want: `
+				# HELP kube_pod_info [STABLE] Information about pod.
+				# HELP kube_pod_terminal_info Information about Succeeded or Failed pod, with fewer labels than kube_pod_info.
+				# TYPE kube_pod_info gauge
+				# TYPE kube_pod_terminal_info gauge
+				kube_pod_info{created_by_kind="Job",created_by_name="job1",host_ip="1.1.1.1",host_network="false",namespace="ns1",node="node1",pod="pod1",pod_ip="1.2.3.4",priority_class="",uid="uid1"} 1
+			`,
+			MetricNames: []string{"kube_pod_info", "kube_pod_terminal_info"},
+		},
+		{
+			Obj: pod(v1.PodSucceeded),
+			Want: `
+				# HELP kube_pod_info [STABLE] Information about pod.
+				# HELP kube_pod_terminal_info Information about Succeeded or Failed pod, with fewer labels than kube_pod_info.
+				# TYPE kube_pod_info gauge
+				# TYPE kube_pod_terminal_info gauge
+				kube_pod_terminal_info{created_by_kind="Job",created_by_name="job1",namespace="ns1",node="node1",phase="Succeeded",pod="pod1",uid="uid1"} 1
+			`,
+			MetricNames: []string{"kube_pod_info", "kube_pod_terminal_info"},
+		},
+	}
+
+	for i, c := range cases {
+		families := podMetricFamilies(nil, nil, podFamilyOptions{splitTerminalPods: true})
+		for _, f := range families {
+			if f.Name == "kube_pod_terminal_info" && f.OptIn {
+				t.Errorf("expected kube_pod_terminal_info not to be opt-in when splitting terminal pods")
+			}
+		}
+		c.Func = generator.ComposeMetricGenFuncs(families)
+		c.Headers = generator.ExtractMetricFamilyHeaders(families)
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
+		}
+	}
+}
+
+func TestPodStoreLastTerminatedInfo(t *testing.T) {
+	c := generateMetricsTestCase{
+		Obj: &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "pod1",
+				Namespace: "ns1",
+				UID:       "uid1",
+			},
+			Status: v1.PodStatus{
+				ContainerStatuses: []v1.ContainerStatus{
+					{
+						Name: "container1",
+						LastTerminationState: v1.ContainerState{
+							Terminated: &v1.ContainerStateTerminated{
+								Reason:     "OOMKilled",
+								ExitCode:   137,
+								FinishedAt: metav1.Unix(1501777018, 0),
+							},
+						},
+					},
+					{
+						Name: "container2",
+						LastTerminationState: v1.ContainerState{
+							Terminated: &v1.ContainerStateTerminated{
+								Reason:   "Error",
+								ExitCode: 1,
+							},
+						},
+					},
+					{
+						Name: "container3",
+					},
+				},
+			},
+		},
+		// The limit of one reason replaces the second reason.
+		Want: `
+			# HELP kube_pod_container_status_last_terminated_info Unix timestamp the container last terminated at, with the reason and exit code of the termination.
+			# TYPE kube_pod_container_status_last_terminated_info gauge
+			kube_pod_container_status_last_terminated_info{container="container1",exit_code="137",namespace="ns1",pod="pod1",reason="OOMKilled",uid="uid1"} 1.501777018e+09
+			kube_pod_container_status_last_terminated_info{container="container2",exit_code="1",namespace="ns1",pod="pod1",reason="Other",uid="uid1"} 0
+		`,
+		MetricNames: []string{"kube_pod_container_status_last_terminated_info"},
+	}
+	families := podMetricFamilies(nil, nil, podFamilyOptions{lastTerminatedReasonLimit: 1})
+	c.Func = generator.ComposeMetricGenFuncs(families)
+	c.Headers = generator.ExtractMetricFamilyHeaders(families)
+	if err := c.run(); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestPodStoreContainerKinds(t *testing.T) {
+	c := generateMetricsTestCase{
+		Obj: &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "pod1",
+				Namespace: "ns1",
+				UID:       "uid1",
+			},
+			Spec: v1.PodSpec{
+				EphemeralContainers: []v1.EphemeralContainer{
+					{
+						EphemeralContainerCommon: v1.EphemeralContainerCommon{
+							Name:  "debugger",
+							Image: "busybox",
+						},
+					},
+				},
+			},
+			Status: v1.PodStatus{
+				InitContainerStatuses: []v1.ContainerStatus{
+					{
+						Name: "init1",
+						State: v1.ContainerState{
+							Terminated: &v1.ContainerStateTerminated{
+								StartedAt: metav1.Unix(1501777018, 0),
+							},
+						},
+						LastTerminationState: v1.ContainerState{
+							Terminated: &v1.ContainerStateTerminated{
+								ExitCode: 1,
+							},
+						},
+					},
+				},
+				EphemeralContainerStatuses: []v1.ContainerStatus{
+					{
+						Name:         "debugger",
+						Image:        "busybox:latest",
+						ImageID:      "docker://sha256:abc",
+						ContainerID:  "docker://def",
+						RestartCount: 2,
+						State: v1.ContainerState{
+							Running: &v1.ContainerStateRunning{
+								StartedAt: metav1.Unix(1501777019, 0),
+							},
+						},
+					},
+				},
+			},
+		},
+		Want: `
+			# HELP kube_pod_ephemeral_container_info Information about an ephemeral container in a pod.
+			# HELP kube_pod_ephemeral_container_state_started Start time in unix timestamp for a pod ephemeral container.
+			# HELP kube_pod_ephemeral_container_status_restarts_total The number of restarts for the ephemeral container.
+			# HELP kube_pod_ephemeral_container_status_running Describes whether the ephemeral container is currently in running state.
+			# HELP kube_pod_init_container_state_started Start time in unix timestamp for a pod init container.
+			# HELP kube_pod_init_container_status_last_terminated_exitcode Describes the exit code for the last init container in terminated state.
+			# TYPE kube_pod_ephemeral_container_info gauge
+			# TYPE kube_pod_ephemeral_container_state_started gauge
+			# TYPE kube_pod_ephemeral_container_status_restarts_total counter
+			# TYPE kube_pod_ephemeral_container_status_running gauge
+			# TYPE kube_pod_init_container_state_started gauge
+			# TYPE kube_pod_init_container_status_last_terminated_exitcode gauge
+			kube_pod_ephemeral_container_info{container="debugger",container_id="docker://def",image="busybox:latest",image_id="docker://sha256:abc",image_spec="busybox",namespace="ns1",pod="pod1",uid="uid1"} 1
+			kube_pod_ephemeral_container_state_started{container="debugger",namespace="ns1",pod="pod1",uid="uid1"} 1.501777019e+09
+			kube_pod_ephemeral_container_status_restarts_total{container="debugger",namespace="ns1",pod="pod1",uid="uid1"} 2
+			kube_pod_ephemeral_container_status_running{container="debugger",namespace="ns1",pod="pod1",uid="uid1"} 1
+			kube_pod_init_container_state_started{container="init1",namespace="ns1",pod="pod1",uid="uid1"} 1.501777018e+09
+			kube_pod_init_container_status_last_terminated_exitcode{container="init1",namespace="ns1",pod="pod1",uid="uid1"} 1
+		`,
+		MetricNames: []string{
+			"kube_pod_ephemeral_container_info",
+			"kube_pod_ephemeral_container_state_started",
+			"kube_pod_ephemeral_container_status_restarts_total",
+			"kube_pod_ephemeral_container_status_running",
+			"kube_pod_init_container_state_started",
+			"kube_pod_init_container_status_last_terminated_exitcode",
+		},
+	}
+	families := podMetricFamilies(nil, nil, podFamilyOptions{})
+	c.Func = generator.ComposeMetricGenFuncs(families)
+	c.Headers = generator.ExtractMetricFamilyHeaders(families)
+	if err := c.run(); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestPodContainerFamilyParity(t *testing.T) {
+	// Ephemeral containers have neither resources nor readiness probes.
+	ephemeralContainerExclusions := map[string]struct{}{
+		"resource_limits":   {},
+		"resource_requests": {},
+		"status_ready":      {},
+	}
+
+	names := map[string]struct{}{}
+	for _, f := range podMetricFamilies(nil, nil, podFamilyOptions{}) {
+		names[f.Name] = struct{}{}
+	}
+	for name := range names {
+		if !strings.HasPrefix(name, "kube_pod_container_") {
+			continue
+		}
+		suffix := strings.TrimPrefix(name, "kube_pod_container_")
+		for _, prefix := range []string{"kube_pod_init_container_", "kube_pod_ephemeral_container_"} {
+			_, ok := names[prefix+suffix]
+			_, excluded := ephemeralContainerExclusions[suffix]
+			if prefix == "kube_pod_ephemeral_container_" && excluded {
+				if ok {
+					t.Errorf("family %s must not be generated for ephemeral containers", prefix+suffix)
+				}
+				continue
+			}
+			if !ok {
+				t.Errorf("family %s has no equivalent %s", name, prefix+suffix)
+			}
+		}
+	}
+}
+
+func TestPodStoreStatusReasons(t *testing.T) {
+	cases := []generateMetricsTestCase{
+		{
+			Obj: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pod1",
+					Namespace: "ns1",
+					UID:       "uid1",
+				},
+				Status: v1.PodStatus{
+					Conditions: []v1.PodCondition{
+						{
+							Type:   v1.DisruptionTarget,
+							Status: v1.ConditionTrue,
+							Reason: v1.PodReasonPreemptionByScheduler,
+						},
+					},
+				},
+			},
+			Want: `
+				# HELP kube_pod_status_reason The pod status reasons
+				# TYPE kube_pod_status_reason gauge
+				kube_pod_status_reason{namespace="ns1",pod="pod1",reason="Evicted",uid="uid1"} 0
+				kube_pod_status_reason{namespace="ns1",pod="pod1",reason="PreemptionByScheduler",uid="uid1"} 1
+				kube_pod_status_reason{namespace="ns1",pod="pod1",reason="TerminationByKubelet",uid="uid1"} 0
+			`,
+			MetricNames: []string{"kube_pod_status_reason"},
+		},
+		{
+			Obj: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pod2",
+					Namespace: "ns1",
+					UID:       "uid2",
+				},
+				Status: v1.PodStatus{
+					Reason:  "Evicted",
+					Message: "The node was low on resource: memory. Threshold quantity: 100Mi, available: 50Mi. ",
+				},
+			},
+			Want: `
+				# HELP kube_pod_status_eviction_category The category of the eviction of an evicted pod, i.e. the resource or node condition the kubelet evicted the pod for.
+				# HELP kube_pod_status_reason The pod status reasons
+				# TYPE kube_pod_status_eviction_category gauge
+				# TYPE kube_pod_status_reason gauge
+				kube_pod_status_eviction_category{category="memory",namespace="ns1",pod="pod2",uid="uid2"} 1
+				kube_pod_status_reason{namespace="ns1",pod="pod2",reason="Evicted",uid="uid2"} 1
+				kube_pod_status_reason{namespace="ns1",pod="pod2",reason="PreemptionByScheduler",uid="uid2"} 0
+				kube_pod_status_reason{namespace="ns1",pod="pod2",reason="TerminationByKubelet",uid="uid2"} 0
+			`,
+			MetricNames: []string{"kube_pod_status_eviction_category", "kube_pod_status_reason"},
+		},
+	}
+	families := podMetricFamilies(nil, nil, podFamilyOptions{statusReasons: []string{"Evicted", v1.PodReasonPreemptionByScheduler, v1.PodReasonTerminationByKubelet}})
+	for i, c := range cases {
+		c.Func = generator.ComposeMetricGenFuncs(families)
+		c.Headers = generator.ExtractMetricFamilyHeaders(families)
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
+		}
+	}
+}
+
+func TestPodEvictionCategory(t *testing.T) {
+	tests := []struct {
+		message string
+		want    string
+	}{
+		{"The node was low on resource: ephemeral-storage. Threshold quantity: 1Gi, available: 500Mi. ", "ephemeral-storage"},
+		{"The node had condition: [DiskPressure]. ", "DiskPressure"},
+		{"Pod ephemeral local storage usage exceeds the total limit of containers 1Gi. ", "ephemeral-storage"},
+		{"Container app exceeded its local ephemeral storage limit \"1Gi\". ", "ephemeral-storage"},
+		{"Usage of EmptyDir volume \"cache\" exceeds the limit \"1Gi\". ", "ephemeral-storage"},
+		{"", "Other"},
+	}
+	for _, tt := range tests {
+		if got := podEvictionCategory(tt.message); got != tt.want {
+			t.Errorf("podEvictionCategory(%q) = %q, want %q", tt.message, got, tt.want)
+		}
+	}
+}
+
 func BenchmarkPodStore(b *testing.B) {
 	b.ReportAllocs()
 
-	f := generator.ComposeMetricGenFuncs(podMetricFamilies(nil, nil))
+	f := generator.ComposeMetricGenFuncs(podMetricFamilies(nil, nil, podFamilyOptions{}))
 
 	pod := &v1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
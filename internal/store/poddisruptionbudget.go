@@ -19,6 +19,7 @@ package store
 import (
 	"context"
 
+	corev1 "k8s.io/api/core/v1"
 	policyv1 "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -41,7 +42,7 @@ var (
 
 func podDisruptionBudgetMetricFamilies(allowAnnotationsList, allowLabelsList []string) []generator.FamilyGenerator {
 	return []generator.FamilyGenerator{
-		*generator.NewFamilyGeneratorWithStability(
+		*generator.NewLazyFamilyGenerator(
 			descPodDisruptionBudgetAnnotationsName,
 			descPodDisruptionBudgetAnnotationsHelp,
 			metric.Gauge,
@@ -63,7 +64,7 @@ func podDisruptionBudgetMetricFamilies(allowAnnotationsList, allowLabelsList []s
 				}
 			}),
 		),
-		*generator.NewFamilyGeneratorWithStability(
+		*generator.NewLazyFamilyGenerator(
 			descPodDisruptionBudgetLabelsName,
 			descPodDisruptionBudgetLabelsHelp,
 			metric.Gauge,
@@ -95,9 +96,7 @@ func podDisruptionBudgetMetricFamilies(allowAnnotationsList, allowLabelsList []s
 				ms := []*metric.Metric{}
 
 				if !p.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						Value: float64(p.CreationTimestamp.Unix()),
-					})
+					ms = append(ms, metric.TimestampMetric(nil, nil, p.CreationTimestamp.Time))
 				}
 
 				return &metric.Family{
@@ -185,6 +184,20 @@ func podDisruptionBudgetMetricFamilies(allowAnnotationsList, allowLabelsList []s
 				}
 			}),
 		),
+		*generator.NewFamilyGeneratorWithStability(
+			"kube_poddisruptionbudget_status_condition",
+			"The current status conditions of a pod disruption budget.",
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			wrapPodDisruptionBudgetFunc(func(p *policyv1.PodDisruptionBudget) *metric.Family {
+				return &metric.Family{
+					Metrics: statusConditionMetrics(p.Status.Conditions, func(c metav1.Condition) (string, corev1.ConditionStatus) {
+						return string(c.Type), corev1.ConditionStatus(c.Status)
+					}),
+				}
+			}),
+		),
 	}
 }
 
@@ -48,6 +48,8 @@ func TestPodDisruptionBudgetStore(t *testing.T) {
 	# TYPE kube_poddisruptionbudget_status_expected_pods gauge
 	# HELP kube_poddisruptionbudget_status_observed_generation [STABLE] Most recent generation observed when updating this PDB status
 	# TYPE kube_poddisruptionbudget_status_observed_generation gauge
+	# HELP kube_poddisruptionbudget_status_condition The current status conditions of a pod disruption budget.
+	# TYPE kube_poddisruptionbudget_status_condition gauge
 	`
 	cases := []generateMetricsTestCase{
 		{
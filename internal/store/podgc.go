@@ -0,0 +1,211 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+// podGCStore wraps a cache.Store of pods and removes Succeeded and Failed pods
+// from it once they finished longer than the threshold ago. Pods whose finish
+// time is unknown, e.g. evicted pods, are removed once they were first
+// observed as finished longer than the threshold ago. As finished pods usually
+// do not receive further updates, expired pods are also removed periodically.
+type podGCStore struct {
+	cache.Store
+	threshold time.Duration
+
+	mutex sync.Mutex
+	// finished holds the finish time of the finished pods which were not
+	// deleted yet.
+	finished map[types.UID]finishedPod
+}
+
+type finishedPod struct {
+	pod        *v1.Pod
+	finishedAt time.Time
+	// collected is set once the pod was removed from the wrapped store.
+	collected bool
+}
+
+func newPodGCStore(ctx context.Context, store cache.Store, threshold time.Duration) *podGCStore {
+	s := &podGCStore{
+		Store:     store,
+		threshold: threshold,
+		finished:  map[types.UID]finishedPod{},
+	}
+
+	interval := threshold
+	if interval > time.Minute {
+		interval = time.Minute
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.collect()
+			}
+		}
+	}()
+
+	return s
+}
+
+// podFinishedAt returns when the given pod finished, or false if it did not.
+// The time is zero if it is unknown.
+func podFinishedAt(p *v1.Pod) (time.Time, bool) {
+	if p.Status.Phase != v1.PodSucceeded && p.Status.Phase != v1.PodFailed {
+		return time.Time{}, false
+	}
+
+	var finishedAt time.Time
+	for _, cs := range p.Status.ContainerStatuses {
+		if cs.State.Terminated != nil && cs.State.Terminated.FinishedAt.After(finishedAt) {
+			finishedAt = cs.State.Terminated.FinishedAt.Time
+		}
+	}
+	if !finishedAt.IsZero() {
+		return finishedAt, true
+	}
+	for _, c := range p.Status.Conditions {
+		if c.Type == v1.PodReady && !c.LastTransitionTime.IsZero() {
+			return c.LastTransitionTime.Time, true
+		}
+	}
+	return time.Time{}, true
+}
+
+// track records the given pod if it finished and reports whether it expired.
+// The mutex must be held by the caller.
+func (s *podGCStore) track(p *v1.Pod) bool {
+	finishedAt, ok := podFinishedAt(p)
+	if !ok {
+		delete(s.finished, p.UID)
+		return false
+	}
+	if finishedAt.IsZero() {
+		finishedAt = timeNow()
+		if f, ok := s.finished[p.UID]; ok {
+			finishedAt = f.finishedAt
+		}
+	}
+	// Only the object meta is needed to delete the pod later on. Expired pods
+	// stay tracked until they are deleted, so that the time they were first
+	// observed as finished is kept.
+	expired := timeNow().Sub(finishedAt) > s.threshold
+	s.finished[p.UID] = finishedPod{
+		pod:        &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: p.Name, Namespace: p.Namespace, UID: p.UID}},
+		finishedAt: finishedAt,
+		collected:  expired,
+	}
+	return expired
+}
+
+// Add adds the pod to the wrapped store unless it expired.
+func (s *podGCStore) Add(obj interface{}) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if p, ok := obj.(*v1.Pod); ok && s.track(p) {
+		return s.Store.Delete(obj)
+	}
+	return s.Store.Add(obj)
+}
+
+// Update updates the pod in the wrapped store, or deletes it from there if it
+// expired.
+func (s *podGCStore) Update(obj interface{}) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if p, ok := obj.(*v1.Pod); ok && s.track(p) {
+		return s.Store.Delete(obj)
+	}
+	return s.Store.Update(obj)
+}
+
+// Delete deletes the pod from the wrapped store.
+func (s *podGCStore) Delete(obj interface{}) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if p, ok := obj.(*v1.Pod); ok {
+		delete(s.finished, p.UID)
+	}
+	return s.Store.Delete(obj)
+}
+
+// Replace replaces the contents of the wrapped store with the pods of the
+// given list which did not expire.
+func (s *podGCStore) Replace(list []interface{}, resourceVersion string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// The finish times of the pods which are still listed are kept, so that
+	// pods whose finish time is unknown are not observed as finished anew.
+	listed := make(map[types.UID]struct{}, len(list))
+	filtered := make([]interface{}, 0, len(list))
+	for _, o := range list {
+		p, ok := o.(*v1.Pod)
+		if !ok {
+			filtered = append(filtered, o)
+			continue
+		}
+		listed[p.UID] = struct{}{}
+		if s.track(p) {
+			continue
+		}
+		filtered = append(filtered, o)
+	}
+	for uid := range s.finished {
+		if _, ok := listed[uid]; !ok {
+			delete(s.finished, uid)
+		}
+	}
+	return s.Store.Replace(filtered, resourceVersion)
+}
+
+// collect deletes the expired pods from the wrapped store.
+func (s *podGCStore) collect() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := timeNow()
+	for uid, f := range s.finished {
+		if f.collected || now.Sub(f.finishedAt) <= s.threshold {
+			continue
+		}
+		if err := s.Store.Delete(f.pod); err != nil {
+			klog.ErrorS(err, "Failed to delete finished pod", "pod", klog.KObj(f.pod))
+			continue
+		}
+		f.collected = true
+		s.finished[uid] = f
+	}
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
+	metricsstore "k8s.io/kube-state-metrics/v2/pkg/metrics_store"
+)
+
+func TestPodGCStore(t *testing.T) {
+	now := time.Unix(1500000000, 0)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	families := podMetricFamilies(nil, nil, podFamilyOptions{})
+	store := metricsstore.NewMetricsStore(
+		generator.ExtractMetricFamilyHeaders(families),
+		generator.ComposeMetricGenFuncs(families),
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	gcStore := newPodGCStore(ctx, store, time.Hour)
+
+	newPod := func(name string, phase v1.PodPhase, finishedAgo time.Duration) *v1.Pod {
+		p := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "ns1",
+				UID:       types.UID(name),
+			},
+			Status: v1.PodStatus{
+				Phase: phase,
+			},
+		}
+		if finishedAgo > 0 {
+			p.Status.ContainerStatuses = []v1.ContainerStatus{
+				{
+					Name: "container1",
+					State: v1.ContainerState{
+						Terminated: &v1.ContainerStateTerminated{
+							FinishedAt: metav1.NewTime(now.Add(-finishedAgo)),
+						},
+					},
+				},
+			}
+		}
+		return p
+	}
+
+	if err := gcStore.Replace([]interface{}{
+		newPod("running", v1.PodRunning, 0),
+		newPod("old-succeeded", v1.PodSucceeded, 2*time.Hour),
+		newPod("recent-failed", v1.PodFailed, 30*time.Minute),
+	}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := gcStore.Add(newPod("old-failed", v1.PodFailed, 3*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	render := func() string {
+		var b strings.Builder
+		if err := metricsstore.NewMetricsWriter(store).WriteAll(&b); err != nil {
+			t.Fatal(err)
+		}
+		return b.String()
+	}
+
+	out := render()
+	for name, want := range map[string]bool{"running": true, "old-succeeded": false, "recent-failed": true, "old-failed": false} {
+		if got := strings.Contains(out, `pod="`+name+`"`); got != want {
+			t.Errorf("expected metrics of pod %s to be present: %v, got:\n%s", name, want, out)
+		}
+	}
+
+	// Once the threshold passed, finished pods are collected without updates.
+	now = now.Add(time.Hour)
+	gcStore.collect()
+
+	out = render()
+	if strings.Contains(out, `pod="recent-failed"`) {
+		t.Errorf("expected metrics of pod recent-failed to be collected, got:\n%s", out)
+	}
+	if !strings.Contains(out, `pod="running"`) {
+		t.Errorf("expected metrics of pod running to be present, got:\n%s", out)
+	}
+
+	// Pods whose finish time is unknown are collected once they were first
+	// observed as finished longer than the threshold ago.
+	evicted := newPod("evicted", v1.PodFailed, 0)
+	evicted.Status.Reason = "Evicted"
+	evicted.Status.StartTime = &metav1.Time{Time: now.Add(-24 * time.Hour)}
+	if err := gcStore.Add(evicted); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(30 * time.Minute)
+	if err := gcStore.Replace([]interface{}{newPod("running", v1.PodRunning, 0), evicted}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if out = render(); !strings.Contains(out, `pod="evicted"`) {
+		t.Errorf("expected metrics of pod evicted to be present, got:\n%s", out)
+	}
+
+	now = now.Add(time.Hour)
+	gcStore.collect()
+	if err := gcStore.Update(evicted); err != nil {
+		t.Fatal(err)
+	}
+	if out = render(); strings.Contains(out, `pod="evicted"`) {
+		t.Errorf("expected metrics of pod evicted to be collected, got:\n%s", out)
+	}
+}
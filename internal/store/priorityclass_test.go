@@ -0,0 +1,96 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
+)
+
+func TestPriorityClassStore(t *testing.T) {
+	startTime := 1501569018
+	metav1StartTime := metav1.Unix(int64(startTime), 0)
+	preemptNever := v1.PreemptNever
+
+	cases := []generateMetricsTestCase{
+		{
+			Obj: &schedulingv1.PriorityClass{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "high-priority",
+					CreationTimestamp: metav1StartTime,
+				},
+				Value:         1000000,
+				GlobalDefault: true,
+			},
+			Want: `
+					# HELP kube_priorityclass_created Unix creation timestamp
+					# HELP kube_priorityclass_info Information about priorityclass.
+					# HELP kube_priorityclass_value The priority of pods of the priorityclass.
+					# TYPE kube_priorityclass_created gauge
+					# TYPE kube_priorityclass_info gauge
+					# TYPE kube_priorityclass_value gauge
+					kube_priorityclass_created{priorityclass="high-priority"} 1.501569018e+09
+					kube_priorityclass_info{priorityclass="high-priority",preemption_policy="PreemptLowerPriority",global_default="true"} 1
+					kube_priorityclass_value{priorityclass="high-priority"} 1e+06
+				`,
+			MetricNames: []string{
+				"kube_priorityclass_created", "kube_priorityclass_info", "kube_priorityclass_value",
+			},
+		},
+		{
+			AllowLabelsList: []string{
+				"team",
+			},
+			Obj: &schedulingv1.PriorityClass{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "batch",
+					Labels: map[string]string{
+						"team": "data",
+					},
+				},
+				Value:            -10,
+				PreemptionPolicy: &preemptNever,
+			},
+			Want: `
+					# HELP kube_priorityclass_info Information about priorityclass.
+					# HELP kube_priorityclass_labels Kubernetes labels converted to Prometheus labels.
+					# HELP kube_priorityclass_value The priority of pods of the priorityclass.
+					# TYPE kube_priorityclass_info gauge
+					# TYPE kube_priorityclass_labels gauge
+					# TYPE kube_priorityclass_value gauge
+					kube_priorityclass_info{priorityclass="batch",preemption_policy="Never",global_default="false"} 1
+					kube_priorityclass_labels{priorityclass="batch",label_team="data"} 1
+					kube_priorityclass_value{priorityclass="batch"} -10
+				`,
+			MetricNames: []string{
+				"kube_priorityclass_info", "kube_priorityclass_labels", "kube_priorityclass_value",
+			},
+		},
+	}
+	for i, c := range cases {
+		c.Func = generator.ComposeMetricGenFuncs(priorityClassMetricFamilies(nil, c.AllowLabelsList))
+		c.Headers = generator.ExtractMetricFamilyHeaders(priorityClassMetricFamilies(nil, c.AllowLabelsList))
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
+		}
+	}
+}
@@ -26,6 +26,7 @@ import (
 	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
 
 	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/watch"
@@ -41,8 +42,15 @@ var (
 	descReplicaSetLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
 )
 
-func replicaSetMetricFamilies(allowAnnotationsList, allowLabelsList []string) []generator.FamilyGenerator {
-	return []generator.FamilyGenerator{
+// replicaSetFamilyOptions configures the replicaset metric families.
+type replicaSetFamilyOptions struct {
+	// deploymentLabel adds the name of the deployment which controls a
+	// replicaset as deployment label to all families.
+	deploymentLabel bool
+}
+
+func replicaSetMetricFamilies(allowAnnotationsList, allowLabelsList []string, opts replicaSetFamilyOptions) []generator.FamilyGenerator {
+	families := []generator.FamilyGenerator{
 		*generator.NewFamilyGeneratorWithStability(
 			"kube_replicaset_created",
 			"Unix creation timestamp",
@@ -53,10 +61,7 @@ func replicaSetMetricFamilies(allowAnnotationsList, allowLabelsList []string) []
 				ms := []*metric.Metric{}
 
 				if !r.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-
-						Value: float64(r.CreationTimestamp.Unix()),
-					})
+					ms = append(ms, metric.TimestampMetric(nil, nil, r.CreationTimestamp.Time))
 				}
 
 				return &metric.Family{
@@ -128,6 +133,20 @@ func replicaSetMetricFamilies(allowAnnotationsList, allowLabelsList []string) []
 				}
 			}),
 		),
+		*generator.NewFamilyGeneratorWithStability(
+			"kube_replicaset_status_condition",
+			"The current status conditions of a replicaset.",
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			wrapReplicaSetFunc(func(r *v1.ReplicaSet) *metric.Family {
+				return &metric.Family{
+					Metrics: statusConditionMetrics(r.Status.Conditions, func(c v1.ReplicaSetCondition) (string, corev1.ConditionStatus) {
+						return string(c.Type), c.Status
+					}),
+				}
+			}),
+		),
 		*generator.NewFamilyGeneratorWithStability(
 			"kube_replicaset_spec_replicas",
 			"Number of desired pods for a ReplicaSet.",
@@ -209,7 +228,7 @@ func replicaSetMetricFamilies(allowAnnotationsList, allowLabelsList []string) []
 				}
 			}),
 		),
-		*generator.NewFamilyGeneratorWithStability(
+		*generator.NewLazyFamilyGenerator(
 			descReplicaSetAnnotationsName,
 			descReplicaSetAnnotationsHelp,
 			metric.Gauge,
@@ -231,7 +250,7 @@ func replicaSetMetricFamilies(allowAnnotationsList, allowLabelsList []string) []
 				}
 			}),
 		),
-		*generator.NewFamilyGeneratorWithStability(
+		*generator.NewLazyFamilyGenerator(
 			descReplicaSetLabelsName,
 			descReplicaSetLabelsHelp,
 			metric.Gauge,
@@ -254,6 +273,33 @@ func replicaSetMetricFamilies(allowAnnotationsList, allowLabelsList []string) []
 			}),
 		),
 	}
+	if opts.deploymentLabel {
+		for i := range families {
+			families[i].GenerateFunc = withReplicaSetDeploymentLabel(families[i].GenerateFunc)
+		}
+	}
+	return families
+}
+
+// withReplicaSetDeploymentLabel adds the name of the deployment which controls
+// the replicaset as deployment label to the metrics generated by f. The label
+// is empty for replicasets which are not controlled by a deployment.
+func withReplicaSetDeploymentLabel(f func(interface{}) *metric.Family) func(interface{}) *metric.Family {
+	return func(obj interface{}) *metric.Family {
+		deployment := ""
+		if owner := metav1.GetControllerOf(obj.(*v1.ReplicaSet)); owner != nil && owner.Kind == "Deployment" {
+			deployment = owner.Name
+		}
+
+		metricFamily := f(obj)
+
+		for _, m := range metricFamily.Metrics {
+			m.LabelKeys = append(m.LabelKeys, "deployment")
+			m.LabelValues = append(m.LabelValues, deployment)
+		}
+
+		return metricFamily
+	}
 }
 
 func wrapReplicaSetFunc(f func(*v1.ReplicaSet) *metric.Family) func(interface{}) *metric.Family {
@@ -50,6 +50,8 @@ func TestReplicaSetStore(t *testing.T) {
 		# TYPE kube_replicaset_status_ready_replicas gauge
 		# HELP kube_replicaset_status_observed_generation [STABLE] The generation observed by the ReplicaSet controller.
 		# TYPE kube_replicaset_status_observed_generation gauge
+		# HELP kube_replicaset_status_condition The current status conditions of a replicaset.
+		# TYPE kube_replicaset_status_condition gauge
 		# HELP kube_replicaset_spec_replicas [STABLE] Number of desired pods for a ReplicaSet.
 		# TYPE kube_replicaset_spec_replicas gauge
 		# HELP kube_replicaset_owner [STABLE] Information about the ReplicaSet's owner.
@@ -130,11 +132,59 @@ func TestReplicaSetStore(t *testing.T) {
 		},
 	}
 	for i, c := range cases {
-		c.Func = generator.ComposeMetricGenFuncs(replicaSetMetricFamilies(nil, nil))
-		c.Headers = generator.ExtractMetricFamilyHeaders(replicaSetMetricFamilies(nil, nil))
+		c.Func = generator.ComposeMetricGenFuncs(replicaSetMetricFamilies(nil, nil, replicaSetFamilyOptions{}))
+		c.Headers = generator.ExtractMetricFamilyHeaders(replicaSetMetricFamilies(nil, nil, replicaSetFamilyOptions{}))
 		if err := c.run(); err != nil {
 			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
 		}
 
 	}
 }
+
+func TestReplicaSetStoreDeploymentLabel(t *testing.T) {
+	controller := true
+	cases := []generateMetricsTestCase{
+		{
+			Obj: &v1.ReplicaSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "rs1-7d9f8b",
+					Namespace: "ns1",
+					OwnerReferences: []metav1.OwnerReference{
+						{Kind: "Deployment", Name: "rs1", Controller: &controller},
+					},
+				},
+				Spec: v1.ReplicaSetSpec{
+					Replicas: &rs1Replicas,
+				},
+			},
+			Want: `
+				# HELP kube_replicaset_spec_replicas [STABLE] Number of desired pods for a ReplicaSet.
+				# TYPE kube_replicaset_spec_replicas gauge
+				kube_replicaset_spec_replicas{deployment="rs1",namespace="ns1",replicaset="rs1-7d9f8b"} 5
+			`,
+			MetricNames: []string{"kube_replicaset_spec_replicas"},
+		},
+		{
+			Obj: &v1.ReplicaSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "rs2",
+					Namespace: "ns2",
+				},
+			},
+			Want: `
+				# HELP kube_replicaset_status_replicas [STABLE] The number of replicas per ReplicaSet.
+				# TYPE kube_replicaset_status_replicas gauge
+				kube_replicaset_status_replicas{deployment="",namespace="ns2",replicaset="rs2"} 0
+			`,
+			MetricNames: []string{"kube_replicaset_status_replicas"},
+		},
+	}
+	families := replicaSetMetricFamilies(nil, nil, replicaSetFamilyOptions{deploymentLabel: true})
+	for i, c := range cases {
+		c.Func = generator.ComposeMetricGenFuncs(families)
+		c.Headers = generator.ExtractMetricFamilyHeaders(families)
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
+		}
+	}
+}
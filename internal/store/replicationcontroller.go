@@ -46,9 +46,7 @@ var (
 				ms := []*metric.Metric{}
 
 				if !r.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						Value: float64(r.CreationTimestamp.Unix()),
-					})
+					ms = append(ms, metric.TimestampMetric(nil, nil, r.CreationTimestamp.Time))
 				}
 
 				return &metric.Family{
@@ -136,6 +134,20 @@ var (
 				}
 			}),
 		),
+		*generator.NewFamilyGeneratorWithStability(
+			"kube_replicationcontroller_status_condition",
+			"The current status conditions of a replication controller.",
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			wrapReplicationControllerFunc(func(r *v1.ReplicationController) *metric.Family {
+				return &metric.Family{
+					Metrics: statusConditionMetrics(r.Status.Conditions, func(c v1.ReplicationControllerCondition) (string, v1.ConditionStatus) {
+						return string(c.Type), c.Status
+					}),
+				}
+			}),
+		),
 		*generator.NewFamilyGeneratorWithStability(
 			"kube_replicationcontroller_spec_replicas",
 			"Number of desired pods for a ReplicationController.",
@@ -54,6 +54,8 @@ func TestReplicationControllerStore(t *testing.T) {
 		# TYPE kube_replicationcontroller_status_ready_replicas gauge
 		# HELP kube_replicationcontroller_status_observed_generation [STABLE] The generation observed by the ReplicationController controller.
 		# TYPE kube_replicationcontroller_status_observed_generation gauge
+		# HELP kube_replicationcontroller_status_condition The current status conditions of a replication controller.
+		# TYPE kube_replicationcontroller_status_condition gauge
 		# HELP kube_replicationcontroller_spec_replicas [STABLE] Number of desired pods for a ReplicationController.
 		# TYPE kube_replicationcontroller_spec_replicas gauge
 	`
@@ -51,10 +51,7 @@ func resourceQuotaMetricFamilies(allowAnnotationsList, allowLabelsList []string)
 				ms := []*metric.Metric{}
 
 				if !r.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-
-						Value: float64(r.CreationTimestamp.Unix()),
-					})
+					ms = append(ms, metric.TimestampMetric(nil, nil, r.CreationTimestamp.Time))
 				}
 
 				return &metric.Family{
@@ -93,7 +90,34 @@ func resourceQuotaMetricFamilies(allowAnnotationsList, allowLabelsList []string)
 				}
 			}),
 		),
-		*generator.NewFamilyGeneratorWithStability(
+		*generator.NewOptInFamilyGenerator(
+			"kube_resourcequota_usage_ratio",
+			"Ratio of used to hard resource quota.",
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			wrapResourceQuotaFunc(func(r *v1.ResourceQuota) *metric.Family {
+				ms := []*metric.Metric{}
+
+				for res, used := range r.Status.Used {
+					hard, ok := r.Status.Hard[res]
+					// A ratio is meaningless without a positive hard limit.
+					if !ok || hard.MilliValue() <= 0 {
+						continue
+					}
+					ms = append(ms, &metric.Metric{
+						LabelKeys:   []string{"resource"},
+						LabelValues: []string{string(res)},
+						Value:       float64(used.MilliValue()) / float64(hard.MilliValue()),
+					})
+				}
+
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		),
+		*generator.NewLazyFamilyGenerator(
 			descResourceQuotaAnnotationsName,
 			descResourceQuotaAnnotationsHelp,
 			metric.Gauge,
@@ -115,7 +139,7 @@ func resourceQuotaMetricFamilies(allowAnnotationsList, allowLabelsList []string)
 				}
 			}),
 		),
-		*generator.NewFamilyGeneratorWithStability(
+		*generator.NewLazyFamilyGenerator(
 			descResourceQuotaLabelsName,
 			descResourceQuotaLabelsHelp,
 			metric.Gauge,
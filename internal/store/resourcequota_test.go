@@ -36,9 +36,11 @@ func TestResourceQuotaStore(t *testing.T) {
 	# TYPE kube_resourcequota gauge
 	# HELP kube_resourcequota_created [STABLE] Unix creation timestamp
 	# HELP kube_resourcequota_labels [STABLE] Kubernetes labels converted to Prometheus labels.
+	# HELP kube_resourcequota_usage_ratio Ratio of used to hard resource quota.
 	# TYPE kube_resourcequota_annotations gauge
 	# TYPE kube_resourcequota_created gauge
 	# TYPE kube_resourcequota_labels gauge
+	# TYPE kube_resourcequota_usage_ratio gauge
 	`
 	cases := []generateMetricsTestCase{
 		// Verify populating base metric and that metric for unset fields are skipped.
@@ -134,8 +136,46 @@ func TestResourceQuotaStore(t *testing.T) {
 			kube_resourcequota{namespace="testNS",resource="services.nodeports",resourcequota="quotaTest",type="used"} 1
 			kube_resourcequota{namespace="testNS",resource="storage",resourcequota="quotaTest",type="hard"} 1e+10
 			kube_resourcequota{namespace="testNS",resource="storage",resourcequota="quotaTest",type="used"} 9e+09
+			kube_resourcequota_usage_ratio{namespace="testNS",resource="configmaps",resourcequota="quotaTest"} 0.75
+			kube_resourcequota_usage_ratio{namespace="testNS",resource="cpu",resourcequota="quotaTest"} 0.4883720930232558
+			kube_resourcequota_usage_ratio{namespace="testNS",resource="memory",resourcequota="quotaTest"} 0.23809523809523808
+			kube_resourcequota_usage_ratio{namespace="testNS",resource="persistentvolumeclaims",resourcequota="quotaTest"} 0.6666666666666666
+			kube_resourcequota_usage_ratio{namespace="testNS",resource="pods",resourcequota="quotaTest"} 0.8888888888888888
+			kube_resourcequota_usage_ratio{namespace="testNS",resource="replicationcontrollers",resourcequota="quotaTest"} 0.8571428571428571
+			kube_resourcequota_usage_ratio{namespace="testNS",resource="resourcequotas",resourcequota="quotaTest"} 0.8333333333333334
+			kube_resourcequota_usage_ratio{namespace="testNS",resource="secrets",resourcequota="quotaTest"} 0.8
+			kube_resourcequota_usage_ratio{namespace="testNS",resource="services",resourcequota="quotaTest"} 0.875
+			kube_resourcequota_usage_ratio{namespace="testNS",resource="services.loadbalancers",resourcequota="quotaTest"} 0
+			kube_resourcequota_usage_ratio{namespace="testNS",resource="services.nodeports",resourcequota="quotaTest"} 0.5
+			kube_resourcequota_usage_ratio{namespace="testNS",resource="storage",resourcequota="quotaTest"} 0.9
 			`,
 		},
+		// Verify usage ratio is skipped for resources without a positive hard limit.
+		{
+			Obj: &v1.ResourceQuota{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "quotaTest",
+					Namespace: "testNS",
+				},
+				Status: v1.ResourceQuotaStatus{
+					Hard: v1.ResourceList{
+						v1.ResourceCPU:  resource.MustParse("0"),
+						v1.ResourcePods: resource.MustParse("4"),
+					},
+					Used: v1.ResourceList{
+						v1.ResourceCPU:    resource.MustParse("1"),
+						v1.ResourceMemory: resource.MustParse("1G"),
+						v1.ResourcePods:   resource.MustParse("1"),
+					},
+				},
+			},
+			Want: `
+			# HELP kube_resourcequota_usage_ratio Ratio of used to hard resource quota.
+			# TYPE kube_resourcequota_usage_ratio gauge
+			kube_resourcequota_usage_ratio{namespace="testNS",resource="pods",resourcequota="quotaTest"} 0.25
+			`,
+			MetricNames: []string{"kube_resourcequota_usage_ratio"},
+		},
 		// Verify kube_resourcequota_annotations and kube_resourcequota_labels are shown.
 		{
 			AllowAnnotationsList: []string{
@@ -41,7 +41,7 @@ var (
 
 func roleMetricFamilies(allowAnnotationsList, allowLabelsList []string) []generator.FamilyGenerator {
 	return []generator.FamilyGenerator{
-		*generator.NewFamilyGeneratorWithStability(
+		*generator.NewLazyFamilyGenerator(
 			descRoleAnnotationsName,
 			descRoleAnnotationsHelp,
 			metric.Gauge,
@@ -63,7 +63,7 @@ func roleMetricFamilies(allowAnnotationsList, allowLabelsList []string) []genera
 				}
 			}),
 		),
-		*generator.NewFamilyGeneratorWithStability(
+		*generator.NewLazyFamilyGenerator(
 			descRoleLabelsName,
 			descRoleLabelsHelp,
 			metric.Gauge,
@@ -111,11 +111,7 @@ func roleMetricFamilies(allowAnnotationsList, allowLabelsList []string) []genera
 				ms := []*metric.Metric{}
 
 				if !r.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						LabelKeys:   []string{},
-						LabelValues: []string{},
-						Value:       float64(r.CreationTimestamp.Unix()),
-					})
+					ms = append(ms, metric.TimestampMetric([]string{}, []string{}, r.CreationTimestamp.Time))
 				}
 
 				return &metric.Family{
@@ -135,7 +131,75 @@ func roleMetricFamilies(allowAnnotationsList, allowLabelsList []string) []genera
 				}
 			}),
 		),
+		*generator.NewFamilyGeneratorWithStability(
+			"kube_role_rules",
+			"Number of policy rules of the role.",
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			wrapRoleFunc(func(r *rbacv1.Role) *metric.Family {
+				return &metric.Family{
+					Metrics: []*metric.Metric{{
+						Value: float64(len(r.Rules)),
+					}},
+				}
+			}),
+		),
+		*generator.NewFamilyGeneratorWithStability(
+			"kube_role_wildcard_rules",
+			"Number of policy rules of the role which contain the '*' wildcard in the field.",
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			wrapRoleFunc(func(r *rbacv1.Role) *metric.Family {
+				return &metric.Family{
+					Metrics: wildcardRuleMetrics(r.Rules, rolePolicyRuleFields),
+				}
+			}),
+		),
+	}
+}
+
+// policyRuleField is a field of policy rules which can contain the '*'
+// wildcard.
+type policyRuleField struct {
+	name   string
+	values func(rbacv1.PolicyRule) []string
+}
+
+var (
+	rolePolicyRuleFields = []policyRuleField{
+		{"verbs", func(r rbacv1.PolicyRule) []string { return r.Verbs }},
+		{"api_groups", func(r rbacv1.PolicyRule) []string { return r.APIGroups }},
+		{"resources", func(r rbacv1.PolicyRule) []string { return r.Resources }},
+	}
+	// Only cluster roles can grant access to non-resource URLs.
+	clusterRolePolicyRuleFields = append(rolePolicyRuleFields[:len(rolePolicyRuleFields):len(rolePolicyRuleFields)],
+		policyRuleField{"non_resource_urls", func(r rbacv1.PolicyRule) []string { return r.NonResourceURLs }},
+	)
+)
+
+// wildcardRuleMetrics returns the number of rules which contain the '*'
+// wildcard in each of the fields.
+func wildcardRuleMetrics(rules []rbacv1.PolicyRule, fields []policyRuleField) []*metric.Metric {
+	ms := make([]*metric.Metric, len(fields))
+	for i, field := range fields {
+		count := 0
+		for _, rule := range rules {
+			for _, v := range field.values(rule) {
+				if v == rbacv1.ResourceAll {
+					count++
+					break
+				}
+			}
+		}
+		ms[i] = &metric.Metric{
+			LabelKeys:   []string{"field"},
+			LabelValues: []string{field.name},
+			Value:       float64(count),
+		}
 	}
+	return ms
 }
 
 func createRoleListWatch(kubeClient clientset.Interface, ns string, fieldSelector string) cache.ListerWatcher {
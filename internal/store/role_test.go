@@ -94,6 +94,37 @@ func TestRoleStore(t *testing.T) {
 				`,
 			MetricNames: []string{"kube_role_info", "kube_role_created", "kube_role_metadata_resource_version"},
 		},
+		{
+			Obj: &rbacv1.Role{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "role3",
+					Namespace: "ns3",
+				},
+				Rules: []rbacv1.PolicyRule{
+					{
+						APIGroups: []string{""},
+						Resources: []string{"*"},
+						Verbs:     []string{"get", "list"},
+					},
+					{
+						APIGroups: []string{"apps"},
+						Resources: []string{"deployments"},
+						Verbs:     []string{"*"},
+					},
+				},
+			},
+			Want: `
+				# HELP kube_role_rules Number of policy rules of the role.
+				# HELP kube_role_wildcard_rules Number of policy rules of the role which contain the '*' wildcard in the field.
+				# TYPE kube_role_rules gauge
+				# TYPE kube_role_wildcard_rules gauge
+				kube_role_rules{namespace="ns3",role="role3"} 2
+				kube_role_wildcard_rules{field="api_groups",namespace="ns3",role="role3"} 0
+				kube_role_wildcard_rules{field="resources",namespace="ns3",role="role3"} 1
+				kube_role_wildcard_rules{field="verbs",namespace="ns3",role="role3"} 1
+				`,
+			MetricNames: []string{"kube_role_rules", "kube_role_wildcard_rules"},
+		},
 	}
 	for i, c := range cases {
 		c.Func = generator.ComposeMetricGenFuncs(roleMetricFamilies(c.AllowAnnotationsList, c.AllowLabelsList))
@@ -41,7 +41,7 @@ var (
 
 func roleBindingMetricFamilies(allowAnnotationsList, allowLabelsList []string) []generator.FamilyGenerator {
 	return []generator.FamilyGenerator{
-		*generator.NewFamilyGeneratorWithStability(
+		*generator.NewLazyFamilyGenerator(
 			descRoleBindingAnnotationsName,
 			descRoleBindingAnnotationsHelp,
 			metric.Gauge,
@@ -63,7 +63,7 @@ func roleBindingMetricFamilies(allowAnnotationsList, allowLabelsList []string) [
 				}
 			}),
 		),
-		*generator.NewFamilyGeneratorWithStability(
+		*generator.NewLazyFamilyGenerator(
 			descRoleBindingLabelsName,
 			descRoleBindingLabelsHelp,
 			metric.Gauge,
@@ -113,11 +113,7 @@ func roleBindingMetricFamilies(allowAnnotationsList, allowLabelsList []string) [
 				ms := []*metric.Metric{}
 
 				if !r.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						LabelKeys:   []string{},
-						LabelValues: []string{},
-						Value:       float64(r.CreationTimestamp.Unix()),
-					})
+					ms = append(ms, metric.TimestampMetric([]string{}, []string{}, r.CreationTimestamp.Time))
 				}
 
 				return &metric.Family{
@@ -137,7 +133,41 @@ func roleBindingMetricFamilies(allowAnnotationsList, allowLabelsList []string) [
 				}
 			}),
 		),
+		*generator.NewFamilyGeneratorWithStability(
+			"kube_rolebinding_subjects",
+			"Number of subjects of the rolebinding by kind.",
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			wrapRoleBindingFunc(func(r *rbacv1.RoleBinding) *metric.Family {
+				return &metric.Family{
+					Metrics: subjectKindMetrics(r.Subjects),
+				}
+			}),
+		),
+	}
+}
+
+// subjectKinds are the kinds of the subjects of role bindings.
+var subjectKinds = []string{rbacv1.UserKind, rbacv1.GroupKind, rbacv1.ServiceAccountKind}
+
+// subjectKindMetrics returns the number of subjects of each kind.
+func subjectKindMetrics(subjects []rbacv1.Subject) []*metric.Metric {
+	ms := make([]*metric.Metric, len(subjectKinds))
+	for i, kind := range subjectKinds {
+		count := 0
+		for _, s := range subjects {
+			if s.Kind == kind {
+				count++
+			}
+		}
+		ms[i] = &metric.Metric{
+			LabelKeys:   []string{"kind"},
+			LabelValues: []string{kind},
+			Value:       float64(count),
+		}
 	}
+	return ms
 }
 
 func createRoleBindingListWatch(kubeClient clientset.Interface, ns string, fieldSelector string) cache.ListerWatcher {
@@ -104,6 +104,27 @@ func TestRoleBindingStore(t *testing.T) {
 				`,
 			MetricNames: []string{"kube_rolebinding_info", "kube_rolebinding_created", "kube_rolebinding_metadata_resource_version"},
 		},
+		{
+			Obj: &rbacv1.RoleBinding{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "rolebinding3",
+					Namespace: "ns3",
+				},
+				Subjects: []rbacv1.Subject{
+					{Kind: rbacv1.ServiceAccountKind, Name: "sa1", Namespace: "ns3"},
+					{Kind: rbacv1.ServiceAccountKind, Name: "sa2", Namespace: "ns3"},
+					{Kind: rbacv1.GroupKind, Name: "developers"},
+				},
+			},
+			Want: `
+				# HELP kube_rolebinding_subjects Number of subjects of the rolebinding by kind.
+				# TYPE kube_rolebinding_subjects gauge
+				kube_rolebinding_subjects{kind="Group",namespace="ns3",rolebinding="rolebinding3"} 1
+				kube_rolebinding_subjects{kind="ServiceAccount",namespace="ns3",rolebinding="rolebinding3"} 2
+				kube_rolebinding_subjects{kind="User",namespace="ns3",rolebinding="rolebinding3"} 0
+				`,
+			MetricNames: []string{"kube_rolebinding_subjects"},
+		},
 	}
 	for i, c := range cases {
 		c.Func = generator.ComposeMetricGenFuncs(roleBindingMetricFamilies(c.AllowAnnotationsList, c.AllowLabelsList))
@@ -0,0 +1,237 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"context"
+
+	basemetrics "k8s.io/component-base/metrics"
+
+	"k8s.io/kube-state-metrics/v2/pkg/metric"
+	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
+
+	nodev1 "k8s.io/api/node/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+var (
+	descRuntimeClassAnnotationsName     = "kube_runtimeclass_annotations"
+	descRuntimeClassAnnotationsHelp     = "Kubernetes annotations converted to Prometheus labels."
+	descRuntimeClassLabelsName          = "kube_runtimeclass_labels"
+	descRuntimeClassLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
+	descRuntimeClassLabelsDefaultLabels = []string{"runtimeclass"}
+)
+
+func runtimeClassMetricFamilies(allowAnnotationsList, allowLabelsList []string) []generator.FamilyGenerator {
+	return []generator.FamilyGenerator{
+		*generator.NewFamilyGeneratorWithStability(
+			"kube_runtimeclass_info",
+			"Information about runtimeclass.",
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			wrapRuntimeClassFunc(func(r *nodev1.RuntimeClass) *metric.Family {
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							LabelKeys:   []string{"handler"},
+							LabelValues: []string{r.Handler},
+							Value:       1,
+						},
+					},
+				}
+			}),
+		),
+		*generator.NewFamilyGeneratorWithStability(
+			"kube_runtimeclass_overhead",
+			"The fixed overhead of pods of the runtimeclass, in the base unit of the resource.",
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			wrapRuntimeClassFunc(func(r *nodev1.RuntimeClass) *metric.Family {
+				ms := []*metric.Metric{}
+
+				if r.Overhead == nil {
+					return &metric.Family{
+						Metrics: ms,
+					}
+				}
+
+				for resourceName, val := range r.Overhead.PodFixed {
+					unit, value, ok := canonicalResourceQuantity(resourceName, val)
+					if !ok {
+						continue
+					}
+					ms = append(ms, &metric.Metric{
+						LabelKeys:   []string{"resource", "unit"},
+						LabelValues: []string{SanitizeLabelName(string(resourceName)), string(unit)},
+						Value:       value,
+					})
+				}
+
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		),
+		*generator.NewFamilyGeneratorWithStability(
+			"kube_runtimeclass_scheduling_node_selector",
+			"Node labels required for nodes to support the runtimeclass.",
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			wrapRuntimeClassFunc(func(r *nodev1.RuntimeClass) *metric.Family {
+				ms := []*metric.Metric{}
+
+				if r.Scheduling == nil {
+					return &metric.Family{
+						Metrics: ms,
+					}
+				}
+
+				for key, value := range r.Scheduling.NodeSelector {
+					ms = append(ms, &metric.Metric{
+						LabelKeys:   []string{"key", "value"},
+						LabelValues: []string{key, value},
+						Value:       1,
+					})
+				}
+
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		),
+		*generator.NewFamilyGeneratorWithStability(
+			"kube_runtimeclass_scheduling_tolerations",
+			"Tolerations added to pods of the runtimeclass.",
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			wrapRuntimeClassFunc(func(r *nodev1.RuntimeClass) *metric.Family {
+				ms := []*metric.Metric{}
+
+				if r.Scheduling == nil {
+					return &metric.Family{
+						Metrics: ms,
+					}
+				}
+
+				for _, t := range r.Scheduling.Tolerations {
+					ms = append(ms, &metric.Metric{
+						LabelKeys:   []string{"key", "operator", "value", "effect"},
+						LabelValues: []string{t.Key, string(t.Operator), t.Value, string(t.Effect)},
+						Value:       1,
+					})
+				}
+
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		),
+		*generator.NewFamilyGeneratorWithStability(
+			"kube_runtimeclass_created",
+			"Unix creation timestamp",
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			wrapRuntimeClassFunc(func(r *nodev1.RuntimeClass) *metric.Family {
+				ms := []*metric.Metric{}
+				if !r.CreationTimestamp.IsZero() {
+					ms = append(ms, metric.TimestampMetric(nil, nil, r.CreationTimestamp.Time))
+				}
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		),
+		*generator.NewLazyFamilyGenerator(
+			descRuntimeClassAnnotationsName,
+			descRuntimeClassAnnotationsHelp,
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			wrapRuntimeClassFunc(func(r *nodev1.RuntimeClass) *metric.Family {
+				if len(allowAnnotationsList) == 0 {
+					return &metric.Family{}
+				}
+				annotationKeys, annotationValues := createPrometheusLabelKeysValues("annotation", r.Annotations, allowAnnotationsList)
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							LabelKeys:   annotationKeys,
+							LabelValues: annotationValues,
+							Value:       1,
+						},
+					},
+				}
+			}),
+		),
+		*generator.NewLazyFamilyGenerator(
+			descRuntimeClassLabelsName,
+			descRuntimeClassLabelsHelp,
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			wrapRuntimeClassFunc(func(r *nodev1.RuntimeClass) *metric.Family {
+				if len(allowLabelsList) == 0 {
+					return &metric.Family{}
+				}
+				labelKeys, labelValues := createPrometheusLabelKeysValues("label", r.Labels, allowLabelsList)
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							LabelKeys:   labelKeys,
+							LabelValues: labelValues,
+							Value:       1,
+						},
+					},
+				}
+			}),
+		),
+	}
+}
+
+func wrapRuntimeClassFunc(f func(*nodev1.RuntimeClass) *metric.Family) func(interface{}) *metric.Family {
+	return func(obj interface{}) *metric.Family {
+		runtimeClass := obj.(*nodev1.RuntimeClass)
+
+		metricFamily := f(runtimeClass)
+
+		for _, m := range metricFamily.Metrics {
+			m.LabelKeys, m.LabelValues = mergeKeyValues(descRuntimeClassLabelsDefaultLabels, []string{runtimeClass.Name}, m.LabelKeys, m.LabelValues)
+		}
+
+		return metricFamily
+	}
+}
+
+func createRuntimeClassListWatch(kubeClient clientset.Interface, _ string, _ string) cache.ListerWatcher {
+	return &cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			return kubeClient.NodeV1().RuntimeClasses().List(context.TODO(), opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			return kubeClient.NodeV1().RuntimeClasses().Watch(context.TODO(), opts)
+		},
+	}
+}
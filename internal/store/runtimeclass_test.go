@@ -0,0 +1,114 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	nodev1 "k8s.io/api/node/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
+)
+
+func TestRuntimeClassStore(t *testing.T) {
+	startTime := 1501569018
+	metav1StartTime := metav1.Unix(int64(startTime), 0)
+
+	cases := []generateMetricsTestCase{
+		{
+			Obj: &nodev1.RuntimeClass{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "gvisor",
+					CreationTimestamp: metav1StartTime,
+				},
+				Handler: "runsc",
+				Overhead: &nodev1.Overhead{
+					PodFixed: v1.ResourceList{
+						v1.ResourceCPU:    resource.MustParse("250m"),
+						v1.ResourceMemory: resource.MustParse("120Mi"),
+					},
+				},
+				Scheduling: &nodev1.Scheduling{
+					NodeSelector: map[string]string{
+						"runtime": "gvisor",
+					},
+					Tolerations: []v1.Toleration{
+						{Key: "runtime", Operator: v1.TolerationOpEqual, Value: "gvisor", Effect: v1.TaintEffectNoSchedule},
+					},
+				},
+			},
+			Want: `
+					# HELP kube_runtimeclass_created Unix creation timestamp
+					# HELP kube_runtimeclass_info Information about runtimeclass.
+					# HELP kube_runtimeclass_overhead The fixed overhead of pods of the runtimeclass, in the base unit of the resource.
+					# HELP kube_runtimeclass_scheduling_node_selector Node labels required for nodes to support the runtimeclass.
+					# HELP kube_runtimeclass_scheduling_tolerations Tolerations added to pods of the runtimeclass.
+					# TYPE kube_runtimeclass_created gauge
+					# TYPE kube_runtimeclass_info gauge
+					# TYPE kube_runtimeclass_overhead gauge
+					# TYPE kube_runtimeclass_scheduling_node_selector gauge
+					# TYPE kube_runtimeclass_scheduling_tolerations gauge
+					kube_runtimeclass_created{runtimeclass="gvisor"} 1.501569018e+09
+					kube_runtimeclass_info{runtimeclass="gvisor",handler="runsc"} 1
+					kube_runtimeclass_overhead{runtimeclass="gvisor",resource="cpu",unit="core"} 0.25
+					kube_runtimeclass_overhead{runtimeclass="gvisor",resource="memory",unit="byte"} 1.2582912e+08
+					kube_runtimeclass_scheduling_node_selector{runtimeclass="gvisor",key="runtime",value="gvisor"} 1
+					kube_runtimeclass_scheduling_tolerations{runtimeclass="gvisor",key="runtime",operator="Equal",value="gvisor",effect="NoSchedule"} 1
+				`,
+			MetricNames: []string{
+				"kube_runtimeclass_created", "kube_runtimeclass_info", "kube_runtimeclass_overhead", "kube_runtimeclass_scheduling_node_selector", "kube_runtimeclass_scheduling_tolerations",
+			},
+		},
+		{
+			AllowLabelsList: []string{
+				"app",
+			},
+			Obj: &nodev1.RuntimeClass{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "runc",
+					Labels: map[string]string{
+						"app": "containerd",
+					},
+				},
+				Handler: "runc",
+			},
+			Want: `
+					# HELP kube_runtimeclass_info Information about runtimeclass.
+					# HELP kube_runtimeclass_labels Kubernetes labels converted to Prometheus labels.
+					# HELP kube_runtimeclass_overhead The fixed overhead of pods of the runtimeclass, in the base unit of the resource.
+					# TYPE kube_runtimeclass_info gauge
+					# TYPE kube_runtimeclass_labels gauge
+					# TYPE kube_runtimeclass_overhead gauge
+					kube_runtimeclass_info{runtimeclass="runc",handler="runc"} 1
+					kube_runtimeclass_labels{runtimeclass="runc",label_app="containerd"} 1
+				`,
+			MetricNames: []string{
+				"kube_runtimeclass_info", "kube_runtimeclass_labels", "kube_runtimeclass_overhead",
+			},
+		},
+	}
+	for i, c := range cases {
+		c.Func = generator.ComposeMetricGenFuncs(runtimeClassMetricFamilies(nil, c.AllowLabelsList))
+		c.Headers = generator.ExtractMetricFamilyHeaders(runtimeClassMetricFamilies(nil, c.AllowLabelsList))
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
+		}
+	}
+}
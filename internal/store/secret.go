@@ -75,7 +75,43 @@ func secretMetricFamilies(allowAnnotationsList, allowLabelsList []string) []gene
 				}
 			}),
 		),
-		*generator.NewFamilyGeneratorWithStability(
+		*generator.NewOptInFamilyGenerator(
+			"kube_secret_data_keys",
+			"Number of data keys of the secret.",
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			wrapSecretFunc(func(s *v1.Secret) *metric.Family {
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							Value: float64(len(s.Data)),
+						},
+					},
+				}
+			}),
+		),
+		*generator.NewOptInFamilyGenerator(
+			"kube_secret_data_bytes",
+			"Total size of the data values of the secret in bytes.",
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			wrapSecretFunc(func(s *v1.Secret) *metric.Family {
+				size := 0
+				for _, v := range s.Data {
+					size += len(v)
+				}
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							Value: float64(size),
+						},
+					},
+				}
+			}),
+		),
+		*generator.NewLazyFamilyGenerator(
 			descSecretAnnotationsName,
 			descSecretAnnotationsHelp,
 			metric.Gauge,
@@ -98,7 +134,7 @@ func secretMetricFamilies(allowAnnotationsList, allowLabelsList []string) []gene
 
 			}),
 		),
-		*generator.NewFamilyGeneratorWithStability(
+		*generator.NewLazyFamilyGenerator(
 			descSecretLabelsName,
 			descSecretLabelsHelp,
 			metric.Gauge,
@@ -131,9 +167,7 @@ func secretMetricFamilies(allowAnnotationsList, allowLabelsList []string) []gene
 				ms := []*metric.Metric{}
 
 				if !s.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						Value: float64(s.CreationTimestamp.Unix()),
-					})
+					ms = append(ms, metric.TimestampMetric(nil, nil, s.CreationTimestamp.Time))
 				}
 
 				return &metric.Family{
@@ -111,6 +111,28 @@ func TestSecretStore(t *testing.T) {
 `,
 			MetricNames: []string{"kube_secret_info", "kube_secret_metadata_resource_version", "kube_secret_created", "kube_secret_labels", "kube_secret_type"},
 		},
+		{
+			Obj: &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "secret4",
+					Namespace: "ns4",
+				},
+				Type: v1.SecretTypeTLS,
+				Data: map[string][]byte{
+					"tls.crt": []byte("certificate"),
+					"tls.key": []byte("key"),
+				},
+			},
+			Want: `
+				# HELP kube_secret_data_bytes Total size of the data values of the secret in bytes.
+				# HELP kube_secret_data_keys Number of data keys of the secret.
+				# TYPE kube_secret_data_bytes gauge
+				# TYPE kube_secret_data_keys gauge
+				kube_secret_data_bytes{namespace="ns4",secret="secret4"} 14
+				kube_secret_data_keys{namespace="ns4",secret="secret4"} 2
+`,
+			MetricNames: []string{"kube_secret_data_bytes", "kube_secret_data_keys"},
+		},
 	}
 	for i, c := range cases {
 		c.Func = generator.ComposeMetricGenFuncs(secretMetricFamilies(nil, nil))
@@ -18,6 +18,7 @@ package store
 
 import (
 	"context"
+	"strconv"
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -64,11 +65,7 @@ func serviceMetricFamilies(allowAnnotationsList, allowLabelsList []string) []gen
 			"",
 			wrapSvcFunc(func(s *v1.Service) *metric.Family {
 				if !s.CreationTimestamp.IsZero() {
-					m := metric.Metric{
-						LabelKeys:   nil,
-						LabelValues: nil,
-						Value:       float64(s.CreationTimestamp.Unix()),
-					}
+					m := *metric.TimestampMetric(nil, nil, s.CreationTimestamp.Time)
 					return &metric.Family{Metrics: []*metric.Metric{&m}}
 				}
 				return &metric.Family{Metrics: []*metric.Metric{}}
@@ -90,7 +87,7 @@ func serviceMetricFamilies(allowAnnotationsList, allowLabelsList []string) []gen
 				return &metric.Family{Metrics: []*metric.Metric{&m}}
 			}),
 		),
-		*generator.NewFamilyGeneratorWithStability(
+		*generator.NewLazyFamilyGenerator(
 			descServiceAnnotationsName,
 			descServiceAnnotationsHelp,
 			metric.Gauge,
@@ -109,7 +106,7 @@ func serviceMetricFamilies(allowAnnotationsList, allowLabelsList []string) []gen
 				return &metric.Family{Metrics: []*metric.Metric{&m}}
 			}),
 		),
-		*generator.NewFamilyGeneratorWithStability(
+		*generator.NewLazyFamilyGenerator(
 			descServiceLabelsName,
 			descServiceLabelsHelp,
 			metric.Gauge,
@@ -184,6 +181,98 @@ func serviceMetricFamilies(allowAnnotationsList, allowLabelsList []string) []gen
 				}
 			}),
 		),
+		*generator.NewFamilyGeneratorWithStability(
+			"kube_service_status_load_balancer_ingress_port",
+			"Port of a service load balancer ingress point, with the error of the port if it failed to be provisioned.",
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			wrapSvcFunc(func(s *v1.Service) *metric.Family {
+				ms := []*metric.Metric{}
+
+				for _, ingress := range s.Status.LoadBalancer.Ingress {
+					for _, port := range ingress.Ports {
+						portError := ""
+						if port.Error != nil {
+							portError = *port.Error
+						}
+						ms = append(ms, &metric.Metric{
+							LabelKeys:   []string{"ip", "hostname", "port", "protocol", "error"},
+							LabelValues: []string{ingress.IP, ingress.Hostname, strconv.Itoa(int(port.Port)), string(port.Protocol), portError},
+							Value:       1,
+						})
+					}
+				}
+
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		),
+		*generator.NewFamilyGeneratorWithStability(
+			"kube_service_status_load_balancer_provisioned",
+			"Describes whether the load balancer of a service of type LoadBalancer has at least one ingress point.",
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			wrapSvcFunc(func(s *v1.Service) *metric.Family {
+				if s.Spec.Type != v1.ServiceTypeLoadBalancer {
+					return &metric.Family{
+						Metrics: []*metric.Metric{},
+					}
+				}
+
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							Value: boolFloat64(len(s.Status.LoadBalancer.Ingress) > 0),
+						},
+					},
+				}
+			}),
+		),
+		*generator.NewFamilyGeneratorWithStability(
+			"kube_service_status_condition",
+			"The current status conditions of a service.",
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			wrapSvcFunc(func(s *v1.Service) *metric.Family {
+				return &metric.Family{
+					Metrics: statusConditionMetrics(s.Status.Conditions, func(c metav1.Condition) (string, v1.ConditionStatus) {
+						return string(c.Type), v1.ConditionStatus(c.Status)
+					}),
+				}
+			}),
+		),
+		*generator.NewFamilyGeneratorWithStability(
+			"kube_service_spec_traffic_policy",
+			"The external and internal traffic policies of a service.",
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			wrapSvcFunc(func(s *v1.Service) *metric.Family {
+				internalTrafficPolicy := ""
+				if s.Spec.InternalTrafficPolicy != nil {
+					internalTrafficPolicy = string(*s.Spec.InternalTrafficPolicy)
+				}
+				if s.Spec.ExternalTrafficPolicy == "" && internalTrafficPolicy == "" {
+					return &metric.Family{
+						Metrics: []*metric.Metric{},
+					}
+				}
+
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							LabelKeys:   []string{"external_traffic_policy", "internal_traffic_policy"},
+							LabelValues: []string{string(s.Spec.ExternalTrafficPolicy), internalTrafficPolicy},
+							Value:       1,
+						},
+					},
+				}
+			}),
+		),
 	}
 }
 
@@ -27,6 +27,9 @@ import (
 )
 
 func TestServiceStore(t *testing.T) {
+	internalTrafficPolicyCluster := v1.ServiceInternalTrafficPolicyCluster
+	portError := "MixedProtocolNotSupported"
+
 	// Fixed metadata on type and help text. We prepend this to every expected
 	// output so we only have to modify a single place when doing adjustments.
 	const metadata = `
@@ -44,6 +47,14 @@ func TestServiceStore(t *testing.T) {
 		# TYPE kube_service_spec_external_ip gauge
 		# HELP kube_service_status_load_balancer_ingress [STABLE] Service load balancer ingress status
 		# TYPE kube_service_status_load_balancer_ingress gauge
+		# HELP kube_service_status_load_balancer_ingress_port Port of a service load balancer ingress point, with the error of the port if it failed to be provisioned.
+		# TYPE kube_service_status_load_balancer_ingress_port gauge
+		# HELP kube_service_status_load_balancer_provisioned Describes whether the load balancer of a service of type LoadBalancer has at least one ingress point.
+		# TYPE kube_service_status_load_balancer_provisioned gauge
+		# HELP kube_service_status_condition The current status conditions of a service.
+		# TYPE kube_service_status_condition gauge
+		# HELP kube_service_spec_traffic_policy The external and internal traffic policies of a service.
+		# TYPE kube_service_spec_traffic_policy gauge
 	`
 	cases := []generateMetricsTestCase{
 		{
@@ -129,6 +140,7 @@ func TestServiceStore(t *testing.T) {
 				kube_service_created{namespace="default",service="test-service3",uid="uid3"} 1.5e+09
 				kube_service_info{cluster_ip="1.2.3.6",external_name="",load_balancer_ip="1.2.3.7",namespace="default",service="test-service3",uid="uid3"} 1
 				kube_service_spec_type{namespace="default",service="test-service3",type="LoadBalancer",uid="uid3"} 1
+				kube_service_status_load_balancer_provisioned{namespace="default",service="test-service3",uid="uid3"} 0
 `,
 		},
 		{
@@ -165,7 +177,9 @@ func TestServiceStore(t *testing.T) {
 					},
 				},
 				Spec: v1.ServiceSpec{
-					Type: v1.ServiceTypeLoadBalancer,
+					Type:                  v1.ServiceTypeLoadBalancer,
+					ExternalTrafficPolicy: v1.ServiceExternalTrafficPolicyTypeLocal,
+					InternalTrafficPolicy: &internalTrafficPolicyCluster,
 				},
 				Status: v1.ServiceStatus{
 					LoadBalancer: v1.LoadBalancerStatus{
@@ -173,6 +187,17 @@ func TestServiceStore(t *testing.T) {
 							{
 								IP:       "1.2.3.8",
 								Hostname: "www.example.com",
+								Ports: []v1.PortStatus{
+									{
+										Port:     443,
+										Protocol: v1.ProtocolTCP,
+									},
+									{
+										Port:     53,
+										Protocol: v1.ProtocolUDP,
+										Error:    &portError,
+									},
+								},
 							},
 						},
 					},
@@ -182,7 +207,11 @@ func TestServiceStore(t *testing.T) {
 				kube_service_created{namespace="default",service="test-service5",uid="uid5"} 1.5e+09
 				kube_service_info{cluster_ip="",external_name="",load_balancer_ip="",namespace="default",service="test-service5",uid="uid5"} 1
 				kube_service_spec_type{namespace="default",service="test-service5",type="LoadBalancer",uid="uid5"} 1
+				kube_service_spec_traffic_policy{external_traffic_policy="Local",internal_traffic_policy="Cluster",namespace="default",service="test-service5",uid="uid5"} 1
 				kube_service_status_load_balancer_ingress{hostname="www.example.com",ip="1.2.3.8",namespace="default",service="test-service5",uid="uid5"} 1
+				kube_service_status_load_balancer_ingress_port{error="",hostname="www.example.com",ip="1.2.3.8",namespace="default",port="443",protocol="TCP",service="test-service5",uid="uid5"} 1
+				kube_service_status_load_balancer_ingress_port{error="MixedProtocolNotSupported",hostname="www.example.com",ip="1.2.3.8",namespace="default",port="53",protocol="UDP",service="test-service5",uid="uid5"} 1
+				kube_service_status_load_balancer_provisioned{namespace="default",service="test-service5",uid="uid5"} 1
 			`,
 		},
 		{
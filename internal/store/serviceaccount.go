@@ -86,11 +86,7 @@ func createServiceAccountCreatedFamilyGenerator() generator.FamilyGenerator {
 			var ms []*metric.Metric
 
 			if !sa.CreationTimestamp.IsZero() {
-				ms = append(ms, &metric.Metric{
-					LabelKeys:   []string{},
-					LabelValues: []string{},
-					Value:       float64(sa.CreationTimestamp.Unix()),
-				})
+				ms = append(ms, metric.TimestampMetric([]string{}, []string{}, sa.CreationTimestamp.Time))
 			}
 
 			return &metric.Family{
@@ -111,11 +107,7 @@ func createServiceAccountDeletedFamilyGenerator() generator.FamilyGenerator {
 			var ms []*metric.Metric
 
 			if sa.DeletionTimestamp != nil && !sa.DeletionTimestamp.IsZero() {
-				ms = append(ms, &metric.Metric{
-					LabelKeys:   []string{},
-					LabelValues: []string{},
-					Value:       float64(sa.DeletionTimestamp.Unix()),
-				})
+				ms = append(ms, metric.TimestampMetric([]string{}, []string{}, sa.DeletionTimestamp.Time))
 			}
 
 			return &metric.Family{
@@ -176,7 +168,7 @@ func createServiceAccountImagePullSecretFamilyGenerator() generator.FamilyGenera
 }
 
 func createServiceAccountAnnotationsGenerator(allowAnnotations []string) generator.FamilyGenerator {
-	return *generator.NewFamilyGeneratorWithStability(
+	return *generator.NewLazyFamilyGenerator(
 		"kube_serviceaccount_annotations",
 		"Kubernetes annotations converted to Prometheus labels.",
 		metric.Gauge,
@@ -200,7 +192,7 @@ func createServiceAccountAnnotationsGenerator(allowAnnotations []string) generat
 }
 
 func createServiceAccountLabelsGenerator(allowLabelsList []string) generator.FamilyGenerator {
-	return *generator.NewFamilyGeneratorWithStability(
+	return *generator.NewLazyFamilyGenerator(
 		"kube_serviceaccount_labels",
 		"Kubernetes labels converted to Prometheus labels.",
 		metric.Gauge,
@@ -0,0 +1,325 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+var invalidSnapshotFileCharRE = regexp.MustCompile(`[^a-zA-Z0-9._-]`)
+
+// snapshotStore wraps a cache.Store and keeps the objects passed to it in a
+// second store, so they can be written to snapshots. It also keeps the last
+// resourceVersion it observed.
+type snapshotStore struct {
+	cache.Store
+	namespace string
+
+	mutex           sync.Mutex
+	objects         cache.Store
+	resourceVersion string
+}
+
+// Add implements the Add method of the store interface.
+func (s *snapshotStore) Add(obj interface{}) error {
+	if err := s.update(obj, s.objects.Add); err != nil {
+		return err
+	}
+	return s.Store.Add(obj)
+}
+
+// Update implements the Update method of the store interface.
+func (s *snapshotStore) Update(obj interface{}) error {
+	if err := s.update(obj, s.objects.Update); err != nil {
+		return err
+	}
+	return s.Store.Update(obj)
+}
+
+// Delete implements the Delete method of the store interface.
+func (s *snapshotStore) Delete(obj interface{}) error {
+	if err := s.update(obj, s.objects.Delete); err != nil {
+		return err
+	}
+	return s.Store.Delete(obj)
+}
+
+// Replace implements the Replace method of the store interface.
+func (s *snapshotStore) Replace(list []interface{}, resourceVersion string) error {
+	s.mutex.Lock()
+	err := s.objects.Replace(list, resourceVersion)
+	if err == nil {
+		s.resourceVersion = resourceVersion
+	}
+	s.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+	return s.Store.Replace(list, resourceVersion)
+}
+
+// update applies the change of obj to the objects and keeps the
+// resourceVersion of obj.
+func (s *snapshotStore) update(obj interface{}, apply func(obj interface{}) error) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := apply(obj); err != nil {
+		return err
+	}
+	if m, err := meta.Accessor(obj); err == nil && m.GetResourceVersion() != "" {
+		s.resourceVersion = m.GetResourceVersion()
+	}
+	return nil
+}
+
+// state returns the objects of the store and the last observed
+// resourceVersion.
+func (s *snapshotStore) state() ([]interface{}, string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.objects.List(), s.resourceVersion
+}
+
+// snapshotWriter periodically writes the objects of the tracked stores to one
+// JSON file per resource.
+type snapshotWriter struct {
+	dir string
+	// resourceVersions is whether the last observed resourceVersion of each
+	// namespace is written next to the objects, to resume watches from.
+	resourceVersions bool
+
+	mutex sync.Mutex
+	// stores holds the tracked stores per resource.
+	stores map[string][]*snapshotStore
+}
+
+func newSnapshotWriter(dir string) *snapshotWriter {
+	return &snapshotWriter{
+		dir:    dir,
+		stores: map[string][]*snapshotStore{},
+	}
+}
+
+// track returns a store wrapping the given one of the namespace whose objects
+// are written to the snapshots of the resource.
+func (w *snapshotWriter) track(resource, namespace string, store cache.Store) cache.Store {
+	s := &snapshotStore{
+		Store:     store,
+		namespace: namespace,
+		objects:   cache.NewStore(cache.DeletionHandlingMetaNamespaceKeyFunc),
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.stores[resource] = append(w.stores[resource], s)
+
+	return s
+}
+
+// run writes snapshots every interval until the context is done.
+func (w *snapshotWriter) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.write(); err != nil {
+				klog.ErrorS(err, "Failed to write snapshot", "snapshotDir", w.dir)
+			}
+		}
+	}
+}
+
+// write writes the snapshots of all tracked resources.
+func (w *snapshotWriter) write() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if err := os.MkdirAll(w.dir, 0o750); err != nil {
+		return err
+	}
+	for resource, stores := range w.stores {
+		var objects []interface{}
+		resourceVersions := map[string]string{}
+		for _, s := range stores {
+			list, resourceVersion := s.state()
+			for _, o := range list {
+				objects = append(objects, sanitizeSnapshotObject(o))
+			}
+			resourceVersions[s.namespace] = resourceVersion
+		}
+		sort.Slice(objects, func(i, j int) bool {
+			return snapshotObjectKey(objects[i]) < snapshotObjectKey(objects[j])
+		})
+		if err := writeSnapshotFile(snapshotPath(w.dir, resource), objects); err != nil {
+			return fmt.Errorf("resource %s: %w", resource, err)
+		}
+		if !w.resourceVersions {
+			continue
+		}
+		// The resourceVersions are written after the objects, so that they
+		// are never newer than the objects.
+		if err := writeJSONFile(resourceVersionsPath(w.dir, resource), resourceVersions); err != nil {
+			return fmt.Errorf("resource %s: %w", resource, err)
+		}
+	}
+	klog.V(4).InfoS("Wrote snapshot", "snapshotDir", w.dir)
+
+	return nil
+}
+
+// writeSnapshotFile atomically replaces the file at path with the JSON
+// encoded objects.
+func writeSnapshotFile(path string, objects []interface{}) error {
+	if objects == nil {
+		objects = []interface{}{}
+	}
+	return writeJSONFile(path, objects)
+}
+
+// writeJSONFile atomically replaces the file at path with the JSON encoded
+// value.
+func writeJSONFile(path string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// readSnapshot returns the objects of the snapshot of the given resource in
+// dir, decoded into the type of expectedType.
+func readSnapshot(dir, resource string, expectedType interface{}) ([]interface{}, error) {
+	data, err := os.ReadFile(snapshotPath(dir, resource))
+	if err != nil {
+		return nil, err
+	}
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	t := reflect.TypeOf(expectedType).Elem()
+	objects := make([]interface{}, 0, len(raw))
+	for _, r := range raw {
+		o := reflect.New(t).Interface()
+		if err := json.Unmarshal(r, o); err != nil {
+			return nil, err
+		}
+		objects = append(objects, o)
+	}
+
+	return objects, nil
+}
+
+// readResourceVersions returns the last observed resourceVersions per
+// namespace of the given resource, written next to its snapshot in dir.
+func readResourceVersions(dir, resource string) (map[string]string, error) {
+	data, err := os.ReadFile(resourceVersionsPath(dir, resource))
+	if err != nil {
+		return nil, err
+	}
+	var resourceVersions map[string]string
+	if err := json.Unmarshal(data, &resourceVersions); err != nil {
+		return nil, err
+	}
+	return resourceVersions, nil
+}
+
+func snapshotPath(dir, resource string) string {
+	return filepath.Join(dir, invalidSnapshotFileCharRE.ReplaceAllString(resource, "_")+".json")
+}
+
+func resourceVersionsPath(dir, resource string) string {
+	return filepath.Join(dir, invalidSnapshotFileCharRE.ReplaceAllString(resource, "_")+".resourceversions.json")
+}
+
+// namespaceObjects returns the objects of the given namespace, or all objects
+// for metav1.NamespaceAll.
+func namespaceObjects(objects []interface{}, namespace string) []interface{} {
+	if namespace == v1.NamespaceAll {
+		return objects
+	}
+	filtered := objects[:0]
+	for _, o := range objects {
+		if m, err := meta.Accessor(o); err == nil && m.GetNamespace() == namespace {
+			filtered = append(filtered, o)
+		}
+	}
+	return filtered
+}
+
+func snapshotObjectKey(obj interface{}) string {
+	key, _ := cache.MetaNamespaceKeyFunc(obj)
+	return key
+}
+
+// sanitizeSnapshotObject returns a copy of the object without the data of
+// secrets and config maps and without fields which are not relevant for
+// metrics but might contain sensitive data.
+func sanitizeSnapshotObject(obj interface{}) interface{} {
+	o, ok := obj.(runtime.Object)
+	if !ok {
+		return obj
+	}
+	o = o.DeepCopyObject()
+
+	if m, err := meta.Accessor(o); err == nil {
+		m.SetManagedFields(nil)
+		if annotations := m.GetAnnotations(); annotations != nil {
+			delete(annotations, v1.LastAppliedConfigAnnotation)
+		}
+	}
+
+	switch o := o.(type) {
+	case *v1.Secret:
+		for k := range o.Data {
+			o.Data[k] = nil
+		}
+		o.StringData = nil
+	case *v1.ConfigMap:
+		for k := range o.Data {
+			o.Data[k] = ""
+		}
+		for k := range o.BinaryData {
+			o.BinaryData[k] = nil
+		}
+	}
+
+	return o
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+
+	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
+	metricsstore "k8s.io/kube-state-metrics/v2/pkg/metrics_store"
+)
+
+func TestSnapshotWriteAndReplay(t *testing.T) {
+	dir := t.TempDir()
+	families := secretMetricFamilies(nil, nil)
+	newStore := func() *metricsstore.MetricsStore {
+		return metricsstore.NewMetricsStore(
+			generator.ExtractMetricFamilyHeaders(families),
+			generator.ComposeMetricGenFuncs(families),
+		)
+	}
+	newSecret := func(name, namespace string) *v1.Secret {
+		return &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:          name,
+				Namespace:     namespace,
+				UID:           types.UID(name),
+				Annotations:   map[string]string{v1.LastAppliedConfigAnnotation: "{}", "team": "a"},
+				ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kubectl"}},
+			},
+			Type: v1.SecretTypeOpaque,
+			Data: map[string][]byte{"password": []byte("hunter2")},
+		}
+	}
+
+	w := newSnapshotWriter(dir)
+	store := w.track("secrets", v1.NamespaceAll, newStore())
+	if err := store.Replace([]interface{}{newSecret("s1", "ns1"), newSecret("s2", "ns2")}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Add(newSecret("s3", "ns1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete(newSecret("s2", "ns2")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.write(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(snapshotPath(dir, "secrets"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, sensitive := range []string{"aHVudGVyMg==", "hunter2", "kubectl", v1.LastAppliedConfigAnnotation} {
+		if strings.Contains(string(data), sensitive) {
+			t.Errorf("expected snapshot to not contain %q, got:\n%s", sensitive, data)
+		}
+	}
+
+	b := NewBuilder()
+	b.WithSnapshotReplay(dir)
+	replayed := newStore()
+	b.replaySnapshot("secrets", &v1.Secret{}, replayed, "ns1")
+
+	var out strings.Builder
+	if err := metricsstore.NewMetricsWriter(replayed).WriteAll(&out); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"s1", "s3"} {
+		if !strings.Contains(out.String(), `secret="`+name+`"`) {
+			t.Errorf("expected metrics of secret %s, got:\n%s", name, out.String())
+		}
+	}
+	if strings.Contains(out.String(), `secret="s2"`) {
+		t.Errorf("expected no metrics of secret s2, got:\n%s", out.String())
+	}
+}
+
+func TestCacheResume(t *testing.T) {
+	dir := t.TempDir()
+	newSecret := func(name, namespace, resourceVersion string) *v1.Secret {
+		return &v1.Secret{ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       namespace,
+			UID:             types.UID(name),
+			ResourceVersion: resourceVersion,
+		}}
+	}
+
+	w := newSnapshotWriter(dir)
+	w.resourceVersions = true
+	ns1 := w.track("secrets", "ns1", cache.NewStore(cache.MetaNamespaceKeyFunc))
+	if err := ns1.Replace([]interface{}{newSecret("s1", "ns1", "5")}, "10"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ns1.Add(newSecret("s3", "ns1", "12")); err != nil {
+		t.Fatal(err)
+	}
+	ns2 := w.track("secrets", "ns2", cache.NewStore(cache.MetaNamespaceKeyFunc))
+	if err := ns2.Replace([]interface{}{newSecret("s2", "ns2", "7")}, "11"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.write(); err != nil {
+		t.Fatal(err)
+	}
+
+	listed := &v1.SecretList{ListMeta: metav1.ListMeta{ResourceVersion: "20"}}
+	apiserver := &cache.ListWatch{ListFunc: func(metav1.ListOptions) (runtime.Object, error) {
+		return listed, nil
+	}}
+	b := NewBuilder()
+	b.WithCache(dir, time.Minute)
+
+	lw := b.cachedListWatch("secrets", &v1.Secret{}, apiserver, "ns1")
+	list, err := lw.List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, item := range items {
+		names = append(names, item.(*v1.Secret).Name)
+	}
+	if strings.Join(names, ",") != "s1,s3" {
+		t.Errorf("expected the cached secrets s1,s3 of namespace ns1, got %v", names)
+	}
+	if m, _ := meta.ListAccessor(list); m.GetResourceVersion() != "12" {
+		t.Errorf("expected the last observed resourceVersion 12, got %s", m.GetResourceVersion())
+	}
+
+	list, err = lw.List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list != listed {
+		t.Errorf("expected later lists to list from the apiserver, got %v", list)
+	}
+
+	// Namespaces without cached resourceVersion are listed from the apiserver.
+	if lw := b.cachedListWatch("secrets", &v1.Secret{}, apiserver, "ns3"); lw != apiserver {
+		t.Errorf("expected the ListerWatcher of the apiserver for an uncached namespace, got %T", lw)
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"time"
 
 	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
@@ -61,6 +62,7 @@ func TestStatefulSetStore(t *testing.T) {
 			},
 			Want: `
 				# HELP kube_statefulset_created [STABLE] Unix creation timestamp
+				# HELP kube_statefulset_generation_mismatch The difference between the generation of the desired state and the generation observed by the StatefulSet controller, which is positive until the controller processed the latest change.
 				# HELP kube_statefulset_labels [STABLE] Kubernetes labels converted to Prometheus labels.
 				# HELP kube_statefulset_metadata_generation [STABLE] Sequence number representing a specific generation of the desired state for the StatefulSet.
 				# HELP kube_statefulset_persistentvolumeclaim_retention_policy Count of retention policy for StatefulSet template PVCs
@@ -75,6 +77,7 @@ func TestStatefulSetStore(t *testing.T) {
 				# HELP kube_statefulset_status_replicas_updated [STABLE] The number of updated replicas per StatefulSet.
 				# HELP kube_statefulset_status_update_revision [STABLE] Indicates the version of the StatefulSet used to generate Pods in the sequence [replicas-updatedReplicas,replicas)
 				# TYPE kube_statefulset_created gauge
+				# TYPE kube_statefulset_generation_mismatch gauge
 				# TYPE kube_statefulset_labels gauge
 				# TYPE kube_statefulset_metadata_generation gauge
 				# TYPE kube_statefulset_persistentvolumeclaim_retention_policy gauge
@@ -99,9 +102,11 @@ func TestStatefulSetStore(t *testing.T) {
  				kube_statefulset_status_observed_generation{namespace="ns1",statefulset="statefulset1"} 1
  				kube_statefulset_replicas{namespace="ns1",statefulset="statefulset1"} 3
  				kube_statefulset_metadata_generation{namespace="ns1",statefulset="statefulset1"} 3
+				kube_statefulset_generation_mismatch{namespace="ns1",statefulset="statefulset1"} 2
 `,
 			MetricNames: []string{
 				"kube_statefulset_created",
+				"kube_statefulset_generation_mismatch",
 				"kube_statefulset_labels",
 				"kube_statefulset_metadata_generation",
 				"kube_statefulset_replicas",
@@ -410,6 +415,29 @@ func TestStatefulSetStore(t *testing.T) {
 				"kube_statefulset_persistentvolumeclaim_retention_policy",
 			},
 		},
+		{
+			Obj: &v1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "statefulset-conditions",
+					Namespace: "ns1",
+				},
+				Status: v1.StatefulSetStatus{
+					Conditions: []v1.StatefulSetCondition{
+						{Type: "example.com/Healthy", Status: corev1.ConditionFalse},
+					},
+				},
+			},
+			Want: `
+				# HELP kube_statefulset_status_condition The current status conditions of a statefulset.
+				# TYPE kube_statefulset_status_condition gauge
+				kube_statefulset_status_condition{condition="example.com/Healthy",namespace="ns1",statefulset="statefulset-conditions",status="false"} 1
+				kube_statefulset_status_condition{condition="example.com/Healthy",namespace="ns1",statefulset="statefulset-conditions",status="true"} 0
+				kube_statefulset_status_condition{condition="example.com/Healthy",namespace="ns1",statefulset="statefulset-conditions",status="unknown"} 0
+`,
+			MetricNames: []string{
+				"kube_statefulset_status_condition",
+			},
+		},
 	}
 	for i, c := range cases {
 		c.Func = generator.ComposeMetricGenFuncs(statefulSetMetricFamilies(nil, nil))
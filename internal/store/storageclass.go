@@ -76,16 +76,14 @@ func storageClassMetricFamilies(allowAnnotationsList, allowLabelsList []string)
 			wrapStorageClassFunc(func(s *storagev1.StorageClass) *metric.Family {
 				ms := []*metric.Metric{}
 				if !s.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						Value: float64(s.CreationTimestamp.Unix()),
-					})
+					ms = append(ms, metric.TimestampMetric(nil, nil, s.CreationTimestamp.Time))
 				}
 				return &metric.Family{
 					Metrics: ms,
 				}
 			}),
 		),
-		*generator.NewFamilyGeneratorWithStability(
+		*generator.NewLazyFamilyGenerator(
 			descStorageClassAnnotationsName,
 			descStorageClassAnnotationsHelp,
 			metric.Gauge,
@@ -107,7 +105,7 @@ func storageClassMetricFamilies(allowAnnotationsList, allowLabelsList []string)
 				}
 			}),
 		),
-		*generator.NewFamilyGeneratorWithStability(
+		*generator.NewLazyFamilyGenerator(
 			descStorageClassLabelsName,
 			descStorageClassLabelsHelp,
 			metric.Gauge,
@@ -0,0 +1,257 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	basemetrics "k8s.io/component-base/metrics"
+
+	"k8s.io/kube-state-metrics/v2/pkg/metric"
+	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
+)
+
+// updateTimeResource describes the kube_<name>_last_update_time family of a
+// resource, whose objects are identified by the same labels as in the other
+// families of the resource.
+type updateTimeResource struct {
+	name   string
+	labels []string
+}
+
+// updateTimeResources are the resources which serve
+// kube_<name>_last_update_time. Certificate signing requests are missing, as
+// their default labels include the signer name, and events are aggregated
+// instead of being served per object.
+var updateTimeResources = map[string]updateTimeResource{
+	"clusterrolebindings":             {"clusterrolebinding", descClusterRoleBindingLabelsDefaultLabels},
+	"clusterroles":                    {"clusterrole", descClusterRoleLabelsDefaultLabels},
+	"configmaps":                      {"configmap", descConfigMapLabelsDefaultLabels},
+	"cronjobs":                        {"cronjob", descCronJobLabelsDefaultLabels},
+	"csidrivers":                      {"csidriver", descCSIDriverLabelsDefaultLabels},
+	"csinodes":                        {"csinode", descCSINodeLabelsDefaultLabels},
+	"csistoragecapacities":            {"csistoragecapacity", descCSIStorageCapacityLabelsDefaultLabels},
+	"daemonsets":                      {"daemonset", descDaemonSetLabelsDefaultLabels},
+	"deployments":                     {"deployment", descDeploymentLabelsDefaultLabels},
+	"endpoints":                       {"endpoint", descEndpointLabelsDefaultLabels},
+	"endpointslices":                  {"endpointslice", descEndpointSliceLabelsDefaultLabels},
+	"horizontalpodautoscalers":        {"horizontalpodautoscaler", descHorizontalPodAutoscalerLabelsDefaultLabels},
+	"ingressclasses":                  {"ingressclass", descIngressClassLabelsDefaultLabels},
+	"ingresses":                       {"ingress", descIngressLabelsDefaultLabels},
+	"jobs":                            {"job", descJobLabelsDefaultLabels},
+	"leases":                          {"lease", descLeaseLabelsDefaultLabels},
+	"limitranges":                     {"limitrange", descLimitRangeLabelsDefaultLabels},
+	"mutatingwebhookconfigurations":   {"mutatingwebhookconfiguration", descMutatingWebhookConfigurationDefaultLabels},
+	"namespaces":                      {"namespace", descNamespaceLabelsDefaultLabels},
+	"networkpolicies":                 {"networkpolicy", descNetworkPolicyLabelsDefaultLabels},
+	"nodes":                           {"node", descNodeLabelsDefaultLabels},
+	"persistentvolumeclaims":          {"persistentvolumeclaim", descPersistentVolumeClaimLabelsDefaultLabels},
+	"persistentvolumes":               {"persistentvolume", descPersistentVolumeLabelsDefaultLabels},
+	"poddisruptionbudgets":            {"poddisruptionbudget", descPodDisruptionBudgetLabelsDefaultLabels},
+	"pods":                            {"pod", descPodLabelsDefaultLabels},
+	"priorityclasses":                 {"priorityclass", descPriorityClassLabelsDefaultLabels},
+	"replicasets":                     {"replicaset", descReplicaSetLabelsDefaultLabels},
+	"replicationcontrollers":          {"replicationcontroller", descReplicationControllerLabelsDefaultLabels},
+	"resourcequotas":                  {"resourcequota", descResourceQuotaLabelsDefaultLabels},
+	"rolebindings":                    {"rolebinding", descRoleBindingLabelsDefaultLabels},
+	"roles":                           {"role", descRoleLabelsDefaultLabels},
+	"runtimeclasses":                  {"runtimeclass", descRuntimeClassLabelsDefaultLabels},
+	"secrets":                         {"secret", descSecretLabelsDefaultLabels},
+	"serviceaccounts":                 {"serviceaccount", descServiceAccountLabelsDefaultLabels},
+	"services":                        {"service", descServiceLabelsDefaultLabels},
+	"statefulsets":                    {"statefulset", descStatefulSetLabelsDefaultLabels},
+	"storageclasses":                  {"storageclass", descStorageClassLabelsDefaultLabels},
+	"validatingwebhookconfigurations": {"validatingwebhookconfiguration", descValidatingWebhookConfigurationDefaultLabels},
+	"volumeattachments":               {"volumeattachment", descVolumeAttachmentLabelsDefaultLabels},
+}
+
+// objectUpdate is the last seen resourceVersion of an object, and when it
+// was last updated. The time is zero until an update was seen.
+type objectUpdate struct {
+	resourceVersion string
+	time            time.Time
+	// store is the store the object was last seen by.
+	store *updateTimeStore
+}
+
+// updateTimeTracker records when the objects of a resource were last changed
+// according to the update events of the reflectors, for
+// kube_<name>_last_update_time. The stores of all namespaces of a resource
+// share a tracker, so that their objects are served by the same family, and
+// each of them only forgets the objects it saw on relist.
+type updateTimeTracker struct {
+	mutex   sync.RWMutex
+	updates map[types.UID]objectUpdate
+}
+
+func newUpdateTimeTracker() *updateTimeTracker {
+	return &updateTimeTracker{updates: map[types.UID]objectUpdate{}}
+}
+
+// observe records the resourceVersion of the given object seen by the given
+// store. The update time is set if the resourceVersion changed since the
+// object was last seen, or if updated is true for an object which was not
+// seen before. Objects seen for the first time otherwise don't have an update
+// time, as their addition says nothing about when they were last changed.
+func (t *updateTimeTracker) observe(store *updateTimeStore, obj interface{}, updated bool) {
+	o, err := meta.Accessor(obj)
+	if err != nil {
+		return
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	prev, ok := t.updates[o.GetUID()]
+	switch {
+	case ok && prev.resourceVersion == o.GetResourceVersion():
+		prev.store = store
+		t.updates[o.GetUID()] = prev
+	case ok || updated:
+		t.updates[o.GetUID()] = objectUpdate{resourceVersion: o.GetResourceVersion(), time: timeNow(), store: store}
+	default:
+		t.updates[o.GetUID()] = objectUpdate{resourceVersion: o.GetResourceVersion(), store: store}
+	}
+}
+
+// forget drops the given object, which might be a tombstone.
+func (t *updateTimeTracker) forget(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	o, err := meta.Accessor(obj)
+	if err != nil {
+		return
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	delete(t.updates, o.GetUID())
+}
+
+// lastUpdate returns when the object with the given UID was last updated, or
+// the zero time if no update was seen.
+func (t *updateTimeTracker) lastUpdate(uid types.UID) time.Time {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	return t.updates[uid].time
+}
+
+// track returns a store which records the update times of the objects
+// passing through it before they are passed on to the given store, so that
+// the metrics generated by the given store include the new update time.
+func (t *updateTimeTracker) track(store cache.Store) cache.Store {
+	return &updateTimeStore{Store: store, tracker: t}
+}
+
+// updateTimeStore wraps a store and records the update times of its objects.
+type updateTimeStore struct {
+	cache.Store
+	tracker *updateTimeTracker
+}
+
+// Add records the object and adds it to the wrapped store.
+func (s *updateTimeStore) Add(obj interface{}) error {
+	s.tracker.observe(s, obj, false)
+	return s.Store.Add(obj)
+}
+
+// Update records the update of the object and updates it in the wrapped
+// store.
+func (s *updateTimeStore) Update(obj interface{}) error {
+	s.tracker.observe(s, obj, true)
+	return s.Store.Update(obj)
+}
+
+// Delete deletes the object from the wrapped store and forgets it.
+func (s *updateTimeStore) Delete(obj interface{}) error {
+	if err := s.Store.Delete(obj); err != nil {
+		return err
+	}
+	s.tracker.forget(obj)
+	return nil
+}
+
+// Replace records the listed objects, of which those whose resourceVersion
+// changed while the watch was interrupted count as updated, and replaces the
+// contents of the wrapped store with them. Objects last seen by this store
+// which are missing from the list are forgotten, while those of the stores of
+// other namespaces are kept.
+func (s *updateTimeStore) Replace(list []interface{}, resourceVersion string) error {
+	listed := make(map[types.UID]struct{}, len(list))
+	for _, obj := range list {
+		s.tracker.observe(s, obj, false)
+		if o, err := meta.Accessor(obj); err == nil {
+			listed[o.GetUID()] = struct{}{}
+		}
+	}
+
+	s.tracker.mutex.Lock()
+	for uid, u := range s.tracker.updates {
+		if _, ok := listed[uid]; !ok && u.store == s {
+			delete(s.tracker.updates, uid)
+		}
+	}
+	s.tracker.mutex.Unlock()
+
+	return s.Store.Replace(list, resourceVersion)
+}
+
+// createLastUpdateTimeFamilyGenerator returns the kube_<name>_last_update_time
+// family of the given resource, whose values are the update times of the
+// given tracker. Objects without a seen update are not served.
+func createLastUpdateTimeFamilyGenerator(resource string, r updateTimeResource, tracker *updateTimeTracker) generator.FamilyGenerator {
+	return *generator.NewOptInFamilyGenerator(
+		"kube_"+r.name+"_last_update_time",
+		"Unix timestamp of the last update event of the "+r.name+" seen by kube-state-metrics which changed its resourceVersion.",
+		metric.Gauge,
+		basemetrics.ALPHA,
+		"",
+		func(obj interface{}) *metric.Family {
+			o, err := meta.Accessor(obj)
+			if err != nil {
+				return &metric.Family{}
+			}
+			t := tracker.lastUpdate(o.GetUID())
+			if t.IsZero() {
+				return &metric.Family{}
+			}
+
+			values := make([]string, len(r.labels))
+			for i, l := range r.labels {
+				switch {
+				case l == "uid":
+					values[i] = string(o.GetUID())
+				case l == "namespace" && resource != "namespaces":
+					values[i] = o.GetNamespace()
+				default:
+					values[i] = o.GetName()
+				}
+			}
+
+			return &metric.Family{
+				Metrics: []*metric.Metric{
+					metric.TimestampMetric(r.labels, values, t),
+				},
+			}
+		},
+	)
+}
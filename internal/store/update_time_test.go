@@ -0,0 +1,217 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+
+	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
+	metricsstore "k8s.io/kube-state-metrics/v2/pkg/metrics_store"
+)
+
+func TestUpdateTimeStore(t *testing.T) {
+	now := time.Unix(1500000000, 0)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	tracker := newUpdateTimeTracker()
+	families := []generator.FamilyGenerator{
+		createLastUpdateTimeFamilyGenerator("deployments", updateTimeResources["deployments"], tracker),
+	}
+	store := metricsstore.NewMetricsStore(
+		generator.ExtractMetricFamilyHeaders(families),
+		generator.ComposeMetricGenFuncs(families),
+	)
+	updateStore := tracker.track(store)
+
+	newDeployment := func(name, resourceVersion string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            name,
+				Namespace:       "ns1",
+				UID:             types.UID(name),
+				ResourceVersion: resourceVersion,
+			},
+		}
+	}
+
+	expect := func(want ...string) {
+		t.Helper()
+		var b strings.Builder
+		if err := metricsstore.NewMetricsWriter(store).WriteAll(&b); err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, l := range strings.Split(b.String(), "\n") {
+			if strings.HasPrefix(l, "kube_deployment_last_update_time{") {
+				got = append(got, l)
+			}
+		}
+		if !sameElements(got, want) {
+			t.Errorf("expected update times:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+		}
+	}
+
+	// Listed and added objects have no update time yet.
+	if err := updateStore.Replace([]interface{}{newDeployment("web", "1"), newDeployment("db", "1")}, "1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := updateStore.Add(newDeployment("cache", "2")); err != nil {
+		t.Fatal(err)
+	}
+	expect()
+
+	if err := updateStore.Update(newDeployment("web", "3")); err != nil {
+		t.Fatal(err)
+	}
+	expect(`kube_deployment_last_update_time{namespace="ns1",deployment="web"} 1.5e+09`)
+
+	// Updates without a new resourceVersion, like resyncs, keep the time.
+	now = now.Add(time.Minute)
+	if err := updateStore.Update(newDeployment("web", "3")); err != nil {
+		t.Fatal(err)
+	}
+	expect(`kube_deployment_last_update_time{namespace="ns1",deployment="web"} 1.5e+09`)
+
+	// Objects changed while the watch was interrupted count as updated on
+	// relist, and objects missing from the list are forgotten.
+	if err := updateStore.Replace([]interface{}{newDeployment("web", "3"), newDeployment("db", "4")}, "4"); err != nil {
+		t.Fatal(err)
+	}
+	expect(
+		`kube_deployment_last_update_time{namespace="ns1",deployment="web"} 1.5e+09`,
+		`kube_deployment_last_update_time{namespace="ns1",deployment="db"} 1.50000006e+09`,
+	)
+	if _, ok := tracker.updates["cache"]; ok {
+		t.Error("expected cache to be forgotten after relist")
+	}
+
+	if err := updateStore.Delete(newDeployment("db", "4")); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := tracker.updates["db"]; ok {
+		t.Error("expected db to be forgotten after delete")
+	}
+}
+
+func TestUpdateTimeStoreNamespaces(t *testing.T) {
+	now := time.Unix(1500000000, 0)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	tracker := newUpdateTimeTracker()
+	families := []generator.FamilyGenerator{
+		createLastUpdateTimeFamilyGenerator("deployments", updateTimeResources["deployments"], tracker),
+	}
+	newStore := func() cache.Store {
+		return tracker.track(metricsstore.NewMetricsStore(
+			generator.ExtractMetricFamilyHeaders(families),
+			generator.ComposeMetricGenFuncs(families),
+		))
+	}
+	ns1, ns2 := newStore(), newStore()
+
+	newDeployment := func(namespace, name, resourceVersion string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            name,
+				Namespace:       namespace,
+				UID:             types.UID(namespace + "/" + name),
+				ResourceVersion: resourceVersion,
+			},
+		}
+	}
+
+	if err := ns1.Replace([]interface{}{newDeployment("ns1", "web", "1"), newDeployment("ns1", "db", "1")}, "1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ns2.Replace([]interface{}{newDeployment("ns2", "web", "1")}, "1"); err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range []*appsv1.Deployment{newDeployment("ns1", "web", "2"), newDeployment("ns2", "web", "2")} {
+		store := ns1
+		if d.Namespace == "ns2" {
+			store = ns2
+		}
+		if err := store.Update(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A relist of one namespace only forgets the objects of that namespace.
+	if err := ns1.Replace([]interface{}{newDeployment("ns1", "web", "2")}, "3"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := tracker.updates["ns1/db"]; ok {
+		t.Error("expected ns1/db to be forgotten after the relist of ns1")
+	}
+	for _, uid := range []types.UID{"ns1/web", "ns2/web"} {
+		if tracker.lastUpdate(uid) != now {
+			t.Errorf("expected the update time of %s to be kept after the relist of ns1", uid)
+		}
+	}
+}
+
+func TestLastUpdateTimeFamilyLabels(t *testing.T) {
+	tracker := newUpdateTimeTracker()
+	tracker.updates["ns1"] = objectUpdate{resourceVersion: "2", time: time.Unix(1500000000, 0)}
+	tracker.updates["p1"] = objectUpdate{resourceVersion: "2", time: time.Unix(1500000000, 0)}
+
+	cases := []struct {
+		resource string
+		generateMetricsTestCase
+	}{
+		{"namespaces", generateMetricsTestCase{
+			Obj: &v1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{Name: "ns1", UID: "ns1"},
+			},
+			Want: `
+				# HELP kube_namespace_last_update_time Unix timestamp of the last update event of the namespace seen by kube-state-metrics which changed its resourceVersion.
+				# TYPE kube_namespace_last_update_time gauge
+				kube_namespace_last_update_time{namespace="ns1"} 1.5e+09
+			`,
+			MetricNames: []string{"kube_namespace_last_update_time"},
+		}},
+		{"pods", generateMetricsTestCase{
+			Obj: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "ns1", UID: "p1"},
+			},
+			Want: `
+				# HELP kube_pod_last_update_time Unix timestamp of the last update event of the pod seen by kube-state-metrics which changed its resourceVersion.
+				# TYPE kube_pod_last_update_time gauge
+				kube_pod_last_update_time{namespace="ns1",pod="pod1",uid="p1"} 1.5e+09
+			`,
+			MetricNames: []string{"kube_pod_last_update_time"},
+		}},
+	}
+	for i, c := range cases {
+		families := []generator.FamilyGenerator{createLastUpdateTimeFamilyGenerator(c.resource, updateTimeResources[c.resource], tracker)}
+		c.Func = generator.ComposeMetricGenFuncs(families)
+		c.Headers = generator.ExtractMetricFamilyHeaders(families)
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
+		}
+	}
+}
@@ -22,20 +22,39 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/util/validation"
 
+	"k8s.io/kube-state-metrics/v2/pkg/constant"
 	"k8s.io/kube-state-metrics/v2/pkg/metric"
 	"k8s.io/kube-state-metrics/v2/pkg/options"
 )
 
 var (
-	invalidLabelCharRE = regexp.MustCompile(`[^a-zA-Z0-9_]`)
-	matchAllCap        = regexp.MustCompile("([a-z0-9])([A-Z])")
-	conditionStatuses  = []v1.ConditionStatus{v1.ConditionTrue, v1.ConditionFalse, v1.ConditionUnknown}
+	invalidLabelCharRE  = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+	validLabelNameRE    = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	validMetricPrefixRE = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	matchAllCap         = regexp.MustCompile("([a-z0-9])([A-Z])")
+	conditionStatuses   = []v1.ConditionStatus{v1.ConditionTrue, v1.ConditionFalse, v1.ConditionUnknown}
+
+	// timeNow is used by metrics that are relative to the time of generation.
+	// It is a variable so tests can pin it.
+	timeNow = time.Now
+
+	// allowListEntries caches the parsed entries of annotation and label allow
+	// lists, as they are evaluated for every object.
+	allowListEntries sync.Map
 )
 
+// allowListRegexChars are characters which are not valid in Kubernetes
+// annotation and label keys. An allow list entry containing any of them is
+// treated as a regular expression.
+const allowListRegexChars = `*+?()[]{}|^$\`
+
 func resourceVersionMetric(rv string) []*metric.Metric {
 	v, err := strconv.ParseFloat(rv, 64)
 	if err != nil {
@@ -57,6 +76,15 @@ func boolFloat64(b bool) float64 {
 	return 0
 }
 
+// optionalBoolString returns the label value of an optional bool, which is
+// empty if it is not set.
+func optionalBoolString(b *bool) string {
+	if b == nil {
+		return ""
+	}
+	return strconv.FormatBool(*b)
+}
+
 // addConditionMetrics generates one metric for each possible condition
 // status. For this function to work properly, the last label in the metric
 // description must be the condition.
@@ -73,6 +101,64 @@ func addConditionMetrics(cs v1.ConditionStatus) []*metric.Metric {
 	return ms
 }
 
+// statusConditionMetrics returns the metrics of a
+// kube_<resource>_status_condition family, with one metric per condition type
+// and possible status. Condition types which are not known to
+// kube-state-metrics, e.g. those of third party controllers, are served as
+// well. A type is only served for its first occurrence.
+func statusConditionMetrics[C any](conditions []C, typeAndStatus func(C) (string, v1.ConditionStatus)) []*metric.Metric {
+	ms := make([]*metric.Metric, 0, len(conditions)*len(conditionStatuses))
+
+	for _, c := range uniqueConditions(conditions, typeAndStatus) {
+		conditionType, status := typeAndStatus(c)
+		for _, m := range addConditionMetrics(status) {
+			m.LabelKeys = []string{"condition", "status"}
+			m.LabelValues = append([]string{conditionType}, m.LabelValues...)
+			ms = append(ms, m)
+		}
+	}
+
+	return ms
+}
+
+// statusConditionLastTransitionMetrics returns the metrics of a
+// kube_<resource>_status_condition_last_transition_time family, with the
+// time the condition last transitioned to its current status as value.
+// Types are only served for their first occurrence like by
+// statusConditionMetrics, and conditions without a transition time are
+// skipped.
+func statusConditionLastTransitionMetrics[C any](conditions []C, typeAndStatus func(C) (string, v1.ConditionStatus), lastTransition func(C) time.Time) []*metric.Metric {
+	ms := make([]*metric.Metric, 0, len(conditions))
+
+	for _, c := range uniqueConditions(conditions, typeAndStatus) {
+		t := lastTransition(c)
+		if t.IsZero() {
+			continue
+		}
+		conditionType, status := typeAndStatus(c)
+		ms = append(ms, metric.TimestampMetric([]string{"condition", "status"}, []string{conditionType, strings.ToLower(string(status))}, t))
+	}
+
+	return ms
+}
+
+// uniqueConditions returns the first occurrence of each condition type.
+func uniqueConditions[C any](conditions []C, typeAndStatus func(C) (string, v1.ConditionStatus)) []C {
+	limited := make([]C, 0, len(conditions))
+	seen := make(map[string]struct{}, len(conditions))
+
+	for _, c := range conditions {
+		conditionType, _ := typeAndStatus(c)
+		if _, ok := seen[conditionType]; ok {
+			continue
+		}
+		seen[conditionType] = struct{}{}
+		limited = append(limited, c)
+	}
+
+	return limited
+}
+
 func kubeMapToPrometheusLabels(prefix string, input map[string]string) ([]string, []string) {
 	return mapToPrometheusLabels(input, prefix)
 }
@@ -164,6 +250,22 @@ func isExtendedResourceName(name v1.ResourceName) bool {
 	return true
 }
 
+// canonicalResourceQuantity returns the value of the quantity of the
+// resource in its base unit, i.e. cores for CPU, bytes for memory, storage and
+// huge pages and integers for attachable volumes and extended resources. It
+// returns false for other resources.
+func canonicalResourceQuantity(name v1.ResourceName, val resource.Quantity) (constant.ResourceUnit, float64, bool) {
+	switch {
+	case name == v1.ResourceCPU:
+		return constant.UnitCore, float64(val.MilliValue()) / 1000, true
+	case name == v1.ResourceMemory || name == v1.ResourceStorage || name == v1.ResourceEphemeralStorage || isHugePageResourceName(name):
+		return constant.UnitByte, float64(val.Value()), true
+	case isAttachableVolumeResourceName(name) || isExtendedResourceName(name):
+		return constant.UnitInteger, float64(val.Value()), true
+	}
+	return "", 0, false
+}
+
 func isNativeResource(name v1.ResourceName) bool {
 	return !strings.Contains(string(name), "/") ||
 		isPrefixedNativeResource(name)
@@ -173,6 +275,104 @@ func isPrefixedNativeResource(name v1.ResourceName) bool {
 	return strings.Contains(string(name), v1.ResourceDefaultNamespacePrefix)
 }
 
+// allowListEntry is a parsed entry of an annotation or label allow list.
+// An entry is either an exact key, optionally followed by ":<name>" to rename
+// the resulting label, or a regular expression matching whole keys.
+type allowListEntry struct {
+	key     string
+	rename  string
+	pattern *regexp.Regexp
+}
+
+// parseAllowListEntry parses an entry of an annotation or label allow list.
+func parseAllowListEntry(entry string) (*allowListEntry, error) {
+	if strings.ContainsAny(entry, allowListRegexChars) {
+		if i := strings.LastIndex(entry, ":"); i >= 0 && validLabelNameRE.MatchString(entry[i+1:]) {
+			return nil, fmt.Errorf("allow list entry %q: renaming is only supported for exact keys", entry)
+		}
+		pattern, err := regexp.Compile("^(?:" + entry + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("allow list entry %q: %w", entry, err)
+		}
+		return &allowListEntry{key: entry, pattern: pattern}, nil
+	}
+
+	if i := strings.LastIndex(entry, ":"); i >= 0 {
+		rename := entry[i+1:]
+		if !validLabelNameRE.MatchString(rename) {
+			return nil, fmt.Errorf("allow list entry %q: %q is not a valid label name", entry, rename)
+		}
+		return &allowListEntry{key: entry[:i], rename: rename}, nil
+	}
+
+	return &allowListEntry{key: entry}, nil
+}
+
+// ValidateAllowList returns an error if an entry of the annotation or label
+// allow list is invalid, or if a key is renamed to the name of another key of
+// the list, which would lose the value of one of them.
+func ValidateAllowList(allowList []string) error {
+	var entries []*allowListEntry
+	for _, entry := range allowList {
+		if entry == options.LabelWildcard {
+			continue
+		}
+		e, err := parseAllowListEntry(entry)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, e)
+	}
+
+	keys := map[string]string{}
+	for _, e := range entries {
+		if e.pattern != nil {
+			continue
+		}
+		name := e.key
+		if e.rename != "" {
+			name = e.rename
+		}
+		if other, ok := keys[name]; ok && other != e.key {
+			return fmt.Errorf("allow list entries of %q and %q both result in %q", other, e.key, name)
+		}
+		keys[name] = e.key
+	}
+	for _, e := range entries {
+		if e.rename == "" {
+			continue
+		}
+		for _, p := range entries {
+			if p.pattern != nil && p.pattern.MatchString(e.rename) {
+				return fmt.Errorf("allow list entry %q: %q is also matched by %q", e.key+":"+e.rename, e.rename, p.key)
+			}
+		}
+	}
+	return nil
+}
+
+// cachedAllowListEntry returns the parsed allow list entry. Entries are
+// validated when configuring the Builder, so invalid entries are treated as
+// exact keys here.
+func cachedAllowListEntry(entry string) *allowListEntry {
+	if e, ok := allowListEntries.Load(entry); ok {
+		return e.(*allowListEntry)
+	}
+	e, err := parseAllowListEntry(entry)
+	if err != nil {
+		e = &allowListEntry{key: entry}
+	}
+	allowListEntries.Store(entry, e)
+	return e
+}
+
+// CreatePrometheusLabelKeysValues takes in passed kubernetes annotations/labels
+// and associated allowed list in kubernetes label format.
+// It returns only those allowed annotations/labels that exist in the list and converts them to Prometheus labels.
+func CreatePrometheusLabelKeysValues(prefix string, allKubeData map[string]string, allowList []string) ([]string, []string) {
+	return createPrometheusLabelKeysValues(prefix, allKubeData, allowList)
+}
+
 // createPrometheusLabelKeysValues takes in passed kubernetes annotations/labels
 // and associated allowed list in kubernetes label format.
 // It returns only those allowed annotations/labels that exist in the list and converts them to Prometheus labels.
@@ -185,9 +385,23 @@ func createPrometheusLabelKeysValues(prefix string, allKubeData map[string]strin
 		}
 
 		for _, l := range allowList {
-			v, found := allKubeData[l]
-			if found {
-				allowedKubeData[l] = v
+			e := cachedAllowListEntry(l)
+			if e.pattern != nil {
+				for k, v := range allKubeData {
+					if e.pattern.MatchString(k) {
+						allowedKubeData[k] = v
+					}
+				}
+				continue
+			}
+			v, found := allKubeData[e.key]
+			if !found {
+				continue
+			}
+			if e.rename != "" {
+				allowedKubeData[e.rename] = v
+			} else {
+				allowedKubeData[e.key] = v
 			}
 		}
 	}
@@ -215,3 +429,48 @@ func mergeKeyValues(keyValues ...[]string) (keys, values []string) {
 
 	return keys, values
 }
+
+// limitedValueOther replaces the values beyond the limit of a valueLimiter.
+const limitedValueOther = "Other"
+
+// valueLimiter bounds the cardinality of a label by replacing the values
+// which were not seen before once the maximum number of distinct values was
+// seen.
+type valueLimiter struct {
+	max int
+
+	mutex sync.Mutex
+	seen  map[string]struct{}
+}
+
+// newValueLimiter returns a valueLimiter for the given maximum number of
+// distinct values. A maximum of 0 disables the limit.
+func newValueLimiter(max int) *valueLimiter {
+	return &valueLimiter{max: max, seen: map[string]struct{}{}}
+}
+
+// limit returns the value, or limitedValueOther if it is beyond the limit.
+func (l *valueLimiter) limit(value string) string {
+	if !l.admit(value) {
+		return limitedValueOther
+	}
+	return value
+}
+
+// admit reports whether the value is within the limit, counting it if it was
+// not seen before.
+func (l *valueLimiter) admit(value string) bool {
+	if l.max == 0 {
+		return true
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if _, ok := l.seen[value]; ok {
+		return true
+	}
+	if len(l.seen) >= l.max {
+		return false
+	}
+	l.seen[value] = struct{}{}
+	return true
+}
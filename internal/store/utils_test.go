@@ -319,3 +319,105 @@ func TestMergeKeyValues(t *testing.T) {
 		})
 	}
 }
+
+func TestCreatePrometheusLabelKeysValues(t *testing.T) {
+	kubeLabels := map[string]string{
+		"app.kubernetes.io/name":      "mysql",
+		"app.kubernetes.io/component": "database",
+		"team":                        "storage",
+		"other":                       "value",
+	}
+
+	testCases := []struct {
+		name         string
+		allowList    []string
+		expectKeys   []string
+		expectValues []string
+	}{
+		{
+			name:         "exact keys",
+			allowList:    []string{"team", "missing"},
+			expectKeys:   []string{"label_team"},
+			expectValues: []string{"storage"},
+		},
+		{
+			name:         "regular expression",
+			allowList:    []string{"app.kubernetes.io/.*"},
+			expectKeys:   []string{"label_app_kubernetes_io_component", "label_app_kubernetes_io_name"},
+			expectValues: []string{"database", "mysql"},
+		},
+		{
+			name:         "regular expressions match whole keys",
+			allowList:    []string{"app.kubernetes.io/(name|comp)"},
+			expectKeys:   []string{"label_app_kubernetes_io_name"},
+			expectValues: []string{"mysql"},
+		},
+		{
+			name:         "renamed key",
+			allowList:    []string{"app.kubernetes.io/name:app", "team"},
+			expectKeys:   []string{"label_app", "label_team"},
+			expectValues: []string{"mysql", "storage"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotKeys, gotValues := createPrometheusLabelKeysValues("label", kubeLabels, tc.allowList)
+			if !reflect.DeepEqual(gotKeys, tc.expectKeys) {
+				t.Errorf("createPrometheusLabelKeysValues() keys = %v, want %v", gotKeys, tc.expectKeys)
+			}
+			if !reflect.DeepEqual(gotValues, tc.expectValues) {
+				t.Errorf("createPrometheusLabelKeysValues() values = %v, want %v", gotValues, tc.expectValues)
+			}
+		})
+	}
+}
+
+func TestValueLimiter(t *testing.T) {
+	l := newValueLimiter(2)
+	for _, test := range []struct {
+		value string
+		want  string
+	}{
+		{"a", "a"},
+		{"b", "b"},
+		{"c", "Other"},
+		{"a", "a"},
+		{"d", "Other"},
+	} {
+		if got := l.limit(test.value); got != test.want {
+			t.Errorf("expected %q for %q, got %q", test.want, test.value, got)
+		}
+	}
+
+	if got := newValueLimiter(0).limit("a"); got != "a" {
+		t.Errorf("expected no limit, got %q", got)
+	}
+}
+
+func TestStatusConditionMetrics(t *testing.T) {
+	conditions := []v1.PodCondition{
+		{Type: v1.PodReady, Status: v1.ConditionTrue},
+		{Type: "example.com/Gate", Status: v1.ConditionFalse},
+		{Type: v1.PodReady, Status: v1.ConditionFalse},
+		{Type: v1.PodScheduled, Status: v1.ConditionUnknown},
+	}
+	typeAndStatus := func(c v1.PodCondition) (string, v1.ConditionStatus) {
+		return string(c.Type), c.Status
+	}
+
+	want := []string{
+		"Ready true 1", "Ready false 0", "Ready unknown 0",
+		"example.com/Gate true 0", "example.com/Gate false 1", "example.com/Gate unknown 0",
+		"PodScheduled true 0", "PodScheduled false 0", "PodScheduled unknown 1",
+	}
+	var got []string
+	for _, m := range statusConditionMetrics(conditions, typeAndStatus) {
+		if !reflect.DeepEqual(m.LabelKeys, []string{"condition", "status"}) {
+			t.Fatalf("unexpected label keys %v", m.LabelKeys)
+		}
+		got = append(got, fmt.Sprintf("%s %s %v", m.LabelValues[0], m.LabelValues[1], m.Value))
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
@@ -61,9 +61,7 @@ var (
 				ms := []*metric.Metric{}
 
 				if !vwc.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						Value: float64(vwc.CreationTimestamp.Unix()),
-					})
+					ms = append(ms, metric.TimestampMetric(nil, nil, vwc.CreationTimestamp.Time))
 				}
 				return &metric.Family{
 					Metrics: ms,
@@ -35,16 +35,21 @@ var (
 	descVolumeAttachmentLabelsName          = "kube_volumeattachment_labels"
 	descVolumeAttachmentLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
 	descVolumeAttachmentLabelsDefaultLabels = []string{"volumeattachment"}
+)
 
-	volumeAttachmentMetricFamilies = []generator.FamilyGenerator{
-		*generator.NewFamilyGeneratorWithStability(
+func volumeAttachmentMetricFamilies(allowLabelsList []string) []generator.FamilyGenerator {
+	return []generator.FamilyGenerator{
+		*generator.NewLazyFamilyGenerator(
 			descVolumeAttachmentLabelsName,
 			descVolumeAttachmentLabelsHelp,
 			metric.Gauge,
 			basemetrics.ALPHA,
 			"",
 			wrapVolumeAttachmentFunc(func(va *storagev1.VolumeAttachment) *metric.Family {
-				labelKeys, labelValues := kubeMapToPrometheusLabels("label", va.Labels)
+				if len(allowLabelsList) == 0 {
+					return &metric.Family{}
+				}
+				labelKeys, labelValues := createPrometheusLabelKeysValues("label", va.Labels, allowLabelsList)
 				return &metric.Family{
 					Metrics: []*metric.Metric{
 						{
@@ -82,11 +87,7 @@ var (
 			"",
 			wrapVolumeAttachmentFunc(func(va *storagev1.VolumeAttachment) *metric.Family {
 				if !va.CreationTimestamp.IsZero() {
-					m := metric.Metric{
-						LabelKeys:   nil,
-						LabelValues: nil,
-						Value:       float64(va.CreationTimestamp.Unix()),
-					}
+					m := *metric.TimestampMetric(nil, nil, va.CreationTimestamp.Time)
 					return &metric.Family{Metrics: []*metric.Metric{&m}}
 				}
 				return &metric.Family{Metrics: []*metric.Metric{}}
@@ -151,7 +152,7 @@ var (
 			}),
 		),
 	}
-)
+}
 
 func wrapVolumeAttachmentFunc(f func(*storagev1.VolumeAttachment) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
@@ -45,6 +45,7 @@ func TestVolumeAttachmentStore(t *testing.T) {
 		volumename = "pvc-44f6ff3f-ba9b-49c4-9b95-8b01c4bd4bab"
 		cases      = []generateMetricsTestCase{
 			{
+				AllowLabelsList: []string{"app"},
 				Obj: &storagev1.VolumeAttachment{
 					ObjectMeta: metav1.ObjectMeta{
 						Generation: 2,
@@ -87,8 +88,8 @@ func TestVolumeAttachmentStore(t *testing.T) {
 		}
 	)
 	for i, c := range cases {
-		c.Func = generator.ComposeMetricGenFuncs(volumeAttachmentMetricFamilies)
-		c.Headers = generator.ExtractMetricFamilyHeaders(volumeAttachmentMetricFamilies)
+		c.Func = generator.ComposeMetricGenFuncs(volumeAttachmentMetricFamilies(c.AllowLabelsList))
+		c.Headers = generator.ExtractMetricFamilyHeaders(volumeAttachmentMetricFamilies(c.AllowLabelsList))
 		if err := c.run(); err != nil {
 			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
 		}
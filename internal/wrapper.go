@@ -25,7 +25,6 @@ import (
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/viper"
-	"gopkg.in/yaml.v3"
 	"k8s.io/klog/v2"
 
 	"k8s.io/kube-state-metrics/v2/pkg/app"
@@ -37,6 +36,9 @@ func RunKubeStateMetricsWrapper(opts *options.Options) {
 
 	KSMRunOrDie := func(ctx context.Context) {
 		if err := app.RunKubeStateMetricsWrapper(ctx, opts); err != nil {
+			if errors.Is(err, app.ErrTerminated) {
+				klog.FlushAndExit(klog.ExitFlushTimeout, 0)
+			}
 			klog.ErrorS(err, "Failed to run kube-state-metrics")
 			klog.FlushAndExit(klog.ExitFlushTimeout, 1)
 		}
@@ -55,8 +57,22 @@ func RunKubeStateMetricsWrapper(opts *options.Options) {
 			}
 			klog.FlushAndExit(klog.ExitFlushTimeout, 1)
 		}
+		lastConfigFile, err := os.ReadFile(filepath.Clean(file))
+		if err != nil {
+			klog.ErrorS(err, "failed to read options configuration file", "file", file)
+		}
 		cfgViper.OnConfigChange(func(e fsnotify.Event) {
 			klog.InfoS("Changes detected", "name", e.Name)
+			// Changes of the metric family filters are applied without
+			// restarting, see app.RunKubeStateMetrics.
+			configFile, err := os.ReadFile(filepath.Clean(file))
+			if err == nil {
+				onlyFilters, err := options.OnlyFamilyFiltersChanged(lastConfigFile, configFile)
+				lastConfigFile = configFile
+				if err == nil && onlyFilters {
+					return
+				}
+			}
 			cancel()
 			// Wait for the ports to be released.
 			<-time.After(3 * time.Second)
@@ -66,12 +82,9 @@ func RunKubeStateMetricsWrapper(opts *options.Options) {
 		cfgViper.WatchConfig()
 
 		// Merge configFile values with opts so we get the CustomResourceConfigFile from config as well
-		configFile, err := os.ReadFile(filepath.Clean(file))
-		if err != nil {
-			klog.ErrorS(err, "failed to read options configuration file", "file", file)
+		if err := opts.ApplyConfigFile(lastConfigFile); err != nil {
+			klog.ErrorS(err, "failed to unmarshal options configuration file", "file", file)
 		}
-
-		yaml.Unmarshal(configFile, opts)
 	}
 	if opts.CustomResourceConfigFile != "" {
 		crcViper := viper.New()
@@ -85,15 +98,8 @@ func RunKubeStateMetricsWrapper(opts *options.Options) {
 			}
 			klog.FlushAndExit(klog.ExitFlushTimeout, 1)
 		}
-		crcViper.OnConfigChange(func(e fsnotify.Event) {
-			klog.InfoS("Changes detected", "name", e.Name)
-			cancel()
-			// Wait for the ports to be released.
-			<-time.After(3 * time.Second)
-			ctx, cancel = context.WithCancel(context.Background())
-			go KSMRunOrDie(ctx)
-		})
-		crcViper.WatchConfig()
+		// Changes of the custom resource config file are applied without
+		// restarting, see app.RunKubeStateMetrics.
 	}
 	if opts.Kubeconfig != "" {
 		kubecfgViper := viper.New()
@@ -127,6 +127,9 @@
         resources: [
           'storageclasses',
           'volumeattachments',
+          'csidrivers',
+          'csinodes',
+          'csistoragecapacities',
         ],
         verbs: ['list', 'watch'],
       },
@@ -138,6 +141,20 @@
         ],
         verbs: ['list', 'watch'],
       },
+      {
+        apiGroups: ['scheduling.k8s.io'],
+        resources: [
+          'priorityclasses',
+        ],
+        verbs: ['list', 'watch'],
+      },
+      {
+        apiGroups: ['node.k8s.io'],
+        resources: [
+          'runtimeclasses',
+        ],
+        verbs: ['list', 'watch'],
+      },
       {
         apiGroups: ['networking.k8s.io'],
         resources: [
@@ -164,6 +181,13 @@
         ],
         verbs: ['list', 'watch'],
       },
+      {
+        apiGroups: ['events.k8s.io'],
+        resources: [
+          'events',
+        ],
+        verbs: ['list', 'watch'],
+      },
      ];
 
     {
@@ -197,7 +221,7 @@
       } },
       readinessProbe: { timeoutSeconds: 5, initialDelaySeconds: 5, httpGet: {
         port: 8081,
-        path: '/',
+        path: '/readyz',
       } },
     };
 
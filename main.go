@@ -21,6 +21,7 @@ import (
 	"k8s.io/klog/v2"
 
 	"k8s.io/kube-state-metrics/v2/internal"
+	"k8s.io/kube-state-metrics/v2/pkg/app"
 	"k8s.io/kube-state-metrics/v2/pkg/options"
 )
 
@@ -31,11 +32,8 @@ func main() {
 		internal.RunKubeStateMetricsWrapper(opts)
 	}
 	opts.AddFlags(cmd)
+	cmd.AddCommand(app.NewExplainCommand(), app.NewGenerateRulesCommand(), app.NewLintCommand(), app.NewMigrateConfigCommand(), app.NewValidateCommand(opts, cmd.Flags()))
 	if err := opts.Parse(); err != nil {
 		klog.FlushAndExit(klog.ExitFlushTimeout, 1)
 	}
-	if err := opts.Validate(); err != nil {
-		klog.ErrorS(err, "Validating options error")
-		klog.FlushAndExit(klog.ExitFlushTimeout, 1)
-	}
 }
@@ -0,0 +1,180 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+
+	"k8s.io/kube-state-metrics/v2/internal/store"
+	"k8s.io/kube-state-metrics/v2/pkg/customresourcestate"
+	"k8s.io/kube-state-metrics/v2/pkg/explain"
+	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
+	"k8s.io/kube-state-metrics/v2/pkg/options"
+)
+
+// explanation describes where a metric family comes from.
+type explanation struct {
+	family   generator.FamilyGenerator
+	resource string
+	kind     string
+	source   string
+	fields   []string
+}
+
+// NewExplainCommand returns the command which describes the resource, object
+// fields and generator a metric family is generated from.
+func NewExplainCommand() *cobra.Command {
+	opts := options.NewOptions()
+	cmd := &cobra.Command{
+		Use:   "explain METRIC_FAMILY",
+		Short: "Describe the source of a metric family.",
+		Long:  "Print the resource, the object fields and the generator a metric family is generated from, including families of the Custom Resource State config. Fields of built-in resources are detected by changing them on a synthetic object, so fields which only affect the metrics for specific values might be missing.",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := explainFamily(opts, args[0], os.Stdout); err != nil {
+				klog.ErrorS(err, "Failed to explain metric family")
+				klog.FlushAndExit(klog.ExitFlushTimeout, 1)
+			}
+			klog.FlushAndExit(klog.ExitFlushTimeout, 0)
+		},
+		Example: "kube-state-metrics explain kube_pod_status_phase",
+	}
+
+	cmd.Flags().StringVar(&opts.CustomResourceConfig, "custom-resource-state-config", "", "Inline Custom Resource State Metrics config YAML (experimental)")
+	cmd.Flags().StringVar(&opts.CustomResourceConfigFile, "custom-resource-state-config-file", "", "Path to a Custom Resource State Metrics config file (experimental)")
+	cmd.Flags().StringVar(&opts.MetricPrefix, "metric-prefix", generator.DefaultNamePrefix, "Prefix which replaces the 'kube_' prefix of the names of all metric families, as passed to kube-state-metrics.")
+
+	return cmd
+}
+
+// explainFamily writes the explanation of the metric family with the given
+// name to w.
+func explainFamily(opts *options.Options, name string, w io.Writer) error {
+	e, err := findFamily(opts, name)
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		return fmt.Errorf("metric family %s is not generated by any resource", name)
+	}
+
+	fmt.Fprintf(w, "FAMILY:    %s\n", e.family.Name)
+	fmt.Fprintf(w, "TYPE:      %s\n", e.family.Type)
+	fmt.Fprintf(w, "RESOURCE:  %s (%s)\n", e.resource, e.kind)
+	fmt.Fprintf(w, "GENERATOR: %s\n", e.source)
+	if e.family.OptIn {
+		fmt.Fprintf(w, "OPT-IN:    true\n")
+	}
+	fmt.Fprintf(w, "\nDESCRIPTION:\n     %s\n", e.family.Help)
+	fmt.Fprintf(w, "\nFIELDS:\n")
+	for _, f := range e.fields {
+		fmt.Fprintf(w, "   %s\n", f)
+	}
+	return nil
+}
+
+// findFamily returns the explanation of the metric family with the given
+// name, or nil if no resource generates it.
+func findFamily(opts *options.Options, name string) (*explanation, error) {
+	prefixed := func(families []generator.FamilyGenerator) []generator.FamilyGenerator {
+		if opts.MetricPrefix != "" && opts.MetricPrefix != generator.DefaultNamePrefix {
+			return generator.PrefixFamilyGenerators(opts.MetricPrefix, families)
+		}
+		return families
+	}
+
+	var found *explanation
+	err := visitMetricFamilies(store.AvailableResources(), generator.NewCompositeFamilyGeneratorFilter(), func(resource string, families []generator.FamilyGenerator, expectedType interface{}) {
+		if found != nil {
+			return
+		}
+		for i, f := range prefixed(families) {
+			if f.Name != name {
+				continue
+			}
+			found = &explanation{
+				family:   f,
+				resource: resource,
+				kind:     reflect.TypeOf(expectedType).Elem().String(),
+				source:   f.Source,
+				// Fields are detected with the family as generated by the
+				// stores, before the name is prefixed.
+				fields: explain.Fields(families[i], expectedType),
+			}
+			return
+		}
+	})
+	if err != nil || found != nil {
+		return found, err
+	}
+
+	resources, err := customResourceStateResources(opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range resources {
+		factory, err := customresourcestate.NewCustomResourceMetrics(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create metrics factory for %s: %w", r.GroupVersionKind, err)
+		}
+		// The families are generated in the order of the configured metrics,
+		// followed by the generation mismatch family.
+		for i, f := range prefixed(factory.MetricFamilyGenerators()) {
+			if f.Name != name {
+				continue
+			}
+			if i >= len(r.Metrics) {
+				return &explanation{
+					family:   f,
+					resource: factory.Name(),
+					kind:     schema.GroupVersionKind(r.GroupVersionKind).String(),
+					source:   "Custom Resource State, generation of the resource",
+					fields:   []string{"metadata.generation", "status.observedGeneration"},
+				}, nil
+			}
+			paths := map[string]struct{}{}
+			for _, p := range r.Metrics[i].FieldPaths() {
+				paths[p] = struct{}{}
+			}
+			for _, p := range r.LabelsFromPath {
+				paths[strings.Join(p, ".")] = struct{}{}
+			}
+			fields := make([]string, 0, len(paths))
+			for p := range paths {
+				fields = append(fields, p)
+			}
+			sort.Strings(fields)
+			return &explanation{
+				family:   f,
+				resource: factory.Name(),
+				kind:     schema.GroupVersionKind(r.GroupVersionKind).String(),
+				source:   fmt.Sprintf("Custom Resource State config, metric %q", r.Metrics[i].Name),
+				fields:   fields,
+			}, nil
+		}
+	}
+	return nil, nil
+}
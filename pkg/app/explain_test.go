@@ -0,0 +1,83 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/kube-state-metrics/v2/pkg/options"
+)
+
+func TestExplainFamily(t *testing.T) {
+	opts := options.NewOptions()
+	opts.CustomResourceConfig = `
+spec:
+  resources:
+    - groupVersionKind:
+        group: myteam.io
+        version: v1
+        kind: Foo
+      labelsFromPath:
+        name: [metadata, name]
+      metrics:
+        - name: ready_count
+          help: Number of ready replicas of foo.
+          each:
+            type: Gauge
+            gauge:
+              path: [status, sub]
+              valueFrom: [ready]
+`
+
+	tests := []struct {
+		family string
+		want   []string
+	}{
+		{
+			family: "kube_pod_status_phase",
+			want: []string{
+				"RESOURCE:  pods (v1.Pod)",
+				"GENERATOR: internal/store/pod.go:",
+				"FIELDS:\n   metadata.name\n   metadata.namespace\n   metadata.uid\n   status.phase\n",
+			},
+		},
+		{
+			family: "kube_customresource_ready_count",
+			want: []string{
+				"RESOURCE:  foos (myteam.io/v1, Kind=Foo)",
+				`GENERATOR: Custom Resource State config, metric "ready_count"`,
+				"FIELDS:\n   metadata.name\n   status.sub.ready\n",
+			},
+		},
+	}
+	for _, test := range tests {
+		var out strings.Builder
+		if err := explainFamily(opts, test.family, &out); err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range test.want {
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("expected explanation of %s to contain %q, got:\n%s", test.family, want, out.String())
+			}
+		}
+	}
+
+	if err := explainFamily(opts, "kube_unknown", &strings.Builder{}); err == nil {
+		t.Errorf("expected an error for an unknown metric family")
+	}
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+
+	"k8s.io/klog/v2"
+
+	"k8s.io/kube-state-metrics/v2/internal/store"
+	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
+	"k8s.io/kube-state-metrics/v2/pkg/options"
+)
+
+// resourcesFilterName is the filter reported for the metric families of
+// resources which are not enabled.
+const resourcesFilterName = "resources"
+
+var (
+	// registeredFiltersMtx protects registeredFilters.
+	registeredFiltersMtx sync.Mutex
+	// registeredFilters are the filters of the metric families registered
+	// with RegisterFamilyGeneratorFilter.
+	registeredFilters []generator.FamilyGeneratorFilter
+)
+
+// RegisterFamilyGeneratorFilter registers a filter of the metric families,
+// e.g. one of a program embedding kube-state-metrics which decides by the
+// ownership of the families in an external system. It is composed with the
+// built-in filters of the options, so that metric families are only exposed
+// if all filters pass them, and its name is reported for the families it
+// rejects on /debug/filters and by the kube_state_metrics_filtered_families
+// self metric. Filters must be registered before RunKubeStateMetrics is
+// called, and are tested whenever the stores are built.
+func RegisterFamilyGeneratorFilter(name string, filter generator.FamilyGeneratorFilter) {
+	registeredFiltersMtx.Lock()
+	defer registeredFiltersMtx.Unlock()
+	registeredFilters = append(registeredFilters, generator.NewNamedFamilyGeneratorFilter(name, filter))
+}
+
+// registeredFamilyGeneratorFilters returns the filters registered with
+// RegisterFamilyGeneratorFilter.
+func registeredFamilyGeneratorFilters() []generator.FamilyGeneratorFilter {
+	registeredFiltersMtx.Lock()
+	defer registeredFiltersMtx.Unlock()
+	return append([]generator.FamilyGeneratorFilter(nil), registeredFilters...)
+}
+
+// filterDecision describes whether a metric family is emitted and which
+// filter rejected it otherwise.
+type filterDecision struct {
+	Family   string `json:"family"`
+	Resource string `json:"resource"`
+	Emitted  bool   `json:"emitted"`
+	Filter   string `json:"filter,omitempty"`
+}
+
+// knownFamily is a metric family of a resource, as it is exposed and as it is
+// tested by the filters.
+type knownFamily struct {
+	family   generator.FamilyGenerator
+	name     string
+	resource string
+	enabled  bool
+}
+
+// newFiltersHandler returns the handler which reports for every metric family
+// of all available resources and of the Custom Resource State config whether
+// it is emitted and which filter decided that. The filter is tested on each
+// request, so that filters changing at runtime are reported as well.
+func newFiltersHandler(opts *options.Options, filter generator.FamilyGeneratorFilter) (http.Handler, error) {
+	families, err := knownFamilies(opts)
+	if err != nil {
+		return nil, err
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(filterDecisions(families, filter)); err != nil {
+			klog.ErrorS(err, "Failed to write filter decisions")
+		}
+	}), nil
+}
+
+// knownFamilies returns the metric families of all available resources and of
+// the Custom Resource State config of the given options, sorted by resource
+// and name.
+func knownFamilies(opts *options.Options) ([]knownFamily, error) {
+	enabled := options.ResourceSet{}
+	switch {
+	case opts.CustomResourcesOnly:
+	case len(opts.Resources) == 0:
+		enabled = options.DefaultResources
+	default:
+		enabled = opts.Resources
+	}
+	exposedName := func(f generator.FamilyGenerator) string {
+		if opts.MetricPrefix != "" && opts.MetricPrefix != generator.DefaultNamePrefix {
+			return generator.PrefixFamilyGenerators(opts.MetricPrefix, []generator.FamilyGenerator{f})[0].Name
+		}
+		return f.Name
+	}
+
+	var families []knownFamily
+	err := visitMetricFamilies(store.AvailableResources(), generator.NewCompositeFamilyGeneratorFilter(), func(resource string, metricFamilies []generator.FamilyGenerator, _ interface{}) {
+		_, ok := enabled[resource]
+		for _, f := range metricFamilies {
+			families = append(families, knownFamily{family: f, name: exposedName(f), resource: resource, enabled: ok})
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	factories, err := customResourceFactories(opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, factory := range factories {
+		for _, f := range factory.MetricFamilyGenerators() {
+			families = append(families, knownFamily{family: f, name: exposedName(f), resource: factory.Name(), enabled: true})
+		}
+	}
+
+	sort.Slice(families, func(i, j int) bool {
+		if families[i].resource != families[j].resource {
+			return families[i].resource < families[j].resource
+		}
+		return families[i].name < families[j].name
+	})
+	return families, nil
+}
+
+// filterDecisions tests the metric families with the filter.
+func filterDecisions(families []knownFamily, filter generator.FamilyGeneratorFilter) []filterDecision {
+	decisions := make([]filterDecision, 0, len(families))
+	for _, f := range families {
+		d := filterDecision{Family: f.name, Resource: f.resource}
+		if !f.enabled {
+			d.Filter = resourcesFilterName
+		} else if name, rejected := generator.RejectingFilter(filter, f.family); rejected {
+			d.Filter = name
+		} else {
+			d.Emitted = true
+		}
+		decisions = append(decisions, d)
+	}
+	return decisions
+}
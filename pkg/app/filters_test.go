@@ -0,0 +1,164 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"k8s.io/kube-state-metrics/v2/internal/store"
+	"k8s.io/kube-state-metrics/v2/pkg/degradation"
+	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
+	"k8s.io/kube-state-metrics/v2/pkg/options"
+)
+
+func TestFiltersHandler(t *testing.T) {
+	opts := options.NewOptions()
+	opts.Resources = options.ResourceSet{"pods": {}}
+	opts.MetricDenylist = options.MetricSet{"kube_pod_info": {}}
+	filter, err := newFamilyGeneratorFilter(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	degradationFilter := &degradation.Filter{}
+	handler, err := newFiltersHandler(opts, generator.NewCompositeFamilyGeneratorFilter(filter, generator.NewNamedFamilyGeneratorFilter("memory_degradation", degradationFilter)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decisions := func() map[string]filterDecision {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, filtersPath, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rec.Code)
+		}
+		var list []filterDecision
+		if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+			t.Fatal(err)
+		}
+		byFamily := make(map[string]filterDecision, len(list))
+		for _, d := range list {
+			byFamily[d.Family] = d
+		}
+		return byFamily
+	}
+
+	got := decisions()
+	for family, want := range map[string]filterDecision{
+		"kube_pod_status_phase":  {Family: "kube_pod_status_phase", Resource: "pods", Emitted: true},
+		"kube_pod_labels":        {Family: "kube_pod_labels", Resource: "pods", Emitted: true},
+		"kube_pod_info":          {Family: "kube_pod_info", Resource: "pods", Filter: "allow_deny_list"},
+		"kube_pod_nodeselectors": {Family: "kube_pod_nodeselectors", Resource: "pods", Filter: "opt_in"},
+		"kube_node_info":         {Family: "kube_node_info", Resource: "nodes", Filter: resourcesFilterName},
+	} {
+		if got[family] != want {
+			t.Errorf("expected %+v, got %+v", want, got[family])
+		}
+	}
+
+	// Filters changing at runtime are reported on the next request.
+	degradationFilter.Degrade()
+	if d := decisions()["kube_pod_labels"]; d.Emitted || d.Filter != "memory_degradation" {
+		t.Errorf("expected kube_pod_labels to be rejected by the memory degradation, got %+v", d)
+	}
+}
+
+func TestRegisterFamilyGeneratorFilter(t *testing.T) {
+	registered := registeredFilters
+	t.Cleanup(func() { registeredFilters = registered })
+
+	RegisterFamilyGeneratorFilter("team_ownership", generator.FamilyGeneratorFilterFunc(func(f generator.FamilyGenerator) bool {
+		return f.Name != "kube_pod_status_phase"
+	}))
+
+	opts := options.NewOptions()
+	opts.Resources = options.ResourceSet{"pods": {}}
+	filter, err := newFamilyGeneratorFilter(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	families, err := knownFamilies(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range filterDecisions(families, filter) {
+		switch d.Family {
+		case "kube_pod_status_phase":
+			if d.Emitted || d.Filter != "team_ownership" {
+				t.Errorf("expected kube_pod_status_phase to be rejected by the registered filter, got %+v", d)
+			}
+		case "kube_pod_info":
+			if !d.Emitted {
+				t.Errorf("expected kube_pod_info to be emitted, got %+v", d)
+			}
+		}
+	}
+}
+
+func TestCanonicalResourceUnits(t *testing.T) {
+	opts := options.NewOptions()
+	opts.Resources = options.ResourceSet{"pods": {}}
+	opts.MetricOptInList = options.MetricSet{"kube_pod_nodeselectors": {}}
+	opts.CanonicalResourceUnits = true
+	filter, err := newFamilyGeneratorFilter(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	families, err := knownFamilies(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	emitted := map[string]bool{}
+	for _, d := range filterDecisions(families, filter) {
+		emitted[d.Family] = d.Emitted
+	}
+	for family, want := range map[string]bool{
+		"kube_pod_overhead":              true,
+		"kube_pod_overhead_cpu_cores":    false,
+		"kube_pod_overhead_memory_bytes": false,
+		"kube_pod_nodeselectors":         true,
+	} {
+		if emitted[family] != want {
+			t.Errorf("expected %s to be emitted: %t, got %t", family, want, emitted[family])
+		}
+	}
+	if len(opts.MetricOptInList) != 1 || len(opts.MetricOptOutList) != 0 {
+		t.Errorf("expected the options to be unchanged, got opt-in list %v and opt-out list %v", opts.MetricOptInList, opts.MetricOptOutList)
+	}
+}
+
+func TestDisabledFamiliesOfOtherResources(t *testing.T) {
+	opts := options.NewOptions()
+	opts.Resources = options.ResourceSet{"pods": {}}
+	opts.MetricFamiliesDisabled = options.LabelsAllowList{"pods": {"kube_pod_info"}}
+	if _, err := newFamilyGeneratorFilter(opts); err != nil {
+		t.Fatalf("did not expect a family of the resource to fail, got %v", err)
+	}
+
+	opts.NamingConvention = store.NamingConventionV3
+	opts.MetricFamiliesDisabled = options.LabelsAllowList{"pods": {"kube_pod_created_timestamp_seconds"}}
+	if _, err := newFamilyGeneratorFilter(opts); err != nil {
+		t.Fatalf("did not expect a renamed family of the resource to fail, got %v", err)
+	}
+
+	opts.MetricFamiliesDisabled = options.LabelsAllowList{"nodes": {"kube_pod_info"}}
+	if _, err := newFamilyGeneratorFilter(opts); err == nil {
+		t.Fatal("expected a family of another resource to fail")
+	}
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	"k8s.io/kube-state-metrics/v2/pkg/options"
+	"k8s.io/kube-state-metrics/v2/pkg/watch"
+)
+
+const (
+	// apiserverCheckInterval is the duration for which the result of the
+	// apiserver health check is cached, so that frequent probes don't
+	// cause requests to the apiserver.
+	apiserverCheckInterval = 10 * time.Second
+	// apiserverCheckTimeout is the timeout of the requests of the
+	// apiserver health check.
+	apiserverCheckTimeout = 5 * time.Second
+)
+
+// healthCheck is a named check of /healthz.
+type healthCheck struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+// newHealthChecks returns the checks of /healthz configured by the options.
+// The checks are skipped without a kube client, as the stores are then
+// populated from snapshots or benchmark objects instead of the apiserver.
+func newHealthChecks(opts *options.Options, kubeClient clientset.Interface, writeTracker *watch.WriteTracker) []healthCheck {
+	if !opts.HealthzAPIServerCheck && writeTracker == nil {
+		return nil
+	}
+	if kubeClient == nil {
+		klog.InfoS("Skipping the health checks of the apiserver and the store writes, as the apiserver is not watched")
+		return nil
+	}
+	var checks []healthCheck
+	if opts.HealthzAPIServerCheck {
+		checks = append(checks, apiserverHealthCheck(func(ctx context.Context) error {
+			return kubeClient.Discovery().RESTClient().Get().AbsPath("/version").Do(ctx).Error()
+		}, apiserverCheckInterval))
+	}
+	if writeTracker != nil {
+		checks = append(checks, storeWriteHealthCheck(writeTracker.LastWrite, time.Now(), opts.HealthzStoreWriteMaxAge))
+	}
+	return checks
+}
+
+// healthzHandler returns the handler of /healthz, which runs the given
+// checks and writes the result of each of them to the response. It responds
+// with 503 Service Unavailable if a check fails. Without checks, it always
+// succeeds.
+func healthzHandler(checks []healthCheck) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var b strings.Builder
+		failed := false
+		for _, c := range checks {
+			if err := c.check(r.Context()); err != nil {
+				failed = true
+				fmt.Fprintf(&b, "[-]%s failed: %v\n", c.name, err)
+				continue
+			}
+			fmt.Fprintf(&b, "[+]%s ok\n", c.name)
+		}
+		if failed {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			b.WriteString("healthz check failed")
+		} else {
+			w.WriteHeader(http.StatusOK)
+			b.WriteString(http.StatusText(http.StatusOK))
+		}
+		w.Write([]byte(b.String()))
+	}
+}
+
+// apiserverHealthCheck returns a check which fails if the apiserver can't be
+// reached with ping. The result is cached for the given interval.
+func apiserverHealthCheck(ping func(ctx context.Context) error, interval time.Duration) healthCheck {
+	var (
+		mutex   sync.Mutex
+		checked time.Time
+		lastErr error
+	)
+	return healthCheck{
+		name: "apiserver",
+		check: func(ctx context.Context) error {
+			mutex.Lock()
+			defer mutex.Unlock()
+			if !checked.IsZero() && time.Since(checked) < interval {
+				return lastErr
+			}
+			ctx, cancel := context.WithTimeout(ctx, apiserverCheckTimeout)
+			defer cancel()
+			lastErr = ping(ctx)
+			checked = time.Now()
+			return lastErr
+		},
+	}
+}
+
+// storeWriteHealthCheck returns a check which fails if nothing was written
+// to the stores for longer than maxAge, counted from start until the first
+// write.
+func storeWriteHealthCheck(lastWrite func() time.Time, start time.Time, maxAge time.Duration) healthCheck {
+	return healthCheck{
+		name: "store-writes",
+		check: func(context.Context) error {
+			last := lastWrite()
+			if last.Before(start) {
+				last = start
+			}
+			if age := time.Since(last); age > maxAge {
+				return fmt.Errorf("no store write for %s", age.Truncate(time.Second))
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHealthzHandler(t *testing.T) {
+	pings := 0
+	var pingErr error
+	apiserver := apiserverHealthCheck(func(context.Context) error {
+		pings++
+		return pingErr
+	}, time.Hour)
+	lastWrite := time.Now()
+	storeWrites := storeWriteHealthCheck(func() time.Time { return lastWrite }, time.Now().Add(-5*time.Minute), time.Minute)
+
+	get := func(checks ...healthCheck) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		healthzHandler(checks).ServeHTTP(w, httptest.NewRequest("GET", healthzPath, nil))
+		return w
+	}
+
+	if w := get(); w.Code != http.StatusOK || w.Body.String() != "OK" {
+		t.Errorf("expected OK without checks, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if w := get(apiserver, storeWrites); w.Code != http.StatusOK || w.Body.String() != "[+]apiserver ok\n[+]store-writes ok\nOK" {
+		t.Errorf("expected all checks to succeed, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// The result of the apiserver check is cached.
+	pingErr = errors.New("connection refused")
+	lastWrite = time.Now().Add(-2 * time.Minute)
+	if w := get(apiserver, storeWrites); w.Code != http.StatusServiceUnavailable || w.Body.String() != "[+]apiserver ok\n[-]store-writes failed: no store write for 2m0s\nhealthz check failed" {
+		t.Errorf("expected the store writes check to fail, got %d: %s", w.Code, w.Body.String())
+	}
+	if pings != 1 {
+		t.Errorf("expected the apiserver check to be cached, got %d pings", pings)
+	}
+
+	// Until the first write, the age is counted from the start.
+	notWritten := storeWriteHealthCheck(func() time.Time { return time.Time{} }, time.Now(), time.Minute)
+	if w := get(notWritten); w.Code != http.StatusOK {
+		t.Errorf("expected the store writes check to succeed after the start, got %d: %s", w.Code, w.Body.String())
+	}
+
+	expired := apiserverHealthCheck(func(context.Context) error { return pingErr }, 0)
+	if w := get(expired); w.Code != http.StatusServiceUnavailable || w.Body.String() != "[-]apiserver failed: connection refused\nhealthz check failed" {
+		t.Errorf("expected the apiserver check to fail, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	"k8s.io/kube-state-metrics/v2/internal/store"
+	"k8s.io/kube-state-metrics/v2/pkg/customresource"
+	"k8s.io/kube-state-metrics/v2/pkg/customresourcestate"
+	"k8s.io/kube-state-metrics/v2/pkg/lint"
+	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
+	"k8s.io/kube-state-metrics/v2/pkg/options"
+)
+
+// NewLintCommand returns the command which validates the metric families of
+// the given resources and Custom Resource State config.
+func NewLintCommand() *cobra.Command {
+	opts := options.NewOptions()
+	cmd := &cobra.Command{
+		Use:   "lint",
+		Short: "Validate the generated metric families.",
+		Long:  "Render the metric families of the given resources and Custom Resource State config against synthetic objects and validate their names, HELP and TYPE, labels and duplicates, similar to 'promtool check metrics'.",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			ok, err := lintCommand(opts, os.Stdout)
+			if err != nil {
+				klog.ErrorS(err, "Failed to lint metric families")
+				klog.FlushAndExit(klog.ExitFlushTimeout, 2)
+			}
+			if !ok {
+				klog.FlushAndExit(klog.ExitFlushTimeout, 1)
+			}
+			klog.FlushAndExit(klog.ExitFlushTimeout, 0)
+		},
+		Example: "kube-state-metrics lint --custom-resource-state-config-file=crs.yaml",
+	}
+
+	cmd.Flags().StringVar(&opts.CustomResourceConfig, "custom-resource-state-config", "", "Inline Custom Resource State Metrics config YAML (experimental)")
+	cmd.Flags().StringVar(&opts.CustomResourceConfigFile, "custom-resource-state-config-file", "", "Path to a Custom Resource State Metrics config file (experimental)")
+	cmd.Flags().BoolVar(&opts.CustomResourcesOnly, "custom-resource-state-only", false, "Only lint Custom Resource State metrics (experimental)")
+	cmd.Flags().StringVar(&opts.MetricPrefix, "metric-prefix", generator.DefaultNamePrefix, "Prefix which replaces the 'kube_' prefix of the names of all metric families, as passed to kube-state-metrics.")
+	cmd.Flags().Var(&opts.Resources, "resources", fmt.Sprintf("Comma-separated list of Resources to lint. Defaults to %q", &options.DefaultResources))
+
+	return cmd
+}
+
+// lintCommand writes the problems of the metric families enabled with the
+// given options to w and returns whether there were none.
+func lintCommand(opts *options.Options, w io.Writer) (bool, error) {
+	problems, err := lintMetricFamilies(opts)
+	if err != nil {
+		return false, err
+	}
+	for _, p := range problems {
+		fmt.Fprintln(w, p)
+	}
+	return len(problems) == 0, nil
+}
+
+// lintMetricFamilies returns the problems of all metric families of the
+// resources and custom resources enabled with the given options. Metric
+// families are not filtered, so that problems of opt-in families are found
+// too.
+func lintMetricFamilies(opts *options.Options) ([]lint.Problem, error) {
+	l := lint.New()
+	lintFamilies := func(resource string, families []generator.FamilyGenerator, expectedType interface{}) {
+		if opts.MetricPrefix != "" && opts.MetricPrefix != generator.DefaultNamePrefix {
+			families = generator.PrefixFamilyGenerators(opts.MetricPrefix, families)
+		}
+		l.Lint(resource, families, lint.SyntheticObject(expectedType))
+	}
+
+	if !opts.CustomResourcesOnly {
+		resources := opts.Resources.AsSlice()
+		if len(resources) == 0 {
+			resources = options.DefaultResources.AsSlice()
+		}
+		if err := visitMetricFamilies(resources, generator.NewCompositeFamilyGeneratorFilter(), lintFamilies); err != nil {
+			return nil, err
+		}
+	}
+
+	factories, err := customResourceFactories(opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range factories {
+		lintFamilies(f.Name(), f.MetricFamilyGenerators(), f.ExpectedType())
+	}
+
+	return l.Problems(), nil
+}
+
+// visitMetricFamilies calls visit with the metric families of each of the
+// given resources which pass the filter. It builds the stores without
+// starting them.
+func visitMetricFamilies(resources []string, filter generator.FamilyGeneratorFilter, visit func(resource string, families []generator.FamilyGenerator, expectedType interface{})) error {
+	for _, r := range resources {
+		// Event stores aggregate events in their reflectors instead of being
+		// built through the BuildStoresFunc.
+		if r == "events" {
+			continue
+		}
+
+		b := store.NewBuilder()
+		if err := b.WithEnabledResources([]string{r}); err != nil {
+			return err
+		}
+		b.WithFamilyGeneratorFilter(filter)
+		b.WithGenerateStoresFunc(func(resourceName string,
+			metricFamilies []generator.FamilyGenerator,
+			expectedType interface{},
+			_ func(kubeClient clientset.Interface, ns string, fieldSelector string) cache.ListerWatcher,
+			_ bool,
+		) []cache.Store {
+			visit(resourceName, generator.FilterFamilyGenerators(filter, metricFamilies), expectedType)
+			return nil
+		})
+		b.BuildStores()
+	}
+	return nil
+}
+
+// customResourceFactories returns the factories of the custom resources of
+// the Custom Resource State config of the given options, as returned by
+// customResourceStateResources.
+func customResourceFactories(opts *options.Options) ([]customresource.RegistryFactory, error) {
+	resources, err := customResourceStateResources(opts)
+	if err != nil {
+		return nil, err
+	}
+	factories := make([]customresource.RegistryFactory, 0, len(resources))
+	for _, r := range resources {
+		f, err := customresourcestate.NewCustomResourceMetrics(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create metrics factory for %s: %w", r.GroupVersionKind, err)
+		}
+		factories = append(factories, f)
+	}
+	return factories, nil
+}
+
+// customResourceStateResources returns the resources of the Custom Resource
+// State config of the given options. Resources whose GroupVersionKind
+// contains wildcards can only be resolved against a cluster and are skipped.
+func customResourceStateResources(opts *options.Options) ([]customresourcestate.Resource, error) {
+	config, err := resolveCustomResourceConfig(opts)
+	if err != nil || config == nil {
+		return nil, err
+	}
+	var crs customresourcestate.Metrics
+	if err := config.Decode(&crs); err != nil {
+		return nil, fmt.Errorf("failed to parse Custom Resource State metrics: %w", err)
+	}
+
+	var resources []customresourcestate.Resource
+	for _, r := range crs.Spec.Resources {
+		gvk := schema.GroupVersionKind(r.GroupVersionKind)
+		if gvk.Version == "" || gvk.Kind == "" || strings.Contains(gvk.String(), "*") {
+			klog.InfoS("Skipping custom resource with wildcard GroupVersionKind", "gvk", gvk)
+			continue
+		}
+		resources = append(resources, r)
+	}
+	return resources, nil
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+
+	"k8s.io/kube-state-metrics/v2/internal/store"
+	"k8s.io/kube-state-metrics/v2/pkg/lint"
+	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
+	"k8s.io/kube-state-metrics/v2/pkg/options"
+)
+
+func TestLintMetricFamilies(t *testing.T) {
+	opts := options.NewOptions()
+	problems, err := lintMetricFamilies(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range problems {
+		t.Errorf("unexpected problem of the default resources: %s", p)
+	}
+
+	opts.CustomResourcesOnly = true
+	opts.CustomResourceConfig = `
+spec:
+  resources:
+    - groupVersionKind:
+        group: myteam.io
+        version: v1
+        kind: Foo
+      metrics:
+        - name: uptime-seconds
+          help: Uptime of foo.
+          each:
+            type: Gauge
+            gauge:
+              path: [status, uptime]
+    - groupVersionKind:
+        group: myteam.io
+        version: "*"
+        kind: Bar
+      metrics:
+        - name: invalid-but-skipped
+          each:
+            type: Gauge
+            gauge:
+              path: [status, uptime]
+`
+	problems, err = lintMetricFamilies(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) != 1 || problems[0].String() != "foos: kube_customresource_uptime-seconds: invalid metric name" {
+		var got []string
+		for _, p := range problems {
+			got = append(got, p.String())
+		}
+		t.Errorf("expected an invalid metric name of the custom resource, got:\n%s", strings.Join(got, "\n"))
+	}
+}
+
+// TestLabelsAnnotationsFamiliesWithoutAllowlist ensures that the labels and
+// annotations families of the built-in resources don't generate metrics,
+// and thus don't take memory in the stores, unless they are allowlisted.
+func TestLabelsAnnotationsFamiliesWithoutAllowlist(t *testing.T) {
+	err := visitMetricFamilies(store.AvailableResources(), generator.NewCompositeFamilyGeneratorFilter(), func(resource string, families []generator.FamilyGenerator, expectedType interface{}) {
+		obj := lint.SyntheticObject(expectedType)
+		o, err := meta.Accessor(obj)
+		if err != nil {
+			return
+		}
+		o.SetName("name")
+		o.SetNamespace("namespace")
+		o.SetLabels(map[string]string{"app": "example"})
+		o.SetAnnotations(map[string]string{"team": "example"})
+		for _, f := range families {
+			if !strings.HasSuffix(f.Name, "_labels") && !strings.HasSuffix(f.Name, "_annotations") {
+				continue
+			}
+			if metrics := f.Generate(obj).Metrics; len(metrics) > 0 {
+				t.Errorf("expected %s of %s not to generate metrics without allowlist, got %d", f.Name, resource, len(metrics))
+			}
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
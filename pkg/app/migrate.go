@@ -0,0 +1,69 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+
+	"k8s.io/kube-state-metrics/v2/pkg/customresourcestate"
+)
+
+// NewMigrateConfigCommand returns the command which migrates a Custom
+// Resource State config to the current apiVersion.
+func NewMigrateConfigCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate-config FILE",
+		Short: "Migrate a Custom Resource State config to the current apiVersion.",
+		Long:  fmt.Sprintf("Write the Custom Resource State config of FILE, or of stdin if FILE is '-', migrated to apiVersion %s to stdout. The order of keys and comments are kept. Configs of older apiVersions are migrated when they are loaded as well, so that migrating them is only required once support for their apiVersion is removed.", customresourcestate.ConfigAPIVersion),
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := migrateConfig(args[0], os.Stdin, os.Stdout); err != nil {
+				klog.ErrorS(err, "Failed to migrate Custom Resource State config")
+				klog.FlushAndExit(klog.ExitFlushTimeout, 1)
+			}
+			klog.FlushAndExit(klog.ExitFlushTimeout, 0)
+		},
+		Example: "kube-state-metrics migrate-config crs.yaml > crs-migrated.yaml",
+	}
+	return cmd
+}
+
+// migrateConfig writes the config of the file, or of stdin if file is '-',
+// migrated to the current apiVersion to w.
+func migrateConfig(file string, stdin io.Reader, w io.Writer) error {
+	r := stdin
+	if file != "-" {
+		f, err := os.Open(filepath.Clean(file))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	from, err := customresourcestate.MigrateConfig(r, w)
+	if err != nil {
+		return err
+	}
+	klog.InfoS("Migrated Custom Resource State config", "apiVersion", from, "to", customresourcestate.ConfigAPIVersion)
+	return nil
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	"k8s.io/klog/v2"
+
+	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
+	"k8s.io/kube-state-metrics/v2/pkg/options"
+	"k8s.io/kube-state-metrics/v2/pkg/rules"
+)
+
+// NewGenerateRulesCommand returns the command which writes Prometheus
+// recording and alerting rules for the metric families exposed with the
+// given flags to stdout.
+func NewGenerateRulesCommand() *cobra.Command {
+	opts := options.NewOptions()
+	var job string
+	cmd := &cobra.Command{
+		Use:   "generate-rules",
+		Short: "Generate Prometheus rules for the enabled metrics.",
+		Long:  "Generate Prometheus recording rules and alerts which only reference metric families that are exposed with the given resources, metric lists and shards.",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := generateRules(opts, job, os.Stdout); err != nil {
+				klog.ErrorS(err, "Failed to generate rules")
+				klog.FlushAndExit(klog.ExitFlushTimeout, 1)
+			}
+			klog.FlushAndExit(klog.ExitFlushTimeout, 0)
+		},
+		Example: "kube-state-metrics generate-rules --resources=pods,deployments --total-shards=2 > rules.yaml",
+	}
+
+	cmd.Flags().IntVar(&opts.TotalShards, "total-shards", 1, "The total number of shards kube-state-metrics runs with.")
+	cmd.Flags().StringVar(&job, "job", "kube-state-metrics", "Job label of the scraped kube-state-metrics metrics.")
+	cmd.Flags().Var(&opts.MetricAllowlist, "metric-allowlist", "Comma-separated list of metrics kube-state-metrics exposes, as passed to kube-state-metrics.")
+	cmd.Flags().Var(&opts.MetricDenylist, "metric-denylist", "Comma-separated list of metrics kube-state-metrics does not expose, as passed to kube-state-metrics.")
+	cmd.Flags().Var(&opts.MetricOptInList, "metric-opt-in-list", "Comma-separated list of opt-in metrics kube-state-metrics exposes, as passed to kube-state-metrics.")
+	cmd.Flags().Var(&opts.MetricOptOutList, "metric-opt-out-list", "Comma-separated list of metrics kube-state-metrics does not expose, as passed to kube-state-metrics.")
+	cmd.Flags().Var(&opts.Resources, "resources", fmt.Sprintf("Comma-separated list of Resources kube-state-metrics exposes. Defaults to %q", &options.DefaultResources))
+
+	return cmd
+}
+
+// generateRules writes the rules for the metric families exposed with the
+// given options to w.
+func generateRules(opts *options.Options, job string, w io.Writer) error {
+	filter, err := newFamilyGeneratorFilter(opts)
+	if err != nil {
+		return err
+	}
+	resources := opts.Resources.AsSlice()
+	if len(resources) == 0 {
+		resources = options.DefaultResources.AsSlice()
+	}
+	families, err := exposedMetricFamilies(resources, filter)
+	if err != nil {
+		return err
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(rules.Generate(families, opts.TotalShards, job)); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// exposedMetricFamilies returns the names of the metric families of the given
+// resources which pass the filter.
+func exposedMetricFamilies(resources []string, filter generator.FamilyGeneratorFilter) (map[string]struct{}, error) {
+	families := map[string]struct{}{}
+	err := visitMetricFamilies(resources, filter, func(_ string, metricFamilies []generator.FamilyGenerator, _ interface{}) {
+		for _, f := range metricFamilies {
+			families[f.Name] = struct{}{}
+		}
+	})
+	return families, err
+}
@@ -20,24 +20,37 @@ import (
 	"context"
 	"crypto/md5" //nolint:gosec
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
 	"net/http/pprof"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/oklog/run"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/collectors"
+	versionCollector "github.com/prometheus/client_golang/prometheus/collectors/version"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/prometheus/common/version"
 	"github.com/prometheus/exporter-toolkit/web"
-	"gopkg.in/yaml.v3"
+	"github.com/spf13/viper"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientset "k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth" // Initialize common client auth plugins.
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/klog/v2"
@@ -47,17 +60,44 @@ import (
 	"k8s.io/kube-state-metrics/v2/pkg/allowdenylist"
 	"k8s.io/kube-state-metrics/v2/pkg/customresource"
 	"k8s.io/kube-state-metrics/v2/pkg/customresourcestate"
+	"k8s.io/kube-state-metrics/v2/pkg/degradation"
+	"k8s.io/kube-state-metrics/v2/pkg/disabledfamilies"
+	"k8s.io/kube-state-metrics/v2/pkg/federate"
+	"k8s.io/kube-state-metrics/v2/pkg/httpsd"
+	"k8s.io/kube-state-metrics/v2/pkg/metric"
 	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
 	"k8s.io/kube-state-metrics/v2/pkg/metricshandler"
 	"k8s.io/kube-state-metrics/v2/pkg/optin"
 	"k8s.io/kube-state-metrics/v2/pkg/options"
+	"k8s.io/kube-state-metrics/v2/pkg/optout"
+	streamapi "k8s.io/kube-state-metrics/v2/pkg/streamapi/v1alpha1"
+	"k8s.io/kube-state-metrics/v2/pkg/telemetry"
 	"k8s.io/kube-state-metrics/v2/pkg/util"
+	"k8s.io/kube-state-metrics/v2/pkg/util/memlimit"
 	"k8s.io/kube-state-metrics/v2/pkg/util/proc"
+	"k8s.io/kube-state-metrics/v2/pkg/watch"
 )
 
 const (
-	metricsPath = "/metrics"
-	healthzPath = "/healthz"
+	metricsPath   = "/metrics"
+	healthzPath   = "/healthz"
+	startupPath   = "/startupz"
+	readyPath     = "/readyz"
+	schemaPath    = "/metrics/schema"
+	influxPath    = "/metrics/influx"
+	deltaPath     = "/metrics/delta"
+	resourcesPath = "/metrics/resources/"
+	federatePath  = "/federate"
+	podsSDPath    = "/discovery/pods"
+	shardsPath    = "/shards"
+	filtersPath   = "/debug/filters"
+
+	// degradationCheckInterval is the interval in which the memory usage is
+	// compared with --memory-degradation-threshold.
+	degradationCheckInterval = 10 * time.Second
+	// storeUsageCheckInterval is the interval in which the footprint of the
+	// stores is compared with --store-memory-budget.
+	storeUsageCheckInterval = 30 * time.Second
 )
 
 // promLogger implements promhttp.Logger
@@ -86,18 +126,22 @@ func RunKubeStateMetricsWrapper(ctx context.Context, opts *options.Options) erro
 // RunKubeStateMetrics will build and run the kube-state-metrics.
 // Any out-of-tree custom resource metrics could be registered by newing a registry factory
 // which implements customresource.RegistryFactory and pass all factories into this function.
+// Filters of the metric families can be registered with RegisterFamilyGeneratorFilter.
 func RunKubeStateMetrics(ctx context.Context, opts *options.Options) error {
 	promLogger := promLogger{}
 	// registry the k8s metrics
 	ksmMetricsRegistry := prometheus.NewRegistry()
-	ksmMetricsRegistry.MustRegister(version.NewCollector("kube_state_metrics"))
+	ksmMetricsRegistry.MustRegister(versionCollector.NewCollector("kube_state_metrics"))
+	if err := telemetry.SetHistogramOptions(opts.TelemetryNativeHistograms, opts.TelemetryClassicHistograms); err != nil {
+		return err
+	}
 	durationVec := promauto.With(ksmMetricsRegistry).NewHistogramVec(
-		prometheus.HistogramOpts{
+		telemetry.HistogramOpts(prometheus.HistogramOpts{
 			Name:        "http_request_duration_seconds",
 			Help:        "A histogram of requests for kube-state-metrics metrics handler.",
 			Buckets:     prometheus.DefBuckets,
 			ConstLabels: prometheus.Labels{"handler": "metrics"},
-		}, []string{"method"},
+		}), []string{"method"},
 	)
 	configHash := promauto.With(ksmMetricsRegistry).NewGaugeVec(
 		prometheus.GaugeOpts{
@@ -128,6 +172,14 @@ func RunKubeStateMetrics(ctx context.Context, opts *options.Options) error {
 		Name: "kube_state_metrics_custom_resource_state_cache",
 		Help: "Net amount of CRDs affecting the cache currently.",
 	})
+	crsUnknownFieldsGauge := promauto.With(ksmMetricsRegistry).NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kube_state_metrics_custom_resource_state_unknown_fields",
+		Help: "Number of paths of the Custom Resource State config which are unknown to the schema of the resource, if validated.",
+	}, []string{"group", "version", "kind"})
+	crdsDiscoveryErrorsCounter := promauto.With(ksmMetricsRegistry).NewCounter(prometheus.CounterOpts{
+		Name: "kube_state_metrics_custom_resource_discovery_errors_total",
+		Help: "Number of malformed CRDs and failed updates of the custom resource stores.",
+	})
 	// import
 	storeBuilder := store.NewBuilder()
 	storeBuilder.WithMetrics(ksmMetricsRegistry)
@@ -138,8 +190,8 @@ func RunKubeStateMetrics(ctx context.Context, opts *options.Options) error {
 		if err != nil {
 			return fmt.Errorf("failed to read opts config file: %v", err)
 		}
-		// NOTE: Config value will override default values of intersecting options.
-		err = yaml.Unmarshal(configFile, opts)
+		// NOTE: Config values override the options which were not set by flags or environment variables.
+		err = opts.ApplyConfigFile(configFile)
 		if err != nil {
 			// DO NOT end the process.
 			// We want to allow the user to still be able to fix the misconfigured config (redeploy or edit the configmaps) and reload KSM automatically once that's done.
@@ -157,18 +209,30 @@ func RunKubeStateMetrics(ctx context.Context, opts *options.Options) error {
 		}
 	}
 
-	kubeConfig, err := clientcmd.BuildConfigFromFlags(opts.Apiserver, opts.Kubeconfig)
-	if err != nil {
-		return fmt.Errorf("failed to build config from flags: %v", err)
-	}
-
 	// Loading custom resource state configuration from cli argument or config file
 	config, err := resolveCustomResourceConfig(opts)
 	if err != nil {
 		return err
 	}
-
-	var factories []customresource.RegistryFactory
+	if config != nil && opts.SnapshotReplayDir != "" {
+		return fmt.Errorf("custom resource state metrics are not supported while replaying a snapshot")
+	}
+	if config != nil && len(opts.BenchmarkObjects) > 0 {
+		return fmt.Errorf("custom resource state metrics are not supported while serving benchmark objects")
+	}
+	if opts.SelfCheck {
+		problems, err := lintMetricFamilies(opts)
+		if err != nil {
+			return fmt.Errorf("failed to run self-check: %v", err)
+		}
+		for _, p := range problems {
+			klog.ErrorS(nil, "Self-check found a problem", "resource", p.Resource, "metricFamily", p.Family, "problem", p.Text)
+		}
+		if len(problems) > 0 {
+			return fmt.Errorf("self-check found %d problems", len(problems))
+		}
+		klog.InfoS("Self-check passed")
+	}
 
 	if opts.CustomResourceConfigFile != "" {
 		crcFile, err := os.ReadFile(filepath.Clean(opts.CustomResourceConfigFile))
@@ -182,67 +246,47 @@ func RunKubeStateMetrics(ctx context.Context, opts *options.Options) error {
 
 	}
 
-	resources := make([]string, len(factories))
-
-	for i, factory := range factories {
-		resources[i] = factory.Name()
-	}
-
-	switch {
-	// crawl the specific metrics
-	case len(opts.Resources) == 0 && !opts.CustomResourcesOnly:
-		resources = append(resources, options.DefaultResources.AsSlice()...)
-		klog.InfoS("Used default resources")
-	case opts.CustomResourcesOnly:
-		// enable custom resource only, these resources will be populated later on
-		klog.InfoS("Used CRD resources only")
-	default:
-		resources = append(resources, opts.Resources.AsSlice()...)
-		klog.InfoS("Used resources", "resources", resources)
-	}
-
-	if err := storeBuilder.WithEnabledResources(resources); err != nil {
-		return fmt.Errorf("failed to set up resources: %v", err)
+	memoryLimit, err := memlimit.Configure(opts.GoMemLimitRatio)
+	if err != nil {
+		klog.ErrorS(err, "Failed to detect the memory limit")
 	}
-	// crawl feature's metrics form different filter feature like whitelist
-	namespaces := opts.Namespaces.GetNamespaces()
-	nsFieldSelector := namespaces.GetExcludeNSFieldSelector(opts.NamespacesDenylist)
-	nodeFieldSelector := opts.Node.GetNodeFieldSelector()
-	merged, err := storeBuilder.MergeFieldSelectors([]string{nsFieldSelector, nodeFieldSelector})
+	degradationFilter := &degradation.Filter{}
+	optionsFilter, err := newFamilyGeneratorFilter(opts)
 	if err != nil {
 		return err
 	}
-	storeBuilder.WithNamespaces(namespaces)
-	storeBuilder.WithFieldSelectorFilter(merged)
-
-	// filter metrics（blacklist & whitelist）在指标层面做筛选
-	allowDenyList, err := allowdenylist.New(opts.MetricAllowlist, opts.MetricDenylist)
+	// The filter of the options is swapped when the options config file
+	// changes, see watchFamilyFilters.
+	reloadableFilter := generator.NewSwappableFamilyGeneratorFilter(optionsFilter)
+	familyGeneratorFilter, err := configureStoreBuilder(storeBuilder, opts, reloadableFilter, generator.NewNamedFamilyGeneratorFilter("memory_degradation", degradationFilter))
 	if err != nil {
 		return err
 	}
-
-	err = allowDenyList.Parse()
-	if err != nil {
-		return fmt.Errorf("error initializing the allowdeny list: %v", err)
+	// 设置storeBuilder是否使用API服务器的缓存。opts.UseAPIServerCache是一个布尔值，如果为true，则storeBuilder会使用API服务器的缓存。
+	storeBuilder.WithUsingAPIServerCache(opts.UseAPIServerCache)
+	storeBuilder.WithResourceListOptions(opts.ResourceListOptions)
+	storeBuilder.WithListConcurrency(opts.ListConcurrency)
+	var staleness *watch.StalenessTracker
+	if opts.StaleThreshold > 0 {
+		staleness = watch.NewStalenessTracker(opts.StaleThreshold)
+		ksmMetricsRegistry.MustRegister(staleness)
 	}
-
-	klog.InfoS("Metric allow-denylisting", "allowDenyStatus", allowDenyList.Status())
-
-	optInMetricFamilyFilter, err := optin.NewMetricFamilyFilter(opts.MetricOptInList)
-	if err != nil {
-		return fmt.Errorf("error initializing the opt-in metric list: %v", err)
+	storeBuilder.WithStalenessTracker(staleness)
+	var writeTracker *watch.WriteTracker
+	if opts.HealthzStoreWriteMaxAge > 0 {
+		writeTracker = watch.NewWriteTracker()
 	}
-
-	if optInMetricFamilyFilter.Count() > 0 {
-		klog.InfoS("Metrics which were opted into", "optInMetricsFamilyStatus", optInMetricFamilyFilter.Status())
+	storeBuilder.WithWriteTracker(writeTracker)
+	var podTargets *httpsd.PodTargets
+	if opts.PodDiscoveryAnnotation != "" {
+		podTargets = httpsd.NewPodTargets(opts.PodDiscoveryAnnotation)
+		storeBuilder.WithPodTargets(podTargets)
+	}
+	if opts.NotificationWebhookURL != "" {
+		notifier := watch.NewNotifier(opts.NotificationWebhookURL, opts.NotificationResources.AsSlice(), ksmMetricsRegistry)
+		go notifier.Run(ctx)
+		storeBuilder.WithNotifier(notifier)
 	}
-
-	storeBuilder.WithFamilyGeneratorFilter(generator.NewCompositeFamilyGeneratorFilter(
-		allowDenyList,
-		optInMetricFamilyFilter,
-	))
-	// 设置storeBuilder是否使用API服务器的缓存。opts.UseAPIServerCache是一个布尔值，如果为true，则storeBuilder会使用API服务器的缓存。
-	storeBuilder.WithUsingAPIServerCache(opts.UseAPIServerCache)
 	// 设置storeBuilder的生成存储函数。生成存储函数，这个函数会被storeBuilder用来生成存储。后续会用来处理不同的存储指标
 	// import （availableStores）
 	storeBuilder.WithGenerateStoresFunc(storeBuilder.DefaultGenerateStoresFunc()) //call the listwatch
@@ -250,21 +294,38 @@ func RunKubeStateMetrics(ctx context.Context, opts *options.Options) error {
 	proc.StartReaper()
 
 	storeBuilder.WithUtilOptions(opts)
+	storeBuilder.WithSnapshots(opts.SnapshotDir, opts.SnapshotInterval)
+	storeBuilder.WithSnapshotReplay(opts.SnapshotReplayDir)
+	storeBuilder.WithCache(opts.CacheDir, opts.CacheInterval)
+	if len(opts.BenchmarkObjects) > 0 {
+		if err := storeBuilder.WithBenchmarkObjects(opts.BenchmarkObjects); err != nil {
+			return fmt.Errorf("failed to set up benchmark objects: %v", err)
+		}
+	}
 	// create client
-	kubeClient, err := util.CreateKubeClient(opts.Apiserver, opts.Kubeconfig)
-	if err != nil {
-		return fmt.Errorf("failed to create client: %v", err)
+	var kubeClient clientset.Interface
+	switch {
+	case opts.SnapshotReplayDir != "":
+		klog.InfoS("Replaying snapshot instead of watching the apiserver", "snapshotReplayDir", opts.SnapshotReplayDir)
+	case len(opts.BenchmarkObjects) > 0:
+		klog.InfoS("Serving generated objects instead of watching the apiserver", "benchmarkObjects", opts.BenchmarkObjects.String())
+	default:
+		kubeClient, err = util.CreateKubeClient(opts.Apiserver, opts.Kubeconfig)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %v", err)
+		}
+		if len(opts.MetadataOnlyResources) > 0 {
+			metadataClient, err := util.CreateMetadataClient(opts.Apiserver, opts.Kubeconfig)
+			if err != nil {
+				return fmt.Errorf("failed to create metadata client: %v", err)
+			}
+			storeBuilder.WithMetadataClient(metadataClient)
+		}
 	}
 
 	// 设置
 	storeBuilder.WithKubeClient(kubeClient)
 	storeBuilder.WithSharding(opts.Shard, opts.TotalShards)
-	if err := storeBuilder.WithAllowAnnotations(opts.AnnotationsAllowList); err != nil {
-		return fmt.Errorf("failed to set up annotations allowlist: %v", err)
-	}
-	if err := storeBuilder.WithAllowLabels(opts.LabelsAllowList); err != nil {
-		return fmt.Errorf("failed to set up labels allowlist: %v", err)
-	}
 
 	ksmMetricsRegistry.MustRegister(
 		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
@@ -280,8 +341,87 @@ func RunKubeStateMetrics(ctx context.Context, opts *options.Options) error {
 		storeBuilder,
 		opts.EnableGZIPEncoding,
 	)
+	if file := opts.ScrapeArchiveFile; file != "" {
+		m.AddSinks(metricshandler.NewFileSink(file))
+	}
+	if opts.PushgatewayURL != "" {
+		instance := opts.PushgatewayInstance
+		if instance == "" {
+			if instance, err = os.Hostname(); err != nil {
+				return fmt.Errorf("failed to get the hostname for the Pushgateway instance: %w", err)
+			}
+		}
+		pusher := metricshandler.NewPusher(m, opts.PushgatewayURL, opts.PushgatewayJob, instance)
+		ctxPush, cancel := context.WithCancel(ctx)
+		g.Add(func() error {
+			pusher.Run(ctxPush, opts.PushgatewayInterval)
+			return nil
+		}, func(error) {
+			cancel()
+		})
+	}
+	if dir := opts.FileExportDir; dir != "" {
+		exporter := metricshandler.NewFileExporter(m, dir, opts.FileExportRetention, opts.FileExportMaxFiles)
+		ctxExport, cancel := context.WithCancel(ctx)
+		g.Add(func() error {
+			exporter.Run(ctxExport, opts.FileExportInterval)
+			return nil
+		}, func(error) {
+			cancel()
+		})
+	}
+	if file := options.GetConfigFile(*opts); file != "" {
+		watchFamilyFilters(ctx, opts, file, reloadableFilter, m.Rebuild, func(configFile []byte, err error) {
+			if err != nil {
+				klog.ErrorS(err, "Failed to reload metric family filters, keeping the previous filters", "file", file)
+				configSuccess.WithLabelValues("config", filepath.Clean(file)).Set(0)
+				return
+			}
+			klog.InfoS("Reloaded metric family filters", "file", file)
+			configSuccess.WithLabelValues("config", filepath.Clean(file)).Set(1)
+			configSuccessTime.WithLabelValues("config", filepath.Clean(file)).SetToCurrentTime()
+			configHash.WithLabelValues("config", filepath.Clean(file)).Set(md5HashAsMetricValue(configFile))
+		})
+	}
+	promauto.With(ksmMetricsRegistry).NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Name: "kube_state_metrics_schema_hash",
+			Help: "Hash of the schema of the currently served metric families, as exposed on " + schemaPath + ".",
+		}, func() float64 {
+			schema, err := m.Schema()
+			if err != nil {
+				return 0
+			}
+			return md5HashAsMetricValue(schema)
+		})
+	degradationMonitor := degradation.NewMonitor(ksmMetricsRegistry, degradationFilter, memoryLimit, opts.MemoryDegradationThreshold, m.Restart)
+	if opts.MemoryDegradationThreshold > 0 {
+		if memoryLimit == 0 {
+			klog.InfoS("Memory degradation is disabled, as no memory limit is set", "memoryDegradationThreshold", opts.MemoryDegradationThreshold)
+		} else {
+			ctxDegradation, cancel := context.WithCancel(ctx)
+			g.Add(func() error {
+				degradationMonitor.Run(ctxDegradation, degradationCheckInterval)
+				<-ctxDegradation.Done()
+				return nil
+			}, func(error) {
+				cancel()
+			})
+		}
+	}
+	storeUsage := metricshandler.NewStoreUsage(m, opts.StoreMemoryBudget)
+	ksmMetricsRegistry.MustRegister(storeUsage)
+	if opts.StoreMemoryBudget > 0 {
+		ctxStoreUsage, cancel := context.WithCancel(ctx)
+		g.Add(func() error {
+			storeUsage.Run(ctxStoreUsage, storeUsageCheckInterval)
+			return nil
+		}, func(error) {
+			cancel()
+		})
+	}
 	// Run MetricsHandler
-	if config == nil {
+	if config == nil && !opts.EnableScaleSubresource {
 		ctxMetricsHandler, cancel := context.WithCancel(ctx)
 		g.Add(func() error {
 			// *****metrics handler run*****
@@ -293,22 +433,60 @@ func RunKubeStateMetrics(ctx context.Context, opts *options.Options) error {
 
 	tlsConfig := opts.TLSConfig
 
-	// A nil CRS config implies that we need to hold off on all CRS operations.
-	if config != nil {
+	// customResources returns the discovered custom resources for the
+	// landing page.
+	var customResources func() []schema.GroupVersionKind
+
+	// A nil CRS config implies that we need to hold off on all CRS operations,
+	// unless the scale subresources of the discovered CRDs are watched.
+	if config != nil || opts.EnableScaleSubresource {
+		kubeConfig, err := clientcmd.BuildConfigFromFlags(opts.Apiserver, opts.Kubeconfig)
+		if err != nil {
+			return fmt.Errorf("failed to build config from flags: %v", err)
+		}
 		discovererInstance := &discovery.CRDiscoverer{
 			CRDsAddEventsCounter:    crdsAddEventsCounter,
 			CRDsDeleteEventsCounter: crdsDeleteEventsCounter,
 			CRDsCacheCountGauge:     crdsCacheCountGauge,
+			DiscoveryErrorsCounter:  crdsDiscoveryErrorsCounter,
 		}
 		// This starts a goroutine that will watch for any new GVKs to extract from CRDs.
 		err = discovererInstance.StartDiscovery(ctx, kubeConfig)
 		if err != nil {
 			return err
 		}
-		// FromConfig will return different behaviours when a G**-based config is supplied (since that is subject to change based on the resources present in the cluster).
-		fn, err := customresourcestate.FromConfig(config, discovererInstance)
-		if err != nil {
-			return err
+		customResources = discovererInstance.GroupVersionKinds
+		schemaValidator := customresourcestate.SchemaValidator{
+			Mode:          opts.CustomResourceStateSchemaValidation,
+			UnknownFields: crsUnknownFieldsGauge,
+		}
+		fn := func() ([]customresource.RegistryFactory, error) { return nil, nil }
+		if config != nil {
+			// FromConfig will return different behaviours when a G**-based config is supplied (since that is subject to change based on the resources present in the cluster).
+			fn, err = customresourcestate.FromConfig(config, discovererInstance, schemaValidator)
+			if err != nil {
+				return err
+			}
+		}
+		if file := opts.CustomResourceConfigFile; config != nil && file != "" && opts.CustomResourceConfig == "" {
+			fn = watchCustomResourceConfigFile(ctx, opts, discovererInstance, schemaValidator, fn, func(crcFile []byte, err error) {
+				if err != nil {
+					klog.ErrorS(err, "Failed to reload custom resource config file, keeping the previous config", "file", file)
+					configSuccess.WithLabelValues("customresourceconfig", filepath.Clean(file)).Set(0)
+					return
+				}
+				klog.InfoS("Reloaded custom resource config file", "file", file)
+				configSuccess.WithLabelValues("customresourceconfig", filepath.Clean(file)).Set(1)
+				configSuccessTime.WithLabelValues("customresourceconfig", filepath.Clean(file)).SetToCurrentTime()
+				configHash.WithLabelValues("customresourceconfig", filepath.Clean(file)).Set(md5HashAsMetricValue(crcFile))
+			})
+		}
+		if opts.EnableScaleSubresource {
+			crsFactories := fn
+			fn = func() ([]customresource.RegistryFactory, error) {
+				factories, err := crsFactories()
+				return append(factories, discovererInstance.ScaleFactories()...), err
+			}
 		}
 		// This starts a goroutine that will keep the cache up to date.
 		discovererInstance.PollForCacheUpdates(
@@ -320,23 +498,44 @@ func RunKubeStateMetrics(ctx context.Context, opts *options.Options) error {
 		)
 	}
 
-	telemetryMux := buildTelemetryServer(ksmMetricsRegistry)
+	allowedCIDRs, err := parseCIDRs(opts.AllowedScrapeCIDRs)
+	if err != nil {
+		return err
+	}
+	drain := &drainer{period: opts.ShutdownDrainPeriod}
+	telemetryMux := buildTelemetryServer(ksmMetricsRegistry, m, drain)
 	telemetryListenAddress := net.JoinHostPort(opts.TelemetryHost, strconv.Itoa(opts.TelemetryPort))
-	telemetryServer := http.Server{
-		Handler:           telemetryMux,
-		ReadHeaderTimeout: 5 * time.Second}
+	telemetryServer := newHTTPServer(opts, allowCIDRs(allowedCIDRs, telemetryMux))
 	telemetryFlags := web.FlagConfig{
 		WebListenAddresses: &[]string{telemetryListenAddress},
 		WebSystemdSocket:   new(bool),
 		WebConfigFile:      &tlsConfig,
 	}
 
-	metricsMux := buildMetricsServer(m, durationVec)
-	metricsServerListenAddress := net.JoinHostPort(opts.Host, strconv.Itoa(opts.Port))
-	metricsServer := http.Server{
-		Handler:           metricsMux,
-		ReadHeaderTimeout: 5 * time.Second,
+	var federateHandler http.Handler
+	if opts.FederateService != "" {
+		federateHandler, err = federate.New(opts.FederateService, metricsPath)
+		if err != nil {
+			return err
+		}
 	}
+	filtersHandler, err := newFiltersHandler(opts, familyGeneratorFilter)
+	if err != nil {
+		return fmt.Errorf("failed to set up the filter report: %v", err)
+	}
+	var scrapeLimiter *metricshandler.ScrapeLimiter
+	if opts.MaxConcurrentScrapes > 0 {
+		scrapeLimiter = metricshandler.NewScrapeLimiter(opts.MaxConcurrentScrapes, opts.ScrapeQueueTimeout, ksmMetricsRegistry)
+	}
+	healthChecks := newHealthChecks(opts, kubeClient, writeTracker)
+	metricsMux := buildMetricsServer(m, durationVec, staleness, opts.AccessLogSampleRate, scrapeLimiter, federateHandler, filtersHandler, podTargets, healthChecks, customResources)
+	if opts.SingleEndpoint {
+		m.SetSelfMetrics(ksmMetricsRegistry)
+		metricsMux.Handle(startupPath, m.StartupHandler())
+		metricsMux.Handle(readyPath, drain.readyHandler())
+	}
+	metricsServerListenAddress := net.JoinHostPort(opts.Host, strconv.Itoa(opts.Port))
+	metricsServer := newHTTPServer(opts, allowCIDRs(allowedCIDRs, metricsMux))
 	metricsFlags := web.FlagConfig{
 		WebListenAddresses: &[]string{metricsServerListenAddress},
 		WebSystemdSocket:   new(bool),
@@ -344,12 +543,12 @@ func RunKubeStateMetrics(ctx context.Context, opts *options.Options) error {
 	}
 
 	// Run Telemetry server
-	{
+	if !opts.SingleEndpoint {
 		g.Add(func() error {
 			klog.InfoS("Started kube-state-metrics self metrics server", "telemetryAddress", telemetryListenAddress)
-			return web.ListenAndServe(&telemetryServer, &telemetryFlags, promLogger)
+			return web.ListenAndServe(telemetryServer, &telemetryFlags, promLogger)
 		}, func(error) {
-			ctxShutDown, cancel := context.WithTimeout(ctx, 3*time.Second)
+			ctxShutDown, cancel := context.WithTimeout(ctx, opts.ShutdownTimeout)
 			defer cancel()
 			telemetryServer.Shutdown(ctxShutDown)
 		})
@@ -357,16 +556,54 @@ func RunKubeStateMetrics(ctx context.Context, opts *options.Options) error {
 	// Run Metrics server
 	{
 		g.Add(func() error {
+			if opts.WaitForSync {
+				klog.InfoS("Waiting for the stores to sync before starting the metrics server")
+				if err := waitForSync(ctx, m); err != nil {
+					return err
+				}
+			}
 			klog.InfoS("Started metrics server", "metricsServerAddress", metricsServerListenAddress)
-			return web.ListenAndServe(&metricsServer, &metricsFlags, promLogger)
+			return web.ListenAndServe(metricsServer, &metricsFlags, promLogger)
 		}, func(error) {
-			ctxShutDown, cancel := context.WithTimeout(ctx, 3*time.Second)
+			ctxShutDown, cancel := context.WithTimeout(ctx, opts.ShutdownTimeout)
 			defer cancel()
 			metricsServer.Shutdown(ctxShutDown)
 		})
 	}
+	// Run gRPC server
+	if opts.GRPCPort != 0 {
+		grpcListenAddress := net.JoinHostPort(opts.GRPCHost, strconv.Itoa(opts.GRPCPort))
+		grpcServer := grpc.NewServer(grpc.StreamInterceptor(allowCIDRsStream(allowedCIDRs)))
+		streamapi.RegisterMetricsStreamServer(grpcServer, metricshandler.NewStreamServer(m, opts.GRPCStreamInterval))
+		g.Add(func() error {
+			l, err := net.Listen("tcp", grpcListenAddress)
+			if err != nil {
+				return err
+			}
+			klog.InfoS("Started gRPC server", "grpcAddress", grpcListenAddress)
+			return grpcServer.Serve(l)
+		}, func(error) {
+			grpcServer.Stop()
+		})
+	}
+	// Drain on termination signals
+	{
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGTERM, os.Interrupt)
+		ctxDrain, cancel := context.WithCancel(ctx)
+		g.Add(func() error {
+			return drain.run(ctxDrain, signals)
+		}, func(error) {
+			signal.Stop(signals)
+			cancel()
+		})
+	}
 
 	if err := g.Run(); err != nil {
+		if errors.Is(err, ErrTerminated) {
+			klog.InfoS("Exited")
+			return err
+		}
 		return fmt.Errorf("run server group error: %v", err)
 	}
 
@@ -374,12 +611,212 @@ func RunKubeStateMetrics(ctx context.Context, opts *options.Options) error {
 	return nil
 }
 
-func buildTelemetryServer(registry prometheus.Gatherer) *http.ServeMux {
+// configureStoreBuilder configures the resources, object filters and allow
+// lists of the given options and the given filters of the metric families,
+// usually starting with the one returned by newFamilyGeneratorFilter, on the
+// store builder. It returns the configured filter of the metric families.
+func configureStoreBuilder(storeBuilder *store.Builder, opts *options.Options, filters ...generator.FamilyGeneratorFilter) (generator.FamilyGeneratorFilter, error) {
+	var resources []string
+	switch {
+	// crawl the specific metrics
+	case len(opts.Resources) == 0 && !opts.CustomResourcesOnly:
+		resources = append(resources, options.DefaultResources.AsSlice()...)
+		klog.InfoS("Used default resources")
+	case opts.CustomResourcesOnly:
+		// enable custom resource only, these resources will be populated later on
+		klog.InfoS("Used CRD resources only")
+	default:
+		resources = append(resources, opts.Resources.AsSlice()...)
+		klog.InfoS("Used resources", "resources", resources)
+	}
+
+	if err := storeBuilder.WithEnabledResources(resources); err != nil {
+		return nil, fmt.Errorf("failed to set up resources: %v", err)
+	}
+	if err := storeBuilder.WithMetadataOnlyResources(opts.MetadataOnlyResources.AsSlice()); err != nil {
+		return nil, fmt.Errorf("failed to set up metadata-only resources: %v", err)
+	}
+	// crawl feature's metrics form different filter feature like whitelist
+	namespaces := opts.Namespaces.GetNamespaces()
+	namespacesDenylist, err := opts.NamespacesDenylist.WithPresets(opts.NamespacesDenylistPresets)
+	if err != nil {
+		return nil, err
+	}
+	deniedNamespaces, deniedNamespacePatterns, err := namespacesDenylist.SplitPatterns()
+	if err != nil {
+		return nil, err
+	}
+	nsFieldSelector := namespaces.GetExcludeNSFieldSelector(deniedNamespaces)
+	nodeFieldSelector := opts.Node.GetNodeFieldSelector()
+	merged, err := storeBuilder.MergeFieldSelectors([]string{nsFieldSelector, nodeFieldSelector})
+	if err != nil {
+		return nil, err
+	}
+	resourceFieldSelectors := make(map[string]string, len(opts.ResourceFieldSelectors))
+	for resource, selector := range opts.ResourceFieldSelectors {
+		resourceFieldSelectors[resource], err = storeBuilder.MergeFieldSelectors([]string{merged, selector})
+		if err != nil {
+			return nil, fmt.Errorf("failed to merge field selector of resource %s: %v", resource, err)
+		}
+	}
+	storeBuilder.WithNamespaces(namespaces)
+	storeBuilder.WithFieldSelectorFilter(merged)
+	storeBuilder.WithResourceFieldSelectors(resourceFieldSelectors)
+	storeBuilder.WithNamespaceDenylistPatterns(deniedNamespacePatterns)
+	storeBuilder.WithStoreShards(opts.StoreShards)
+	storeBuilder.WithPodGCThreshold(opts.PodGCThreshold)
+	storeBuilder.WithSplitTerminalPods(opts.SplitTerminalPods)
+	storeBuilder.WithLastTerminatedReasonLimit(opts.LastTerminatedReasonLimit)
+	storeBuilder.WithPodStatusReasons(opts.PodStatusReasons)
+	storeBuilder.WithNodeImageLimit(opts.NodeImageLimit)
+	storeBuilder.WithReplicaSetDeploymentLabel(opts.ReplicaSetDeploymentLabel)
+	if err := storeBuilder.WithMetricPrefix(opts.MetricPrefix); err != nil {
+		return nil, fmt.Errorf("failed to set up metric prefix: %v", err)
+	}
+	if err := storeBuilder.WithNamingConvention(opts.NamingConvention); err != nil {
+		return nil, fmt.Errorf("failed to set up naming convention: %v", err)
+	}
+	storeBuilder.WithExemplars(opts.ExemplarFamilies, opts.ExemplarTraceIDAnnotation)
+	storeBuilder.WithOwnerKinds(opts.OwnerKindsAllowList, opts.OwnerKindsDenyList)
+	storeBuilder.WithIgnoreAnnotation(opts.IgnoreAnnotation)
+
+	if err := storeBuilder.WithLabelValueLimit(metric.LabelValueLimit{MaxLength: opts.LabelValueMaxLength, Policy: metric.LabelValueLimitPolicy(opts.LabelValueMaxLengthPolicy)}); err != nil {
+		return nil, fmt.Errorf("failed to set up label value limit: %v", err)
+	}
+	if err := storeBuilder.WithConditionLimit(opts.ConditionLimit, opts.ConditionTypeLimit); err != nil {
+		return nil, fmt.Errorf("failed to set up condition limit: %v", err)
+	}
+	if err := storeBuilder.WithTimestampPrecision(metric.TimestampPrecision(opts.TimestampPrecision)); err != nil {
+		return nil, fmt.Errorf("failed to set up timestamp precision: %v", err)
+	}
+
+	// filter metrics（blacklist & whitelist）在指标层面做筛选
+	filter := generator.NewCompositeFamilyGeneratorFilter(filters...)
+	storeBuilder.WithFamilyGeneratorFilter(filter)
+	if err := storeBuilder.WithAllowAnnotations(opts.AnnotationsAllowList); err != nil {
+		return nil, fmt.Errorf("failed to set up annotations allowlist: %v", err)
+	}
+	if err := storeBuilder.WithAllowLabels(opts.LabelsAllowList); err != nil {
+		return nil, fmt.Errorf("failed to set up labels allowlist: %v", err)
+	}
+
+	return filter, nil
+}
+
+// servedFamilyNames returns the names of the metric families served by each of
+// the given resources which is a built-in resource, including the names of the
+// families renamed by the given naming convention. Events and custom resources
+// are left out.
+func servedFamilyNames(resources []string, namingConvention string) (map[string][]string, error) {
+	renames, err := store.FamilyRenames(namingConvention)
+	if err != nil {
+		return nil, err
+	}
+	builtin := []string{}
+	for _, r := range resources {
+		if slices.Contains(store.AvailableResources(), r) {
+			builtin = append(builtin, r)
+		}
+	}
+	served := map[string][]string{}
+	err = visitMetricFamilies(builtin, generator.NewCompositeFamilyGeneratorFilter(), func(resource string, families []generator.FamilyGenerator, _ interface{}) {
+		for _, f := range generator.RenameFamilyGenerators(renames, families) {
+			served[resource] = append(served[resource], f.Name)
+		}
+	})
+	return served, err
+}
+
+// newFamilyGeneratorFilter returns the filter of the metric families which are
+// exposed according to the given options and the filters registered with
+// RegisterFamilyGeneratorFilter.
+func newFamilyGeneratorFilter(opts *options.Options) (generator.FamilyGeneratorFilter, error) {
+	allowDenyList, err := allowdenylist.New(opts.MetricAllowlist, opts.MetricDenylist)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := allowDenyList.Parse(); err != nil {
+		return nil, fmt.Errorf("error initializing the allowdeny list: %v", err)
+	}
+
+	klog.InfoS("Metric allow-denylisting", "allowDenyStatus", allowDenyList.Status())
+
+	optInList, optOutList := opts.MetricOptInList, opts.MetricOptOutList
+	if opts.CanonicalResourceUnits {
+		optInList, optOutList = canonicalResourceUnitLists(optInList, optOutList)
+	}
+	optInMetricFamilyFilter, err := optin.NewMetricFamilyFilter(optInList)
+	if err != nil {
+		return nil, fmt.Errorf("error initializing the opt-in metric list: %v", err)
+	}
+
+	if optInMetricFamilyFilter.Count() > 0 {
+		klog.InfoS("Metrics which were opted into", "optInMetricsFamilyStatus", optInMetricFamilyFilter.Status())
+	}
+
+	optOutMetricFamilyFilter := optout.NewMetricFamilyFilter(optOutList)
+
+	if optOutMetricFamilyFilter.Count() > 0 {
+		klog.InfoS("Metrics which were opted out of", "optOutMetricsFamilyStatus", optOutMetricFamilyFilter.Status())
+	}
+
+	disabledMetricFamilyFilter, err := disabledfamilies.NewMetricFamilyFilter(opts.MetricFamiliesDisabled)
+	if err != nil {
+		return nil, fmt.Errorf("error initializing the disabled metric families: %v", err)
+	}
+
+	if disabledMetricFamilyFilter.Count() > 0 {
+		served, err := servedFamilyNames(disabledMetricFamilyFilter.Resources(), opts.NamingConvention)
+		if err != nil {
+			return nil, fmt.Errorf("error validating the disabled metric families: %v", err)
+		}
+		if err := disabledMetricFamilyFilter.Validate(served); err != nil {
+			return nil, fmt.Errorf("error validating the disabled metric families: %v", err)
+		}
+		klog.InfoS("Metric families which were disabled", "disabledMetricFamiliesStatus", disabledMetricFamilyFilter.Status())
+	}
+
+	filters := []generator.FamilyGeneratorFilter{
+		generator.NewNamedFamilyGeneratorFilter("allow_deny_list", allowDenyList),
+		generator.NewNamedFamilyGeneratorFilter("opt_in", optInMetricFamilyFilter),
+		generator.NewNamedFamilyGeneratorFilter("opt_out", optOutMetricFamilyFilter),
+		generator.NewNamedFamilyGeneratorFilter("disabled_families", disabledMetricFamilyFilter),
+	}
+	return generator.NewCompositeFamilyGeneratorFilter(append(filters, registeredFamilyGeneratorFilters()...)...), nil
+}
+
+// canonicalResourceUnitLists returns copies of the opt-in and opt-out lists
+// which opt into the families serving resources in their base unit and out
+// of the deprecated families they replace.
+func canonicalResourceUnitLists(optInList, optOutList options.MetricSet) (options.MetricSet, options.MetricSet) {
+	optIn, optOut := options.MetricSet{}, options.MetricSet{}
+	for name := range optInList {
+		optIn[name] = struct{}{}
+	}
+	for name := range optOutList {
+		optOut[name] = struct{}{}
+	}
+	for deprecated, canonical := range store.UnitSuffixedFamilies {
+		optIn["^"+canonical+"$"] = struct{}{}
+		optOut[deprecated] = struct{}{}
+	}
+	return optIn, optOut
+}
+
+func buildTelemetryServer(registry prometheus.Gatherer, m *metricshandler.MetricsHandler, drain *drainer) *http.ServeMux {
 	mux := http.NewServeMux()
 
 	// Add metricsPath
 	mux.Handle(metricsPath, promhttp.HandlerFor(registry, promhttp.HandlerOpts{ErrorLog: promLogger{}}))
 
+	// Add startupPath, which reports the sync progress and succeeds once the
+	// stores have synced
+	mux.Handle(startupPath, m.StartupHandler())
+
+	// Add readyPath, which fails while draining before shutting down
+	mux.Handle(readyPath, drain.readyHandler())
+
 	// Add index
 	landingConfig := web.LandingConfig{
 		Name:        "kube-state-metrics",
@@ -390,6 +827,14 @@ func buildTelemetryServer(registry prometheus.Gatherer) *http.ServeMux {
 				Address: metricsPath,
 				Text:    "Metrics",
 			},
+			{
+				Address: startupPath,
+				Text:    "Startup",
+			},
+			{
+				Address: readyPath,
+				Text:    "Readiness",
+			},
 		},
 	}
 	landingPage, err := web.NewLandingPage(landingConfig)
@@ -400,7 +845,39 @@ func buildTelemetryServer(registry prometheus.Gatherer) *http.ServeMux {
 	return mux
 }
 
-func buildMetricsServer(m *metricshandler.MetricsHandler, durationObserver prometheus.ObserverVec) *http.ServeMux {
+// newHTTPServer returns a server of the handler with the timeouts and limits
+// of the options.
+func newHTTPServer(opts *options.Options, handler http.Handler) *http.Server {
+	if opts.ServerMaxRequestBodyBytes > 0 {
+		handler = http.MaxBytesHandler(handler, opts.ServerMaxRequestBodyBytes)
+	}
+	return &http.Server{
+		Handler:           handler,
+		ReadTimeout:       opts.ServerReadTimeout,
+		ReadHeaderTimeout: opts.ServerReadHeaderTimeout,
+		WriteTimeout:      opts.ServerWriteTimeout,
+		IdleTimeout:       opts.ServerIdleTimeout,
+		MaxHeaderBytes:    opts.ServerMaxHeaderBytes,
+	}
+}
+
+// waitForSync waits until the stores of the metrics handler have synced or
+// the context is done.
+func waitForSync(ctx context.Context, m *metricshandler.MetricsHandler) error {
+	t := time.NewTicker(100 * time.Millisecond)
+	defer t.Stop()
+	for !m.HasSynced() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+	klog.InfoS("Stores have synced")
+	return nil
+}
+
+func buildMetricsServer(m *metricshandler.MetricsHandler, durationObserver prometheus.ObserverVec, staleness *watch.StalenessTracker, accessLogSampleRate float64, scrapeLimiter *metricshandler.ScrapeLimiter, federateHandler, filtersHandler http.Handler, podTargets *httpsd.PodTargets, healthChecks []healthCheck, customResources func() []schema.GroupVersionKind) *http.ServeMux {
 	mux := http.NewServeMux()
 
 	// TODO: This doesn't belong into serveMetrics
@@ -409,14 +886,28 @@ func buildMetricsServer(m *metricshandler.MetricsHandler, durationObserver prome
 	mux.Handle("/debug/pprof/profile", http.HandlerFunc(pprof.Profile))
 	mux.Handle("/debug/pprof/symbol", http.HandlerFunc(pprof.Symbol))
 	mux.Handle("/debug/pprof/trace", http.HandlerFunc(pprof.Trace))
+	if filtersHandler != nil {
+		mux.Handle(filtersPath, filtersHandler)
+	}
 
-	mux.Handle(metricsPath, promhttp.InstrumentHandlerDuration(durationObserver, m))
+	instrument := func(handler http.Handler) http.Handler {
+		return metricshandler.AccessLogHandler(promhttp.InstrumentHandlerDuration(durationObserver, staleHeader(staleness, scrapeLimiter.Handler(handler))), accessLogSampleRate)
+	}
+	mux.Handle(metricsPath, instrument(m))
+	mux.Handle(resourcesPath, instrument(m.ResourcesHandler(resourcesPath)))
+	mux.Handle(schemaPath, m.SchemaHandler())
+	mux.Handle(influxPath, instrument(m.InfluxHandler()))
+	mux.Handle(deltaPath, instrument(m.DeltaHandler()))
+	mux.Handle(shardsPath, m.ShardsHandler())
+	if federateHandler != nil {
+		mux.Handle(federatePath, federateHandler)
+	}
+	if podTargets != nil {
+		mux.Handle(podsSDPath, podTargets)
+	}
 
 	// Add healthzPath
-	mux.HandleFunc(healthzPath, func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte(http.StatusText(http.StatusOK)))
-	})
+	mux.HandleFunc(healthzPath, healthzHandler(healthChecks))
 
 	// Add index
 	landingConfig := web.LandingConfig{
@@ -428,20 +919,136 @@ func buildMetricsServer(m *metricshandler.MetricsHandler, durationObserver prome
 				Address: metricsPath,
 				Text:    "Metrics",
 			},
+			{
+				Address: schemaPath,
+				Text:    "Metrics Schema",
+			},
+			{
+				Address: resourcesPath,
+				Text:    "Metrics per resource",
+			},
+			{
+				Address: influxPath,
+				Text:    "Metrics in the Influx line protocol",
+			},
+			{
+				Address: deltaPath,
+				Text:    "Metrics changed since a generation",
+			},
 			{
 				Address: healthzPath,
 				Text:    "Healthz",
 			},
 		},
 	}
-	landingPage, err := web.NewLandingPage(landingConfig)
-	if err != nil {
-		klog.ErrorS(err, "failed to create landing page")
+	if filtersHandler != nil {
+		landingConfig.Links = append(landingConfig.Links, web.LandingLinks{
+			Address: filtersPath,
+			Text:    "Metric family filters",
+		})
 	}
-	mux.Handle("/", landingPage)
+	if federateHandler != nil {
+		landingConfig.Links = append(landingConfig.Links, web.LandingLinks{
+			Address: federatePath,
+			Text:    "Federated metrics of all shards",
+		})
+	}
+	if podTargets != nil {
+		landingConfig.Links = append(landingConfig.Links, web.LandingLinks{
+			Address: podsSDPath,
+			Text:    "Pod targets of the HTTP service discovery",
+		})
+	}
+	mux.Handle("/", &statusPage{
+		config:          landingConfig,
+		status:          m.Status,
+		customResources: customResources,
+	})
 	return mux
 }
 
+// staleHeader sets the X-Kube-State-Metrics-Stale header to the resources
+// whose metrics are stale, if any, before calling the handler.
+func staleHeader(staleness *watch.StalenessTracker, handler http.Handler) http.Handler {
+	if staleness == nil {
+		return handler
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if stale := staleness.StaleResources(); len(stale) > 0 {
+			w.Header().Set("X-Kube-State-Metrics-Stale", strings.Join(stale, ","))
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// parseCIDRs parses the given CIDR notations.
+func parseCIDRs(cidrs []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(strings.TrimSpace(cidr))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse allowed scrape CIDR: %v", err)
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+// allowCIDRs rejects requests from clients whose address is not in one of the
+// given networks with 403 Forbidden, except for the health, startup and
+// readiness probes, which are sent by the kubelet from the address of the node. No
+// request is rejected without networks.
+func allowCIDRs(cidrs []*net.IPNet, handler http.Handler) http.Handler {
+	if len(cidrs) == 0 {
+		return handler
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == healthzPath || r.URL.Path == startupPath || r.URL.Path == readyPath {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		if containsAddr(cidrs, r.RemoteAddr) {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+	})
+}
+
+// allowCIDRsStream rejects streams of gRPC clients whose address is not in
+// one of the given networks with PermissionDenied. No stream is rejected
+// without networks.
+func allowCIDRsStream(cidrs []*net.IPNet) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if len(cidrs) > 0 {
+			p, ok := peer.FromContext(ss.Context())
+			if !ok || !containsAddr(cidrs, p.Addr.String()) {
+				return status.Error(codes.PermissionDenied, "client address not allowed")
+			}
+		}
+		return handler(srv, ss)
+	}
+}
+
+// containsAddr returns whether the host of the given address is in one of the
+// given networks.
+func containsAddr(cidrs []*net.IPNet, addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, cidr := range cidrs {
+		if cidr.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
 // md5HashAsMetricValue creates an md5 hash and returns the most significant bytes that fit into a float64
 // Taken from https://github.com/prometheus/alertmanager/blob/6ef6e6868dbeb7984d2d577dd4bf75c65bf1904f/config/coordinator.go#L149
 func md5HashAsMetricValue(data []byte) float64 {
@@ -453,16 +1060,126 @@ func md5HashAsMetricValue(data []byte) float64 {
 	return float64(binary.LittleEndian.Uint64(bytes))
 }
 
+// watchCustomResourceConfigFile watches the Custom Resource State config file
+// of the given options and returns a factory generator which generates the
+// factories of the last valid config, starting with the given one. Changes
+// are applied by the discoverer, whose stores are swapped once they synced,
+// instead of restarting kube-state-metrics. reloaded is called with the
+// contents of the file or the error after each change.
+func watchCustomResourceConfigFile(ctx context.Context, opts *options.Options, discoverer *discovery.CRDiscoverer, validator customresourcestate.SchemaValidator, factories func() ([]customresource.RegistryFactory, error), reloaded func(crcFile []byte, err error)) func() ([]customresource.RegistryFactory, error) {
+	var mtx sync.Mutex
+	current := factories
+
+	crcViper := viper.New()
+	crcViper.SetConfigType("yaml")
+	crcViper.SetConfigFile(opts.CustomResourceConfigFile)
+	if err := crcViper.ReadInConfig(); err != nil {
+		klog.ErrorS(err, "Failed to watch custom resource config file", "file", opts.CustomResourceConfigFile)
+		return factories
+	}
+	crcViper.OnConfigChange(func(e fsnotify.Event) {
+		// The watch is not stopped when kube-state-metrics restarts.
+		if ctx.Err() != nil {
+			return
+		}
+		klog.InfoS("Changes detected", "name", e.Name)
+		crcFile, err := os.ReadFile(filepath.Clean(opts.CustomResourceConfigFile))
+		if err != nil {
+			reloaded(nil, err)
+			return
+		}
+		config, err := resolveCustomResourceConfig(opts)
+		if err != nil {
+			reloaded(nil, err)
+			return
+		}
+		fn, err := customresourcestate.FromConfig(config, discoverer, validator)
+		if err == nil {
+			// Invalid metrics only fail when generating the factories.
+			_, err = fn()
+		}
+		if err != nil {
+			reloaded(nil, err)
+			return
+		}
+		mtx.Lock()
+		current = fn
+		mtx.Unlock()
+		discoverer.MarkUpdated()
+		reloaded(crcFile, nil)
+	})
+	crcViper.WatchConfig()
+
+	return func() ([]customresource.RegistryFactory, error) {
+		mtx.Lock()
+		fn := current
+		mtx.Unlock()
+		return fn()
+	}
+}
+
+// watchFamilyFilters watches the options config file and swaps the filter of
+// the metric families when only the options configuring it changed, see
+// options.OnlyFamilyFiltersChanged. The stores are rebuilt with the new
+// filter by rebuild in the background, while other changes restart
+// kube-state-metrics. reloaded is called with the contents of the file or the
+// error after each reload of the filter.
+func watchFamilyFilters(ctx context.Context, opts *options.Options, file string, filter *generator.SwappableFamilyGeneratorFilter, rebuild func(), reloaded func(configFile []byte, err error)) {
+	last, err := os.ReadFile(filepath.Clean(file))
+	if err != nil {
+		klog.ErrorS(err, "Failed to watch options config file", "file", file)
+		return
+	}
+	cfgViper := viper.New()
+	cfgViper.SetConfigType("yaml")
+	cfgViper.SetConfigFile(file)
+	if err := cfgViper.ReadInConfig(); err != nil {
+		klog.ErrorS(err, "Failed to watch options config file", "file", file)
+		return
+	}
+	cfgViper.OnConfigChange(func(e fsnotify.Event) {
+		// The watch is not stopped when kube-state-metrics restarts.
+		if ctx.Err() != nil {
+			return
+		}
+		configFile, err := os.ReadFile(filepath.Clean(file))
+		if err != nil {
+			reloaded(nil, err)
+			return
+		}
+		onlyFilters, err := options.OnlyFamilyFiltersChanged(last, configFile)
+		if err != nil || !onlyFilters {
+			// kube-state-metrics is restarted with the changed options.
+			return
+		}
+		last = configFile
+		next, err := opts.WithFamilyFilters(configFile)
+		if err != nil {
+			reloaded(nil, err)
+			return
+		}
+		f, err := newFamilyGeneratorFilter(next)
+		if err != nil {
+			reloaded(nil, err)
+			return
+		}
+		filter.Swap(f)
+		reloaded(configFile, nil)
+		go rebuild()
+	})
+	cfgViper.WatchConfig()
+}
+
 func resolveCustomResourceConfig(opts *options.Options) (customresourcestate.ConfigDecoder, error) {
 	if s := opts.CustomResourceConfig; s != "" {
-		return yaml.NewDecoder(strings.NewReader(s)), nil
+		return customresourcestate.NewConfigDecoder(strings.NewReader(s)), nil
 	}
 	if file := opts.CustomResourceConfigFile; file != "" {
 		f, err := os.Open(filepath.Clean(file))
 		if err != nil {
 			return nil, fmt.Errorf("Custom Resource State Metrics file could not be opened: %v", err)
 		}
-		return yaml.NewDecoder(f), nil
+		return customresourcestate.NewConfigDecoder(f), nil
 	}
 	return nil, nil
 }
@@ -19,9 +19,14 @@ package app
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
 	"sort"
 	"strconv"
 	"strings"
@@ -230,6 +235,19 @@ func TestFullScrapeCycle(t *testing.T) {
 # HELP kube_pod_init_container_status_terminated_reason Describes the reason the init container is currently in terminated state.
 # HELP kube_pod_init_container_status_waiting [STABLE] Describes whether the init container is currently in waiting state.
 # HELP kube_pod_init_container_status_waiting_reason Describes the reason the init container is currently in waiting state.
+# HELP kube_pod_init_container_restart_policy Describes the restart policy of an init container, which is only set for restartable init containers.
+# HELP kube_pod_ephemeral_container_info Information about an ephemeral container in a pod.
+# HELP kube_pod_ephemeral_container_state_started Start time in unix timestamp for a pod ephemeral container.
+# HELP kube_pod_ephemeral_container_status_last_terminated_exitcode Describes the exit code for the last ephemeral container in terminated state.
+# HELP kube_pod_ephemeral_container_status_last_terminated_reason Describes the last reason the ephemeral container was in terminated state.
+# HELP kube_pod_ephemeral_container_status_restarts_total The number of restarts for the ephemeral container.
+# HELP kube_pod_ephemeral_container_status_running Describes whether the ephemeral container is currently in running state.
+# HELP kube_pod_ephemeral_container_status_terminated Describes whether the ephemeral container is currently in terminated state.
+# HELP kube_pod_ephemeral_container_status_terminated_reason Describes the reason the ephemeral container is currently in terminated state.
+# HELP kube_pod_ephemeral_container_status_waiting Describes whether the ephemeral container is currently in waiting state.
+# HELP kube_pod_ephemeral_container_status_waiting_reason Describes the reason the ephemeral container is currently in waiting state.
+# HELP kube_pod_init_container_state_started Start time in unix timestamp for a pod init container.
+# HELP kube_pod_init_container_status_last_terminated_exitcode Describes the exit code for the last init container in terminated state.
 # HELP kube_pod_ips Pod IP addresses
 # HELP kube_pod_labels [STABLE] Kubernetes labels converted to Prometheus labels.
 # HELP kube_pod_overhead_cpu_cores The pod overhead in regards to cpu cores associated with running a pod.
@@ -239,6 +257,8 @@ func TestFullScrapeCycle(t *testing.T) {
 # HELP kube_pod_service_account The service account for a pod.
 # HELP kube_pod_owner [STABLE] Information about the Pod's owner.
 # HELP kube_pod_restart_policy [STABLE] Describes the restart policy in use by this pod.
+# HELP kube_pod_spec_resource_limits The pod-level limit of a resource set in the pod spec, in the base unit of the resource.
+# HELP kube_pod_spec_resource_requests The pod-level request of a resource set in the pod spec, in the base unit of the resource.
 # HELP kube_pod_spec_volumes_persistentvolumeclaims_info [STABLE] Information about persistentvolumeclaim volumes in a pod.
 # HELP kube_pod_spec_volumes_persistentvolumeclaims_readonly [STABLE] Describes whether a persistentvolumeclaim is mounted read only.
 # HELP kube_pod_start_time [STABLE] Start time in unix timestamp for a pod.
@@ -248,6 +268,7 @@ func TestFullScrapeCycle(t *testing.T) {
 # HELP kube_pod_status_phase [STABLE] The pods current phase.
 # HELP kube_pod_status_ready_time Readiness achieved time in unix timestamp for a pod.
 # HELP kube_pod_status_ready [STABLE] Describes whether the pod is ready to serve requests.
+# HELP kube_pod_status_eviction_category The category of the eviction of an evicted pod, i.e. the resource or node condition the kubelet evicted the pod for.
 # HELP kube_pod_status_reason The pod status reasons
 # HELP kube_pod_status_scheduled [STABLE] Describes the status of the scheduling process for the pod.
 # HELP kube_pod_status_scheduled_time [STABLE] Unix timestamp when pod moved into scheduled status
@@ -282,6 +303,19 @@ func TestFullScrapeCycle(t *testing.T) {
 # TYPE kube_pod_init_container_status_terminated_reason gauge
 # TYPE kube_pod_init_container_status_waiting gauge
 # TYPE kube_pod_init_container_status_waiting_reason gauge
+# TYPE kube_pod_init_container_restart_policy gauge
+# TYPE kube_pod_ephemeral_container_info gauge
+# TYPE kube_pod_ephemeral_container_state_started gauge
+# TYPE kube_pod_ephemeral_container_status_last_terminated_exitcode gauge
+# TYPE kube_pod_ephemeral_container_status_last_terminated_reason gauge
+# TYPE kube_pod_ephemeral_container_status_restarts_total counter
+# TYPE kube_pod_ephemeral_container_status_running gauge
+# TYPE kube_pod_ephemeral_container_status_terminated gauge
+# TYPE kube_pod_ephemeral_container_status_terminated_reason gauge
+# TYPE kube_pod_ephemeral_container_status_waiting gauge
+# TYPE kube_pod_ephemeral_container_status_waiting_reason gauge
+# TYPE kube_pod_init_container_state_started gauge
+# TYPE kube_pod_init_container_status_last_terminated_exitcode gauge
 # TYPE kube_pod_ips gauge
 # TYPE kube_pod_labels gauge
 # TYPE kube_pod_overhead_cpu_cores gauge
@@ -291,6 +325,8 @@ func TestFullScrapeCycle(t *testing.T) {
 # TYPE kube_pod_service_account gauge
 # TYPE kube_pod_owner gauge
 # TYPE kube_pod_restart_policy gauge
+# TYPE kube_pod_spec_resource_limits gauge
+# TYPE kube_pod_spec_resource_requests gauge
 # TYPE kube_pod_spec_volumes_persistentvolumeclaims_info gauge
 # TYPE kube_pod_spec_volumes_persistentvolumeclaims_readonly gauge
 # TYPE kube_pod_start_time gauge
@@ -300,6 +336,7 @@ func TestFullScrapeCycle(t *testing.T) {
 # TYPE kube_pod_status_qos_class gauge
 # TYPE kube_pod_status_ready gauge
 # TYPE kube_pod_status_ready_time gauge
+# TYPE kube_pod_status_eviction_category gauge
 # TYPE kube_pod_status_reason gauge
 # TYPE kube_pod_status_scheduled gauge
 # TYPE kube_pod_status_scheduled_time gauge
@@ -378,7 +415,7 @@ kube_pod_status_reason{namespace="default",pod="pod0",uid="abc-0",reason="Unexpe
 		}
 	}
 
-	telemetryMux := buildTelemetryServer(reg)
+	telemetryMux := buildTelemetryServer(reg, handler, &drainer{})
 
 	req2 := httptest.NewRequest("GET", "http://localhost:8081/metrics", nil)
 
@@ -427,8 +464,122 @@ kube_state_metrics_total_shards 1
 	}
 }
 
+// TestStartupProbe checks that the startup endpoint only succeeds once the
+// stores have synced.
+func TestStartupProbe(t *testing.T) {
+	t.Parallel()
+
+	kubeClient := fake.NewSimpleClientset()
+	if err := pod(kubeClient, 0); err != nil {
+		t.Fatalf("failed to insert sample pod %v", err.Error())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	builder := store.NewBuilder()
+	builder.WithMetrics(prometheus.NewRegistry())
+	if err := builder.WithEnabledResources([]string{"pods"}); err != nil {
+		t.Fatal(err)
+	}
+	builder.WithKubeClient(kubeClient)
+	builder.WithNamespaces(options.DefaultNamespaces)
+	builder.WithGenerateStoresFunc(builder.DefaultGenerateStoresFunc())
+	builder.WithFamilyGeneratorFilter(generator.NewCompositeFamilyGeneratorFilter())
+
+	handler := metricshandler.New(&options.Options{}, kubeClient, builder, false)
+	telemetryMux := buildTelemetryServer(prometheus.NewRegistry(), handler, &drainer{})
+	var body string
+	probe := func() int {
+		w := httptest.NewRecorder()
+		telemetryMux.ServeHTTP(w, httptest.NewRequest("GET", "http://localhost:8081/startupz", nil))
+		body = strings.TrimSpace(w.Body.String())
+		return w.Code
+	}
+
+	if code := probe(); code != http.StatusServiceUnavailable {
+		t.Errorf("expected status %d before the stores were built, got %d", http.StatusServiceUnavailable, code)
+	}
+	if want := `{"synced":false,"resources":[]}`; body != want {
+		t.Errorf("expected the progress %s before the stores were built, got %s", want, body)
+	}
+
+	handler.ConfigureSharding(ctx, 0, 1)
+	syncCtx, syncCancel := context.WithTimeout(ctx, 10*time.Second)
+	defer syncCancel()
+	if err := waitForSync(syncCtx, handler); err != nil {
+		t.Fatal(err)
+	}
+	if code := probe(); code != http.StatusOK {
+		t.Errorf("expected status %d after the stores synced, got %d", http.StatusOK, code)
+	}
+	// The pod is counted along with the object of the aggregated
+	// kube_pod_priority_class_count family.
+	if want := `{"synced":true,"resources":[{"resource":"pods","synced":true,"objects":2}]}`; body != want {
+		t.Errorf("expected the progress %s after the stores synced, got %s", want, body)
+	}
+}
+
 // TestShardingEquivalenceScrapeCycle is a simple smoke test covering the entire cycle from
 // cache filling to scraping comparing a sharded with an unsharded setup.
+// TestMetricsSchema checks that the schema endpoint lists the families which
+// are currently served.
+func TestMetricsSchema(t *testing.T) {
+	t.Parallel()
+
+	kubeClient := fake.NewSimpleClientset()
+	if err := pod(kubeClient, 0); err != nil {
+		t.Fatalf("failed to insert sample pod %v", err.Error())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	builder := store.NewBuilder()
+	builder.WithMetrics(prometheus.NewRegistry())
+	if err := builder.WithEnabledResources([]string{"pods"}); err != nil {
+		t.Fatal(err)
+	}
+	builder.WithKubeClient(kubeClient)
+	builder.WithNamespaces(options.DefaultNamespaces)
+	builder.WithGenerateStoresFunc(builder.DefaultGenerateStoresFunc())
+
+	l, err := allowdenylist.New(map[string]struct{}{"kube_pod_info": {}, "kube_pod_status_ready_time": {}}, map[string]struct{}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Parse(); err != nil {
+		t.Fatal(err)
+	}
+	builder.WithFamilyGeneratorFilter(generator.NewCompositeFamilyGeneratorFilter(l))
+
+	handler := metricshandler.New(&options.Options{}, kubeClient, builder, false)
+	handler.ConfigureSharding(ctx, 0, 1)
+
+	// Wait for caches to fill
+	time.Sleep(time.Second)
+
+	w := httptest.NewRecorder()
+	handler.SchemaHandler().ServeHTTP(w, httptest.NewRequest("GET", "http://localhost:8080/metrics/schema", nil))
+	resp := w.Result()
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected 200 status code but got %v", resp.StatusCode)
+	}
+
+	var schema metricshandler.Schema
+	if err := json.NewDecoder(resp.Body).Decode(&schema); err != nil {
+		t.Fatal(err)
+	}
+	// kube_pod_status_ready_time has no series for the sample pod.
+	expected := metricshandler.Schema{Families: []metricshandler.FamilySchema{{
+		Name:      "kube_pod_info",
+		Type:      "gauge",
+		Help:      "[STABLE] Information about pod.",
+		LabelKeys: []string{"created_by_kind", "created_by_name", "host_ip", "host_network", "namespace", "node", "pod", "pod_ip", "priority_class", "uid"},
+	}}}
+	if !reflect.DeepEqual(schema, expected) {
+		t.Errorf("want schema:\n%+v\ngot:\n%+v", expected, schema)
+	}
+}
+
 func TestShardingEquivalenceScrapeCycle(t *testing.T) {
 	t.Parallel()
 
@@ -443,10 +594,17 @@ func TestShardingEquivalenceScrapeCycle(t *testing.T) {
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	l, err := allowdenylist.New(map[string]struct{}{}, map[string]struct{}{})
+	// Aggregated families are counted per shard, so they differ between the
+	// sharded and the unsharded output.
+	l, err := allowdenylist.New(map[string]struct{}{}, map[string]struct{}{
+		"kube_pod_priority_class_count": {},
+	})
 	if err != nil {
 		t.Fatal(err)
 	}
+	if err := l.Parse(); err != nil {
+		t.Fatal(err)
+	}
 
 	reg := prometheus.NewRegistry()
 	unshardedBuilder := store.NewBuilder()
@@ -967,3 +1125,125 @@ func (f *fooFactory) ListWatch(customResourceClient interface{}, ns string, fiel
 		},
 	}
 }
+
+func TestAllowCIDRs(t *testing.T) {
+	cidrs, err := parseCIDRs([]string{"10.0.0.0/8", " fd00::/8"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parseCIDRs([]string{"10.0.0.1"}); err == nil {
+		t.Error("expected an error for an address without prefix length")
+	}
+
+	handler := allowCIDRs(cidrs, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	tests := []struct {
+		remoteAddr string
+		path       string
+		want       int
+	}{
+		{remoteAddr: "10.1.2.3:4711", path: metricsPath, want: http.StatusOK},
+		{remoteAddr: "[fd00::1]:4711", path: metricsPath, want: http.StatusOK},
+		{remoteAddr: "192.168.0.1:4711", path: metricsPath, want: http.StatusForbidden},
+		{remoteAddr: "192.168.0.1:4711", path: "/debug/pprof/", want: http.StatusForbidden},
+		{remoteAddr: "192.168.0.1:4711", path: healthzPath, want: http.StatusOK},
+		{remoteAddr: "192.168.0.1:4711", path: startupPath, want: http.StatusOK},
+		{remoteAddr: "192.168.0.1:4711", path: readyPath, want: http.StatusOK},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest("GET", "http://localhost:8080"+test.path, nil)
+		req.RemoteAddr = test.remoteAddr
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != test.want {
+			t.Errorf("expected status %d for %s from %s, got %d", test.want, test.path, test.remoteAddr, w.Code)
+		}
+	}
+}
+
+func TestNewHTTPServer(t *testing.T) {
+	opts := options.NewOptions()
+	opts.ServerReadTimeout = time.Minute
+	opts.ServerMaxHeaderBytes = 4096
+	opts.ServerMaxRequestBodyBytes = 4
+	server := newHTTPServer(opts, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.ReadAll(r.Body); err != nil {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+		}
+	}))
+	if server.ReadTimeout != time.Minute || server.MaxHeaderBytes != 4096 {
+		t.Errorf("expected the timeouts and limits of the options, got %+v", server)
+	}
+
+	for body, want := range map[string]int{"1234": http.StatusOK, "12345": http.StatusRequestEntityTooLarge} {
+		w := httptest.NewRecorder()
+		server.Handler.ServeHTTP(w, httptest.NewRequest("POST", metricsPath, strings.NewReader(body)))
+		if w.Code != want {
+			t.Errorf("expected status %d for a body of %d bytes, got %d", want, len(body), w.Code)
+		}
+	}
+}
+
+func TestWatchFamilyFilters(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(file, []byte("port: 8080\nmetric_denylist:\n  kube_pod_info: {}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	opts := options.NewOptions()
+	opts.Config = file
+	opts.MetricDenylist = options.MetricSet{"kube_pod_info": {}}
+	optionsFilter, err := newFamilyGeneratorFilter(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	filter := generator.NewSwappableFamilyGeneratorFilter(optionsFilter)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	rebuilt := make(chan struct{}, 10)
+	reloaded := make(chan error, 10)
+	watchFamilyFilters(ctx, opts, file, filter, func() { rebuilt <- struct{}{} }, func(_ []byte, err error) { reloaded <- err })
+
+	podInfo := generator.FamilyGenerator{Name: "kube_pod_info"}
+	podLabels := generator.FamilyGenerator{Name: "kube_pod_labels"}
+	if filter.Test(podInfo) {
+		t.Fatal("expected kube_pod_info to be denied initially")
+	}
+
+	if err := os.WriteFile(file, []byte("port: 8080\nmetric_denylist:\n  kube_pod_labels: {}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-reloaded:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for the filters to be reloaded")
+	}
+	select {
+	case <-rebuilt:
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for the stores to be rebuilt")
+	}
+	if !filter.Test(podInfo) || filter.Test(podLabels) {
+		t.Error("expected the reloaded denylist to deny kube_pod_labels only")
+	}
+	for len(reloaded) > 0 {
+		<-reloaded
+	}
+
+	// Changes of other options are left to the restart.
+	if err := os.WriteFile(file, []byte("port: 9090\nmetric_denylist:\n  kube_pod_info: {}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-reloaded:
+		t.Errorf("expected no reload, got %v", err)
+	case <-time.After(time.Second):
+	}
+	if !filter.Test(podInfo) || filter.Test(podLabels) {
+		t.Error("expected the filter to be unchanged")
+	}
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// ErrTerminated is returned by RunKubeStateMetrics if it stopped because of
+// a termination signal.
+var ErrTerminated = errors.New("terminated by signal")
+
+// drainer keeps the servers running for a drain period after a termination
+// signal, while failing the readiness probe, so that scrapers get a final
+// scrape before the listeners are shut down.
+type drainer struct {
+	period   time.Duration
+	draining atomic.Bool
+}
+
+// run waits for a signal and drains for the drain period. It returns
+// ErrTerminated after draining, and nil if the context is done before.
+func (d *drainer) run(ctx context.Context, signals <-chan os.Signal) error {
+	select {
+	case <-ctx.Done():
+		return nil
+	case sig := <-signals:
+		klog.InfoS("Received termination signal, draining before shutting down", "signal", sig.String(), "drainPeriod", d.period)
+	}
+	d.draining.Store(true)
+	select {
+	case <-ctx.Done():
+	case <-time.After(d.period):
+	}
+	return ErrTerminated
+}
+
+// readyHandler returns the handler of the readiness probe, which fails while
+// draining.
+func (d *drainer) readyHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if d.draining.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte("shutting down"))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(http.StatusText(http.StatusOK)))
+	})
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestDrainer(t *testing.T) {
+	d := &drainer{period: 50 * time.Millisecond}
+	ready := func() int {
+		w := httptest.NewRecorder()
+		d.readyHandler().ServeHTTP(w, httptest.NewRequest("GET", readyPath, nil))
+		return w.Code
+	}
+
+	if code := ready(); code != http.StatusOK {
+		t.Fatalf("expected ready before the signal, got %d", code)
+	}
+
+	signals := make(chan os.Signal, 1)
+	done := make(chan error)
+	go func() {
+		done <- d.run(context.Background(), signals)
+	}()
+	signals <- syscall.SIGTERM
+
+	start := time.Now()
+	for !d.draining.Load() {
+		time.Sleep(time.Millisecond)
+	}
+	if code := ready(); code != http.StatusServiceUnavailable {
+		t.Errorf("expected not ready while draining, got %d", code)
+	}
+	if err := <-done; !errors.Is(err, ErrTerminated) {
+		t.Errorf("expected ErrTerminated, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("expected to drain for the drain period, returned after %s", elapsed)
+	}
+
+	// Without a signal, it returns once the context is done.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := (&drainer{period: time.Hour}).run(ctx, make(chan os.Signal)); err != nil {
+		t.Errorf("expected no error when the context is done, got %v", err)
+	}
+}
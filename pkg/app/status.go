@@ -0,0 +1,85 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+
+	"github.com/prometheus/exporter-toolkit/web"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+
+	"k8s.io/kube-state-metrics/v2/pkg/metricshandler"
+)
+
+// statusTemplate renders the status sections of the landing page.
+var statusTemplate = template.Must(template.New("status").Parse(`
+<h3>Resources</h3>
+{{if .Status.Synced}}<div>All stores have synced.</div>{{else}}<div>Waiting for the stores to sync, see <a href="{{.StartupPath}}">{{.StartupPath}}</a>.</div>{{end}}
+<table>
+<tr><th>Resource</th><th>Synced</th><th>Objects</th><th>Metric families</th></tr>
+{{range .Status.Resources}}<tr><td><a href="{{$.ResourcesPath}}{{.Resource}}">{{.Resource}}</a></td><td>{{.Synced}}</td><td>{{.Objects}}</td><td>{{.Families}}</td></tr>
+{{end}}</table>
+<h3>Sharding</h3>
+<div>Shard {{.Status.Shard}} of {{.Status.TotalShards}}{{if .Status.Autosharding}}, detected from the StatefulSet of the pod, see <a href="{{.ShardsPath}}">{{.ShardsPath}}</a> for all shards{{end}}.</div>
+{{if .CustomResources}}<h3>Discovered custom resources</h3>
+<ul>
+{{range .CustomResources}}<li>{{.Group}}/{{.Version}}, Kind={{.Kind}}</li>
+{{end}}</ul>{{end}}
+`))
+
+// statusPage serves the landing page, extended by the status of the served
+// stores, the sharding and the discovered custom resources.
+type statusPage struct {
+	config web.LandingConfig
+	status func() metricshandler.Status
+	// customResources returns the discovered custom resources. It is nil
+	// without discovery.
+	customResources func() []schema.GroupVersionKind
+}
+
+func (p *statusPage) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	data := struct {
+		Status                                 metricshandler.Status
+		CustomResources                        []schema.GroupVersionKind
+		StartupPath, ResourcesPath, ShardsPath string
+	}{
+		Status:        p.status(),
+		StartupPath:   startupPath,
+		ResourcesPath: resourcesPath,
+		ShardsPath:    shardsPath,
+	}
+	if p.customResources != nil {
+		data.CustomResources = p.customResources()
+	}
+	var buf bytes.Buffer
+	if err := statusTemplate.Execute(&buf, data); err != nil {
+		klog.ErrorS(err, "Failed to render the status of the landing page")
+	}
+
+	config := p.config
+	config.ExtraHTML = buf.String()
+	page, err := web.NewLandingPage(config)
+	if err != nil {
+		klog.ErrorS(err, "Failed to create landing page")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	page.ServeHTTP(w, r)
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/exporter-toolkit/web"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"k8s.io/kube-state-metrics/v2/pkg/metricshandler"
+)
+
+func TestStatusPage(t *testing.T) {
+	status := metricshandler.Status{
+		Shard:       0,
+		TotalShards: 1,
+		Resources: []metricshandler.ResourceStatus{
+			{ResourceSyncProgress: metricshandler.ResourceSyncProgress{Resource: "pods", Synced: false, Objects: 3}, Families: 0},
+		},
+	}
+	p := &statusPage{
+		config: web.LandingConfig{Name: "kube-state-metrics", Links: []web.LandingLinks{{Address: metricsPath, Text: "Metrics"}}},
+		status: func() metricshandler.Status { return status },
+	}
+	get := func() string {
+		w := httptest.NewRecorder()
+		p.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+		}
+		return w.Body.String()
+	}
+
+	body := get()
+	for _, want := range []string{
+		`<a href="/metrics">Metrics</a>`,
+		"Waiting for the stores to sync",
+		`<tr><td><a href="/metrics/resources/pods">pods</a></td><td>false</td><td>3</td><td>0</td></tr>`,
+		"Shard 0 of 1.",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected the landing page to contain %q, got\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "Discovered custom resources") {
+		t.Errorf("expected no custom resources without discovery, got\n%s", body)
+	}
+
+	// The status is rendered for each request.
+	status.Synced = true
+	status.Shard, status.TotalShards, status.Autosharding = 1, 2, true
+	p.customResources = func() []schema.GroupVersionKind {
+		return []schema.GroupVersionKind{{Group: "samplecontroller.k8s.io", Version: "v1alpha1", Kind: "Foo<"}}
+	}
+	body = get()
+	for _, want := range []string{
+		"All stores have synced.",
+		`Shard 1 of 2, detected from the StatefulSet of the pod, see <a href="/shards">/shards</a> for all shards.`,
+		"<li>samplecontroller.k8s.io/v1alpha1, Kind=Foo&lt;</li>",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected the landing page to contain %q, got\n%s", want, body)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/prometheus/exporter-toolkit/web"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/klog/v2"
+
+	"k8s.io/kube-state-metrics/v2/internal/store"
+	"k8s.io/kube-state-metrics/v2/pkg/options"
+)
+
+// NewValidateCommand returns the command which validates the given options,
+// including the files they reference, without serving metrics. flags are the
+// flags of the options, as added to the root command.
+func NewValidateCommand(opts *options.Options, flags *pflag.FlagSet) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Validate the options and config files.",
+		Long:  "Validate the options, the options config file, the Custom Resource State config and the TLS config without connecting to the apiserver, e.g. in CI before deploying a change of the manifests.",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := validateOptions(opts); err != nil {
+				klog.ErrorS(err, "Invalid configuration")
+				klog.FlushAndExit(klog.ExitFlushTimeout, 1)
+			}
+			fmt.Println("Configuration is valid.")
+			klog.FlushAndExit(klog.ExitFlushTimeout, 0)
+		},
+		Example: "kube-state-metrics validate --config=config.yaml",
+	}
+	cmd.Flags().AddFlagSet(flags)
+	return cmd
+}
+
+// validateOptions returns an error if kube-state-metrics could not serve
+// metrics with the given options.
+func validateOptions(opts *options.Options) error {
+	if file := options.GetConfigFile(*opts); file != "" {
+		configFile, err := os.ReadFile(filepath.Clean(file))
+		if err != nil {
+			return fmt.Errorf("failed to read opts config file: %v", err)
+		}
+		if err := opts.ApplyConfigFile(configFile); err != nil {
+			return fmt.Errorf("failed to unmarshal opts config file: %v", err)
+		}
+	}
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+	filter, err := newFamilyGeneratorFilter(opts)
+	if err != nil {
+		return err
+	}
+	if _, err := configureStoreBuilder(store.NewBuilder(), opts, filter); err != nil {
+		return err
+	}
+	if _, err := customResourceFactories(opts); err != nil {
+		return err
+	}
+	if opts.TLSConfig != "" {
+		if err := web.Validate(opts.TLSConfig); err != nil {
+			return fmt.Errorf("invalid TLS config: %v", err)
+		}
+	}
+	return nil
+}
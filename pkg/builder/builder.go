@@ -18,17 +18,23 @@ package builder
 
 import (
 	"context"
+	"regexp"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
 	"k8s.io/client-go/tools/cache"
 
 	internalstore "k8s.io/kube-state-metrics/v2/internal/store"
 	ksmtypes "k8s.io/kube-state-metrics/v2/pkg/builder/types"
 	"k8s.io/kube-state-metrics/v2/pkg/customresource"
+	"k8s.io/kube-state-metrics/v2/pkg/httpsd"
+	"k8s.io/kube-state-metrics/v2/pkg/metric"
 	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
 	metricsstore "k8s.io/kube-state-metrics/v2/pkg/metrics_store"
 	"k8s.io/kube-state-metrics/v2/pkg/options"
+	"k8s.io/kube-state-metrics/v2/pkg/watch"
 )
 
 // Make sure the public Builder implements the public BuilderInterface.
@@ -69,6 +75,172 @@ func (b *Builder) WithFieldSelectorFilter(fieldSelectorFilter string) {
 	b.internal.WithFieldSelectorFilter(fieldSelectorFilter)
 }
 
+// WithNamespaceDenylistPatterns configures regular expressions of namespaces
+// whose objects are excluded from metric generation.
+func (b *Builder) WithNamespaceDenylistPatterns(patterns []*regexp.Regexp) {
+	b.internal.WithNamespaceDenylistPatterns(patterns)
+}
+
+// WithStoreShards configures the number of shards the metrics of each store
+// are split into by namespace.
+func (b *Builder) WithStoreShards(shards int) {
+	b.internal.WithStoreShards(shards)
+}
+
+// WithPodGCThreshold configures the duration after which Succeeded and Failed
+// pods are excluded from metric generation.
+func (b *Builder) WithPodGCThreshold(threshold time.Duration) {
+	b.internal.WithPodGCThreshold(threshold)
+}
+
+// WithResourceFieldSelectors sets the field selectors which are used instead
+// of the fieldSelectorFilter for specific resources.
+func (b *Builder) WithResourceFieldSelectors(fieldSelectors map[string]string) {
+	b.internal.WithResourceFieldSelectors(fieldSelectors)
+}
+
+// WithResourceListOptions sets the list options of specific resources,
+// which take precedence over WithUsingAPIServerCache.
+func (b *Builder) WithResourceListOptions(listOptions options.ResourceListOptions) {
+	b.internal.WithResourceListOptions(listOptions)
+}
+
+// WithSplitTerminalPods configures whether Succeeded and Failed pods are
+// served by kube_pod_terminal_info instead of kube_pod_info.
+func (b *Builder) WithSplitTerminalPods(split bool) {
+	b.internal.WithSplitTerminalPods(split)
+}
+
+// WithLastTerminatedReasonLimit configures the maximum number of distinct
+// reasons of kube_pod_container_status_last_terminated_info.
+func (b *Builder) WithLastTerminatedReasonLimit(limit int) {
+	b.internal.WithLastTerminatedReasonLimit(limit)
+}
+
+// WithPodStatusReasons configures the reasons served by kube_pod_status_reason.
+func (b *Builder) WithPodStatusReasons(reasons []string) {
+	b.internal.WithPodStatusReasons(reasons)
+}
+
+// WithNodeImageLimit configures the maximum number of images per node served
+// by kube_node_image_size_bytes.
+func (b *Builder) WithNodeImageLimit(limit int) {
+	b.internal.WithNodeImageLimit(limit)
+}
+
+// WithReplicaSetDeploymentLabel configures whether the name of the deployment
+// which controls a replicaset is added as deployment label to all replicaset
+// metric families.
+func (b *Builder) WithReplicaSetDeploymentLabel(enabled bool) {
+	b.internal.WithReplicaSetDeploymentLabel(enabled)
+}
+
+// WithMetricPrefix configures the prefix which replaces the default "kube_"
+// prefix of the metric family names.
+func (b *Builder) WithMetricPrefix(prefix string) error {
+	return b.internal.WithMetricPrefix(prefix)
+}
+
+// WithNamingConvention configures the naming convention of the metric
+// families, one of "v2" or "v3".
+func (b *Builder) WithNamingConvention(convention string) error {
+	return b.internal.WithNamingConvention(convention)
+}
+
+// WithLabelValueLimit configures the maximum length of label values of the
+// metric families and how longer values are handled.
+func (b *Builder) WithLabelValueLimit(limit metric.LabelValueLimit) error {
+	return b.internal.WithLabelValueLimit(limit)
+}
+
+// WithConditionLimit configures the maximum number of condition types per
+// object served by the kube_<resource>_status_condition families, and the
+// maximum number of distinct condition types per resource which are not
+// defined by Kubernetes. A limit of 0 disables it.
+func (b *Builder) WithConditionLimit(perObject, types int) error {
+	return b.internal.WithConditionLimit(perObject, types)
+}
+
+// WithTimestampPrecision configures the precision of the values of metrics
+// which are timestamps.
+func (b *Builder) WithTimestampPrecision(precision metric.TimestampPrecision) error {
+	return b.internal.WithTimestampPrecision(precision)
+}
+
+// WithExemplars configures the metric families whose metrics carry exemplars
+// with the UID of their object, and the annotation of the trace ID added to
+// the exemplars, if any.
+func (b *Builder) WithExemplars(families map[string]struct{}, traceIDAnnotation string) {
+	b.internal.WithExemplars(families, traceIDAnnotation)
+}
+
+// WithSnapshots configures the directory the objects of all stores are written
+// to every interval.
+func (b *Builder) WithSnapshots(dir string, interval time.Duration) {
+	b.internal.WithSnapshots(dir, interval)
+}
+
+// WithListConcurrency limits the concurrent list requests of all stores.
+func (b *Builder) WithListConcurrency(maxConcurrency int) {
+	b.internal.WithListConcurrency(maxConcurrency)
+}
+
+// WithStalenessTracker configures the tracker which records the results of
+// the list and watch requests of all stores.
+func (b *Builder) WithStalenessTracker(t *watch.StalenessTracker) {
+	b.internal.WithStalenessTracker(t)
+}
+
+// WithWriteTracker configures the tracker which records successful writes to
+// the stores.
+func (b *Builder) WithWriteTracker(t *watch.WriteTracker) {
+	b.internal.WithWriteTracker(t)
+}
+
+// WithPodTargets configures the pod targets of the HTTP service discovery,
+// which track the pods of the stores.
+func (b *Builder) WithPodTargets(t *httpsd.PodTargets) {
+	b.internal.WithPodTargets(t)
+}
+
+// WithNotifier configures the notifier which is notified about the changes
+// of the objects of the stores.
+func (b *Builder) WithNotifier(n *watch.Notifier) {
+	b.internal.WithNotifier(n)
+}
+
+// WithCache configures the directory the objects of all stores and their
+// resourceVersions are cached in every interval, to populate the stores from
+// on the next start.
+func (b *Builder) WithCache(dir string, interval time.Duration) {
+	b.internal.WithCache(dir, interval)
+}
+
+// WithBenchmarkObjects configures the number of generated objects the stores
+// of each resource are populated with, instead of listing and watching the
+// objects.
+func (b *Builder) WithBenchmarkObjects(counts map[string]int) error {
+	return b.internal.WithBenchmarkObjects(counts)
+}
+
+// WithSnapshotReplay configures the directory of a snapshot the stores are
+// populated from, instead of listing and watching the objects.
+func (b *Builder) WithSnapshotReplay(dir string) {
+	b.internal.WithSnapshotReplay(dir)
+}
+
+// WithOwnerKinds configures the owner reference kinds objects are filtered by,
+// per resource.
+func (b *Builder) WithOwnerKinds(allowList, denyList map[string][]string) {
+	b.internal.WithOwnerKinds(allowList, denyList)
+}
+
+// WithIgnoreAnnotation configures the annotation key which excludes objects
+// from metric generation.
+func (b *Builder) WithIgnoreAnnotation(key string) {
+	b.internal.WithIgnoreAnnotation(key)
+}
+
 // WithSharding sets the shard and totalShards property of a Builder.
 func (b *Builder) WithSharding(shard int32, totalShards int) {
 	b.internal.WithSharding(shard, totalShards)
@@ -84,6 +256,18 @@ func (b *Builder) WithKubeClient(c clientset.Interface) {
 	b.internal.WithKubeClient(c)
 }
 
+// WithMetadataClient sets the client the objects of the resources configured
+// by WithMetadataOnlyResources are watched by.
+func (b *Builder) WithMetadataClient(c metadata.Interface) {
+	b.internal.WithMetadataClient(c)
+}
+
+// WithMetadataOnlyResources configures the resources whose objects are watched
+// by their metadata only.
+func (b *Builder) WithMetadataOnlyResources(resources []string) error {
+	return b.internal.WithMetadataOnlyResources(resources)
+}
+
 // WithCustomResourceClients sets the customResourceClients property of a Builder.
 func (b *Builder) WithCustomResourceClients(cs map[string]interface{}) {
 	b.internal.WithCustomResourceClients(cs)
@@ -63,7 +63,8 @@ func TestBuilderWithCustomStore(t *testing.T) {
 	}
 }
 
-func customStore(_ []generator.FamilyGenerator,
+func customStore(_ string,
+	_ []generator.FamilyGenerator,
 	_ interface{},
 	_ func(kubeClient clientset.Interface, ns string, fieldSelector string) cache.ListerWatcher,
 	_ bool,
@@ -18,16 +18,22 @@ package types
 
 import (
 	"context"
+	"regexp"
+	"time"
 
 	metricsstore "k8s.io/kube-state-metrics/v2/pkg/metrics_store"
 
 	"github.com/prometheus/client_golang/prometheus"
 	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
 	"k8s.io/client-go/tools/cache"
 
 	"k8s.io/kube-state-metrics/v2/pkg/customresource"
+	"k8s.io/kube-state-metrics/v2/pkg/httpsd"
+	"k8s.io/kube-state-metrics/v2/pkg/metric"
 	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
 	"k8s.io/kube-state-metrics/v2/pkg/options"
+	"k8s.io/kube-state-metrics/v2/pkg/watch"
 )
 
 // BuilderInterface represent all methods that a Builder should implements
@@ -36,9 +42,38 @@ type BuilderInterface interface {
 	WithEnabledResources(c []string) error
 	WithNamespaces(n options.NamespaceList)
 	WithFieldSelectorFilter(fieldSelectors string)
+	WithResourceFieldSelectors(fieldSelectors map[string]string)
+	WithResourceListOptions(listOptions options.ResourceListOptions)
+	WithIgnoreAnnotation(key string)
+	WithNamespaceDenylistPatterns(patterns []*regexp.Regexp)
+	WithStoreShards(shards int)
+	WithPodGCThreshold(threshold time.Duration)
+	WithSplitTerminalPods(split bool)
+	WithLastTerminatedReasonLimit(limit int)
+	WithPodStatusReasons(reasons []string)
+	WithNodeImageLimit(limit int)
+	WithReplicaSetDeploymentLabel(enabled bool)
+	WithMetricPrefix(prefix string) error
+	WithNamingConvention(convention string) error
+	WithLabelValueLimit(limit metric.LabelValueLimit) error
+	WithConditionLimit(perObject, types int) error
+	WithTimestampPrecision(precision metric.TimestampPrecision) error
+	WithExemplars(families map[string]struct{}, traceIDAnnotation string)
+	WithSnapshots(dir string, interval time.Duration)
+	WithSnapshotReplay(dir string)
+	WithBenchmarkObjects(counts map[string]int) error
+	WithCache(dir string, interval time.Duration)
+	WithListConcurrency(maxConcurrency int)
+	WithStalenessTracker(t *watch.StalenessTracker)
+	WithWriteTracker(t *watch.WriteTracker)
+	WithNotifier(n *watch.Notifier)
+	WithPodTargets(t *httpsd.PodTargets)
+	WithOwnerKinds(allowList, denyList map[string][]string)
 	WithSharding(shard int32, totalShards int)
 	WithContext(ctx context.Context)
 	WithKubeClient(c clientset.Interface)
+	WithMetadataClient(c metadata.Interface)
+	WithMetadataOnlyResources(resources []string) error
 	WithCustomResourceClients(cs map[string]interface{})
 	WithUsingAPIServerCache(u bool)
 	WithFamilyGeneratorFilter(l generator.FamilyGeneratorFilter)
@@ -54,7 +89,8 @@ type BuilderInterface interface {
 }
 
 // BuildStoresFunc function signature that is used to return a list of cache.Store
-type BuildStoresFunc func(metricFamilies []generator.FamilyGenerator,
+type BuildStoresFunc func(resourceName string,
+	metricFamilies []generator.FamilyGenerator,
 	expectedType interface{},
 	listWatchFunc func(kubeClient clientset.Interface, ns string, fieldSelector string) cache.ListerWatcher,
 	useAPIServerCache bool,
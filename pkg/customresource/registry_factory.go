@@ -116,3 +116,14 @@ type RegistryFactory interface {
 	// }
 	ListWatch(customResourceClient interface{}, ns string, fieldSelector string) cache.ListerWatcher
 }
+
+// AllowListRegistryFactory is an optional interface for a RegistryFactory
+// which exposes annotations and labels metrics of the custom resource,
+// controlled via the '*' entry of --metric-annotations-allowlist and
+// --metric-labels-allowlist.
+type AllowListRegistryFactory interface {
+	// AllowListMetricFamilyGenerators returns the metric family generators for
+	// the annotations and labels metrics of the custom resource. Families
+	// should be omitted if their allow list is empty.
+	AllowListMetricFamilyGenerators(allowAnnotationsList, allowLabelsList []string) []generator.FamilyGenerator
+}
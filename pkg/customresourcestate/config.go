@@ -18,6 +18,7 @@ package customresourcestate
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/gobuffalo/flect"
@@ -35,6 +36,11 @@ const customResourceState string = "customresource"
 
 // Metrics is the top level configuration object.
 type Metrics struct {
+	// APIVersion is the version of the config, see ConfigAPIVersion.
+	APIVersion string `yaml:"apiVersion" json:"apiVersion"`
+	// Kind is the kind of the config, see ConfigKind.
+	Kind string `yaml:"kind" json:"kind"`
+	// Spec configures the metrics of the custom resources.
 	Spec MetricsSpec `yaml:"spec" json:"spec"`
 }
 
@@ -65,6 +71,35 @@ type Resource struct {
 
 	// ResourcePlural sets the plural name of the resource. Defaults to the plural version of the Kind according to flect.Pluralize.
 	ResourcePlural string `yaml:"resourcePlural" json:"resourcePlural"`
+
+	// AnnotationsAllowList overrides the '*' entry of --metric-annotations-allowlist for the resource.
+	// An empty list disables the annotations metric of the resource.
+	AnnotationsAllowList *[]string `yaml:"annotationsAllowList" json:"annotationsAllowList"`
+	// LabelsAllowList overrides the '*' entry of --metric-labels-allowlist for the resource.
+	// An empty list disables the labels metric of the resource.
+	LabelsAllowList *[]string `yaml:"labelsAllowList" json:"labelsAllowList"`
+
+	// LabelsFromOwner adds labels taken from an owner of the objects to all metrics of the resource.
+	LabelsFromOwner *OwnerLabels `yaml:"labelsFromOwner" json:"labelsFromOwner"`
+}
+
+// OwnerLabels configures labels whose values are taken from an owner of the objects. The owner is looked up by the
+// owner references of the objects in a cache of the owner resource, which requires list and watch permissions on it.
+type OwnerLabels struct {
+	// GroupVersionKind of the owner. Owner references to other kinds are ignored.
+	GroupVersionKind GroupVersionKind `yaml:"groupVersionKind" json:"groupVersionKind"`
+	// ResourcePlural sets the plural name of the owner resource. Defaults to the plural version of the Kind according to flect.Pluralize.
+	ResourcePlural string `yaml:"resourcePlural" json:"resourcePlural"`
+	// LabelsFromPath adds labels where the value is taken from a field of the owner.
+	LabelsFromPath map[string][]string `yaml:"labelsFromPath" json:"labelsFromPath"`
+}
+
+// GetResourceName returns the lowercase, plural form of the owner Kind. This is ResourcePlural if it is set.
+func (o OwnerLabels) GetResourceName() string {
+	if o.ResourcePlural != "" {
+		return o.ResourcePlural
+	}
+	return strings.ToLower(flect.Pluralize(o.GroupVersionKind.Kind))
 }
 
 // GetMetricNamePrefix returns the prefix to use for metrics.
@@ -143,6 +178,60 @@ type Generator struct {
 	ErrorLogV klog.Level `yaml:"errorLogV" json:"errorLogV"`
 }
 
+// FieldPaths returns the sorted paths of the fields of the custom resource
+// which the metric is generated from, with their elements joined by dots.
+// Paths of labels configured for the whole resource are not included.
+func (g Generator) FieldPaths() []string {
+	paths := map[string]struct{}{}
+	for _, p := range g.paths() {
+		paths[strings.Join(p, ".")] = struct{}{}
+	}
+	result := make([]string, 0, len(paths))
+	for p := range paths {
+		result = append(result, p)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// paths returns the paths of the fields of the custom resource which the
+// metric is generated from, see FieldPaths.
+func (g Generator) paths() [][]string {
+	var meta MetricMeta
+	var valueFrom []string
+	switch {
+	case g.Each.Gauge != nil:
+		meta, valueFrom = g.Each.Gauge.MetricMeta, g.Each.Gauge.ValueFrom
+	case g.Each.StateSet != nil:
+		meta, valueFrom = g.Each.StateSet.MetricMeta, g.Each.StateSet.ValueFrom
+	case g.Each.Info != nil:
+		meta = g.Each.Info.MetricMeta
+	}
+
+	var paths [][]string
+	add := func(path ...[]string) {
+		var elements []string
+		for _, p := range path {
+			elements = append(elements, p...)
+		}
+		if len(elements) > 0 {
+			paths = append(paths, elements)
+		}
+	}
+	if len(valueFrom) > 0 {
+		add(meta.Path, valueFrom)
+	} else {
+		add(meta.Path)
+	}
+	for _, p := range meta.LabelsFromPath {
+		add(meta.Path, p)
+	}
+	for _, p := range g.LabelsFromPath {
+		add(p)
+	}
+	return paths
+}
+
 // Metric defines a metric to expose.
 // +union
 type Metric struct {
@@ -167,7 +256,8 @@ type ConfigDecoder interface {
 }
 
 // FromConfig decodes a configuration source into a slice of `customresource.RegistryFactory` that are ready to use.
-func FromConfig(decoder ConfigDecoder, discovererInstance *discovery.CRDiscoverer) (func() ([]customresource.RegistryFactory, error), error) {
+// The configured paths of the resources are validated against the schemas of their CRDs by the validator.
+func FromConfig(decoder ConfigDecoder, discovererInstance *discovery.CRDiscoverer, validator SchemaValidator) (func() ([]customresource.RegistryFactory, error), error) {
 	var customResourceConfig Metrics
 	factoriesIndex := map[string]bool{}
 	if err := decoder.Decode(&customResourceConfig); err != nil {
@@ -175,6 +265,7 @@ func FromConfig(decoder ConfigDecoder, discovererInstance *discovery.CRDiscovere
 	}
 	fn := func() (factories []customresource.RegistryFactory, err error) {
 		resources := customResourceConfig.Spec.Resources
+		validator.reset()
 		// resolvedGVKPs will have the final list of GVKs, in addition to the resolved G** resources.
 		var resolvedGVKPs []Resource
 		for _, resource := range resources /* G** */ {
@@ -187,6 +278,9 @@ func FromConfig(decoder ConfigDecoder, discovererInstance *discovery.CRDiscovere
 				resource.GroupVersionKind = GroupVersionKind(resolved.GroupVersionKind)
 				// Set the plural name of the resource based on the extracted value from the same field in the CRD schema.
 				resource.ResourcePlural = resolved.Plural
+				if !validator.admit(resource, resolved.Schema) {
+					continue
+				}
 				resolvedGVKPs = append(resolvedGVKPs, resource)
 			}
 		}
@@ -45,6 +45,12 @@ type MetricGauge struct {
 	LabelFromKey string `yaml:"labelFromKey" json:"labelFromKey"`
 	// NilIsZero indicates that if a value is nil it will be treated as zero value.
 	NilIsZero bool `yaml:"nilIsZero" json:"nilIsZero"`
+	// Multiply multiplies the values by the given factor, e.g. 0.001 to convert millicores to cores.
+	Multiply *float64 `yaml:"multiply" json:"multiply"`
+	// Divide divides the values by the given non-zero divisor, after multiplying them.
+	Divide *float64 `yaml:"divide" json:"divide"`
+	// Offset is added to the values after multiplying and dividing them.
+	Offset float64 `yaml:"offset" json:"offset"`
 }
 
 // MetricInfo is a metric which is used to expose textual information.
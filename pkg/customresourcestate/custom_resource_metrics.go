@@ -18,6 +18,8 @@ package customresourcestate
 
 import (
 	"context"
+	"fmt"
+	"sort"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -29,7 +31,9 @@ import (
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 
+	"k8s.io/kube-state-metrics/v2/internal/store"
 	"k8s.io/kube-state-metrics/v2/pkg/customresource"
+	"k8s.io/kube-state-metrics/v2/pkg/metric"
 	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
 )
 
@@ -40,9 +44,20 @@ type customResourceMetrics struct {
 	GroupVersionKind schema.GroupVersionKind
 	ResourceName     string
 	Families         []compiledFamily
+	// resourceLabels holds the labels configured for the whole resource.
+	resourceLabels *compiledFamily
+	// annotationsAllowList and labelsAllowList override the allow lists of
+	// the options for the resource, if set.
+	annotationsAllowList *[]string
+	labelsAllowList      *[]string
+	// owner adds the labels taken from the owner of the objects, if not nil.
+	owner *compiledOwner
 }
 
-var _ customresource.RegistryFactory = &customResourceMetrics{}
+var (
+	_ customresource.RegistryFactory          = &customResourceMetrics{}
+	_ customresource.AllowListRegistryFactory = &customResourceMetrics{}
+)
 
 // NewCustomResourceMetrics creates a customresource.RegistryFactory from a configuration object.
 func NewCustomResourceMetrics(resource Resource) (customresource.RegistryFactory, error) {
@@ -50,12 +65,38 @@ func NewCustomResourceMetrics(resource Resource) (customresource.RegistryFactory
 	if err != nil {
 		return nil, err
 	}
+	resourceLabels, err := compileResourceLabels(resource)
+	if err != nil {
+		return nil, err
+	}
+	owner, err := compileOwner(resource.LabelsFromOwner)
+	if err != nil {
+		return nil, err
+	}
+	for i := range compiled {
+		compiled[i].owner = owner
+	}
+	resourceLabels.owner = owner
+	if l := resource.AnnotationsAllowList; l != nil {
+		if err := store.ValidateAllowList(*l); err != nil {
+			return nil, fmt.Errorf("annotationsAllowList: %w", err)
+		}
+	}
+	if l := resource.LabelsAllowList; l != nil {
+		if err := store.ValidateAllowList(*l); err != nil {
+			return nil, fmt.Errorf("labelsAllowList: %w", err)
+		}
+	}
 	gvk := schema.GroupVersionKind(resource.GroupVersionKind)
 	return &customResourceMetrics{
-		MetricNamePrefix: resource.GetMetricNamePrefix(),
-		GroupVersionKind: gvk,
-		Families:         compiled,
-		ResourceName:     resource.GetResourceName(),
+		MetricNamePrefix:     resource.GetMetricNamePrefix(),
+		GroupVersionKind:     gvk,
+		Families:             compiled,
+		ResourceName:         resource.GetResourceName(),
+		resourceLabels:       resourceLabels,
+		annotationsAllowList: resource.AnnotationsAllowList,
+		labelsAllowList:      resource.LabelsAllowList,
+		owner:                owner,
 	}, nil
 }
 
@@ -68,6 +109,9 @@ func (s customResourceMetrics) CreateClient(cfg *rest.Config) (interface{}, erro
 	if err != nil {
 		return nil, err
 	}
+	if s.owner != nil {
+		s.owner.cache.start(c)
+	}
 	return c.Resource(schema.GroupVersionResource{
 		Group:    s.GroupVersionKind.Group,
 		Version:  s.GroupVersionKind.Version,
@@ -75,15 +119,125 @@ func (s customResourceMetrics) CreateClient(cfg *rest.Config) (interface{}, erro
 	}), nil
 }
 
+// MetricFamilyGenerators returns the configured metric families of the
+// custom resource, in the order of the config, followed by the generation
+// mismatch family unless a configured family has its name.
 func (s customResourceMetrics) MetricFamilyGenerators() (result []generator.FamilyGenerator) {
 	klog.InfoS("Custom resource state added metrics", "familyNames", s.names())
+	generationMismatch := s.generationMismatchFamilyGenerator()
 	for _, f := range s.Families {
 		result = append(result, famGen(f))
+		if f.Name == generationMismatch.Name {
+			generationMismatch.Name = ""
+		}
+	}
+	if generationMismatch.Name != "" {
+		result = append(result, generationMismatch)
 	}
 
 	return result
 }
 
+// generationMismatchFamilyGenerator returns the family of the difference of
+// metadata.generation and status.observedGeneration, for custom resources
+// whose controller reports the observed generation.
+func (s customResourceMetrics) generationMismatchFamilyGenerator() generator.FamilyGenerator {
+	name := "generation_mismatch"
+	if s.MetricNamePrefix != "" {
+		name = s.MetricNamePrefix + "_" + name
+	}
+	return generator.FamilyGenerator{
+		Name: name,
+		Type: metric.Gauge,
+		Help: "The difference between the generation of the desired state and the generation observed by the controller, which is positive until the controller processed the latest change.",
+		GenerateFunc: func(obj interface{}) *metric.Family {
+			u := obj.(*unstructured.Unstructured)
+			observed, ok, err := unstructured.NestedInt64(u.Object, "status", "observedGeneration")
+			if !ok || err != nil {
+				return &metric.Family{}
+			}
+			keys, values := s.resourceLabelKeysValues(u)
+			return &metric.Family{
+				Metrics: []*metric.Metric{
+					{
+						LabelKeys:   keys,
+						LabelValues: values,
+						Value:       float64(u.GetGeneration() - observed),
+					},
+				},
+			}
+		},
+	}
+}
+
+// resourceLabelKeysValues returns the labels configured for the whole
+// resource, sorted by key.
+func (s customResourceMetrics) resourceLabelKeysValues(u *unstructured.Unstructured) (keys, values []string) {
+	baseLabels := s.resourceLabels.BaseLabels(u.Object)
+	keys = make([]string, 0, len(baseLabels))
+	for k := range baseLabels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	values = make([]string, len(keys))
+	for i, k := range keys {
+		values[i] = baseLabels[k]
+	}
+	return keys, values
+}
+
+// AllowListMetricFamilyGenerators returns the annotations and labels metric
+// families of the custom resource. They carry the labels configured for the
+// whole resource. The allow lists of the resource config take precedence
+// over the given ones.
+func (s customResourceMetrics) AllowListMetricFamilyGenerators(allowAnnotationsList, allowLabelsList []string) (result []generator.FamilyGenerator) {
+	if s.annotationsAllowList != nil {
+		allowAnnotationsList = *s.annotationsAllowList
+	}
+	if s.labelsAllowList != nil {
+		allowLabelsList = *s.labelsAllowList
+	}
+	if len(allowAnnotationsList) > 0 {
+		result = append(result, s.allowListFamilyGenerator("annotations", "Kubernetes annotations converted to Prometheus labels.", "annotation", allowAnnotationsList, (*unstructured.Unstructured).GetAnnotations))
+	}
+	if len(allowLabelsList) > 0 {
+		result = append(result, s.allowListFamilyGenerator("labels", "Kubernetes labels converted to Prometheus labels.", "label", allowLabelsList, (*unstructured.Unstructured).GetLabels))
+	}
+	return result
+}
+
+func (s customResourceMetrics) allowListFamilyGenerator(name, help, prefix string, allowList []string, kubeData func(*unstructured.Unstructured) map[string]string) generator.FamilyGenerator {
+	if s.MetricNamePrefix != "" {
+		name = s.MetricNamePrefix + "_" + name
+	}
+	return generator.FamilyGenerator{
+		Name: name,
+		Type: metric.Gauge,
+		Help: help,
+		GenerateFunc: func(obj interface{}) *metric.Family {
+			u := obj.(*unstructured.Unstructured)
+			keys, values := store.CreatePrometheusLabelKeysValues(prefix, kubeData(u), allowList)
+			if len(keys) == 0 {
+				return &metric.Family{}
+			}
+
+			baseKeys, baseValues := s.resourceLabelKeysValues(u)
+			keys = append(keys, baseKeys...)
+			values = append(values, baseValues...)
+
+			return &metric.Family{
+				Metrics: []*metric.Metric{
+					{
+						LabelKeys:   keys,
+						LabelValues: values,
+						Value:       1,
+					},
+				},
+			}
+		},
+	}
+}
+
 func (s customResourceMetrics) ExpectedType() interface{} {
 	u := unstructured.Unstructured{}
 	u.SetGroupVersionKind(s.GroupVersionKind)
@@ -21,6 +21,7 @@ import (
 	"reflect"
 	"testing"
 
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/utils/ptr"
 )
@@ -227,7 +228,7 @@ func TestNewCustomResourceMetrics(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			v, err := NewCustomResourceMetrics(tt.r)
 			if err != nil {
-				t.Errorf(err.Error())
+				t.Error(err)
 			}
 
 			// convert to JSON for easier nil comparison
@@ -246,3 +247,126 @@ func TestNewCustomResourceMetrics(t *testing.T) {
 		})
 	}
 }
+
+func TestAllowListMetricFamilyGenerators(t *testing.T) {
+	f, err := NewCustomResourceMetrics(Resource{
+		GroupVersionKind: GroupVersionKind{
+			Group:   "apps",
+			Version: "v1",
+			Kind:    "Deployment",
+		},
+		Labels: Labels{
+			LabelsFromPath: map[string][]string{
+				"name": {"metadata", "name"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	factory := f.(*customResourceMetrics)
+
+	if got := factory.AllowListMetricFamilyGenerators(nil, nil); len(got) != 0 {
+		t.Fatalf("expected no families for empty allow lists, got %d", len(got))
+	}
+
+	families := factory.AllowListMetricFamilyGenerators(nil, []string{"team"})
+	if len(families) != 1 || families[0].Name != "kube_customresource_labels" {
+		t.Fatalf("expected only the kube_customresource_labels family, got %v", families)
+	}
+
+	u := &unstructured.Unstructured{}
+	u.SetName("foo")
+	u.SetLabels(map[string]string{"team": "storage", "other": "value"})
+
+	got := string(families[0].Generate(u).ByteSlice())
+	want := `kube_customresource_labels{label_team="storage",customresource_group="apps",customresource_kind="Deployment",customresource_version="v1",name="foo"} 1
+`
+	if got != want {
+		t.Errorf("unexpected metrics, got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestAllowListMetricFamilyGeneratorsOverride(t *testing.T) {
+	gvk := GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
+	f, err := NewCustomResourceMetrics(Resource{
+		GroupVersionKind:     gvk,
+		AnnotationsAllowList: &[]string{},
+		LabelsAllowList:      &[]string{"app"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	factory := f.(*customResourceMetrics)
+
+	// The allow lists of the resource config override the given ones.
+	families := factory.AllowListMetricFamilyGenerators([]string{"*"}, []string{"team"})
+	if len(families) != 1 || families[0].Name != "kube_customresource_labels" {
+		t.Fatalf("expected only the kube_customresource_labels family, got %v", families)
+	}
+	u := &unstructured.Unstructured{}
+	u.SetLabels(map[string]string{"team": "storage", "app": "db"})
+	got := string(families[0].Generate(u).ByteSlice())
+	want := `kube_customresource_labels{label_app="db",customresource_group="apps",customresource_kind="Deployment",customresource_version="v1"} 1
+`
+	if got != want {
+		t.Errorf("unexpected metrics, got:\n%s\nwant:\n%s", got, want)
+	}
+
+	if _, err := NewCustomResourceMetrics(Resource{
+		GroupVersionKind: gvk,
+		LabelsAllowList:  &[]string{"team.*:renamed"},
+	}); err == nil {
+		t.Error("expected an error for an invalid allow list entry")
+	}
+}
+
+func TestGenerationMismatchFamilyGenerator(t *testing.T) {
+	f, err := NewCustomResourceMetrics(Resource{
+		GroupVersionKind: GroupVersionKind{Group: "myteam.io", Version: "v1", Kind: "Foo"},
+		Labels: Labels{
+			LabelsFromPath: map[string][]string{
+				"name": {"metadata", "name"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	families := f.MetricFamilyGenerators()
+	if len(families) != 1 || families[0].Name != "kube_customresource_generation_mismatch" {
+		t.Fatalf("expected only the kube_customresource_generation_mismatch family, got %v", families)
+	}
+
+	u := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	u.SetName("foo")
+	u.SetGeneration(5)
+	if got := families[0].Generate(u).ByteSlice(); len(got) != 0 {
+		t.Errorf("expected no metrics without status.observedGeneration, got %s", got)
+	}
+
+	if err := unstructured.SetNestedField(u.Object, int64(3), "status", "observedGeneration"); err != nil {
+		t.Fatal(err)
+	}
+	got := string(families[0].Generate(u).ByteSlice())
+	want := `kube_customresource_generation_mismatch{customresource_group="myteam.io",customresource_kind="Foo",customresource_version="v1",name="foo"} 2
+`
+	if got != want {
+		t.Errorf("unexpected metrics, got:\n%s\nwant:\n%s", got, want)
+	}
+
+	// A configured family with the same name takes precedence.
+	f, err = NewCustomResourceMetrics(Resource{
+		GroupVersionKind: GroupVersionKind{Group: "myteam.io", Version: "v1", Kind: "Foo"},
+		Metrics: []Generator{{
+			Name: "generation_mismatch",
+			Each: Metric{Type: MetricTypeGauge, Gauge: &MetricGauge{MetricMeta: MetricMeta{Path: []string{"status", "lag"}}}},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if families := f.MetricFamilyGenerators(); len(families) != 1 {
+		t.Errorf("expected only the configured family, got %d families", len(families))
+	}
+}
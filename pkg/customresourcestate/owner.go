@@ -0,0 +1,163 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package customresourcestate
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+// ownerSyncTimeout is the maximum time the creation of the client of a
+// resource waits for the cache of its owners to sync.
+const ownerSyncTimeout = time.Minute
+
+var (
+	// ownerCachesMtx protects ownerCaches.
+	ownerCachesMtx sync.Mutex
+	// ownerCaches holds the caches of the owner resources. They are shared by
+	// all resources with the same owner resource, and kept across rebuilds of
+	// the stores.
+	ownerCaches = map[schema.GroupVersionResource]*ownerCache{}
+)
+
+// ownerCache is a cache of the objects of an owner resource.
+type ownerCache struct {
+	gvr  schema.GroupVersionResource
+	once sync.Once
+	// mtx protects indexer, which is nil until the cache is started.
+	mtx     sync.RWMutex
+	indexer cache.Indexer
+}
+
+// ownerCacheFor returns the shared cache of the given owner resource.
+func ownerCacheFor(gvr schema.GroupVersionResource) *ownerCache {
+	ownerCachesMtx.Lock()
+	defer ownerCachesMtx.Unlock()
+	c, ok := ownerCaches[gvr]
+	if !ok {
+		c = &ownerCache{gvr: gvr}
+		ownerCaches[gvr] = c
+	}
+	return c
+}
+
+// start starts the informer of the cache with the given client once, and
+// waits for it to sync for up to ownerSyncTimeout. The informer runs for the
+// lifetime of the process.
+func (c *ownerCache) start(client dynamic.Interface) {
+	c.once.Do(func() {
+		informer := dynamicinformer.NewFilteredDynamicInformer(client, c.gvr, metav1.NamespaceAll, 0, cache.Indexers{}, nil).Informer()
+		c.mtx.Lock()
+		c.indexer = informer.GetIndexer()
+		c.mtx.Unlock()
+		go informer.Run(make(chan struct{}))
+
+		stop := make(chan struct{})
+		timer := time.AfterFunc(ownerSyncTimeout, func() { close(stop) })
+		defer timer.Stop()
+		if !cache.WaitForCacheSync(stop, informer.HasSynced) {
+			klog.InfoS("Cache of owners did not sync in time, labels from owners might be missing", "resource", c.gvr.String(), "timeout", ownerSyncTimeout)
+		}
+	})
+}
+
+// get returns the owner with the given namespace and name, if cached. Owners
+// of namespaced objects might be cluster-scoped.
+func (c *ownerCache) get(namespace, name string) (map[string]interface{}, bool) {
+	c.mtx.RLock()
+	indexer := c.indexer
+	c.mtx.RUnlock()
+	if indexer == nil {
+		return nil, false
+	}
+	keys := []string{name}
+	if namespace != "" {
+		keys = []string{namespace + "/" + name, name}
+	}
+	for _, key := range keys {
+		obj, ok, err := indexer.GetByKey(key)
+		if err != nil || !ok {
+			continue
+		}
+		if u, ok := obj.(*unstructured.Unstructured); ok {
+			return u.Object, true
+		}
+	}
+	return nil, false
+}
+
+// compiledOwner adds the labels taken from the owner of objects.
+type compiledOwner struct {
+	apiVersion    string
+	kind          string
+	labelFromPath map[string]valuePath
+	cache         *ownerCache
+}
+
+// compileOwner compiles the labels from the owner, or returns nil if none
+// are configured.
+func compileOwner(o *OwnerLabels) (*compiledOwner, error) {
+	if o == nil {
+		return nil, nil
+	}
+	if o.GroupVersionKind.Version == "" || o.GroupVersionKind.Kind == "" {
+		return nil, errors.New("labelsFromOwner: version and kind of the owner are required")
+	}
+	labelsFromPath, err := compilePaths(o.LabelsFromPath)
+	if err != nil {
+		return nil, fmt.Errorf("labelsFromOwner: labelsFromPath: %w", err)
+	}
+	gv := schema.GroupVersion{Group: o.GroupVersionKind.Group, Version: o.GroupVersionKind.Version}
+	return &compiledOwner{
+		apiVersion:    gv.String(),
+		kind:          o.GroupVersionKind.Kind,
+		labelFromPath: labelsFromPath,
+		cache:         ownerCacheFor(gv.WithResource(o.GetResourceName())),
+	}, nil
+}
+
+// addLabels adds the labels taken from the first cached owner of the object
+// with the configured kind to result. The owner reference must match the UID
+// of the owner, so that a recreated owner isn't confused with the previous
+// one.
+func (o *compiledOwner) addLabels(obj map[string]interface{}, result map[string]string) {
+	if o == nil {
+		return
+	}
+	u := unstructured.Unstructured{Object: obj}
+	for _, ref := range u.GetOwnerReferences() {
+		if ref.APIVersion != o.apiVersion || ref.Kind != o.kind {
+			continue
+		}
+		owner, ok := o.cache.get(u.GetNamespace(), ref.Name)
+		if !ok || (&unstructured.Unstructured{Object: owner}).GetUID() != ref.UID {
+			continue
+		}
+		addPathLabels(owner, o.labelFromPath, result)
+		return
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package customresourcestate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestCompiledOwner(t *testing.T) {
+	_, err := compileOwner(&OwnerLabels{GroupVersionKind: GroupVersionKind{Group: "argoproj.io", Kind: "Application"}})
+	assert.EqualError(t, err, "labelsFromOwner: version and kind of the owner are required")
+
+	owner, err := compileOwner(&OwnerLabels{
+		GroupVersionKind: GroupVersionKind{Group: "argoproj.io", Version: "v1alpha1", Kind: "Application"},
+		LabelsFromPath: map[string][]string{
+			"application": {"metadata", "name"},
+			"team":        {"metadata", "labels", "team"},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, schema.GroupVersionResource{Group: "argoproj.io", Version: "v1alpha1", Resource: "applications"}, owner.cache.gvr)
+
+	app := func(namespace, name, uid string) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "argoproj.io/v1alpha1",
+			"kind":       "Application",
+			"metadata": map[string]interface{}{
+				"name":   name,
+				"uid":    uid,
+				"labels": map[string]interface{}{"team": "platform"},
+			},
+		}}
+		u.SetNamespace(namespace)
+		return u
+	}
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		owner.cache.gvr: "ApplicationList",
+	}, app("argocd", "guestbook", "a"), app("", "cluster-app", "b"))
+	owner.cache.start(client)
+
+	child := func(namespace, ownerKind, ownerName, ownerUID string) map[string]interface{} {
+		return map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name":      "foo",
+				"namespace": namespace,
+				"labels":    map[string]interface{}{"team": "payments"},
+				"ownerReferences": []interface{}{
+					map[string]interface{}{"apiVersion": "argoproj.io/v1alpha1", "kind": ownerKind, "name": ownerName, "uid": ownerUID},
+				},
+			},
+		}
+	}
+	tests := []struct {
+		name string
+		obj  map[string]interface{}
+		want map[string]string
+	}{
+		{name: "namespaced owner", obj: child("argocd", "Application", "guestbook", "a"), want: map[string]string{"application": "guestbook", "team": "platform"}},
+		{name: "cluster-scoped owner", obj: child("argocd", "Application", "cluster-app", "b"), want: map[string]string{"application": "cluster-app", "team": "platform"}},
+		{name: "recreated owner", obj: child("argocd", "Application", "guestbook", "c"), want: map[string]string{}},
+		{name: "other kind", obj: child("argocd", "AppProject", "guestbook", "a"), want: map[string]string{}},
+		{name: "missing owner", obj: child("default", "Application", "guestbook", "a"), want: map[string]string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := map[string]string{}
+			owner.addLabels(tt.obj, got)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	// The labels of the object take precedence over the labels of the owner.
+	f := compiledFamily{
+		Labels:        map[string]string{"kind": "Foo"},
+		LabelFromPath: map[string]valuePath{"team": mustCompilePath(t, "metadata", "labels", "team")},
+		owner:         owner,
+	}
+	assert.Equal(t, map[string]string{"kind": "Foo", "application": "guestbook", "team": "payments"}, f.BaseLabels(child("argocd", "Application", "guestbook", "a")))
+}
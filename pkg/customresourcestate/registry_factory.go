@@ -54,6 +54,27 @@ func compile(resource Resource) ([]compiledFamily, error) {
 	return families, nil
 }
 
+// compileResourceLabels compiles the labels configured for the whole resource,
+// including the GVK labels, into a family without metrics.
+func compileResourceLabels(resource Resource) (*compiledFamily, error) {
+	labelsFromPath, err := compilePaths(resource.LabelsFromPath)
+	if err != nil {
+		return nil, fmt.Errorf("labelsFromPath: %w", err)
+	}
+	labels := map[string]string{
+		customResourceState + "_group":   resource.GroupVersionKind.Group,
+		customResourceState + "_version": resource.GroupVersionKind.Version,
+		customResourceState + "_kind":    resource.GroupVersionKind.Kind,
+	}
+	for k, v := range resource.CommonLabels {
+		labels[k] = v
+	}
+	return &compiledFamily{
+		Labels:        labels,
+		LabelFromPath: labelsFromPath,
+	}, nil
+}
+
 func compileCommon(c MetricMeta) (*compiledCommon, error) {
 	eachPath, err := compilePath(c.Path)
 	if err != nil {
@@ -166,11 +187,16 @@ func newCompiledMetric(m Metric) (compiledMetric, error) {
 		if err != nil {
 			return nil, fmt.Errorf("each.gauge.valueFrom: %w", err)
 		}
+		scale, err := compileValueScale(m.Gauge.Multiply, m.Gauge.Divide, m.Gauge.Offset)
+		if err != nil {
+			return nil, fmt.Errorf("each.gauge: %w", err)
+		}
 		return &compiledGauge{
 			compiledCommon: *cc,
 			ValueFrom:      valueFromPath,
 			NilIsZero:      m.Gauge.NilIsZero,
 			labelFromKey:   m.Gauge.LabelFromKey,
+			scale:          scale,
 		}, nil
 	case MetricTypeInfo:
 		if m.Info == nil {
@@ -214,6 +240,36 @@ type compiledGauge struct {
 	ValueFrom    valuePath
 	NilIsZero    bool
 	labelFromKey string
+	// scale is applied to the values, if not nil.
+	scale *valueScale
+}
+
+// valueScale is the arithmetic applied to the values of a gauge.
+type valueScale struct {
+	multiply, divide, offset float64
+}
+
+// compileValueScale returns the arithmetic of the given multiply, divide and
+// offset of a gauge, or nil if there is none.
+func compileValueScale(multiply, divide *float64, offset float64) (*valueScale, error) {
+	if multiply == nil && divide == nil && offset == 0 {
+		return nil, nil
+	}
+	s := &valueScale{multiply: 1, divide: 1, offset: offset}
+	if multiply != nil {
+		s.multiply = *multiply
+	}
+	if divide != nil {
+		if *divide == 0 {
+			return nil, errors.New("divide must not be 0")
+		}
+		s.divide = *divide
+	}
+	return s, nil
+}
+
+func (s *valueScale) apply(v float64) float64 {
+	return v*s.multiply/s.divide + s.offset
 }
 
 func (c *compiledGauge) Values(v interface{}) (result []eachValue, errs []error) {
@@ -300,6 +356,11 @@ func (c *compiledGauge) Values(v interface{}) (result []eachValue, errs []error)
 		addPathLabels(v, c.LabelFromPath(), value.Labels)
 		result = append(result, *value)
 	}
+	if c.scale != nil {
+		for i := range result {
+			result[i].Value = c.scale.apply(result[i].Value)
+		}
+	}
 	return
 }
 
@@ -501,6 +562,9 @@ type compiledFamily struct {
 	Labels        map[string]string
 	LabelFromPath map[string]valuePath
 	ErrorLogV     klog.Level
+	// owner adds the labels taken from the owner of the objects, if not nil.
+	// The labels of the object take precedence.
+	owner *compiledOwner
 }
 
 func (f compiledFamily) BaseLabels(obj map[string]interface{}) map[string]string {
@@ -508,6 +572,7 @@ func (f compiledFamily) BaseLabels(obj map[string]interface{}) map[string]string
 	for k, v := range f.Labels {
 		result[k] = v
 	}
+	f.owner.addLabels(obj, result)
 	addPathLabels(obj, f.LabelFromPath, result)
 	return result
 }
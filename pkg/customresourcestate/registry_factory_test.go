@@ -278,6 +278,24 @@ func Test_values(t *testing.T) {
 		}, wantResult: []eachValue{
 			newEachValue(t, 0.39, "name", "foo"),
 		}},
+		{name: "scaled", each: &compiledGauge{
+			compiledCommon: compiledCommon{
+				path: mustCompilePath(t, "spec", "replicas"),
+			},
+			scale: &valueScale{multiply: 2, divide: 4, offset: 1},
+		}, wantResult: []eachValue{
+			newEachValue(t, 1.5),
+		}},
+		{name: "scaled obj", each: &compiledGauge{
+			compiledCommon: compiledCommon{
+				path: mustCompilePath(t, "status", "active"),
+			},
+			labelFromKey: "type",
+			scale:        &valueScale{multiply: 1, divide: 2},
+		}, wantResult: []eachValue{
+			newEachValue(t, 0.5, "type", "type-a"),
+			newEachValue(t, 1.5, "type", "type-b"),
+		}},
 		{name: "boolean_string", each: &compiledGauge{
 			compiledCommon: compiledCommon{
 				path: mustCompilePath(t, "spec", "paused"),
@@ -368,6 +386,21 @@ func Test_values(t *testing.T) {
 	}
 }
 
+func Test_compileValueScale(t *testing.T) {
+	factor, zero := 1000.0, 0.0
+	if s, err := compileValueScale(nil, nil, 0); s != nil || err != nil {
+		t.Errorf("expected no scale without arithmetic, got %v, %v", s, err)
+	}
+	s, err := compileValueScale(&factor, nil, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 249.0, s.apply(0.25))
+	s, err = compileValueScale(nil, &factor, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.25, s.apply(250))
+	_, err = newCompiledMetric(Metric{Type: MetricTypeGauge, Gauge: &MetricGauge{Divide: &zero}})
+	assert.EqualError(t, err, "each.gauge: divide must not be 0")
+}
+
 func Test_compiledFamily_BaseLabels(t *testing.T) {
 	tests := []struct {
 		name   string
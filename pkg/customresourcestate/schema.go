@@ -0,0 +1,157 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package customresourcestate
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/klog/v2"
+)
+
+// Modes of the validation of the paths of the config against the schemas of
+// the CRDs.
+const (
+	// SchemaValidationDisabled disables the validation.
+	SchemaValidationDisabled = ""
+	// SchemaValidationWarn logs the paths which are unknown to the schema.
+	SchemaValidationWarn = "warn"
+	// SchemaValidationStrict doesn't generate the metrics of resources with
+	// paths which are unknown to the schema.
+	SchemaValidationStrict = "strict"
+)
+
+// SchemaValidator validates the paths of the config against the schemas of
+// the CRDs in FromConfig.
+type SchemaValidator struct {
+	// Mode is one of the SchemaValidation modes.
+	Mode string
+	// UnknownFields is set to the number of unknown paths of each resource by
+	// group, version and kind, if not nil.
+	UnknownFields *prometheus.GaugeVec
+}
+
+// reset removes the numbers of unknown paths of the previously validated
+// resources.
+func (v SchemaValidator) reset() {
+	if v.Mode != SchemaValidationDisabled && v.UnknownFields != nil {
+		v.UnknownFields.Reset()
+	}
+}
+
+// admit validates the resource against the OpenAPI v3 schema of the custom
+// resource and returns whether its metrics are generated.
+func (v SchemaValidator) admit(r Resource, openAPISchema map[string]interface{}) bool {
+	if v.Mode == SchemaValidationDisabled {
+		return true
+	}
+	unknown := UnknownFieldPaths(r, openAPISchema)
+	if v.UnknownFields != nil {
+		gvk := r.GroupVersionKind
+		v.UnknownFields.WithLabelValues(gvk.Group, gvk.Version, gvk.Kind).Set(float64(len(unknown)))
+	}
+	if len(unknown) == 0 {
+		return true
+	}
+	if v.Mode == SchemaValidationStrict {
+		klog.ErrorS(nil, "Custom Resource State config uses fields which are unknown to the schema of the resource, skipping its metrics", "gvk", r.GroupVersionKind, "paths", unknown)
+		return false
+	}
+	klog.InfoS("Custom Resource State config uses fields which are unknown to the schema of the resource", "gvk", r.GroupVersionKind, "paths", unknown)
+	return true
+}
+
+// UnknownFieldPaths returns the sorted paths of the resource config, with
+// their elements joined by dots, which don't exist in the OpenAPI v3 schema
+// of the custom resource. Fields below objects which preserve unknown fields
+// or have no properties in the schema are not validated.
+func UnknownFieldPaths(r Resource, openAPISchema map[string]interface{}) []string {
+	if openAPISchema == nil {
+		return nil
+	}
+	var paths [][]string
+	for _, p := range r.LabelsFromPath {
+		paths = append(paths, p)
+	}
+	for _, g := range r.Metrics {
+		paths = append(paths, g.paths()...)
+	}
+
+	unknown := map[string]struct{}{}
+	for _, p := range paths {
+		if !schemaHasPath(openAPISchema, p) {
+			unknown[strings.Join(p, ".")] = struct{}{}
+		}
+	}
+	result := make([]string, 0, len(unknown))
+	for p := range unknown {
+		result = append(result, p)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// schemaHasPath returns whether the path, as resolved by compilePath, might
+// exist in objects of the schema.
+func schemaHasPath(node map[string]interface{}, path []string) bool {
+	for i := 0; i < len(path); i++ {
+		if node == nil || node["x-kubernetes-preserve-unknown-fields"] == true {
+			return true
+		}
+		part := path[i]
+		if node["type"] == "array" {
+			items, _ := node["items"].(map[string]interface{})
+			if strings.HasPrefix(part, "[") && strings.HasSuffix(part, "]") {
+				// [key=value] looks up the element by a field of it.
+				key, _, _ := strings.Cut(part[1:len(part)-1], "=")
+				if !schemaHasPath(items, []string{key}) {
+					return false
+				}
+				node = items
+				continue
+			}
+			if _, err := strconv.Atoi(part); err == nil {
+				node = items
+				continue
+			}
+			// Paths continue for each element of lists, e.g. with the
+			// valueFrom path of a gauge.
+			node = items
+			i--
+			continue
+		}
+		properties, hasProperties := node["properties"].(map[string]interface{})
+		if !hasProperties {
+			// Maps, e.g. of labels, allow arbitrary keys.
+			if additional, ok := node["additionalProperties"].(map[string]interface{}); ok {
+				node = additional
+				continue
+			}
+			return true
+		}
+		// key=value only resolves the field if it has the value.
+		key, _, _ := strings.Cut(part, "=")
+		property, ok := properties[key].(map[string]interface{})
+		if !ok {
+			return false
+		}
+		node = property
+	}
+	return true
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package customresourcestate
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+var testSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"metadata": map[string]interface{}{"type": "object"},
+		"spec": map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"replicas": map[string]interface{}{"type": "integer"},
+				"selector": map[string]interface{}{
+					"type":                 "object",
+					"additionalProperties": map[string]interface{}{"type": "string"},
+				},
+				"template": map[string]interface{}{
+					"type":                                 "object",
+					"x-kubernetes-preserve-unknown-fields": true,
+				},
+			},
+		},
+		"status": map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"conditions": map[string]interface{}{
+					"type": "array",
+					"items": map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"type":   map[string]interface{}{"type": "string"},
+							"status": map[string]interface{}{"type": "string"},
+						},
+					},
+				},
+			},
+		},
+	},
+}
+
+func TestUnknownFieldPaths(t *testing.T) {
+	r := Resource{
+		Labels: Labels{
+			LabelsFromPath: map[string][]string{
+				"name":     {"metadata", "name"},
+				"selector": {"spec", "selector", "app"},
+				"typo":     {"spec", "replica"},
+			},
+		},
+		Metrics: []Generator{
+			{
+				Name: "replicas",
+				Each: Metric{
+					Type:  MetricTypeGauge,
+					Gauge: &MetricGauge{MetricMeta: MetricMeta{Path: []string{"spec", "replicas"}}},
+				},
+			},
+			{
+				Name: "ready",
+				Each: Metric{
+					Type: MetricTypeGauge,
+					Gauge: &MetricGauge{
+						MetricMeta: MetricMeta{
+							Path:           []string{"status", "conditions", "[type=Ready]"},
+							LabelsFromPath: map[string][]string{"reason": {"reason"}},
+						},
+						ValueFrom: []string{"status"},
+					},
+				},
+			},
+			{
+				Name: "conditions",
+				Each: Metric{
+					Type: MetricTypeGauge,
+					Gauge: &MetricGauge{
+						MetricMeta: MetricMeta{
+							Path:           []string{"status", "conditions"},
+							LabelsFromPath: map[string][]string{"type": {"type"}},
+						},
+						ValueFrom: []string{"statuss"},
+					},
+				},
+			},
+			{
+				Name: "template",
+				Each: Metric{
+					Type:  MetricTypeGauge,
+					Gauge: &MetricGauge{MetricMeta: MetricMeta{Path: []string{"spec", "template", "spec", "containers", "0"}}},
+				},
+			},
+			{
+				Name: "lookup",
+				Each: Metric{
+					Type:  MetricTypeGauge,
+					Gauge: &MetricGauge{MetricMeta: MetricMeta{Path: []string{"status", "conditions", "[kind=Ready]"}}},
+				},
+			},
+		},
+	}
+
+	want := []string{
+		"spec.replica",
+		"status.conditions.[kind=Ready]",
+		"status.conditions.[type=Ready].reason",
+		"status.conditions.statuss",
+	}
+	if got := UnknownFieldPaths(r, testSchema); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if got := UnknownFieldPaths(r, nil); len(got) != 0 {
+		t.Errorf("expected no unknown paths without schema, got %v", got)
+	}
+}
+
+func TestSchemaValidatorAdmit(t *testing.T) {
+	r := Resource{
+		GroupVersionKind: GroupVersionKind{Group: "myteam.io", Version: "v1", Kind: "Foo"},
+		Labels: Labels{
+			LabelsFromPath: map[string][]string{"typo": {"spec", "replica"}},
+		},
+	}
+	gauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "unknown_fields"}, []string{"group", "version", "kind"})
+
+	for mode, want := range map[string]bool{
+		SchemaValidationDisabled: true,
+		SchemaValidationWarn:     true,
+		SchemaValidationStrict:   false,
+	} {
+		v := SchemaValidator{Mode: mode, UnknownFields: gauge}
+		if got := v.admit(r, testSchema); got != want {
+			t.Errorf("mode %q: expected admit %t, got %t", mode, want, got)
+		}
+	}
+	if got := testutil.ToFloat64(gauge.WithLabelValues("myteam.io", "v1", "Foo")); got != 1 {
+		t.Errorf("expected 1 unknown path, got %v", got)
+	}
+}
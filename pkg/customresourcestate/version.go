@@ -0,0 +1,174 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package customresourcestate
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// ConfigAPIVersion is the current apiVersion of the Custom Resource State
+	// config, which configs of older apiVersions are migrated to.
+	ConfigAPIVersion = "kube-state-metrics.io/v1alpha1"
+	// ConfigKind is the kind of the Custom Resource State config.
+	ConfigKind = "CustomResourceStateMetrics"
+)
+
+// conversion migrates a config of one apiVersion to the next one.
+type conversion struct {
+	// to is the apiVersion the config is migrated to.
+	to string
+	// convert changes the fields of the config in place, e.g. by renaming
+	// keys. The apiVersion is set by migrateConfig.
+	convert func(config *yaml.Node) error
+}
+
+// conversions holds the conversion of each apiVersion preceding the current
+// one, where configs without apiVersion have the empty apiVersion. New
+// apiVersions add a conversion from the previous current apiVersion.
+var conversions = map[string]conversion{
+	// Configs without apiVersion have the fields of the first apiVersion.
+	"": {to: ConfigAPIVersion, convert: func(*yaml.Node) error { return nil }},
+}
+
+// configDecoder decodes Custom Resource State configs of all supported
+// apiVersions, migrating them to the current one.
+type configDecoder struct {
+	decoder *yaml.Decoder
+}
+
+// NewConfigDecoder returns a ConfigDecoder which decodes the YAML Custom
+// Resource State config read from r, migrating it from older apiVersions to
+// ConfigAPIVersion.
+func NewConfigDecoder(r io.Reader) ConfigDecoder {
+	return &configDecoder{decoder: yaml.NewDecoder(r)}
+}
+
+// Decode decodes the next config into v.
+func (d *configDecoder) Decode(v interface{}) error {
+	var doc yaml.Node
+	if err := d.decoder.Decode(&doc); err != nil {
+		return err
+	}
+	from, err := migrateConfig(&doc)
+	if err != nil {
+		return err
+	}
+	if from != ConfigAPIVersion {
+		klog.InfoS("Migrated Custom Resource State config, it can be migrated permanently with the migrate-config command", "apiVersion", from, "to", ConfigAPIVersion)
+	}
+	return doc.Decode(v)
+}
+
+// MigrateConfig writes the YAML Custom Resource State config read from r to
+// w, migrated to ConfigAPIVersion. The order of keys and comments are kept.
+// It returns the apiVersion of the config before the migration.
+func MigrateConfig(r io.Reader, w io.Writer) (string, error) {
+	var doc yaml.Node
+	if err := yaml.NewDecoder(r).Decode(&doc); err != nil {
+		return "", fmt.Errorf("failed to parse Custom Resource State config: %w", err)
+	}
+	from, err := migrateConfig(&doc)
+	if err != nil {
+		return "", err
+	}
+	e := yaml.NewEncoder(w)
+	e.SetIndent(2)
+	if err := e.Encode(&doc); err != nil {
+		return "", err
+	}
+	return from, e.Close()
+}
+
+// migrateConfig migrates the config document to ConfigAPIVersion in place
+// and returns its apiVersion before the migration.
+func migrateConfig(doc *yaml.Node) (string, error) {
+	config := doc
+	if config.Kind == yaml.DocumentNode && len(config.Content) > 0 {
+		config = config.Content[0]
+	}
+	if config.Kind != yaml.MappingNode {
+		return "", fmt.Errorf("Custom Resource State config must be a mapping")
+	}
+
+	var from string
+	if v := mappingValue(config, "apiVersion"); v != nil {
+		from = v.Value
+	}
+	for version := from; version != ConfigAPIVersion; {
+		c, ok := conversions[version]
+		if !ok {
+			return from, fmt.Errorf("unsupported apiVersion %q of the Custom Resource State config, supported are %s", version, supportedAPIVersions())
+		}
+		if err := c.convert(config); err != nil {
+			return from, fmt.Errorf("failed to migrate Custom Resource State config from apiVersion %q to %q: %w", version, c.to, err)
+		}
+		version = c.to
+	}
+	setMappingValue(config, "kind", ConfigKind)
+	setMappingValue(config, "apiVersion", ConfigAPIVersion)
+	return from, nil
+}
+
+// supportedAPIVersions returns the quoted apiVersions which configs can be
+// decoded from.
+func supportedAPIVersions() string {
+	versions := []string{fmt.Sprintf("%q", ConfigAPIVersion)}
+	for v := range conversions {
+		if v != "" {
+			versions = append(versions, fmt.Sprintf("%q", v))
+		}
+	}
+	sort.Strings(versions)
+	return strings.Join(versions, ", ")
+}
+
+// mappingValue returns the value of the key of the mapping node, or nil if it
+// has none.
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// setMappingValue sets the value of the key of the mapping node to the
+// string. Missing keys are prepended, so that e.g. apiVersion and kind lead
+// the config.
+func setMappingValue(mapping *yaml.Node, key, value string) {
+	if v := mappingValue(mapping, key); v != nil {
+		v.Kind, v.Tag, v.Value, v.Content = yaml.ScalarNode, "!!str", value, nil
+		return
+	}
+	keyNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}
+	if len(mapping.Content) > 0 {
+		// The comment at the top of the mapping stays there.
+		keyNode.HeadComment, mapping.Content[0].HeadComment = mapping.Content[0].HeadComment, ""
+	}
+	mapping.Content = append([]*yaml.Node{
+		keyNode,
+		{Kind: yaml.ScalarNode, Tag: "!!str", Value: value},
+	}, mapping.Content...)
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package customresourcestate
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const unversionedConfig = `# Metrics of foos.
+spec:
+  resources:
+    - groupVersionKind:
+        group: myteam.io
+        version: v1
+        kind: Foo
+      metrics:
+        - name: replicas
+          each:
+            type: Gauge
+            gauge:
+              path: [spec, replicas]
+`
+
+func TestMigrateConfig(t *testing.T) {
+	var out bytes.Buffer
+	from, err := MigrateConfig(strings.NewReader(unversionedConfig), &out)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if from != "" {
+		t.Errorf("expected the config to have no apiVersion, got %q", from)
+	}
+	want := "# Metrics of foos.\napiVersion: " + ConfigAPIVersion + "\nkind: " + ConfigKind + "\nspec:\n"
+	if !strings.HasPrefix(out.String(), want) {
+		t.Errorf("expected the migrated config to start with %q, got:\n%s", want, out.String())
+	}
+
+	// Migrated configs are kept as they are.
+	var again bytes.Buffer
+	from, err = MigrateConfig(bytes.NewReader(out.Bytes()), &again)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if from != ConfigAPIVersion || again.String() != out.String() {
+		t.Errorf("expected the migrated config to be unchanged, got apiVersion %q and:\n%s", from, again.String())
+	}
+
+	if _, err := MigrateConfig(strings.NewReader("apiVersion: kube-state-metrics.io/v9\nspec: {}\n"), &out); err == nil {
+		t.Errorf("expected an error for an unsupported apiVersion")
+	}
+}
+
+func TestConfigDecoder(t *testing.T) {
+	var m Metrics
+	if err := NewConfigDecoder(strings.NewReader(unversionedConfig)).Decode(&m); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m.APIVersion != ConfigAPIVersion || m.Kind != ConfigKind {
+		t.Errorf("expected the config to be migrated, got apiVersion %q and kind %q", m.APIVersion, m.Kind)
+	}
+	if len(m.Spec.Resources) != 1 || m.Spec.Resources[0].Metrics[0].Each.Gauge == nil {
+		t.Errorf("expected the resources of the config to be decoded, got %+v", m.Spec)
+	}
+}
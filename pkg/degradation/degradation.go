@@ -0,0 +1,143 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package degradation disables the heaviest metric families while the memory
+// usage of kube-state-metrics is close to its memory limit.
+package degradation
+
+import (
+	"context"
+	"runtime/metrics"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"k8s.io/klog/v2"
+
+	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
+)
+
+// degradedSuffixes are the suffixes of the names of the metric families
+// which are disabled while degraded. The labels and annotations families
+// usually are the largest ones, as they hold one label per allowed Kubernetes
+// label or annotation of each object.
+var degradedSuffixes = []string{"_labels", "_annotations"}
+
+// Filter filters the labels and annotations metric families once
+// degraded. It is used as a generator.FamilyGeneratorFilter, so stores built
+// after the degradation do not contain these families.
+type Filter struct {
+	degraded atomic.Bool
+}
+
+// Test tests if a given generator is not disabled by the degradation.
+func (f *Filter) Test(generator generator.FamilyGenerator) bool {
+	if !f.degraded.Load() {
+		return true
+	}
+	for _, suffix := range degradedSuffixes {
+		if strings.HasSuffix(generator.Name, suffix) {
+			return false
+		}
+	}
+	return true
+}
+
+// Degraded returns whether the filter was degraded.
+func (f *Filter) Degraded() bool {
+	return f.degraded.Load()
+}
+
+// Degrade degrades the filter. It returns whether the filter was not
+// degraded before.
+func (f *Filter) Degrade() bool {
+	return f.degraded.CompareAndSwap(false, true)
+}
+
+// Monitor degrades a Filter once the memory usage of the process exceeds a
+// share of its memory limit. The degradation is kept until the process is
+// restarted, as the memory usage drops after disabling the families.
+type Monitor struct {
+	filter    *Filter
+	limit     int64
+	threshold float64
+	onDegrade func()
+	usage     func() uint64
+	degraded  prometheus.Gauge
+}
+
+// NewMonitor returns a Monitor which degrades the filter and calls onDegrade
+// once the memory usage exceeds the threshold, a share of the memory limit in
+// bytes. The self-metric of the degradation state is registered with r.
+func NewMonitor(r prometheus.Registerer, filter *Filter, limit int64, threshold float64, onDegrade func()) *Monitor {
+	return &Monitor{
+		filter:    filter,
+		limit:     limit,
+		threshold: threshold,
+		onDegrade: onDegrade,
+		usage:     memoryUsage,
+		degraded: promauto.With(r).NewGauge(prometheus.GaugeOpts{
+			Name: "kube_state_metrics_degraded",
+			Help: "Whether the labels and annotations metric families were disabled, as the memory usage exceeded the degradation threshold.",
+		}),
+	}
+}
+
+// Run checks the memory usage in the given interval until the context is
+// done or the filter was degraded.
+func (m *Monitor) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for !m.check() {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// check degrades the filter if the memory usage exceeds the threshold. It
+// returns whether the filter is degraded.
+func (m *Monitor) check() bool {
+	if m.filter.Degraded() {
+		m.degraded.Set(1)
+		return true
+	}
+	usage := m.usage()
+	if float64(usage) < m.threshold*float64(m.limit) {
+		return false
+	}
+	if m.filter.Degrade() {
+		klog.InfoS("Disabling the labels and annotations metric families, as the memory usage exceeded the degradation threshold", "usageBytes", usage, "limitBytes", m.limit, "threshold", m.threshold)
+		m.degraded.Set(1)
+		m.onDegrade()
+	}
+	return true
+}
+
+// memoryUsage returns the memory mapped by the Go runtime which was not
+// released to the operating system.
+func memoryUsage() uint64 {
+	samples := []metrics.Sample{
+		{Name: "/memory/classes/total:bytes"},
+		{Name: "/memory/classes/heap/released:bytes"},
+	}
+	metrics.Read(samples)
+	return samples[0].Value.Uint64() - samples[1].Value.Uint64()
+}
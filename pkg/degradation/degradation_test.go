@@ -0,0 +1,83 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package degradation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
+)
+
+func TestFilter(t *testing.T) {
+	f := &Filter{}
+	labels := generator.FamilyGenerator{Name: "kube_pod_labels"}
+	annotations := generator.FamilyGenerator{Name: "kube_pod_annotations"}
+	info := generator.FamilyGenerator{Name: "kube_pod_info"}
+
+	if !f.Test(labels) || !f.Test(annotations) || !f.Test(info) {
+		t.Fatal("expected all families to pass before the degradation")
+	}
+	if !f.Degrade() {
+		t.Fatal("expected the first degradation to change the filter")
+	}
+	if f.Degrade() {
+		t.Error("expected the second degradation not to change the filter")
+	}
+	if f.Test(labels) || f.Test(annotations) {
+		t.Error("expected the labels and annotations families to be filtered once degraded")
+	}
+	if !f.Test(info) {
+		t.Error("expected other families to pass once degraded")
+	}
+}
+
+func TestMonitor(t *testing.T) {
+	f := &Filter{}
+	degraded := 0
+	m := NewMonitor(prometheus.NewRegistry(), f, 1000, 0.8, func() { degraded++ })
+	var usage uint64 = 500
+	m.usage = func() uint64 { return usage }
+
+	if m.check() {
+		t.Fatal("expected no degradation below the threshold")
+	}
+	if v := testutil.ToFloat64(m.degraded); v != 0 {
+		t.Errorf("expected degraded metric 0, got %v", v)
+	}
+
+	usage = 800
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	m.Run(ctx, time.Millisecond)
+	if !f.Degraded() || degraded != 1 {
+		t.Fatalf("expected one degradation above the threshold, got %d", degraded)
+	}
+	if v := testutil.ToFloat64(m.degraded); v != 1 {
+		t.Errorf("expected degraded metric 1, got %v", v)
+	}
+
+	// The degradation is kept once the memory usage dropped.
+	usage = 0
+	if !m.check() || degraded != 1 {
+		t.Error("expected the degradation to be kept")
+	}
+}
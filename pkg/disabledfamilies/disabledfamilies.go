@@ -0,0 +1,115 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disabledfamilies
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
+)
+
+// MetricFamilyFilter filters metric families which were disabled per resource
+// in the configuration.
+type MetricFamilyFilter struct {
+	// families maps the name of a disabled metric family to its resource.
+	families map[string]string
+}
+
+// Test tests if a given generator is not disabled.
+func (filter MetricFamilyFilter) Test(generator generator.FamilyGenerator) bool {
+	_, disabled := filter.families[generator.Name]
+	return !disabled
+}
+
+// Status returns the disabled metric families as a comma-separated string of
+// resource=family pairs.
+func (filter MetricFamilyFilter) Status() string {
+	asStrings := make([]string, 0, len(filter.families))
+	for family, resource := range filter.families {
+		asStrings = append(asStrings, resource+"="+family)
+	}
+	// sort the strings for the sake of ux such that the resulting status is consistent
+	sort.Strings(asStrings)
+	return strings.Join(asStrings, ", ")
+}
+
+// Resources returns the sorted resources which metric families are disabled
+// for.
+func (filter MetricFamilyFilter) Resources() []string {
+	seen := map[string]struct{}{}
+	resources := []string{}
+	for _, resource := range filter.families {
+		if _, ok := seen[resource]; ok {
+			continue
+		}
+		seen[resource] = struct{}{}
+		resources = append(resources, resource)
+	}
+	sort.Strings(resources)
+	return resources
+}
+
+// Validate returns an error if a disabled metric family is not served by its
+// resource, given the names of the metric families served by resources.
+// Families of resources missing from served are not validated.
+func (filter MetricFamilyFilter) Validate(served map[string][]string) error {
+	names := make([]string, 0, len(filter.families))
+	for name := range filter.families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		resource := filter.families[name]
+		families, ok := served[resource]
+		if !ok {
+			continue
+		}
+		if !slices.Contains(families, name) {
+			return fmt.Errorf("metric family %s is disabled for resource %s, which doesn't serve it", name, resource)
+		}
+	}
+	return nil
+}
+
+// Count returns the amount of metric families contained within the filter
+func (filter MetricFamilyFilter) Count() int {
+	return len(filter.families)
+}
+
+// NewMetricFamilyFilter creates new MetricFamilyFilter instances from a map of
+// resources to the exact names of the metric families to disable for them.
+func NewMetricFamilyFilter(disabled map[string][]string) (*MetricFamilyFilter, error) {
+	families := map[string]string{}
+	for resource, names := range disabled {
+		if resource == "" {
+			return nil, fmt.Errorf("disabled metric families %v have no resource", names)
+		}
+		for _, name := range names {
+			if name == "" {
+				return nil, fmt.Errorf("empty metric family name disabled for resource %s", resource)
+			}
+			if other, ok := families[name]; ok && other != resource {
+				return nil, fmt.Errorf("metric family %s is disabled for both resources %s and %s", name, other, resource)
+			}
+			families[name] = resource
+		}
+	}
+	return &MetricFamilyFilter{families}, nil
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disabledfamilies
+
+import (
+	"testing"
+
+	basemetrics "k8s.io/component-base/metrics"
+
+	"k8s.io/kube-state-metrics/v2/pkg/metric"
+	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
+)
+
+func TestFilter(t *testing.T) {
+	filter, err := NewMetricFamilyFilter(map[string][]string{
+		"pods":  {"kube_pod_container_status_last_terminated_reason"},
+		"nodes": {"kube_node_spec_taint"},
+	})
+	if err != nil {
+		t.Fatalf("did not expect NewMetricFamilyFilter to fail, the error is %v", err)
+	}
+
+	tests := []struct {
+		MetricFamily string
+		Want         bool
+	}{
+		{"kube_pod_container_status_last_terminated_reason", false},
+		{"kube_pod_container_status_last_terminated_exitcode", true},
+		{"kube_node_spec_taint", false},
+		{"kube_node_info", true},
+	}
+
+	for _, test := range tests {
+		familyGenerator := *generator.NewFamilyGeneratorWithStability(
+			test.MetricFamily,
+			"",
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			func(_ interface{}) *metric.Family {
+				return nil
+			},
+		)
+
+		if result := filter.Test(familyGenerator); result != test.Want {
+			t.Errorf("unexpected filter result for %s, got: %v, want: %v", test.MetricFamily, result, test.Want)
+		}
+	}
+
+	if got, want := filter.Status(), "nodes=kube_node_spec_taint, pods=kube_pod_container_status_last_terminated_reason"; got != want {
+		t.Errorf("unexpected status, got: %q, want: %q", got, want)
+	}
+}
+
+func TestNewMetricFamilyFilter(t *testing.T) {
+	t.Run("should fail if a metric family is disabled for several resources", func(t *testing.T) {
+		_, err := NewMetricFamilyFilter(map[string][]string{
+			"pods":  {"kube_pod_info"},
+			"nodes": {"kube_pod_info"},
+		})
+		if err == nil {
+			t.Errorf("expected NewMetricFamilyFilter to fail for a metric family disabled for several resources")
+		}
+	})
+
+	t.Run("should fail if an empty metric family name is passed in", func(t *testing.T) {
+		_, err := NewMetricFamilyFilter(map[string][]string{"pods": {""}})
+		if err == nil {
+			t.Errorf("expected NewMetricFamilyFilter to fail for an empty metric family name")
+		}
+	})
+}
+
+func TestValidate(t *testing.T) {
+	served := map[string][]string{
+		"pods":  {"kube_pod_info", "kube_pod_container_status_last_terminated_reason"},
+		"nodes": {"kube_node_info", "kube_node_spec_taint"},
+	}
+
+	t.Run("should succeed if the metric families are served by their resources", func(t *testing.T) {
+		filter, err := NewMetricFamilyFilter(map[string][]string{
+			"pods":       {"kube_pod_container_status_last_terminated_reason"},
+			"nodes":      {"kube_node_spec_taint"},
+			"foos.bar.x": {"kube_customresource_foo"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := filter.Validate(served); err != nil {
+			t.Errorf("did not expect Validate to fail, the error is %v", err)
+		}
+	})
+
+	t.Run("should fail if a metric family is not served by its resource", func(t *testing.T) {
+		filter, err := NewMetricFamilyFilter(map[string][]string{
+			"nodes": {"kube_pod_info"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := filter.Validate(served); err == nil {
+			t.Errorf("expected Validate to fail for a metric family of another resource")
+		}
+	})
+}
@@ -0,0 +1,289 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package explain detects the fields of Kubernetes objects which metric
+// families are generated from.
+package explain
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"k8s.io/kube-state-metrics/v2/pkg/lint"
+	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
+)
+
+const (
+	// maxDepth limits the traversal of recursive types.
+	maxDepth = 12
+	// maxPasses limits the passes over the fields of an object.
+	maxPasses = 3
+)
+
+// stringValues are tried for string fields until the generated metrics
+// change. Besides an arbitrary value, they contain common values of enum
+// fields, which metrics are often generated from by comparison.
+var stringValues = []string{"explain", "True", "False", "Running", "Pending", "Succeeded", "Failed", "Bound", "Ready", "Active"}
+
+var (
+	timeType      = reflect.TypeOf(metav1.Time{})
+	microTimeType = reflect.TypeOf(metav1.MicroTime{})
+	durationType  = reflect.TypeOf(metav1.Duration{})
+	quantityType  = reflect.TypeOf(resource.Quantity{})
+)
+
+// Fields returns the sorted paths of the fields of objects of the type of
+// expectedType which the metrics of the family are generated from. Fields are
+// detected by changing each field of a synthetic object, with one element in
+// each list, and comparing the generated metrics. Fields which only affect
+// the metrics for specific values or in combination with other fields might
+// be missing.
+func Fields(family generator.FamilyGenerator, expectedType interface{}) []string {
+	obj := lint.SyntheticObject(expectedType)
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	populate(v.Elem(), 0)
+
+	// Changes which affect the metrics are kept, so that fields which only
+	// affect the metrics in combination with them are found in the next pass.
+	baseline := render(family, obj)
+	fields := map[string]struct{}{}
+	for pass := 0; pass < maxPasses; pass++ {
+		found := len(fields)
+		visitLeaves(v.Elem(), "", 0, func(path string, perturb func(try func() bool)) {
+			if _, ok := fields[path]; ok {
+				return
+			}
+			perturb(func() bool {
+				out := render(family, obj)
+				if out == baseline {
+					return false
+				}
+				baseline = out
+				fields[path] = struct{}{}
+				return true
+			})
+		})
+		if len(fields) == found {
+			break
+		}
+	}
+
+	result := make([]string, 0, len(fields))
+	for f := range fields {
+		result = append(result, f)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// render returns the metrics generated by the family for obj in a
+// comparable form.
+func render(family generator.FamilyGenerator, obj interface{}) (out string) {
+	defer func() {
+		if r := recover(); r != nil {
+			out = fmt.Sprintf("panic: %v", r)
+		}
+	}()
+	f := family.Generate(obj)
+	lines := make([]string, 0, len(f.Metrics))
+	for _, m := range f.Metrics {
+		var s strings.Builder
+		m.Write(&s)
+		lines = append(lines, s.String())
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "")
+}
+
+// populate allocates the nil pointers of the struct v and its nested structs,
+// and adds one element to each empty slice.
+func populate(v reflect.Value, depth int) {
+	if depth > maxDepth {
+		return
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() && v.CanSet() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		if !v.IsNil() {
+			populate(v.Elem(), depth+1)
+		}
+	case reflect.Slice:
+		if v.Len() == 0 && v.CanSet() {
+			v.Set(reflect.MakeSlice(v.Type(), 1, 1))
+		}
+		for i := 0; i < v.Len(); i++ {
+			populate(v.Index(i), depth+1)
+		}
+	case reflect.Struct:
+		if isLeafStruct(v.Type()) {
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if v.Field(i).CanSet() {
+				populate(v.Field(i), depth+1)
+			}
+		}
+	}
+}
+
+// visitLeaves calls visit with the path of each settable leaf field of v and
+// a function which sets the field to different values and calls try after
+// each change until it returns true. The field is restored unless try
+// returned true.
+func visitLeaves(v reflect.Value, path string, depth int, visit func(path string, perturb func(try func() bool))) {
+	if depth > maxDepth || !v.CanSet() {
+		return
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			visitLeaves(v.Elem(), path, depth+1, visit)
+		}
+		return
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			break
+		}
+		for i := 0; i < v.Len(); i++ {
+			visitLeaves(v.Index(i), path, depth+1, visit)
+		}
+		return
+	case reflect.Struct:
+		if isLeafStruct(v.Type()) {
+			break
+		}
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			name, ok := jsonName(field)
+			if !ok {
+				continue
+			}
+			fieldPath := path
+			if name != "" {
+				fieldPath = strings.TrimPrefix(path+"."+name, ".")
+			}
+			visitLeaves(v.Field(i), fieldPath, depth+1, visit)
+		}
+		return
+	case reflect.Interface, reflect.Func, reflect.Chan:
+		return
+	}
+
+	values := perturbations(v)
+	if len(values) == 0 {
+		return
+	}
+	visit(path, func(try func() bool) {
+		old := reflect.New(v.Type()).Elem()
+		old.Set(v)
+		for _, value := range values {
+			v.Set(value)
+			if try() {
+				return
+			}
+		}
+		v.Set(old)
+	})
+}
+
+// perturbations returns values different from the current value of the leaf
+// field v.
+func perturbations(v reflect.Value) []reflect.Value {
+	t := v.Type()
+	switch t {
+	case timeType:
+		return []reflect.Value{reflect.ValueOf(metav1.Unix(1000000000, 0))}
+	case microTimeType:
+		return []reflect.Value{reflect.ValueOf(metav1.NewMicroTime(time.Unix(1000000000, 0)))}
+	case durationType:
+		return []reflect.Value{reflect.ValueOf(metav1.Duration{Duration: time.Minute})}
+	case quantityType:
+		return []reflect.Value{reflect.ValueOf(resource.MustParse("3"))}
+	}
+
+	var values []reflect.Value
+	switch t.Kind() {
+	case reflect.String:
+		for _, s := range stringValues {
+			if s != v.String() {
+				values = append(values, reflect.ValueOf(s).Convert(t))
+			}
+		}
+	case reflect.Bool:
+		values = append(values, reflect.ValueOf(!v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		values = append(values, reflect.ValueOf(v.Int()+3).Convert(t))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		values = append(values, reflect.ValueOf(v.Uint()+3).Convert(t))
+	case reflect.Float32, reflect.Float64:
+		values = append(values, reflect.ValueOf(v.Float()+3).Convert(t))
+	case reflect.Slice:
+		values = append(values, reflect.ValueOf([]byte("explain")).Convert(t))
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil
+		}
+		for _, key := range []string{"explain", "cpu", "memory"} {
+			m := reflect.MakeMap(t)
+			value := reflect.New(t.Elem()).Elem()
+			if p := perturbations(value); len(p) > 0 {
+				value = p[0]
+			}
+			m.SetMapIndex(reflect.ValueOf(key).Convert(t.Key()), value)
+			values = append(values, m)
+		}
+	}
+	for i := range values {
+		values[i] = values[i].Convert(t)
+	}
+	return values
+}
+
+// isLeafStruct returns whether fields of type t are changed as a whole.
+func isLeafStruct(t reflect.Type) bool {
+	return t == timeType || t == microTimeType || t == durationType || t == quantityType
+}
+
+// jsonName returns the name of the field in the JSON encoding of its struct,
+// which is empty for inlined fields, and whether the field is encoded at all.
+func jsonName(field reflect.StructField) (string, bool) {
+	if !field.IsExported() {
+		return "", false
+	}
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	name, opts, _ := strings.Cut(tag, ",")
+	if name == "" && (field.Anonymous || opts == "inline") {
+		return "", true
+	}
+	if name == "" {
+		name = field.Name
+	}
+	return name, true
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package explain
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+
+	"k8s.io/kube-state-metrics/v2/pkg/metric"
+	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
+)
+
+func TestFields(t *testing.T) {
+	family := generator.FamilyGenerator{
+		Name: "kube_pod_container_status_ready",
+		Type: metric.Gauge,
+		Help: "Describes whether the containers readiness check succeeded.",
+		GenerateFunc: func(obj interface{}) *metric.Family {
+			p := obj.(*v1.Pod)
+			ms := []*metric.Metric{}
+			// Metrics are only generated for running pods, so the name of
+			// the pod only affects them in combination with the phase.
+			if p.Status.Phase != v1.PodRunning {
+				return &metric.Family{Metrics: ms}
+			}
+			for _, cs := range p.Status.ContainerStatuses {
+				ms = append(ms, &metric.Metric{
+					LabelKeys:   []string{"pod", "container"},
+					LabelValues: []string{p.Name, cs.Name},
+					Value:       boolFloat64(cs.Ready),
+				})
+			}
+			return &metric.Family{Metrics: ms}
+		},
+	}
+
+	want := []string{"metadata.name", "status.containerStatuses.name", "status.containerStatuses.ready", "status.phase"}
+	if got := Fields(family, &v1.Pod{}); !reflect.DeepEqual(got, want) {
+		t.Errorf("want fields %v, got %v", want, got)
+	}
+}
+
+func boolFloat64(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,222 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package federate serves the metrics of all shards of kube-state-metrics on
+// a single endpoint, by fanning out to the shards behind a headless Service.
+package federate
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// defaultTimeout is the timeout of the requests to the shards, unless the
+// scraper sends its scrape timeout.
+const defaultTimeout = 30 * time.Second
+
+// Handler serves the merged metrics of all shards whose addresses the host of
+// the headless Service resolves to.
+type Handler struct {
+	host string
+	port string
+	path string
+
+	client *http.Client
+	lookup func(ctx context.Context, host string) ([]string, error)
+}
+
+// New returns a Handler which fans out to the given path on the port of all
+// addresses the host of service resolves to. service is the host and port of
+// a headless Service, e.g.
+// kube-state-metrics.kube-system.svc.cluster.local:8080.
+func New(service, path string) (*Handler, error) {
+	host, port, err := net.SplitHostPort(service)
+	if err != nil {
+		return nil, fmt.Errorf("invalid federation service %q: %w", service, err)
+	}
+	return &Handler{
+		host:   host,
+		port:   port,
+		path:   path,
+		client: &http.Client{},
+		lookup: net.DefaultResolver.LookupHost,
+	}, nil
+}
+
+// ServeHTTP fetches the metrics of all shards and writes them merged by
+// metric family. If the shards cannot be resolved or one of them fails, it
+// responds with 502 Bad Gateway rather than with partial metrics.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), scrapeTimeout(r))
+	defer cancel()
+
+	addresses, err := h.lookup(ctx, h.host)
+	if err != nil {
+		klog.ErrorS(err, "Failed to resolve shards", "host", h.host)
+		http.Error(w, fmt.Sprintf("failed to resolve shards: %v", err), http.StatusBadGateway)
+		return
+	}
+	sort.Strings(addresses)
+
+	bodies := make([][]byte, len(addresses))
+	errs := make([]error, len(addresses))
+	var wg sync.WaitGroup
+	for i, address := range addresses {
+		wg.Add(1)
+		go func(i int, address string) {
+			defer wg.Done()
+			bodies[i], errs[i] = h.fetch(ctx, address)
+		}(i, address)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			klog.ErrorS(err, "Failed to fetch metrics of shard", "address", addresses[i])
+			http.Error(w, fmt.Sprintf("failed to fetch metrics of shard %s: %v", addresses[i], err), http.StatusBadGateway)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Header().Set("X-Kube-State-Metrics-Federated-Shards", strconv.Itoa(len(addresses)))
+	if err := merge(w, bodies); err != nil {
+		klog.ErrorS(err, "Failed to write federated metrics")
+	}
+}
+
+// fetch returns the metrics of the shard with the given address in the
+// Prometheus text format.
+func (h *Handler) fetch(ctx context.Context, address string) ([]byte, error) {
+	url := "http://" + net.JoinHostPort(address, h.port) + h.path
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/plain")
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// scrapeTimeout returns the scrape timeout Prometheus sends with its
+// requests, or defaultTimeout.
+func scrapeTimeout(r *http.Request) time.Duration {
+	if v := r.Header.Get("X-Prometheus-Scrape-Timeout-Seconds"); v != "" {
+		if seconds, err := strconv.ParseFloat(v, 64); err == nil && seconds > 0 {
+			return time.Duration(seconds * float64(time.Second))
+		}
+	}
+	return defaultTimeout
+}
+
+// family holds the header and the metric lines of a metric family.
+type family struct {
+	header  []string
+	metrics []string
+}
+
+// merge writes the metrics of the given bodies in the Prometheus text format
+// to w, grouped by metric family, so that the header of each family is only
+// written once. Families are written in the order of their first occurrence.
+func merge(w io.Writer, bodies [][]byte) error {
+	var order []string
+	families := map[string]*family{}
+	for _, body := range bodies {
+		var current *family
+		scanner := bufio.NewScanner(bytes.NewReader(body))
+		scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if line == "" {
+				continue
+			}
+			if name, ok := headerName(line); ok {
+				f, ok := families[name]
+				if !ok {
+					f = &family{}
+					families[name] = f
+					order = append(order, name)
+				}
+				// The headers of a family are identical on all shards.
+				if !containsLine(f.header, line) {
+					f.header = append(f.header, line)
+				}
+				current = f
+				continue
+			}
+			if strings.HasPrefix(line, "#") || current == nil {
+				continue
+			}
+			current.metrics = append(current.metrics, line)
+		}
+		if err := scanner.Err(); err != nil {
+			return fmt.Errorf("failed to read metrics: %w", err)
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, name := range order {
+		f := families[name]
+		for _, line := range f.header {
+			bw.WriteString(line)
+			bw.WriteByte('\n')
+		}
+		for _, line := range f.metrics {
+			bw.WriteString(line)
+			bw.WriteByte('\n')
+		}
+	}
+	return bw.Flush()
+}
+
+// headerName returns the name of the metric family of a HELP or TYPE line.
+func headerName(line string) (string, bool) {
+	for _, prefix := range []string{"# HELP ", "# TYPE "} {
+		if strings.HasPrefix(line, prefix) {
+			name, _, _ := strings.Cut(strings.TrimPrefix(line, prefix), " ")
+			return name, true
+		}
+	}
+	return "", false
+}
+
+func containsLine(lines []string, line string) bool {
+	for _, l := range lines {
+		if l == line {
+			return true
+		}
+	}
+	return false
+}
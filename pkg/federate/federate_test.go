@@ -0,0 +1,109 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package federate
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func newTestHandler(t *testing.T, shards map[string]http.HandlerFunc) *Handler {
+	h, err := New("kube-state-metrics.kube-system.svc:8080", "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.lookup = func(context.Context, string) ([]string, error) {
+		addresses := make([]string, 0, len(shards))
+		for address := range shards {
+			addresses = append(addresses, address)
+		}
+		return addresses, nil
+	}
+	h.client = &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		w := httptest.NewRecorder()
+		shards[r.URL.Hostname()](w, r)
+		return w.Result(), nil
+	})}
+	return h
+}
+
+func TestHandler(t *testing.T) {
+	h := newTestHandler(t, map[string]http.HandlerFunc{
+		"10.0.0.1": func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`# HELP kube_pod_info Information about pod.
+# TYPE kube_pod_info gauge
+kube_pod_info{pod="a"} 1
+# HELP kube_node_info Information about a cluster node.
+# TYPE kube_node_info gauge
+`))
+		},
+		"10.0.0.2": func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`# HELP kube_pod_info Information about pod.
+# TYPE kube_pod_info gauge
+kube_pod_info{pod="b"} 1
+# HELP kube_node_info Information about a cluster node.
+# TYPE kube_node_info gauge
+kube_node_info{node="n"} 1
+`))
+		},
+	})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/federate", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	want := `# HELP kube_pod_info Information about pod.
+# TYPE kube_pod_info gauge
+kube_pod_info{pod="a"} 1
+kube_pod_info{pod="b"} 1
+# HELP kube_node_info Information about a cluster node.
+# TYPE kube_node_info gauge
+kube_node_info{node="n"} 1
+`
+	if got := w.Body.String(); got != want {
+		t.Errorf("expected merged metrics:\n%s\ngot:\n%s", want, got)
+	}
+	if got := w.Header().Get("X-Kube-State-Metrics-Federated-Shards"); got != "2" {
+		t.Errorf("expected 2 federated shards, got %q", got)
+	}
+}
+
+func TestHandlerFailingShard(t *testing.T) {
+	h := newTestHandler(t, map[string]http.HandlerFunc{
+		"10.0.0.1": func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("kube_pod_info{pod=\"a\"} 1\n"))
+		},
+		"10.0.0.2": func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		},
+	})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/federate", nil))
+	if w.Code != http.StatusBadGateway {
+		t.Errorf("expected status 502 if a shard fails, got %d", w.Code)
+	}
+}
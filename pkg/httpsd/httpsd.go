@@ -0,0 +1,265 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package httpsd serves the pods watched by kube-state-metrics as targets of
+// the HTTP service discovery of Prometheus, so that Prometheus can discover
+// them without watching pods itself.
+package httpsd
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+const metaLabelPrefix = "__meta_kubernetes_"
+
+var invalidLabelCharRE = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// TargetGroup is a group of targets of the Prometheus HTTP service
+// discovery.
+type TargetGroup struct {
+	Targets []string          `json:"targets"`
+	Labels  map[string]string `json:"labels,omitempty"`
+}
+
+// PodTargets tracks the pods which are annotated with the annotation set to
+// "true" and serves them as targets of the Prometheus HTTP service discovery.
+// Like the pod role of the Kubernetes service discovery of Prometheus, each
+// TCP port of the containers of a pod is a target, with the same meta labels,
+// or the pod IP if its containers declare no ports.
+type PodTargets struct {
+	annotation string
+
+	mutex sync.Mutex
+	// stores holds the tracked stores.
+	stores map[*podStore]struct{}
+}
+
+// NewPodTargets returns PodTargets tracking the pods with the annotation.
+func NewPodTargets(annotation string) *PodTargets {
+	return &PodTargets{
+		annotation: annotation,
+		stores:     map[*podStore]struct{}{},
+	}
+}
+
+// Track returns a store wrapping the given pod store whose annotated pods are
+// served as targets until the context is done.
+func (t *PodTargets) Track(ctx context.Context, store cache.Store) cache.Store {
+	s := &podStore{
+		Store:      store,
+		annotation: t.annotation,
+		groups:     map[types.UID][]TargetGroup{},
+	}
+	t.mutex.Lock()
+	t.stores[s] = struct{}{}
+	t.mutex.Unlock()
+	go func() {
+		<-ctx.Done()
+		t.mutex.Lock()
+		delete(t.stores, s)
+		t.mutex.Unlock()
+	}()
+	return s
+}
+
+// TargetGroups returns the target groups of all tracked pods, sorted by
+// namespace, pod, container and port.
+func (t *PodTargets) TargetGroups() []TargetGroup {
+	t.mutex.Lock()
+	groups := []TargetGroup{}
+	for s := range t.stores {
+		groups = append(groups, s.targetGroups()...)
+	}
+	t.mutex.Unlock()
+
+	sort.Slice(groups, func(i, j int) bool {
+		return groupKey(groups[i]) < groupKey(groups[j])
+	})
+	return groups
+}
+
+// ServeHTTP implements the http.Handler interface, serving the target groups
+// as JSON.
+func (t *PodTargets) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(t.TargetGroups()); err != nil {
+		klog.ErrorS(err, "Failed to write pod targets")
+	}
+}
+
+// groupKey returns the key target groups are sorted by.
+func groupKey(g TargetGroup) string {
+	return strings.Join([]string{
+		g.Labels[metaLabelPrefix+"namespace"],
+		g.Labels[metaLabelPrefix+"pod_name"],
+		g.Labels[metaLabelPrefix+"pod_container_name"],
+		strings.Join(g.Targets, ","),
+	}, "/")
+}
+
+// podStore wraps a cache.Store and keeps the target groups of the annotated
+// pods passed to it.
+type podStore struct {
+	cache.Store
+	annotation string
+
+	mutex sync.Mutex
+	// groups holds the target groups of each annotated pod.
+	groups map[types.UID][]TargetGroup
+}
+
+// Add implements the Add method of the store interface.
+func (s *podStore) Add(obj interface{}) error {
+	s.update(obj)
+	return s.Store.Add(obj)
+}
+
+// Update implements the Update method of the store interface.
+func (s *podStore) Update(obj interface{}) error {
+	s.update(obj)
+	return s.Store.Update(obj)
+}
+
+// Delete implements the Delete method of the store interface.
+func (s *podStore) Delete(obj interface{}) error {
+	pod := podOf(obj)
+	if pod != nil {
+		s.mutex.Lock()
+		delete(s.groups, pod.UID)
+		s.mutex.Unlock()
+	}
+	return s.Store.Delete(obj)
+}
+
+// Replace implements the Replace method of the store interface.
+func (s *podStore) Replace(list []interface{}, resourceVersion string) error {
+	groups := map[types.UID][]TargetGroup{}
+	for _, obj := range list {
+		if pod, ok := obj.(*v1.Pod); ok {
+			if g := s.podTargetGroups(pod); len(g) > 0 {
+				groups[pod.UID] = g
+			}
+		}
+	}
+	s.mutex.Lock()
+	s.groups = groups
+	s.mutex.Unlock()
+	return s.Store.Replace(list, resourceVersion)
+}
+
+// update replaces the target groups of the pod.
+func (s *podStore) update(obj interface{}) {
+	pod, ok := obj.(*v1.Pod)
+	if !ok {
+		return
+	}
+	groups := s.podTargetGroups(pod)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if len(groups) == 0 {
+		delete(s.groups, pod.UID)
+		return
+	}
+	s.groups[pod.UID] = groups
+}
+
+// targetGroups returns the target groups of all pods of the store.
+func (s *podStore) targetGroups() []TargetGroup {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var groups []TargetGroup
+	for _, g := range s.groups {
+		groups = append(groups, g...)
+	}
+	return groups
+}
+
+// podTargetGroups returns the target groups of the pod, which are empty
+// unless the pod is annotated, has an IP and didn't finish.
+func (s *podStore) podTargetGroups(pod *v1.Pod) []TargetGroup {
+	if pod.Annotations[s.annotation] != "true" || pod.Status.PodIP == "" ||
+		pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+		return nil
+	}
+
+	labels := map[string]string{
+		metaLabelPrefix + "namespace":     pod.Namespace,
+		metaLabelPrefix + "pod_name":      pod.Name,
+		metaLabelPrefix + "pod_ip":        pod.Status.PodIP,
+		metaLabelPrefix + "pod_uid":       string(pod.UID),
+		metaLabelPrefix + "pod_node_name": pod.Spec.NodeName,
+		metaLabelPrefix + "pod_phase":     string(pod.Status.Phase),
+	}
+	for k, v := range pod.Labels {
+		labels[metaLabelPrefix+"pod_label_"+sanitizeLabelName(k)] = v
+	}
+	for k, v := range pod.Annotations {
+		labels[metaLabelPrefix+"pod_annotation_"+sanitizeLabelName(k)] = v
+	}
+
+	var groups []TargetGroup
+	for _, c := range pod.Spec.Containers {
+		for _, p := range c.Ports {
+			if p.Protocol != "" && p.Protocol != v1.ProtocolTCP {
+				continue
+			}
+			groupLabels := make(map[string]string, len(labels)+3)
+			for k, v := range labels {
+				groupLabels[k] = v
+			}
+			groupLabels[metaLabelPrefix+"pod_container_name"] = c.Name
+			groupLabels[metaLabelPrefix+"pod_container_port_name"] = p.Name
+			groupLabels[metaLabelPrefix+"pod_container_port_number"] = strconv.Itoa(int(p.ContainerPort))
+			groups = append(groups, TargetGroup{
+				Targets: []string{net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(int(p.ContainerPort)))},
+				Labels:  groupLabels,
+			})
+		}
+	}
+	if len(groups) == 0 {
+		groups = append(groups, TargetGroup{Targets: []string{pod.Status.PodIP}, Labels: labels})
+	}
+	return groups
+}
+
+// podOf returns the pod of the object passed to Delete, which might be a
+// tombstone.
+func podOf(obj interface{}) *v1.Pod {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	pod, _ := obj.(*v1.Pod)
+	return pod
+}
+
+// sanitizeLabelName replaces the characters which are invalid in label names
+// with underscores.
+func sanitizeLabelName(name string) string {
+	return invalidLabelCharRE.ReplaceAllString(name, "_")
+}
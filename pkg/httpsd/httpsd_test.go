@@ -0,0 +1,97 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpsd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestPodTargets(t *testing.T) {
+	newPod := func(name, ip string, annotations map[string]string, ports ...v1.ContainerPort) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, UID: types.UID("uid-" + name), Annotations: annotations, Labels: map[string]string{"app.kubernetes.io/name": name}},
+			Spec:       v1.PodSpec{NodeName: "node", Containers: []v1.Container{{Name: "app", Ports: ports}}},
+			Status:     v1.PodStatus{Phase: v1.PodRunning, PodIP: ip},
+		}
+	}
+	scrape := map[string]string{"prometheus.io/scrape": "true"}
+	podLabels := func(name, ip string) map[string]string {
+		return map[string]string{
+			"__meta_kubernetes_namespace":                           "default",
+			"__meta_kubernetes_pod_name":                            name,
+			"__meta_kubernetes_pod_ip":                              ip,
+			"__meta_kubernetes_pod_uid":                             "uid-" + name,
+			"__meta_kubernetes_pod_node_name":                       "node",
+			"__meta_kubernetes_pod_phase":                           "Running",
+			"__meta_kubernetes_pod_label_app_kubernetes_io_name":    name,
+			"__meta_kubernetes_pod_annotation_prometheus_io_scrape": "true",
+		}
+	}
+
+	targets := NewPodTargets("prometheus.io/scrape")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := targets.Track(ctx, cache.NewStore(cache.MetaNamespaceKeyFunc))
+	if err := s.Replace([]interface{}{
+		newPod("a", "10.0.0.1", scrape, v1.ContainerPort{Name: "http", ContainerPort: 8080}, v1.ContainerPort{Name: "dns", ContainerPort: 53, Protocol: v1.ProtocolUDP}),
+		newPod("b", "10.0.0.2", nil, v1.ContainerPort{ContainerPort: 8080}),
+		newPod("c", "", scrape),
+	}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Add(newPod("d", "fd00::1", scrape)); err != nil {
+		t.Fatal(err)
+	}
+
+	aLabels := podLabels("a", "10.0.0.1")
+	aLabels["__meta_kubernetes_pod_container_name"] = "app"
+	aLabels["__meta_kubernetes_pod_container_port_name"] = "http"
+	aLabels["__meta_kubernetes_pod_container_port_number"] = "8080"
+	expected := []TargetGroup{
+		{Targets: []string{"10.0.0.1:8080"}, Labels: aLabels},
+		{Targets: []string{"fd00::1"}, Labels: podLabels("d", "fd00::1")},
+	}
+	rec := httptest.NewRecorder()
+	targets.ServeHTTP(rec, httptest.NewRequest("GET", "/discovery/pods", nil))
+	var got []TargetGroup
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected target groups\n%+v\ngot\n%+v", expected, got)
+	}
+
+	// Pods are removed once they are deleted or no longer annotated.
+	if err := s.Delete(newPod("d", "fd00::1", scrape)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Update(newPod("a", "10.0.0.1", nil)); err != nil {
+		t.Fatal(err)
+	}
+	if got := targets.TargetGroups(); len(got) != 0 {
+		t.Fatalf("expected no target groups, got %+v", got)
+	}
+}
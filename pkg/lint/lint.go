@@ -0,0 +1,213 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package lint validates metric families against the Prometheus naming
+// conventions and the text exposition format, similar to 'promtool check
+// metrics', without having to run against a cluster.
+package lint
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	"k8s.io/kube-state-metrics/v2/pkg/metric"
+	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
+)
+
+var (
+	metricNameRE = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	labelNameRE  = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// Problem is a violation found in a metric family of a resource.
+type Problem struct {
+	Resource string
+	Family   string
+	Text     string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s: %s: %s", p.Resource, p.Family, p.Text)
+}
+
+// Linter collects the problems of the metric families of several resources.
+// Family names are checked for duplicates across all linted resources.
+type Linter struct {
+	// families maps the names of the linted families to their resource.
+	families map[string]string
+	problems []Problem
+}
+
+// New returns a Linter without any linted families.
+func New() *Linter {
+	return &Linter{families: map[string]string{}}
+}
+
+// Problems returns the problems found so far.
+func (l *Linter) Problems() []Problem {
+	return l.problems
+}
+
+// Lint checks the given families of the resource and renders them with obj.
+// Use SyntheticObject to create an object of the type of the resource.
+func (l *Linter) Lint(resource string, families []generator.FamilyGenerator, obj interface{}) {
+	for i := range families {
+		f := &families[i]
+		report := func(format string, args ...interface{}) {
+			l.problems = append(l.problems, Problem{Resource: resource, Family: f.Name, Text: fmt.Sprintf(format, args...)})
+		}
+
+		if !metricNameRE.MatchString(f.Name) {
+			report("invalid metric name")
+		}
+		if other, ok := l.families[f.Name]; ok {
+			report("duplicate metric family, also generated by resource %s", other)
+		} else {
+			l.families[f.Name] = resource
+		}
+		if strings.TrimSpace(f.Help) == "" {
+			report("no HELP text")
+		}
+		switch f.Type {
+		case metric.Counter:
+			if !strings.HasSuffix(f.Name, "_total") {
+				report("counter metrics should have \"_total\" suffix")
+			}
+		case metric.Gauge, metric.Info, metric.StateSet:
+		default:
+			report("invalid TYPE %q", f.Type)
+		}
+		if f.GenerateFunc == nil {
+			report("no generate function")
+			continue
+		}
+
+		family, err := render(f, obj)
+		if err != nil {
+			report("failed to render: %v", err)
+			continue
+		}
+		for _, text := range lintMetrics(family) {
+			report("%s", text)
+		}
+	}
+}
+
+// render generates the family of obj, turning panics of the generate function
+// into errors.
+func render(f *generator.FamilyGenerator, obj interface{}) (family *metric.Family, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	return f.Generate(obj), nil
+}
+
+// lintMetrics returns the problems of the metrics of a generated family.
+func lintMetrics(family *metric.Family) []string {
+	var problems []string
+	series := map[string]struct{}{}
+	for _, m := range family.Metrics {
+		if len(m.LabelKeys) != len(m.LabelValues) {
+			problems = append(problems, fmt.Sprintf("%d label keys but %d label values", len(m.LabelKeys), len(m.LabelValues)))
+			continue
+		}
+		keys := map[string]struct{}{}
+		for _, k := range m.LabelKeys {
+			if !labelNameRE.MatchString(k) || strings.HasPrefix(k, "__") {
+				problems = append(problems, fmt.Sprintf("invalid label name %q", k))
+			}
+			if _, ok := keys[k]; ok {
+				problems = append(problems, fmt.Sprintf("duplicate label name %q", k))
+			}
+			keys[k] = struct{}{}
+		}
+
+		var s strings.Builder
+		m.Write(&s)
+		if _, ok := series[s.String()]; ok {
+			problems = append(problems, fmt.Sprintf("duplicate series %s", strings.TrimSpace(s.String())))
+		}
+		series[s.String()] = struct{}{}
+	}
+	return problems
+}
+
+// SyntheticObject returns a new object of the type of expectedType with a
+// name, namespace, UID and creation timestamp set, to render metric families
+// with. Nil pointers are allocated like the apiserver defaults them, so that
+// generate functions can rely on defaulted fields.
+func SyntheticObject(expectedType interface{}) interface{} {
+	var obj interface{}
+	switch o := expectedType.(type) {
+	case *unstructured.Unstructured:
+		// Copying keeps the GroupVersionKind of the custom resource.
+		obj = o.DeepCopy()
+	default:
+		t := reflect.TypeOf(expectedType)
+		if t == nil || t.Kind() != reflect.Ptr {
+			return expectedType
+		}
+		v := reflect.New(t.Elem())
+		allocatePointers(v.Elem(), 0)
+		obj = v.Interface()
+	}
+	if m, err := meta.Accessor(obj); err == nil {
+		m.SetName("lint")
+		m.SetNamespace("lint")
+		m.SetUID(types.UID("lint"))
+		m.SetCreationTimestamp(metav1.Unix(0, 0))
+	}
+	if c, ok := obj.(*batchv1.CronJob); ok {
+		c.Spec.Schedule = "@hourly"
+	}
+	return obj
+}
+
+// maxAllocationDepth limits allocatePointers for recursive types.
+const maxAllocationDepth = 8
+
+// allocatePointers allocates the nil pointers of the exported fields of the
+// struct v and of its nested structs.
+func allocatePointers(v reflect.Value, depth int) {
+	if v.Kind() != reflect.Struct || depth > maxAllocationDepth {
+		return
+	}
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		if !f.CanSet() {
+			continue
+		}
+		switch f.Kind() {
+		case reflect.Ptr:
+			if f.IsNil() {
+				f.Set(reflect.New(f.Type().Elem()))
+			}
+			allocatePointers(f.Elem(), depth+1)
+		case reflect.Struct:
+			allocatePointers(f, depth+1)
+		}
+	}
+}
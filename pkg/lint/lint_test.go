@@ -0,0 +1,91 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+
+	"k8s.io/kube-state-metrics/v2/pkg/metric"
+	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
+)
+
+func TestLint(t *testing.T) {
+	family := func(name, help string, metricType metric.Type, metrics ...*metric.Metric) generator.FamilyGenerator {
+		return generator.FamilyGenerator{
+			Name: name,
+			Help: help,
+			Type: metricType,
+			GenerateFunc: func(obj interface{}) *metric.Family {
+				return &metric.Family{Metrics: metrics}
+			},
+		}
+	}
+
+	l := New()
+	l.Lint("pods", []generator.FamilyGenerator{
+		family("kube_pod_info", "Information about pod.", metric.Gauge, &metric.Metric{LabelKeys: []string{"node"}, LabelValues: []string{"a"}}),
+		family("kube_pod-restarts", "Restarts.", metric.Counter),
+		family("kube_pod_labels", "", "histogram",
+			&metric.Metric{LabelKeys: []string{"label_app", "label_app"}, LabelValues: []string{"a", "b"}},
+			&metric.Metric{LabelKeys: []string{"__name"}, LabelValues: []string{"a"}},
+			&metric.Metric{LabelKeys: []string{"pod"}, LabelValues: []string{"a", "b"}},
+		),
+		family("kube_pod_status", "Status.", metric.Gauge,
+			&metric.Metric{LabelKeys: []string{"phase"}, LabelValues: []string{"Running"}},
+			&metric.Metric{LabelKeys: []string{"phase"}, LabelValues: []string{"Running"}},
+		),
+		{Name: "kube_pod_owner", Help: "Owner.", Type: metric.Gauge, GenerateFunc: func(obj interface{}) *metric.Family {
+			return &metric.Family{Metrics: []*metric.Metric{{Value: float64(len(obj.(*v1.Pod).OwnerReferences[0].Kind))}}}
+		}},
+	}, SyntheticObject(&v1.Pod{}))
+	l.Lint("nodes", []generator.FamilyGenerator{
+		family("kube_pod_info", "Information about pod.", metric.Gauge),
+	}, SyntheticObject(&v1.Node{}))
+
+	want := []Problem{
+		{Resource: "pods", Family: "kube_pod-restarts", Text: "invalid metric name"},
+		{Resource: "pods", Family: "kube_pod-restarts", Text: `counter metrics should have "_total" suffix`},
+		{Resource: "pods", Family: "kube_pod_labels", Text: "no HELP text"},
+		{Resource: "pods", Family: "kube_pod_labels", Text: `invalid TYPE "histogram"`},
+		{Resource: "pods", Family: "kube_pod_labels", Text: `duplicate label name "label_app"`},
+		{Resource: "pods", Family: "kube_pod_labels", Text: `invalid label name "__name"`},
+		{Resource: "pods", Family: "kube_pod_labels", Text: "1 label keys but 2 label values"},
+		{Resource: "pods", Family: "kube_pod_status", Text: `duplicate series {phase="Running"} 0`},
+		{Resource: "pods", Family: "kube_pod_owner", Text: "failed to render: runtime error: index out of range [0] with length 0"},
+		{Resource: "nodes", Family: "kube_pod_info", Text: "duplicate metric family, also generated by resource pods"},
+	}
+	if got := l.Problems(); !reflect.DeepEqual(got, want) {
+		t.Errorf("want problems:\n%v\ngot:\n%v", want, got)
+	}
+}
+
+func TestSyntheticObject(t *testing.T) {
+	d, ok := SyntheticObject(&appsv1.Deployment{}).(*appsv1.Deployment)
+	if !ok {
+		t.Fatalf("expected a deployment")
+	}
+	if d.Name == "" || d.Namespace == "" || d.UID == "" {
+		t.Errorf("expected object metadata to be set, got %+v", d.ObjectMeta)
+	}
+	if d.Spec.Replicas == nil || d.Spec.Strategy.RollingUpdate == nil || d.Spec.Strategy.RollingUpdate.MaxSurge == nil {
+		t.Errorf("expected pointers to be allocated, got %+v", d.Spec)
+	}
+}
@@ -16,10 +16,6 @@ limitations under the License.
 
 package metric
 
-import (
-	"strings"
-)
-
 // FamilyInterface interface for a family
 type FamilyInterface interface {
 	Inspect(inspect func(Family))
@@ -38,13 +34,18 @@ func (f Family) Inspect(inspect func(Family)) {
 	inspect(f)
 }
 
-// ByteSlice returns the given Family in its string representation.
+// ByteSlice returns the given Family in its string representation. The
+// metrics are written to a pooled buffer, so that only the returned slice is
+// allocated.
 func (f Family) ByteSlice() []byte {
-	b := strings.Builder{}
+	bp := getBuf()
 	for _, m := range f.Metrics {
-		b.WriteString(f.Name)
-		m.Write(&b)
+		*bp = append(*bp, f.Name...)
+		*bp = m.appendTo(*bp)
 	}
 
-	return []byte(b.String())
+	b := make([]byte, len(*bp))
+	copy(b, *bp)
+	putBuf(bp)
+	return b
 }
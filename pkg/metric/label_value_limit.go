@@ -0,0 +1,164 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metric
+
+import (
+	"fmt"
+	"hash/fnv"
+	"unicode/utf8"
+)
+
+// LabelValueLimitPolicy defines how label values longer than the configured
+// maximum length are handled.
+type LabelValueLimitPolicy string
+
+const (
+	// LabelValueLimitTruncate cuts label values to the maximum length,
+	// ending with a hash of the whole value.
+	LabelValueLimitTruncate LabelValueLimitPolicy = "truncate"
+	// LabelValueLimitHash replaces label values by a hash of their value.
+	LabelValueLimitHash LabelValueLimitPolicy = "hash"
+	// LabelValueLimitDrop drops the label.
+	LabelValueLimitDrop LabelValueLimitPolicy = "drop"
+)
+
+const (
+	// minLabelValueMaxLength is the minimum maximum length of the truncate
+	// and hash policies, whose values keep long values distinguishable by a
+	// hash of up to 16 characters.
+	minLabelValueMaxLength = 16
+	// minDropLabelValueMaxLength is the minimum maximum length of the drop
+	// policy. Names of objects are at most 253 characters long, so that the
+	// labels identifying objects are never dropped, which would result in
+	// duplicate series of different objects.
+	minDropLabelValueMaxLength = 253
+)
+
+// LabelValueLimit limits the length of label values.
+type LabelValueLimit struct {
+	// MaxLength is the maximum length of label values. 0 disables the limit.
+	MaxLength int
+	// Policy defines how label values longer than MaxLength are handled.
+	Policy LabelValueLimitPolicy
+}
+
+// Validate returns an error if the limit is invalid.
+func (l LabelValueLimit) Validate() error {
+	if l.MaxLength < 0 {
+		return fmt.Errorf("label value max length must not be negative, got %d", l.MaxLength)
+	}
+	switch l.Policy {
+	case LabelValueLimitTruncate, LabelValueLimitHash:
+		if l.MaxLength != 0 && l.MaxLength < minLabelValueMaxLength {
+			return fmt.Errorf("label value max length must be at least %d with policy %q, got %d", minLabelValueMaxLength, l.Policy, l.MaxLength)
+		}
+	case LabelValueLimitDrop:
+		if l.MaxLength != 0 && l.MaxLength < minDropLabelValueMaxLength {
+			return fmt.Errorf("label value max length must be at least %d with policy %q, got %d", minDropLabelValueMaxLength, l.Policy, l.MaxLength)
+		}
+	default:
+		return fmt.Errorf("unknown label value limit policy %q, must be one of %q, %q or %q", l.Policy, LabelValueLimitTruncate, LabelValueLimitHash, LabelValueLimitDrop)
+	}
+	return nil
+}
+
+// Apply applies the limit to the label values of the metrics of the family.
+// Metrics whose labels equal the labels of a previous metric of the family
+// after dropping labels are removed, so that the series stay unique.
+func (l LabelValueLimit) Apply(f *Family) {
+	if l.MaxLength == 0 || f == nil {
+		return
+	}
+	anyDropped := false
+	for _, m := range f.Metrics {
+		var dropped bool
+		m.LabelKeys, m.LabelValues, dropped = l.limitLabels(m.LabelKeys, m.LabelValues)
+		anyDropped = anyDropped || dropped
+	}
+	if !anyDropped {
+		return
+	}
+
+	seen := make(map[string]struct{}, len(f.Metrics))
+	metrics := make([]*Metric, 0, len(f.Metrics))
+	for _, m := range f.Metrics {
+		labels := string(appendLabels(nil, m.LabelKeys, m.LabelValues))
+		if _, ok := seen[labels]; ok {
+			continue
+		}
+		seen[labels] = struct{}{}
+		metrics = append(metrics, m)
+	}
+	f.Metrics = metrics
+}
+
+// limitLabels returns the labels with the limit applied to their values, and
+// whether labels were dropped. The given slices are not modified, as they
+// may be shared between metrics.
+func (l LabelValueLimit) limitLabels(keys, values []string) ([]string, []string, bool) {
+	var limitedKeys, limitedValues []string
+	dropped := false
+	for i, v := range values {
+		limited, ok := l.limit(v)
+		if ok && limited == v && limitedValues == nil {
+			continue
+		}
+		if limitedValues == nil {
+			limitedKeys = append(make([]string, 0, len(keys)), keys[:i]...)
+			limitedValues = append(make([]string, 0, len(values)), values[:i]...)
+		}
+		if !ok {
+			dropped = true
+			continue
+		}
+		limitedKeys = append(limitedKeys, keys[i])
+		limitedValues = append(limitedValues, limited)
+	}
+	if limitedValues == nil {
+		return keys, values, false
+	}
+	return limitedKeys, limitedValues, dropped
+}
+
+// limit applies the limit to the given value. It returns false if the label
+// should be dropped.
+func (l LabelValueLimit) limit(v string) (string, bool) {
+	if len(v) <= l.MaxLength {
+		return v, true
+	}
+
+	switch l.Policy {
+	case LabelValueLimitHash:
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(v))
+		return fmt.Sprintf("%016x", h.Sum64()), true
+	case LabelValueLimitDrop:
+		return "", false
+	default:
+		// Values sharing their beginning are kept apart by a hash of the
+		// whole value.
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(v))
+		suffix := fmt.Sprintf("~%08x", h.Sum32())
+		// Do not cut multi-byte characters in half.
+		i := l.MaxLength - len(suffix)
+		for i > 0 && !utf8.RuneStart(v[i]) {
+			i--
+		}
+		return v[:i] + suffix, true
+	}
+}
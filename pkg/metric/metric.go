@@ -25,18 +25,37 @@ import (
 )
 
 const (
-	initialNumBufSize = 24
+	// initialBufSize is the initial capacity of pooled buffers, which fits
+	// the metrics of most families of an object.
+	initialBufSize = 1024
+	// maxPooledBufSize is the capacity above which buffers are not returned
+	// to the pool, so that single large families don't keep memory.
+	maxPooledBufSize = 64 << 10
 )
 
 var (
-	numBufPool = sync.Pool{
+	bufPool = sync.Pool{
 		New: func() interface{} {
-			b := make([]byte, 0, initialNumBufSize)
+			b := make([]byte, 0, initialBufSize)
 			return &b
 		},
 	}
 )
 
+// getBuf returns an empty buffer from the pool.
+func getBuf() *[]byte {
+	bp := bufPool.Get().(*[]byte)
+	*bp = (*bp)[:0]
+	return bp
+}
+
+// putBuf returns the buffer to the pool unless it grew too large.
+func putBuf(bp *[]byte) {
+	if cap(*bp) <= maxPooledBufSize {
+		bufPool.Put(bp)
+	}
+}
+
 // Type represents the type of a metric e.g. a counter. See
 // https://github.com/OpenObservability/OpenMetrics/blob/main/specification/OpenMetrics.md#metric-types.
 type Type string
@@ -59,9 +78,33 @@ type Metric struct {
 	LabelKeys   []string
 	LabelValues []string
 	Value       float64
+	// Exemplar is the OpenMetrics exemplar of the metric, if any.
+	Exemplar *Exemplar
+
+	// milliseconds are the milliseconds of the timestamp of metrics created
+	// by TimestampMetric, which are truncated from their value.
+	milliseconds int64
 }
 
+// Exemplar represents an OpenMetrics exemplar, which references e.g. the
+// object a metric is generated from.
+type Exemplar struct {
+	LabelKeys   []string
+	LabelValues []string
+	Value       float64
+}
+
+// Write writes the metric without its name to s.
 func (m *Metric) Write(s *strings.Builder) {
+	bp := getBuf()
+	*bp = m.appendTo(*bp)
+	s.Write(*bp)
+	putBuf(bp)
+}
+
+// appendTo appends the metric without its name to b and returns the
+// extended buffer.
+func (m *Metric) appendTo(b []byte) []byte {
 	if len(m.LabelKeys) != len(m.LabelValues) {
 		panic(fmt.Sprintf(
 			"expected labelKeys %q to be of same length as labelValues %q",
@@ -69,62 +112,147 @@ func (m *Metric) Write(s *strings.Builder) {
 		))
 	}
 
-	labelsToString(s, m.LabelKeys, m.LabelValues)
-	s.WriteByte(' ')
-	writeFloat(s, m.Value)
-	s.WriteByte('\n')
+	b = appendLabels(b, m.LabelKeys, m.LabelValues)
+	b = append(b, ' ')
+	b = appendFloat(b, m.Value)
+	if m.Exemplar != nil {
+		b = append(b, " # "...)
+		// Exemplars require a label set, even if it is empty.
+		start := len(b)
+		b = appendLabels(b, m.Exemplar.LabelKeys, m.Exemplar.LabelValues)
+		if len(b) == start {
+			b = append(b, "{}"...)
+		}
+		b = append(b, ' ')
+		b = appendFloat(b, m.Exemplar.Value)
+	}
+	return append(b, '\n')
 }
 
-func labelsToString(m *strings.Builder, keys, values []string) {
-	if len(keys) > 0 {
-		var separator byte = '{'
-
-		for i := 0; i < len(keys); i++ {
-			m.WriteByte(separator)
-			m.WriteString(keys[i])
-			m.WriteString("=\"")
-			escapeString(m, values[i])
-			m.WriteByte('"')
-			separator = ','
-		}
+func appendLabels(b []byte, keys, values []string) []byte {
+	var separator byte = '{'
+
+	for i := 0; i < len(keys); i++ {
+		b = append(b, separator)
+		b = append(b, keys[i]...)
+		b = append(b, "=\""...)
+		b = appendEscaped(b, values[i])
+		b = append(b, '"')
+		separator = ','
+	}
 
-		m.WriteByte('}')
+	if separator == ',' {
+		b = append(b, '}')
 	}
+	return b
 }
 
-var (
-	escapeWithDoubleQuote = strings.NewReplacer("\\", `\\`, "\n", `\n`, "\"", `\"`)
-)
+// ParseMetric parses a line written by Family.ByteSlice, i.e. the name of the
+// family followed by the output of Metric.Write without the trailing new
+// line, and returns the name and the metric. Exemplars are ignored.
+func ParseMetric(line string) (string, *Metric, error) {
+	end := strings.IndexAny(line, "{ ")
+	if end <= 0 {
+		return "", nil, fmt.Errorf("invalid metric %q: missing name", line)
+	}
+	name, rest := line[:end], line[end:]
+	m := &Metric{}
+	if rest[0] == '{' {
+		rest = rest[1:]
+		for {
+			eq := strings.Index(rest, "=\"")
+			if eq <= 0 {
+				return "", nil, fmt.Errorf("invalid metric %q: invalid label", line)
+			}
+			key := rest[:eq]
+			rest = rest[eq+2:]
+			var value strings.Builder
+			closed := false
+			for i := 0; i < len(rest); i++ {
+				c := rest[i]
+				if c == '"' {
+					rest, closed = rest[i+1:], true
+					break
+				}
+				if c == '\\' && i+1 < len(rest) {
+					i++
+					switch rest[i] {
+					case 'n':
+						c = '\n'
+					default:
+						c = rest[i]
+					}
+				}
+				value.WriteByte(c)
+			}
+			if !closed {
+				return "", nil, fmt.Errorf("invalid metric %q: unterminated label value", line)
+			}
+			m.LabelKeys = append(m.LabelKeys, key)
+			m.LabelValues = append(m.LabelValues, value.String())
+			if strings.HasPrefix(rest, ",") {
+				rest = rest[1:]
+				continue
+			}
+			if !strings.HasPrefix(rest, "}") {
+				return "", nil, fmt.Errorf("invalid metric %q: unterminated labels", line)
+			}
+			rest = rest[1:]
+			break
+		}
+	}
+	if !strings.HasPrefix(rest, " ") {
+		return "", nil, fmt.Errorf("invalid metric %q: missing value", line)
+	}
+	sample, _, _ := strings.Cut(rest[1:], " # ")
+	v, err := strconv.ParseFloat(sample, 64)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid metric %q: %w", line, err)
+	}
+	m.Value = v
+	return name, m, nil
+}
 
-// escapeString replaces '\' by '\\', new line character by '\n', and '"' by
-// '\"'.
-// Taken from github.com/prometheus/common/expfmt/text_create.go.
-func escapeString(m *strings.Builder, v string) {
-	escapeWithDoubleQuote.WriteString(m, v)
+// appendEscaped appends v to b with '\' replaced by '\\', new line
+// characters by '\n', and '"' by '\"', without allocating.
+func appendEscaped(b []byte, v string) []byte {
+	// Most label values don't need to be escaped.
+	if !strings.ContainsAny(v, "\\\n\"") {
+		return append(b, v...)
+	}
+	for i := 0; i < len(v); i++ {
+		switch c := v[i]; c {
+		case '\\':
+			b = append(b, `\\`...)
+		case '\n':
+			b = append(b, `\n`...)
+		case '"':
+			b = append(b, `\"`...)
+		default:
+			b = append(b, c)
+		}
+	}
+	return b
 }
 
-// writeFloat is equivalent to fmt.Fprint with a float64 argument but hardcodes
-// a few common cases for increased efficiency. For non-hardcoded cases, it uses
-// strconv.AppendFloat to avoid allocations, similar to writeInt.
+// appendFloat appends f to b like fmt.Fprint with a float64 argument, but
+// hardcodes a few common cases for increased efficiency.
 // Taken from github.com/prometheus/common/expfmt/text_create.go.
-func writeFloat(w *strings.Builder, f float64) {
+func appendFloat(b []byte, f float64) []byte {
 	switch {
 	case f == 1:
-		w.WriteByte('1')
+		return append(b, '1')
 	case f == 0:
-		w.WriteByte('0')
+		return append(b, '0')
 	case f == -1:
-		w.WriteString("-1")
+		return append(b, "-1"...)
 	case math.IsNaN(f):
-		w.WriteString("NaN")
+		return append(b, "NaN"...)
 	case math.IsInf(f, +1):
-		w.WriteString("+Inf")
+		return append(b, "+Inf"...)
 	case math.IsInf(f, -1):
-		w.WriteString("-Inf")
+		return append(b, "-Inf"...)
 	default:
-		bp := numBufPool.Get().(*[]byte)
-		*bp = strconv.AppendFloat((*bp)[:0], f, 'g', -1, 64)
-		w.Write(*bp)
-		numBufPool.Put(bp)
+		return strconv.AppendFloat(b, f, 'g', -1, 64)
 	}
 }
@@ -17,8 +17,11 @@ limitations under the License.
 package metric
 
 import (
+	"math"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestFamilyString(t *testing.T) {
@@ -41,6 +44,64 @@ func TestFamilyString(t *testing.T) {
 	}
 }
 
+func TestLabelValueLimit(t *testing.T) {
+	tests := []struct {
+		limit    LabelValueLimit
+		expected string
+	}{
+		{LabelValueLimit{0, LabelValueLimitTruncate}, `kube_pod_annotations{namespace="default",annotation_description="a long description ☃ of the pod"} 1
+kube_pod_annotations{namespace="default",annotation_description="a long description of the node"} 1`},
+		// The snowman takes three bytes and is not cut in half.
+		{LabelValueLimit{29, LabelValueLimitTruncate}, `kube_pod_annotations{namespace="default",annotation_description="a long description ~a285536c"} 1
+kube_pod_annotations{namespace="default",annotation_description="a long description o~2930f6e6"} 1`},
+		{LabelValueLimit{16, LabelValueLimitHash}, `kube_pod_annotations{namespace="default",annotation_description="a6b677f138bf0bec"} 1
+kube_pod_annotations{namespace="default",annotation_description="6c8eb7e371ad6c26"} 1`},
+		// The series of the second metric would equal the first one.
+		{LabelValueLimit{253, LabelValueLimitDrop}, `kube_pod_annotations{namespace="default",annotation_description="a long description ☃ of the pod"} 1
+kube_pod_annotations{namespace="default",annotation_description="a long description of the node"} 1`},
+	}
+
+	for _, test := range tests {
+		if err := test.limit.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		keys := []string{"namespace", "annotation_description"}
+		f := Family{Name: "kube_pod_annotations", Metrics: []*Metric{
+			{LabelKeys: keys, LabelValues: []string{"default", "a long description ☃ of the pod"}, Value: 1},
+			{LabelKeys: keys, LabelValues: []string{"default", "a long description of the node"}, Value: 1},
+		}}
+		test.limit.Apply(&f)
+		if got := strings.TrimSpace(string(f.ByteSlice())); got != test.expected {
+			t.Errorf("limit %+v: expected %v but got %v", test.limit, test.expected, got)
+		}
+		if keys[1] != "annotation_description" {
+			t.Errorf("limit %+v: expected the shared label keys to be unmodified, got %v", test.limit, keys)
+		}
+	}
+
+	long := strings.Repeat("x", 300)
+	f := Family{Name: "kube_pod_annotations", Metrics: []*Metric{
+		{LabelKeys: []string{"namespace", "annotation_description"}, LabelValues: []string{"default", long + "a"}, Value: 1},
+		{LabelKeys: []string{"namespace", "annotation_description"}, LabelValues: []string{"default", long + "b"}, Value: 1},
+	}}
+	LabelValueLimit{253, LabelValueLimitDrop}.Apply(&f)
+	if got, expected := strings.TrimSpace(string(f.ByteSlice())), `kube_pod_annotations{namespace="default"} 1`; got != expected {
+		t.Errorf("expected the duplicate series to be removed, got %v", got)
+	}
+
+	for _, limit := range []LabelValueLimit{
+		{10, "unknown"},
+		{-1, LabelValueLimitTruncate},
+		{10, LabelValueLimitTruncate},
+		{10, LabelValueLimitHash},
+		{100, LabelValueLimitDrop},
+	} {
+		if err := limit.Validate(); err == nil {
+			t.Errorf("expected limit %+v to be invalid", limit)
+		}
+	}
+}
+
 func BenchmarkMetricWrite(b *testing.B) {
 	tests := []struct {
 		testName       string
@@ -86,3 +147,107 @@ func BenchmarkMetricWrite(b *testing.B) {
 		})
 	}
 }
+
+func TestParseMetric(t *testing.T) {
+	for _, want := range []*Metric{
+		{Value: 1},
+		{LabelKeys: []string{"a"}, LabelValues: []string{"b"}, Value: 2.5},
+		{LabelKeys: []string{"a", "b"}, LabelValues: []string{"x,y}=\"z\"", "line\nbreak\\"}, Value: -3},
+		{LabelKeys: []string{"a"}, LabelValues: []string{""}, Value: math.Inf(1)},
+	} {
+		var s strings.Builder
+		s.WriteString("kube_test")
+		want.Write(&s)
+
+		name, got, err := ParseMetric(strings.TrimSuffix(s.String(), "\n"))
+		if err != nil {
+			t.Fatalf("unexpected error for %q: %v", s.String(), err)
+		}
+		if name != "kube_test" || !reflect.DeepEqual(got, want) {
+			t.Errorf("expected kube_test %+v, got %s %+v", want, name, got)
+		}
+	}
+
+	_, got, err := ParseMetric(`kube_test{a="b"} 2 # {uid="x # {y"} 2`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (&Metric{LabelKeys: []string{"a"}, LabelValues: []string{"b"}, Value: 2}); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the exemplar to be ignored, got %+v", got)
+	}
+
+	for _, line := range []string{"", "kube_test", `kube_test{a="b" 1`, `kube_test{a="b} 1`, "kube_test one"} {
+		if _, _, err := ParseMetric(line); err == nil {
+			t.Errorf("expected an error for %q", line)
+		}
+	}
+}
+
+func TestTimestampMetric(t *testing.T) {
+	ts := time.Unix(1700000000, 250*int64(time.Millisecond))
+
+	tests := []struct {
+		precision TimestampPrecision
+		want      string
+	}{
+		{precision: TimestampPrecisionSeconds, want: "kube_test{a=\"b\"} 1.7e+09\n"},
+		{precision: TimestampPrecisionMilliseconds, want: "kube_test{a=\"b\"} 1.70000000025e+09\n"},
+	}
+	for _, test := range tests {
+		if err := test.precision.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		f := &Family{Name: "kube_test", Metrics: []*Metric{TimestampMetric([]string{"a"}, []string{"b"}, ts)}}
+		test.precision.Apply(f)
+		var s strings.Builder
+		s.WriteString("kube_test")
+		f.Metrics[0].Write(&s)
+		if s.String() != test.want {
+			t.Errorf("expected %q with precision %s, got %q", test.want, test.precision, s.String())
+		}
+	}
+
+	if err := TimestampPrecision("nanoseconds").Validate(); err == nil {
+		t.Error("expected an error for an unknown precision")
+	}
+}
+
+func TestMetricWriteExemplar(t *testing.T) {
+	for _, test := range []struct {
+		metric *Metric
+		want   string
+	}{
+		{
+			metric: &Metric{LabelKeys: []string{"a"}, LabelValues: []string{"b"}, Value: 2, Exemplar: &Exemplar{LabelKeys: []string{"uid"}, LabelValues: []string{"c"}, Value: 2}},
+			want:   `kube_test{a="b"} 2 # {uid="c"} 2` + "\n",
+		},
+		{
+			metric: &Metric{Value: 1, Exemplar: &Exemplar{Value: 1}},
+			want:   "kube_test 1 # {} 1\n",
+		},
+	} {
+		var s strings.Builder
+		s.WriteString("kube_test")
+		test.metric.Write(&s)
+		if s.String() != test.want {
+			t.Errorf("expected %q, got %q", test.want, s.String())
+		}
+	}
+}
+
+func BenchmarkFamilyByteSlice(b *testing.B) {
+	f := Family{Name: "kube_pod_container_info"}
+	for i := 0; i < 10; i++ {
+		f.Metrics = append(f.Metrics, &Metric{
+			LabelKeys:   []string{"container", "container_id", "image", "image_id", "namespace", "pod", "uid"},
+			LabelValues: []string{"container2", "docker://cd456", "k8s.gcr.io/hyperkube2", "docker://sha256:bbb", "ns2", "pod2", "a1b2c3d4-e5f6-a7b8-c9d0-e1f2a3b4c5d6"},
+			Value:       float64(i),
+		})
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if len(f.ByteSlice()) == 0 {
+			b.Fatal("expected metrics to be written")
+		}
+	}
+}
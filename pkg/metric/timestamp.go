@@ -0,0 +1,68 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metric
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimestampPrecision defines the precision of the values of metrics which
+// are timestamps.
+type TimestampPrecision string
+
+const (
+	// TimestampPrecisionSeconds truncates timestamps to seconds.
+	TimestampPrecisionSeconds TimestampPrecision = "seconds"
+	// TimestampPrecisionMilliseconds keeps the milliseconds of timestamps as
+	// fraction of the seconds.
+	TimestampPrecisionMilliseconds TimestampPrecision = "milliseconds"
+)
+
+// Validate returns an error if the precision is unknown.
+func (p TimestampPrecision) Validate() error {
+	switch p {
+	case TimestampPrecisionSeconds, TimestampPrecisionMilliseconds:
+		return nil
+	default:
+		return fmt.Errorf("unknown timestamp precision %q, must be one of %q or %q", p, TimestampPrecisionSeconds, TimestampPrecisionMilliseconds)
+	}
+}
+
+// Apply applies the precision to the values of the metrics of the family
+// which were created by TimestampMetric.
+func (p TimestampPrecision) Apply(f *Family) {
+	if p != TimestampPrecisionMilliseconds || f == nil {
+		return
+	}
+	for _, m := range f.Metrics {
+		if m.milliseconds != 0 {
+			m.Value = float64(int64(m.Value)*1000+m.milliseconds) / 1000
+		}
+	}
+}
+
+// TimestampMetric returns a metric whose value is the Unix time of t in
+// seconds. The milliseconds of t are kept for TimestampPrecision.Apply.
+func TimestampMetric(labelKeys, labelValues []string, t time.Time) *Metric {
+	return &Metric{
+		LabelKeys:    labelKeys,
+		LabelValues:  labelValues,
+		Value:        float64(t.Unix()),
+		milliseconds: t.UnixMilli() - t.Unix()*1000,
+	}
+}
@@ -16,6 +16,8 @@ limitations under the License.
 
 package generator
 
+import "sync"
+
 // FamilyGeneratorFilter represents a filter which decides whether a metric
 // family is exposed by the store or not
 type FamilyGeneratorFilter interface {
@@ -25,6 +27,15 @@ type FamilyGeneratorFilter interface {
 	Test(generator FamilyGenerator) bool
 }
 
+// FamilyGeneratorFilterFunc is an adapter to use ordinary functions as
+// FamilyGeneratorFilter.
+type FamilyGeneratorFilterFunc func(generator FamilyGenerator) bool
+
+// Test calls f(generator).
+func (f FamilyGeneratorFilterFunc) Test(generator FamilyGenerator) bool {
+	return f(generator)
+}
+
 // CompositeFamilyGeneratorFilter is composite for combining multiple filters
 type CompositeFamilyGeneratorFilter struct {
 	filters []FamilyGeneratorFilter
@@ -59,3 +70,82 @@ func FilterFamilyGenerators(filter FamilyGeneratorFilter, families []FamilyGener
 func NewCompositeFamilyGeneratorFilter(filters ...FamilyGeneratorFilter) CompositeFamilyGeneratorFilter {
 	return CompositeFamilyGeneratorFilter{filters}
 }
+
+// SwappableFamilyGeneratorFilter is a filter which tests generators with a
+// filter which can be swapped while it is in use, e.g. when the filter is
+// reloaded.
+type SwappableFamilyGeneratorFilter struct {
+	mtx    sync.RWMutex
+	filter FamilyGeneratorFilter
+}
+
+// NewSwappableFamilyGeneratorFilter returns a filter which tests generators
+// with the given filter until it is swapped.
+func NewSwappableFamilyGeneratorFilter(filter FamilyGeneratorFilter) *SwappableFamilyGeneratorFilter {
+	return &SwappableFamilyGeneratorFilter{filter: filter}
+}
+
+// Test tests the generator with the current filter.
+func (swappable *SwappableFamilyGeneratorFilter) Test(generator FamilyGenerator) bool {
+	return swappable.Filter().Test(generator)
+}
+
+// Filter returns the current filter.
+func (swappable *SwappableFamilyGeneratorFilter) Filter() FamilyGeneratorFilter {
+	swappable.mtx.RLock()
+	defer swappable.mtx.RUnlock()
+	return swappable.filter
+}
+
+// Swap replaces the current filter with the given one.
+func (swappable *SwappableFamilyGeneratorFilter) Swap(filter FamilyGeneratorFilter) {
+	swappable.mtx.Lock()
+	defer swappable.mtx.Unlock()
+	swappable.filter = filter
+}
+
+// otherFilterName is the name RejectingFilter reports for filters without a
+// name.
+const otherFilterName = "other"
+
+// namedFamilyGeneratorFilter is a filter with a name.
+type namedFamilyGeneratorFilter struct {
+	name   string
+	filter FamilyGeneratorFilter
+}
+
+// Test tests the generator with the wrapped filter.
+func (named namedFamilyGeneratorFilter) Test(generator FamilyGenerator) bool {
+	return named.filter.Test(generator)
+}
+
+// NewNamedFamilyGeneratorFilter returns the filter with the given name, which
+// RejectingFilter reports for the metric families it rejects.
+func NewNamedFamilyGeneratorFilter(name string, filter FamilyGeneratorFilter) FamilyGeneratorFilter {
+	return namedFamilyGeneratorFilter{name, filter}
+}
+
+// RejectingFilter returns the name of the filter which rejects the generator
+// and whether it is rejected at all. The filters of composite filters are
+// tested in order. Filters without a name are reported as "other".
+func RejectingFilter(filter FamilyGeneratorFilter, generator FamilyGenerator) (string, bool) {
+	switch f := filter.(type) {
+	case namedFamilyGeneratorFilter:
+		if !f.filter.Test(generator) {
+			return f.name, true
+		}
+	case *SwappableFamilyGeneratorFilter:
+		return RejectingFilter(f.Filter(), generator)
+	case CompositeFamilyGeneratorFilter:
+		for _, sub := range f.filters {
+			if name, rejected := RejectingFilter(sub, generator); rejected {
+				return name, true
+			}
+		}
+	default:
+		if !filter.Test(generator) {
+			return otherFilterName, true
+		}
+	}
+	return "", false
+}
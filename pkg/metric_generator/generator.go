@@ -18,30 +18,74 @@ package generator
 
 import (
 	"fmt"
+	"runtime"
 	"strings"
 
+	"k8s.io/apimachinery/pkg/api/meta"
 	basemetrics "k8s.io/component-base/metrics"
+	"k8s.io/klog/v2"
 
 	"k8s.io/kube-state-metrics/v2/pkg/metric"
 )
 
+// DefaultNamePrefix is the prefix of the names of the metric families
+// generated by kube-state-metrics.
+const DefaultNamePrefix = "kube_"
+
+// sourceRoot is the path of the module, which is trimmed from the Source of
+// family generators.
+var sourceRoot = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return strings.TrimSuffix(file, "pkg/metric_generator/generator.go")
+}()
+
 // FamilyGenerator provides everything needed to generate a metric family with a
 // Kubernetes object.
 // DeprecatedVersion is defined only if the metric for which this options applies is,
 // in fact, deprecated.
+// Source is the file and line the family generator is created at, if known.
+// Lazy is set for families which are only generated from the metadata of the
+// objects, so that stores can defer generating them until they are requested.
+// ScrapeTime is set for families which depend on the time they are generated
+// at, so that stores generate them whenever they are written.
 type FamilyGenerator struct {
 	Name              string
 	Help              string
 	Type              metric.Type
 	OptIn             bool
+	Lazy              bool
+	ScrapeTime        bool
 	DeprecatedVersion string
 	StabilityLevel    basemetrics.StabilityLevel
+	Source            string
 	GenerateFunc      func(obj interface{}) *metric.Family
 }
 
 // NewFamilyGeneratorWithStability creates new FamilyGenerator instances with metric
 // stabilityLevel.
 func NewFamilyGeneratorWithStability(name string, help string, metricType metric.Type, stabilityLevel basemetrics.StabilityLevel, deprecatedVersion string, generateFunc func(obj interface{}) *metric.Family) *FamilyGenerator {
+	return newFamilyGenerator(name, help, metricType, stabilityLevel, deprecatedVersion, generateFunc)
+}
+
+// NewOptInFamilyGenerator creates new FamilyGenerator instances for opt-in metric families.
+func NewOptInFamilyGenerator(name string, help string, metricType metric.Type, stabilityLevel basemetrics.StabilityLevel, deprecatedVersion string, generateFunc func(obj interface{}) *metric.Family) *FamilyGenerator {
+	f := newFamilyGenerator(name, help, metricType, stabilityLevel, deprecatedVersion, generateFunc)
+	f.OptIn = true
+	return f
+}
+
+// NewLazyFamilyGenerator creates new FamilyGenerator instances for metric
+// families which are only generated from the metadata of the objects, i.e.
+// their name, namespace, UID, labels, annotations and owner references.
+func NewLazyFamilyGenerator(name string, help string, metricType metric.Type, stabilityLevel basemetrics.StabilityLevel, deprecatedVersion string, generateFunc func(obj interface{}) *metric.Family) *FamilyGenerator {
+	f := newFamilyGenerator(name, help, metricType, stabilityLevel, deprecatedVersion, generateFunc)
+	f.Lazy = true
+	return f
+}
+
+// newFamilyGenerator must be called directly by the exported constructors, so
+// that the Source is the caller of the constructor.
+func newFamilyGenerator(name string, help string, metricType metric.Type, stabilityLevel basemetrics.StabilityLevel, deprecatedVersion string, generateFunc func(obj interface{}) *metric.Family) *FamilyGenerator {
 	f := &FamilyGenerator{
 		Name:              name,
 		Type:              metricType,
@@ -54,14 +98,9 @@ func NewFamilyGeneratorWithStability(name string, help string, metricType metric
 	if deprecatedVersion != "" {
 		f.Help = fmt.Sprintf("(Deprecated since %s) %s", deprecatedVersion, help)
 	}
-	return f
-}
-
-// NewOptInFamilyGenerator creates new FamilyGenerator instances for opt-in metric families.
-func NewOptInFamilyGenerator(name string, help string, metricType metric.Type, stabilityLevel basemetrics.StabilityLevel, deprecatedVersion string, generateFunc func(obj interface{}) *metric.Family) *FamilyGenerator {
-	f := NewFamilyGeneratorWithStability(name, help, metricType, stabilityLevel,
-		deprecatedVersion, generateFunc)
-	f.OptIn = true
+	if _, file, line, ok := runtime.Caller(2); ok {
+		f.Source = fmt.Sprintf("%s:%d", strings.TrimPrefix(file, sourceRoot), line)
+	}
 	return f
 }
 
@@ -107,6 +146,110 @@ func ExtractMetricFamilyHeaders(families []FamilyGenerator) []string {
 	return headers
 }
 
+// PrefixFamilyGenerators returns copies of the given families where the
+// DefaultNamePrefix of their names is replaced by prefix. Names which do not
+// start with the DefaultNamePrefix are kept.
+func PrefixFamilyGenerators(prefix string, families []FamilyGenerator) []FamilyGenerator {
+	prefixed := make([]FamilyGenerator, len(families))
+	for i, f := range families {
+		if strings.HasPrefix(f.Name, DefaultNamePrefix) {
+			f.Name = prefix + strings.TrimPrefix(f.Name, DefaultNamePrefix)
+		}
+		prefixed[i] = f
+	}
+	return prefixed
+}
+
+// RenameFamilyGenerators returns the given families followed by copies of the
+// families in renames, named with their new names. Both names are served so
+// that consumers can migrate between them. Copies whose new name is already
+// served, by one of the given families or another copy, are skipped to not
+// serve duplicate series.
+func RenameFamilyGenerators(renames map[string]string, families []FamilyGenerator) []FamilyGenerator {
+	names := make(map[string]struct{}, len(families))
+	for _, f := range families {
+		names[f.Name] = struct{}{}
+	}
+	renamed := append(make([]FamilyGenerator, 0, len(families)), families...)
+	for _, f := range families {
+		name, ok := renames[f.Name]
+		if !ok {
+			continue
+		}
+		if _, ok := names[name]; ok {
+			klog.InfoS("Skipping renamed metric family which is already served", "family", f.Name, "name", name)
+			continue
+		}
+		names[name] = struct{}{}
+		f.Name = name
+		renamed = append(renamed, f)
+	}
+	return renamed
+}
+
+// ExemplarFamilyGenerators returns copies of the given families where the
+// families with the given names attach an exemplar with the UID of the object
+// to each metric. If traceIDAnnotation is set, its value is added to the
+// exemplars of annotated objects as trace_id.
+func ExemplarFamilyGenerators(names map[string]struct{}, traceIDAnnotation string, families []FamilyGenerator) []FamilyGenerator {
+	result := make([]FamilyGenerator, len(families))
+	for i, f := range families {
+		if _, ok := names[f.Name]; ok {
+			generate := f.GenerateFunc
+			f.GenerateFunc = func(obj interface{}) *metric.Family {
+				family := generate(obj)
+				o, err := meta.Accessor(obj)
+				if err != nil || o.GetUID() == "" {
+					return family
+				}
+				keys, values := []string{"uid"}, []string{string(o.GetUID())}
+				if traceID := o.GetAnnotations()[traceIDAnnotation]; traceIDAnnotation != "" && traceID != "" {
+					keys, values = append(keys, "trace_id"), append(values, traceID)
+				}
+				for _, m := range family.Metrics {
+					m.Exemplar = &metric.Exemplar{LabelKeys: keys, LabelValues: values, Value: m.Value}
+				}
+				return family
+			}
+		}
+		result[i] = f
+	}
+	return result
+}
+
+// LimitLabelValueFamilyGenerators returns copies of the given families which
+// apply the given limit to the label values of their metrics.
+func LimitLabelValueFamilyGenerators(limit metric.LabelValueLimit, families []FamilyGenerator) []FamilyGenerator {
+	result := make([]FamilyGenerator, len(families))
+	for i, f := range families {
+		generate := f.GenerateFunc
+		f.GenerateFunc = func(obj interface{}) *metric.Family {
+			family := generate(obj)
+			limit.Apply(family)
+			return family
+		}
+		result[i] = f
+	}
+	return result
+}
+
+// TimestampPrecisionFamilyGenerators returns copies of the given families
+// which apply the given precision to the values of their metrics which are
+// timestamps.
+func TimestampPrecisionFamilyGenerators(precision metric.TimestampPrecision, families []FamilyGenerator) []FamilyGenerator {
+	result := make([]FamilyGenerator, len(families))
+	for i, f := range families {
+		generate := f.GenerateFunc
+		f.GenerateFunc = func(obj interface{}) *metric.Family {
+			family := generate(obj)
+			precision.Apply(family)
+			return family
+		}
+		result[i] = f
+	}
+	return result
+}
+
 // ComposeMetricGenFuncs takes a slice of metric families and returns a function
 // that composes their metric generation functions into a single one.
 func ComposeMetricGenFuncs(familyGens []FamilyGenerator) func(obj interface{}) []metric.FamilyInterface {
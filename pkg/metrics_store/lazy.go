@@ -0,0 +1,91 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricsstore
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"k8s.io/kube-state-metrics/v2/pkg/metric"
+)
+
+// lazyFamilies are the families of a MetricsStore which are only generated
+// once the metrics of the store are first written.
+type lazyFamilies struct {
+	// indices are the indices of the families in the headers of the store.
+	indices []int
+	// keep returns what generate needs of an object to generate the families
+	// once they are requested.
+	keep func(interface{}) interface{}
+	// generate generates the families, in the order of indices.
+	generate func(interface{}) []metric.FamilyInterface
+
+	once      sync.Once
+	requested atomic.Bool
+}
+
+// fill sets the families of the given metrics of an object to the ones
+// generated from obj.
+func (l *lazyFamilies) fill(metrics [][]byte, obj interface{}) {
+	for i, f := range l.generate(obj) {
+		metrics[l.indices[i]] = f.ByteSlice()
+	}
+}
+
+// WithLazyFamilies makes the store generate the families at the given
+// indices of its headers only once its metrics are first written, so that
+// families which are never scraped take no memory for their series. Until
+// then, the store keeps what keep returns for each object, and generates the
+// families from it with generate. The generate function of the store must
+// return empty families at the given indices. It must be called before
+// objects are added to the store.
+func (s *MetricsStore) WithLazyFamilies(indices []int, keep func(interface{}) interface{}, generate func(interface{}) []metric.FamilyInterface) {
+	s.lazy = &lazyFamilies{
+		indices:  indices,
+		keep:     keep,
+		generate: generate,
+	}
+	for _, shard := range s.shards {
+		shard.pending = map[types.UID]interface{}{}
+	}
+}
+
+// requestLazyFamilies generates the lazy families of all objects of the
+// store, if it has any, on the first call. Later calls return once the
+// families are generated.
+func (s *MetricsStore) requestLazyFamilies() {
+	if s.lazy == nil {
+		return
+	}
+	s.lazy.once.Do(func() {
+		s.lazy.requested.Store(true)
+		for _, shard := range s.shards {
+			shard.mutex.Lock()
+			for uid, obj := range shard.pending {
+				if metrics, ok := shard.metrics[uid]; ok {
+					shard.add(uid, metrics, -1)
+					s.lazy.fill(metrics, obj)
+					shard.add(uid, metrics, 1)
+				}
+			}
+			shard.pending = nil
+			shard.mutex.Unlock()
+		}
+	})
+}
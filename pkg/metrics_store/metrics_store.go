@@ -17,6 +17,8 @@ limitations under the License.
 package metricsstore
 
 import (
+	"bytes"
+	"hash/fnv"
 	"sync"
 
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -25,10 +27,9 @@ import (
 	"k8s.io/kube-state-metrics/v2/pkg/metric"
 )
 
-// MetricsStore implements the k8s.io/client-go/tools/cache.Store
-// interface. Instead of storing entire Kubernetes objects, it stores metrics
-// generated based on those objects.
-type MetricsStore struct {
+// metricsShard holds the metrics of the objects of a subset of the
+// namespaces of a MetricsStore.
+type metricsShard struct {
 	// Protects metrics
 	mutex sync.RWMutex
 	// metrics is a map indexed by Kubernetes object id, containing a slice of
@@ -36,23 +37,137 @@ type MetricsStore struct {
 	// grouped by metric families in order to zip families with their help text in
 	// MetricsStore.WriteAll().
 	metrics map[types.UID][][]byte
+	// series and bytes are the number of series and the approximate number
+	// of bytes of the metrics, protected by mutex.
+	series int
+	bytes  int64
+	// pending holds what the lazy families of the store are generated from
+	// for each object, until they are requested. It is nil once they are
+	// requested or if the store has no lazy families.
+	pending map[types.UID]interface{}
+	// objects holds the objects the scrape-time families of the store are
+	// generated from. It is nil if the store has no scrape-time families.
+	objects map[types.UID]interface{}
+}
+
+// sliceHeaderSize is the size of a slice header on 64-bit platforms, used to
+// approximate the memory of the metrics of an object.
+const sliceHeaderSize = 24
+
+// Usage is the approximate footprint of the metrics of one or more stores.
+type Usage struct {
+	// Stores is the number of stores, each of which is fed by the goroutines
+	// of a reflector.
+	Stores int
+	// Objects is the number of objects metrics are cached for.
+	Objects int
+	// Series is the number of cached series.
+	Series int
+	// Bytes approximates the memory of the cached metrics, including the
+	// per-object overhead of the store.
+	Bytes int64
+}
+
+// add adds the footprint of the metrics of an object to the shard, or
+// subtracts it if sign is -1. It must be called with the mutex held.
+func (s *metricsShard) add(uid types.UID, families [][]byte, sign int) {
+	size := int64(len(uid) + sliceHeaderSize*(1+len(families)))
+	series := 0
+	for _, f := range families {
+		size += int64(len(f))
+		series += bytes.Count(f, []byte("\n"))
+	}
+	s.series += sign * series
+	s.bytes += int64(sign) * size
+}
+
+// MetricsStore implements the k8s.io/client-go/tools/cache.Store
+// interface. Instead of storing entire Kubernetes objects, it stores metrics
+// generated based on those objects.
+//
+// The metrics are split into shards by the namespace of the objects, so that
+// informer events and scrapes of different namespaces don't contend on the
+// same lock. Objects without a namespace share a shard.
+type MetricsStore struct {
+	// Protects synced
+	mutex sync.RWMutex
+	// shards hold the metrics, with the objects of each namespace in the
+	// same shard.
+	shards []*metricsShard
 	// headers contains the header (TYPE and HELP) of each metric family. It is
 	// later on zipped with with their corresponding metric families in
 	// MetricStore.WriteAll().
 	headers []string
+	// synced is whether the initial list of objects was added, protected by
+	// mutex.
+	synced bool
 
 	// generateMetricsFunc generates metrics based on a given Kubernetes object
 	// and returns them grouped by metric family.
 	generateMetricsFunc func(interface{}) []metric.FamilyInterface
+	// lazy are the families which are generated once the metrics are first
+	// written, nil if all families are generated right away.
+	lazy *lazyFamilies
+	// scrapeTime are the families which are generated whenever the metrics
+	// are written, nil if there are none.
+	scrapeTime *scrapeTimeFamilies
 }
 
 // NewMetricsStore returns a new MetricsStore
 func NewMetricsStore(headers []string, generateFunc func(interface{}) []metric.FamilyInterface) *MetricsStore {
-	return &MetricsStore{
+	return NewShardedMetricsStore(headers, generateFunc, 1)
+}
+
+// NewShardedMetricsStore returns a new MetricsStore which splits the metrics
+// into the given number of shards by the namespace of the objects. A number
+// of shards below 1 is treated as 1.
+func NewShardedMetricsStore(headers []string, generateFunc func(interface{}) []metric.FamilyInterface, shards int) *MetricsStore {
+	if shards < 1 {
+		shards = 1
+	}
+	s := &MetricsStore{
 		generateMetricsFunc: generateFunc,
 		headers:             headers,
-		metrics:             map[types.UID][][]byte{},
+		shards:              make([]*metricsShard, shards),
+	}
+	for i := range s.shards {
+		s.shards[i] = &metricsShard{metrics: map[types.UID][][]byte{}}
+	}
+	return s
+}
+
+// shard returns the shard of the objects of the given namespace.
+func (s *MetricsStore) shard(namespace string) *metricsShard {
+	if len(s.shards) == 1 {
+		return s.shards[0]
+	}
+	h := fnv.New32a()
+	h.Write([]byte(namespace))
+	return s.shards[h.Sum32()%uint32(len(s.shards))]
+}
+
+// objectCount returns the number of objects in the store.
+func (s *MetricsStore) objectCount() int {
+	count := 0
+	for _, shard := range s.shards {
+		shard.mutex.RLock()
+		count += len(shard.metrics)
+		shard.mutex.RUnlock()
 	}
+	return count
+}
+
+// usage returns the footprint of the metrics of the store.
+func (s *MetricsStore) usage() Usage {
+	u := Usage{Stores: 1}
+	for _, shard := range s.shards {
+		shard.mutex.RLock()
+		u.Objects += len(shard.metrics)
+		u.Series += shard.series
+		u.Bytes += shard.bytes
+		shard.mutex.RUnlock()
+	}
+	return u
 }
 
 // Implementing k8s.io/client-go/tools/cache.Store interface
@@ -65,17 +180,43 @@ func (s *MetricsStore) Add(obj interface{}) error {
 		return err
 	}
 
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-
 	families := s.generateMetricsFunc(obj)
 	familyStrings := make([][]byte, len(families))
 
 	for i, f := range families {
 		familyStrings[i] = f.ByteSlice()
 	}
+	var kept interface{}
+	requested := s.lazy == nil || s.lazy.requested.Load()
+	if s.lazy != nil {
+		if requested {
+			s.lazy.fill(familyStrings, obj)
+		} else {
+			kept = s.lazy.keep(obj)
+		}
+	}
+
+	shard := s.shard(o.GetNamespace())
+	shard.mutex.Lock()
+	defer shard.mutex.Unlock()
 
-	s.metrics[o.GetUID()] = familyStrings
+	switch {
+	case requested:
+		delete(shard.pending, o.GetUID())
+	case shard.pending == nil:
+		// The lazy families were requested meanwhile.
+		s.lazy.fill(familyStrings, kept)
+	default:
+		shard.pending[o.GetUID()] = kept
+	}
+	if shard.objects != nil {
+		shard.objects[o.GetUID()] = obj
+	}
+	if old, ok := shard.metrics[o.GetUID()]; ok {
+		shard.add(o.GetUID(), old, -1)
+	}
+	shard.metrics[o.GetUID()] = familyStrings
+	shard.add(o.GetUID(), familyStrings, 1)
 
 	return nil
 }
@@ -94,10 +235,16 @@ func (s *MetricsStore) Delete(obj interface{}) error {
 		return err
 	}
 
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
+	shard := s.shard(o.GetNamespace())
+	shard.mutex.Lock()
+	defer shard.mutex.Unlock()
 
-	delete(s.metrics, o.GetUID())
+	if old, ok := shard.metrics[o.GetUID()]; ok {
+		shard.add(o.GetUID(), old, -1)
+		delete(shard.metrics, o.GetUID())
+	}
+	delete(shard.pending, o.GetUID())
+	delete(shard.objects, o.GetUID())
 
 	return nil
 }
@@ -125,9 +272,19 @@ func (s *MetricsStore) GetByKey(_ string) (item interface{}, exists bool, err er
 // Replace will delete the contents of the store, using instead the
 // given list.
 func (s *MetricsStore) Replace(list []interface{}, _ string) error {
-	s.mutex.Lock()
-	s.metrics = map[types.UID][][]byte{}
-	s.mutex.Unlock()
+	for _, shard := range s.shards {
+		shard.mutex.Lock()
+		shard.metrics = map[types.UID][][]byte{}
+		shard.series = 0
+		shard.bytes = 0
+		if shard.pending != nil {
+			shard.pending = map[types.UID]interface{}{}
+		}
+		if shard.objects != nil {
+			shard.objects = map[types.UID]interface{}{}
+		}
+		shard.mutex.Unlock()
+	}
 
 	for _, o := range list {
 		err := s.Add(o)
@@ -136,9 +293,21 @@ func (s *MetricsStore) Replace(list []interface{}, _ string) error {
 		}
 	}
 
+	s.mutex.Lock()
+	s.synced = true
+	s.mutex.Unlock()
+
 	return nil
 }
 
+// HasSynced returns whether the initial list of objects was added to the
+// store, i.e. whether Replace was called.
+func (s *MetricsStore) HasSynced() bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.synced
+}
+
 // Resync implements the Resync method of the store interface.
 func (s *MetricsStore) Resync() error {
 	return nil
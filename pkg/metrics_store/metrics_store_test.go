@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -94,3 +95,334 @@ func TestObjectsSameNameDifferentNamespaces(t *testing.T) {
 		}
 	}
 }
+
+func TestShardedMetricsStore(t *testing.T) {
+	genFunc := func(obj interface{}) []metric.FamilyInterface {
+		o, err := meta.Accessor(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return []metric.FamilyInterface{
+			&metric.Family{Name: "kube_configmap_info", Metrics: []*metric.Metric{{LabelKeys: []string{"namespace", "configmap"}, LabelValues: []string{o.GetNamespace(), o.GetName()}, Value: 1}}},
+			&metric.Family{Name: "kube_configmap_created", Metrics: []*metric.Metric{{LabelKeys: []string{"namespace", "configmap"}, LabelValues: []string{o.GetNamespace(), o.GetName()}, Value: 2}}},
+		}
+	}
+	headers := []string{"# HELP kube_configmap_info Information about configmap.", "# HELP kube_configmap_created Unix creation timestamp"}
+
+	sharded := NewShardedMetricsStore(headers, genFunc, 4)
+	single := NewMetricsStore(headers, genFunc)
+	var objs []interface{}
+	for i := 0; i < 20; i++ {
+		objs = append(objs, &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name:      "configmap",
+			Namespace: fmt.Sprintf("ns%d", i),
+			UID:       types.UID(fmt.Sprintf("uid%d", i)),
+		}})
+	}
+	for _, s := range []*MetricsStore{sharded, single} {
+		if err := s.Replace(objs, ""); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Delete(objs[3]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	used := 0
+	for _, shard := range sharded.shards {
+		if len(shard.metrics) > 0 {
+			used++
+		}
+	}
+	if used < 2 {
+		t.Errorf("expected the objects to be spread over several shards, got %d", used)
+	}
+	if got := sharded.objectCount(); got != 19 {
+		t.Errorf("expected 19 objects, got %d", got)
+	}
+
+	var want, got strings.Builder
+	if err := (MetricsWriterList{NewMetricsWriter(single)}).WriteAllSorted(&want); err != nil {
+		t.Fatal(err)
+	}
+	if err := (MetricsWriterList{NewMetricsWriter(sharded)}).WriteAllSorted(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.String() != want.String() {
+		t.Errorf("expected the sharded store to write\n%s\ngot\n%s", want.String(), got.String())
+	}
+	if strings.Contains(got.String(), `namespace="ns3"`) {
+		t.Errorf("expected the deleted object not to be written, got\n%s", got.String())
+	}
+}
+
+// hookWriter calls hook before the first write.
+type hookWriter struct {
+	strings.Builder
+	hook func()
+}
+
+func (w *hookWriter) Write(p []byte) (int, error) {
+	if w.hook != nil {
+		hook := w.hook
+		w.hook = nil
+		hook()
+	}
+	return w.Builder.Write(p)
+}
+
+func TestWriteAllUnshardedIsConsistent(t *testing.T) {
+	genFunc := func(obj interface{}) []metric.FamilyInterface {
+		o, err := meta.Accessor(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := metric.Metric{LabelKeys: []string{"configmap", "version"}, LabelValues: []string{o.GetName(), o.GetResourceVersion()}, Value: 1}
+		return []metric.FamilyInterface{
+			&metric.Family{Name: "kube_configmap_info", Metrics: []*metric.Metric{&m}},
+			&metric.Family{Name: "kube_configmap_created", Metrics: []*metric.Metric{&m}},
+		}
+	}
+	headers := []string{"# HELP kube_configmap_info Information about configmap.", "# HELP kube_configmap_created Unix creation timestamp"}
+	s := NewMetricsStore(headers, genFunc)
+	cm := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "configmap", Namespace: "ns", UID: "uid", ResourceVersion: "1"}}
+	if err := s.Add(cm); err != nil {
+		t.Fatal(err)
+	}
+
+	updated := make(chan error, 1)
+	w := &hookWriter{hook: func() {
+		go func() {
+			updated <- s.Update(&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "configmap", Namespace: "ns", UID: "uid", ResourceVersion: "2"}})
+		}()
+		select {
+		case <-updated:
+			t.Error("expected the update to wait for the write")
+		case <-time.After(50 * time.Millisecond):
+		}
+	}}
+	if err := NewMetricsWriter(s).WriteAll(w); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-updated; err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(w.String(), `version="2"`) {
+		t.Errorf("expected all families to be written from the same version, got\n%s", w.String())
+	}
+}
+
+func TestMetricsWriterUsage(t *testing.T) {
+	genFunc := func(obj interface{}) []metric.FamilyInterface {
+		o, err := meta.Accessor(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return []metric.FamilyInterface{
+			&metric.Family{Name: "kube_configmap_info", Metrics: []*metric.Metric{
+				{LabelKeys: []string{"configmap"}, LabelValues: []string{o.GetName()}, Value: 1},
+				{LabelKeys: []string{"configmap"}, LabelValues: []string{o.GetName() + "-copy"}, Value: 1},
+			}},
+		}
+	}
+	cm := func(name string) *v1.ConfigMap {
+		return &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID(name)}}
+	}
+
+	s1 := NewShardedMetricsStore([]string{"# HELP kube_configmap_info Information about configmap."}, genFunc, 2)
+	s2 := NewMetricsStore([]string{"# HELP kube_configmap_info Information about configmap."}, genFunc)
+	if err := s1.Replace([]interface{}{cm("a"), cm("b")}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := s2.Add(cm("c")); err != nil {
+		t.Fatal(err)
+	}
+	mw := NewMetricsWriter(s1, s2)
+
+	u := mw.Usage()
+	if u.Stores != 2 || u.Objects != 3 || u.Series != 6 {
+		t.Errorf("expected 2 stores, 3 objects and 6 series, got %+v", u)
+	}
+	var b strings.Builder
+	if err := mw.WriteAll(&b); err != nil {
+		t.Fatal(err)
+	}
+	if u.Bytes < int64(b.Len()) {
+		t.Errorf("expected at least the %d bytes of the written metrics, got %d", b.Len(), u.Bytes)
+	}
+
+	// Updates replace the footprint of the object, deletes remove it.
+	if err := s1.Update(cm("a")); err != nil {
+		t.Fatal(err)
+	}
+	if got := mw.Usage(); got != u {
+		t.Errorf("expected the update not to change the usage %+v, got %+v", u, got)
+	}
+	for _, o := range []interface{}{cm("a"), cm("b")} {
+		if err := s1.Delete(o); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s2.Replace(nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	if got := mw.Usage(); got != (Usage{Stores: 2}) {
+		t.Errorf("expected no usage after removing all objects, got %+v", got)
+	}
+}
+
+func TestLazyFamilies(t *testing.T) {
+	genFunc := func(obj interface{}) []metric.FamilyInterface {
+		o, err := meta.Accessor(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return []metric.FamilyInterface{
+			&metric.Family{Name: "kube_configmap_info", Metrics: []*metric.Metric{
+				{LabelKeys: []string{"configmap"}, LabelValues: []string{o.GetName()}, Value: 1},
+			}},
+			&metric.Family{},
+		}
+	}
+	lazyGenFunc := func(obj interface{}) []metric.FamilyInterface {
+		o, err := meta.Accessor(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return []metric.FamilyInterface{
+			&metric.Family{Name: "kube_configmap_labels", Metrics: []*metric.Metric{
+				{LabelKeys: []string{"configmap", "label_app"}, LabelValues: []string{o.GetName(), o.GetLabels()["app"]}, Value: 1},
+			}},
+		}
+	}
+	cm := func(name, app string) *v1.ConfigMap {
+		return &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: name, UID: types.UID(name), Labels: map[string]string{"app": app}}}
+	}
+	var kept []string
+	keep := func(obj interface{}) interface{} {
+		kept = append(kept, obj.(*v1.ConfigMap).Name)
+		return obj
+	}
+
+	s := NewShardedMetricsStore([]string{"# HELP kube_configmap_info Information about configmap.", "# HELP kube_configmap_labels Kubernetes labels converted to Prometheus labels."}, genFunc, 2)
+	s.WithLazyFamilies([]int{1}, keep, lazyGenFunc)
+	if err := s.Replace([]interface{}{cm("a", "web"), cm("b", "db"), cm("c", "cache")}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Update(cm("a", "api")); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete(cm("c", "cache")); err != nil {
+		t.Fatal(err)
+	}
+	if got := pendingCount(s); got != 2 {
+		t.Errorf("expected 2 objects to be kept before the first write, got %d", got)
+	}
+
+	var b strings.Builder
+	if err := NewMetricsWriter(s).WriteAll(&b); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`kube_configmap_labels{configmap="a",label_app="api"} 1`,
+		`kube_configmap_labels{configmap="b",label_app="db"} 1`,
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("expected %s to be written, got\n%s", want, b.String())
+		}
+	}
+	if strings.Contains(b.String(), `configmap="c"`) {
+		t.Errorf("expected the deleted object not to be written, got\n%s", b.String())
+	}
+
+	// Once requested, the families are generated right away.
+	if err := s.Add(cm("d", "queue")); err != nil {
+		t.Fatal(err)
+	}
+	if got := pendingCount(s); got != 0 {
+		t.Errorf("expected no objects to be kept after the first write, got %d", got)
+	}
+	b.Reset()
+	if err := NewMetricsWriter(s).WriteAll(&b); err != nil {
+		t.Fatal(err)
+	}
+	if want := `kube_configmap_labels{configmap="d",label_app="queue"} 1`; !strings.Contains(b.String(), want) {
+		t.Errorf("expected %s to be written, got\n%s", want, b.String())
+	}
+	if len(kept) != 4 {
+		t.Errorf("expected the objects to be kept only until the first write, got %v", kept)
+	}
+}
+
+// pendingCount returns the number of objects the store keeps to generate its
+// lazy families from.
+func pendingCount(s *MetricsStore) int {
+	count := 0
+	for _, shard := range s.shards {
+		count += len(shard.pending)
+	}
+	return count
+}
+
+func TestScrapeTimeFamilies(t *testing.T) {
+	genFunc := func(obj interface{}) []metric.FamilyInterface {
+		o, err := meta.Accessor(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return []metric.FamilyInterface{
+			&metric.Family{Name: "kube_configmap_info", Metrics: []*metric.Metric{
+				{LabelKeys: []string{"configmap"}, LabelValues: []string{o.GetName()}, Value: 1},
+			}},
+			&metric.Family{},
+		}
+	}
+	scrapes := 0
+	scrapeTimeGenFunc := func(obj interface{}) []metric.FamilyInterface {
+		o, err := meta.Accessor(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return []metric.FamilyInterface{
+			&metric.Family{Name: "kube_configmap_scrapes", Metrics: []*metric.Metric{
+				{LabelKeys: []string{"configmap"}, LabelValues: []string{o.GetName()}, Value: float64(scrapes)},
+			}},
+		}
+	}
+	cm := func(name string) *v1.ConfigMap {
+		return &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: name, UID: types.UID(name)}}
+	}
+
+	s := NewShardedMetricsStore([]string{"# HELP kube_configmap_info Information about configmap.", "# HELP kube_configmap_scrapes Number of scrapes."}, genFunc, 2)
+	s.WithScrapeTimeFamilies([]int{1}, scrapeTimeGenFunc)
+	if err := s.Replace([]interface{}{cm("a"), cm("b")}, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	// The families are generated on each write, without any update.
+	for scrapes = 1; scrapes <= 2; scrapes++ {
+		var b strings.Builder
+		if err := NewMetricsWriter(s).WriteAll(&b); err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{
+			fmt.Sprintf(`kube_configmap_scrapes{configmap="a"} %d`, scrapes),
+			fmt.Sprintf(`kube_configmap_scrapes{configmap="b"} %d`, scrapes),
+		} {
+			if !strings.Contains(b.String(), want) {
+				t.Errorf("expected %s to be written, got\n%s", want, b.String())
+			}
+		}
+	}
+
+	if err := s.Delete(cm("b")); err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	if err := NewMetricsWriter(s).WriteAll(&b); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(b.String(), `configmap="b"`) {
+		t.Errorf("expected the deleted object not to be written, got\n%s", b.String())
+	}
+}
@@ -19,6 +19,8 @@ package metricsstore
 import (
 	"fmt"
 	"io"
+	"sort"
+	"strings"
 )
 
 // MetricsWriterList represent a list of MetricsWriter
@@ -32,7 +34,8 @@ type MetricsWriterList []*MetricsWriter
 // metrics with the same name coming from different stores end up grouped together.
 // It also ensures that the metric headers are only written out once.
 type MetricsWriter struct {
-	stores []*MetricsStore
+	resource string
+	stores   []*MetricsStore
 }
 
 // NewMetricsWriter creates a new MetricsWriter.
@@ -42,28 +45,80 @@ func NewMetricsWriter(stores ...*MetricsStore) *MetricsWriter {
 	}
 }
 
+// NewResourceMetricsWriter creates a new MetricsWriter for the stores of the
+// given resource.
+func NewResourceMetricsWriter(resource string, stores ...*MetricsStore) *MetricsWriter {
+	return &MetricsWriter{
+		resource: resource,
+		stores:   stores,
+	}
+}
+
+// Resource returns the resource of the stores, which is empty unless the
+// writer was created with NewResourceMetricsWriter.
+func (m MetricsWriter) Resource() string {
+	return m.resource
+}
+
+// HasSynced returns whether all underlying stores have synced.
+func (m MetricsWriter) HasSynced() bool {
+	for _, s := range m.stores {
+		if !s.HasSynced() {
+			return false
+		}
+	}
+	return true
+}
+
+// HasSynced returns whether the stores of all writers have synced.
+func (l MetricsWriterList) HasSynced() bool {
+	for _, m := range l {
+		if !m.HasSynced() {
+			return false
+		}
+	}
+	return true
+}
+
 // WriteAll writes out metrics from the underlying stores to the given writer.
 //
 // WriteAll writes metrics so that the ones with the same name
-// are grouped together when written out.
+// are grouped together when written out. Families which the stores generate
+// lazily are generated first, and families which the stores generate at
+// scrape time are generated while they are written. Unsharded stores are
+// locked for the whole write, so that all families are written from the same
+// state of the objects. The shards of sharded stores are only locked while the metrics of
+// a family are written from them, so that informer events are not blocked for
+// the duration of the scrape.
 func (m MetricsWriter) WriteAll(w io.Writer) error {
 	if len(m.stores) == 0 {
 		return nil
 	}
 
+	sharded := false
 	for _, s := range m.stores {
-		s.mutex.RLock()
-		defer func(s *MetricsStore) {
-			s.mutex.RUnlock()
-		}(s)
+		s.requestLazyFamilies()
+		sharded = sharded || len(s.shards) > 1
+	}
+	if !sharded {
+		for _, s := range m.stores {
+			s.shards[0].mutex.RLock()
+			defer s.shards[0].mutex.RUnlock()
+		}
 	}
 
+	var hasObjects bool
+	if sharded {
+		hasObjects = m.stores[0].objectCount() > 0
+	} else {
+		hasObjects = len(m.stores[0].shards[0].metrics) > 0
+	}
 	for i, help := range m.stores[0].headers {
 		if help != "" && help != "\n" {
 			help += "\n"
 		}
 
-		if len(m.stores[0].metrics) > 0 {
+		if hasObjects {
 			_, err := w.Write([]byte(help))
 			if err != nil {
 				return fmt.Errorf("failed to write help text: %v", err)
@@ -71,17 +126,180 @@ func (m MetricsWriter) WriteAll(w io.Writer) error {
 		}
 
 		for _, s := range m.stores {
-			for _, metricFamilies := range s.metrics {
-				_, err := w.Write(metricFamilies[i])
+			for _, shard := range s.shards {
+				if sharded {
+					shard.mutex.RLock()
+				}
+				err := shard.writeFamily(w, i, s.scrapeTime)
+				if sharded {
+					shard.mutex.RUnlock()
+				}
 				if err != nil {
-					return fmt.Errorf("failed to write metrics family: %v", err)
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// writeFamily writes the metrics of the i-th family of all objects of the
+// shard to w, generating them if they are scrape-time families of the store.
+// It must be called with the mutex read-locked.
+func (s *metricsShard) writeFamily(w io.Writer, i int, scrapeTime *scrapeTimeFamilies) error {
+	if metrics, ok := scrapeTime.metrics(s.objects, i); ok {
+		for _, m := range metrics {
+			if _, err := w.Write(m); err != nil {
+				return fmt.Errorf("failed to write metrics family: %v", err)
+			}
+		}
+		return nil
+	}
+	for _, metricFamilies := range s.metrics {
+		_, err := w.Write(metricFamilies[i])
+		if err != nil {
+			return fmt.Errorf("failed to write metrics family: %v", err)
+		}
+	}
+	return nil
+}
+
+// WriteAllSorted writes out the metrics of the underlying stores of all
+// writers to the given writer like WriteAll, but with the families sorted by
+// name and the series of each family sorted, so that the output is
+// deterministic. As all metrics are buffered to be sorted, it is slower than
+// WriteAll.
+func (l MetricsWriterList) WriteAllSorted(w io.Writer) error {
+	type family struct {
+		header string
+		series []string
+	}
+	families := map[string]*family{}
+	var names []string
+	// Headers removed by SanitizeHeaders continue the previous family, which
+	// can be of the previous writer.
+	var name string
+	for _, m := range l {
+		m.VisitFamilies(func(header string, metrics [][]byte) {
+			if header != "" {
+				name = familyName(header)
+			}
+			if len(metrics) == 0 {
+				return
+			}
+			f, ok := families[name]
+			if !ok {
+				f = &family{}
+				families[name] = f
+				names = append(names, name)
+			}
+			if f.header == "" && header != "\n" {
+				f.header = header
+			}
+			for _, b := range metrics {
+				for _, line := range strings.SplitAfter(string(b), "\n") {
+					if line != "" {
+						f.series = append(f.series, line)
+					}
 				}
 			}
+		})
+	}
+
+	sort.Strings(names)
+	for _, name := range names {
+		f := families[name]
+		if f.header != "" {
+			if _, err := io.WriteString(w, f.header+"\n"); err != nil {
+				return fmt.Errorf("failed to write help text: %v", err)
+			}
+		}
+		sort.Strings(f.series)
+		for _, series := range f.series {
+			if _, err := io.WriteString(w, series); err != nil {
+				return fmt.Errorf("failed to write metrics family: %v", err)
+			}
 		}
 	}
 	return nil
 }
 
+// familyName returns the name of the family of the header, or the header
+// if it is not a HELP comment.
+func familyName(header string) string {
+	rest := strings.TrimPrefix(header, "# HELP ")
+	if rest == header {
+		return header
+	}
+	if i := strings.IndexAny(rest, " \n"); i >= 0 {
+		return rest[:i]
+	}
+	return rest
+}
+
+// VisitFamilies calls visit with the header of each metric family of the
+// underlying stores and the metrics of the family for each object, in the
+// order WriteAll writes them. Headers removed by SanitizeHeaders are empty.
+// The metrics must not be modified.
+func (m MetricsWriter) VisitFamilies(visit func(header string, metrics [][]byte)) {
+	if len(m.stores) == 0 {
+		return
+	}
+
+	for _, s := range m.stores {
+		s.requestLazyFamilies()
+	}
+	for i, header := range m.stores[0].headers {
+		var metrics [][]byte
+		for _, s := range m.stores {
+			for _, shard := range s.shards {
+				shard.mutex.RLock()
+				if generated, ok := s.scrapeTime.metrics(shard.objects, i); ok {
+					metrics = append(metrics, generated...)
+				} else {
+					for _, metricFamilies := range shard.metrics {
+						metrics = append(metrics, metricFamilies[i])
+					}
+				}
+				shard.mutex.RUnlock()
+			}
+		}
+		visit(header, metrics)
+	}
+}
+
+// FamilyCount returns the number of metric families WriteAll writes, which
+// is 0 while the underlying stores hold no objects.
+func (m MetricsWriter) FamilyCount() int {
+	if len(m.stores) == 0 {
+		return 0
+	}
+	s := m.stores[0]
+	if s.objectCount() == 0 {
+		return 0
+	}
+	count := 0
+	for _, header := range s.headers {
+		if header != "" && header != "\n" {
+			count++
+		}
+	}
+	return count
+}
+
+// Usage returns the summed footprint of the metrics of the underlying stores.
+func (m MetricsWriter) Usage() Usage {
+	var u Usage
+	for _, s := range m.stores {
+		su := s.usage()
+		u.Stores += su.Stores
+		u.Objects += su.Objects
+		u.Series += su.Series
+		u.Bytes += su.Bytes
+	}
+	return u
+}
+
 // SanitizeHeaders removes duplicate headers from the given MetricsWriterList for the same family (generated through CRS).
 // These are expected to be consecutive since G** resolution generates groups of similar metrics with same headers before moving onto the next G** spec in the CRS configuration.
 func SanitizeHeaders(writers MetricsWriterList) MetricsWriterList {
@@ -24,6 +24,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 
 	"k8s.io/kube-state-metrics/v2/pkg/metric"
 	metricsstore "k8s.io/kube-state-metrics/v2/pkg/metrics_store"
@@ -263,3 +264,94 @@ func TestWriteAllWithEmptyStores(t *testing.T) {
 		t.Fatalf("Unexpected output, got %q, want %q", result, "")
 	}
 }
+
+func TestHasSynced(t *testing.T) {
+	genFunc := func(obj interface{}) []metric.FamilyInterface {
+		return []metric.FamilyInterface{&metric.Family{Name: "kube_service_info"}}
+	}
+	s1 := metricsstore.NewMetricsStore([]string{"Info about services"}, genFunc)
+	s2 := metricsstore.NewMetricsStore([]string{"Info about services"}, genFunc)
+	writers := metricsstore.MetricsWriterList{metricsstore.NewMetricsWriter(s1, s2)}
+
+	if writers.HasSynced() {
+		t.Fatal("expected writers without synced stores not to be synced")
+	}
+	if err := s1.Replace(nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	if writers.HasSynced() {
+		t.Fatal("expected writers with one synced store not to be synced")
+	}
+	if err := s2.Replace([]interface{}{&v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "service", UID: "a"}}}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if !writers.HasSynced() {
+		t.Fatal("expected writers with synced stores to be synced")
+	}
+}
+
+func TestWriteAllSorted(t *testing.T) {
+	genFunc := func(names ...string) func(obj interface{}) []metric.FamilyInterface {
+		return func(obj interface{}) []metric.FamilyInterface {
+			o, err := meta.Accessor(obj)
+			if err != nil {
+				t.Fatal(err)
+			}
+			families := make([]metric.FamilyInterface, 0, len(names))
+			for _, name := range names {
+				families = append(families, &metric.Family{
+					Name: name,
+					Metrics: []*metric.Metric{
+						{LabelKeys: []string{"uid"}, LabelValues: []string{string(o.GetUID())}, Value: 1},
+					},
+				})
+			}
+			return families
+		}
+	}
+	s1 := metricsstore.NewMetricsStore([]string{
+		"# HELP kube_service_info Information about service.\n# TYPE kube_service_info gauge",
+		"# HELP kube_service_created Unix creation timestamp.\n# TYPE kube_service_created gauge",
+	}, genFunc("kube_service_info", "kube_service_created"))
+	s2 := metricsstore.NewMetricsStore([]string{
+		"# HELP kube_service_created Unix creation timestamp.\n# TYPE kube_service_created gauge",
+		"# HELP kube_configmap_info Information about configmap.\n# TYPE kube_configmap_info gauge",
+	}, genFunc("kube_service_created", "kube_configmap_info"))
+	for _, uid := range []string{"c", "a", "b"} {
+		if err := s1.Add(&v1.Service{ObjectMeta: metav1.ObjectMeta{Name: uid, UID: types.UID(uid)}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s2.Add(&v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "d", UID: "d"}}); err != nil {
+		t.Fatal(err)
+	}
+	writers := metricsstore.SanitizeHeaders(metricsstore.MetricsWriterList{
+		metricsstore.NewMetricsWriter(s1),
+		metricsstore.NewMetricsWriter(s2),
+	})
+
+	expected := `# HELP kube_configmap_info Information about configmap.
+# TYPE kube_configmap_info gauge
+kube_configmap_info{uid="d"} 1
+# HELP kube_service_created Unix creation timestamp.
+# TYPE kube_service_created gauge
+kube_service_created{uid="a"} 1
+kube_service_created{uid="b"} 1
+kube_service_created{uid="c"} 1
+kube_service_created{uid="d"} 1
+# HELP kube_service_info Information about service.
+# TYPE kube_service_info gauge
+kube_service_info{uid="a"} 1
+kube_service_info{uid="b"} 1
+kube_service_info{uid="c"} 1
+`
+	for i := 0; i < 5; i++ {
+		w := strings.Builder{}
+		if err := writers.WriteAllSorted(&w); err != nil {
+			t.Fatalf("failed to write metrics: %v", err)
+		}
+		if w.String() != expected {
+			t.Fatalf("Unexpected output, got\n%s\nwant\n%s", w.String(), expected)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricsstore
+
+import (
+	"k8s.io/apimachinery/pkg/types"
+
+	"k8s.io/kube-state-metrics/v2/pkg/metric"
+)
+
+// scrapeTimeFamilies are the families of a MetricsStore which are generated
+// whenever the metrics of the store are written.
+type scrapeTimeFamilies struct {
+	// positions maps the indices of the families in the headers of the store
+	// to their position in the families returned by generate.
+	positions map[int]int
+	// generate generates the families, in the order of their indices.
+	generate func(interface{}) []metric.FamilyInterface
+}
+
+// metrics generates the metrics of the i-th family of the store for the given
+// objects. It returns false if the family is not generated at scrape time.
+func (f *scrapeTimeFamilies) metrics(objects map[types.UID]interface{}, i int) ([][]byte, bool) {
+	if f == nil {
+		return nil, false
+	}
+	position, ok := f.positions[i]
+	if !ok {
+		return nil, false
+	}
+	metrics := make([][]byte, 0, len(objects))
+	for _, obj := range objects {
+		metrics = append(metrics, f.generate(obj)[position].ByteSlice())
+	}
+	return metrics, true
+}
+
+// WithScrapeTimeFamilies makes the store generate the families at the given
+// indices of its headers whenever its metrics are written, e.g. for families
+// relative to the time they are generated at. The store keeps the objects to
+// generate the families from with generate. The generate function of the
+// store must return empty families at the given indices. It must be called
+// before objects are added to the store.
+func (s *MetricsStore) WithScrapeTimeFamilies(indices []int, generate func(interface{}) []metric.FamilyInterface) {
+	s.scrapeTime = &scrapeTimeFamilies{
+		positions: make(map[int]int, len(indices)),
+		generate:  generate,
+	}
+	for position, i := range indices {
+		s.scrapeTime.positions[i] = position
+	}
+	for _, shard := range s.shards {
+		shard.objects = map[types.UID]interface{}{}
+	}
+}
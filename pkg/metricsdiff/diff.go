@@ -0,0 +1,236 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metricsdiff compares two expositions of metrics in the Prometheus
+// text format.
+package metricsdiff
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+// Report holds the differences between two expositions.
+type Report struct {
+	AddedFamilies   []string
+	RemovedFamilies []string
+	// ChangedFamilies holds the differences of the families which are part of
+	// both expositions.
+	ChangedFamilies []FamilyDiff
+}
+
+// FamilyDiff holds the differences of a metric family.
+type FamilyDiff struct {
+	Name          string
+	OldType       string
+	NewType       string
+	AddedLabels   []string
+	RemovedLabels []string
+	AddedSeries   []string
+	RemovedSeries []string
+	ValueDrifts   []ValueDrift
+}
+
+// ValueDrift is a series whose value differs between the expositions.
+type ValueDrift struct {
+	Series string
+	Old    float64
+	New    float64
+}
+
+// Diff parses the old and new expositions and returns their differences.
+// Values of series are considered equal if their difference relative to the
+// larger absolute value is at most tolerance.
+func Diff(oldMetrics, newMetrics io.Reader, tolerance float64) (*Report, error) {
+	var parser expfmt.TextParser
+	oldFamilies, err := parser.TextToMetricFamilies(oldMetrics)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse old metrics: %w", err)
+	}
+	newFamilies, err := parser.TextToMetricFamilies(newMetrics)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse new metrics: %w", err)
+	}
+
+	r := &Report{}
+	for _, name := range sortedKeys(oldFamilies) {
+		if _, ok := newFamilies[name]; !ok {
+			r.RemovedFamilies = append(r.RemovedFamilies, name)
+		}
+	}
+	for _, name := range sortedKeys(newFamilies) {
+		oldFamily, ok := oldFamilies[name]
+		if !ok {
+			r.AddedFamilies = append(r.AddedFamilies, name)
+			continue
+		}
+		if d := diffFamily(oldFamily, newFamilies[name], tolerance); d != nil {
+			r.ChangedFamilies = append(r.ChangedFamilies, *d)
+		}
+	}
+
+	return r, nil
+}
+
+// Empty returns whether the report contains no differences.
+func (r *Report) Empty() bool {
+	return len(r.AddedFamilies) == 0 && len(r.RemovedFamilies) == 0 && len(r.ChangedFamilies) == 0
+}
+
+// Write writes the report in a human readable form to w.
+func (r *Report) Write(w io.Writer) error {
+	var b strings.Builder
+	for _, name := range r.AddedFamilies {
+		fmt.Fprintf(&b, "+ %s\n", name)
+	}
+	for _, name := range r.RemovedFamilies {
+		fmt.Fprintf(&b, "- %s\n", name)
+	}
+	for _, f := range r.ChangedFamilies {
+		fmt.Fprintf(&b, "~ %s\n", f.Name)
+		if f.OldType != f.NewType {
+			fmt.Fprintf(&b, "    type: %s -> %s\n", f.OldType, f.NewType)
+		}
+		if len(f.AddedLabels) > 0 {
+			fmt.Fprintf(&b, "    added labels: %s\n", strings.Join(f.AddedLabels, ","))
+		}
+		if len(f.RemovedLabels) > 0 {
+			fmt.Fprintf(&b, "    removed labels: %s\n", strings.Join(f.RemovedLabels, ","))
+		}
+		for _, s := range f.AddedSeries {
+			fmt.Fprintf(&b, "    + %s%s\n", f.Name, s)
+		}
+		for _, s := range f.RemovedSeries {
+			fmt.Fprintf(&b, "    - %s%s\n", f.Name, s)
+		}
+		for _, d := range f.ValueDrifts {
+			fmt.Fprintf(&b, "    ~ %s%s: %v -> %v\n", f.Name, d.Series, d.Old, d.New)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func diffFamily(oldFamily, newFamily *dto.MetricFamily, tolerance float64) *FamilyDiff {
+	d := FamilyDiff{
+		Name:    newFamily.GetName(),
+		OldType: strings.ToLower(oldFamily.GetType().String()),
+		NewType: strings.ToLower(newFamily.GetType().String()),
+	}
+
+	oldLabels, newLabels := labelNames(oldFamily), labelNames(newFamily)
+	d.AddedLabels = missing(newLabels, oldLabels)
+	d.RemovedLabels = missing(oldLabels, newLabels)
+
+	oldSeries, newSeries := series(oldFamily), series(newFamily)
+	d.AddedSeries = missing(newSeries, oldSeries)
+	d.RemovedSeries = missing(oldSeries, newSeries)
+	for _, s := range sortedKeys(newSeries) {
+		o, ok := oldSeries[s]
+		if !ok {
+			continue
+		}
+		if n := newSeries[s]; drifted(o, n, tolerance) {
+			d.ValueDrifts = append(d.ValueDrifts, ValueDrift{Series: s, Old: o, New: n})
+		}
+	}
+
+	if d.OldType == d.NewType && len(d.AddedLabels) == 0 && len(d.RemovedLabels) == 0 &&
+		len(d.AddedSeries) == 0 && len(d.RemovedSeries) == 0 && len(d.ValueDrifts) == 0 {
+		return nil
+	}
+	return &d
+}
+
+// labelNames returns the label names of all series of the family.
+func labelNames(f *dto.MetricFamily) map[string]struct{} {
+	names := map[string]struct{}{}
+	for _, m := range f.GetMetric() {
+		for _, l := range m.GetLabel() {
+			names[l.GetName()] = struct{}{}
+		}
+	}
+	return names
+}
+
+// series returns the values of the series of the family by their labels.
+func series(f *dto.MetricFamily) map[string]float64 {
+	s := make(map[string]float64, len(f.GetMetric()))
+	for _, m := range f.GetMetric() {
+		labels := make([]string, 0, len(m.GetLabel()))
+		for _, l := range m.GetLabel() {
+			labels = append(labels, fmt.Sprintf("%s=%q", l.GetName(), l.GetValue()))
+		}
+		sort.Strings(labels)
+		s["{"+strings.Join(labels, ",")+"}"] = value(m)
+	}
+	return s
+}
+
+func value(m *dto.Metric) float64 {
+	switch {
+	case m.Gauge != nil:
+		return m.GetGauge().GetValue()
+	case m.Counter != nil:
+		return m.GetCounter().GetValue()
+	case m.Histogram != nil:
+		return float64(m.GetHistogram().GetSampleCount())
+	case m.Summary != nil:
+		return float64(m.GetSummary().GetSampleCount())
+	default:
+		return m.GetUntyped().GetValue()
+	}
+}
+
+func drifted(oldValue, newValue, tolerance float64) bool {
+	if oldValue == newValue {
+		return false
+	}
+	if math.IsNaN(oldValue) || math.IsNaN(newValue) {
+		return math.IsNaN(oldValue) != math.IsNaN(newValue)
+	}
+	if math.IsInf(oldValue, 0) || math.IsInf(newValue, 0) {
+		return true
+	}
+	return math.Abs(newValue-oldValue) > tolerance*math.Max(math.Abs(oldValue), math.Abs(newValue))
+}
+
+// missing returns the sorted keys of a which are not in b.
+func missing[T any](a, b map[string]T) []string {
+	var keys []string
+	for _, k := range sortedKeys(a) {
+		if _, ok := b[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricsdiff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	oldMetrics := `# HELP kube_pod_info Information about pod.
+# TYPE kube_pod_info gauge
+kube_pod_info{namespace="ns1",pod="pod1",node="node1"} 1
+kube_pod_info{namespace="ns1",pod="pod2",node="node1"} 1
+# HELP kube_pod_created Unix creation timestamp
+# TYPE kube_pod_created gauge
+kube_pod_created{namespace="ns1",pod="pod1"} 1.5e+09
+kube_pod_created{namespace="ns1",pod="pod2"} 1.6e+09
+# HELP kube_pod_removed Removed family.
+# TYPE kube_pod_removed gauge
+kube_pod_removed{namespace="ns1",pod="pod1"} 1
+`
+	newMetrics := `# HELP kube_pod_info Information about pod.
+# TYPE kube_pod_info gauge
+kube_pod_info{namespace="ns1",pod="pod1",uid="uid1"} 1
+# HELP kube_pod_created Unix creation timestamp
+# TYPE kube_pod_created gauge
+kube_pod_created{namespace="ns1",pod="pod1"} 1.5e+09
+kube_pod_created{namespace="ns1",pod="pod2"} 1.7e+09
+# HELP kube_pod_added Added family.
+# TYPE kube_pod_added counter
+kube_pod_added{namespace="ns1",pod="pod1"} 1
+`
+	want := `+ kube_pod_added
+- kube_pod_removed
+~ kube_pod_created
+    ~ kube_pod_created{namespace="ns1",pod="pod2"}: 1.6e+09 -> 1.7e+09
+~ kube_pod_info
+    added labels: uid
+    removed labels: node
+    + kube_pod_info{namespace="ns1",pod="pod1",uid="uid1"}
+    - kube_pod_info{namespace="ns1",node="node1",pod="pod1"}
+    - kube_pod_info{namespace="ns1",node="node1",pod="pod2"}
+`
+
+	r, err := Diff(strings.NewReader(oldMetrics), strings.NewReader(newMetrics), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	if err := r.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != want {
+		t.Errorf("want:\n%s\ngot:\n%s", want, b.String())
+	}
+
+	r, err = Diff(strings.NewReader(oldMetrics), strings.NewReader(oldMetrics), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.Empty() {
+		t.Errorf("expected no differences between identical expositions, got %+v", r)
+	}
+
+	// The drift of kube_pod_created is within a tolerance of 10%.
+	r, err = Diff(strings.NewReader(oldMetrics), strings.NewReader(newMetrics), 0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range r.ChangedFamilies {
+		if f.Name == "kube_pod_created" {
+			t.Errorf("expected kube_pod_created to be unchanged within tolerance, got %+v", f)
+		}
+	}
+
+	if _, err := Diff(strings.NewReader("invalid metric"), strings.NewReader(newMetrics), 0); err == nil {
+		t.Errorf("expected error for invalid exposition")
+	}
+}
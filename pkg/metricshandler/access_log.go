@@ -0,0 +1,119 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"context"
+	"math/rand"
+	"net"
+	"net/http"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+type accessLogEntryKey struct{}
+
+// accessLogEntry holds the fields of an access log entry which are only known
+// to the handler of the request.
+type accessLogEntry struct {
+	families int
+}
+
+// recordFamilies records the number of metric families served for the
+// request in its access log entry, if the request is logged.
+func recordFamilies(r *http.Request, families int) {
+	if entry, ok := r.Context().Value(accessLogEntryKey{}).(*accessLogEntry); ok {
+		entry.families = families
+	}
+}
+
+// AccessLogHandler returns a handler which logs the given share of the
+// requests to handler as structured access log entries, with the client, the
+// number of served metric families, the response size and the duration, so
+// that scrape load can be attributed to clients. A sample rate of 0 disables
+// the access log.
+func AccessLogHandler(handler http.Handler, sampleRate float64) http.Handler {
+	if sampleRate <= 0 {
+		return handler
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if sampleRate < 1 && rand.Float64() >= sampleRate { //nolint:gosec
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		entry := &accessLogEntry{}
+		rw := &accessLogResponseWriter{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+		handler.ServeHTTP(rw, r.WithContext(context.WithValue(r.Context(), accessLogEntryKey{}, entry)))
+
+		klog.InfoS("Served request",
+			"client", clientIP(r),
+			"forwardedFor", r.Header.Get("X-Forwarded-For"),
+			"user", clientUser(r),
+			"userAgent", r.UserAgent(),
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", rw.status,
+			"families", entry.families,
+			"bytes", rw.bytes,
+			"duration", time.Since(start),
+		)
+	})
+}
+
+// accessLogResponseWriter records the status and the number of bytes of a
+// response.
+type accessLogResponseWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (w *accessLogResponseWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *accessLogResponseWriter) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += n
+	return n, err
+}
+
+// clientIP returns the IP address of the client of the request.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// clientUser returns the identity of the client of the request, which is the
+// common name of its TLS client certificate or the user of its basic
+// authentication, if any.
+func clientUser(r *http.Request) string {
+	if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+		return r.TLS.PeerCertificates[0].Subject.CommonName
+	}
+	if user, _, ok := r.BasicAuth(); ok {
+		return user
+	}
+	return ""
+}
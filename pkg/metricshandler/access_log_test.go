@@ -0,0 +1,75 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	"k8s.io/kube-state-metrics/v2/pkg/options"
+)
+
+func TestAccessLogHandler(t *testing.T) {
+	b := &fakeBuilder{}
+	m := New(options.NewOptions(), nil, b, false)
+	m.ConfigureSharding(context.Background(), 0, 1)
+	first, _ := b.build(0)
+	if err := first.Replace([]interface{}{&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", UID: "a"}}}, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if h := AccessLogHandler(m, 0); h != m {
+		t.Error("expected the handler to be returned unchanged without sampling")
+	}
+
+	var buf bytes.Buffer
+	klog.LogToStderr(false)
+	klog.SetOutput(&buf)
+	defer klog.LogToStderr(true)
+
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	req.RemoteAddr = "192.0.2.1:4711"
+	req.SetBasicAuth("tenant-a", "secret")
+	rec := httptest.NewRecorder()
+	AccessLogHandler(m, 1).ServeHTTP(rec, req)
+	klog.Flush()
+
+	for _, want := range []string{
+		`"Served request"`,
+		`client="192.0.2.1"`,
+		`user="tenant-a"`,
+		`path="/metrics"`,
+		`status=200`,
+		`families=1`,
+		"bytes=" + strconv.Itoa(rec.Body.Len()),
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("expected access log to contain %s, got %q", want, buf.String())
+		}
+	}
+	if strings.Contains(buf.String(), "secret") {
+		t.Errorf("expected access log not to contain the password, got %q", buf.String())
+	}
+}
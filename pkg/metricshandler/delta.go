@@ -0,0 +1,143 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"bufio"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/common/expfmt"
+	"k8s.io/klog/v2"
+
+	"k8s.io/kube-state-metrics/v2/pkg/metric"
+)
+
+const (
+	// deltaHistory is the number of generations which deltas can be
+	// requested since, before a full resync is required.
+	deltaHistory = 16
+
+	generationHeader      = "X-Kube-State-Metrics-Generation"
+	deltaHeader           = "X-Kube-State-Metrics-Delta"
+	removedFamiliesHeader = "X-Kube-State-Metrics-Removed-Families"
+)
+
+// deltaGeneration holds the checksums of the families of a generation of the
+// served metrics.
+type deltaGeneration struct {
+	generation uint64
+	sums       map[string]uint64
+}
+
+// DeltaHandler returns a http.Handler serving the metric families which
+// changed since the generation of the since query parameter, in the text
+// exposition format, for consumers polling the metrics frequently. The
+// generation of the served metrics is returned in the
+// X-Kube-State-Metrics-Generation header, and increases whenever the metrics
+// changed since the previous request. Families which were removed are listed
+// in the X-Kube-State-Metrics-Removed-Families header. If since is missing or
+// too old, all families are served, which is signaled by the
+// X-Kube-State-Metrics-Delta header being "full" instead of "incremental".
+func (m *MetricsHandler) DeltaHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		families, err := m.Snapshot(r.Context())
+		if err != nil {
+			klog.ErrorS(err, "Failed to build metrics delta")
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		var since *uint64
+		if s := r.URL.Query().Get("since"); s != "" {
+			g, err := strconv.ParseUint(s, 10, 64)
+			if err != nil {
+				http.Error(w, "invalid since parameter: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			since = &g
+		}
+
+		generation, updates, full := m.delta(since, families)
+		w.Header().Set("Content-Type", string(expfmt.NewFormat(expfmt.TypeTextPlain)))
+		w.Header().Set(generationHeader, strconv.FormatUint(generation, 10))
+		if full {
+			w.Header().Set(deltaHeader, "full")
+		} else {
+			w.Header().Set(deltaHeader, "incremental")
+		}
+		var removed []string
+		bw := bufio.NewWriter(w)
+		for _, u := range updates {
+			if u.Removed {
+				removed = append(removed, u.Name)
+				continue
+			}
+			writeFamily(bw, u.MetricFamily)
+		}
+		if len(removed) > 0 {
+			sort.Strings(removed)
+			w.Header().Set(removedFamiliesHeader, strings.Join(removed, ","))
+		}
+		if err := bw.Flush(); err != nil {
+			klog.ErrorS(err, "Failed to write metrics delta")
+		}
+	})
+}
+
+// delta records the families as a new generation if they changed since the
+// latest one, and returns the current generation and the updates since the
+// given generation. If the generation is nil or no longer known, all
+// families are returned and full is true.
+func (m *MetricsHandler) delta(since *uint64, families []MetricFamily) (generation uint64, updates []FamilyUpdate, full bool) {
+	m.deltaMtx.Lock()
+	defer m.deltaMtx.Unlock()
+
+	var latest deltaGeneration
+	if n := len(m.deltaGenerations); n > 0 {
+		latest = m.deltaGenerations[n-1]
+	}
+	changed, sums := diffFamilies(latest.sums, families)
+	if latest.sums == nil || len(changed) > 0 {
+		latest = deltaGeneration{generation: latest.generation + 1, sums: sums}
+		m.deltaGenerations = append(m.deltaGenerations, latest)
+		if len(m.deltaGenerations) > deltaHistory {
+			m.deltaGenerations = m.deltaGenerations[len(m.deltaGenerations)-deltaHistory:]
+		}
+	}
+
+	if since != nil {
+		for _, g := range m.deltaGenerations {
+			if g.generation == *since {
+				updates, _ = diffFamilies(g.sums, families)
+				return latest.generation, updates, false
+			}
+		}
+	}
+	updates, _ = diffFamilies(nil, families)
+	return latest.generation, updates, true
+}
+
+// writeFamily writes the family in the text exposition format.
+func writeFamily(w *bufio.Writer, f MetricFamily) {
+	if f.Help != "" || f.Type != "" {
+		w.WriteString("# HELP " + f.Name + " " + f.Help + "\n")
+		w.WriteString("# TYPE " + f.Name + " " + string(f.Type) + "\n")
+	}
+	w.Write(metric.Family{Name: f.Name, Metrics: f.Metrics}.ByteSlice())
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"k8s.io/kube-state-metrics/v2/pkg/metric"
+	metricsstore "k8s.io/kube-state-metrics/v2/pkg/metrics_store"
+)
+
+func TestDeltaHandler(t *testing.T) {
+	newStore := func(header string, name string) *metricsstore.MetricsStore {
+		return metricsstore.NewMetricsStore([]string{header}, func(obj interface{}) []metric.FamilyInterface {
+			pod := obj.(*v1.Pod)
+			return []metric.FamilyInterface{&metric.Family{Name: name, Metrics: []*metric.Metric{
+				{LabelKeys: []string{"pod"}, LabelValues: []string{pod.Name}, Value: 1},
+			}}}
+		})
+	}
+	info := newStore("# HELP kube_pod_info Information about pod.\n# TYPE kube_pod_info gauge", "kube_pod_info")
+	owner := newStore("# HELP kube_pod_owner Information about the owner of the pod.\n# TYPE kube_pod_owner gauge", "kube_pod_owner")
+	for _, s := range []*metricsstore.MetricsStore{info, owner} {
+		if err := s.Add(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "a", UID: "a"}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m := &MetricsHandler{mtx: &sync.RWMutex{}, metricsWriters: metricsstore.MetricsWriterList{
+		metricsstore.NewMetricsWriter(info),
+		metricsstore.NewMetricsWriter(owner),
+	}}
+	get := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		m.DeltaHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics/delta"+query, nil))
+		return rec
+	}
+	check := func(rec *httptest.ResponseRecorder, generation, delta, removed, body string) {
+		t.Helper()
+		if got := rec.Header().Get(generationHeader); got != generation {
+			t.Errorf("expected generation %s, got %s", generation, got)
+		}
+		if got := rec.Header().Get(deltaHeader); got != delta {
+			t.Errorf("expected delta %s, got %s", delta, got)
+		}
+		if got := rec.Header().Get(removedFamiliesHeader); got != removed {
+			t.Errorf("expected removed families %q, got %q", removed, got)
+		}
+		if got := rec.Body.String(); got != body {
+			t.Errorf("expected body\n%s\ngot\n%s", body, got)
+		}
+	}
+
+	all := `# HELP kube_pod_info Information about pod.
+# TYPE kube_pod_info gauge
+kube_pod_info{pod="a"} 1
+# HELP kube_pod_owner Information about the owner of the pod.
+# TYPE kube_pod_owner gauge
+kube_pod_owner{pod="a"} 1
+`
+	check(get(""), "1", "full", "", all)
+	check(get("?since=1"), "1", "incremental", "", "")
+
+	if err := info.Add(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "b", UID: "b"}}); err != nil {
+		t.Fatal(err)
+	}
+	rec := get("?since=1")
+	if got := rec.Header().Get(generationHeader); got != "2" {
+		t.Errorf("expected generation 2, got %s", got)
+	}
+	// The series of a family are not ordered.
+	if body := rec.Body.String(); !strings.Contains(body, `kube_pod_info{pod="b"} 1`) || strings.Contains(body, "kube_pod_owner") {
+		t.Errorf("expected only the changed family, got\n%s", body)
+	}
+
+	if err := owner.Delete(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "a", UID: "a"}}); err != nil {
+		t.Fatal(err)
+	}
+	check(get("?since=2"), "3", "incremental", "kube_pod_owner", "")
+
+	// Unknown generations require a full resync.
+	rec = get("?since=42")
+	if got := rec.Header().Get(deltaHeader); got != "full" {
+		t.Errorf("expected a full resync for an unknown generation, got %s", got)
+	}
+	if rec := get("?since=x"); rec.Code != 400 {
+		t.Errorf("expected an invalid since parameter to be rejected, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+)
+
+// exemplarSeparator separates the exemplar from the sample of a metric.
+var exemplarSeparator = []byte(" # {")
+
+// exemplarStripper removes the exemplars of the metrics written to it, which
+// are only supported by OpenMetrics. Writes must consist of complete lines,
+// as written by the MetricsWriters.
+type exemplarStripper struct {
+	w io.Writer
+}
+
+func (s exemplarStripper) Write(p []byte) (int, error) {
+	if !bytes.Contains(p, exemplarSeparator) {
+		return s.w.Write(p)
+	}
+	out := make([]byte, 0, len(p))
+	for _, line := range bytes.SplitAfter(p, []byte("\n")) {
+		out = append(out, stripExemplar(line)...)
+	}
+	if _, err := s.w.Write(out); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// stripExemplar removes the exemplar from the line of a metric. Label values
+// which contain the separator are kept, as the sample before the exemplar
+// must end in a number.
+func stripExemplar(line []byte) []byte {
+	i := bytes.LastIndex(line, exemplarSeparator)
+	if i < 0 || line[0] == '#' {
+		return line
+	}
+	sample := line[:i]
+	if _, err := strconv.ParseFloat(string(sample[bytes.LastIndexByte(sample, ' ')+1:]), 64); err != nil {
+		return line
+	}
+	if bytes.HasSuffix(line, []byte("\n")) {
+		return append(sample[:i:i], '\n')
+	}
+	return sample
+}
+
+// exemplars returns whether metrics carry exemplars.
+func (m *MetricsHandler) exemplars() bool {
+	return m.opts != nil && len(m.opts.ExemplarFamilies) > 0
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"k8s.io/kube-state-metrics/v2/pkg/metric"
+	metricsstore "k8s.io/kube-state-metrics/v2/pkg/metrics_store"
+	"k8s.io/kube-state-metrics/v2/pkg/options"
+)
+
+func TestStripExemplar(t *testing.T) {
+	for line, want := range map[string]string{
+		"kube_a 1 # {uid=\"x\"} 1\n":                        "kube_a 1\n",
+		"kube_a{b=\"c\"} 1 # {} 1":                          "kube_a{b=\"c\"} 1",
+		"kube_a{b=\"c\"} 1 1700000000000 # {uid=\"x\"} 1\n": "kube_a{b=\"c\"} 1 1700000000000\n",
+		"kube_a{b=\"c # {d\"} 1\n":                          "kube_a{b=\"c # {d\"} 1\n",
+		"# HELP kube_a Help # {x} 1\n":                      "# HELP kube_a Help # {x} 1\n",
+	} {
+		if got := string(stripExemplar([]byte(line))); got != want {
+			t.Errorf("expected %q for %q, got %q", want, line, got)
+		}
+	}
+}
+
+func TestExemplarsOnlyServedWithOpenMetrics(t *testing.T) {
+	store := metricsstore.NewMetricsStore([]string{"# HELP kube_pod_restarts_total Restarts.\n# TYPE kube_pod_restarts_total counter"}, func(obj interface{}) []metric.FamilyInterface {
+		return []metric.FamilyInterface{&metric.Family{Name: "kube_pod_restarts_total", Metrics: []*metric.Metric{
+			{Value: 3, Exemplar: &metric.Exemplar{LabelKeys: []string{"uid"}, LabelValues: []string{"a"}, Value: 3}},
+		}}}
+	})
+	if err := store.Add(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", UID: "a"}}); err != nil {
+		t.Fatal(err)
+	}
+	opts := options.NewOptions()
+	opts.ExemplarFamilies = options.MetricSet{"kube_pod_restarts_total": {}}
+	m := New(opts, nil, nil, false)
+	m.metricsWriters = metricsstore.MetricsWriterList{metricsstore.NewMetricsWriter(store)}
+
+	if out := scrape(m); !strings.Contains(out, "kube_pod_restarts_total 3\n") {
+		t.Errorf("expected the exemplar to be removed from the text format, got %q", out)
+	}
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	req.Header.Set("Accept", "application/openmetrics-text; version=1.0.0")
+	m.ServeHTTP(rec, req)
+	if out := rec.Body.String(); !strings.Contains(out, `kube_pod_restarts_total 3 # {uid="a"} 3`) {
+		t.Errorf("expected the exemplar to be served with OpenMetrics, got %q", out)
+	}
+}
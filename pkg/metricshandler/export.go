@@ -0,0 +1,184 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	metricsstore "k8s.io/kube-state-metrics/v2/pkg/metrics_store"
+)
+
+const (
+	exportFilePrefix = "metrics-"
+	exportFileSuffix = ".prom.gz"
+	// exportTimeFormat is the format of the timestamps in the names of the
+	// exported files, which sort in the order of the timestamps.
+	exportTimeFormat = "20060102T150405.000Z"
+)
+
+// FileExporter periodically writes the metrics served on /metrics to
+// timestamped, gzipped files in the text exposition format, e.g. to ship
+// them out-of-band from air-gapped clusters. Old files are removed according
+// to the retention and the maximum number of files.
+type FileExporter struct {
+	handler *MetricsHandler
+	dir     string
+	// retention is the age after which files are removed. 0 keeps files
+	// regardless of their age.
+	retention time.Duration
+	// maxFiles is the number of files which are kept. 0 keeps all files.
+	maxFiles int
+
+	now func() time.Time
+}
+
+// NewFileExporter returns an exporter which writes the metrics of the handler
+// to the directory dir.
+func NewFileExporter(m *MetricsHandler, dir string, retention time.Duration, maxFiles int) *FileExporter {
+	return &FileExporter{
+		handler:   m,
+		dir:       filepath.Clean(dir),
+		retention: retention,
+		maxFiles:  maxFiles,
+		now:       time.Now,
+	}
+}
+
+// Run exports the metrics every interval until the context is done.
+func (e *FileExporter) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := e.export(); err != nil {
+				klog.ErrorS(err, "Failed to export metrics", "fileExportDir", e.dir)
+			}
+			if err := e.rotate(); err != nil {
+				klog.ErrorS(err, "Failed to remove old metrics exports", "fileExportDir", e.dir)
+			}
+		}
+	}
+}
+
+// export writes the current metrics to a new file. The file only appears
+// under its final name once it is complete.
+func (e *FileExporter) export() error {
+	if err := os.MkdirAll(e.dir, 0o750); err != nil {
+		return err
+	}
+	name := exportFilePrefix + e.now().UTC().Format(exportTimeFormat) + exportFileSuffix
+	f, err := os.CreateTemp(e.dir, "."+name+".*")
+	if err != nil {
+		return err
+	}
+	err = e.write(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), filepath.Join(e.dir, name))
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+	klog.V(4).InfoS("Exported metrics", "file", filepath.Join(e.dir, name))
+	return nil
+}
+
+// write writes the gzipped metrics to w.
+func (e *FileExporter) write(w io.Writer) error {
+	gz := gzip.NewWriter(w)
+	if err := e.handler.writeText(gz); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// writeText writes all metrics served on /metrics to w in the text
+// exposition format.
+func (m *MetricsHandler) writeText(w io.Writer) error {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+	m.metricsWriters = metricsstore.SanitizeHeaders(m.metricsWriters)
+	if m.exemplars() {
+		w = exemplarStripper{w: w}
+	}
+	if m.deterministicOutput() {
+		return m.metricsWriters.WriteAllSorted(w)
+	}
+	for _, mw := range m.metricsWriters {
+		if err := mw.WriteAll(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rotate removes the exported files which are older than the retention and
+// the oldest files beyond the maximum number of files.
+func (e *FileExporter) rotate() error {
+	entries, err := os.ReadDir(e.dir)
+	if err != nil {
+		return err
+	}
+	type export struct {
+		name string
+		time time.Time
+	}
+	var exports []export
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, exportFilePrefix) || !strings.HasSuffix(name, exportFileSuffix) {
+			continue
+		}
+		t, err := time.Parse(exportTimeFormat, strings.TrimSuffix(strings.TrimPrefix(name, exportFilePrefix), exportFileSuffix))
+		if err != nil {
+			// Files which weren't written by the exporter are kept.
+			continue
+		}
+		exports = append(exports, export{name: name, time: t})
+	}
+	// Newest first.
+	sort.Slice(exports, func(i, j int) bool {
+		return exports[i].time.After(exports[j].time)
+	})
+
+	now := e.now()
+	for i, x := range exports {
+		expired := e.retention > 0 && now.Sub(x.time) > e.retention
+		if !expired && (e.maxFiles <= 0 || i < e.maxFiles) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(e.dir, x.name)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"k8s.io/kube-state-metrics/v2/pkg/metric"
+	metricsstore "k8s.io/kube-state-metrics/v2/pkg/metrics_store"
+	"k8s.io/kube-state-metrics/v2/pkg/options"
+)
+
+func TestFileExporter(t *testing.T) {
+	s := metricsstore.NewMetricsStore([]string{"# HELP kube_pod_info Information about pod.\n# TYPE kube_pod_info gauge"}, func(interface{}) []metric.FamilyInterface {
+		return []metric.FamilyInterface{&metric.Family{Name: "kube_pod_info", Metrics: []*metric.Metric{{Value: 1}}}}
+	})
+	if err := s.Add(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", UID: "a"}}); err != nil {
+		t.Fatal(err)
+	}
+	m := New(options.NewOptions(), nil, nil, false)
+	m.metricsWriters = metricsstore.MetricsWriterList{metricsstore.NewResourceMetricsWriter("pods", s)}
+
+	dir := t.TempDir()
+	// Files not written by the exporter are kept.
+	if err := os.WriteFile(filepath.Join(dir, "README"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2023, 10, 15, 10, 0, 0, 0, time.UTC)
+	now := start
+	e := NewFileExporter(m, dir, 3*time.Minute, 2)
+	e.now = func() time.Time { return now }
+
+	for i := 0; i < 5; i++ {
+		if err := e.export(); err != nil {
+			t.Fatal(err)
+		}
+		now = now.Add(time.Minute)
+	}
+	if err := e.rotate(); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"README", "metrics-20231015T100300.000Z.prom.gz", "metrics-20231015T100400.000Z.prom.gz"}
+	if got := dirNames(t, dir); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected files %v after rotation by count, got %v", expected, got)
+	}
+
+	// Files older than the retention are removed.
+	now = start.Add(6*time.Minute + 30*time.Second)
+	if err := e.rotate(); err != nil {
+		t.Fatal(err)
+	}
+	expected = []string{"README", "metrics-20231015T100400.000Z.prom.gz"}
+	if got := dirNames(t, dir); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected files %v after rotation by age, got %v", expected, got)
+	}
+
+	f, err := os.Open(filepath.Join(dir, expected[1]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exported, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out := scrape(m); string(exported) != out {
+		t.Errorf("expected the served metrics %q to be exported, got %q", out, exported)
+	}
+}
+
+func dirNames(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"google.golang.org/grpc/status"
+
+	streamapi "k8s.io/kube-state-metrics/v2/pkg/streamapi/v1alpha1"
+)
+
+// StreamServer serves the MetricsStream gRPC service, which streams the
+// updates of StreamMetrics to subscribers in other processes.
+type StreamServer struct {
+	streamapi.UnimplementedMetricsStreamServer
+	m        *MetricsHandler
+	interval time.Duration
+}
+
+// NewStreamServer returns a StreamServer streaming the metrics of the given
+// MetricsHandler, checking for changes every interval.
+func NewStreamServer(m *MetricsHandler, interval time.Duration) *StreamServer {
+	return &StreamServer{m: m, interval: interval}
+}
+
+// StreamMetrics implements the StreamMetrics RPC. Updates of families not
+// requested by the subscriber are left out, and responses without updates
+// are not sent.
+func (s *StreamServer) StreamMetrics(req *streamapi.StreamMetricsRequest, stream streamapi.MetricsStream_StreamMetricsServer) error {
+	var requested map[string]struct{}
+	if len(req.GetFamilies()) > 0 {
+		requested = make(map[string]struct{}, len(req.GetFamilies()))
+		for _, name := range req.GetFamilies() {
+			requested[name] = struct{}{}
+		}
+	}
+
+	err := s.m.StreamMetrics(stream.Context(), s.interval, func(updates []FamilyUpdate) error {
+		resp := &streamapi.StreamMetricsResponse{}
+		for _, u := range updates {
+			if _, ok := requested[u.Name]; requested != nil && !ok {
+				continue
+			}
+			resp.Updates = append(resp.Updates, familyUpdateToProto(u))
+		}
+		if len(resp.Updates) == 0 {
+			return nil
+		}
+		return stream.Send(resp)
+	})
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+	return err
+}
+
+// familyUpdateToProto converts the update to its message of the
+// MetricsStream service.
+func familyUpdateToProto(u FamilyUpdate) *streamapi.FamilyUpdate {
+	f := &streamapi.FamilyUpdate{
+		Name:    u.Name,
+		Help:    u.Help,
+		Type:    string(u.Type),
+		Removed: u.Removed,
+		Metrics: make([]*streamapi.Metric, 0, len(u.Metrics)),
+	}
+	for _, m := range u.Metrics {
+		labels := make([]*streamapi.Label, 0, len(m.LabelKeys))
+		for i, k := range m.LabelKeys {
+			labels = append(labels, &streamapi.Label{Name: k, Value: m.LabelValues[i]})
+		}
+		f.Metrics = append(f.Metrics, &streamapi.Metric{Labels: labels, Value: m.Value})
+	}
+	return f
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"k8s.io/kube-state-metrics/v2/pkg/metric"
+	metricsstore "k8s.io/kube-state-metrics/v2/pkg/metrics_store"
+	streamapi "k8s.io/kube-state-metrics/v2/pkg/streamapi/v1alpha1"
+)
+
+func TestStreamServer(t *testing.T) {
+	s := metricsstore.NewMetricsStore([]string{
+		"# HELP kube_pod_info Information about pod.\n# TYPE kube_pod_info gauge",
+		"# HELP kube_pod_created Unix creation timestamp.\n# TYPE kube_pod_created gauge",
+	}, func(obj interface{}) []metric.FamilyInterface {
+		pod := obj.(*v1.Pod)
+		return []metric.FamilyInterface{
+			&metric.Family{Name: "kube_pod_info", Metrics: []*metric.Metric{
+				{LabelKeys: []string{"pod"}, LabelValues: []string{pod.Name}, Value: 1},
+			}},
+			&metric.Family{Name: "kube_pod_created", Metrics: []*metric.Metric{
+				{LabelKeys: []string{"pod"}, LabelValues: []string{pod.Name}, Value: 1500000000},
+			}},
+		}
+	})
+	if err := s.Add(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "a", UID: "a"}}); err != nil {
+		t.Fatal(err)
+	}
+	m := &MetricsHandler{mtx: &sync.RWMutex{}, metricsWriters: metricsstore.MetricsWriterList{metricsstore.NewMetricsWriter(s)}}
+
+	l := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	streamapi.RegisterMetricsStreamServer(srv, NewStreamServer(m, time.Millisecond))
+	go srv.Serve(l) //nolint:errcheck
+	defer srv.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return l.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	stream, err := streamapi.NewMetricsStreamClient(conn).StreamMetrics(ctx, &streamapi.StreamMetricsRequest{Families: []string{"kube_pod_info"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Updates) != 1 || resp.Updates[0].Name != "kube_pod_info" || resp.Updates[0].Type != string(metric.Gauge) {
+		t.Fatalf("expected the requested family only, got %v", resp.Updates)
+	}
+	if got := resp.Updates[0].Metrics; len(got) != 1 || got[0].Value != 1 || got[0].Labels[0].Name != "pod" || got[0].Labels[0].Value != "a" {
+		t.Fatalf("expected the metric of pod a, got %v", got)
+	}
+
+	if err := s.Add(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "b", UID: "b"}}); err != nil {
+		t.Fatal(err)
+	}
+	resp, err = stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Updates) != 1 || len(resp.Updates[0].Metrics) != 2 {
+		t.Fatalf("expected the updated family, got %v", resp.Updates)
+	}
+}
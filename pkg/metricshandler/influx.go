@@ -0,0 +1,111 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"k8s.io/kube-state-metrics/v2/pkg/metric"
+)
+
+var (
+	// influxMeasurementEscaper escapes measurements of the line protocol.
+	influxMeasurementEscaper = strings.NewReplacer(`,`, `\,`, ` `, `\ `, "\n", `\n`)
+	// influxTagEscaper escapes tag keys and values of the line protocol.
+	influxTagEscaper = strings.NewReplacer(`,`, `\,`, `=`, `\=`, ` `, `\ `, "\n", `\n`)
+)
+
+// InfluxHandler returns a http.Handler serving the metrics served on
+// /metrics in the InfluxDB line protocol, so that Telegraf can consume them
+// without a Prometheus in between. Like Telegraf's Prometheus input, each
+// metric family is a measurement with the labels as tags and the value as
+// field named after the type of the family, i.e. counter or gauge. All lines
+// carry the time of the request.
+func (m *MetricsHandler) InfluxHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		families, err := m.Snapshot(r.Context())
+		if err != nil {
+			klog.ErrorS(err, "Failed to render metrics in the Influx line protocol")
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if err := writeInflux(w, families, time.Now()); err != nil {
+			klog.ErrorS(err, "Failed to write metrics in the Influx line protocol")
+		}
+	})
+}
+
+// writeInflux writes the families to w in the InfluxDB line protocol with the
+// given timestamp. Metrics whose value can't be represented, i.e. NaN and
+// infinite values, are skipped, as well as labels with empty values.
+func writeInflux(w io.Writer, families []MetricFamily, now time.Time) error {
+	bw := bufio.NewWriter(w)
+	timestamp := strconv.FormatInt(now.UnixNano(), 10)
+	for _, f := range families {
+		field := string(metric.Gauge)
+		if f.Type == metric.Counter {
+			field = string(metric.Counter)
+		}
+		measurement := influxMeasurementEscaper.Replace(f.Name)
+		for _, s := range f.Metrics {
+			if math.IsNaN(s.Value) || math.IsInf(s.Value, 0) {
+				continue
+			}
+			bw.WriteString(measurement)
+			for _, i := range sortedLabels(s) {
+				if s.LabelValues[i] == "" {
+					continue
+				}
+				bw.WriteByte(',')
+				bw.WriteString(influxTagEscaper.Replace(s.LabelKeys[i]))
+				bw.WriteByte('=')
+				bw.WriteString(influxTagEscaper.Replace(s.LabelValues[i]))
+			}
+			bw.WriteByte(' ')
+			bw.WriteString(field)
+			bw.WriteByte('=')
+			bw.WriteString(strconv.FormatFloat(s.Value, 'g', -1, 64))
+			bw.WriteByte(' ')
+			bw.WriteString(timestamp)
+			bw.WriteByte('\n')
+		}
+	}
+	return bw.Flush()
+}
+
+// sortedLabels returns the indexes of the labels of the metric in the order
+// of their keys, which InfluxDB recommends for tags.
+func sortedLabels(s *metric.Metric) []int {
+	indexes := make([]int, len(s.LabelKeys))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return s.LabelKeys[indexes[i]] < s.LabelKeys[indexes[j]]
+	})
+	return indexes
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/kube-state-metrics/v2/pkg/metric"
+)
+
+func TestWriteInflux(t *testing.T) {
+	families := []MetricFamily{
+		{
+			Name: "kube_pod_info",
+			Type: metric.Gauge,
+			Metrics: []*metric.Metric{
+				{LabelKeys: []string{"pod", "namespace", "node"}, LabelValues: []string{"a b", "default", ""}, Value: 1},
+				{LabelKeys: []string{"pod", "namespace"}, LabelValues: []string{"c,d=e", "default"}, Value: math.NaN()},
+			},
+		},
+		{
+			Name: "kube_pod_container_status_restarts_total",
+			Type: metric.Counter,
+			Metrics: []*metric.Metric{
+				{LabelKeys: []string{"pod"}, LabelValues: []string{"c,d=e"}, Value: 3},
+			},
+		},
+	}
+	var out strings.Builder
+	if err := writeInflux(&out, families, time.Unix(1697364000, 0)); err != nil {
+		t.Fatal(err)
+	}
+	expected := `kube_pod_info,namespace=default,pod=a\ b gauge=1 1697364000000000000
+kube_pod_container_status_restarts_total,pod=c\,d\=e counter=3 1697364000000000000
+`
+	if out.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// errQueueTimeout is returned for scrapes which waited longer than the queue
+// timeout.
+var errQueueTimeout = errors.New("timed out waiting for the scrapes in flight to finish")
+
+// ScrapeLimiter limits the number of concurrently served scrapes, so that
+// overlapping scrapes of many clients don't multiply the memory usage.
+// Scrapes beyond the limit are queued until a scrape finishes, and rejected
+// with 503 Service Unavailable if they waited longer than the queue timeout.
+type ScrapeLimiter struct {
+	slots        chan struct{}
+	queueTimeout time.Duration
+
+	inFlight prometheus.Gauge
+	queued   prometheus.Gauge
+	rejected prometheus.Counter
+}
+
+// NewScrapeLimiter returns a ScrapeLimiter which serves at most limit scrapes
+// concurrently and queues further scrapes for at most queueTimeout.
+func NewScrapeLimiter(limit int, queueTimeout time.Duration, r prometheus.Registerer) *ScrapeLimiter {
+	return &ScrapeLimiter{
+		slots:        make(chan struct{}, limit),
+		queueTimeout: queueTimeout,
+		inFlight: promauto.With(r).NewGauge(prometheus.GaugeOpts{
+			Name: "kube_state_metrics_scrapes_in_flight",
+			Help: "Number of scrapes which are currently served.",
+		}),
+		queued: promauto.With(r).NewGauge(prometheus.GaugeOpts{
+			Name: "kube_state_metrics_scrapes_queued",
+			Help: "Number of scrapes which wait for the scrapes in flight to finish.",
+		}),
+		rejected: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Name: "kube_state_metrics_scrapes_rejected_total",
+			Help: "Number of scrapes which were rejected because they waited longer than the queue timeout.",
+		}),
+	}
+}
+
+// Handler returns a handler which serves requests to handler within the
+// limit of l. A nil ScrapeLimiter doesn't limit the requests.
+func (l *ScrapeLimiter) Handler(handler http.Handler) http.Handler {
+	if l == nil {
+		return handler
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := l.acquire(r.Context()); err != nil {
+			// Canceled requests are not answered, the client is gone.
+			if errors.Is(err, errQueueTimeout) {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(l.queueTimeout.Seconds()))))
+				http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			}
+			return
+		}
+		defer l.release()
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// acquire waits for a free slot. It returns errQueueTimeout if the queue
+// timeout expires first, and the error of ctx if it is done first.
+func (l *ScrapeLimiter) acquire(ctx context.Context) error {
+	select {
+	case l.slots <- struct{}{}:
+		l.inFlight.Inc()
+		return nil
+	default:
+	}
+
+	l.queued.Inc()
+	defer l.queued.Dec()
+	timer := time.NewTimer(l.queueTimeout)
+	defer timer.Stop()
+	select {
+	case l.slots <- struct{}{}:
+		l.inFlight.Inc()
+		return nil
+	case <-timer.C:
+		l.rejected.Inc()
+		return errQueueTimeout
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *ScrapeLimiter) release() {
+	l.inFlight.Dec()
+	<-l.slots
+}
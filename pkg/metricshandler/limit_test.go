@@ -0,0 +1,83 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestScrapeLimiter(t *testing.T) {
+	l := NewScrapeLimiter(1, 50*time.Millisecond, prometheus.NewRegistry())
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	h := l.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/block" {
+			close(started)
+			<-unblock
+		}
+	}))
+
+	blocked := make(chan int)
+	go func() {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/block", nil))
+		blocked <- rec.Code
+	}()
+	<-started
+
+	// The slot is taken, so the scrape is rejected once the queue timeout
+	// expires.
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status %d, got %d", http.StatusServiceUnavailable, rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "1" {
+		t.Errorf("expected Retry-After 1, got %q", got)
+	}
+	if got := testutil.ToFloat64(l.rejected); got != 1 {
+		t.Errorf("expected 1 rejected scrape, got %v", got)
+	}
+
+	// A queued scrape is served once the scrape in flight finishes.
+	l.queueTimeout = time.Minute
+	queued := make(chan int)
+	go func() {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+		queued <- rec.Code
+	}()
+	for testutil.ToFloat64(l.queued) != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	close(unblock)
+	if code := <-blocked; code != http.StatusOK {
+		t.Errorf("expected status %d for the blocking scrape, got %d", http.StatusOK, code)
+	}
+	if code := <-queued; code != http.StatusOK {
+		t.Errorf("expected status %d for the queued scrape, got %d", http.StatusOK, code)
+	}
+	if got := testutil.ToFloat64(l.inFlight); got != 0 {
+		t.Errorf("expected no scrapes in flight, got %v", got)
+	}
+}
@@ -17,7 +17,6 @@ limitations under the License.
 package metricshandler
 
 import (
-	"compress/gzip"
 	"context"
 	"errors"
 	"fmt"
@@ -26,7 +25,9 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/expfmt"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -41,6 +42,15 @@ import (
 	"k8s.io/kube-state-metrics/v2/pkg/options"
 )
 
+const (
+	// storeSyncTimeout is the maximum time rebuilt stores are synced in the
+	// background before they replace the served stores.
+	storeSyncTimeout = 5 * time.Minute
+	// storeSyncPollInterval is the interval at which rebuilt stores are
+	// checked for being synced.
+	storeSyncPollInterval = 100 * time.Millisecond
+)
+
 // MetricsHandler is a http.Handler that exposes the main kube-state-metrics
 // /metrics endpoint. It allows concurrent reconfiguration at runtime.
 type MetricsHandler struct {
@@ -49,13 +59,30 @@ type MetricsHandler struct {
 	storeBuilder       ksmtypes.BuilderInterface
 	enableGZIPEncoding bool
 
-	cancel func()
+	// buildMtx serializes building the stores, and protects ctx.
+	buildMtx sync.Mutex
+	// ctx is the context the served stores were built for.
+	ctx              context.Context
+	storeSyncTimeout time.Duration
 
-	// mtx protects metricsWriters, curShard, and curTotalShards
+	// mtx protects cancel, metricsWriters, sinks, selfMetrics, curShard,
+	// and curTotalShards
 	mtx            *sync.RWMutex
+	cancel         func()
 	metricsWriters metricsstore.MetricsWriterList
+	sinks          []Sink
+	selfMetrics    prometheus.Gatherer
 	curShard       int32
 	curTotalShards int
+
+	// schemaMtx protects schema and schemaTime
+	schemaMtx  sync.Mutex
+	schema     []byte
+	schemaTime time.Time
+
+	// deltaMtx protects deltaGenerations
+	deltaMtx         sync.Mutex
+	deltaGenerations []deltaGeneration
 }
 
 // New creates and returns a new MetricsHandler with the given options.
@@ -65,6 +92,7 @@ func New(opts *options.Options, kubeClient kubernetes.Interface, storeBuilder ks
 		kubeClient:         kubeClient,
 		storeBuilder:       storeBuilder,
 		enableGZIPEncoding: enableGZIPEncoding,
+		storeSyncTimeout:   storeSyncTimeout,
 		mtx:                &sync.RWMutex{},
 	}
 }
@@ -72,21 +100,115 @@ func New(opts *options.Options, kubeClient kubernetes.Interface, storeBuilder ks
 // ConfigureSharding (re-)configures sharding. Re-configuration can be done
 // concurrently.
 func (m *MetricsHandler) ConfigureSharding(ctx context.Context, shard int32, totalShards int) {
-	m.mtx.Lock()
-	defer m.mtx.Unlock()
+	m.buildMtx.Lock()
+	defer m.buildMtx.Unlock()
 
-	if m.cancel != nil {
-		m.cancel()
-	}
 	if totalShards != 1 {
 		klog.InfoS("Configuring sharding of this instance to be shard index (zero-indexed) out of total shards", "shard", shard, "totalShards", totalShards)
 	}
-	ctx, m.cancel = context.WithCancel(ctx)
+	m.buildStores(ctx, shard, totalShards)
+}
+
+// Rebuild rebuilds the stores with the current configuration of the store
+// builder, e.g. after the enabled resources changed. The served stores are
+// only replaced once the new stores have synced, so that scrapes do not
+// return partial data in the meantime. Rebuild does nothing until sharding
+// was configured, as the stores are built with the current configuration
+// then.
+func (m *MetricsHandler) Rebuild() {
+	m.buildMtx.Lock()
+	defer m.buildMtx.Unlock()
+
+	if m.ctx == nil || m.ctx.Err() != nil {
+		return
+	}
+	m.mtx.RLock()
+	shard, totalShards := m.curShard, m.curTotalShards
+	m.mtx.RUnlock()
+	m.buildStores(m.ctx, shard, totalShards)
+}
+
+// Restart stops the served stores and rebuilds them with the current
+// configuration of the store builder right away. Unlike Rebuild, the served
+// and the new stores never coexist, which keeps the memory usage low, but
+// scrapes return partial data until the new stores have synced.
+func (m *MetricsHandler) Restart() {
+	m.buildMtx.Lock()
+	defer m.buildMtx.Unlock()
+
+	if m.ctx == nil || m.ctx.Err() != nil {
+		return
+	}
+	m.mtx.Lock()
+	if m.cancel != nil {
+		m.cancel()
+	}
+	m.cancel = nil
+	m.metricsWriters = nil
+	shard, totalShards := m.curShard, m.curTotalShards
+	m.mtx.Unlock()
+	m.buildStores(m.ctx, shard, totalShards)
+}
+
+// buildStores builds the stores for the given sharding configuration and
+// swaps them with the served stores. If stores are served already, the new
+// stores are synced in the background first, for up to storeSyncTimeout.
+// The served stores are stopped after the swap.
+func (m *MetricsHandler) buildStores(ctx context.Context, shard int32, totalShards int) {
+	storesCtx, cancel := context.WithCancel(ctx)
 	m.storeBuilder.WithSharding(shard, totalShards)
-	m.storeBuilder.WithContext(ctx)
-	m.metricsWriters = m.storeBuilder.Build()
+	m.storeBuilder.WithContext(storesCtx)
+	metricsWriters := m.storeBuilder.Build()
+
+	m.mtx.RLock()
+	serving := m.cancel != nil
+	m.mtx.RUnlock()
+	if serving {
+		if err := waitForSync(storesCtx, metricsWriters, m.storeSyncTimeout); err != nil {
+			if storesCtx.Err() != nil {
+				cancel()
+				return
+			}
+			klog.ErrorS(err, "Serving stores which have not synced yet")
+		}
+	}
+
+	m.mtx.Lock()
+	oldCancel := m.cancel
+	m.cancel = cancel
+	m.metricsWriters = metricsWriters
 	m.curShard = shard
 	m.curTotalShards = totalShards
+	m.mtx.Unlock()
+	m.ctx = ctx
+
+	if oldCancel != nil {
+		oldCancel()
+	}
+}
+
+// HasSynced returns whether the stores were built and have synced.
+func (m *MetricsHandler) HasSynced() bool {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+	return m.cancel != nil && m.metricsWriters.HasSynced()
+}
+
+// waitForSync waits until all stores of the writers have synced, the context
+// is done or the timeout expired.
+func waitForSync(ctx context.Context, metricsWriters metricsstore.MetricsWriterList, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	t := time.NewTicker(storeSyncPollInterval)
+	defer t.Stop()
+	for !metricsWriters.HasSynced() {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for stores to sync: %w", ctx.Err())
+		case <-t.C:
+		}
+	}
+	return nil
 }
 
 // Run configures the MetricsHandler's sharding and if autosharding is enabled
@@ -180,49 +302,71 @@ func (m *MetricsHandler) Run(ctx context.Context) error {
 }
 
 // ServeHTTP implements the http.Handler interface. It writes all generated
-// metrics to the response body.
+// metrics to the response body, except for the metrics of the resources
+// listed in the comma-separated exclude query parameters, which are named
+// like for ResourcesHandler.
 func (m *MetricsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	m.mtx.RLock()
 	defer m.mtx.RUnlock()
-	resHeader := w.Header()
-	var writer io.Writer = w
+	// m.metricsWriters
+	//MetricsWriter 是一个接口，它定义了写入指标数据的方法。MetricsWriterList 则是一个包含多个 MetricsWriter 对象的列表。
+	//在这个上下文中，m.metricsWriters 被用于在 HTTP 请求处理过程中，将生成的指标数据写入 HTTP 响应。
+	m.metricsWriters = metricsstore.SanitizeHeaders(m.metricsWriters)
+	exclude := r.URL.Query()["exclude"]
+	var sinks []Sink
+	if len(exclude) == 0 {
+		sinks = m.sinks
+	}
+	m.writeMetrics(w, r, excludeResources(m.metricsWriters, exclude), m.selfMetrics, sinks...)
+}
 
+// writeMetrics writes the metrics of the given writers, followed by the self
+// metrics of the gatherer if it isn't nil, in the exposition format
+// negotiated with the request to the response and the given sinks.
+func (m *MetricsHandler) writeMetrics(w http.ResponseWriter, r *http.Request, metricsWriters metricsstore.MetricsWriterList, selfMetrics prometheus.Gatherer, sinks ...Sink) {
 	contentType := expfmt.NegotiateIncludingOpenMetrics(r.Header)
 
 	// We do not support protobuf at the moment. Fall back to FmtText if the negotiated exposition format is not FmtOpenMetrics See: https://github.com/kubernetes/kube-state-metrics/issues/2022
-	if contentType != expfmt.FmtOpenMetrics_1_0_0 && contentType != expfmt.FmtOpenMetrics_0_0_1 {
-		contentType = expfmt.FmtText
+	if contentType.FormatType() != expfmt.TypeOpenMetrics {
+		contentType = expfmt.NewFormat(expfmt.TypeTextPlain)
 	}
-	resHeader.Set("Content-Type", string(contentType))
-
-	if m.enableGZIPEncoding {
-		// Gzip response if requested. Taken from
-		// github.com/prometheus/client_golang/prometheus/promhttp.decorateWriter.
-		reqHeader := r.Header.Get("Accept-Encoding")
-		parts := strings.Split(reqHeader, ",")
-		for _, part := range parts {
-			part = strings.TrimSpace(part)
-			if part == "gzip" || strings.HasPrefix(part, "gzip;") {
-				writer = gzip.NewWriter(writer)
-				resHeader.Set("Content-Encoding", "gzip")
-			}
-		}
+
+	writer, err := openSinks(contentType, responseSink{w: w, r: r, gzip: m.enableGZIPEncoding}, sinks)
+	if err != nil {
+		klog.ErrorS(err, "Failed to open the response")
+		return
 	}
 
-	// m.metricsWriters
-	//MetricsWriter 是一个接口，它定义了写入指标数据的方法。MetricsWriterList 则是一个包含多个 MetricsWriter 对象的列表。
-	//在这个上下文中，m.metricsWriters 被用于在 HTTP 请求处理过程中，将生成的指标数据写入 HTTP 响应。
-	m.metricsWriters = metricsstore.SanitizeHeaders(m.metricsWriters)
-	for _, w := range m.metricsWriters {
-		// write result to w
-		err := w.WriteAll(writer)
-		if err != nil {
+	var out io.Writer = writer
+	if contentType.FormatType() == expfmt.TypeTextPlain && m.exemplars() {
+		out = exemplarStripper{w: writer}
+	}
+	if m.deterministicOutput() {
+		if err := metricsWriters.WriteAllSorted(out); err != nil {
 			klog.ErrorS(err, "Failed to write metrics")
 		}
+	} else {
+		for _, w := range metricsWriters {
+			// write result to w
+			err := w.WriteAll(out)
+			if err != nil {
+				klog.ErrorS(err, "Failed to write metrics")
+			}
+		}
+	}
+	if selfMetrics != nil {
+		if err := writeSelfMetrics(writer, selfMetrics, contentType); err != nil {
+			klog.ErrorS(err, "Failed to write self metrics")
+		}
+	}
+	families := 0
+	for _, w := range metricsWriters {
+		families += w.FamilyCount()
 	}
+	recordFamilies(r, families)
 
 	// OpenMetrics spec requires that we end with an EOF directive.
-	if contentType == expfmt.FmtOpenMetrics_1_0_0 || contentType == expfmt.FmtOpenMetrics_0_0_1 {
+	if contentType.FormatType() == expfmt.TypeOpenMetrics {
 		_, err := writer.Write([]byte("# EOF\n"))
 		if err != nil {
 			klog.ErrorS(err, "Failed to write EOF directive")
@@ -230,14 +374,17 @@ func (m *MetricsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// In case we gzipped the response, we have to close the writer.
-	if closer, ok := writer.(io.Closer); ok {
-		err := closer.Close()
-		if err != nil {
-			klog.ErrorS(err, "Failed to close the writer")
-		}
+	if err := writer.Close(); err != nil {
+		klog.ErrorS(err, "Failed to close the writer")
 	}
 }
 
+// deterministicOutput returns whether families and series are written
+// sorted.
+func (m *MetricsHandler) deterministicOutput() bool {
+	return m.opts != nil && m.opts.DeterministicOutput
+}
+
 func shardingSettingsFromStatefulSet(ss *appsv1.StatefulSet, podName string) (nominal int32, totalReplicas int, err error) {
 	nominal, err = detectNominalFromPod(ss.Name, podName)
 	if err != nil {
@@ -0,0 +1,166 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ksmtypes "k8s.io/kube-state-metrics/v2/pkg/builder/types"
+	"k8s.io/kube-state-metrics/v2/pkg/metric"
+	metricsstore "k8s.io/kube-state-metrics/v2/pkg/metrics_store"
+	"k8s.io/kube-state-metrics/v2/pkg/options"
+)
+
+// fakeBuilder builds one store per call of Build, whose metrics are named
+// after the number of the build.
+type fakeBuilder struct {
+	ksmtypes.BuilderInterface
+	mtx    sync.Mutex
+	ctx    context.Context
+	ctxs   []context.Context
+	stores []*metricsstore.MetricsStore
+}
+
+// build returns the store and the context of the build with the given number.
+func (b *fakeBuilder) build(i int) (*metricsstore.MetricsStore, context.Context) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return b.stores[i], b.ctxs[i]
+}
+
+func (b *fakeBuilder) WithSharding(int32, int) {}
+
+func (b *fakeBuilder) WithContext(ctx context.Context) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	b.ctx = ctx
+}
+
+func (b *fakeBuilder) Build() metricsstore.MetricsWriterList {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	name := []string{"kube_first", "kube_second"}[len(b.stores)]
+	s := metricsstore.NewMetricsStore([]string{"# TYPE " + name + " gauge"}, func(interface{}) []metric.FamilyInterface {
+		return []metric.FamilyInterface{&metric.Family{Name: name, Metrics: []*metric.Metric{{Value: 1}}}}
+	})
+	b.stores = append(b.stores, s)
+	b.ctxs = append(b.ctxs, b.ctx)
+	return metricsstore.MetricsWriterList{metricsstore.NewMetricsWriter(s)}
+}
+
+func scrape(m *MetricsHandler) string {
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	return rec.Body.String()
+}
+
+func TestRebuildSwapsSyncedStores(t *testing.T) {
+	b := &fakeBuilder{}
+	m := New(options.NewOptions(), nil, b, false)
+	object := []interface{}{&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", UID: "a"}}}
+
+	// The first stores are served without waiting for them to sync.
+	m.ConfigureSharding(context.Background(), 0, 1)
+	first, firstCtx := b.build(0)
+	if err := first.Replace(object, ""); err != nil {
+		t.Fatal(err)
+	}
+	if out := scrape(m); !strings.Contains(out, "kube_first 1") {
+		t.Fatalf("expected the first stores to be served, got %q", out)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		m.Rebuild()
+		close(done)
+	}()
+	time.Sleep(3 * storeSyncPollInterval)
+	if out := scrape(m); !strings.Contains(out, "kube_first 1") || strings.Contains(out, "kube_second") {
+		t.Fatalf("expected the first stores to be served until the second stores synced, got %q", out)
+	}
+	if firstCtx.Err() != nil {
+		t.Fatal("expected the first stores to run until the second stores synced")
+	}
+
+	second, secondCtx := b.build(1)
+	if err := second.Replace(object, ""); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-done:
+	case <-time.After(time.Minute):
+		t.Fatal("expected Rebuild to return once the second stores synced")
+	}
+	if out := scrape(m); !strings.Contains(out, "kube_second 1") || strings.Contains(out, "kube_first") {
+		t.Fatalf("expected the second stores to be served, got %q", out)
+	}
+	if firstCtx.Err() == nil {
+		t.Error("expected the first stores to be stopped")
+	}
+	if secondCtx.Err() != nil {
+		t.Error("expected the second stores to run")
+	}
+}
+
+func TestRebuildServesUnsyncedStoresAfterTimeout(t *testing.T) {
+	b := &fakeBuilder{}
+	m := New(options.NewOptions(), nil, b, false)
+	m.storeSyncTimeout = storeSyncPollInterval
+
+	m.ConfigureSharding(context.Background(), 0, 1)
+	m.Rebuild()
+	second, _ := b.build(1)
+	if err := second.Replace([]interface{}{&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", UID: "a"}}}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if out := scrape(m); !strings.Contains(out, "kube_second 1") {
+		t.Fatalf("expected the second stores to be served after the timeout, got %q", out)
+	}
+}
+
+func TestRestartStopsServedStoresFirst(t *testing.T) {
+	b := &fakeBuilder{}
+	m := New(options.NewOptions(), nil, b, false)
+
+	m.ConfigureSharding(context.Background(), 0, 1)
+	_, firstCtx := b.build(0)
+	m.Restart()
+	if firstCtx.Err() == nil {
+		t.Error("expected the first stores to be stopped")
+	}
+	second, secondCtx := b.build(1)
+	if secondCtx.Err() != nil {
+		t.Error("expected the second stores to run")
+	}
+	if m.HasSynced() {
+		t.Error("expected the restarted stores not to have synced yet")
+	}
+	if err := second.Replace([]interface{}{&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", UID: "a"}}}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if out := scrape(m); !strings.Contains(out, "kube_second 1") {
+		t.Fatalf("expected the second stores to be served, got %q", out)
+	}
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"k8s.io/klog/v2"
+)
+
+// SyncProgress is the progress of the initial lists of the stores.
+type SyncProgress struct {
+	// Synced is whether the stores were built and all of them have synced.
+	Synced bool `json:"synced"`
+	// Resources is the progress of each served resource, sorted by name.
+	Resources []ResourceSyncProgress `json:"resources"`
+}
+
+// ResourceSyncProgress is the progress of the initial lists of the stores of
+// a resource.
+type ResourceSyncProgress struct {
+	// Resource is the name of the resource, named like for ResourcesHandler.
+	Resource string `json:"resource"`
+	// Synced is whether all stores of the resource have synced.
+	Synced bool `json:"synced"`
+	// Objects is the number of objects in the stores, including the objects
+	// of aggregated metric families. The objects of a list are only stored
+	// once the list is complete.
+	Objects int `json:"objects"`
+}
+
+// SyncProgress returns the progress of the initial lists of the stores.
+func (m *MetricsHandler) SyncProgress() SyncProgress {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	progress := SyncProgress{
+		Synced:    m.cancel != nil && m.metricsWriters.HasSynced(),
+		Resources: []ResourceSyncProgress{},
+	}
+	index := map[string]int{}
+	for _, mw := range m.metricsWriters {
+		if mw.Resource() == "" {
+			continue
+		}
+		name := resourcePathName(mw.Resource())
+		i, ok := index[name]
+		if !ok {
+			i = len(progress.Resources)
+			index[name] = i
+			progress.Resources = append(progress.Resources, ResourceSyncProgress{Resource: name, Synced: true})
+		}
+		progress.Resources[i].Synced = progress.Resources[i].Synced && mw.HasSynced()
+		progress.Resources[i].Objects += mw.Usage().Objects
+	}
+	sort.Slice(progress.Resources, func(i, j int) bool {
+		return progress.Resources[i].Resource < progress.Resources[j].Resource
+	})
+	return progress
+}
+
+// StartupHandler returns a http.Handler serving the SyncProgress as JSON. It
+// responds with 503 Service Unavailable until the stores have synced, so that
+// it can be used as startup probe.
+func (m *MetricsHandler) StartupHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		progress := m.SyncProgress()
+		w.Header().Set("Content-Type", "application/json")
+		if !progress.Synced {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		if err := json.NewEncoder(w).Encode(progress); err != nil {
+			klog.ErrorS(err, "Failed to write sync progress")
+		}
+	})
+}
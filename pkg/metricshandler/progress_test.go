@@ -0,0 +1,57 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"k8s.io/kube-state-metrics/v2/pkg/options"
+)
+
+func TestSyncProgress(t *testing.T) {
+	b := &resourcesBuilder{resources: []string{"secrets", "pods", "samplecontroller.k8s.io/v1alpha1, Resource=foos"}}
+	m := New(options.NewOptions(), nil, b, false)
+
+	w := httptest.NewRecorder()
+	m.StartupHandler().ServeHTTP(w, httptest.NewRequest("GET", "/startupz", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status %d before the stores were built, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+
+	m.ConfigureSharding(context.Background(), 0, 1)
+	want := SyncProgress{
+		Synced: true,
+		Resources: []ResourceSyncProgress{
+			{Resource: "foos.samplecontroller.k8s.io", Synced: true, Objects: 1},
+			{Resource: "pods", Synced: true, Objects: 1},
+			{Resource: "secrets", Synced: true, Objects: 1},
+		},
+	}
+	if got := m.SyncProgress(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the progress %+v, got %+v", want, got)
+	}
+
+	w = httptest.NewRecorder()
+	m.StartupHandler().ServeHTTP(w, httptest.NewRequest("GET", "/startupz", nil))
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("expected a JSON response with status %d after the stores synced, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+}
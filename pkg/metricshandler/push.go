@@ -0,0 +1,124 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/expfmt"
+	"k8s.io/klog/v2"
+)
+
+// pushTimeout is the timeout of a push to the Pushgateway.
+const pushTimeout = 30 * time.Second
+
+// Pusher periodically pushes the metrics served on /metrics to a Prometheus
+// Pushgateway, for clusters which Prometheus can't scrape. The metrics
+// replace the group of the job, the instance and the current shard, so that
+// the shards of an instance don't overwrite each other.
+type Pusher struct {
+	handler  *MetricsHandler
+	url      string
+	job      string
+	instance string
+	client   *http.Client
+}
+
+// NewPusher returns a pusher which pushes the metrics of the handler to the
+// Pushgateway at url, grouped by the job and the instance.
+func NewPusher(m *MetricsHandler, url, job, instance string) *Pusher {
+	return &Pusher{
+		handler:  m,
+		url:      strings.TrimSuffix(url, "/"),
+		job:      job,
+		instance: instance,
+		client:   &http.Client{Timeout: pushTimeout},
+	}
+}
+
+// Run pushes the metrics every interval until the context is done.
+func (p *Pusher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := p.push(ctx); err != nil {
+				klog.ErrorS(err, "Failed to push metrics to the Pushgateway", "url", p.url)
+			}
+		}
+	}
+}
+
+// push replaces the metrics of the group of the pusher with the current
+// metrics.
+func (p *Pusher) push(ctx context.Context) error {
+	var buf bytes.Buffer
+	if err := p.handler.writeText(&buf); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, p.groupURL(), &buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", string(expfmt.NewFormat(expfmt.TypeTextPlain)))
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected status %s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+	klog.V(4).InfoS("Pushed metrics to the Pushgateway", "url", p.url)
+	return nil
+}
+
+// groupURL returns the URL of the group of the pusher, with the shard the
+// handler currently serves.
+func (p *Pusher) groupURL() string {
+	p.handler.mtx.RLock()
+	shard := p.handler.curShard
+	p.handler.mtx.RUnlock()
+
+	return p.url + "/metrics" +
+		groupingLabel("job", p.job) +
+		groupingLabel("instance", p.instance) +
+		groupingLabel("shard", strconv.Itoa(int(shard)))
+}
+
+// groupingLabel returns the path segments of a grouping label of the
+// Pushgateway. Values are base64 encoded, so that they may contain slashes,
+// with empty values encoded as "=".
+func groupingLabel(name, value string) string {
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(value))
+	if encoded == "" {
+		encoded = "="
+	}
+	return "/" + name + "@base64/" + encoded
+}
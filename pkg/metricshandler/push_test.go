@@ -0,0 +1,72 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"k8s.io/kube-state-metrics/v2/pkg/metric"
+	metricsstore "k8s.io/kube-state-metrics/v2/pkg/metrics_store"
+	"k8s.io/kube-state-metrics/v2/pkg/options"
+)
+
+func TestPusher(t *testing.T) {
+	s := metricsstore.NewMetricsStore([]string{"# HELP kube_pod_info Information about pod.\n# TYPE kube_pod_info gauge"}, func(interface{}) []metric.FamilyInterface {
+		return []metric.FamilyInterface{&metric.Family{Name: "kube_pod_info", Metrics: []*metric.Metric{{Value: 1}}}}
+	})
+	if err := s.Add(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", UID: "a"}}); err != nil {
+		t.Fatal(err)
+	}
+	m := New(options.NewOptions(), nil, nil, false)
+	m.metricsWriters = metricsstore.MetricsWriterList{metricsstore.NewResourceMetricsWriter("pods", s)}
+	m.curShard = 2
+
+	var method, path, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		method, path, body = r.Method, r.URL.Path, string(b)
+	}))
+	defer server.Close()
+
+	p := NewPusher(m, server.URL+"/", "kube-state-metrics", "node/a")
+	if err := p.push(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if method != http.MethodPut {
+		t.Errorf("expected the group to be replaced with PUT, got %s", method)
+	}
+	if expected := "/metrics/job@base64/a3ViZS1zdGF0ZS1tZXRyaWNz/instance@base64/bm9kZS9h/shard@base64/Mg"; path != expected {
+		t.Errorf("expected path %s, got %s", expected, path)
+	}
+	if out := scrape(m); body != out {
+		t.Errorf("expected the served metrics %q to be pushed, got %q", out, body)
+	}
+
+	if got := groupingLabel("instance", ""); got != "/instance@base64/=" {
+		t.Errorf("expected empty values to be encoded as =, got %s", got)
+	}
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	metricsstore "k8s.io/kube-state-metrics/v2/pkg/metrics_store"
+)
+
+// ResourcesHandler returns a http.Handler serving the metrics of a single
+// resource on prefix followed by the name of the resource, e.g.
+// /metrics/resources/pods, so that large resources can be scraped separately
+// with a longer timeout. Custom resources are named <resource>.<group>. On
+// prefix itself, it serves the sorted names of the served resources as JSON.
+func (m *MetricsHandler) ResourcesHandler(prefix string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(r.URL.Path, prefix)
+
+		m.mtx.RLock()
+		defer m.mtx.RUnlock()
+
+		if name == "" {
+			names := make([]string, 0, len(m.metricsWriters))
+			for _, mw := range m.metricsWriters {
+				if mw.Resource() != "" {
+					names = append(names, resourcePathName(mw.Resource()))
+				}
+			}
+			sort.Strings(names)
+			w.Header().Set("Content-Type", "application/json")
+			if err := json.NewEncoder(w).Encode(names); err != nil {
+				klog.ErrorS(err, "Failed to write resources")
+			}
+			return
+		}
+
+		var metricsWriters metricsstore.MetricsWriterList
+		for _, mw := range m.metricsWriters {
+			if mw.Resource() != "" && resourcePathName(mw.Resource()) == name {
+				metricsWriters = append(metricsWriters, mw)
+			}
+		}
+		if len(metricsWriters) == 0 {
+			http.Error(w, "resource "+name+" is not served", http.StatusNotFound)
+			return
+		}
+		m.writeMetrics(w, r, metricsWriters, nil)
+	})
+}
+
+// resourcePathName returns the name of the resource in the path of its
+// endpoint. Custom resources, whose stores are named after their
+// GroupVersionResource, are named <resource>.<group>.
+func resourcePathName(resource string) string {
+	gv, name, ok := strings.Cut(resource, ", Resource=")
+	if !ok {
+		return resource
+	}
+	group, _, _ := strings.Cut(gv, "/")
+	if group == "" {
+		return name
+	}
+	return name + "." + group
+}
+
+// excludeResources returns the writers whose resources are not listed in the
+// given comma-separated lists of resources.
+func excludeResources(metricsWriters metricsstore.MetricsWriterList, exclude []string) metricsstore.MetricsWriterList {
+	if len(exclude) == 0 {
+		return metricsWriters
+	}
+	excluded := map[string]struct{}{}
+	for _, list := range exclude {
+		for _, name := range strings.Split(list, ",") {
+			excluded[strings.TrimSpace(name)] = struct{}{}
+		}
+	}
+	filtered := make(metricsstore.MetricsWriterList, 0, len(metricsWriters))
+	for _, mw := range metricsWriters {
+		if _, ok := excluded[resourcePathName(mw.Resource())]; !ok || mw.Resource() == "" {
+			filtered = append(filtered, mw)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ksmtypes "k8s.io/kube-state-metrics/v2/pkg/builder/types"
+	"k8s.io/kube-state-metrics/v2/pkg/metric"
+	metricsstore "k8s.io/kube-state-metrics/v2/pkg/metrics_store"
+	"k8s.io/kube-state-metrics/v2/pkg/options"
+)
+
+// resourcesBuilder builds one store per resource, whose metric is named after
+// the resource.
+type resourcesBuilder struct {
+	ksmtypes.BuilderInterface
+	resources []string
+}
+
+func (b *resourcesBuilder) WithSharding(int32, int) {}
+
+func (b *resourcesBuilder) WithContext(context.Context) {}
+
+func (b *resourcesBuilder) Build() metricsstore.MetricsWriterList {
+	var writers metricsstore.MetricsWriterList
+	for _, resource := range b.resources {
+		name := "kube_" + strings.NewReplacer(".", "_", "/", "_", ", Resource=", "_").Replace(resource)
+		s := metricsstore.NewMetricsStore([]string{"# TYPE " + name + " gauge"}, func(interface{}) []metric.FamilyInterface {
+			return []metric.FamilyInterface{&metric.Family{Name: name, Metrics: []*metric.Metric{{Value: 1}}}}
+		})
+		if err := s.Replace([]interface{}{&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", UID: "a"}}}, ""); err != nil {
+			panic(err)
+		}
+		writers = append(writers, metricsstore.NewResourceMetricsWriter(resource, s))
+	}
+	return writers
+}
+
+func TestResourcesHandler(t *testing.T) {
+	b := &resourcesBuilder{resources: []string{"pods", "secrets", "samplecontroller.k8s.io/v1alpha1, Resource=foos"}}
+	m := New(options.NewOptions(), nil, b, false)
+	m.ConfigureSharding(context.Background(), 0, 1)
+	handler := m.ResourcesHandler("/metrics/resources/")
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	if w := get("/metrics/resources/"); strings.TrimSpace(w.Body.String()) != `["foos.samplecontroller.k8s.io","pods","secrets"]` {
+		t.Errorf("unexpected resources %s", w.Body.String())
+	}
+
+	w := get("/metrics/resources/pods")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "kube_pods 1") || strings.Contains(w.Body.String(), "kube_secrets") {
+		t.Errorf("expected only the metrics of pods, got %d: %s", w.Code, w.Body.String())
+	}
+	w = get("/metrics/resources/foos.samplecontroller.k8s.io")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "kube_samplecontroller_k8s_io_v1alpha1_foos 1") {
+		t.Errorf("expected the metrics of the custom resource, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := get("/metrics/resources/nodes"); w.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 for a resource which is not served, got %d", w.Code)
+	}
+}
+
+func TestServeHTTPExclude(t *testing.T) {
+	b := &resourcesBuilder{resources: []string{"pods", "secrets", "configmaps"}}
+	m := New(options.NewOptions(), nil, b, false)
+	m.ConfigureSharding(context.Background(), 0, 1)
+
+	out := scrape(m)
+	for _, want := range []string{"kube_pods 1", "kube_secrets 1", "kube_configmaps 1"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %s without exclusions, got:\n%s", want, out)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, httptest.NewRequest("GET", "/metrics?exclude=pods,secrets", nil))
+	if out := w.Body.String(); !strings.Contains(out, "kube_configmaps 1") || strings.Contains(out, "kube_pods") || strings.Contains(out, "kube_secrets") {
+		t.Errorf("expected only the metrics of configmaps, got:\n%s", out)
+	}
+}
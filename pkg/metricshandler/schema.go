@@ -0,0 +1,140 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/expfmt"
+	"k8s.io/klog/v2"
+
+	metricsstore "k8s.io/kube-state-metrics/v2/pkg/metrics_store"
+)
+
+// schemaMaxAge is the duration the schema is cached for, as building it
+// requires rendering all metrics.
+const schemaMaxAge = time.Minute
+
+// Schema is the catalog of the metric families which are currently served.
+type Schema struct {
+	Families []FamilySchema `json:"families"`
+}
+
+// FamilySchema describes a served metric family.
+type FamilySchema struct {
+	Name      string   `json:"name"`
+	Type      string   `json:"type"`
+	Help      string   `json:"help"`
+	LabelKeys []string `json:"labelKeys"`
+}
+
+// Schema returns the JSON encoded Schema of the metric families which are
+// currently served.
+func (m *MetricsHandler) Schema() ([]byte, error) {
+	m.schemaMtx.Lock()
+	defer m.schemaMtx.Unlock()
+
+	if m.schema != nil && time.Since(m.schemaTime) < schemaMaxAge {
+		return m.schema, nil
+	}
+
+	var buf bytes.Buffer
+	var out io.Writer = &buf
+	if m.exemplars() {
+		out = exemplarStripper{w: &buf}
+	}
+	m.mtx.RLock()
+	for _, w := range metricsstore.SanitizeHeaders(m.metricsWriters) {
+		if err := w.WriteAll(out); err != nil {
+			m.mtx.RUnlock()
+			return nil, err
+		}
+	}
+	m.mtx.RUnlock()
+
+	schema, err := buildSchema(&buf)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+	m.schema, m.schemaTime = data, time.Now()
+
+	return data, nil
+}
+
+// SchemaHandler returns a http.Handler serving the Schema.
+func (m *MetricsHandler) SchemaHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		schema, err := m.Schema()
+		if err != nil {
+			klog.ErrorS(err, "Failed to build metrics schema")
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := w.Write(schema); err != nil {
+			klog.ErrorS(err, "Failed to write metrics schema")
+		}
+	})
+}
+
+// buildSchema parses the given metrics in the Prometheus text format and
+// returns their Schema.
+func buildSchema(metrics *bytes.Buffer) (*Schema, error) {
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(metrics)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse metrics: %w", err)
+	}
+
+	schema := &Schema{Families: make([]FamilySchema, 0, len(families))}
+	for name, f := range families {
+		keys := map[string]struct{}{}
+		for _, metric := range f.GetMetric() {
+			for _, l := range metric.GetLabel() {
+				keys[l.GetName()] = struct{}{}
+			}
+		}
+		labelKeys := make([]string, 0, len(keys))
+		for k := range keys {
+			labelKeys = append(labelKeys, k)
+		}
+		sort.Strings(labelKeys)
+
+		schema.Families = append(schema.Families, FamilySchema{
+			Name:      name,
+			Type:      strings.ToLower(f.GetType().String()),
+			Help:      f.GetHelp(),
+			LabelKeys: labelKeys,
+		})
+	}
+	sort.Slice(schema.Families, func(i, j int) bool {
+		return schema.Families[i].Name < schema.Families[j].Name
+	})
+
+	return schema, nil
+}
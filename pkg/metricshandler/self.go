@@ -0,0 +1,59 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"io"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+)
+
+// selfMetricsPrefix is the prefix of the self metrics written to /metrics.
+const selfMetricsPrefix = "kube_state_metrics_"
+
+// SetSelfMetrics sets the gatherer of the self metrics, which are written to
+// the response of each scrape of /metrics after the metrics of the stores.
+// Families which aren't prefixed with kube_state_metrics_, like the Go and
+// process metrics, are prefixed with it, so that they can't be confused with
+// the metrics of other targets.
+func (m *MetricsHandler) SetSelfMetrics(g prometheus.Gatherer) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.selfMetrics = g
+}
+
+// writeSelfMetrics writes the prefixed families of the gatherer to w in the
+// given exposition format.
+func writeSelfMetrics(w io.Writer, g prometheus.Gatherer, format expfmt.Format) error {
+	families, err := g.Gather()
+	if err != nil {
+		return err
+	}
+	enc := expfmt.NewEncoder(w, format)
+	for _, f := range families {
+		if !strings.HasPrefix(f.GetName(), selfMetricsPrefix) {
+			name := selfMetricsPrefix + f.GetName()
+			f.Name = &name
+		}
+		if err := enc.Encode(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
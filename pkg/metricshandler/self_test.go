@@ -0,0 +1,75 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"k8s.io/kube-state-metrics/v2/pkg/metric"
+	metricsstore "k8s.io/kube-state-metrics/v2/pkg/metrics_store"
+	"k8s.io/kube-state-metrics/v2/pkg/options"
+)
+
+func TestSelfMetrics(t *testing.T) {
+	s := metricsstore.NewMetricsStore([]string{"# HELP kube_pod_info Information about pod.\n# TYPE kube_pod_info gauge"}, func(interface{}) []metric.FamilyInterface {
+		return []metric.FamilyInterface{&metric.Family{Name: "kube_pod_info", Metrics: []*metric.Metric{{Value: 1}}}}
+	})
+	if err := s.Add(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", UID: "a"}}); err != nil {
+		t.Fatal(err)
+	}
+	m := New(options.NewOptions(), nil, nil, false)
+	m.metricsWriters = metricsstore.MetricsWriterList{metricsstore.NewResourceMetricsWriter("pods", s)}
+
+	registry := prometheus.NewRegistry()
+	promauto.With(registry).NewCounter(prometheus.CounterOpts{Name: "kube_state_metrics_watch_total", Help: "Watches."}).Inc()
+	promauto.With(registry).NewGauge(prometheus.GaugeOpts{Name: "go_goroutines", Help: "Goroutines."}).Set(3)
+
+	if out := scrape(m); strings.Contains(out, "kube_state_metrics_") {
+		t.Fatalf("expected no self metrics without a gatherer, got %q", out)
+	}
+
+	m.SetSelfMetrics(registry)
+	want := `# HELP kube_pod_info Information about pod.
+# TYPE kube_pod_info gauge
+kube_pod_info 1
+# HELP kube_state_metrics_go_goroutines Goroutines.
+# TYPE kube_state_metrics_go_goroutines gauge
+kube_state_metrics_go_goroutines 3
+# HELP kube_state_metrics_watch_total Watches.
+# TYPE kube_state_metrics_watch_total counter
+kube_state_metrics_watch_total 1
+`
+	if out := scrape(m); out != want {
+		t.Errorf("expected the prefixed self metrics after the metrics of the stores, got\n%s", out)
+	}
+
+	// The self metrics are written before the EOF directive of OpenMetrics.
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	req.Header.Set("Accept", "application/openmetrics-text; version=1.0.0")
+	m.ServeHTTP(rec, req)
+	if out := rec.Body.String(); !strings.HasSuffix(out, "kube_state_metrics_watch_total 1.0\n# EOF\n") {
+		t.Errorf("expected the self metrics before the EOF directive, got\n%s", out)
+	}
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+// TargetGroup is a group of scrape targets in the format of the Prometheus
+// HTTP service discovery.
+type TargetGroup struct {
+	Targets []string          `json:"targets"`
+	Labels  map[string]string `json:"labels"`
+}
+
+// Shards returns one TargetGroup per pod of the StatefulSet of this instance,
+// with the address of its metrics server, sorted by shard. It requires
+// autosharding.
+func (m *MetricsHandler) Shards(ctx context.Context) ([]TargetGroup, error) {
+	ss, err := detectStatefulSet(m.kubeClient, m.opts.Pod, m.opts.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("detect StatefulSet: %w", err)
+	}
+	selector, err := metav1.LabelSelectorAsSelector(ss.Spec.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector of StatefulSet %s/%s: %w", ss.Namespace, ss.Name, err)
+	}
+	pods, err := m.kubeClient.CoreV1().Pods(ss.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("list pods of StatefulSet %s/%s: %w", ss.Namespace, ss.Name, err)
+	}
+
+	_, totalShards, err := shardingSettingsFromStatefulSet(ss, m.opts.Pod)
+	if err != nil {
+		return nil, err
+	}
+	type shardGroup struct {
+		shard int32
+		group TargetGroup
+	}
+	var shards []shardGroup
+	for _, p := range pods.Items {
+		if p.Status.PodIP == "" || p.DeletionTimestamp != nil || !metav1.IsControlledBy(&p, ss) {
+			continue
+		}
+		shard, err := detectNominalFromPod(ss.Name, p.Name)
+		if err != nil {
+			continue
+		}
+		shards = append(shards, shardGroup{shard, TargetGroup{
+			Targets: []string{net.JoinHostPort(p.Status.PodIP, strconv.Itoa(m.opts.Port))},
+			Labels: map[string]string{
+				"__meta_kube_state_metrics_namespace":    p.Namespace,
+				"__meta_kube_state_metrics_pod":          p.Name,
+				"__meta_kube_state_metrics_shard":        strconv.Itoa(int(shard)),
+				"__meta_kube_state_metrics_total_shards": strconv.Itoa(totalShards),
+			},
+		}})
+	}
+	sort.Slice(shards, func(i, j int) bool {
+		return shards[i].shard < shards[j].shard
+	})
+	groups := make([]TargetGroup, 0, len(shards))
+	for _, s := range shards {
+		groups = append(groups, s.group)
+	}
+	return groups, nil
+}
+
+// ShardsHandler returns a http.Handler serving the Shards in the format of
+// the Prometheus HTTP service discovery. It responds with 404 Not Found
+// unless autosharding is enabled.
+func (m *MetricsHandler) ShardsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if m.opts.Pod == "" || m.opts.Namespace == "" {
+			http.Error(w, "shard discovery requires autosharding with --pod and --pod-namespace", http.StatusNotFound)
+			return
+		}
+		groups, err := m.Shards(r.Context())
+		if err != nil {
+			klog.ErrorS(err, "Failed to discover shards")
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(groups); err != nil {
+			klog.ErrorS(err, "Failed to write shards")
+		}
+	})
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"k8s.io/kube-state-metrics/v2/pkg/options"
+)
+
+func TestShardsHandler(t *testing.T) {
+	replicas := int32(2)
+	controller := true
+	ss := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "ksm", Namespace: "monitoring", UID: types.UID("ss")},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "ksm"}},
+		},
+	}
+	pod := func(name, ip string) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "monitoring",
+				Labels:    map[string]string{"app": "ksm"},
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: "apps/v1", Kind: "StatefulSet", Name: "ksm", UID: ss.UID, Controller: &controller},
+				},
+			},
+			Status: v1.PodStatus{PodIP: ip},
+		}
+	}
+	kubeClient := fake.NewSimpleClientset(ss, pod("ksm-1", "10.0.0.2"), pod("ksm-0", "10.0.0.1"), pod("ksm-2", ""))
+
+	opts := options.NewOptions()
+	opts.Port = 8080
+	serve := func(opts *options.Options) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		New(opts, kubeClient, nil, false).ShardsHandler().ServeHTTP(w, httptest.NewRequest("GET", "/shards", nil))
+		return w
+	}
+
+	if w := serve(opts); w.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 without autosharding, got %d", w.Code)
+	}
+
+	opts.Pod = "ksm-0"
+	opts.Namespace = "monitoring"
+	w := serve(opts)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var got []TargetGroup
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := []TargetGroup{
+		{Targets: []string{"10.0.0.1:8080"}, Labels: map[string]string{
+			"__meta_kube_state_metrics_namespace":    "monitoring",
+			"__meta_kube_state_metrics_pod":          "ksm-0",
+			"__meta_kube_state_metrics_shard":        "0",
+			"__meta_kube_state_metrics_total_shards": "2",
+		}},
+		{Targets: []string{"10.0.0.2:8080"}, Labels: map[string]string{
+			"__meta_kube_state_metrics_namespace":    "monitoring",
+			"__meta_kube_state_metrics_pod":          "ksm-1",
+			"__meta_kube_state_metrics_shard":        "1",
+			"__meta_kube_state_metrics_total_shards": "2",
+		}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected shards %+v, got %+v", want, got)
+	}
+}
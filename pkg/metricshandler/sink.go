@@ -0,0 +1,198 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/prometheus/common/expfmt"
+	"k8s.io/klog/v2"
+)
+
+// Sink receives the metrics rendered for scrapes of /metrics, so that a
+// single rendering pass serves the scrape and e.g. archives the metrics.
+type Sink interface {
+	// Open returns the writer of the metrics of a scrape, in the exposition
+	// format of the content type. The writer is closed once all metrics were
+	// written, or after it failed to write.
+	Open(contentType expfmt.Format) (io.WriteCloser, error)
+}
+
+// AddSinks adds sinks which receive the metrics of each scrape of /metrics,
+// besides the response. Scrapes which exclude resources are not passed to
+// the sinks.
+func (m *MetricsHandler) AddSinks(sinks ...Sink) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.sinks = append(m.sinks, sinks...)
+}
+
+// responseSink is the sink of the response of a scrape.
+type responseSink struct {
+	w http.ResponseWriter
+	r *http.Request
+	// gzip is whether the response is gzipped if requested.
+	gzip bool
+}
+
+// Open sets the headers of the response and returns the writer of its body.
+func (s responseSink) Open(contentType expfmt.Format) (io.WriteCloser, error) {
+	s.w.Header().Set("Content-Type", string(contentType))
+	if s.gzip {
+		// Gzip response if requested. Taken from
+		// github.com/prometheus/client_golang/prometheus/promhttp.decorateWriter.
+		reqHeader := s.r.Header.Get("Accept-Encoding")
+		parts := strings.Split(reqHeader, ",")
+		for _, part := range parts {
+			part = strings.TrimSpace(part)
+			if part == "gzip" || strings.HasPrefix(part, "gzip;") {
+				s.w.Header().Set("Content-Encoding", "gzip")
+				return gzip.NewWriter(s.w), nil
+			}
+		}
+	}
+	return nopCloser{s.w}, nil
+}
+
+// nopCloser is an io.WriteCloser whose Close does nothing.
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+// fanOutWriter writes to the writers of multiple sinks. Failures of the
+// primary writer, i.e. of the response, are returned, while other writers
+// which fail are logged and skipped for the rest of the scrape, so that they
+// don't affect the response.
+type fanOutWriter struct {
+	primary   io.WriteCloser
+	secondary []io.WriteCloser
+}
+
+// openSinks opens the writers of the sinks, the first one being the primary.
+func openSinks(contentType expfmt.Format, primary Sink, sinks []Sink) (*fanOutWriter, error) {
+	w, err := primary.Open(contentType)
+	if err != nil {
+		return nil, err
+	}
+	f := &fanOutWriter{primary: w}
+	for _, s := range sinks {
+		w, err := s.Open(contentType)
+		if err != nil {
+			klog.ErrorS(err, "Failed to open metrics sink")
+			continue
+		}
+		f.secondary = append(f.secondary, w)
+	}
+	return f, nil
+}
+
+func (f *fanOutWriter) Write(p []byte) (int, error) {
+	for i, w := range f.secondary {
+		if w == nil {
+			continue
+		}
+		if _, err := w.Write(p); err != nil {
+			klog.ErrorS(err, "Failed to write metrics to sink, skipping it for the rest of the scrape")
+			if err := w.Close(); err != nil {
+				klog.ErrorS(err, "Failed to close metrics sink")
+			}
+			f.secondary[i] = nil
+		}
+	}
+	return f.primary.Write(p)
+}
+
+// Close closes the writers of all sinks and returns the error of the
+// primary writer.
+func (f *fanOutWriter) Close() error {
+	for _, w := range f.secondary {
+		if w == nil {
+			continue
+		}
+		if err := w.Close(); err != nil {
+			klog.ErrorS(err, "Failed to close metrics sink")
+		}
+	}
+	return f.primary.Close()
+}
+
+// FileSink writes the metrics of each scrape to a file. The previous content
+// of the file is only replaced once all metrics of a scrape were written, so
+// that the file always holds the metrics of a complete scrape.
+type FileSink struct {
+	path string
+}
+
+// NewFileSink returns a sink which writes the metrics of each scrape to the
+// file at path.
+func NewFileSink(path string) *FileSink {
+	return &FileSink{path: filepath.Clean(path)}
+}
+
+// Open creates a temporary file next to the file of the sink, which replaces
+// it once the writer is closed.
+func (s *FileSink) Open(expfmt.Format) (io.WriteCloser, error) {
+	f, err := os.CreateTemp(filepath.Dir(s.path), "."+filepath.Base(s.path)+".*")
+	if err != nil {
+		return nil, err
+	}
+	return &fileSinkWriter{f: f, path: s.path}, nil
+}
+
+// fileSinkWriter writes the metrics of a scrape to a temporary file.
+type fileSinkWriter struct {
+	f    *os.File
+	path string
+	// err is the first error writing to the file.
+	err error
+}
+
+func (w *fileSinkWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	n, err := w.f.Write(p)
+	w.err = err
+	return n, err
+}
+
+// Close replaces the file of the sink with the temporary file, unless
+// writing failed, in which case the temporary file is removed.
+func (w *fileSinkWriter) Close() error {
+	err := w.f.Close()
+	if w.err != nil || err != nil {
+		_ = os.Remove(w.f.Name())
+		if w.err != nil {
+			return w.err
+		}
+		return err
+	}
+	if err := os.Rename(w.f.Name(), w.path); err != nil {
+		_ = os.Remove(w.f.Name())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"errors"
+	"io"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/common/expfmt"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"k8s.io/kube-state-metrics/v2/pkg/metric"
+	metricsstore "k8s.io/kube-state-metrics/v2/pkg/metrics_store"
+	"k8s.io/kube-state-metrics/v2/pkg/options"
+)
+
+// failingSink is a sink whose writers fail to write.
+type failingSink struct{}
+
+func (failingSink) Open(expfmt.Format) (io.WriteCloser, error) {
+	return failingWriter{}, nil
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("failed")
+}
+
+func (failingWriter) Close() error {
+	return nil
+}
+
+func TestSinks(t *testing.T) {
+	s := metricsstore.NewMetricsStore([]string{"# HELP kube_pod_info Information about pod.\n# TYPE kube_pod_info gauge"}, func(interface{}) []metric.FamilyInterface {
+		return []metric.FamilyInterface{&metric.Family{Name: "kube_pod_info", Metrics: []*metric.Metric{{Value: 1}}}}
+	})
+	if err := s.Add(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", UID: "a"}}); err != nil {
+		t.Fatal(err)
+	}
+	m := New(options.NewOptions(), nil, nil, false)
+	m.metricsWriters = metricsstore.MetricsWriterList{metricsstore.NewResourceMetricsWriter("pods", s)}
+	file := filepath.Join(t.TempDir(), "metrics.prom")
+	m.AddSinks(failingSink{}, NewFileSink(file))
+
+	// Failing sinks don't affect the response or the other sinks.
+	out := scrape(m)
+	if !strings.Contains(out, "kube_pod_info 1") {
+		t.Fatalf("expected the metrics to be served, got %q", out)
+	}
+	archived, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(archived) != out {
+		t.Errorf("expected the scrape to be archived, got %q", archived)
+	}
+
+	// Scrapes which exclude resources are not archived.
+	if err := os.Remove(file); err != nil {
+		t.Fatal(err)
+	}
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics?exclude=pods", nil))
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Errorf("expected the scrape excluding resources not to be archived, got %v", err)
+	}
+	entries, err := os.ReadDir(filepath.Dir(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected no temporary files to be left, got %v", entries)
+	}
+}
+
+func TestFileSinkKeepsCompleteScrapes(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "metrics.prom")
+	if err := os.WriteFile(file, []byte("complete\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	w, err := NewFileSink(file).Open(expfmt.NewFormat(expfmt.TypeTextPlain))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("partial")); err != nil {
+		t.Fatal(err)
+	}
+	// Files are only replaced once closed.
+	if b, _ := os.ReadFile(file); string(b) != "complete\n" {
+		t.Errorf("expected the previous scrape until the writer is closed, got %q", b)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := os.ReadFile(file); string(b) != "partial" {
+		t.Errorf("expected the new scrape, got %q", b)
+	}
+}
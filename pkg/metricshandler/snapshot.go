@@ -0,0 +1,115 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/kube-state-metrics/v2/pkg/metric"
+)
+
+// MetricFamily is a metric family of a snapshot of the metrics, see
+// MetricsHandler.Snapshot.
+type MetricFamily struct {
+	Name    string
+	Help    string
+	Type    metric.Type
+	Metrics []*metric.Metric
+}
+
+// Snapshot returns the metrics currently served on /metrics as structured
+// data, for consumers embedding kube-state-metrics. Families are returned in
+// the order they are served, families of different stores with the same name
+// are merged and families without metrics are left out. It returns the error
+// of the context if it is done before the snapshot is complete.
+func (m *MetricsHandler) Snapshot(ctx context.Context) ([]MetricFamily, error) {
+	m.mtx.RLock()
+	metricsWriters := m.metricsWriters
+	m.mtx.RUnlock()
+
+	var families []MetricFamily
+	index := map[string]int{}
+	// headers holds the help and type of each family by name, since headers
+	// of families of multiple stores might be empty, see
+	// metricsstore.SanitizeHeaders.
+	headers := map[string]MetricFamily{}
+	for _, w := range metricsWriters {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		var err error
+		w.VisitFamilies(func(header string, metrics [][]byte) {
+			if err != nil {
+				return
+			}
+			if h, ok := parseHeader(header); ok {
+				if _, ok := headers[h.Name]; !ok {
+					headers[h.Name] = h
+				}
+			}
+			for _, b := range metrics {
+				for _, line := range bytes.Split(bytes.TrimSuffix(b, []byte("\n")), []byte("\n")) {
+					if len(line) == 0 {
+						continue
+					}
+					name, sample, parseErr := metric.ParseMetric(string(line))
+					if parseErr != nil {
+						err = parseErr
+						return
+					}
+					i, ok := index[name]
+					if !ok {
+						i = len(families)
+						index[name] = i
+						families = append(families, MetricFamily{Name: name})
+					}
+					families[i].Metrics = append(families[i].Metrics, sample)
+				}
+			}
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse metrics: %w", err)
+		}
+	}
+	for i := range families {
+		if h, ok := headers[families[i].Name]; ok {
+			families[i].Help, families[i].Type = h.Help, h.Type
+		}
+	}
+	return families, nil
+}
+
+// parseHeader returns the name, help and type of the family of the header
+// written by the stores, which is empty for removed headers.
+func parseHeader(header string) (MetricFamily, bool) {
+	helpLine, typeLine, ok := strings.Cut(header, "\n")
+	if !ok {
+		return MetricFamily{}, false
+	}
+	name, help, ok := strings.Cut(strings.TrimPrefix(helpLine, "# HELP "), " ")
+	if !ok {
+		return MetricFamily{}, false
+	}
+	_, t, ok := strings.Cut(strings.TrimPrefix(typeLine, "# TYPE "), " ")
+	if !ok {
+		return MetricFamily{}, false
+	}
+	return MetricFamily{Name: name, Help: help, Type: metric.Type(t)}, true
+}
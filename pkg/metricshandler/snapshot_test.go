@@ -0,0 +1,79 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"k8s.io/kube-state-metrics/v2/pkg/metric"
+	metricsstore "k8s.io/kube-state-metrics/v2/pkg/metrics_store"
+)
+
+func TestSnapshot(t *testing.T) {
+	newStore := func(header string) *metricsstore.MetricsStore {
+		return metricsstore.NewMetricsStore([]string{header}, func(obj interface{}) []metric.FamilyInterface {
+			pod := obj.(*v1.Pod)
+			return []metric.FamilyInterface{&metric.Family{Name: "kube_pod_info", Metrics: []*metric.Metric{
+				{LabelKeys: []string{"pod", "note"}, LabelValues: []string{pod.Name, `a "quoted"\value`}, Value: 1},
+			}}}
+		})
+	}
+	first := newStore("# HELP kube_pod_info Information about pod.\n# TYPE kube_pod_info gauge")
+	// The header of the second store was removed as a duplicate.
+	second := newStore("")
+	if err := first.Add(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "a", UID: "a"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := second.Add(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "b", UID: "b"}}); err != nil {
+		t.Fatal(err)
+	}
+	m := &MetricsHandler{mtx: &sync.RWMutex{}, metricsWriters: metricsstore.MetricsWriterList{
+		metricsstore.NewMetricsWriter(first),
+		metricsstore.NewMetricsWriter(second),
+	}}
+
+	got, err := m.Snapshot(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []MetricFamily{
+		{
+			Name: "kube_pod_info",
+			Help: "Information about pod.",
+			Type: metric.Gauge,
+			Metrics: []*metric.Metric{
+				{LabelKeys: []string{"pod", "note"}, LabelValues: []string{"a", `a "quoted"\value`}, Value: 1},
+				{LabelKeys: []string{"pod", "note"}, LabelValues: []string{"b", `a "quoted"\value`}, Value: 1},
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := m.Snapshot(ctx); err == nil {
+		t.Error("expected an error for a done context")
+	}
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+// Status is the state of the served stores, as shown on the landing page.
+type Status struct {
+	// Synced is whether the stores were built and all of them have synced.
+	Synced bool
+	// Shard and TotalShards are the sharding of the served stores.
+	Shard       int32
+	TotalShards int
+	// Autosharding is whether the sharding is detected from the StatefulSet
+	// of the pod.
+	Autosharding bool
+	// Resources is the status of each served resource, sorted by name.
+	Resources []ResourceStatus
+}
+
+// ResourceStatus is the status of the stores of a resource.
+type ResourceStatus struct {
+	ResourceSyncProgress
+	// Families is the number of metric families written for the resource,
+	// which is 0 while its stores hold no objects.
+	Families int
+}
+
+// Status returns the state of the served stores.
+func (m *MetricsHandler) Status() Status {
+	progress := m.SyncProgress()
+
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+	status := Status{
+		Synced:       progress.Synced,
+		Shard:        m.curShard,
+		TotalShards:  m.curTotalShards,
+		Autosharding: m.opts != nil && m.opts.Pod != "" && m.opts.Namespace != "",
+		Resources:    make([]ResourceStatus, len(progress.Resources)),
+	}
+	families := map[string]int{}
+	for _, mw := range m.metricsWriters {
+		if mw.Resource() != "" {
+			families[resourcePathName(mw.Resource())] += mw.FamilyCount()
+		}
+	}
+	for i, r := range progress.Resources {
+		status.Resources[i] = ResourceStatus{ResourceSyncProgress: r, Families: families[r.Resource]}
+	}
+	return status
+}
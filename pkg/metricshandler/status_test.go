@@ -0,0 +1,48 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"k8s.io/kube-state-metrics/v2/pkg/options"
+)
+
+func TestStatus(t *testing.T) {
+	b := &resourcesBuilder{resources: []string{"secrets", "pods"}}
+	opts := options.NewOptions()
+	opts.Pod = "kube-state-metrics-1"
+	opts.Namespace = "kube-system"
+	m := New(opts, nil, b, false)
+	m.ConfigureSharding(context.Background(), 1, 2)
+
+	want := Status{
+		Synced:       true,
+		Shard:        1,
+		TotalShards:  2,
+		Autosharding: true,
+		Resources: []ResourceStatus{
+			{ResourceSyncProgress: ResourceSyncProgress{Resource: "pods", Synced: true, Objects: 1}, Families: 1},
+			{ResourceSyncProgress: ResourceSyncProgress{Resource: "secrets", Synced: true, Objects: 1}, Families: 1},
+		},
+	}
+	if got := m.Status(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the status %+v, got %+v", want, got)
+	}
+}
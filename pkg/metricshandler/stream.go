@@ -0,0 +1,110 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"context"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FamilyUpdate is an update of a metric family streamed by
+// MetricsHandler.StreamMetrics.
+type FamilyUpdate struct {
+	MetricFamily
+	// Removed is whether the family is no longer served, in which case it
+	// has no metrics.
+	Removed bool
+}
+
+// StreamMetrics streams incremental updates of the metrics served on
+// /metrics to send, so that consumers embedding kube-state-metrics can react
+// to changes of the state of objects faster than the scrape interval. The
+// first call of send receives all families, and the following calls receive
+// the families whose metrics changed since the previous call, checked every
+// interval. Families are sent as a whole, like in Snapshot. Intervals
+// without changes don't call send. StreamMetrics returns once the context is
+// done or send fails.
+func (m *MetricsHandler) StreamMetrics(ctx context.Context, interval time.Duration, send func([]FamilyUpdate) error) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var sums map[string]uint64
+	for {
+		families, err := m.Snapshot(ctx)
+		if err != nil {
+			return err
+		}
+		var updates []FamilyUpdate
+		updates, sums = diffFamilies(sums, families)
+		if len(updates) > 0 {
+			if err := send(updates); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// diffFamilies returns the updates of the families compared to the families
+// the checksums were computed from, and the checksums of the families. A nil
+// map of checksums results in updates of all families.
+func diffFamilies(sums map[string]uint64, families []MetricFamily) ([]FamilyUpdate, map[string]uint64) {
+	var updates []FamilyUpdate
+	next := make(map[string]uint64, len(families))
+	for _, f := range families {
+		sum := familyChecksum(f)
+		next[f.Name] = sum
+		if prev, ok := sums[f.Name]; ok && prev == sum {
+			continue
+		}
+		updates = append(updates, FamilyUpdate{MetricFamily: f})
+	}
+	for name := range sums {
+		if _, ok := next[name]; !ok {
+			updates = append(updates, FamilyUpdate{MetricFamily: MetricFamily{Name: name}, Removed: true})
+		}
+	}
+	return updates, next
+}
+
+// familyChecksum returns a checksum of the help, type and metrics of the
+// family. The metrics are sorted first, as the order of the series of a
+// family is not stable between snapshots.
+func familyChecksum(f MetricFamily) uint64 {
+	series := make([]string, 0, len(f.Metrics))
+	for _, metric := range f.Metrics {
+		var s strings.Builder
+		metric.Write(&s)
+		series = append(series, s.String())
+	}
+	sort.Strings(series)
+
+	h := fnv.New64a()
+	h.Write([]byte(f.Help + "\n" + string(f.Type) + "\n"))
+	for _, s := range series {
+		h.Write([]byte(s))
+	}
+	return h.Sum64()
+}
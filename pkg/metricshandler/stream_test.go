@@ -0,0 +1,101 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"k8s.io/kube-state-metrics/v2/pkg/metric"
+	metricsstore "k8s.io/kube-state-metrics/v2/pkg/metrics_store"
+)
+
+func TestDiffFamilies(t *testing.T) {
+	info := MetricFamily{Name: "kube_pod_info", Type: metric.Gauge, Metrics: []*metric.Metric{{LabelKeys: []string{"pod"}, LabelValues: []string{"a"}, Value: 1}}}
+	phase := MetricFamily{Name: "kube_pod_status_phase", Type: metric.Gauge, Metrics: []*metric.Metric{{LabelKeys: []string{"phase"}, LabelValues: []string{"Running"}, Value: 1}}}
+
+	updates, sums := diffFamilies(nil, []MetricFamily{info, phase})
+	if got := updateNames(updates); !reflect.DeepEqual(got, []string{"kube_pod_info", "kube_pod_status_phase"}) {
+		t.Fatalf("expected updates of all families initially, got %v", got)
+	}
+
+	updates, sums = diffFamilies(sums, []MetricFamily{info, phase})
+	if len(updates) != 0 {
+		t.Fatalf("expected no updates of unchanged families, got %v", updateNames(updates))
+	}
+
+	changed := phase
+	changed.Metrics = []*metric.Metric{{LabelKeys: []string{"phase"}, LabelValues: []string{"Running"}, Value: 0}}
+	updates, sums = diffFamilies(sums, []MetricFamily{info, changed})
+	if len(updates) != 1 || updates[0].Name != "kube_pod_status_phase" || updates[0].Removed || !reflect.DeepEqual(updates[0].Metrics, changed.Metrics) {
+		t.Fatalf("expected an update of the changed family, got %+v", updates)
+	}
+
+	updates, _ = diffFamilies(sums, []MetricFamily{info})
+	if len(updates) != 1 || updates[0].Name != "kube_pod_status_phase" || !updates[0].Removed {
+		t.Fatalf("expected the removal of the family, got %+v", updates)
+	}
+}
+
+func TestStreamMetrics(t *testing.T) {
+	s := metricsstore.NewMetricsStore([]string{"# HELP kube_pod_info Information about pod.\n# TYPE kube_pod_info gauge"}, func(obj interface{}) []metric.FamilyInterface {
+		pod := obj.(*v1.Pod)
+		return []metric.FamilyInterface{&metric.Family{Name: "kube_pod_info", Metrics: []*metric.Metric{
+			{LabelKeys: []string{"pod"}, LabelValues: []string{pod.Name}, Value: 1},
+		}}}
+	})
+	if err := s.Add(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "a", UID: "a"}}); err != nil {
+		t.Fatal(err)
+	}
+	m := &MetricsHandler{mtx: &sync.RWMutex{}, metricsWriters: metricsstore.MetricsWriterList{metricsstore.NewMetricsWriter(s)}}
+
+	stop := errors.New("stop")
+	var sent [][]FamilyUpdate
+	err := m.StreamMetrics(context.Background(), time.Millisecond, func(updates []FamilyUpdate) error {
+		sent = append(sent, updates)
+		if len(sent) == 1 {
+			return s.Add(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "b", UID: "b"}})
+		}
+		return stop
+	})
+	if !errors.Is(err, stop) {
+		t.Fatalf("expected the error of send, got %v", err)
+	}
+	if len(sent[0]) != 1 || len(sent[0][0].Metrics) != 1 {
+		t.Fatalf("expected the initial family, got %+v", sent[0])
+	}
+	if len(sent[1]) != 1 || len(sent[1][0].Metrics) != 2 {
+		t.Fatalf("expected the updated family, got %+v", sent[1])
+	}
+}
+
+func updateNames(updates []FamilyUpdate) []string {
+	names := make([]string, 0, len(updates))
+	for _, u := range updates {
+		names = append(names, u.Name)
+	}
+	sort.Strings(names)
+	return names
+}
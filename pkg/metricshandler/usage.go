@@ -0,0 +1,182 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/klog/v2"
+
+	metricsstore "k8s.io/kube-state-metrics/v2/pkg/metrics_store"
+)
+
+var (
+	storeStoresDesc = prometheus.NewDesc(
+		"kube_state_metrics_store_reflectors",
+		"Number of stores of the resource, each of which is fed by the goroutines of a reflector.",
+		[]string{"resource"}, nil,
+	)
+	storeObjectsDesc = prometheus.NewDesc(
+		"kube_state_metrics_store_objects",
+		"Number of objects of the resource metrics are cached for.",
+		[]string{"resource"}, nil,
+	)
+	storeSeriesDesc = prometheus.NewDesc(
+		"kube_state_metrics_store_series",
+		"Number of cached series of the resource.",
+		[]string{"resource"}, nil,
+	)
+	storeBytesDesc = prometheus.NewDesc(
+		"kube_state_metrics_store_bytes",
+		"Approximate memory of the cached metrics of the resource in bytes.",
+		[]string{"resource"}, nil,
+	)
+	storeBudgetExceededDesc = prometheus.NewDesc(
+		"kube_state_metrics_store_budget_exceeded",
+		"Whether the approximate memory of the cached metrics of the resource exceeds the store memory budget.",
+		[]string{"resource"}, nil,
+	)
+)
+
+// StoreUsage exposes the approximate footprint of the stores of each served
+// resource and checks it against a soft memory budget per resource, so that
+// single resources dominating the memory usage can be spotted. Exceeding the
+// budget is only logged and exposed, the metrics of the resource are still
+// served. It implements prometheus.Collector.
+type StoreUsage struct {
+	handler *MetricsHandler
+	budget  int64
+
+	mutex sync.Mutex
+	// exceeded holds the resources which exceeded the budget on the last
+	// check.
+	exceeded map[string]struct{}
+}
+
+// NewStoreUsage returns a StoreUsage of the stores served by the handler.
+// The budget is the approximate memory in bytes the metrics of each resource
+// may use, 0 disables it.
+func NewStoreUsage(handler *MetricsHandler, budget int64) *StoreUsage {
+	return &StoreUsage{
+		handler:  handler,
+		budget:   budget,
+		exceeded: map[string]struct{}{},
+	}
+}
+
+// Describe implements the prometheus.Collector interface.
+func (u *StoreUsage) Describe(ch chan<- *prometheus.Desc) {
+	ch <- storeStoresDesc
+	ch <- storeObjectsDesc
+	ch <- storeSeriesDesc
+	ch <- storeBytesDesc
+	if u.budget > 0 {
+		ch <- storeBudgetExceededDesc
+	}
+}
+
+// Collect implements the prometheus.Collector interface.
+func (u *StoreUsage) Collect(ch chan<- prometheus.Metric) {
+	for resource, usage := range u.handler.resourceUsage() {
+		ch <- prometheus.MustNewConstMetric(storeStoresDesc, prometheus.GaugeValue, float64(usage.Stores), resource)
+		ch <- prometheus.MustNewConstMetric(storeObjectsDesc, prometheus.GaugeValue, float64(usage.Objects), resource)
+		ch <- prometheus.MustNewConstMetric(storeSeriesDesc, prometheus.GaugeValue, float64(usage.Series), resource)
+		ch <- prometheus.MustNewConstMetric(storeBytesDesc, prometheus.GaugeValue, float64(usage.Bytes), resource)
+		if u.budget > 0 {
+			ch <- prometheus.MustNewConstMetric(storeBudgetExceededDesc, prometheus.GaugeValue, boolFloat64(usage.Bytes > u.budget), resource)
+		}
+	}
+}
+
+// Run checks the usage of the stores against the budget in the given
+// interval until the context is done.
+func (u *StoreUsage) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		u.check()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// check logs the resources which started or stopped exceeding the budget
+// since the last check. It returns the sorted resources exceeding it.
+func (u *StoreUsage) check() []string {
+	if u.budget <= 0 {
+		return nil
+	}
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	exceeded := map[string]struct{}{}
+	var resources []string
+	for resource, usage := range u.handler.resourceUsage() {
+		if usage.Bytes <= u.budget {
+			continue
+		}
+		exceeded[resource] = struct{}{}
+		resources = append(resources, resource)
+		if _, ok := u.exceeded[resource]; !ok {
+			klog.InfoS("Metrics of the resource exceed the store memory budget", "resource", resource, "bytes", usage.Bytes, "budgetBytes", u.budget, "objects", usage.Objects, "series", usage.Series)
+		}
+	}
+	for resource := range u.exceeded {
+		if _, ok := exceeded[resource]; !ok {
+			klog.InfoS("Metrics of the resource no longer exceed the store memory budget", "resource", resource, "budgetBytes", u.budget)
+		}
+	}
+	u.exceeded = exceeded
+	sort.Strings(resources)
+	return resources
+}
+
+// resourceUsage returns the summed usage of the stores of each served
+// resource, named like for ResourcesHandler.
+func (m *MetricsHandler) resourceUsage() map[string]metricsstore.Usage {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	usage := map[string]metricsstore.Usage{}
+	for _, mw := range m.metricsWriters {
+		if mw.Resource() == "" {
+			continue
+		}
+		name := resourcePathName(mw.Resource())
+		u, wu := usage[name], mw.Usage()
+		u.Stores += wu.Stores
+		u.Objects += wu.Objects
+		u.Series += wu.Series
+		u.Bytes += wu.Bytes
+		usage[name] = u
+	}
+	return usage
+}
+
+func boolFloat64(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
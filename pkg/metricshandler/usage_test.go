@@ -0,0 +1,74 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"k8s.io/kube-state-metrics/v2/pkg/options"
+)
+
+func TestStoreUsage(t *testing.T) {
+	b := &resourcesBuilder{resources: []string{"pods", "secrets", "samplecontroller.k8s.io/v1alpha1, Resource=foos"}}
+	m := New(options.NewOptions(), nil, b, false)
+	m.ConfigureSharding(context.Background(), 0, 1)
+
+	usage := m.resourceUsage()
+	if len(usage) != 3 {
+		t.Fatalf("expected the usage of 3 resources, got %v", usage)
+	}
+	for resource, u := range usage {
+		if u.Stores != 1 || u.Objects != 1 || u.Series != 1 || u.Bytes <= 0 {
+			t.Errorf("expected 1 store, object and series and some bytes for %s, got %+v", resource, u)
+		}
+	}
+
+	// The custom resource has the longest metric name, so only it exceeds
+	// a budget between its size and the size of the others.
+	budget := usage["secrets"].Bytes
+	u := NewStoreUsage(m, budget)
+	if got := u.check(); !reflect.DeepEqual(got, []string{"foos.samplecontroller.k8s.io"}) {
+		t.Errorf("expected only foos to exceed the budget, got %v", got)
+	}
+	want := `
+# HELP kube_state_metrics_store_budget_exceeded Whether the approximate memory of the cached metrics of the resource exceeds the store memory budget.
+# TYPE kube_state_metrics_store_budget_exceeded gauge
+kube_state_metrics_store_budget_exceeded{resource="foos.samplecontroller.k8s.io"} 1
+kube_state_metrics_store_budget_exceeded{resource="pods"} 0
+kube_state_metrics_store_budget_exceeded{resource="secrets"} 0
+# HELP kube_state_metrics_store_objects Number of objects of the resource metrics are cached for.
+# TYPE kube_state_metrics_store_objects gauge
+kube_state_metrics_store_objects{resource="foos.samplecontroller.k8s.io"} 1
+kube_state_metrics_store_objects{resource="pods"} 1
+kube_state_metrics_store_objects{resource="secrets"} 1
+`
+	if err := testutil.CollectAndCompare(u, strings.NewReader(want), "kube_state_metrics_store_budget_exceeded", "kube_state_metrics_store_objects"); err != nil {
+		t.Error(err)
+	}
+
+	if got := NewStoreUsage(m, 0).check(); got != nil {
+		t.Errorf("expected no resources to exceed a disabled budget, got %v", got)
+	}
+	if n := testutil.CollectAndCount(NewStoreUsage(m, 0), "kube_state_metrics_store_budget_exceeded"); n != 0 {
+		t.Errorf("expected no budget metrics without a budget, got %d", n)
+	}
+}
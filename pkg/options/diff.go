@@ -0,0 +1,76 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+
+	"k8s.io/kube-state-metrics/v2/pkg/metricsdiff"
+)
+
+// newDiffCommand returns the command which compares two metric exposition
+// files. Like diff(1), it exits with 1 if there are differences and with 2 on
+// errors.
+func newDiffCommand() *cobra.Command {
+	var tolerance float64
+	cmd := &cobra.Command{
+		Use:   "diff OLD NEW",
+		Short: "Compare two metric exposition files.",
+		Long:  "Compare two files of metrics in the Prometheus text format, e.g. before and after an upgrade, and report added and removed metric families, label changes and value drifts.",
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			differs, err := diffFiles(args[0], args[1], tolerance, os.Stdout)
+			if err != nil {
+				klog.ErrorS(err, "Failed to compare metrics")
+				klog.FlushAndExit(klog.ExitFlushTimeout, 2)
+			}
+			if differs {
+				klog.FlushAndExit(klog.ExitFlushTimeout, 1)
+			}
+			klog.FlushAndExit(klog.ExitFlushTimeout, 0)
+		},
+		Example: "kube-state-metrics diff old.prom new.prom",
+	}
+	cmd.Flags().Float64Var(&tolerance, "tolerance", 0, "Relative difference up to which values of series are considered equal (Example: 0.1 for 10%).")
+	return cmd
+}
+
+// diffFiles writes the differences of the given exposition files to w and
+// returns whether there are any.
+func diffFiles(oldPath, newPath string, tolerance float64, w io.Writer) (bool, error) {
+	oldFile, err := os.Open(filepath.Clean(oldPath))
+	if err != nil {
+		return false, err
+	}
+	defer oldFile.Close()
+	newFile, err := os.Open(filepath.Clean(newPath))
+	if err != nil {
+		return false, err
+	}
+	defer newFile.Close()
+
+	report, err := metricsdiff.Diff(oldFile, newFile, tolerance)
+	if err != nil {
+		return false, err
+	}
+	return !report.Empty(), report.Write(w)
+}
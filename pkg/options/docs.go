@@ -0,0 +1,73 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+)
+
+// newDocsCommand returns the command which writes the reference of all
+// commands and their flags as Markdown to stdout.
+func newDocsCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "docs",
+		Short: "Generate Markdown documentation of the commands.",
+		Long:  "Generate the reference of all commands and their flags as Markdown, e.g. to keep the documentation of a deployment in sync with the version of kube-state-metrics.",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := writeDocs(cmd.Root(), os.Stdout); err != nil {
+				klog.ErrorS(err, "Failed to generate documentation")
+				klog.FlushAndExit(klog.ExitFlushTimeout, 1)
+			}
+			klog.FlushAndExit(klog.ExitFlushTimeout, 0)
+		},
+		Example: "kube-state-metrics docs > kube-state-metrics.md",
+	}
+}
+
+// writeDocs writes the reference of cmd and its available subcommands to w,
+// with one section per command.
+func writeDocs(cmd *cobra.Command, w io.Writer) error {
+	level := "#"
+	if cmd.HasParent() {
+		level = "##"
+	}
+	description := cmd.Long
+	if description == "" {
+		description = cmd.Short
+	}
+	if _, err := fmt.Fprintf(w, "%s %s\n\n%s\n\n```txt\n%s```\n", level, cmd.CommandPath(), description, cmd.UsageString()); err != nil {
+		return err
+	}
+	for _, c := range cmd.Commands() {
+		if !c.IsAvailableCommand() || c.IsAdditionalHelpTopicCommand() {
+			continue
+		}
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+		if err := writeDocs(c, w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// flagGroupAnnotation is the annotation of a flag which holds the title of
+// the group the flag is listed in by the help text.
+const flagGroupAnnotation = "kube-state-metrics.io/flag-group"
+
+// loggingFlagGroup is the title of the group of the klog flags.
+const loggingFlagGroup = "Logging"
+
+// flagGroups are the groups of the flags of kube-state-metrics, in the order
+// they are listed by the help text.
+var flagGroups = []struct {
+	title string
+	flags []string
+}{
+	{"Server", []string{"access-log-sample-rate", "allowed-scrape-cidrs", "config", "deterministic-output", "enable-gzip-encoding", "gomemlimit-ratio", "grpc-host", "grpc-port", "grpc-stream-interval", "healthz-apiserver-check", "healthz-store-write-max-age", "help", "host", "max-concurrent-scrapes", "memory-degradation-threshold", "pod-discovery-annotation", "port", "pushgateway-instance", "pushgateway-interval", "pushgateway-job", "pushgateway-url", "scrape-archive-file", "scrape-queue-timeout", "self-check", "server-idle-timeout", "server-max-header-bytes", "server-max-request-body-bytes", "server-read-header-timeout", "server-read-timeout", "server-write-timeout", "shutdown-drain-period", "shutdown-timeout", "single-endpoint", "store-memory-budget", "store-shards", "telemetry-classic-histograms", "telemetry-host", "telemetry-native-histograms", "telemetry-port", "tls-config", "wait-for-sync"}},
+	{"Kubernetes API", []string{"apiserver", "kubeconfig", "list-concurrency", "resource-list-options", "stale-threshold", "use-apiserver-cache"}},
+	{"Metric", []string{"canonical-resource-units", "condition-limit", "condition-type-limit", "exemplar-families", "exemplar-trace-id-annotation", "label-value-max-length", "label-value-max-length-policy", "last-terminated-reason-limit", "metadata-only-resources", "metric-allowlist", "metric-annotations-allowlist", "metric-denylist", "metric-labels-allowlist", "metric-opt-in-list", "metric-opt-out-list", "metric-prefix", "naming-convention", "node-image-limit", "pod-status-reasons", "replicaset-deployment-label", "resources", "split-terminal-pods", "timestamp-precision"}},
+	{"Object Filter", []string{"ignore-annotation", "namespace-denylist-preset", "namespaces", "namespaces-denylist", "owner-kinds-allowlist", "owner-kinds-denylist", "pod-gc-threshold", "resource-field-selector"}},
+	{"Sharding", []string{"federate-service", "node", "pod", "pod-namespace", "shard", "total-shards"}},
+	{"Custom Resource State", []string{"custom-resource-state-config", "custom-resource-state-config-file", "custom-resource-discovery-interval", "custom-resource-state-only", "custom-resource-state-schema-validation", "enable-scale-subresource-metrics"}},
+	{"Notification", []string{"notification-resources", "notification-webhook-url"}},
+	{"Snapshot", []string{"benchmark-objects", "cache-dir", "cache-interval", "file-export-dir", "file-export-interval", "file-export-max-files", "file-export-retention", "snapshot-dir", "snapshot-interval", "snapshot-replay-dir"}},
+	{loggingFlagGroup, nil},
+}
+
+func init() {
+	cobra.AddTemplateFunc("flagGroupUsages", flagGroupUsages)
+}
+
+// setFlagGroup sets the group of the flags with the given names.
+func setFlagGroup(fs *pflag.FlagSet, title string, names ...string) {
+	for _, name := range names {
+		if err := fs.SetAnnotation(name, flagGroupAnnotation, []string{title}); err != nil {
+			panic(fmt.Sprintf("failed to set group of flag: %v", err))
+		}
+	}
+}
+
+// usageTemplate returns the usage template of cmd, which lists the local
+// flags by their groups.
+func usageTemplate(cmd *cobra.Command) string {
+	return strings.Replace(cmd.UsageTemplate(),
+		"Flags:\n{{.LocalFlags.FlagUsages | trimTrailingWhitespaces}}",
+		"{{flagGroupUsages .LocalFlags | trimTrailingWhitespaces}}", 1)
+}
+
+// flagGroupUsages returns the usages of the flags of fs, listed by their
+// groups. Flags without a group are listed last. If no flag has a group, the
+// flags are listed like by cobra.
+func flagGroupUsages(fs *pflag.FlagSet) string {
+	groups := map[string]*pflag.FlagSet{}
+	var other *pflag.FlagSet
+	fs.VisitAll(func(f *pflag.Flag) {
+		title := ""
+		if t := f.Annotations[flagGroupAnnotation]; len(t) > 0 {
+			title = t[0]
+		}
+		set := other
+		if title != "" {
+			set = groups[title]
+		}
+		if set == nil {
+			set = pflag.NewFlagSet(title, pflag.ContinueOnError)
+			if title == "" {
+				other = set
+			} else {
+				groups[title] = set
+			}
+		}
+		set.AddFlag(f)
+	})
+	if len(groups) == 0 {
+		return "Flags:\n" + fs.FlagUsages()
+	}
+
+	var b strings.Builder
+	for _, g := range flagGroups {
+		if set, ok := groups[g.title]; ok {
+			fmt.Fprintf(&b, "%s Flags:\n%s\n", g.title, set.FlagUsages())
+		}
+	}
+	if other != nil {
+		fmt.Fprintf(&b, "Other Flags:\n%s\n", other.FlagUsages())
+	}
+	return b.String()
+}
@@ -19,42 +19,125 @@ package options
 import (
 	"flag"
 	"fmt"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
+	"reflect"
 	"strings"
+	"time"
 
 	"github.com/prometheus/common/version"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
 	"k8s.io/klog/v2"
 )
 
 // Options are the configurable parameters for kube-state-metrics.
 type Options struct {
-	AnnotationsAllowList     LabelsAllowList `yaml:"annotations_allow_list"`
-	Apiserver                string          `yaml:"apiserver"`
-	CustomResourceConfig     string          `yaml:"custom_resource_config"`
-	CustomResourceConfigFile string          `yaml:"custom_resource_config_file"`
-	CustomResourcesOnly      bool            `yaml:"custom_resources_only"`
-	EnableGZIPEncoding       bool            `yaml:"enable_gzip_encoding"`
-	Help                     bool            `yaml:"help"`
-	Host                     string          `yaml:"host"`
-	Kubeconfig               string          `yaml:"kubeconfig"`
-	LabelsAllowList          LabelsAllowList `yaml:"labels_allow_list"`
-	MetricAllowlist          MetricSet       `yaml:"metric_allowlist"`
-	MetricDenylist           MetricSet       `yaml:"metric_denylist"`
-	MetricOptInList          MetricSet       `yaml:"metric_opt_in_list"`
-	Namespace                string          `yaml:"namespace"`
-	Namespaces               NamespaceList   `yaml:"namespaces"`
-	NamespacesDenylist       NamespaceList   `yaml:"namespaces_denylist"`
-	Node                     NodeType        `yaml:"node"`
-	Pod                      string          `yaml:"pod"`
-	Port                     int             `yaml:"port"`
-	Resources                ResourceSet     `yaml:"resources"`
-	Shard                    int32           `yaml:"shard"`
-	TLSConfig                string          `yaml:"tls_config"`
-	TelemetryHost            string          `yaml:"telemetry_host"`
-	TelemetryPort            int             `yaml:"telemetry_port"`
-	TotalShards              int             `yaml:"total_shards"`
-	UseAPIServerCache        bool            `yaml:"use_api_server_cache"`
+	AccessLogSampleRate                 float64                `yaml:"access_log_sample_rate"`
+	AllowedScrapeCIDRs                  []string               `yaml:"allowed_scrape_cidrs"`
+	AnnotationsAllowList                LabelsAllowList        `yaml:"annotations_allow_list"`
+	Apiserver                           string                 `yaml:"apiserver"`
+	BenchmarkObjects                    BenchmarkObjects       `yaml:"benchmark_objects"`
+	CacheDir                            string                 `yaml:"cache_dir"`
+	CacheInterval                       time.Duration          `yaml:"cache_interval"`
+	CanonicalResourceUnits              bool                   `yaml:"canonical_resource_units"`
+	ConditionLimit                      int                    `yaml:"condition_limit"`
+	ConditionTypeLimit                  int                    `yaml:"condition_type_limit"`
+	CustomResourceConfig                string                 `yaml:"custom_resource_config"`
+	CustomResourceConfigFile            string                 `yaml:"custom_resource_config_file"`
+	CustomResourceDiscoveryInterval     time.Duration          `yaml:"custom_resource_discovery_interval"`
+	CustomResourceStateSchemaValidation string                 `yaml:"custom_resource_state_schema_validation"`
+	CustomResourcesOnly                 bool                   `yaml:"custom_resources_only"`
+	DeterministicOutput                 bool                   `yaml:"deterministic_output"`
+	EnableGZIPEncoding                  bool                   `yaml:"enable_gzip_encoding"`
+	EnableScaleSubresource              bool                   `yaml:"enable_scale_subresource_metrics"`
+	ExemplarFamilies                    MetricSet              `yaml:"exemplar_families"`
+	ExemplarTraceIDAnnotation           string                 `yaml:"exemplar_trace_id_annotation"`
+	FederateService                     string                 `yaml:"federate_service"`
+	FileExportDir                       string                 `yaml:"file_export_dir"`
+	FileExportInterval                  time.Duration          `yaml:"file_export_interval"`
+	FileExportMaxFiles                  int                    `yaml:"file_export_max_files"`
+	FileExportRetention                 time.Duration          `yaml:"file_export_retention"`
+	GoMemLimitRatio                     float64                `yaml:"gomemlimit_ratio"`
+	GRPCHost                            string                 `yaml:"grpc_host"`
+	GRPCPort                            int                    `yaml:"grpc_port"`
+	GRPCStreamInterval                  time.Duration          `yaml:"grpc_stream_interval"`
+	HealthzAPIServerCheck               bool                   `yaml:"healthz_apiserver_check"`
+	HealthzStoreWriteMaxAge             time.Duration          `yaml:"healthz_store_write_max_age"`
+	Help                                bool                   `yaml:"help"`
+	Host                                string                 `yaml:"host"`
+	IgnoreAnnotation                    string                 `yaml:"ignore_annotation"`
+	Kubeconfig                          string                 `yaml:"kubeconfig"`
+	LabelValueMaxLength                 int                    `yaml:"label_value_max_length"`
+	LabelValueMaxLengthPolicy           string                 `yaml:"label_value_max_length_policy"`
+	LastTerminatedReasonLimit           int                    `yaml:"last_terminated_reason_limit"`
+	LabelsAllowList                     LabelsAllowList        `yaml:"labels_allow_list"`
+	ListConcurrency                     int                    `yaml:"list_concurrency"`
+	MaxConcurrentScrapes                int                    `yaml:"max_concurrent_scrapes"`
+	MemoryDegradationThreshold          float64                `yaml:"memory_degradation_threshold"`
+	MetadataOnlyResources               ResourceSet            `yaml:"metadata_only_resources"`
+	MetricAllowlist                     MetricSet              `yaml:"metric_allowlist"`
+	MetricDenylist                      MetricSet              `yaml:"metric_denylist"`
+	MetricFamiliesDisabled              LabelsAllowList        `yaml:"metric_families_disabled"`
+	MetricOptInList                     MetricSet              `yaml:"metric_opt_in_list"`
+	MetricOptOutList                    MetricSet              `yaml:"metric_opt_out_list"`
+	MetricPrefix                        string                 `yaml:"metric_prefix"`
+	Namespace                           string                 `yaml:"namespace"`
+	Namespaces                          NamespaceList          `yaml:"namespaces"`
+	NamespacesDenylist                  NamespaceList          `yaml:"namespaces_denylist"`
+	NamespacesDenylistPresets           []string               `yaml:"namespaces_denylist_presets"`
+	NamingConvention                    string                 `yaml:"naming_convention"`
+	Node                                NodeType               `yaml:"node"`
+	NodeImageLimit                      int                    `yaml:"node_image_limit"`
+	NotificationResources               ResourceSet            `yaml:"notification_resources"`
+	NotificationWebhookURL              string                 `yaml:"notification_webhook_url"`
+	OwnerKindsAllowList                 LabelsAllowList        `yaml:"owner_kinds_allow_list"`
+	OwnerKindsDenyList                  LabelsAllowList        `yaml:"owner_kinds_deny_list"`
+	Pod                                 string                 `yaml:"pod"`
+	PodDiscoveryAnnotation              string                 `yaml:"pod_discovery_annotation"`
+	PodGCThreshold                      time.Duration          `yaml:"pod_gc_threshold"`
+	PodStatusReasons                    []string               `yaml:"pod_status_reasons"`
+	Port                                int                    `yaml:"port"`
+	PushgatewayInstance                 string                 `yaml:"pushgateway_instance"`
+	PushgatewayInterval                 time.Duration          `yaml:"pushgateway_interval"`
+	PushgatewayJob                      string                 `yaml:"pushgateway_job"`
+	PushgatewayURL                      string                 `yaml:"pushgateway_url"`
+	ResourceFieldSelectors              ResourceFieldSelectors `yaml:"resource_field_selectors"`
+	ResourceListOptions                 ResourceListOptions    `yaml:"resource_list_options"`
+	ReplicaSetDeploymentLabel           bool                   `yaml:"replicaset_deployment_label"`
+	Resources                           ResourceSet            `yaml:"resources"`
+	ScrapeArchiveFile                   string                 `yaml:"scrape_archive_file"`
+	ScrapeQueueTimeout                  time.Duration          `yaml:"scrape_queue_timeout"`
+	SelfCheck                           bool                   `yaml:"self_check"`
+	ServerIdleTimeout                   time.Duration          `yaml:"server_idle_timeout"`
+	ServerMaxHeaderBytes                int                    `yaml:"server_max_header_bytes"`
+	ServerMaxRequestBodyBytes           int64                  `yaml:"server_max_request_body_bytes"`
+	ServerReadHeaderTimeout             time.Duration          `yaml:"server_read_header_timeout"`
+	ServerReadTimeout                   time.Duration          `yaml:"server_read_timeout"`
+	ServerWriteTimeout                  time.Duration          `yaml:"server_write_timeout"`
+	Shard                               int32                  `yaml:"shard"`
+	SingleEndpoint                      bool                   `yaml:"single_endpoint"`
+	StaleThreshold                      time.Duration          `yaml:"stale_threshold"`
+	ShutdownDrainPeriod                 time.Duration          `yaml:"shutdown_drain_period"`
+	ShutdownTimeout                     time.Duration          `yaml:"shutdown_timeout"`
+	SnapshotDir                         string                 `yaml:"snapshot_dir"`
+	SnapshotInterval                    time.Duration          `yaml:"snapshot_interval"`
+	SnapshotReplayDir                   string                 `yaml:"snapshot_replay_dir"`
+	SplitTerminalPods                   bool                   `yaml:"split_terminal_pods"`
+	StoreMemoryBudget                   int64                  `yaml:"store_memory_budget"`
+	StoreShards                         int                    `yaml:"store_shards"`
+	TLSConfig                           string                 `yaml:"tls_config"`
+	TelemetryClassicHistograms          bool                   `yaml:"telemetry_classic_histograms"`
+	TelemetryHost                       string                 `yaml:"telemetry_host"`
+	TelemetryNativeHistograms           bool                   `yaml:"telemetry_native_histograms"`
+	TelemetryPort                       int                    `yaml:"telemetry_port"`
+	TimestampPrecision                  string                 `yaml:"timestamp_precision"`
+	TotalShards                         int                    `yaml:"total_shards"`
+	UseAPIServerCache                   bool                   `yaml:"use_api_server_cache"`
+	WaitForSync                         bool                   `yaml:"wait_for_sync"`
 
 	Config string
 
@@ -66,15 +149,142 @@ func GetConfigFile(opt Options) string {
 	return opt.Config
 }
 
+// ApplyConfigFile sets the options which are set in the given options config
+// file. Options which were set by flags or environment variables take
+// precedence and are kept.
+func (o *Options) ApplyConfigFile(data []byte) error {
+	var keys map[string]interface{}
+	if err := yaml.Unmarshal(data, &keys); err != nil {
+		return err
+	}
+	var config Options
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return err
+	}
+
+	set := o.setByFlags()
+	opts := reflect.ValueOf(o).Elem()
+	for i := 0; i < opts.NumField(); i++ {
+		key, _, _ := strings.Cut(opts.Type().Field(i).Tag.Get("yaml"), ",")
+		if _, ok := keys[key]; !ok || key == "" {
+			continue
+		}
+		if _, ok := set[opts.Field(i).Addr().Pointer()]; ok {
+			continue
+		}
+		opts.Field(i).Set(reflect.ValueOf(config).Field(i))
+	}
+	return nil
+}
+
+// setByFlags returns the addresses of the options which were set by flags or
+// environment variables.
+func (o *Options) setByFlags() map[uintptr]struct{} {
+	// The values of the flags point to the options they set.
+	set := map[uintptr]struct{}{}
+	if o.cmd != nil {
+		o.cmd.Flags().Visit(func(f *pflag.Flag) {
+			if v := reflect.ValueOf(f.Value); v.Kind() == reflect.Ptr {
+				set[v.Pointer()] = struct{}{}
+			}
+		})
+	}
+	return set
+}
+
+// familyFilterKeys are the keys of the options config file which only
+// configure the filter of the metric families, so that changes of them are
+// applied without restarting kube-state-metrics.
+var familyFilterKeys = []string{"canonical_resource_units", "metric_allowlist", "metric_denylist", "metric_families_disabled", "metric_opt_in_list", "metric_opt_out_list"}
+
+// OnlyFamilyFiltersChanged returns whether the given contents of the options
+// config file only differ in the options which configure the filter of the
+// metric families. It returns an error if either is not a valid options
+// config file.
+func OnlyFamilyFiltersChanged(old, new []byte) (bool, error) {
+	var oldKeys, newKeys map[string]interface{}
+	var config Options
+	for _, data := range [][]byte{old, new} {
+		if err := yaml.Unmarshal(data, &config); err != nil {
+			return false, err
+		}
+	}
+	if err := yaml.Unmarshal(old, &oldKeys); err != nil {
+		return false, err
+	}
+	if err := yaml.Unmarshal(new, &newKeys); err != nil {
+		return false, err
+	}
+	for _, key := range familyFilterKeys {
+		delete(oldKeys, key)
+		delete(newKeys, key)
+	}
+	return reflect.DeepEqual(oldKeys, newKeys), nil
+}
+
+// WithFamilyFilters returns a copy of the options with the options which
+// configure the filter of the metric families set as in the given options
+// config file. Those missing from the file are reset to their defaults, while
+// those set by flags or environment variables are kept.
+func (o *Options) WithFamilyFilters(data []byte) (*Options, error) {
+	var keys map[string]interface{}
+	if err := yaml.Unmarshal(data, &keys); err != nil {
+		return nil, err
+	}
+	var config Options
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+
+	set := o.setByFlags()
+	defaults := reflect.ValueOf(NewOptions()).Elem()
+	reloaded := *o
+	opts := reflect.ValueOf(o).Elem()
+	reloadedOpts := reflect.ValueOf(&reloaded).Elem()
+	for i := 0; i < opts.NumField(); i++ {
+		key, _, _ := strings.Cut(opts.Type().Field(i).Tag.Get("yaml"), ",")
+		if !isFamilyFilterKey(key) {
+			continue
+		}
+		if _, ok := set[opts.Field(i).Addr().Pointer()]; ok {
+			continue
+		}
+		if _, ok := keys[key]; ok {
+			reloadedOpts.Field(i).Set(reflect.ValueOf(config).Field(i))
+		} else {
+			reloadedOpts.Field(i).Set(defaults.Field(i))
+		}
+	}
+	return &reloaded, nil
+}
+
+// isFamilyFilterKey returns whether the key of the options config file
+// configures the filter of the metric families.
+func isFamilyFilterKey(key string) bool {
+	for _, k := range familyFilterKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
 // NewOptions returns a new instance of `Options`.
 func NewOptions() *Options {
 	return &Options{
-		Resources:            ResourceSet{},
-		MetricAllowlist:      MetricSet{},
-		MetricDenylist:       MetricSet{},
-		MetricOptInList:      MetricSet{},
-		AnnotationsAllowList: LabelsAllowList{},
-		LabelsAllowList:      LabelsAllowList{},
+		Resources:              ResourceSet{},
+		NotificationResources:  ResourceSet{},
+		MetadataOnlyResources:  ResourceSet{},
+		ExemplarFamilies:       MetricSet{},
+		MetricAllowlist:        MetricSet{},
+		MetricDenylist:         MetricSet{},
+		MetricOptInList:        MetricSet{},
+		MetricOptOutList:       MetricSet{},
+		MetricFamiliesDisabled: LabelsAllowList{},
+		AnnotationsAllowList:   LabelsAllowList{},
+		LabelsAllowList:        LabelsAllowList{},
+		OwnerKindsAllowList:    LabelsAllowList{},
+		OwnerKindsDenyList:     LabelsAllowList{},
 	}
 }
 
@@ -102,7 +312,7 @@ func (o *Options) AddFlags(cmd *cobra.Command) {
 		},
 	}
 
-	cmd.AddCommand(completionCommand, versionCommand)
+	cmd.AddCommand(completionCommand, newDiffCommand(), newDocsCommand(), versionCommand)
 
 	o.cmd.Flags().Usage = func() {
 		_, _ = fmt.Fprintf(os.Stderr, "Usage of %s:\n", os.Args[0])
@@ -118,33 +328,146 @@ func (o *Options) AddFlags(cmd *cobra.Command) {
 
 	autoshardingNotice := "When set, it is expected that --pod and --pod-namespace are both set. Most likely this should be passed via the downward API. This is used for auto-detecting sharding. If set, this has preference over statically configured sharding. This is experimental, it may be removed without notice."
 
+	o.cmd.Flags().BoolVar(&o.CanonicalResourceUnits, "canonical-resource-units", false, "Serve the resources of pod overheads as the kube_pod_overhead family, in the base unit of each resource with a unit label like kube_pod_container_resource_requests, instead of the deprecated families with the unit in their name, i.e. kube_pod_overhead_cpu_cores and kube_pod_overhead_memory_bytes.")
 	o.cmd.Flags().BoolVar(&o.CustomResourcesOnly, "custom-resource-state-only", false, "Only provide Custom Resource State metrics (experimental)")
+	o.cmd.Flags().BoolVar(&o.DeterministicOutput, "deterministic-output", false, "Sort the metric families by name and the series of each family before writing them, so that the output of identical objects is identical, e.g. for snapshot-based tests or diffing scrapes. Sorting buffers all metrics, which makes scrapes slower and uses more memory.")
+	o.cmd.Flags().BoolVar(&o.ReplicaSetDeploymentLabel, "replicaset-deployment-label", false, "Add the name of the deployment which controls a replicaset as deployment label to all kube_replicaset_* families, so that they can be grouped by deployment. The label is empty for replicasets which are not controlled by a deployment.")
+	o.cmd.Flags().BoolVar(&o.SplitTerminalPods, "split-terminal-pods", false, "Serve Succeeded and Failed pods as kube_pod_terminal_info, with fewer labels, instead of kube_pod_info, to reduce the cardinality of completed jobs.")
+	o.cmd.Flags().BoolVar(&o.TelemetryClassicHistograms, "telemetry-classic-histograms", true, "Expose the histograms of the self metrics as classic histograms with fixed buckets. Can be disabled with --telemetry-native-histograms, for scrapers which support native histograms.")
+	o.cmd.Flags().BoolVar(&o.TelemetryNativeHistograms, "telemetry-native-histograms", false, "Expose the histograms of the self metrics as native histograms with exponential buckets to scrapers negotiating the protobuf format, in addition to the classic histograms unless --telemetry-classic-histograms=false.")
 	o.cmd.Flags().BoolVar(&o.EnableGZIPEncoding, "enable-gzip-encoding", false, "Gzip responses when requested by clients via 'Accept-Encoding: gzip' header.")
+	o.cmd.Flags().BoolVar(&o.EnableScaleSubresource, "enable-scale-subresource-metrics", false, "Expose the desired and current replicas of all custom resources whose CustomResourceDefinition declares the scale subresource as kube_scale_spec_replicas and kube_scale_status_replicas (experimental)")
+	o.cmd.Flags().BoolVar(&o.HealthzAPIServerCheck, "healthz-apiserver-check", false, "Fail /healthz if the apiserver can't be reached with a request of /version. The result is cached for 10 seconds.")
 	o.cmd.Flags().BoolVarP(&o.Help, "help", "h", false, "Print Help text")
-	o.cmd.Flags().BoolVarP(&o.UseAPIServerCache, "use-apiserver-cache", "", false, "Sets resourceVersion=0 for ListWatch requests, using cached resources from the apiserver instead of an etcd quorum read.")
+	o.cmd.Flags().BoolVarP(&o.UseAPIServerCache, "use-apiserver-cache", "", false, "Sets resourceVersion=0 for ListWatch requests, using cached resources from the apiserver instead of an etcd quorum read. Equivalent to --resource-list-options='*=resourceVersion=cache'.")
+	o.cmd.Flags().BoolVar(&o.WaitForSync, "wait-for-sync", false, "Delay listening on --port until the stores of all enabled resources have synced, so that scrapes never reach an instance without metrics. The sync state is exposed on /startupz of the telemetry server in the meantime, e.g. for a startup probe.")
+	o.cmd.Flags().BoolVar(&o.SingleEndpoint, "single-endpoint", false, "Serve the self metrics, prefixed with kube_state_metrics_, on /metrics of --port after the metrics of the stores, together with /startupz and /readyz, instead of starting the telemetry server. --telemetry-host and --telemetry-port are ignored.")
+	o.cmd.Flags().BoolVar(&o.SelfCheck, "self-check", false, "Lint the metric families of the enabled resources and the Custom Resource State config at startup, like the lint command, and exit if there are problems.")
 	o.cmd.Flags().Int32Var(&o.Shard, "shard", int32(0), "The instances shard nominal (zero indexed) within the total number of shards. (default 0)")
+	o.cmd.Flags().DurationVar(&o.CustomResourceDiscoveryInterval, "custom-resource-discovery-interval", time.Minute, "Interval in which the discovered CRDs are checked for updates of the Custom Resource State metrics, in addition to the updates on CRD events. 0 disables the periodic checks. (experimental)")
+	o.cmd.Flags().DurationVar(&o.CacheInterval, "cache-interval", time.Minute, "Interval in which the objects are written to --cache-dir.")
+	o.cmd.Flags().DurationVar(&o.FileExportInterval, "file-export-interval", 5*time.Minute, "Interval in which the metrics are exported to --file-export-dir.")
+	o.cmd.Flags().DurationVar(&o.GRPCStreamInterval, "grpc-stream-interval", time.Second, "Interval in which the metrics streamed over the MetricsStream gRPC service on --grpc-port are checked for changes.")
+	o.cmd.Flags().DurationVar(&o.FileExportRetention, "file-export-retention", 24*time.Hour, "Age after which files exported to --file-export-dir are removed. 0 keeps files regardless of their age.")
+	o.cmd.Flags().DurationVar(&o.PushgatewayInterval, "pushgateway-interval", time.Minute, "Interval in which the metrics are pushed to --pushgateway-url.")
+	o.cmd.Flags().DurationVar(&o.ScrapeQueueTimeout, "scrape-queue-timeout", 5*time.Second, "Maximum duration scrapes wait for a free slot if --max-concurrent-scrapes scrapes are in flight, before they are rejected with 503 Service Unavailable.")
+	o.cmd.Flags().DurationVar(&o.ServerIdleTimeout, "server-idle-timeout", 0, "Maximum duration to wait for the next request on keep-alive connections of the metrics and telemetry servers. 0 uses --server-read-timeout.")
+	o.cmd.Flags().DurationVar(&o.ServerReadHeaderTimeout, "server-read-header-timeout", 5*time.Second, "Maximum duration for reading the request headers on the metrics and telemetry servers. 0 uses --server-read-timeout.")
+	o.cmd.Flags().DurationVar(&o.ServerReadTimeout, "server-read-timeout", 0, "Maximum duration for reading the entire request, including the body, on the metrics and telemetry servers. 0 disables the timeout.")
+	o.cmd.Flags().DurationVar(&o.ServerWriteTimeout, "server-write-timeout", 0, "Maximum duration from the end of reading the request headers to the end of writing the response on the metrics and telemetry servers. Needs to be longer than the slowest scrape. 0 disables the timeout.")
+	o.cmd.Flags().DurationVar(&o.ShutdownDrainPeriod, "shutdown-drain-period", 0, "Duration for which the metrics are still served after SIGTERM, while /readyz of the telemetry server fails, so that scrapers get a final scrape before the listeners are shut down. 0 shuts down right away.")
+	o.cmd.Flags().DurationVar(&o.ShutdownTimeout, "shutdown-timeout", 3*time.Second, "Maximum duration to wait for in-flight requests when shutting down the listeners.")
+	o.cmd.Flags().DurationVar(&o.SnapshotInterval, "snapshot-interval", 5*time.Minute, "Interval in which snapshots are written to --snapshot-dir.")
+	o.cmd.Flags().DurationVar(&o.StaleThreshold, "stale-threshold", 5*time.Minute, "Duration after which the metrics of a resource are marked as stale while its list and watch requests fail, e.g. because the apiserver is unreachable. Stale resources are exposed via the kube_state_metrics_stale self metric and the X-Kube-State-Metrics-Stale header of /metrics responses. 0 disables it.")
+	o.cmd.Flags().DurationVar(&o.HealthzStoreWriteMaxAge, "healthz-store-write-max-age", 0, "Fail /healthz if no object was successfully written to the stores for this duration, e.g. because the watches hang. Only suitable for clusters whose watched objects change more often than this duration. 0 disables it.")
+	o.cmd.Flags().DurationVar(&o.PodGCThreshold, "pod-gc-threshold", 0, "Exclude Succeeded and Failed pods from metrics once they finished longer than this duration ago. Pods whose finish time is unknown, e.g. evicted pods, are excluded once they were first observed as finished longer than this duration ago. 0 disables it.")
+	o.cmd.Flags().Float64Var(&o.AccessLogSampleRate, "access-log-sample-rate", 0, "Share of the requests to the metrics server which are logged as structured access log entries with the client address and identity, the number of served metric families, the response size and the duration, e.g. to attribute scrape load in multi-tenant setups. 0 disables the access log, 1 logs all requests.")
+	o.cmd.Flags().Float64Var(&o.GoMemLimitRatio, "gomemlimit-ratio", 0.9, "Ratio of the memory limit of the cgroup which the Go memory limit is set to, so that the garbage collector runs more often close to the limit instead of the process being killed. It is not applied if the GOMEMLIMIT environment variable is set. 0 disables it.")
+	o.cmd.Flags().Float64Var(&o.MemoryDegradationThreshold, "memory-degradation-threshold", 0, "Ratio of the memory limit above which the labels and annotations metric families are disabled until restart, to reduce the memory usage. The memory limit is the limit of the cgroup, or else GOMEMLIMIT. The degradation is exposed via the kube_state_metrics_degraded self metric. 0 disables it.")
+	o.cmd.Flags().IntVar(&o.ConditionLimit, "condition-limit", 0, "Maximum number of condition types per object of the kube_<resource>_status_condition families, in the order of the status of the object. Further conditions are dropped. 0 disables the limit.")
+	o.cmd.Flags().IntVar(&o.ConditionTypeLimit, "condition-type-limit", 0, "Maximum number of distinct condition types per resource of the kube_<resource>_status_condition families which are not defined by Kubernetes, across all objects. Conditions of further types are dropped. 0 disables the limit.")
+	o.cmd.Flags().IntVar(&o.FileExportMaxFiles, "file-export-max-files", 0, "Maximum number of files kept in --file-export-dir, removing the oldest ones first. 0 disables the limit.")
+	o.cmd.Flags().IntVar(&o.GRPCPort, "grpc-port", 0, "Port to serve the MetricsStream gRPC service on, which streams the changes of the metric families to subscribers. Subscribers are restricted by --allowed-scrape-cidrs like scrapes on --port. 0 disables the gRPC server.")
+	o.cmd.Flags().IntVar(&o.LabelValueMaxLength, "label-value-max-length", 0, "Maximum length of label values, at least 16, or 253 with the 'drop' policy, so that the labels identifying objects are kept. Longer values are handled according to --label-value-max-length-policy. 0 disables the limit.")
+	o.cmd.Flags().IntVar(&o.LastTerminatedReasonLimit, "last-terminated-reason-limit", 16, "Maximum number of distinct termination reasons of kube_pod_container_status_last_terminated_info. Further reasons are served as 'Other'. 0 disables the limit.")
+	o.cmd.Flags().IntVar(&o.ListConcurrency, "list-concurrency", 0, "Maximum number of concurrent list requests to the apiserver, e.g. while starting or after the apiserver recovered. The limit is halved whenever the apiserver signals overload and recovers with successful requests. After repeated failures of the apiserver, list requests are paused for a cooldown period. 0 disables the limit.")
+	o.cmd.Flags().IntVar(&o.ServerMaxHeaderBytes, "server-max-header-bytes", http.DefaultMaxHeaderBytes, "Maximum size in bytes of the request headers on the metrics and telemetry servers.")
+	o.cmd.Flags().IntVar(&o.MaxConcurrentScrapes, "max-concurrent-scrapes", 0, "Maximum number of concurrently served scrapes of /metrics, /metrics/resources, /metrics/influx and /metrics/delta. Further scrapes are queued for up to --scrape-queue-timeout. The number of scrapes in flight, queued and rejected is exposed as self metrics. 0 disables the limit.")
+	o.cmd.Flags().IntVar(&o.NodeImageLimit, "node-image-limit", 50, "Maximum number of images per node of kube_node_image_size_bytes, in the order reported by the kubelet, which lists the largest images first. 0 disables the limit.")
 	o.cmd.Flags().IntVar(&o.Port, "port", 8080, `Port to expose metrics on.`)
+	o.cmd.Flags().IntVar(&o.StoreShards, "store-shards", 1, "Number of shards the metrics of each resource are split into by the namespace of the objects, so that informer events and scrapes contend on finer-grained locks. Objects without a namespace share a shard.")
 	o.cmd.Flags().IntVar(&o.TelemetryPort, "telemetry-port", 8081, `Port to expose kube-state-metrics self metrics on.`)
 	o.cmd.Flags().IntVar(&o.TotalShards, "total-shards", 1, "The total number of shards. Sharding is disabled when total shards is set to 1.")
+	o.cmd.Flags().Int64Var(&o.ServerMaxRequestBodyBytes, "server-max-request-body-bytes", 0, "Maximum size in bytes of the request bodies on the metrics and telemetry servers. Larger bodies are rejected while they are read. 0 disables the limit.")
+	o.cmd.Flags().Int64Var(&o.StoreMemoryBudget, "store-memory-budget", 0, "Soft budget of the approximate memory in bytes the cached metrics of each resource may use. Resources exceeding it are logged and flagged by the kube_state_metrics_store_budget_exceeded self metric, but still served. 0 disables it.")
 	o.cmd.Flags().StringVar(&o.Apiserver, "apiserver", "", `The URL of the apiserver to use as a master`)
 	o.cmd.Flags().StringVar(&o.CustomResourceConfig, "custom-resource-state-config", "", "Inline Custom Resource State Metrics config YAML (experimental)")
 	o.cmd.Flags().StringVar(&o.CustomResourceConfigFile, "custom-resource-state-config-file", "", "Path to a Custom Resource State Metrics config file (experimental)")
+	o.cmd.Flags().StringVar(&o.CustomResourceStateSchemaValidation, "custom-resource-state-schema-validation", "", "Validate the paths of the Custom Resource State config against the OpenAPI schemas of the CRDs when they are loaded and when CRDs change. With 'warn', paths unknown to the schema are logged, with 'strict', resources with such paths don't generate metrics. Unknown paths are counted by the kube_state_metrics_custom_resource_state_unknown_fields metric. Disabled if empty. (experimental)")
+	o.cmd.Flags().StringVar(&o.GRPCHost, "grpc-host", "::", `Host to serve the MetricsStream gRPC service on.`)
 	o.cmd.Flags().StringVar(&o.Host, "host", "::", `Host to expose metrics on.`)
+	o.cmd.Flags().StringVar(&o.IgnoreAnnotation, "ignore-annotation", DefaultIgnoreAnnotation, "Annotation key which, when set to \"true\" on an object, excludes the object from all metrics. Set to an empty string to disable.")
 	o.cmd.Flags().StringVar(&o.Kubeconfig, "kubeconfig", "", "Absolute path to the kubeconfig file")
+	o.cmd.Flags().StringVar(&o.LabelValueMaxLengthPolicy, "label-value-max-length-policy", "truncate", "How label values longer than --label-value-max-length are handled. One of 'truncate' (cut the value, ending with a hash of the whole value, so that the series stay unique), 'hash' (replace the value by its hash) or 'drop' (drop the label, removing the series of an object which become duplicates).")
+	o.cmd.Flags().StringVar(&o.MetricPrefix, "metric-prefix", "kube_", "Prefix which replaces the 'kube_' prefix of the names of all metric families, including Custom Resource State metrics. The metric allow-, deny-, opt-in and opt-out lists keep matching the default names.")
+	o.cmd.Flags().StringVar(&o.NamingConvention, "naming-convention", "v2", "Naming convention of the metric families. One of 'v2' (the current names) or 'v3', which additionally serves the families whose names do not follow the Prometheus naming conventions with names that do, e.g. kube_pod_created as kube_pod_created_timestamp_seconds, for migrating consumers.")
+	o.cmd.Flags().StringVar(&o.NotificationWebhookURL, "notification-webhook-url", "", "URL which JSON notifications about added and deleted objects and changed conditions of objects of the --notification-resources are POSTed to. Notifications are derived from the watch events of the stores and dropped if they can't be delivered. Disabled if empty.")
 	o.cmd.Flags().StringVar(&o.Namespace, "pod-namespace", "", "Name of the namespace of the pod specified by --pod. "+autoshardingNotice)
+	o.cmd.Flags().StringVar(&o.PodDiscoveryAnnotation, "pod-discovery-annotation", "", "Annotation key which, when set to \"true\" on a pod, serves the pod as target of the Prometheus HTTP service discovery on /discovery/pods of the metrics server, with the meta labels of the pod role of the Kubernetes service discovery. Requires the pods resource. Disabled if empty.")
 	o.cmd.Flags().StringVar(&o.Pod, "pod", "", "Name of the pod that contains the kube-state-metrics container. "+autoshardingNotice)
+	o.cmd.Flags().StringVar(&o.CacheDir, "cache-dir", "", "Directory to periodically cache the watched objects and their resourceVersions in, in the format of --snapshot-dir. On start, the stores are populated from the cache, so that approximate metrics are served right away, and watches resume from the cached resourceVersions instead of listing all objects, unless they expired.")
+	o.cmd.Flags().StringVar(&o.FederateService, "federate-service", "", "Host and port of the headless Service of all shards, e.g. kube-state-metrics.kube-system.svc.cluster.local:8080. If set, /federate on the metrics server serves the metrics of all shards the host resolves to, merged by metric family, for scrapers which cannot scrape each shard. Not supported with --tls-config.")
+	o.cmd.Flags().StringVar(&o.FileExportDir, "file-export-dir", "", "Directory to periodically export the metrics served on /metrics to, as timestamped, gzipped files in the text exposition format, e.g. to ship them out-of-band from air-gapped clusters. Old files are removed according to --file-export-retention and --file-export-max-files.")
+	o.cmd.Flags().StringVar(&o.PushgatewayInstance, "pushgateway-instance", "", "Value of the instance grouping key of the metrics pushed to --pushgateway-url. Defaults to the hostname.")
+	o.cmd.Flags().StringVar(&o.PushgatewayJob, "pushgateway-job", "kube-state-metrics", "Value of the job grouping key of the metrics pushed to --pushgateway-url.")
+	o.cmd.Flags().StringVar(&o.PushgatewayURL, "pushgateway-url", "", "URL of a Prometheus Pushgateway which the metrics served on /metrics are pushed to every --pushgateway-interval, for clusters which Prometheus can't scrape. The metrics replace the group of the job, instance and shard grouping keys. Disabled if empty.")
+	o.cmd.Flags().StringVar(&o.ScrapeArchiveFile, "scrape-archive-file", "", "Path of a file which the metrics of each scrape of /metrics are written to as well, replacing its previous content once the scrape is complete. Scrapes which exclude resources are not written.")
+	o.cmd.Flags().StringVar(&o.SnapshotDir, "snapshot-dir", "", "Directory to periodically write snapshots of the watched objects to, as sanitized JSON without the data of secrets and config maps. Snapshots can be replayed with --snapshot-replay-dir to reproduce metrics offline.")
+	o.cmd.Flags().StringVar(&o.SnapshotReplayDir, "snapshot-replay-dir", "", "Directory of a snapshot written by --snapshot-dir to serve metrics from, instead of watching the apiserver. Custom Resource State metrics and autosharding are not supported while replaying.")
+	o.cmd.Flags().StringVar(&o.TimestampPrecision, "timestamp-precision", "seconds", "Precision of the values of metrics which are timestamps, e.g. kube_pod_created. One of 'seconds' or 'milliseconds', which keeps the milliseconds as fraction. Most timestamps of Kubernetes objects only have a precision of seconds.")
 	o.cmd.Flags().StringVar(&o.TLSConfig, "tls-config", "", "Path to the TLS configuration file")
 	o.cmd.Flags().StringVar(&o.TelemetryHost, "telemetry-host", "::", `Host to expose kube-state-metrics self metrics on.`)
 	o.cmd.Flags().StringVar(&o.Config, "config", "", "Path to the kube-state-metrics options config file")
 	o.cmd.Flags().StringVar((*string)(&o.Node), "node", "", "Name of the node that contains the kube-state-metrics pod. Most likely it should be passed via the downward API. This is used for daemonset sharding. Only available for resources (pod metrics) that support spec.nodeName fieldSelector. This is experimental.")
-	o.cmd.Flags().Var(&o.AnnotationsAllowList, "metric-annotations-allowlist", "Comma-separated list of Kubernetes annotations keys that will be used in the resource' labels metric. By default the annotations metrics are not exposed. To include them, provide a list of resource names in their plural form and Kubernetes annotation keys you would like to allow for them (Example: '=namespaces=[kubernetes.io/team,...],pods=[kubernetes.io/team],...)'. A single '*' can be provided per resource instead to allow any annotations, but that has severe performance implications (Example: '=pods=[*]').")
-	o.cmd.Flags().Var(&o.LabelsAllowList, "metric-labels-allowlist", "Comma-separated list of additional Kubernetes label keys that will be used in the resource' labels metric. By default the labels metrics are not exposed. To include them, provide a list of resource names in their plural form and Kubernetes label keys you would like to allow for them (Example: '=namespaces=[k8s-label-1,k8s-label-n,...],pods=[app],...)'. A single '*' can be provided per resource instead to allow any labels, but that has severe performance implications (Example: '=pods=[*]'). Additionally, an asterisk (*) can be provided as a key, which will resolve to all resources, i.e., assuming '--resources=deployments,pods', '=*=[*]' will resolve to '=deployments=[*],pods=[*]'.")
+	o.cmd.Flags().Var(&o.AnnotationsAllowList, "metric-annotations-allowlist", "Comma-separated list of Kubernetes annotations keys that will be used in the resource' labels metric. By default the annotations metrics are not exposed. To include them, provide a list of resource names in their plural form and Kubernetes annotation keys you would like to allow for them (Example: '=namespaces=[kubernetes.io/team,...],pods=[kubernetes.io/team],...)'. A single '*' can be provided per resource instead to allow any annotations, but that has severe performance implications (Example: '=pods=[*]'). Keys and the asterisk (*) resource key are handled the same as in --metric-labels-allowlist.")
+	o.cmd.Flags().Var(&o.LabelsAllowList, "metric-labels-allowlist", "Comma-separated list of additional Kubernetes label keys that will be used in the resource' labels metric. By default the labels metrics are not exposed. To include them, provide a list of resource names in their plural form and Kubernetes label keys you would like to allow for them (Example: '=namespaces=[k8s-label-1,k8s-label-n,...],pods=[app],...)'. A single '*' can be provided per resource instead to allow any labels, but that has severe performance implications (Example: '=pods=[*]'). Additionally, an asterisk (*) can be provided as a key, which will resolve to all resources, i.e., assuming '--resources=deployments,pods', '=*=[*]' will resolve to '=deployments=[*],pods=[*]'. Resources which are provided explicitly override the asterisk key, which also applies to Custom Resource State metrics. Keys containing regular expression characters are matched as regular expressions against whole label keys (Example: '=pods=[app.kubernetes.io/.*]'). Exact keys can be renamed with a ':<name>' suffix (Example: '=pods=[app.kubernetes.io/name:app]'), to a name which no other key of the list results in.")
 	o.cmd.Flags().Var(&o.MetricAllowlist, "metric-allowlist", "Comma-separated list of metrics to be exposed. This list comprises of exact metric names and/or regex patterns. The allowlist and denylist are mutually exclusive.")
 	o.cmd.Flags().Var(&o.MetricDenylist, "metric-denylist", "Comma-separated list of metrics not to be enabled. This list comprises of exact metric names and/or regex patterns. The allowlist and denylist are mutually exclusive.")
+	o.cmd.Flags().Var(&o.ExemplarFamilies, "exemplar-families", "Comma-separated list of metric families whose metrics carry OpenMetrics exemplars with the UID of their object, by their default names. Exemplars are only served to clients negotiating OpenMetrics.")
+	o.cmd.Flags().StringVar(&o.ExemplarTraceIDAnnotation, "exemplar-trace-id-annotation", "", "Annotation of objects whose value is added to the exemplars of --exemplar-families as trace_id.")
 	o.cmd.Flags().Var(&o.MetricOptInList, "metric-opt-in-list", "Comma-separated list of metrics which are opt-in and not enabled by default. This is in addition to the metric allow- and denylists")
+	o.cmd.Flags().Var(&o.MetricOptOutList, "metric-opt-out-list", "Comma-separated list of exact names of metrics which are enabled by default but should not be exposed. This is in addition to the metric allow- and denylists")
 	o.cmd.Flags().Var(&o.Namespaces, "namespaces", fmt.Sprintf("Comma-separated list of namespaces to be enabled. Defaults to %q", &DefaultNamespaces))
-	o.cmd.Flags().Var(&o.NamespacesDenylist, "namespaces-denylist", "Comma-separated list of namespaces not to be enabled. If namespaces and namespaces-denylist are both set, only namespaces that are excluded in namespaces-denylist will be used.")
+	o.cmd.Flags().Var(&o.NamespacesDenylist, "namespaces-denylist", "Comma-separated list of namespaces not to be enabled. If namespaces and namespaces-denylist are both set, only namespaces that are excluded in namespaces-denylist will be used. Entries which are not valid namespace names are matched as regular expressions against whole namespace names (Example: 'ci-.+').")
+	o.cmd.Flags().Var(&o.OwnerKindsAllowList, "owner-kinds-allowlist", "Comma-separated list of resource names in their plural form and owner reference kinds. Objects of those resources are only exposed if they are owned by an object of one of the kinds (Example: '=replicasets=[Deployment]'). An asterisk (*) can be provided as a resource, which applies to all resources which are not provided explicitly.")
+	o.cmd.Flags().Var(&o.OwnerKindsDenyList, "owner-kinds-denylist", "Comma-separated list of resource names in their plural form and owner reference kinds. Objects of those resources are not exposed if they are owned by an object of one of the kinds (Example: '=pods=[Job]'). The asterisk (*) resource is handled the same as in --owner-kinds-allowlist.")
+	o.cmd.Flags().StringSliceVar(&o.AllowedScrapeCIDRs, "allowed-scrape-cidrs", nil, "Comma-separated list of CIDRs of the clients which are allowed to send requests to the metrics and the telemetry server, e.g. the pod network of the Prometheus servers. Requests from other clients are rejected with 403 Forbidden, except for /healthz, /startupz and /readyz, which are probed by the kubelet. If empty, requests from all clients are allowed.")
+	o.cmd.Flags().StringSliceVar(&o.PodStatusReasons, "pod-status-reasons", nil, "Comma-separated list of the reasons served by kube_pod_status_reason. Besides the reason of the pod status, reasons match the reason of the DisruptionTarget condition, e.g. PreemptionByScheduler or TerminationByKubelet. Defaults to 'Evicted,NodeAffinity,NodeLost,Shutdown,UnexpectedAdmissionError'.")
+	o.cmd.Flags().StringSliceVar(&o.NamespacesDenylistPresets, "namespace-denylist-preset", nil, fmt.Sprintf("Comma-separated list of presets of namespaces to add to --namespaces-denylist. Available presets: %s", describeNamespaceDenylistPresets()))
+	o.cmd.Flags().Var(&o.BenchmarkObjects, "benchmark-objects", "Number of generated objects per resource in its plural form to serve metrics from, instead of watching the apiserver, to benchmark scrape performance and memory usage (Example: 'pods=100000,deployments=5000'). Enabled resources without a number are served without objects.")
+	o.cmd.Flags().Var(&o.ResourceFieldSelectors, "resource-field-selector", "Field selector for a single resource in its plural form, which is combined with the field selectors of --namespaces-denylist and --node (Example: 'events=type=Warning'). Can be specified multiple times.")
+	o.cmd.Flags().Var(&o.ResourceListOptions, "resource-list-options", "List options for a single resource in its plural form, or '*' for all resources. resourceVersion=cache lists from the watch cache of the apiserver, which is cheap but might be stale, resourceVersion=consistent lists from etcd. pageSize is the maximum number of objects per list request (Example: 'pods=resourceVersion=consistent,pageSize=500'). Can be specified multiple times. Takes precedence over --use-apiserver-cache.")
+	o.cmd.Flags().Var(&o.MetadataOnlyResources, "metadata-only-resources", "Comma-separated list of resources whose objects are watched by their metadata only, so that e.g. the values of secrets are never held in memory. Only the info, labels, annotations, created and metadata_resource_version families are served for them. Supported resources: configmaps, secrets.")
+	o.cmd.Flags().Var(&o.NotificationResources, "notification-resources", "Comma-separated list of the enabled resources whose objects are notified about with --notification-webhook-url. Defaults to all enabled resources.")
 	o.cmd.Flags().Var(&o.Resources, "resources", fmt.Sprintf("Comma-separated list of Resources to be enabled. Defaults to %q", &DefaultResources))
+
+	for _, g := range flagGroups {
+		setFlagGroup(o.cmd.Flags(), g.title, g.flags...)
+	}
+	klogFlags.VisitAll(func(f *flag.Flag) {
+		setFlagGroup(o.cmd.Flags(), loggingFlagGroup, f.Name)
+	})
+	o.cmd.SetUsageTemplate(usageTemplate(o.cmd))
+	o.cmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		return applyEnv(cmd.Flags())
+	}
+	o.cmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+		return o.Validate()
+	}
+
+	// Serving metrics is the default, so that existing invocations with
+	// flags only keep working.
+	serveCommand := &cobra.Command{
+		Use:     "serve",
+		Short:   "Serve metrics about the state of the objects (default).",
+		Long:    o.cmd.Long,
+		Args:    cobra.NoArgs,
+		PreRunE: o.cmd.PreRunE,
+		Run: func(cmd *cobra.Command, args []string) {
+			o.cmd.Run(cmd, args)
+		},
+	}
+	serveCommand.Flags().AddFlagSet(o.cmd.Flags())
+	o.cmd.AddCommand(serveCommand)
+}
+
+func describeNamespaceDenylistPresets() string {
+	names := namespaceDenylistPresetNames()
+	descriptions := make([]string, 0, len(names))
+	for _, name := range names {
+		descriptions = append(descriptions, fmt.Sprintf("%s (%s)", name, strings.Join(NamespaceDenylistPresets[name], ",")))
+	}
+	return strings.Join(descriptions, ", ")
 }
 
 // Parse parses the flag definitions from the argument list.
@@ -160,6 +483,177 @@ func (o *Options) Usage() {
 
 // Validate validates arguments
 func (o *Options) Validate() error {
+	if o.SnapshotReplayDir != "" {
+		if o.SnapshotDir != "" {
+			return fmt.Errorf("--snapshot-dir and --snapshot-replay-dir are mutually exclusive")
+		}
+		if o.Pod != "" {
+			return fmt.Errorf("autosharding is not supported with --snapshot-replay-dir")
+		}
+		if o.CustomResourceConfig != "" || o.CustomResourceConfigFile != "" {
+			return fmt.Errorf("custom resource state metrics are not supported with --snapshot-replay-dir")
+		}
+		if o.EnableScaleSubresource {
+			return fmt.Errorf("scale subresource metrics are not supported with --snapshot-replay-dir")
+		}
+		if o.CacheDir != "" {
+			return fmt.Errorf("--cache-dir and --snapshot-replay-dir are mutually exclusive")
+		}
+	}
+	if len(o.BenchmarkObjects) > 0 {
+		if o.SnapshotReplayDir != "" || o.SnapshotDir != "" || o.CacheDir != "" {
+			return fmt.Errorf("--benchmark-objects is mutually exclusive with --snapshot-replay-dir, --snapshot-dir and --cache-dir")
+		}
+		if o.Pod != "" {
+			return fmt.Errorf("autosharding is not supported with --benchmark-objects")
+		}
+		if o.CustomResourceConfig != "" || o.CustomResourceConfigFile != "" {
+			return fmt.Errorf("custom resource state metrics are not supported with --benchmark-objects")
+		}
+		if o.EnableScaleSubresource {
+			return fmt.Errorf("scale subresource metrics are not supported with --benchmark-objects")
+		}
+	}
+	if o.StaleThreshold < 0 {
+		return fmt.Errorf("--stale-threshold must not be negative")
+	}
+	switch o.CustomResourceStateSchemaValidation {
+	case "", "warn", "strict":
+	default:
+		return fmt.Errorf("invalid --custom-resource-state-schema-validation %q, must be one of 'warn' or 'strict'", o.CustomResourceStateSchemaValidation)
+	}
+	if o.CustomResourceDiscoveryInterval < 0 {
+		return fmt.Errorf("--custom-resource-discovery-interval must not be negative")
+	}
+	for _, cidr := range o.AllowedScrapeCIDRs {
+		if _, _, err := net.ParseCIDR(strings.TrimSpace(cidr)); err != nil {
+			return fmt.Errorf("invalid --allowed-scrape-cidrs: %v", err)
+		}
+	}
+	if o.FederateService != "" {
+		if _, _, err := net.SplitHostPort(o.FederateService); err != nil {
+			return fmt.Errorf("invalid --federate-service: %v", err)
+		}
+		if o.TLSConfig != "" {
+			return fmt.Errorf("--federate-service is not supported with --tls-config")
+		}
+	}
+	if o.AccessLogSampleRate < 0 || o.AccessLogSampleRate > 1 {
+		return fmt.Errorf("--access-log-sample-rate must be between 0 and 1")
+	}
+	if o.GoMemLimitRatio < 0 || o.GoMemLimitRatio > 1 {
+		return fmt.Errorf("--gomemlimit-ratio must be between 0 and 1")
+	}
+	if o.MemoryDegradationThreshold < 0 || o.MemoryDegradationThreshold > 1 {
+		return fmt.Errorf("--memory-degradation-threshold must be between 0 and 1")
+	}
+	if o.ListConcurrency < 0 {
+		return fmt.Errorf("--list-concurrency must not be negative")
+	}
+	if o.ConditionLimit < 0 {
+		return fmt.Errorf("--condition-limit must not be negative")
+	}
+	if o.ConditionTypeLimit < 0 {
+		return fmt.Errorf("--condition-type-limit must not be negative")
+	}
+	if o.LastTerminatedReasonLimit < 0 {
+		return fmt.Errorf("--last-terminated-reason-limit must not be negative")
+	}
+	for resource, lo := range o.ResourceListOptions {
+		if lo.ResourceVersion != "" && lo.ResourceVersion != ListResourceVersionCache && lo.ResourceVersion != ListResourceVersionConsistent {
+			return fmt.Errorf("--resource-list-options of %s: resourceVersion must be %q or %q", resource, ListResourceVersionCache, ListResourceVersionConsistent)
+		}
+		if lo.PageSize < 0 {
+			return fmt.Errorf("--resource-list-options of %s: pageSize must not be negative", resource)
+		}
+	}
+	if !o.TelemetryNativeHistograms && !o.TelemetryClassicHistograms {
+		return fmt.Errorf("--telemetry-classic-histograms can only be disabled with --telemetry-native-histograms")
+	}
+	if o.HealthzStoreWriteMaxAge < 0 {
+		return fmt.Errorf("--healthz-store-write-max-age must not be negative")
+	}
+	if o.ServerIdleTimeout < 0 || o.ServerReadHeaderTimeout < 0 || o.ServerReadTimeout < 0 || o.ServerWriteTimeout < 0 {
+		return fmt.Errorf("--server-idle-timeout, --server-read-header-timeout, --server-read-timeout and --server-write-timeout must not be negative")
+	}
+	if o.ServerMaxHeaderBytes <= 0 {
+		return fmt.Errorf("--server-max-header-bytes must be positive")
+	}
+	if o.ServerMaxRequestBodyBytes < 0 {
+		return fmt.Errorf("--server-max-request-body-bytes must not be negative")
+	}
+	if o.ShutdownDrainPeriod < 0 {
+		return fmt.Errorf("--shutdown-drain-period must not be negative")
+	}
+	if o.ShutdownTimeout < 0 {
+		return fmt.Errorf("--shutdown-timeout must not be negative")
+	}
+	if o.StoreMemoryBudget < 0 {
+		return fmt.Errorf("--store-memory-budget must not be negative")
+	}
+	if o.StoreShards < 1 {
+		return fmt.Errorf("--store-shards must be positive")
+	}
+	if o.MaxConcurrentScrapes < 0 {
+		return fmt.Errorf("--max-concurrent-scrapes must not be negative")
+	}
+	if o.ScrapeQueueTimeout < 0 {
+		return fmt.Errorf("--scrape-queue-timeout must not be negative")
+	}
+	if o.NodeImageLimit < 0 {
+		return fmt.Errorf("--node-image-limit must not be negative")
+	}
+	if o.CacheDir != "" && o.CacheInterval <= 0 {
+		return fmt.Errorf("--cache-interval must be positive")
+	}
+	if o.SnapshotDir != "" && o.SnapshotInterval <= 0 {
+		return fmt.Errorf("--snapshot-interval must be positive")
+	}
+	if o.NotificationWebhookURL != "" {
+		u, err := url.Parse(o.NotificationWebhookURL)
+		if err != nil {
+			return fmt.Errorf("invalid --notification-webhook-url: %w", err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return fmt.Errorf("--notification-webhook-url must be a http or https URL")
+		}
+	}
+	if o.PodDiscoveryAnnotation != "" && len(o.Resources) > 0 {
+		if _, ok := o.Resources["pods"]; !ok {
+			return fmt.Errorf("--pod-discovery-annotation requires the pods resource")
+		}
+	}
+	if o.PushgatewayURL != "" {
+		u, err := url.Parse(o.PushgatewayURL)
+		if err != nil {
+			return fmt.Errorf("invalid --pushgateway-url: %w", err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return fmt.Errorf("--pushgateway-url must be a http or https URL")
+		}
+		if o.PushgatewayInterval <= 0 {
+			return fmt.Errorf("--pushgateway-interval must be positive")
+		}
+		if o.PushgatewayJob == "" {
+			return fmt.Errorf("--pushgateway-job must not be empty")
+		}
+	}
+	if o.GRPCPort < 0 {
+		return fmt.Errorf("--grpc-port must not be negative")
+	}
+	if o.GRPCPort != 0 && o.GRPCStreamInterval <= 0 {
+		return fmt.Errorf("--grpc-stream-interval must be positive")
+	}
+	if o.FileExportDir != "" && o.FileExportInterval <= 0 {
+		return fmt.Errorf("--file-export-interval must be positive")
+	}
+	if o.FileExportRetention < 0 {
+		return fmt.Errorf("--file-export-retention must not be negative")
+	}
+	if o.FileExportMaxFiles < 0 {
+		return fmt.Errorf("--file-export-max-files must not be negative")
+	}
+
 	shardableResource := "pods"
 	if o.Node == "" {
 		return nil
@@ -18,7 +18,11 @@ package options
 
 import (
 	"os"
+	"strings"
 	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 func TestOptionsParse(t *testing.T) {
@@ -63,3 +67,174 @@ func TestOptionsParse(t *testing.T) {
 		})
 	}
 }
+
+func TestServeCommand(t *testing.T) {
+	for _, args := range [][]string{
+		{"--resources=pods", "--port=9090"},
+		{"serve", "--resources=pods", "--port=9090"},
+	} {
+		t.Run(strings.Join(args, " "), func(t *testing.T) {
+			served := false
+			cmd := &cobra.Command{Use: "kube-state-metrics", Args: cobra.NoArgs, Run: func(cmd *cobra.Command, args []string) {
+				served = true
+			}}
+			opts := NewOptions()
+			opts.AddFlags(cmd)
+			cmd.SetArgs(args)
+			if err := cmd.Execute(); err != nil {
+				t.Fatal(err)
+			}
+			if !served || opts.Port != 9090 || opts.Resources.String() != "pods" {
+				t.Errorf("expected metrics to be served on port 9090 for pods, got served=%t, port=%d, resources=%s", served, opts.Port, opts.Resources.String())
+			}
+		})
+	}
+}
+
+func TestFlagGroups(t *testing.T) {
+	cmd := &cobra.Command{Use: "kube-state-metrics"}
+	NewOptions().AddFlags(cmd)
+
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if len(f.Annotations[flagGroupAnnotation]) == 0 {
+			t.Errorf("expected flag %s to have a group", f.Name)
+		}
+	})
+
+	usage := cmd.UsageString()
+	for _, g := range flagGroups {
+		if !strings.Contains(usage, g.title+" Flags:\n") {
+			t.Errorf("expected usage to list group %s, got:\n%s", g.title, usage)
+		}
+	}
+	if strings.Contains(usage, "Other Flags:") {
+		t.Errorf("expected usage to not list flags without group, got:\n%s", usage)
+	}
+}
+
+func TestOptionsPrecedence(t *testing.T) {
+	t.Setenv("KSM_PORT", "9191")
+	t.Setenv("KSM_RESOURCES", "pods")
+	t.Setenv("KSM_TELEMETRY_HOST", "127.0.0.1")
+
+	cmd := &cobra.Command{Use: "kube-state-metrics", Args: cobra.NoArgs, Run: func(cmd *cobra.Command, args []string) {}}
+	opts := NewOptions()
+	opts.AddFlags(cmd)
+	cmd.SetArgs([]string{"--port=9090"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	config := `
+port: 1
+resources:
+  secrets: {}
+telemetry_port: 7
+`
+	if err := opts.ApplyConfigFile([]byte(config)); err != nil {
+		t.Fatal(err)
+	}
+
+	if opts.Port != 9090 {
+		t.Errorf("expected the flag to take precedence, got port %d", opts.Port)
+	}
+	if opts.Resources.String() != "pods" || opts.TelemetryHost != "127.0.0.1" {
+		t.Errorf("expected environment variables to take precedence over the config file, got resources %s and telemetry host %s", opts.Resources.String(), opts.TelemetryHost)
+	}
+	if opts.TelemetryPort != 7 {
+		t.Errorf("expected the config file to take precedence over defaults, got telemetry port %d", opts.TelemetryPort)
+	}
+	if opts.Host != "::" {
+		t.Errorf("expected the default host, got %s", opts.Host)
+	}
+
+	t.Setenv("KSM_SHARD", "one")
+	cmd = &cobra.Command{Use: "kube-state-metrics", Args: cobra.NoArgs, Run: func(cmd *cobra.Command, args []string) {}}
+	NewOptions().AddFlags(cmd)
+	cmd.SetArgs([]string{})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "KSM_SHARD") {
+		t.Errorf("expected an error for the invalid environment variable, got %v", err)
+	}
+}
+
+func TestOnlyFamilyFiltersChanged(t *testing.T) {
+	tests := []struct {
+		old, new string
+		want     bool
+	}{
+		{
+			old:  "port: 8080\nmetric_denylist:\n  kube_pod_info: {}\n",
+			new:  "port: 8080\nmetric_allowlist:\n  kube_pod_.*: {}\nmetric_opt_in_list:\n  kube_pod_nodeselectors: {}\n",
+			want: true,
+		},
+		{
+			old:  "port: 8080\n",
+			new:  "port: 8080\n",
+			want: true,
+		},
+		{
+			old:  "port: 8080\nmetric_denylist:\n  kube_pod_info: {}\n",
+			new:  "port: 9090\nmetric_denylist:\n  kube_pod_info: {}\n",
+			want: false,
+		},
+		{
+			old:  "resources:\n  pods: {}\n",
+			new:  "",
+			want: false,
+		},
+	}
+	for _, test := range tests {
+		got, err := OnlyFamilyFiltersChanged([]byte(test.old), []byte(test.new))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.want {
+			t.Errorf("expected %t for %q -> %q, got %t", test.want, test.old, test.new, got)
+		}
+	}
+	if _, err := OnlyFamilyFiltersChanged([]byte("port: 8080\n"), []byte("port: [")); err == nil {
+		t.Error("expected an error for invalid YAML")
+	}
+	// Fixing an invalid config file restarts kube-state-metrics.
+	if _, err := OnlyFamilyFiltersChanged([]byte("metric_denylist: 1\n"), []byte("metric_denylist:\n  kube_pod_info: {}\n")); err == nil {
+		t.Error("expected an error for an invalid options config file")
+	}
+}
+
+func TestWithFamilyFilters(t *testing.T) {
+	cmd := &cobra.Command{Use: "kube-state-metrics", Args: cobra.NoArgs, Run: func(cmd *cobra.Command, args []string) {}}
+	opts := NewOptions()
+	opts.AddFlags(cmd)
+	cmd.SetArgs([]string{"--metric-opt-in-list=kube_pod_nodeselectors"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if err := opts.ApplyConfigFile([]byte("port: 9090\nmetric_denylist:\n  kube_pod_info: {}\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := opts.WithFamilyFilters([]byte(`
+port: 1
+metric_allowlist:
+  kube_pod_status_phase: {}
+metric_opt_in_list:
+  kube_node_status_addresses: {}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.MetricAllowlist.String() != "kube_pod_status_phase" {
+		t.Errorf("expected the allowlist of the config file, got %q", reloaded.MetricAllowlist.String())
+	}
+	if len(reloaded.MetricDenylist) != 0 {
+		t.Errorf("expected the denylist missing from the config file to be reset, got %q", reloaded.MetricDenylist.String())
+	}
+	if reloaded.MetricOptInList.String() != "kube_pod_nodeselectors" {
+		t.Errorf("expected the flag to take precedence, got opt-in list %q", reloaded.MetricOptInList.String())
+	}
+	if reloaded.Port != 9090 {
+		t.Errorf("expected options other than the filters to be kept, got port %d", reloaded.Port)
+	}
+	if opts.MetricDenylist.String() != "kube_pod_info" {
+		t.Errorf("expected the options to be unchanged, got denylist %q", opts.MetricDenylist.String())
+	}
+}
@@ -20,10 +20,20 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// DefaultIgnoreAnnotation is the default annotation key which excludes an object from metric generation.
+const DefaultIgnoreAnnotation = "kube-state-metrics.io/ignore"
+
 var (
 	// DefaultNamespaces is the default namespace selector for selecting and filtering across all namespaces.
 	DefaultNamespaces = NamespaceList{metav1.NamespaceAll}
 
+	// NamespaceDenylistPresets are named sets of namespaces which can be added to the namespace denylist.
+	// Entries which are not valid namespace names are regular expressions.
+	NamespaceDenylistPresets = map[string]NamespaceList{
+		"system": {metav1.NamespaceSystem, metav1.NamespacePublic, "kube-node-lease"},
+		"ci":     {"ci-.+", "pr-[0-9]+", "e2e-.+"},
+	}
+
 	// DefaultResources represents the default set of resources in kube-state-metrics.
 	DefaultResources = ResourceSet{
 		"certificatesigningrequests":      struct{}{},
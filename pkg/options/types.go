@@ -18,10 +18,14 @@ package options
 
 import (
 	"errors"
+	"fmt"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 
 	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/util/validation"
 
 	"k8s.io/klog/v2"
 
@@ -152,6 +156,184 @@ func MergeTwoFieldSelectors(s1 string, s2 string) (string, error) {
 	return fields.AndSelectors(selector1, selector2).String(), nil
 }
 
+// ResourceFieldSelectors represents field selectors which only apply to
+// specific resources.
+type ResourceFieldSelectors map[string]string
+
+// Set adds a field selector for a resource to the ResourceFieldSelectors.
+// Value is in the following format, where the selector may contain commas:
+// resource=selector
+// Example: events=type=Warning
+func (r *ResourceFieldSelectors) Set(value string) error {
+	resource, selector, ok := strings.Cut(value, "=")
+	resource = strings.TrimSpace(resource)
+	if !ok || resource == "" {
+		return fmt.Errorf("invalid format %q, expected resource=selector", value)
+	}
+	if _, err := fields.ParseSelector(selector); err != nil {
+		return fmt.Errorf("invalid field selector for resource %q: %w", resource, err)
+	}
+	if *r == nil {
+		*r = ResourceFieldSelectors{}
+	}
+	(*r)[resource] = selector
+	return nil
+}
+
+func (r *ResourceFieldSelectors) String() string {
+	s := make([]string, 0, len(*r))
+	for resource, selector := range *r {
+		s = append(s, resource+"="+selector)
+	}
+	sort.Strings(s)
+	return strings.Join(s, ";")
+}
+
+// Type returns a descriptive string about the ResourceFieldSelectors type.
+func (r *ResourceFieldSelectors) Type() string {
+	return "string"
+}
+
+const (
+	// ListResourceVersionCache lists with resourceVersion=0, so that lists
+	// are served from the watch cache of the apiserver, which might be stale.
+	ListResourceVersionCache = "cache"
+	// ListResourceVersionConsistent lists without resourceVersion, so that
+	// lists are consistent reads from etcd.
+	ListResourceVersionConsistent = "consistent"
+)
+
+// ListOptions configure the list requests of the reflectors of a resource.
+type ListOptions struct {
+	// ResourceVersion is ListResourceVersionCache,
+	// ListResourceVersionConsistent, or empty to keep the resourceVersion
+	// chosen by the reflector.
+	ResourceVersion string `yaml:"resource_version"`
+	// PageSize is the maximum number of objects per list request, 0 keeps
+	// the page size of the reflector.
+	PageSize int64 `yaml:"page_size"`
+}
+
+// ResourceListOptions represents the list options of specific resources,
+// with '*' applying to all resources.
+type ResourceListOptions map[string]ListOptions
+
+// Set adds the list options of a resource to the ResourceListOptions.
+// Value is in the following format:
+// resource=key=value,...
+// Example: pods=resourceVersion=consistent,pageSize=500
+func (r *ResourceListOptions) Set(value string) error {
+	resource, list, ok := strings.Cut(value, "=")
+	resource = strings.TrimSpace(resource)
+	if !ok || resource == "" {
+		return fmt.Errorf("invalid format %q, expected resource=key=value,...", value)
+	}
+	var o ListOptions
+	for _, pair := range strings.Split(list, ",") {
+		key, v, ok := strings.Cut(pair, "=")
+		v = strings.TrimSpace(v)
+		switch strings.TrimSpace(key) {
+		case "resourceVersion":
+			if v != ListResourceVersionCache && v != ListResourceVersionConsistent {
+				return fmt.Errorf("invalid resourceVersion for resource %q: %q, expected %q or %q", resource, v, ListResourceVersionCache, ListResourceVersionConsistent)
+			}
+			o.ResourceVersion = v
+		case "pageSize":
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || n < 0 {
+				return fmt.Errorf("invalid pageSize for resource %q: %q", resource, v)
+			}
+			o.PageSize = n
+		default:
+			if !ok {
+				return fmt.Errorf("invalid format %q, expected key=value", pair)
+			}
+			return fmt.Errorf("unknown list option %q for resource %q, expected resourceVersion or pageSize", key, resource)
+		}
+	}
+	if *r == nil {
+		*r = ResourceListOptions{}
+	}
+	(*r)[resource] = o
+	return nil
+}
+
+func (r *ResourceListOptions) String() string {
+	s := make([]string, 0, len(*r))
+	for resource, o := range *r {
+		var opts []string
+		if o.ResourceVersion != "" {
+			opts = append(opts, "resourceVersion="+o.ResourceVersion)
+		}
+		if o.PageSize != 0 {
+			opts = append(opts, "pageSize="+strconv.FormatInt(o.PageSize, 10))
+		}
+		s = append(s, resource+"="+strings.Join(opts, ","))
+	}
+	sort.Strings(s)
+	return strings.Join(s, ";")
+}
+
+// Type returns a descriptive string about the ResourceListOptions type.
+func (r *ResourceListOptions) Type() string {
+	return "string"
+}
+
+// Resource returns the list options of the given resource, with the options
+// the resource doesn't set taken from the '*' entry.
+func (r ResourceListOptions) Resource(resource string) ListOptions {
+	o := r["*"]
+	if ro, ok := r[resource]; ok {
+		if ro.ResourceVersion != "" {
+			o.ResourceVersion = ro.ResourceVersion
+		}
+		if ro.PageSize != 0 {
+			o.PageSize = ro.PageSize
+		}
+	}
+	return o
+}
+
+// BenchmarkObjects represents the number of objects to generate per
+// resource.
+type BenchmarkObjects map[string]int
+
+// Set parses a comma-separated list of resource=count pairs into the
+// BenchmarkObjects.
+// Example: pods=100000,deployments=5000
+func (b *BenchmarkObjects) Set(value string) error {
+	if *b == nil {
+		*b = BenchmarkObjects{}
+	}
+	for _, pair := range strings.Split(value, ",") {
+		resource, count, ok := strings.Cut(pair, "=")
+		resource = strings.TrimSpace(resource)
+		if !ok || resource == "" {
+			return fmt.Errorf("invalid format %q, expected resource=count", pair)
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(count))
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid number of objects for resource %q: %q", resource, count)
+		}
+		(*b)[resource] = n
+	}
+	return nil
+}
+
+func (b *BenchmarkObjects) String() string {
+	s := make([]string, 0, len(*b))
+	for resource, count := range *b {
+		s = append(s, resource+"="+strconv.Itoa(count))
+	}
+	sort.Strings(s)
+	return strings.Join(s, ",")
+}
+
+// Type returns a descriptive string about the BenchmarkObjects type.
+func (b *BenchmarkObjects) Type() string {
+	return "string"
+}
+
 // NamespaceList represents a list of namespaces to query from.
 type NamespaceList []string
 
@@ -208,6 +390,51 @@ func (n *NamespaceList) GetExcludeNSFieldSelector(nsDenylist []string) string {
 	return fields.AndSelectors(namespaceExcludeSelectors...).String()
 }
 
+// WithPresets returns the NamespaceList extended by the namespaces of the
+// given NamespaceDenylistPresets.
+func (n NamespaceList) WithPresets(presets []string) (NamespaceList, error) {
+	result := append(NamespaceList{}, n...)
+	for _, preset := range presets {
+		namespaces, ok := NamespaceDenylistPresets[preset]
+		if !ok {
+			return nil, fmt.Errorf("namespace denylist preset %s does not exist. Available presets: %s", preset, strings.Join(namespaceDenylistPresetNames(), ","))
+		}
+		result = append(result, namespaces...)
+	}
+	return result, nil
+}
+
+// SplitPatterns splits the NamespaceList into exact namespace names and
+// regular expressions. Entries which are not valid namespace names are
+// compiled into regular expressions matching whole namespace names.
+func (n NamespaceList) SplitPatterns() (NamespaceList, []*regexp.Regexp, error) {
+	var (
+		exact    NamespaceList
+		patterns []*regexp.Regexp
+	)
+	for _, ns := range n {
+		if len(validation.IsDNS1123Label(ns)) == 0 {
+			exact = append(exact, ns)
+			continue
+		}
+		pattern, err := regexp.Compile("^(?:" + ns + ")$")
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid namespace pattern %q: %w", ns, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return exact, patterns, nil
+}
+
+func namespaceDenylistPresetNames() []string {
+	names := make([]string, 0, len(NamespaceDenylistPresets))
+	for name := range NamespaceDenylistPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // Type returns a descriptive string about the NamespaceList type.
 func (n *NamespaceList) Type() string {
 	return "string"
@@ -155,6 +155,66 @@ func TestNamespaceList_ExcludeNamespacesFieldSelector(t *testing.T) {
 	}
 }
 
+func TestNamespaceList_WithPresets(t *testing.T) {
+	tests := []struct {
+		Desc        string
+		Denylist    NamespaceList
+		Presets     []string
+		Wanted      NamespaceList
+		WantedError bool
+	}{
+		{
+			Desc:     "no presets",
+			Denylist: NamespaceList{"some-system"},
+			Wanted:   NamespaceList{"some-system"},
+		},
+		{
+			Desc:     "system preset",
+			Denylist: NamespaceList{"some-system"},
+			Presets:  []string{"system"},
+			Wanted:   NamespaceList{"some-system", "kube-system", "kube-public", "kube-node-lease"},
+		},
+		{
+			Desc:        "unknown preset",
+			Presets:     []string{"unknown"},
+			WantedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		actual, err := test.Denylist.WithPresets(test.Presets)
+		if err != nil != test.WantedError {
+			t.Errorf("Test error for Desc: %s. Want error: %v. Got: %v.", test.Desc, test.WantedError, err)
+		}
+		if !test.WantedError && !reflect.DeepEqual(actual, test.Wanted) {
+			t.Errorf("Test error for Desc: %s. Want: %+v. Got: %+v.", test.Desc, test.Wanted, actual)
+		}
+	}
+}
+
+func TestNamespaceList_SplitPatterns(t *testing.T) {
+	exact, patterns, err := NamespaceList{"some-system", "ci-.+", "pr-[0-9]+"}.SplitPatterns()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(exact, NamespaceList{"some-system"}) {
+		t.Errorf("Want exact namespaces: %+v. Got: %+v.", NamespaceList{"some-system"}, exact)
+	}
+	for ns, wanted := range map[string]bool{"ci-1234": true, "ci-": false, "pr-42": true, "pr-42-x": false, "default": false} {
+		matched := false
+		for _, p := range patterns {
+			matched = matched || p.MatchString(ns)
+		}
+		if matched != wanted {
+			t.Errorf("Namespace %s: want match %v. Got: %v.", ns, wanted, matched)
+		}
+	}
+
+	if _, _, err := (NamespaceList{"ci-(.+"}).SplitPatterns(); err == nil {
+		t.Errorf("Want error for invalid pattern.")
+	}
+}
+
 func TestNodeFieldSelector(t *testing.T) {
 	tests := []struct {
 		Desc   string
@@ -182,6 +242,150 @@ func TestNodeFieldSelector(t *testing.T) {
 	}
 }
 
+func TestResourceFieldSelectorsSet(t *testing.T) {
+	tests := []struct {
+		Desc        string
+		Values      []string
+		Wanted      ResourceFieldSelectors
+		WantedError bool
+	}{
+		{
+			Desc:   "single resource",
+			Values: []string{"events=type=Warning"},
+			Wanted: ResourceFieldSelectors{"events": "type=Warning"},
+		},
+		{
+			Desc:   "multiple resources and terms",
+			Values: []string{"pods=spec.nodeName!=,status.phase=Running", "events=type=Warning"},
+			Wanted: ResourceFieldSelectors{"pods": "spec.nodeName!=,status.phase=Running", "events": "type=Warning"},
+		},
+		{
+			Desc:   "later values override earlier ones",
+			Values: []string{"events=type=Normal", "events=type=Warning"},
+			Wanted: ResourceFieldSelectors{"events": "type=Warning"},
+		},
+		{
+			Desc:        "missing resource",
+			Values:      []string{"=type=Warning"},
+			WantedError: true,
+		},
+		{
+			Desc:        "missing selector",
+			Values:      []string{"events"},
+			WantedError: true,
+		},
+		{
+			Desc:        "invalid selector",
+			Values:      []string{"events=type"},
+			WantedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		selectors := ResourceFieldSelectors{}
+		var err error
+		for _, v := range test.Values {
+			if err = selectors.Set(v); err != nil {
+				break
+			}
+		}
+		if test.WantedError {
+			if err == nil {
+				t.Errorf("Test error for Desc: %s. Want error. Got: %+v.", test.Desc, selectors)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test error for Desc: %s. Unexpected error: %v.", test.Desc, err)
+		}
+		if !reflect.DeepEqual(selectors, test.Wanted) {
+			t.Errorf("Test error for Desc: %s. Want: %+v. Got: %+v.", test.Desc, test.Wanted, selectors)
+		}
+	}
+}
+
+func TestResourceListOptionsSet(t *testing.T) {
+	tests := []struct {
+		Desc        string
+		Values      []string
+		Wanted      ResourceListOptions
+		WantedError bool
+	}{
+		{
+			Desc:   "single resource",
+			Values: []string{"pods=resourceVersion=consistent,pageSize=500"},
+			Wanted: ResourceListOptions{"pods": {ResourceVersion: ListResourceVersionConsistent, PageSize: 500}},
+		},
+		{
+			Desc:   "multiple resources",
+			Values: []string{"*=resourceVersion=cache", "secrets=pageSize=100"},
+			Wanted: ResourceListOptions{"*": {ResourceVersion: ListResourceVersionCache}, "secrets": {PageSize: 100}},
+		},
+		{
+			Desc:        "missing options",
+			Values:      []string{"pods"},
+			WantedError: true,
+		},
+		{
+			Desc:        "invalid resourceVersion",
+			Values:      []string{"pods=resourceVersion=0"},
+			WantedError: true,
+		},
+		{
+			Desc:        "negative pageSize",
+			Values:      []string{"pods=pageSize=-1"},
+			WantedError: true,
+		},
+		{
+			Desc:        "unknown option",
+			Values:      []string{"pods=limit=500"},
+			WantedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		listOptions := ResourceListOptions{}
+		var err error
+		for _, v := range test.Values {
+			if err = listOptions.Set(v); err != nil {
+				break
+			}
+		}
+		if test.WantedError {
+			if err == nil {
+				t.Errorf("Test error for Desc: %s. Want error. Got: %+v.", test.Desc, listOptions)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test error for Desc: %s. Unexpected error: %v.", test.Desc, err)
+		}
+		if !reflect.DeepEqual(listOptions, test.Wanted) {
+			t.Errorf("Test error for Desc: %s. Want: %+v. Got: %+v.", test.Desc, test.Wanted, listOptions)
+		}
+	}
+}
+
+func TestResourceListOptionsResource(t *testing.T) {
+	listOptions := ResourceListOptions{
+		"*":       {ResourceVersion: ListResourceVersionCache, PageSize: 500},
+		"pods":    {ResourceVersion: ListResourceVersionConsistent},
+		"secrets": {PageSize: 100},
+	}
+	for resource, want := range map[string]ListOptions{
+		"pods":    {ResourceVersion: ListResourceVersionConsistent, PageSize: 500},
+		"secrets": {ResourceVersion: ListResourceVersionCache, PageSize: 100},
+		"nodes":   {ResourceVersion: ListResourceVersionCache, PageSize: 500},
+	} {
+		if got := listOptions.Resource(resource); got != want {
+			t.Errorf("expected the list options %+v for %s, got %+v", want, resource, got)
+		}
+	}
+	if got := (ResourceListOptions(nil)).Resource("pods"); got != (ListOptions{}) {
+		t.Errorf("expected no list options without configuration, got %+v", got)
+	}
+}
+
 func TestMergeFieldSelectors(t *testing.T) {
 	tests := []struct {
 		Desc             string
@@ -392,3 +596,50 @@ func TestLabelsAllowListSet(t *testing.T) {
 		}
 	}
 }
+
+func TestBenchmarkObjectsSet(t *testing.T) {
+	tests := []struct {
+		Desc        string
+		Value       string
+		Wanted      BenchmarkObjects
+		WantedError bool
+	}{
+		{
+			Desc:   "multiple resources",
+			Value:  "pods=100000, deployments=5000",
+			Wanted: BenchmarkObjects{"pods": 100000, "deployments": 5000},
+		},
+		{
+			Desc:        "missing count",
+			Value:       "pods",
+			WantedError: true,
+		},
+		{
+			Desc:        "negative count",
+			Value:       "pods=-1",
+			WantedError: true,
+		},
+		{
+			Desc:        "invalid count",
+			Value:       "pods=many",
+			WantedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		objects := BenchmarkObjects{}
+		err := objects.Set(test.Value)
+		if test.WantedError {
+			if err == nil {
+				t.Errorf("Test error for Desc: %s. Want error. Got: %+v.", test.Desc, objects)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test error for Desc: %s. Unexpected error: %v.", test.Desc, err)
+		}
+		if !reflect.DeepEqual(objects, test.Wanted) {
+			t.Errorf("Test error for Desc: %s. Want: %+v. Got: %+v.", test.Desc, test.Wanted, objects)
+		}
+	}
+}
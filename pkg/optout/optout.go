@@ -0,0 +1,62 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package optout
+
+import (
+	"sort"
+	"strings"
+
+	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
+)
+
+// MetricFamilyFilter filters metric families which were opted out of at
+// startup. In contrast to the denylist, metric families are matched by their
+// exact name.
+type MetricFamilyFilter struct {
+	metrics map[string]struct{}
+}
+
+// Test tests if a given generator was not passed as an opt-out metric family at startup
+func (filter MetricFamilyFilter) Test(generator generator.FamilyGenerator) bool {
+	_, optedOut := filter.metrics[generator.Name]
+	return !optedOut
+}
+
+// Status returns the metrics contained within the filter as a comma-separated string
+func (filter MetricFamilyFilter) Status() string {
+	asStrings := make([]string, 0, len(filter.metrics))
+	for metric := range filter.metrics {
+		asStrings = append(asStrings, metric)
+	}
+	// sort the strings for the sake of ux such that the resulting status is consistent
+	sort.Strings(asStrings)
+	return strings.Join(asStrings, ", ")
+}
+
+// Count returns the amount of metrics contained within the filter
+func (filter MetricFamilyFilter) Count() int {
+	return len(filter.metrics)
+}
+
+// NewMetricFamilyFilter creates new MetricFamilyFilter instances.
+func NewMetricFamilyFilter(metrics map[string]struct{}) *MetricFamilyFilter {
+	m := make(map[string]struct{}, len(metrics))
+	for metric := range metrics {
+		m[metric] = struct{}{}
+	}
+	return &MetricFamilyFilter{m}
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package optout
+
+import (
+	"testing"
+
+	basemetrics "k8s.io/component-base/metrics"
+
+	"k8s.io/kube-state-metrics/v2/pkg/metric"
+	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
+)
+
+func TestFilter(t *testing.T) {
+	tests := []struct {
+		MetricFamily string
+		OptOutMetric string
+		Want         bool
+	}{
+		{"kube_pod_container_status_last_terminated_reason", "kube_pod_container_status_last_terminated_reason", false},
+		{"kube_pod_container_status_last_terminated_exitcode", "kube_pod_container_status_last_terminated_reason", true},
+		// Opt-out metrics are not regular expressions.
+		{"kube_pod_info", "kube_pod_.*", true},
+		{"kube_node_info", "", true},
+	}
+
+	for _, test := range tests {
+		filter := NewMetricFamilyFilter(map[string]struct{}{
+			test.OptOutMetric: {},
+		})
+
+		familyGenerator := *generator.NewFamilyGeneratorWithStability(
+			test.MetricFamily,
+			"",
+			metric.Gauge,
+			basemetrics.ALPHA,
+			"",
+			func(_ interface{}) *metric.Family {
+				return nil
+			},
+		)
+
+		result := filter.Test(familyGenerator)
+		if result != test.Want {
+			t.Errorf("the metric family %s did not pass the filter as expected, got: %v, want: %v", test.MetricFamily, result, test.Want)
+		}
+	}
+}
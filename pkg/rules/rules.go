@@ -0,0 +1,312 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package rules generates Prometheus recording and alerting rules which only
+// reference metric families kube-state-metrics is configured to expose.
+package rules
+
+import (
+	"strings"
+)
+
+// RuleGroups is the content of a Prometheus rules file.
+type RuleGroups struct {
+	Groups []RuleGroup `yaml:"groups"`
+}
+
+// RuleGroup is a named group of rules.
+type RuleGroup struct {
+	Name  string `yaml:"name"`
+	Rules []Rule `yaml:"rules"`
+}
+
+// Rule is a Prometheus recording or alerting rule.
+type Rule struct {
+	Record      string            `yaml:"record,omitempty"`
+	Alert       string            `yaml:"alert,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+	Expr        string            `yaml:"expr"`
+	For         string            `yaml:"for,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+}
+
+// candidate is a rule which is generated if all the metric families it
+// requires are exposed. jobPlaceholder in its expression is replaced by the
+// job of kube-state-metrics.
+type candidate struct {
+	rule     Rule
+	requires []string
+	// sharded is set for rules which are only generated with more than one
+	// shard.
+	sharded bool
+}
+
+const jobPlaceholder = "$JOB"
+
+var recordingRules = []candidate{
+	{
+		rule: Rule{
+			Record: "namespace_phase:kube_pod_status_phase:sum",
+			Expr:   `sum by (cluster, namespace, phase) (kube_pod_status_phase{job="$JOB"})`,
+		},
+		requires: []string{"kube_pod_status_phase"},
+	},
+	{
+		rule: Rule{
+			Record: "namespace:kube_pod_container_resource_requests_cpu_cores:sum",
+			Expr:   `sum by (cluster, namespace) (kube_pod_container_resource_requests{job="$JOB",resource="cpu"})`,
+		},
+		requires: []string{"kube_pod_container_resource_requests"},
+	},
+	{
+		rule: Rule{
+			Record: "namespace:kube_pod_container_resource_requests_memory_bytes:sum",
+			Expr:   `sum by (cluster, namespace) (kube_pod_container_resource_requests{job="$JOB",resource="memory"})`,
+		},
+		requires: []string{"kube_pod_container_resource_requests"},
+	},
+	{
+		rule: Rule{
+			Record: "namespace:kube_deployment_status_replicas_unavailable:sum",
+			Expr:   `sum by (cluster, namespace) (kube_deployment_status_replicas_unavailable{job="$JOB"})`,
+		},
+		requires: []string{"kube_deployment_status_replicas_unavailable"},
+	},
+}
+
+var alertingRules = []candidate{
+	{
+		rule: Rule{
+			Alert: "KubePodCrashLooping",
+			Annotations: map[string]string{
+				"description": `Pod {{ $labels.namespace }}/{{ $labels.pod }} ({{ $labels.container }}) is in waiting state (reason: "CrashLoopBackOff").`,
+				"summary":     "Pod is crash looping.",
+			},
+			Expr:   `max_over_time(kube_pod_container_status_waiting_reason{job="$JOB",reason="CrashLoopBackOff"}[5m]) >= 1`,
+			For:    "15m",
+			Labels: map[string]string{"severity": "warning"},
+		},
+		requires: []string{"kube_pod_container_status_waiting_reason"},
+	},
+	{
+		rule: Rule{
+			Alert: "KubePodNotReady",
+			Annotations: map[string]string{
+				"description": "Pod {{ $labels.namespace }}/{{ $labels.pod }} has been in a non-ready state for longer than 15 minutes.",
+				"summary":     "Pod has been in a non-ready state for more than 15 minutes.",
+			},
+			Expr:   `sum by (cluster, namespace, pod) (kube_pod_status_phase{job="$JOB",phase=~"Pending|Unknown|Failed"}) > 0`,
+			For:    "15m",
+			Labels: map[string]string{"severity": "warning"},
+		},
+		requires: []string{"kube_pod_status_phase"},
+	},
+	{
+		rule: Rule{
+			Alert: "KubeDeploymentReplicasMismatch",
+			Annotations: map[string]string{
+				"description": "Deployment {{ $labels.namespace }}/{{ $labels.deployment }} has not matched the expected number of replicas for longer than 15 minutes.",
+				"summary":     "Deployment has not matched the expected number of replicas.",
+			},
+			Expr:   `kube_deployment_spec_replicas{job="$JOB"} != kube_deployment_status_replicas_available{job="$JOB"}`,
+			For:    "15m",
+			Labels: map[string]string{"severity": "warning"},
+		},
+		requires: []string{"kube_deployment_spec_replicas", "kube_deployment_status_replicas_available"},
+	},
+	{
+		rule: Rule{
+			Alert: "KubeStatefulSetReplicasMismatch",
+			Annotations: map[string]string{
+				"description": "StatefulSet {{ $labels.namespace }}/{{ $labels.statefulset }} has not matched the expected number of replicas for longer than 15 minutes.",
+				"summary":     "StatefulSet has not matched the expected number of replicas.",
+			},
+			Expr:   `kube_statefulset_status_replicas_ready{job="$JOB"} != kube_statefulset_status_replicas{job="$JOB"}`,
+			For:    "15m",
+			Labels: map[string]string{"severity": "warning"},
+		},
+		requires: []string{"kube_statefulset_status_replicas_ready", "kube_statefulset_status_replicas"},
+	},
+	{
+		rule: Rule{
+			Alert: "KubeDaemonSetNotScheduled",
+			Annotations: map[string]string{
+				"description": "{{ $value }} Pods of DaemonSet {{ $labels.namespace }}/{{ $labels.daemonset }} are not scheduled.",
+				"summary":     "DaemonSet pods are not scheduled.",
+			},
+			Expr:   `kube_daemonset_status_desired_number_scheduled{job="$JOB"} - kube_daemonset_status_current_number_scheduled{job="$JOB"} > 0`,
+			For:    "10m",
+			Labels: map[string]string{"severity": "warning"},
+		},
+		requires: []string{"kube_daemonset_status_desired_number_scheduled", "kube_daemonset_status_current_number_scheduled"},
+	},
+	{
+		rule: Rule{
+			Alert: "KubeJobFailed",
+			Annotations: map[string]string{
+				"description": "Job {{ $labels.namespace }}/{{ $labels.job_name }} failed to complete.",
+				"summary":     "Job failed to complete.",
+			},
+			Expr:   `kube_job_failed{job="$JOB"} > 0`,
+			For:    "15m",
+			Labels: map[string]string{"severity": "warning"},
+		},
+		requires: []string{"kube_job_failed"},
+	},
+	{
+		rule: Rule{
+			Alert: "KubeNodeNotReady",
+			Annotations: map[string]string{
+				"description": "{{ $labels.node }} has been unready for more than 15 minutes.",
+				"summary":     "Node is not ready.",
+			},
+			Expr:   `kube_node_status_condition{job="$JOB",condition="Ready",status="true"} == 0`,
+			For:    "15m",
+			Labels: map[string]string{"severity": "warning"},
+		},
+		requires: []string{"kube_node_status_condition"},
+	},
+	{
+		rule: Rule{
+			Alert: "KubePersistentVolumeErrors",
+			Annotations: map[string]string{
+				"description": "The persistent volume {{ $labels.persistentvolume }} has status {{ $labels.phase }}.",
+				"summary":     "PersistentVolume is having issues with provisioning.",
+			},
+			Expr:   `kube_persistentvolume_status_phase{job="$JOB",phase=~"Failed|Pending"} > 0`,
+			For:    "5m",
+			Labels: map[string]string{"severity": "critical"},
+		},
+		requires: []string{"kube_persistentvolume_status_phase"},
+	},
+	{
+		rule: Rule{
+			Alert: "KubeHpaMaxedOut",
+			Annotations: map[string]string{
+				"description": "HPA {{ $labels.namespace }}/{{ $labels.horizontalpodautoscaler }} has been running at max replicas for longer than 15 minutes.",
+				"summary":     "HPA is running at max replicas.",
+			},
+			Expr:   `kube_horizontalpodautoscaler_status_current_replicas{job="$JOB"} == kube_horizontalpodautoscaler_spec_max_replicas{job="$JOB"}`,
+			For:    "15m",
+			Labels: map[string]string{"severity": "warning"},
+		},
+		requires: []string{"kube_horizontalpodautoscaler_status_current_replicas", "kube_horizontalpodautoscaler_spec_max_replicas"},
+	},
+}
+
+// selfAlertingRules alert on kube-state-metrics itself. They rely on self
+// metrics, which are always exposed.
+var selfAlertingRules = []candidate{
+	{
+		rule: Rule{
+			Alert: "KubeStateMetricsListErrors",
+			Annotations: map[string]string{
+				"description": "kube-state-metrics is experiencing errors at an elevated rate in list operations. This is likely causing it to not be able to expose metrics about Kubernetes objects correctly or at all.",
+				"summary":     "kube-state-metrics is experiencing errors in list operations.",
+			},
+			Expr: `(sum(rate(kube_state_metrics_list_total{job="$JOB",result="error"}[5m])) by (cluster)
+  /
+sum(rate(kube_state_metrics_list_total{job="$JOB"}[5m])) by (cluster))
+> 0.01`,
+			For:    "15m",
+			Labels: map[string]string{"severity": "critical"},
+		},
+	},
+	{
+		rule: Rule{
+			Alert: "KubeStateMetricsWatchErrors",
+			Annotations: map[string]string{
+				"description": "kube-state-metrics is experiencing errors at an elevated rate in watch operations. This is likely causing it to not be able to expose metrics about Kubernetes objects correctly or at all.",
+				"summary":     "kube-state-metrics is experiencing errors in watch operations.",
+			},
+			Expr: `(sum(rate(kube_state_metrics_watch_total{job="$JOB",result="error"}[5m])) by (cluster)
+  /
+sum(rate(kube_state_metrics_watch_total{job="$JOB"}[5m])) by (cluster))
+> 0.01`,
+			For:    "15m",
+			Labels: map[string]string{"severity": "critical"},
+		},
+	},
+	{
+		rule: Rule{
+			Alert: "KubeStateMetricsShardingMismatch",
+			Annotations: map[string]string{
+				"description": "kube-state-metrics pods are running with different --total-shards configuration, some Kubernetes objects may be exposed multiple times or not exposed at all.",
+				"summary":     "kube-state-metrics sharding is misconfigured.",
+			},
+			Expr:   `stdvar (kube_state_metrics_total_shards{job="$JOB"}) by (cluster) != 0`,
+			For:    "15m",
+			Labels: map[string]string{"severity": "critical"},
+		},
+		sharded: true,
+	},
+	{
+		rule: Rule{
+			Alert: "KubeStateMetricsShardsMissing",
+			Annotations: map[string]string{
+				"description": "kube-state-metrics shards are missing, some Kubernetes objects are not being exposed.",
+				"summary":     "kube-state-metrics shards are missing.",
+			},
+			Expr: `2^max(kube_state_metrics_total_shards{job="$JOB"}) by (cluster) - 1
+  -
+sum( 2 ^ max by (cluster, shard_ordinal) (kube_state_metrics_shard_ordinal{job="$JOB"}) ) by (cluster)
+!= 0`,
+			For:    "15m",
+			Labels: map[string]string{"severity": "critical"},
+		},
+		sharded: true,
+	},
+}
+
+// Generate returns the recording and alerting rules which only reference the
+// given metric families. Rules about sharding are only generated with more than
+// one shard. job is the job label of the scraped kube-state-metrics metrics.
+func Generate(families map[string]struct{}, totalShards int, job string) RuleGroups {
+	var groups RuleGroups
+	if rules := selectRules(recordingRules, families, totalShards, job); len(rules) > 0 {
+		groups.Groups = append(groups.Groups, RuleGroup{Name: "kube-state-metrics.rules", Rules: rules})
+	}
+	if rules := selectRules(alertingRules, families, totalShards, job); len(rules) > 0 {
+		groups.Groups = append(groups.Groups, RuleGroup{Name: "kubernetes-objects", Rules: rules})
+	}
+	groups.Groups = append(groups.Groups, RuleGroup{Name: "kube-state-metrics", Rules: selectRules(selfAlertingRules, families, totalShards, job)})
+	return groups
+}
+
+func selectRules(candidates []candidate, families map[string]struct{}, totalShards int, job string) []Rule {
+	var rules []Rule
+	for _, c := range candidates {
+		if c.sharded && totalShards <= 1 {
+			continue
+		}
+		if !exposed(c.requires, families) {
+			continue
+		}
+		r := c.rule
+		r.Expr = strings.ReplaceAll(r.Expr, jobPlaceholder, job)
+		rules = append(rules, r)
+	}
+	return rules
+}
+
+func exposed(required []string, families map[string]struct{}) bool {
+	for _, f := range required {
+		if _, ok := families[f]; !ok {
+			return false
+		}
+	}
+	return true
+}
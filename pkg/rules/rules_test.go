@@ -0,0 +1,79 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		Desc        string
+		Families    []string
+		TotalShards int
+		Wanted      map[string][]string
+	}{
+		{
+			Desc:        "no families and no sharding",
+			TotalShards: 1,
+			Wanted: map[string][]string{
+				"kube-state-metrics": {"KubeStateMetricsListErrors", "KubeStateMetricsWatchErrors"},
+			},
+		},
+		{
+			Desc:        "partially exposed families with sharding",
+			Families:    []string{"kube_pod_container_status_waiting_reason", "kube_deployment_spec_replicas"},
+			TotalShards: 2,
+			Wanted: map[string][]string{
+				"kubernetes-objects": {"KubePodCrashLooping"},
+				"kube-state-metrics": {"KubeStateMetricsListErrors", "KubeStateMetricsWatchErrors", "KubeStateMetricsShardingMismatch", "KubeStateMetricsShardsMissing"},
+			},
+		},
+		{
+			Desc:        "recording rules",
+			Families:    []string{"kube_pod_status_phase"},
+			TotalShards: 1,
+			Wanted: map[string][]string{
+				"kube-state-metrics.rules": {"namespace_phase:kube_pod_status_phase:sum"},
+				"kubernetes-objects":       {"KubePodNotReady"},
+				"kube-state-metrics":       {"KubeStateMetricsListErrors", "KubeStateMetricsWatchErrors"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		families := map[string]struct{}{}
+		for _, f := range test.Families {
+			families[f] = struct{}{}
+		}
+
+		got := map[string][]string{}
+		for _, g := range Generate(families, test.TotalShards, "ksm").Groups {
+			for _, r := range g.Rules {
+				got[g.Name] = append(got[g.Name], r.Record+r.Alert)
+				if strings.Contains(r.Expr, jobPlaceholder) || (strings.Contains(r.Expr, "job=") && !strings.Contains(r.Expr, `job="ksm"`)) {
+					t.Errorf("Test error for Desc: %s. Expected job to be replaced in expression of %s, got: %s", test.Desc, r.Record+r.Alert, r.Expr)
+				}
+			}
+		}
+		if !reflect.DeepEqual(got, test.Wanted) {
+			t.Errorf("Test error for Desc: %s. Want: %+v. Got: %+v.", test.Desc, test.Wanted, got)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+//
+//Copyright 2023 The Kubernetes Authors All rights reserved.
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and
+//limitations under the License.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: stream.proto
+
+package v1alpha1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	MetricsStream_StreamMetrics_FullMethodName = "/kubestatemetrics.v1alpha1.MetricsStream/StreamMetrics"
+)
+
+// MetricsStreamClient is the client API for MetricsStream service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// MetricsStream streams the metric families served on /metrics.
+type MetricsStreamClient interface {
+	// StreamMetrics sends all families first, followed by the families whose
+	// metrics changed since the previous response. Families are sent as a
+	// whole.
+	StreamMetrics(ctx context.Context, in *StreamMetricsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StreamMetricsResponse], error)
+}
+
+type metricsStreamClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMetricsStreamClient(cc grpc.ClientConnInterface) MetricsStreamClient {
+	return &metricsStreamClient{cc}
+}
+
+func (c *metricsStreamClient) StreamMetrics(ctx context.Context, in *StreamMetricsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StreamMetricsResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &MetricsStream_ServiceDesc.Streams[0], MetricsStream_StreamMetrics_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamMetricsRequest, StreamMetricsResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MetricsStream_StreamMetricsClient = grpc.ServerStreamingClient[StreamMetricsResponse]
+
+// MetricsStreamServer is the server API for MetricsStream service.
+// All implementations must embed UnimplementedMetricsStreamServer
+// for forward compatibility.
+//
+// MetricsStream streams the metric families served on /metrics.
+type MetricsStreamServer interface {
+	// StreamMetrics sends all families first, followed by the families whose
+	// metrics changed since the previous response. Families are sent as a
+	// whole.
+	StreamMetrics(*StreamMetricsRequest, grpc.ServerStreamingServer[StreamMetricsResponse]) error
+	mustEmbedUnimplementedMetricsStreamServer()
+}
+
+// UnimplementedMetricsStreamServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedMetricsStreamServer struct{}
+
+func (UnimplementedMetricsStreamServer) StreamMetrics(*StreamMetricsRequest, grpc.ServerStreamingServer[StreamMetricsResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamMetrics not implemented")
+}
+func (UnimplementedMetricsStreamServer) mustEmbedUnimplementedMetricsStreamServer() {}
+func (UnimplementedMetricsStreamServer) testEmbeddedByValue()                       {}
+
+// UnsafeMetricsStreamServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MetricsStreamServer will
+// result in compilation errors.
+type UnsafeMetricsStreamServer interface {
+	mustEmbedUnimplementedMetricsStreamServer()
+}
+
+func RegisterMetricsStreamServer(s grpc.ServiceRegistrar, srv MetricsStreamServer) {
+	// If the following call pancis, it indicates UnimplementedMetricsStreamServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&MetricsStream_ServiceDesc, srv)
+}
+
+func _MetricsStream_StreamMetrics_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamMetricsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MetricsStreamServer).StreamMetrics(m, &grpc.GenericServerStream[StreamMetricsRequest, StreamMetricsResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MetricsStream_StreamMetricsServer = grpc.ServerStreamingServer[StreamMetricsResponse]
+
+// MetricsStream_ServiceDesc is the grpc.ServiceDesc for MetricsStream service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var MetricsStream_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "kubestatemetrics.v1alpha1.MetricsStream",
+	HandlerType: (*MetricsStreamServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamMetrics",
+			Handler:       _MetricsStream_StreamMetrics_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "stream.proto",
+}
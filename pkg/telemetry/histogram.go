@@ -0,0 +1,69 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package telemetry configures the self metrics of kube-state-metrics.
+package telemetry
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// nativeHistogramBucketFactor is the growth factor of the buckets of
+	// native histograms, which bounds their relative error to about 5%.
+	nativeHistogramBucketFactor = 1.1
+	// nativeHistogramMaxBuckets is the maximum number of buckets of native
+	// histograms, after which the resolution is reduced.
+	nativeHistogramMaxBuckets = 100
+	// nativeHistogramMinResetDuration is the minimum duration after which
+	// native histograms are reset instead of reducing their resolution.
+	nativeHistogramMinResetDuration = time.Hour
+)
+
+var (
+	nativeHistograms  bool
+	classicHistograms = true
+)
+
+// SetHistogramOptions configures whether the histograms of the self metrics
+// are native histograms, classic histograms with fixed buckets, or both. Native
+// histograms are only exposed to scrapers negotiating the protobuf format.
+// It should be called before any self metrics are created.
+func SetHistogramOptions(native, classic bool) error {
+	if !native && !classic {
+		return fmt.Errorf("at least one of native and classic histograms must be enabled")
+	}
+	nativeHistograms = native
+	classicHistograms = classic
+	return nil
+}
+
+// HistogramOpts returns the given options with the configured kinds of
+// histograms applied.
+func HistogramOpts(opts prometheus.HistogramOpts) prometheus.HistogramOpts {
+	if nativeHistograms {
+		opts.NativeHistogramBucketFactor = nativeHistogramBucketFactor
+		opts.NativeHistogramMaxBucketNumber = nativeHistogramMaxBuckets
+		opts.NativeHistogramMinResetDuration = nativeHistogramMinResetDuration
+		if !classicHistograms {
+			opts.Buckets = nil
+		}
+	}
+	return opts
+}
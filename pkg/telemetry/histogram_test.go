@@ -0,0 +1,63 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package telemetry
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestHistogramOpts(t *testing.T) {
+	defer func() {
+		if err := SetHistogramOptions(false, true); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	tests := []struct {
+		native, classic bool
+		wantNative      bool
+		wantBuckets     int
+	}{
+		{native: false, classic: true, wantBuckets: len(prometheus.DefBuckets)},
+		{native: true, classic: true, wantNative: true, wantBuckets: len(prometheus.DefBuckets)},
+		{native: true, classic: false, wantNative: true},
+	}
+	for _, tt := range tests {
+		if err := SetHistogramOptions(tt.native, tt.classic); err != nil {
+			t.Fatal(err)
+		}
+		h := prometheus.NewHistogram(HistogramOpts(prometheus.HistogramOpts{Name: "test_duration_seconds", Help: "Test.", Buckets: prometheus.DefBuckets}))
+		h.Observe(0.3)
+		m := &dto.Metric{}
+		if err := h.Write(m); err != nil {
+			t.Fatal(err)
+		}
+		if got := len(m.GetHistogram().GetBucket()); got != tt.wantBuckets {
+			t.Errorf("native=%t classic=%t: expected %d classic buckets, got %d", tt.native, tt.classic, tt.wantBuckets, got)
+		}
+		if got := m.GetHistogram().Schema != nil; got != tt.wantNative {
+			t.Errorf("native=%t classic=%t: expected native histogram %t, got %t", tt.native, tt.classic, tt.wantNative, got)
+		}
+	}
+
+	if err := SetHistogramOptions(false, false); err == nil {
+		t.Error("expected an error with neither native nor classic histograms")
+	}
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package memlimit detects the memory limit of the cgroup of the process and
+// configures the Go memory limit accordingly.
+package memlimit
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"runtime/debug"
+	"strconv"
+	"strings"
+)
+
+const (
+	cgroupV2Path = "/sys/fs/cgroup/memory.max"
+	cgroupV1Path = "/sys/fs/cgroup/memory/memory.limit_in_bytes"
+
+	// unlimitedV1 is the lower bound of the values cgroup v1 reports if no
+	// memory limit is set, rounded down to the page size of the host.
+	unlimitedV1 = 1 << 62
+)
+
+// CgroupLimit returns the memory limit of the cgroup of the process in bytes,
+// or 0 if no memory limit is set or no cgroup filesystem is mounted.
+func CgroupLimit() (int64, error) {
+	return cgroupLimit(cgroupV2Path, cgroupV1Path)
+}
+
+func cgroupLimit(paths ...string) (int64, error) {
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to read memory limit: %w", err)
+		}
+		value := strings.TrimSpace(string(data))
+		if value == "max" {
+			return 0, nil
+		}
+		limit, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse memory limit %q of %s: %w", value, path, err)
+		}
+		if limit <= 0 || limit >= unlimitedV1 {
+			return 0, nil
+		}
+		return limit, nil
+	}
+	return 0, nil
+}
+
+// Configure sets the Go memory limit to the given ratio of the memory limit
+// of the cgroup, unless the GOMEMLIMIT environment variable is set or the
+// ratio is 0. It returns the memory limit of the process, which is the cgroup
+// memory limit or else the Go memory limit, or 0 if neither is set.
+func Configure(ratio float64) (int64, error) {
+	limit, err := CgroupLimit()
+	if err != nil {
+		return 0, err
+	}
+	if limit > 0 && ratio > 0 && os.Getenv("GOMEMLIMIT") == "" {
+		debug.SetMemoryLimit(int64(float64(limit) * ratio))
+	}
+	if limit > 0 {
+		return limit, nil
+	}
+	// A negative limit only reads the current Go memory limit.
+	if goLimit := debug.SetMemoryLimit(-1); goLimit != math.MaxInt64 {
+		return goLimit, nil
+	}
+	return 0, nil
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package memlimit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCgroupLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    int64
+		wantErr bool
+	}{
+		{name: "limit", content: "536870912\n", want: 536870912},
+		{name: "cgroup v2 without limit", content: "max\n", want: 0},
+		{name: "cgroup v1 without limit", content: "9223372036854771712\n", want: 0},
+		{name: "invalid", content: "lots\n", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "memory.max")
+			if err := os.WriteFile(path, []byte(test.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			got, err := cgroupLimit(filepath.Join(t.TempDir(), "missing"), path)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != test.want {
+				t.Errorf("expected limit %d, got %d", test.want, got)
+			}
+		})
+	}
+
+	got, err := cgroupLimit(filepath.Join(t.TempDir(), "missing"))
+	if err != nil || got != 0 {
+		t.Errorf("expected no limit without cgroup filesystem, got %d, %v", got, err)
+	}
+}
@@ -27,6 +27,7 @@ import (
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/klog/v2"
@@ -39,6 +40,7 @@ var config *rest.Config
 var currentKubeClient clientset.Interface
 var currentDiscoveryClient *discovery.DiscoveryClient
 var currentDynamicClient *dynamic.DynamicClient
+var currentMetadataClient metadata.Interface
 
 // CreateKubeClient creates a Kubernetes clientset and a custom resource clientset.
 func CreateKubeClient(apiserver string, kubeconfig string) (clientset.Interface, error) {
@@ -134,6 +136,22 @@ func CreateDynamicClient(apiserver string, kubeconfig string) (*dynamic.DynamicC
 	return currentDynamicClient, err
 }
 
+// CreateMetadataClient creates a Kubernetes metadata client.
+func CreateMetadataClient(apiserver string, kubeconfig string) (metadata.Interface, error) {
+	if currentMetadataClient != nil {
+		return currentMetadataClient, nil
+	}
+	var err error
+	if config == nil {
+		config, err = clientcmd.BuildConfigFromFlags(apiserver, kubeconfig)
+		if err != nil {
+			return nil, err
+		}
+	}
+	currentMetadataClient, err = metadata.NewForConfig(config)
+	return currentMetadataClient, err
+}
+
 // GVRFromType returns the GroupVersionResource for a given type.
 func GVRFromType(resourceName string, expectedType interface{}) *schema.GroupVersionResource {
 	if _, ok := expectedType.(*testUnstructuredMock.Foo); ok {
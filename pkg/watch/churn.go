@@ -0,0 +1,113 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package watch
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/tools/cache"
+)
+
+// ObjectChurnMetrics stores the pointers of
+// kube_state_metrics_object_[adds|updates|deletes]_total metrics.
+type ObjectChurnMetrics struct {
+	AddsTotal    *prometheus.CounterVec
+	UpdatesTotal *prometheus.CounterVec
+	DeletesTotal *prometheus.CounterVec
+}
+
+// NewObjectChurnMetrics takes in a prometheus registry and initializes
+// and registers the kube_state_metrics_object_adds_total,
+// kube_state_metrics_object_updates_total and
+// kube_state_metrics_object_deletes_total metrics. It returns those
+// registered metrics.
+func NewObjectChurnMetrics(r prometheus.Registerer) *ObjectChurnMetrics {
+	return &ObjectChurnMetrics{
+		AddsTotal: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "kube_state_metrics_object_adds_total",
+				Help: "Number of objects added to kube-state-metrics by watch events",
+			},
+			[]string{"resource", "namespace"},
+		),
+		UpdatesTotal: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "kube_state_metrics_object_updates_total",
+				Help: "Number of objects updated in kube-state-metrics by watch events",
+			},
+			[]string{"resource", "namespace"},
+		),
+		DeletesTotal: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "kube_state_metrics_object_deletes_total",
+				Help: "Number of objects deleted from kube-state-metrics by watch events",
+			},
+			[]string{"resource", "namespace"},
+		),
+	}
+}
+
+// InstrumentedStore provides the kube_state_metrics_object_*_total metrics
+// with a cache.Store obj and the related resource. Objects passed to Replace
+// on (re)lists are not accounted, as they do not reflect individual changes.
+type InstrumentedStore struct {
+	cache.Store
+	metrics  *ObjectChurnMetrics
+	resource string
+}
+
+// NewInstrumentedStore returns a new InstrumentedStore.
+func NewInstrumentedStore(store cache.Store, metrics *ObjectChurnMetrics, resource string) cache.Store {
+	return &InstrumentedStore{
+		Store:    store,
+		metrics:  metrics,
+		resource: resource,
+	}
+}
+
+// Add is a wrapper func around the cache.Store.Add func. It increases the
+// adds counter of the object's namespace.
+func (i *InstrumentedStore) Add(obj interface{}) error {
+	i.metrics.AddsTotal.WithLabelValues(i.resource, objectNamespace(obj)).Inc()
+	return i.Store.Add(obj)
+}
+
+// Update is a wrapper func around the cache.Store.Update func. It increases
+// the updates counter of the object's namespace.
+func (i *InstrumentedStore) Update(obj interface{}) error {
+	i.metrics.UpdatesTotal.WithLabelValues(i.resource, objectNamespace(obj)).Inc()
+	return i.Store.Update(obj)
+}
+
+// Delete is a wrapper func around the cache.Store.Delete func. It increases
+// the deletes counter of the object's namespace.
+func (i *InstrumentedStore) Delete(obj interface{}) error {
+	i.metrics.DeletesTotal.WithLabelValues(i.resource, objectNamespace(obj)).Inc()
+	return i.Store.Delete(obj)
+}
+
+func objectNamespace(obj interface{}) string {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	o, err := meta.Accessor(obj)
+	if err != nil {
+		return ""
+	}
+	return o.GetNamespace()
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package watch
+
+import (
+	"context"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var filteredObjectsDesc = prometheus.NewDesc(
+	"kube_state_metrics_filtered_objects",
+	"Number of objects of the resource which are currently dropped by the filter, so that no metrics are generated for them. Objects excluded by field selectors are never received and not counted.",
+	[]string{"resource", "filter"}, nil,
+)
+
+// FilterMetrics exposes the number of metric families and objects which are
+// filtered per resource, so that operators can verify their filters.
+type FilterMetrics struct {
+	families *prometheus.GaugeVec
+
+	mutex sync.Mutex
+	// counters holds the functions which count the filtered objects of the
+	// running stores.
+	counters map[*filterCounter]struct{}
+}
+
+type filterCounter struct {
+	resource string
+	filter   string
+	count    func() int
+}
+
+// NewFilterMetrics takes in a prometheus registry and initializes and
+// registers the metrics of filtered metric families and objects.
+func NewFilterMetrics(r prometheus.Registerer) *FilterMetrics {
+	m := &FilterMetrics{
+		families: promauto.With(r).NewGaugeVec(prometheus.GaugeOpts{
+			Name: "kube_state_metrics_filtered_families",
+			Help: "Number of metric families of the resource which are not exposed because of the filter.",
+		}, []string{"resource", "filter"}),
+		counters: map[*filterCounter]struct{}{},
+	}
+	r.MustRegister(m)
+	return m
+}
+
+// ObserveFamilies records the number of metric families of the resource which
+// are rejected by each filter, replacing the previous numbers.
+func (m *FilterMetrics) ObserveFamilies(resource string, rejected map[string]int) {
+	m.families.DeletePartialMatch(prometheus.Labels{"resource": resource})
+	for filter, count := range rejected {
+		m.families.WithLabelValues(resource, filter).Set(float64(count))
+	}
+}
+
+// TrackObjects exposes the number of objects of the resource which are dropped
+// by the filter, as returned by count, until the context is done.
+func (m *FilterMetrics) TrackObjects(ctx context.Context, resource, filter string, count func() int) {
+	c := &filterCounter{resource: resource, filter: filter, count: count}
+	m.mutex.Lock()
+	m.counters[c] = struct{}{}
+	m.mutex.Unlock()
+	go func() {
+		<-ctx.Done()
+		m.mutex.Lock()
+		delete(m.counters, c)
+		m.mutex.Unlock()
+	}()
+}
+
+// Describe implements the prometheus.Collector interface.
+func (m *FilterMetrics) Describe(ch chan<- *prometheus.Desc) {
+	ch <- filteredObjectsDesc
+}
+
+// Collect implements the prometheus.Collector interface.
+func (m *FilterMetrics) Collect(ch chan<- prometheus.Metric) {
+	type key struct{ resource, filter string }
+	counts := map[key]int{}
+	m.mutex.Lock()
+	for c := range m.counters {
+		counts[key{c.resource, c.filter}] += c.count()
+	}
+	m.mutex.Unlock()
+	for k, count := range counts {
+		ch <- prometheus.MustNewConstMetric(filteredObjectsDesc, prometheus.GaugeValue, float64(count), k.resource, k.filter)
+	}
+}
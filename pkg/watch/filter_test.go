@@ -0,0 +1,65 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package watch
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestFilterMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewFilterMetrics(reg)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.TrackObjects(ctx, "pods", "namespace_denylist", func() int { return 3 })
+	m.TrackObjects(context.Background(), "pods", "namespace_denylist", func() int { return 2 })
+	m.ObserveFamilies("pods", map[string]int{"allow_deny_list": 2, "opt_in": 1})
+	// Families are replaced when the resource is rebuilt.
+	m.ObserveFamilies("pods", map[string]int{"allow_deny_list": 4})
+
+	expected := `
+# HELP kube_state_metrics_filtered_families Number of metric families of the resource which are not exposed because of the filter.
+# TYPE kube_state_metrics_filtered_families gauge
+kube_state_metrics_filtered_families{filter="allow_deny_list",resource="pods"} 4
+# HELP kube_state_metrics_filtered_objects Number of objects of the resource which are currently dropped by the filter, so that no metrics are generated for them. Objects excluded by field selectors are never received and not counted.
+# TYPE kube_state_metrics_filtered_objects gauge
+kube_state_metrics_filtered_objects{filter="namespace_denylist",resource="pods"} 5
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(expected)); err != nil {
+		t.Error(err)
+	}
+
+	// Stores are no longer counted once stopped.
+	cancel()
+	expected = strings.Replace(expected, "} 5", "} 2", 1)
+	var err error
+	for i := 0; i < 100; i++ {
+		if err = testutil.GatherAndCompare(reg, strings.NewReader(expected)); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,356 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package watch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// NotificationAdded is the type of notifications of added objects.
+	NotificationAdded = "added"
+	// NotificationDeleted is the type of notifications of deleted objects.
+	NotificationDeleted = "deleted"
+	// NotificationConditionChanged is the type of notifications of objects
+	// whose status of a condition changed.
+	NotificationConditionChanged = "condition_changed"
+
+	// notificationQueueSize is the number of notifications which are queued
+	// for delivery, before further notifications are dropped.
+	notificationQueueSize = 1024
+	// notificationTimeout is the timeout of a request delivering a
+	// notification.
+	notificationTimeout = 10 * time.Second
+)
+
+// Notification is a significant change of an object, POSTed as JSON to the
+// URL of a Notifier.
+type Notification struct {
+	Type      string `json:"type"`
+	Resource  string `json:"resource"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	UID       string `json:"uid,omitempty"`
+	// Condition, Status and PreviousStatus are set for notifications of
+	// changed conditions.
+	Condition      string    `json:"condition,omitempty"`
+	Status         string    `json:"status,omitempty"`
+	PreviousStatus string    `json:"previousStatus,omitempty"`
+	Time           time.Time `json:"time"`
+}
+
+// Notifier POSTs notifications of added and deleted objects and of changed
+// conditions of objects of the selected resources to a URL. Notifications
+// are derived from the changes of the stores, see NewNotifyingStore, and
+// delivered asynchronously, so that slow endpoints don't delay the stores.
+// Notifications which can't be queued or delivered are dropped.
+type Notifier struct {
+	url    string
+	client *http.Client
+	// resources are the resources whose objects are notified about, all
+	// resources if empty.
+	resources map[string]struct{}
+	queue     chan Notification
+	now       func() time.Time
+
+	notificationsTotal *prometheus.CounterVec
+}
+
+// NewNotifier returns a Notifier which POSTs the notifications about objects
+// of the given resources, or of all resources if there are none, to url. It
+// registers the kube_state_metrics_notifications_total metric with r.
+func NewNotifier(url string, resources []string, r prometheus.Registerer) *Notifier {
+	n := &Notifier{
+		url:       url,
+		client:    &http.Client{Timeout: notificationTimeout},
+		resources: map[string]struct{}{},
+		queue:     make(chan Notification, notificationQueueSize),
+		now:       time.Now,
+		notificationsTotal: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "kube_state_metrics_notifications_total",
+				Help: "Number of notifications about changes of objects by result, i.e. sent, failed or dropped because the queue was full.",
+			},
+			[]string{"result"},
+		),
+	}
+	for _, resource := range resources {
+		n.resources[resource] = struct{}{}
+	}
+	return n
+}
+
+// Watches returns whether objects of the resource are notified about.
+func (n *Notifier) Watches(resource string) bool {
+	if n == nil {
+		return false
+	}
+	if len(n.resources) == 0 {
+		return true
+	}
+	_, ok := n.resources[resource]
+	return ok
+}
+
+// Run delivers the queued notifications until the context is done.
+func (n *Notifier) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case notification := <-n.queue:
+			if err := n.send(ctx, notification); err != nil {
+				klog.ErrorS(err, "Failed to send notification", "type", notification.Type, "resource", notification.Resource, "object", klog.KRef(notification.Namespace, notification.Name))
+				n.notificationsTotal.WithLabelValues("failed").Inc()
+				continue
+			}
+			n.notificationsTotal.WithLabelValues("sent").Inc()
+		}
+	}
+}
+
+// send POSTs the notification.
+func (n *Notifier) send(ctx context.Context, notification Notification) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// notify queues a notification about the object, dropping it if the queue
+// is full.
+func (n *Notifier) notify(notification Notification, obj interface{}) {
+	if m, err := meta.Accessor(obj); err == nil {
+		notification.Namespace = m.GetNamespace()
+		notification.Name = m.GetName()
+		notification.UID = string(m.GetUID())
+	}
+	notification.Time = n.now()
+	select {
+	case n.queue <- notification:
+	default:
+		n.notificationsTotal.WithLabelValues("dropped").Inc()
+	}
+}
+
+// NotifyingStore wraps a cache.Store and notifies about the objects which
+// are added to and deleted from it, and about changes of the status of their
+// conditions. The objects of the first Replace, i.e. the initial list, are
+// not notified about, while later Replaces, i.e. relists, notify about the
+// changes since the previous state.
+type NotifyingStore struct {
+	cache.Store
+	notifier *Notifier
+	resource string
+
+	mutex    sync.Mutex
+	replaced bool
+	// conditions holds the status of each condition of each object by key.
+	conditions map[string]map[string]string
+}
+
+// NewNotifyingStore returns a NotifyingStore notifying about the objects of
+// the resource in store.
+func NewNotifyingStore(store cache.Store, notifier *Notifier, resource string) cache.Store {
+	return &NotifyingStore{
+		Store:      store,
+		notifier:   notifier,
+		resource:   resource,
+		conditions: map[string]map[string]string{},
+	}
+}
+
+// Add implements the Add method of the store interface.
+func (s *NotifyingStore) Add(obj interface{}) error {
+	if err := s.Store.Add(obj); err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.observe(obj)
+	return nil
+}
+
+// Update implements the Update method of the store interface.
+func (s *NotifyingStore) Update(obj interface{}) error {
+	if err := s.Store.Update(obj); err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.observe(obj)
+	return nil
+}
+
+// Delete implements the Delete method of the store interface.
+func (s *NotifyingStore) Delete(obj interface{}) error {
+	if err := s.Store.Delete(obj); err != nil {
+		return err
+	}
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		return nil
+	}
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, ok := s.conditions[key]; ok {
+		delete(s.conditions, key)
+		s.notifier.notify(Notification{Type: NotificationDeleted, Resource: s.resource}, obj)
+	}
+	return nil
+}
+
+// Replace implements the Replace method of the store interface.
+func (s *NotifyingStore) Replace(list []interface{}, resourceVersion string) error {
+	if err := s.Store.Replace(list, resourceVersion); err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.replaced {
+		s.replaced = true
+		for _, obj := range list {
+			if key, err := cache.MetaNamespaceKeyFunc(obj); err == nil {
+				s.conditions[key] = objectConditions(obj)
+			}
+		}
+		return nil
+	}
+
+	listed := make(map[string]struct{}, len(list))
+	for _, obj := range list {
+		if key, err := cache.MetaNamespaceKeyFunc(obj); err == nil {
+			listed[key] = struct{}{}
+		}
+		s.observe(obj)
+	}
+	for key := range s.conditions {
+		if _, ok := listed[key]; ok {
+			continue
+		}
+		delete(s.conditions, key)
+		namespace, name, _ := cache.SplitMetaNamespaceKey(key)
+		s.notifier.notify(Notification{Type: NotificationDeleted, Resource: s.resource, Namespace: namespace, Name: name}, nil)
+	}
+	return nil
+}
+
+// observe notifies about the object if it was added or the status of its
+// conditions changed. The mutex must be held by the caller.
+func (s *NotifyingStore) observe(obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		return
+	}
+	conditions := objectConditions(obj)
+	previous, ok := s.conditions[key]
+	s.conditions[key] = conditions
+	if !ok {
+		s.notifier.notify(Notification{Type: NotificationAdded, Resource: s.resource}, obj)
+		return
+	}
+	for condition, status := range conditions {
+		if previous[condition] == status {
+			continue
+		}
+		s.notifier.notify(Notification{
+			Type:           NotificationConditionChanged,
+			Resource:       s.resource,
+			Condition:      condition,
+			Status:         status,
+			PreviousStatus: previous[condition],
+		}, obj)
+	}
+}
+
+// objectConditions returns the status of each condition in the
+// status.conditions of the object, whose elements have a type and status.
+func objectConditions(obj interface{}) map[string]string {
+	conditions := map[string]string{}
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		list, _, _ := unstructured.NestedSlice(u.Object, "status", "conditions")
+		for _, c := range list {
+			c, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			conditionType, _, _ := unstructured.NestedString(c, "type")
+			status, _, _ := unstructured.NestedString(c, "status")
+			if conditionType != "" {
+				conditions[conditionType] = status
+			}
+		}
+		return conditions
+	}
+
+	v := reflect.Indirect(reflect.ValueOf(obj))
+	if v.Kind() != reflect.Struct {
+		return conditions
+	}
+	status := v.FieldByName("Status")
+	if status.Kind() != reflect.Struct {
+		return conditions
+	}
+	list := status.FieldByName("Conditions")
+	if list.Kind() != reflect.Slice {
+		return conditions
+	}
+	for i := 0; i < list.Len(); i++ {
+		c := reflect.Indirect(list.Index(i))
+		if c.Kind() != reflect.Struct {
+			continue
+		}
+		conditionType, status := c.FieldByName("Type"), c.FieldByName("Status")
+		if conditionType.Kind() != reflect.String || status.Kind() != reflect.String || conditionType.String() == "" {
+			continue
+		}
+		conditions[conditionType.String()] = status.String()
+	}
+	return conditions
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestNotifyingStore(t *testing.T) {
+	n := NewNotifier("http://localhost", []string{"pods"}, prometheus.NewRegistry())
+	n.now = func() time.Time { return time.Time{} }
+	if !n.Watches("pods") || n.Watches("nodes") {
+		t.Fatal("expected only pods to be watched")
+	}
+	s := NewNotifyingStore(cache.NewStore(cache.MetaNamespaceKeyFunc), n, "pods")
+	pod := func(name string, ready v1.ConditionStatus) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, UID: types.UID("uid-" + name)},
+			Status:     v1.PodStatus{Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: ready}}},
+		}
+	}
+
+	// The objects of the initial list are not notified about.
+	if err := s.Replace([]interface{}{pod("a", v1.ConditionTrue)}, "1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Add(pod("b", v1.ConditionFalse)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Update(pod("b", v1.ConditionFalse)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Update(pod("a", v1.ConditionFalse)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete(pod("b", v1.ConditionFalse)); err != nil {
+		t.Fatal(err)
+	}
+	// Relists notify about the changes since the previous state.
+	if err := s.Replace([]interface{}{pod("c", v1.ConditionTrue)}, "2"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Notification{
+		{Type: NotificationAdded, Resource: "pods", Namespace: "default", Name: "b", UID: "uid-b"},
+		{Type: NotificationConditionChanged, Resource: "pods", Namespace: "default", Name: "a", UID: "uid-a", Condition: "Ready", Status: "False", PreviousStatus: "True"},
+		{Type: NotificationDeleted, Resource: "pods", Namespace: "default", Name: "b", UID: "uid-b"},
+		{Type: NotificationAdded, Resource: "pods", Namespace: "default", Name: "c", UID: "uid-c"},
+		{Type: NotificationDeleted, Resource: "pods", Namespace: "default", Name: "a"},
+	}
+	var got []Notification
+	for len(n.queue) > 0 {
+		got = append(got, <-n.queue)
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected notifications\n%+v\ngot\n%+v", expected, got)
+	}
+}
+
+func TestNotifierRun(t *testing.T) {
+	received := make(chan Notification, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var notification Notification
+		if err := json.NewDecoder(r.Body).Decode(&notification); err != nil {
+			t.Error(err)
+		}
+		received <- notification
+	}))
+	defer server.Close()
+
+	n := NewNotifier(server.URL, nil, prometheus.NewRegistry())
+	if !n.Watches("nodes") {
+		t.Fatal("expected all resources to be watched")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go n.Run(ctx)
+
+	n.notify(Notification{Type: NotificationAdded, Resource: "nodes"}, &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}})
+	select {
+	case notification := <-received:
+		if notification.Type != NotificationAdded || notification.Name != "node" {
+			t.Errorf("unexpected notification %+v", notification)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for the notification")
+	}
+}
@@ -0,0 +1,229 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package watch
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// circuitFailureThreshold is the number of consecutive failed list
+	// requests after which the circuit opens.
+	circuitFailureThreshold = 5
+	// minCircuitCooldown and maxCircuitCooldown bound the time the circuit
+	// stays open, which doubles each time it opens again without a list
+	// request succeeding in between.
+	minCircuitCooldown = 5 * time.Second
+	maxCircuitCooldown = 2 * time.Minute
+)
+
+// ErrCircuitOpen is returned for list requests while the circuit is open.
+var ErrCircuitOpen = errors.New("list requests are paused after repeated failures of the apiserver")
+
+// ListPacingMetrics stores the pointers of the metrics of the pacing of list
+// requests.
+type ListPacingMetrics struct {
+	Limit       prometheus.Gauge
+	InFlight    prometheus.Gauge
+	Waiting     prometheus.Gauge
+	CircuitOpen prometheus.Gauge
+}
+
+// NewListPacingMetrics takes in a prometheus registry and initializes and
+// registers the metrics of the pacing of list requests. It returns those
+// registered metrics.
+func NewListPacingMetrics(r prometheus.Registerer) *ListPacingMetrics {
+	return &ListPacingMetrics{
+		Limit: promauto.With(r).NewGauge(prometheus.GaugeOpts{
+			Name: "kube_state_metrics_list_concurrency_limit",
+			Help: "Current limit of concurrent list requests, adapted to the responses of the apiserver. 0 if list requests are not paced.",
+		}),
+		InFlight: promauto.With(r).NewGauge(prometheus.GaugeOpts{
+			Name: "kube_state_metrics_list_in_flight",
+			Help: "Number of paced list requests in flight.",
+		}),
+		Waiting: promauto.With(r).NewGauge(prometheus.GaugeOpts{
+			Name: "kube_state_metrics_list_waiting",
+			Help: "Number of list requests waiting for the concurrency limit.",
+		}),
+		CircuitOpen: promauto.With(r).NewGauge(prometheus.GaugeOpts{
+			Name: "kube_state_metrics_list_circuit_open",
+			Help: "Whether list requests are paused after repeated failures of the apiserver.",
+		}),
+	}
+}
+
+// ListPacer limits the concurrent list requests of all reflectors, so that
+// starting on a large cluster or the recovery of the apiserver does not cause
+// a storm of full list requests. The limit is halved whenever the apiserver
+// signals overload and increased by one with every successful request, up to
+// the configured maximum. After repeated failures of the apiserver, list
+// requests fail right away for a cooldown period, after which they are
+// retried one at a time.
+type ListPacer struct {
+	maxLimit int
+	metrics  *ListPacingMetrics
+	now      func() time.Time
+
+	mutex     sync.Mutex
+	cond      *sync.Cond
+	limit     int
+	inFlight  int
+	waiting   int
+	failures  int
+	openUntil time.Time
+	cooldown  time.Duration
+}
+
+// NewListPacer returns a ListPacer which allows up to maxLimit concurrent
+// list requests. metrics may be nil.
+func NewListPacer(maxLimit int, metrics *ListPacingMetrics) *ListPacer {
+	p := &ListPacer{
+		maxLimit: maxLimit,
+		metrics:  metrics,
+		now:      time.Now,
+		limit:    maxLimit,
+		cooldown: minCircuitCooldown,
+	}
+	p.cond = sync.NewCond(&p.mutex)
+	p.updateMetrics()
+	return p
+}
+
+// acquire waits until a list request may be sent. It returns ErrCircuitOpen
+// while the circuit is open.
+func (p *ListPacer) acquire() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.waiting++
+	defer func() {
+		p.waiting--
+		p.updateMetrics()
+	}()
+	for {
+		if p.now().Before(p.openUntil) {
+			return ErrCircuitOpen
+		}
+		if p.inFlight < p.limit {
+			p.inFlight++
+			return nil
+		}
+		p.updateMetrics()
+		p.cond.Wait()
+	}
+}
+
+// release adapts the limit to the result of a list request.
+func (p *ListPacer) release(err error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.inFlight--
+	switch {
+	case err == nil:
+		p.failures = 0
+		p.cooldown = minCircuitCooldown
+		if p.limit < p.maxLimit {
+			p.limit++
+		}
+	case overloaded(err):
+		p.failures++
+		if p.limit > 1 {
+			p.limit /= 2
+		}
+	case unreachable(err):
+		p.failures++
+	}
+	if p.failures >= circuitFailureThreshold {
+		klog.ErrorS(err, "Pausing list requests after repeated failures of the apiserver", "cooldown", p.cooldown)
+		p.openUntil = p.now().Add(p.cooldown)
+		p.cooldown *= 2
+		if p.cooldown > maxCircuitCooldown {
+			p.cooldown = maxCircuitCooldown
+		}
+		p.failures = 0
+		// Requests are retried one at a time once the circuit closes.
+		p.limit = 1
+	}
+	p.cond.Broadcast()
+	p.updateMetrics()
+}
+
+func (p *ListPacer) updateMetrics() {
+	if p.metrics == nil {
+		return
+	}
+	p.metrics.Limit.Set(float64(p.limit))
+	p.metrics.InFlight.Set(float64(p.inFlight))
+	p.metrics.Waiting.Set(float64(p.waiting))
+	if p.now().Before(p.openUntil) {
+		p.metrics.CircuitOpen.Set(1)
+	} else {
+		p.metrics.CircuitOpen.Set(0)
+	}
+}
+
+// overloaded returns whether the error signals that the apiserver is
+// overloaded, e.g. by priority and fairness.
+func overloaded(err error) bool {
+	return apierrors.IsTooManyRequests(err) || apierrors.IsServerTimeout(err) || apierrors.IsTimeout(err) || apierrors.IsServiceUnavailable(err)
+}
+
+// unreachable returns whether the error signals that the apiserver failed
+// or could not be reached, as opposed to rejecting the request, e.g. due to
+// missing permissions.
+func unreachable(err error) bool {
+	var status apierrors.APIStatus
+	return apierrors.IsInternalError(err) || !errors.As(err, &status)
+}
+
+// PacedListerWatcher paces the list requests of a cache.ListerWatcher with a
+// ListPacer.
+type PacedListerWatcher struct {
+	cache.ListerWatcher
+	pacer *ListPacer
+}
+
+// NewPacedListerWatcher returns a PacedListerWatcher, or lw if pacer is nil.
+func NewPacedListerWatcher(lw cache.ListerWatcher, pacer *ListPacer) cache.ListerWatcher {
+	if pacer == nil {
+		return lw
+	}
+	return &PacedListerWatcher{ListerWatcher: lw, pacer: pacer}
+}
+
+// List is a wrapper func around the cache.ListerWatcher.List func, which
+// waits for the pacer before sending the request.
+func (p *PacedListerWatcher) List(options metav1.ListOptions) (runtime.Object, error) {
+	if err := p.pacer.acquire(); err != nil {
+		return nil, err
+	}
+	res, err := p.ListerWatcher.List(options)
+	p.pacer.release(err)
+	return res, err
+}
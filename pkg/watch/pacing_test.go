@@ -0,0 +1,153 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package watch
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestListPacerLimitsConcurrency(t *testing.T) {
+	pacer := NewListPacer(2, nil)
+
+	var mutex sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	release := make(chan struct{})
+	lw := NewPacedListerWatcher(&cache.ListWatch{ListFunc: func(metav1.ListOptions) (runtime.Object, error) {
+		mutex.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mutex.Unlock()
+		<-release
+		mutex.Lock()
+		inFlight--
+		mutex.Unlock()
+		return &v1.PodList{}, nil
+	}}, pacer)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := lw.List(metav1.ListOptions{}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if maxInFlight != 2 {
+		t.Errorf("expected at most 2 concurrent list requests, got %d", maxInFlight)
+	}
+}
+
+func TestListPacerAdaptsLimit(t *testing.T) {
+	metrics := NewListPacingMetrics(prometheus.NewRegistry())
+	pacer := NewListPacer(8, metrics)
+	now := time.Unix(0, 0)
+	pacer.now = func() time.Time { return now }
+
+	var err error
+	lw := NewPacedListerWatcher(&cache.ListWatch{ListFunc: func(metav1.ListOptions) (runtime.Object, error) {
+		return &v1.PodList{}, err
+	}}, pacer)
+	list := func() error {
+		_, err := lw.List(metav1.ListOptions{})
+		return err
+	}
+
+	err = apierrors.NewTooManyRequests("overloaded", 1)
+	_ = list()
+	_ = list()
+	if got := testutil.ToFloat64(metrics.Limit); got != 2 {
+		t.Errorf("expected the limit to be halved twice to 2, got %v", got)
+	}
+
+	err = nil
+	_ = list()
+	if got := testutil.ToFloat64(metrics.Limit); got != 3 {
+		t.Errorf("expected the limit to increase to 3, got %v", got)
+	}
+
+	// Missing permissions do not affect the limit or the circuit.
+	err = apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "", errors.New("forbidden"))
+	for i := 0; i < circuitFailureThreshold; i++ {
+		_ = list()
+	}
+	if got := testutil.ToFloat64(metrics.CircuitOpen); got != 0 {
+		t.Errorf("expected the circuit to be closed, got %v", got)
+	}
+}
+
+func TestListPacerCircuit(t *testing.T) {
+	metrics := NewListPacingMetrics(prometheus.NewRegistry())
+	pacer := NewListPacer(4, metrics)
+	now := time.Unix(0, 0)
+	pacer.now = func() time.Time { return now }
+
+	calls := 0
+	err := errors.New("connection refused")
+	lw := NewPacedListerWatcher(&cache.ListWatch{ListFunc: func(metav1.ListOptions) (runtime.Object, error) {
+		calls++
+		return &v1.PodList{}, err
+	}}, pacer)
+	list := func() error {
+		_, err := lw.List(metav1.ListOptions{})
+		return err
+	}
+
+	for i := 0; i < circuitFailureThreshold; i++ {
+		_ = list()
+	}
+	if got := testutil.ToFloat64(metrics.CircuitOpen); got != 1 {
+		t.Errorf("expected the circuit to be open, got %v", got)
+	}
+	if err := list(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("expected ErrCircuitOpen, got %v", err)
+	}
+	if calls != circuitFailureThreshold {
+		t.Errorf("expected no list requests while the circuit is open, got %d", calls-circuitFailureThreshold)
+	}
+
+	now = now.Add(minCircuitCooldown)
+	err = nil
+	if err := list(); err != nil {
+		t.Errorf("expected list requests after the cooldown, got %v", err)
+	}
+	if got := testutil.ToFloat64(metrics.CircuitOpen); got != 0 {
+		t.Errorf("expected the circuit to be closed, got %v", got)
+	}
+	if got := testutil.ToFloat64(metrics.Limit); got != 2 {
+		t.Errorf("expected the limit to restart from 1 and increase to 2, got %v", got)
+	}
+}
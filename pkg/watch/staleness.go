@@ -0,0 +1,179 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package watch
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+var staleDesc = prometheus.NewDesc(
+	"kube_state_metrics_stale",
+	"Whether the list and watch requests of the resource failed for longer than the staleness threshold, so that its metrics might be outdated.",
+	[]string{"resource"}, nil,
+)
+
+// StalenessTracker tracks since when the list and watch requests of the
+// reflectors of each resource fail. The metrics of a resource are considered
+// stale if the requests of one of its reflectors failed for longer than the
+// threshold. It implements prometheus.Collector to expose the staleness of
+// each resource.
+type StalenessTracker struct {
+	threshold time.Duration
+	now       func() time.Time
+
+	mutex sync.Mutex
+	// reflectors holds the state of each StalenessListerWatcher.
+	reflectors map[*StalenessListerWatcher]reflectorState
+}
+
+type reflectorState struct {
+	resource string
+	// failingSince is the time of the first failed request since the last
+	// successful one, zero if the last request succeeded.
+	failingSince time.Time
+}
+
+// NewStalenessTracker returns a StalenessTracker with the given threshold.
+func NewStalenessTracker(threshold time.Duration) *StalenessTracker {
+	return &StalenessTracker{
+		threshold:  threshold,
+		now:        time.Now,
+		reflectors: map[*StalenessListerWatcher]reflectorState{},
+	}
+}
+
+// StaleResources returns the sorted resources whose metrics are stale.
+func (t *StalenessTracker) StaleResources() []string {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	stale := map[string]struct{}{}
+	for _, s := range t.reflectors {
+		if !s.failingSince.IsZero() && t.now().Sub(s.failingSince) >= t.threshold {
+			stale[s.resource] = struct{}{}
+		}
+	}
+	resources := make([]string, 0, len(stale))
+	for r := range stale {
+		resources = append(resources, r)
+	}
+	sort.Strings(resources)
+	return resources
+}
+
+// Describe implements the prometheus.Collector interface.
+func (t *StalenessTracker) Describe(ch chan<- *prometheus.Desc) {
+	ch <- staleDesc
+}
+
+// Collect implements the prometheus.Collector interface.
+func (t *StalenessTracker) Collect(ch chan<- prometheus.Metric) {
+	stale := map[string]struct{}{}
+	for _, r := range t.StaleResources() {
+		stale[r] = struct{}{}
+	}
+
+	t.mutex.Lock()
+	resources := map[string]struct{}{}
+	for _, s := range t.reflectors {
+		resources[s.resource] = struct{}{}
+	}
+	t.mutex.Unlock()
+
+	for r := range resources {
+		value := 0.0
+		if _, ok := stale[r]; ok {
+			value = 1
+		}
+		ch <- prometheus.MustNewConstMetric(staleDesc, prometheus.GaugeValue, value, r)
+	}
+}
+
+// observe records the result of a request of the reflector.
+func (t *StalenessTracker) observe(lw *StalenessListerWatcher, err error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	// Requests which finish after the reflector was stopped are ignored.
+	if lw.ctx.Err() != nil {
+		return
+	}
+	s := t.reflectors[lw]
+	s.resource = lw.resource
+	switch {
+	case err == nil:
+		s.failingSince = time.Time{}
+	case s.failingSince.IsZero():
+		s.failingSince = t.now()
+	}
+	t.reflectors[lw] = s
+}
+
+// forget stops tracking the reflector.
+func (t *StalenessTracker) forget(lw *StalenessListerWatcher) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	delete(t.reflectors, lw)
+}
+
+// StalenessListerWatcher records the results of the list and watch requests
+// of a cache.ListerWatcher with a StalenessTracker.
+type StalenessListerWatcher struct {
+	ctx      context.Context
+	lw       cache.ListerWatcher
+	tracker  *StalenessTracker
+	resource string
+}
+
+// NewStalenessListerWatcher returns a StalenessListerWatcher, or lw if tracker
+// is nil. The reflector is tracked until the context is done.
+func NewStalenessListerWatcher(ctx context.Context, lw cache.ListerWatcher, tracker *StalenessTracker, resource string) cache.ListerWatcher {
+	if tracker == nil {
+		return lw
+	}
+	s := &StalenessListerWatcher{ctx: ctx, lw: lw, tracker: tracker, resource: resource}
+	go func() {
+		<-ctx.Done()
+		tracker.forget(s)
+	}()
+	return s
+}
+
+// List is a wrapper func around the cache.ListerWatcher.List func, which
+// records its result.
+func (s *StalenessListerWatcher) List(options metav1.ListOptions) (runtime.Object, error) {
+	res, err := s.lw.List(options)
+	s.tracker.observe(s, err)
+	return res, err
+}
+
+// Watch is a wrapper func around the cache.ListerWatcher.Watch func, which
+// records its result.
+func (s *StalenessListerWatcher) Watch(options metav1.ListOptions) (watch.Interface, error) {
+	res, err := s.lw.Watch(options)
+	s.tracker.observe(s, err)
+	return res, err
+}
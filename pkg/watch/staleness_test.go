@@ -0,0 +1,111 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package watch
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestStalenessTracker(t *testing.T) {
+	tracker := NewStalenessTracker(time.Minute)
+	now := time.Unix(0, 0)
+	tracker.now = func() time.Time { return now }
+
+	var err error
+	fake := &cache.ListWatch{
+		ListFunc: func(metav1.ListOptions) (runtime.Object, error) {
+			return &v1.PodList{}, err
+		},
+		WatchFunc: func(metav1.ListOptions) (watch.Interface, error) {
+			if err != nil {
+				return nil, err
+			}
+			return watch.NewFake(), nil
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pods := NewStalenessListerWatcher(ctx, fake, tracker, "*v1.Pod")
+	nodesCtx, nodesCancel := context.WithCancel(context.Background())
+	nodes := NewStalenessListerWatcher(nodesCtx, fake, tracker, "*v1.Node")
+
+	if _, err := pods.List(metav1.ListOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := nodes.Watch(metav1.ListOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	err = errors.New("connection refused")
+	_, _ = pods.Watch(metav1.ListOptions{})
+	now = now.Add(30 * time.Second)
+	_, _ = pods.Watch(metav1.ListOptions{})
+	_, _ = nodes.Watch(metav1.ListOptions{})
+	if stale := tracker.StaleResources(); len(stale) != 0 {
+		t.Errorf("expected no stale resources below the threshold, got %v", stale)
+	}
+
+	now = now.Add(30 * time.Second)
+	if stale := tracker.StaleResources(); !reflect.DeepEqual(stale, []string{"*v1.Pod"}) {
+		t.Errorf("expected pods to be stale, got %v", stale)
+	}
+	expected := `
+# HELP kube_state_metrics_stale Whether the list and watch requests of the resource failed for longer than the staleness threshold, so that its metrics might be outdated.
+# TYPE kube_state_metrics_stale gauge
+kube_state_metrics_stale{resource="*v1.Node"} 0
+kube_state_metrics_stale{resource="*v1.Pod"} 1
+`
+	if err := testutil.CollectAndCompare(tracker, strings.NewReader(expected)); err != nil {
+		t.Error(err)
+	}
+
+	err = nil
+	if _, err := pods.List(metav1.ListOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if stale := tracker.StaleResources(); len(stale) != 0 {
+		t.Errorf("expected no stale resources after a successful request, got %v", stale)
+	}
+
+	// Stopped reflectors are not tracked anymore.
+	nodesCancel()
+	if err := wait(func() bool { return testutil.CollectAndCount(tracker) == 1 }); err != nil {
+		t.Error("expected the stopped reflector to be forgotten")
+	}
+}
+
+func wait(condition func() bool) error {
+	for i := 0; i < 100; i++ {
+		if condition() {
+			return nil
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return errors.New("timed out")
+}
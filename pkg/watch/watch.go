@@ -17,23 +17,30 @@ limitations under the License.
 package watch
 
 import (
+	"time"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/tools/cache"
+
+	"k8s.io/kube-state-metrics/v2/pkg/telemetry"
 )
 
-// ListWatchMetrics stores the pointers of kube_state_metrics_[list|watch]_total metrics.
+// ListWatchMetrics stores the pointers of kube_state_metrics_[list|watch]_total
+// and kube_state_metrics_list_duration_seconds metrics.
 type ListWatchMetrics struct {
-	WatchTotal *prometheus.CounterVec
-	ListTotal  *prometheus.CounterVec
+	WatchTotal   *prometheus.CounterVec
+	ListTotal    *prometheus.CounterVec
+	ListDuration *prometheus.HistogramVec
 }
 
 // NewListWatchMetrics takes in a prometheus registry and initializes
-// and registers the kube_state_metrics_list_total and
-// kube_state_metrics_watch_total metrics. It returns those registered metrics.
+// and registers the kube_state_metrics_list_total,
+// kube_state_metrics_watch_total and kube_state_metrics_list_duration_seconds
+// metrics. It returns those registered metrics.
 func NewListWatchMetrics(r prometheus.Registerer) *ListWatchMetrics {
 	return &ListWatchMetrics{
 		WatchTotal: promauto.With(r).NewCounterVec(
@@ -50,6 +57,14 @@ func NewListWatchMetrics(r prometheus.Registerer) *ListWatchMetrics {
 			},
 			[]string{"result", "resource"},
 		),
+		ListDuration: promauto.With(r).NewHistogramVec(
+			telemetry.HistogramOpts(prometheus.HistogramOpts{
+				Name:    "kube_state_metrics_list_duration_seconds",
+				Help:    "Duration of the list requests of the resources in kube-state-metrics.",
+				Buckets: prometheus.ExponentialBuckets(0.01, 4, 8),
+			}),
+			[]string{"resource"},
+		),
 	}
 }
 
@@ -76,11 +91,14 @@ func NewInstrumentedListerWatcher(lw cache.ListerWatcher, metrics *ListWatchMetr
 // / counters based on the outcome of the List operation it instruments.
 func (i *InstrumentedListerWatcher) List(options metav1.ListOptions) (res runtime.Object, err error) {
 
-	if i.useAPIServerCache {
+	// Continued lists must not set a resourceVersion.
+	if i.useAPIServerCache && options.Continue == "" {
 		options.ResourceVersion = "0"
 	}
 
+	start := time.Now()
 	res, err = i.lw.List(options)
+	i.metrics.ListDuration.WithLabelValues(i.resource).Observe(time.Since(start).Seconds())
 	if err != nil {
 		i.metrics.ListTotal.WithLabelValues("error", i.resource).Inc()
 		return
@@ -102,3 +120,22 @@ func (i *InstrumentedListerWatcher) Watch(options metav1.ListOptions) (res watch
 	i.metrics.WatchTotal.WithLabelValues("success", i.resource).Inc()
 	return
 }
+
+// ConsistentListerWatcher lists without resourceVersion, so that lists are
+// consistent reads from etcd instead of being served from the watch cache of
+// the apiserver, which reflectors use for their initial lists.
+type ConsistentListerWatcher struct {
+	cache.ListerWatcher
+}
+
+// NewConsistentListerWatcher returns a new ConsistentListerWatcher.
+func NewConsistentListerWatcher(lw cache.ListerWatcher) cache.ListerWatcher {
+	return &ConsistentListerWatcher{ListerWatcher: lw}
+}
+
+// List lists the objects with an empty resourceVersion.
+func (c *ConsistentListerWatcher) List(options metav1.ListOptions) (runtime.Object, error) {
+	options.ResourceVersion = ""
+	options.ResourceVersionMatch = ""
+	return c.ListerWatcher.List(options)
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package watch
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestListResourceVersion(t *testing.T) {
+	var got metav1.ListOptions
+	lw := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			got = options
+			return &v1.PodList{}, nil
+		},
+	}
+	metrics := NewListWatchMetrics(prometheus.NewRegistry())
+
+	tests := []struct {
+		name    string
+		lw      cache.ListerWatcher
+		options metav1.ListOptions
+		want    string
+	}{
+		{"cache", NewInstrumentedListerWatcher(lw, metrics, "pods", true), metav1.ListOptions{ResourceVersion: "42"}, "0"},
+		{"cache continued", NewInstrumentedListerWatcher(lw, metrics, "pods", true), metav1.ListOptions{Continue: "token"}, ""},
+		{"reflector", NewInstrumentedListerWatcher(lw, metrics, "pods", false), metav1.ListOptions{ResourceVersion: "42"}, "42"},
+		{"consistent", NewConsistentListerWatcher(NewInstrumentedListerWatcher(lw, metrics, "pods", false)), metav1.ListOptions{ResourceVersion: "0"}, ""},
+	}
+	for _, tt := range tests {
+		if _, err := tt.lw.List(tt.options); err != nil {
+			t.Fatal(err)
+		}
+		if got.ResourceVersion != tt.want {
+			t.Errorf("%s: expected resourceVersion %q, got %q", tt.name, tt.want, got.ResourceVersion)
+		}
+	}
+}
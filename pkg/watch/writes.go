@@ -0,0 +1,90 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package watch
+
+import (
+	"sync/atomic"
+	"time"
+
+	"k8s.io/client-go/tools/cache"
+)
+
+// WriteTracker tracks the time of the last successful write to the stores it
+// tracks, to check that the events of the reflectors are still processed.
+type WriteTracker struct {
+	now func() time.Time
+	// lastWrite is the Unix time of the last write in nanoseconds, 0 if
+	// nothing was written yet.
+	lastWrite atomic.Int64
+}
+
+// NewWriteTracker returns a new WriteTracker.
+func NewWriteTracker() *WriteTracker {
+	return &WriteTracker{now: time.Now}
+}
+
+// LastWrite returns the time of the last successful write to a tracked
+// store, or the zero time if nothing was written yet.
+func (t *WriteTracker) LastWrite() time.Time {
+	n := t.lastWrite.Load()
+	if n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, n)
+}
+
+// Track returns a store which records successful writes to the given store.
+// A nil tracker returns the store unchanged.
+func (t *WriteTracker) Track(store cache.Store) cache.Store {
+	if t == nil {
+		return store
+	}
+	return &writeTrackingStore{Store: store, tracker: t}
+}
+
+// record records a write if err is nil, and returns err.
+func (t *WriteTracker) record(err error) error {
+	if err == nil {
+		t.lastWrite.Store(t.now().UnixNano())
+	}
+	return err
+}
+
+type writeTrackingStore struct {
+	cache.Store
+	tracker *WriteTracker
+}
+
+// Add adds the object to the store and records the write.
+func (s *writeTrackingStore) Add(obj interface{}) error {
+	return s.tracker.record(s.Store.Add(obj))
+}
+
+// Update updates the object in the store and records the write.
+func (s *writeTrackingStore) Update(obj interface{}) error {
+	return s.tracker.record(s.Store.Update(obj))
+}
+
+// Delete deletes the object from the store and records the write.
+func (s *writeTrackingStore) Delete(obj interface{}) error {
+	return s.tracker.record(s.Store.Delete(obj))
+}
+
+// Replace replaces the objects of the store and records the write.
+func (s *writeTrackingStore) Replace(list []interface{}, resourceVersion string) error {
+	return s.tracker.record(s.Store.Replace(list, resourceVersion))
+}
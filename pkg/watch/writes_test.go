@@ -0,0 +1,73 @@
+/*
+Copyright 2023 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package watch
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// failingStore fails all writes.
+type failingStore struct {
+	cache.Store
+}
+
+func (failingStore) Add(interface{}) error { return errors.New("failed") }
+
+func TestWriteTracker(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	tracker := NewWriteTracker()
+	tracker.now = func() time.Time { return now }
+
+	if got := tracker.LastWrite(); !got.IsZero() {
+		t.Errorf("expected no write yet, got %v", got)
+	}
+
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default"}}
+	if err := tracker.Track(failingStore{}).Add(pod); err == nil {
+		t.Fatal("expected the write to fail")
+	}
+	if got := tracker.LastWrite(); !got.IsZero() {
+		t.Errorf("expected failed writes not to be recorded, got %v", got)
+	}
+
+	store := tracker.Track(cache.NewStore(cache.MetaNamespaceKeyFunc))
+	if err := store.Add(pod); err != nil {
+		t.Fatal(err)
+	}
+	if got := tracker.LastWrite(); !got.Equal(now) {
+		t.Errorf("expected the last write at %v, got %v", now, got)
+	}
+
+	now = now.Add(time.Minute)
+	if err := store.Replace(nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	if got := tracker.LastWrite(); !got.Equal(now) {
+		t.Errorf("expected the last write at %v, got %v", now, got)
+	}
+
+	var nilTracker *WriteTracker
+	if s := cache.NewStore(cache.MetaNamespaceKeyFunc); nilTracker.Track(s) != s {
+		t.Error("expected a nil tracker to return the store unchanged")
+	}
+}
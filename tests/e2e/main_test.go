@@ -256,10 +256,15 @@ func TestDefaultCollectorMetricsAvailable(t *testing.T) {
 	nonDefaultResources := map[string]bool{
 		"clusterrole":        true,
 		"clusterrolebinding": true,
+		"csidriver":          true,
+		"csinode":            true,
+		"csistoragecapacity": true,
 		"endpointslice":      true,
 		"ingressclass":       true,
+		"priorityclass":      true,
 		"role":               true,
 		"rolebinding":        true,
+		"runtimeclass":       true,
 		"serviceaccount":     true,
 	}
 	nonResources := map[string]bool{